@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"strings"
+)
+
+// BodyDecoder retrieves the content of bodies using a fixed schema, for
+// applications that decode many bodies against the same schema. It prepares
+// the lookup structures derived from the schema once, rather than for each
+// body as the Content and PartialContent methods of a body must.
+//
+// Bodies that implement DecoderBody use the prepared structures directly,
+// while the content of any other body is retrieved using its Content or
+// PartialContent method, and so the results are the same as calling those
+// methods with the schema. A BodyDecoder is not modified by decoding, and so
+// it is safe for concurrent use and the returned content remains valid
+// regardless of any later calls.
+type BodyDecoder struct {
+	schema *BodySchema
+
+	blocks     map[string]BlockHeaderSchema
+	blocksFold map[string]BlockHeaderSchema
+}
+
+// DecoderBody is an optional interface implemented by bodies that can use the
+// lookup structures prepared by a BodyDecoder to retrieve their content with
+// fewer allocations.
+type DecoderBody interface {
+	Body
+
+	// ContentWithDecoder is equivalent to Content with the decoder's schema.
+	ContentWithDecoder(d *BodyDecoder) (*BodyContent, Diagnostics)
+
+	// PartialContentWithDecoder is equivalent to PartialContent with the
+	// decoder's schema.
+	PartialContentWithDecoder(d *BodyDecoder) (*BodyContent, Body, Diagnostics)
+}
+
+// NewBodyDecoder returns a BodyDecoder for the given schema, which must not
+// be modified while the decoder is in use.
+func NewBodyDecoder(schema *BodySchema) *BodyDecoder {
+	d := &BodyDecoder{
+		schema: schema,
+		blocks: make(map[string]BlockHeaderSchema, len(schema.Blocks)),
+	}
+	if schema.CaseInsensitive {
+		d.blocksFold = make(map[string]BlockHeaderSchema, len(schema.Blocks))
+	}
+	for _, blockS := range schema.Blocks {
+		d.blocks[blockS.Type] = blockS
+		if d.blocksFold != nil {
+			d.blocksFold[strings.ToLower(blockS.Type)] = blockS
+		}
+	}
+	return d
+}
+
+// Schema returns the schema the decoder was created with.
+func (d *BodyDecoder) Schema() *BodySchema {
+	return d.schema
+}
+
+// BlockSchema returns the block header schema for the given block type, if
+// the decoder's schema has one.
+func (d *BodyDecoder) BlockSchema(typeName string) (BlockHeaderSchema, bool) {
+	blockS, ok := d.blocks[typeName]
+	return blockS, ok
+}
+
+// BlockSchemaFold returns the block header schema for the given block type
+// ignoring differences in letter case, if the decoder's schema is case
+// insensitive and has one.
+func (d *BodyDecoder) BlockSchemaFold(typeName string) (BlockHeaderSchema, bool) {
+	if d.blocksFold == nil {
+		return BlockHeaderSchema{}, false
+	}
+	blockS, ok := d.blocksFold[strings.ToLower(typeName)]
+	return blockS, ok
+}
+
+// Decode retrieves the content of the given body using the decoder's schema,
+// as with the body's Content method.
+func (d *BodyDecoder) Decode(body Body) (*BodyContent, Diagnostics) {
+	if db, ok := body.(DecoderBody); ok {
+		return db.ContentWithDecoder(d)
+	}
+	return body.Content(d.schema)
+}
+
+// DecodePartial retrieves the content of the given body using the decoder's
+// schema, as with the body's PartialContent method.
+func (d *BodyDecoder) DecodePartial(body Body) (*BodyContent, Body, Diagnostics) {
+	if db, ok := body.(DecoderBody); ok {
+		return db.PartialContentWithDecoder(d)
+	}
+	return body.PartialContent(d.schema)
+}
@@ -0,0 +1,308 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiffAction describes how an item differs between two bodies compared by
+// DiffBodies.
+type DiffAction rune
+
+const (
+	// DiffAdded indicates an item present only in the new body.
+	DiffAdded DiffAction = '+'
+
+	// DiffRemoved indicates an item present only in the old body.
+	DiffRemoved DiffAction = '-'
+
+	// DiffChanged indicates an item present in both bodies but with
+	// different content.
+	DiffChanged DiffAction = '~'
+)
+
+// BodyDiff describes the differences between two bodies, as returned by
+// DiffBodies. Items that are the same in both bodies are not included.
+type BodyDiff struct {
+	// Attributes describes the attributes that differ, in lexical order by
+	// name.
+	Attributes []AttributeDiff
+
+	// Blocks describes the blocks that differ. Blocks removed or changed
+	// appear in the order they appear in the old body, followed by the
+	// blocks added in the order they appear in the new body.
+	Blocks []BlockDiff
+}
+
+// Empty returns true if the diff describes no differences at all.
+func (d *BodyDiff) Empty() bool {
+	return d == nil || (len(d.Attributes) == 0 && len(d.Blocks) == 0)
+}
+
+// AttributeDiff describes an attribute that differs between two bodies.
+type AttributeDiff struct {
+	Name   string
+	Action DiffAction
+
+	// Old and New are the attribute in each of the bodies, with Old nil for
+	// an added attribute and New nil for a removed attribute.
+	Old, New *Attribute
+}
+
+// BlockDiff describes a block that differs between two bodies.
+type BlockDiff struct {
+	Type   string
+	Labels []string
+	Action DiffAction
+
+	// Old and New are the block in each of the bodies, with Old nil for an
+	// added block and New nil for a removed block.
+	Old, New *Block
+
+	// Body describes the differences between the bodies of Old and New, for
+	// a changed block. It is nil for added and removed blocks.
+	Body *BodyDiff
+}
+
+// ExprEqualFunc is the signature of a function that DiffBodies uses to
+// decide whether the expressions of two attributes of the same name are
+// equivalent.
+type ExprEqualFunc func(oldExpr, newExpr Expression) bool
+
+// DiffBodies compares the content of two bodies, such as the same file
+// before and after a change, and describes the attributes and blocks that
+// were added, removed or changed.
+//
+// Attributes are matched by name, and blocks by their type and labels, so
+// the result doesn't depend on the order of the items in each body. If
+// there are several blocks with the same type and labels then they are
+// matched in the order they appear. The bodies of matched blocks are
+// compared recursively, and a block is changed if its body is.
+//
+// If a schema is given then it is used to retrieve the content of both of
+// the given bodies, and any diagnostics that produces are returned.
+// Otherwise, and always for the bodies of nested blocks, each body is
+// retrieved using its own inferred schema if it implements
+// InferredSchemaBody, or using JustAttributes otherwise.
+//
+// The attributes of the same name in each body are compared by passing
+// their expressions to the given function, which is ExprValuesEqual(nil)
+// if none is given.
+func DiffBodies(oldBody, newBody Body, schema *BodySchema, equal ExprEqualFunc) (*BodyDiff, Diagnostics) {
+	if equal == nil {
+		equal = ExprValuesEqual(nil)
+	}
+
+	var diags Diagnostics
+	oldContent, moreDiags := diffBodyContent(oldBody, schema)
+	diags = append(diags, moreDiags...)
+	newContent, moreDiags := diffBodyContent(newBody, schema)
+	diags = append(diags, moreDiags...)
+
+	d, moreDiags := diffContent(oldContent, newContent, equal)
+	diags = append(diags, moreDiags...)
+	return d, diags
+}
+
+func diffBodyContent(body Body, schema *BodySchema) (*BodyContent, Diagnostics) {
+	if schema == nil {
+		ib, ok := body.(InferredSchemaBody)
+		if !ok {
+			attrs, diags := body.JustAttributes()
+			return &BodyContent{Attributes: attrs}, diags
+		}
+		schema = ib.InferredSchema()
+	}
+	return body.Content(schema)
+}
+
+func diffContent(oldContent, newContent *BodyContent, equal ExprEqualFunc) (*BodyDiff, Diagnostics) {
+	var diags Diagnostics
+	d := &BodyDiff{}
+
+	names := make([]string, 0, len(oldContent.Attributes)+len(newContent.Attributes))
+	for name := range oldContent.Attributes {
+		names = append(names, name)
+	}
+	for name := range newContent.Attributes {
+		if _, exists := oldContent.Attributes[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldAttr, newAttr := oldContent.Attributes[name], newContent.Attributes[name]
+		switch {
+		case oldAttr == nil:
+			d.Attributes = append(d.Attributes, AttributeDiff{Name: name, Action: DiffAdded, New: newAttr})
+		case newAttr == nil:
+			d.Attributes = append(d.Attributes, AttributeDiff{Name: name, Action: DiffRemoved, Old: oldAttr})
+		case !equal(oldAttr.Expr, newAttr.Expr):
+			d.Attributes = append(d.Attributes, AttributeDiff{Name: name, Action: DiffChanged, Old: oldAttr, New: newAttr})
+		}
+	}
+
+	// The new blocks with each key that haven't yet been matched with an
+	// old block, in the order they appear.
+	unmatched := map[string][]*Block{}
+	for _, block := range newContent.Blocks {
+		key := diffBlockKey(block)
+		unmatched[key] = append(unmatched[key], block)
+	}
+	matched := map[*Block]bool{}
+	for _, oldBlock := range oldContent.Blocks {
+		key := diffBlockKey(oldBlock)
+		if len(unmatched[key]) == 0 {
+			d.Blocks = append(d.Blocks, BlockDiff{
+				Type:   oldBlock.Type,
+				Labels: oldBlock.Labels,
+				Action: DiffRemoved,
+				Old:    oldBlock,
+			})
+			continue
+		}
+		newBlock := unmatched[key][0]
+		unmatched[key] = unmatched[key][1:]
+		matched[newBlock] = true
+
+		bodyDiff, moreDiags := DiffBodies(oldBlock.Body, newBlock.Body, nil, equal)
+		diags = append(diags, moreDiags...)
+		if !bodyDiff.Empty() {
+			d.Blocks = append(d.Blocks, BlockDiff{
+				Type:   oldBlock.Type,
+				Labels: oldBlock.Labels,
+				Action: DiffChanged,
+				Old:    oldBlock,
+				New:    newBlock,
+				Body:   bodyDiff,
+			})
+		}
+	}
+	for _, newBlock := range newContent.Blocks {
+		if !matched[newBlock] {
+			d.Blocks = append(d.Blocks, BlockDiff{
+				Type:   newBlock.Type,
+				Labels: newBlock.Labels,
+				Action: DiffAdded,
+				New:    newBlock,
+			})
+		}
+	}
+
+	return d, diags
+}
+
+func diffBlockKey(block *Block) string {
+	var buf strings.Builder
+	buf.WriteString(block.Type)
+	for _, label := range block.Labels {
+		fmt.Fprintf(&buf, " %q", label)
+	}
+	return buf.String()
+}
+
+// ExprValuesEqual returns an ExprEqualFunc that considers two expressions
+// equivalent if they produce equal values when evaluated in the given
+// context, which may be nil.
+//
+// If either expression can't be evaluated in the context, such as because
+// it refers to a variable that isn't defined, then the expressions are
+// instead compared by their structure: two static traversals, as accepted
+// by AbsTraversalForExpr, are equivalent if they have the same steps, and
+// two function calls, tuple constructors or object constructors, as
+// accepted by ExprCall, ExprList and ExprMap respectively, are equivalent if
+// their corresponding parts are each equivalent in the same way. Any other
+// expressions that can't be evaluated are conservatively considered to
+// differ.
+func ExprValuesEqual(ctx *EvalContext) ExprEqualFunc {
+	return func(oldExpr, newExpr Expression) bool {
+		return exprsEquivalent(oldExpr, newExpr, ctx)
+	}
+}
+
+func exprsEquivalent(oldExpr, newExpr Expression, ctx *EvalContext) bool {
+	oldVal, oldDiags := oldExpr.Value(ctx)
+	newVal, newDiags := newExpr.Value(ctx)
+	if !oldDiags.HasErrors() && !newDiags.HasErrors() {
+		return oldVal.RawEquals(newVal)
+	}
+
+	if oldTraversal, diags := AbsTraversalForExpr(oldExpr); !diags.HasErrors() {
+		newTraversal, diags := AbsTraversalForExpr(newExpr)
+		return !diags.HasErrors() && traversalStepsEqual(oldTraversal, newTraversal)
+	}
+	if oldCall, diags := ExprCall(oldExpr); !diags.HasErrors() {
+		newCall, diags := ExprCall(newExpr)
+		if diags.HasErrors() || oldCall.Name != newCall.Name {
+			return false
+		}
+		return exprListsEquivalent(oldCall.Arguments, newCall.Arguments, ctx)
+	}
+	if oldElems, diags := ExprList(oldExpr); !diags.HasErrors() {
+		newElems, diags := ExprList(newExpr)
+		return !diags.HasErrors() && exprListsEquivalent(oldElems, newElems, ctx)
+	}
+	if oldItems, diags := ExprMap(oldExpr); !diags.HasErrors() {
+		newItems, diags := ExprMap(newExpr)
+		if diags.HasErrors() || len(oldItems) != len(newItems) {
+			return false
+		}
+		for i := range oldItems {
+			if !exprsEquivalent(oldItems[i].Key, newItems[i].Key, ctx) || !exprsEquivalent(oldItems[i].Value, newItems[i].Value, ctx) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func exprListsEquivalent(oldExprs, newExprs []Expression, ctx *EvalContext) bool {
+	if len(oldExprs) != len(newExprs) {
+		return false
+	}
+	for i := range oldExprs {
+		if !exprsEquivalent(oldExprs[i], newExprs[i], ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// traversalStepsEqual returns true if the given traversals have the same
+// steps, disregarding their source ranges.
+func traversalStepsEqual(a, b Traversal) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		switch as := a[i].(type) {
+		case TraverseRoot:
+			bs, ok := b[i].(TraverseRoot)
+			if !ok || as.Name != bs.Name {
+				return false
+			}
+		case TraverseAttr:
+			bs, ok := b[i].(TraverseAttr)
+			if !ok || as.Name != bs.Name {
+				return false
+			}
+		case TraverseIndex:
+			bs, ok := b[i].(TraverseIndex)
+			if !ok || !as.Key.RawEquals(bs.Key) {
+				return false
+			}
+		case TraverseSplat:
+			if _, ok := b[i].(TraverseSplat); !ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
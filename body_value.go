@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// InferredSchemaBody is an optional interface implemented by bodies that can
+// describe their own structure, allowing BodyToValue to decode their nested
+// blocks without a schema provided by the caller.
+type InferredSchemaBody interface {
+	Body
+
+	// InferredSchema returns a schema that describes all of the attributes
+	// and block types present in the body. Each block type is described
+	// with as many labels as the first block of that type has.
+	InferredSchema() *BodySchema
+}
+
+// BodyToValue makes a best-effort attempt to convert the entire content of
+// the given body into an object value without a schema, for generic tooling
+// such as a tool that dumps any configuration as JSON.
+//
+// Each attribute becomes an attribute of the result whose value is the
+// result of evaluating its expression in the given EvalContext. Diagnostics
+// from evaluating an attribute are returned, and the attribute's value is
+// then whatever placeholder the expression returned, usually an unknown
+// value, so that any other attributes can still be converted.
+//
+// If the body implements InferredSchemaBody then each block type also
+// becomes an attribute of the result, named after the block type. A single
+// block without labels becomes an object converted from its body in the same
+// way, and several blocks of the same type become a tuple of such objects.
+// Blocks with labels are grouped into a nested object for each label, in
+// the same way as in the JSON syntax, so that for example the body of a
+// block `resource "a" "b"` is at `resource.a.b`. A block type with the same
+// name as an attribute is ignored, with a warning diagnostic.
+//
+// Bodies that don't implement InferredSchemaBody are converted using only
+// their JustAttributes method. A body in a syntax that can't distinguish
+// attributes from blocks, such as JSON, therefore produces block content as
+// the values of attributes.
+func BodyToValue(body Body, ctx *EvalContext) (cty.Value, Diagnostics) {
+	ib, ok := body.(InferredSchemaBody)
+	if !ok {
+		attrs, diags := body.JustAttributes()
+		vals, moreDiags := attributeValues(attrs, ctx)
+		diags = append(diags, moreDiags...)
+		return cty.ObjectVal(vals), diags
+	}
+
+	content, diags := ib.Content(ib.InferredSchema())
+	vals, moreDiags := attributeValues(content.Attributes, ctx)
+	diags = append(diags, moreDiags...)
+
+	blocksByType := content.Blocks.ByType()
+	typeNames := make([]string, 0, len(blocksByType))
+	for typeName := range blocksByType {
+		typeNames = append(typeNames, typeName)
+	}
+	sort.Strings(typeNames)
+	for _, typeName := range typeNames {
+		blocks := blocksByType[typeName]
+		if attr, exists := content.Attributes[typeName]; exists {
+			diags = append(diags, &Diagnostic{
+				Severity: DiagWarning,
+				Summary:  "Conflicting block type",
+				Detail:   fmt.Sprintf("The %q blocks are ignored, because there is also an argument named %q at %s.", typeName, typeName, attr.NameRange),
+				Subject:  blocks[0].TypeRange.Ptr(),
+			})
+			continue
+		}
+		val, moreDiags := blocksToValue(blocks, 0, ctx)
+		diags = append(diags, moreDiags...)
+		vals[typeName] = val
+	}
+
+	return cty.ObjectVal(vals), diags
+}
+
+func attributeValues(attrs Attributes, ctx *EvalContext) (map[string]cty.Value, Diagnostics) {
+	var diags Diagnostics
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vals := make(map[string]cty.Value, len(attrs))
+	for _, name := range names {
+		attr := attrs[name]
+		val, valDiags := attr.Expr.Value(ctx)
+		diags = append(diags, valDiags...)
+		if val == cty.NilVal {
+			val = cty.DynamicVal
+		}
+		vals[name] = val
+	}
+	return vals, diags
+}
+
+// blocksToValue converts the given blocks, which all have the same type,
+// grouping them by their labels from the given index onwards.
+func blocksToValue(blocks Blocks, labelIdx int, ctx *EvalContext) (cty.Value, Diagnostics) {
+	var diags Diagnostics
+
+	if labelIdx < len(blocks[0].Labels) {
+		groups := make(map[string]Blocks)
+		for _, block := range blocks {
+			if labelIdx >= len(block.Labels) {
+				continue // can't happen for blocks that conform to the schema
+			}
+			label := block.Labels[labelIdx]
+			groups[label] = append(groups[label], block)
+		}
+		vals := make(map[string]cty.Value, len(groups))
+		for label, group := range groups {
+			val, moreDiags := blocksToValue(group, labelIdx+1, ctx)
+			diags = append(diags, moreDiags...)
+			vals[label] = val
+		}
+		return cty.ObjectVal(vals), diags
+	}
+
+	if len(blocks) == 1 {
+		return BodyToValue(blocks[0].Body, ctx)
+	}
+	vals := make([]cty.Value, len(blocks))
+	for i, block := range blocks {
+		val, moreDiags := BodyToValue(block.Body, ctx)
+		diags = append(diags, moreDiags...)
+		vals[i] = val
+	}
+	return cty.TupleVal(vals), diags
+}
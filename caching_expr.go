@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+type cachingExpr struct {
+	wrapped Expression
+
+	mu     sync.Mutex
+	cached bool
+	ctx    *EvalContext
+	val    cty.Value
+	diags  Diagnostics
+}
+
+// NewCachingExpression returns an Expression that wraps the given expression
+// and remembers the result of the most recent call to Value, returning it
+// again without re-evaluating for as long as subsequent calls pass the same
+// *EvalContext.
+//
+// The cache is keyed only by the identity of the context pointer, so callers
+// must not modify the variables or functions of a context (or any of its
+// ancestors) while still using it with a caching expression. Passing a
+// different context, including nil, causes the wrapped expression to be
+// evaluated again and the cached result to be replaced.
+//
+// The Variables, Range and StartRange methods delegate directly to the
+// wrapped expression, and the result can be unwrapped using
+// UnwrapExpression. The returned expression is safe for concurrent use if
+// the wrapped expression is.
+func NewCachingExpression(expr Expression) Expression {
+	return &cachingExpr{wrapped: expr}
+}
+
+func (e *cachingExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cached && e.ctx == ctx {
+		return e.val, e.diags
+	}
+
+	e.val, e.diags = e.wrapped.Value(ctx)
+	e.ctx = ctx
+	e.cached = true
+	return e.val, e.diags
+}
+
+func (e *cachingExpr) Variables() []Traversal {
+	return e.wrapped.Variables()
+}
+
+func (e *cachingExpr) Range() Range {
+	return e.wrapped.Range()
+}
+
+func (e *cachingExpr) StartRange() Range {
+	return e.wrapped.StartRange()
+}
+
+func (e *cachingExpr) UnwrapExpression() Expression {
+	return e.wrapped
+}
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+type countingExpr struct {
+	staticExpr
+	calls int
+}
+
+func (e *countingExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	e.calls++
+	return e.val, nil
+}
+
+func (e *countingExpr) Variables() []Traversal {
+	return []Traversal{{TraverseRoot{Name: "foo"}}}
+}
+
+func TestNewCachingExpression(t *testing.T) {
+	rng := Range{Filename: "test.hcl", Start: InitialPos, End: Pos{Line: 1, Column: 4, Byte: 3}}
+	inner := &countingExpr{staticExpr: staticExpr{val: cty.StringVal("hello"), rng: rng}}
+	expr := NewCachingExpression(inner)
+
+	ctx1 := &EvalContext{}
+	ctx2 := &EvalContext{}
+	steps := []struct {
+		ctx       *EvalContext
+		wantCalls int
+	}{
+		{ctx1, 1},
+		{ctx1, 1},
+		{ctx2, 2},
+		{ctx2, 2},
+		{ctx1, 3},
+		{nil, 4},
+		{nil, 4},
+	}
+	for i, step := range steps {
+		got, diags := expr.Value(step.ctx)
+		if diags.HasErrors() {
+			t.Fatalf("step %d: unexpected diagnostics: %s", i, diags.Error())
+		}
+		if !got.RawEquals(cty.StringVal("hello")) {
+			t.Errorf("step %d: wrong value %#v", i, got)
+		}
+		if inner.calls != step.wantCalls {
+			t.Errorf("step %d: wrapped expression evaluated %d times; want %d", i, inner.calls, step.wantCalls)
+		}
+	}
+
+	if got := expr.Variables(); len(got) != 1 || got[0].RootName() != "foo" {
+		t.Errorf("wrong variables %#v", got)
+	}
+	if got := expr.Range(); got != rng {
+		t.Errorf("wrong range %#v", got)
+	}
+	if got := UnwrapExpression(expr); got != inner {
+		t.Errorf("wrong unwrapped expression %#v", got)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+type convertExpr struct {
+	wrapped Expression
+	ty      cty.Type
+}
+
+// ConvertExpr returns an Expression that wraps the given expression and
+// converts the result of each call to its Value method to the given type.
+//
+// If the value cannot be converted then the result is an unknown value of
+// the target type along with an error diagnostic whose subject is the range
+// of the wrapped expression. Any diagnostics from evaluating the wrapped
+// expression are also returned, and if they include errors then no
+// conversion is attempted.
+//
+// The Variables, Range and StartRange methods delegate directly to the
+// wrapped expression, and the result can be unwrapped using
+// UnwrapExpression.
+func ConvertExpr(expr Expression, ty cty.Type) Expression {
+	return &convertExpr{wrapped: expr, ty: ty}
+}
+
+func (e *convertExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	val, diags := e.wrapped.Value(ctx)
+	if diags.HasErrors() {
+		return cty.UnknownVal(e.ty.WithoutOptionalAttributesDeep()), diags
+	}
+
+	convVal, err := convert.Convert(val, e.ty)
+	if err != nil {
+		rng := e.wrapped.Range()
+		diags = append(diags, &Diagnostic{
+			Severity:    DiagError,
+			Summary:     "Unsuitable value type",
+			Detail:      fmt.Sprintf("Unsuitable value: %s.", err.Error()),
+			Subject:     &rng,
+			Expression:  e.wrapped,
+			EvalContext: ctx,
+		})
+		return cty.UnknownVal(e.ty.WithoutOptionalAttributesDeep()), diags
+	}
+	return convVal, diags
+}
+
+func (e *convertExpr) Variables() []Traversal {
+	return e.wrapped.Variables()
+}
+
+func (e *convertExpr) Range() Range {
+	return e.wrapped.Range()
+}
+
+func (e *convertExpr) StartRange() Range {
+	return e.wrapped.StartRange()
+}
+
+func (e *convertExpr) UnwrapExpression() Expression {
+	return e.wrapped
+}
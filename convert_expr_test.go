@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestConvertExpr(t *testing.T) {
+	rng := Range{Filename: "test.hcl", Start: InitialPos, End: Pos{Line: 1, Column: 4, Byte: 3}}
+
+	tests := []struct {
+		val      cty.Value
+		ty       cty.Type
+		want     cty.Value
+		wantDiag string
+	}{
+		{
+			cty.NumberIntVal(5),
+			cty.String,
+			cty.StringVal("5"),
+			``,
+		},
+		{
+			cty.StringVal("true"),
+			cty.Bool,
+			cty.True,
+			``,
+		},
+		{
+			cty.NullVal(cty.Number),
+			cty.String,
+			cty.NullVal(cty.String),
+			``,
+		},
+		{
+			cty.UnknownVal(cty.Number),
+			cty.String,
+			cty.UnknownVal(cty.String),
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{cty.StringVal("a")}),
+			cty.String,
+			cty.UnknownVal(cty.String),
+			`test.hcl:1,1-4: Unsuitable value type; Unsuitable value: string required.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.val.GoString(), func(t *testing.T) {
+			expr := ConvertExpr(StaticExpr(test.val, rng), test.ty)
+			got, diags := expr.Value(nil)
+			if test.wantDiag == "" {
+				if len(diags) != 0 {
+					t.Fatalf("unexpected diagnostics: %s", diags.Error())
+				}
+			} else if got := diags.Error(); got != test.wantDiag {
+				t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", got, test.wantDiag)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	t.Run("delegation", func(t *testing.T) {
+		inner := &countingExpr{staticExpr: staticExpr{val: cty.StringVal("hello"), rng: rng}}
+		expr := ConvertExpr(inner, cty.String)
+		if got := expr.Variables(); len(got) != 1 || got[0].RootName() != "foo" {
+			t.Errorf("wrong variables %#v", got)
+		}
+		if got := expr.Range(); got != rng {
+			t.Errorf("wrong range %#v", got)
+		}
+		if got := expr.StartRange(); got != rng {
+			t.Errorf("wrong start range %#v", got)
+		}
+		if got := UnwrapExpression(expr); got != inner {
+			t.Errorf("wrong unwrapped expression %#v", got)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// DecodeBodyTyped is like calling Content on the given body with the given
+// schema, but additionally evaluates the expression of each attribute in the
+// schema and returns the results as a map keyed by attribute name.
+//
+// If an attribute schema has a Type other than cty.NilType then the
+// attribute's value is converted to that type, and a failure to convert it
+// is reported as an error diagnostic on the attribute's value. The result
+// includes every attribute in the schema, with those absent from the body
+// represented as null values of their declared types, or of
+// cty.DynamicPseudoType if no type is declared.
+//
+// The returned content can be used to access the blocks in the body.
+func DecodeBodyTyped(body Body, schema *BodySchema, ctx *EvalContext) (map[string]cty.Value, *BodyContent, Diagnostics) {
+	content, diags := body.Content(schema)
+	if content == nil {
+		return nil, nil, diags
+	}
+
+	vals := make(map[string]cty.Value, len(schema.Attributes))
+	for _, attrS := range schema.Attributes {
+		ty := attrS.Type
+		if ty == cty.NilType {
+			ty = cty.DynamicPseudoType
+		}
+
+		attr, exists := content.Attributes[attrS.Name]
+		if !exists {
+			vals[attrS.Name] = cty.NullVal(ty.WithoutOptionalAttributesDeep())
+			continue
+		}
+
+		val, valDiags := attr.Expr.Value(ctx)
+		diags = append(diags, valDiags...)
+
+		convVal, err := convert.Convert(val, ty)
+		if err != nil {
+			diags = append(diags, &Diagnostic{
+				Severity: DiagError,
+				Summary:  "Incorrect attribute value type",
+				Detail: fmt.Sprintf(
+					"Inappropriate value for attribute %q: %s.",
+					attrS.Name, err.Error(),
+				),
+				Subject:     attr.Expr.Range().Ptr(),
+				Context:     RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+				Expression:  attr.Expr,
+				EvalContext: ctx,
+			})
+			// Return an unknown value of the correct type so that the
+			// result can still be used for some partial analysis.
+			convVal = cty.UnknownVal(ty.WithoutOptionalAttributesDeep())
+		}
+		vals[attrS.Name] = convVal
+	}
+
+	return vals, content, diags
+}
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeBodyTyped(t *testing.T) {
+	schema := &BodySchema{
+		Attributes: []AttributeSchema{
+			{Name: "count", Type: cty.Number},
+			{Name: "names", Type: cty.List(cty.String)},
+			{Name: "raw"},
+			{Name: "missing", Type: cty.Bool},
+		},
+	}
+
+	tests := map[string]struct {
+		attrs     map[string]cty.Value
+		want      map[string]cty.Value
+		diagCount int
+	}{
+		"converted": {
+			map[string]cty.Value{
+				"count": cty.StringVal("5"),
+				"names": cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.True}),
+				"raw":   cty.True,
+			},
+			map[string]cty.Value{
+				"count":   cty.NumberIntVal(5),
+				"names":   cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("true")}),
+				"raw":     cty.True,
+				"missing": cty.NullVal(cty.Bool),
+			},
+			0,
+		},
+		"absent": {
+			map[string]cty.Value{},
+			map[string]cty.Value{
+				"count":   cty.NullVal(cty.Number),
+				"names":   cty.NullVal(cty.List(cty.String)),
+				"raw":     cty.NullVal(cty.DynamicPseudoType),
+				"missing": cty.NullVal(cty.Bool),
+			},
+			0,
+		},
+		"invalid": {
+			map[string]cty.Value{
+				"count": cty.StringVal("many"),
+			},
+			map[string]cty.Value{
+				"count":   cty.UnknownVal(cty.Number),
+				"names":   cty.NullVal(cty.List(cty.String)),
+				"raw":     cty.NullVal(cty.DynamicPseudoType),
+				"missing": cty.NullVal(cty.Bool),
+			},
+			1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := testDecodeTypedBody(test.attrs)
+			got, content, diags := DecodeBodyTyped(body, schema, nil)
+
+			if len(diags) != test.diagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.diagCount)
+				for _, diag := range diags {
+					t.Logf("- %s", diag)
+				}
+			}
+			for _, diag := range diags {
+				if diag.Summary != "Incorrect attribute value type" {
+					t.Errorf("wrong diagnostic summary %q", diag.Summary)
+				}
+				want := test.attrs["count"]
+				if got := diag.Subject.Filename; got != want.AsString() {
+					t.Errorf("diagnostic is not on the attribute's value: %s", diag.Subject)
+				}
+			}
+			if content == nil {
+				t.Fatalf("content is nil")
+			}
+
+			if len(got) != len(test.want) {
+				t.Errorf("wrong number of values %d; want %d", len(got), len(test.want))
+			}
+			for k, want := range test.want {
+				if !got[k].RawEquals(want) {
+					t.Errorf("wrong value for %q\ngot:  %#v\nwant: %#v", k, got[k], want)
+				}
+			}
+		})
+	}
+}
+
+// testDecodeTypedBody is a body containing only the given attributes. Each
+// attribute's expression has a range whose filename is the attribute's
+// value, if that value is a string, so that tests can check which
+// expression a diagnostic refers to.
+type testDecodeTypedBody map[string]cty.Value
+
+func (b testDecodeTypedBody) Content(schema *BodySchema) (*BodyContent, Diagnostics) {
+	content, _, diags := b.PartialContent(schema)
+	return content, diags
+}
+
+func (b testDecodeTypedBody) PartialContent(schema *BodySchema) (*BodyContent, Body, Diagnostics) {
+	attrs, diags := b.JustAttributes()
+	content := &BodyContent{
+		Attributes: Attributes{},
+	}
+	for _, attrS := range schema.Attributes {
+		if attr, ok := attrs[attrS.Name]; ok {
+			content.Attributes[attrS.Name] = attr
+		}
+	}
+	return content, EmptyBody(), diags
+}
+
+func (b testDecodeTypedBody) JustAttributes() (Attributes, Diagnostics) {
+	attrs := Attributes{}
+	for name, val := range b {
+		var rng Range
+		if val.Type() == cty.String {
+			rng.Filename = val.AsString()
+		}
+		attrs[name] = &Attribute{
+			Name: name,
+			Expr: StaticExpr(val, rng),
+		}
+	}
+	return attrs, nil
+}
+
+func (b testDecodeTypedBody) MissingItemRange() Range {
+	return Range{}
+}
@@ -5,6 +5,7 @@ package hcl
 
 import (
 	"fmt"
+	"sort"
 )
 
 // DiagnosticSeverity represents the severity of a diagnostic.
@@ -161,6 +162,84 @@ func (d Diagnostics) Errs() []error {
 	return errs
 }
 
+// Filter returns a new Diagnostics containing only the diagnostics from the
+// receiver that have the given severity, in their original order. The
+// receiver is not modified.
+func (d Diagnostics) Filter(severity DiagnosticSeverity) Diagnostics {
+	var ret Diagnostics
+	for _, diag := range d {
+		if diag.Severity == severity {
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
+// SortByRange returns a new Diagnostics containing the same diagnostics as
+// the receiver, ordered by the filename and then the start byte offset of
+// their Subject ranges. Diagnostics with no Subject are placed last.
+// Diagnostics that compare equal retain their original relative order. The
+// receiver is not modified.
+func (d Diagnostics) SortByRange() Diagnostics {
+	if d == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(d))
+	copy(ret, d)
+	sort.SliceStable(ret, func(i, j int) bool {
+		si, sj := ret[i].Subject, ret[j].Subject
+		switch {
+		case si == nil || sj == nil:
+			return si != nil && sj == nil
+		case si.Filename != sj.Filename:
+			return si.Filename < sj.Filename
+		default:
+			return si.Start.Byte < sj.Start.Byte
+		}
+	})
+	return ret
+}
+
+// Dedupe returns a new Diagnostics containing the diagnostics from the
+// receiver with any duplicates removed, keeping the first occurrence of each
+// in its original order. The receiver is not modified.
+//
+// Two diagnostics are duplicates if they have the same severity, summary and
+// detail, and either both have no Subject or both have equal Subject ranges.
+// The other fields, such as Context and Extra, are not considered, so the
+// diagnostic that is kept retains its own values for them.
+func (d Diagnostics) Dedupe() Diagnostics {
+	if d == nil {
+		return nil
+	}
+	type diagKey struct {
+		severity   DiagnosticSeverity
+		summary    string
+		detail     string
+		hasSubject bool
+		subject    Range
+	}
+	seen := make(map[diagKey]struct{}, len(d))
+	ret := make(Diagnostics, 0, len(d))
+	for _, diag := range d {
+		key := diagKey{
+			severity: diag.Severity,
+			summary:  diag.Summary,
+			detail:   diag.Detail,
+		}
+		if diag.Subject != nil {
+			key.hasSubject = true
+			key.subject = *diag.Subject
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
 // A DiagnosticWriter emits diagnostics somehow.
 type DiagnosticWriter interface {
 	WriteDiagnostic(*Diagnostic) error
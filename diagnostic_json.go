@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"encoding/json"
+)
+
+// DiagnosticsJSONFormatVersion is the version of the JSON format produced by
+// MarshalDiagnosticsJSON. The minor version is incremented for backward
+// compatible additions, such as new properties, and the major version for
+// any other change.
+const DiagnosticsJSONFormatVersion = "1.0"
+
+type diagnosticsJSON struct {
+	FormatVersion string           `json:"format_version"`
+	Diagnostics   []diagnosticJSON `json:"diagnostics"`
+}
+
+type diagnosticJSON struct {
+	Severity string     `json:"severity"`
+	Summary  string     `json:"summary"`
+	Detail   string     `json:"detail"`
+	Subject  *rangeJSON `json:"subject,omitempty"`
+	Context  *rangeJSON `json:"context,omitempty"`
+}
+
+type rangeJSON struct {
+	Filename string  `json:"filename"`
+	Start    posJSON `json:"start"`
+	End      posJSON `json:"end"`
+}
+
+type posJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// MarshalDiagnosticsJSON returns a machine-readable JSON representation of
+// the given diagnostics, intended for consumption by other tools.
+//
+// The result is an object with a "format_version" property, whose value is
+// DiagnosticsJSONFormatVersion, and a "diagnostics" property, whose value is
+// an array of objects with the properties "severity" ("error" or "warning"),
+// "summary", "detail" and, when available, "subject" and "context". Ranges
+// are objects with the properties "filename", "start" and "end", where the
+// latter two are objects with the properties "line", "column" and "byte".
+//
+// The diagnostics are ordered as by Diagnostics.SortByRange, so that the
+// output is the same for the same set of diagnostics regardless of the order
+// they were produced in. This makes the result suitable for comparing
+// between runs.
+func MarshalDiagnosticsJSON(diags Diagnostics) ([]byte, error) {
+	ret := diagnosticsJSON{
+		FormatVersion: DiagnosticsJSONFormatVersion,
+		Diagnostics:   make([]diagnosticJSON, 0, len(diags)),
+	}
+
+	for _, diag := range diags.SortByRange() {
+		ret.Diagnostics = append(ret.Diagnostics, diagnosticJSON{
+			Severity: diagnosticSeverityJSON(diag.Severity),
+			Summary:  diag.Summary,
+			Detail:   diag.Detail,
+			Subject:  newRangeJSON(diag.Subject),
+			Context:  newRangeJSON(diag.Context),
+		})
+	}
+
+	return json.Marshal(ret)
+}
+
+func diagnosticSeverityJSON(severity DiagnosticSeverity) string {
+	switch severity {
+	case DiagError:
+		return "error"
+	case DiagWarning:
+		return "warning"
+	default:
+		return "invalid"
+	}
+}
+
+func newRangeJSON(rng *Range) *rangeJSON {
+	if rng == nil {
+		return nil
+	}
+	return &rangeJSON{
+		Filename: rng.Filename,
+		Start:    posJSON{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:      posJSON{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+)
+
+func TestMarshalDiagnosticsJSON(t *testing.T) {
+	diags := Diagnostics{
+		{
+			Severity: DiagWarning,
+			Summary:  "No subject",
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Later",
+			Detail:   "Second in file.",
+			Subject: &Range{
+				Filename: "a.hcl",
+				Start:    Pos{Line: 2, Column: 1, Byte: 10},
+				End:      Pos{Line: 2, Column: 4, Byte: 13},
+			},
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Earlier",
+			Detail:   "First in file.",
+			Subject: &Range{
+				Filename: "a.hcl",
+				Start:    Pos{Line: 1, Column: 1, Byte: 0},
+				End:      Pos{Line: 1, Column: 2, Byte: 1},
+			},
+			Context: &Range{
+				Filename: "a.hcl",
+				Start:    Pos{Line: 1, Column: 1, Byte: 0},
+				End:      Pos{Line: 1, Column: 6, Byte: 5},
+			},
+		},
+	}
+
+	got, err := MarshalDiagnosticsJSON(diags)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `{"format_version":"1.0","diagnostics":[` +
+		`{"severity":"error","summary":"Earlier","detail":"First in file.",` +
+		`"subject":{"filename":"a.hcl","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":2,"byte":1}},` +
+		`"context":{"filename":"a.hcl","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":6,"byte":5}}},` +
+		`{"severity":"error","summary":"Later","detail":"Second in file.",` +
+		`"subject":{"filename":"a.hcl","start":{"line":2,"column":1,"byte":10},"end":{"line":2,"column":4,"byte":13}}},` +
+		`{"severity":"warning","summary":"No subject","detail":""}` +
+		`]}`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+
+	got, err = MarshalDiagnosticsJSON(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `{"format_version":"1.0","diagnostics":[]}`; string(got) != want {
+		t.Errorf("wrong result for no diagnostics\ngot:  %s\nwant: %s", got, want)
+	}
+}
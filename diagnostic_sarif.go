@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+// DiagnosticExtraRuleID is an interface that may be implemented by the Extra
+// value of a diagnostic to give an identifier for the kind of problem it
+// describes, for use as the rule id when the diagnostic is serialized with
+// MarshalDiagnosticsSARIF.
+//
+// DiagnosticRuleID may return an empty string to indicate dynamically that
+// no identifier is available, in which case the default is used.
+type DiagnosticExtraRuleID interface {
+	DiagnosticRuleID() string
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// MarshalDiagnosticsSARIF returns a representation of the given diagnostics
+// as a SARIF 2.1.0 log, for consumption by code scanning tools. The log
+// contains a single run whose tool has the given name.
+//
+// Each diagnostic becomes a result whose level is "error" or "warning"
+// according to its severity and whose message is its summary followed by its
+// detail. If the diagnostic has a subject then it becomes the result's
+// location, with the subject's filename as the artifact URI.
+//
+// The rule id of each result is given by the diagnostic's Extra value if it
+// implements DiagnosticExtraRuleID, or is defaultRuleID otherwise. Each
+// distinct rule id is described in the tool's rules by the summary of the
+// first diagnostic that uses it.
+//
+// As with MarshalDiagnosticsJSON, the diagnostics are ordered as by
+// Diagnostics.SortByRange.
+func MarshalDiagnosticsSARIF(diags Diagnostics, toolName string, defaultRuleID string) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:  toolName,
+				Rules: []sarifRule{},
+			},
+		},
+		// HCL columns count characters rather than UTF-16 code units,
+		// which is the SARIF default.
+		ColumnKind: "unicodeCodePoints",
+		Results:    make([]sarifResult, 0, len(diags)),
+	}
+
+	seenRules := make(map[string]struct{})
+	for _, diag := range diags.SortByRange() {
+		ruleID := diagnosticRuleID(diag)
+		if ruleID == "" {
+			ruleID = defaultRuleID
+		}
+		if _, seen := seenRules[ruleID]; !seen {
+			seenRules[ruleID] = struct{}{}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: diag.Summary},
+			})
+		}
+
+		text := diag.Summary
+		if diag.Detail != "" {
+			text += "\n\n" + diag.Detail
+		}
+		result := sarifResult{
+			RuleID:  ruleID,
+			Level:   diagnosticSeveritySARIF(diag.Severity),
+			Message: sarifMessage{Text: text},
+		}
+		if rng := diag.Subject; rng != nil {
+			result.Locations = []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{
+							URI: filepath.ToSlash(rng.Filename),
+						},
+						Region: sarifRegion{
+							StartLine:   rng.Start.Line,
+							StartColumn: rng.Start.Column,
+							EndLine:     rng.End.Line,
+							EndColumn:   rng.End.Column,
+						},
+					},
+				},
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return json.Marshal(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// diagnosticRuleID returns the rule id given by the diagnostic's Extra value,
+// or an empty string if it doesn't have one.
+func diagnosticRuleID(diag *Diagnostic) string {
+	extra := diag.Extra
+	for extra != nil {
+		if withID, ok := extra.(DiagnosticExtraRuleID); ok {
+			return withID.DiagnosticRuleID()
+		}
+		unwrap, ok := extra.(DiagnosticExtraUnwrapper)
+		if !ok {
+			break
+		}
+		extra = unwrap.UnwrapDiagnosticExtra()
+	}
+	return ""
+}
+
+func diagnosticSeveritySARIF(severity DiagnosticSeverity) string {
+	switch severity {
+	case DiagError:
+		return "error"
+	case DiagWarning:
+		return "warning"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+)
+
+type testDiagnosticRuleID string
+
+func (id testDiagnosticRuleID) DiagnosticRuleID() string {
+	return string(id)
+}
+
+type testDiagnosticExtraWrapper struct {
+	wrapped interface{}
+}
+
+func (w testDiagnosticExtraWrapper) UnwrapDiagnosticExtra() interface{} {
+	return w.wrapped
+}
+
+func TestMarshalDiagnosticsSARIF(t *testing.T) {
+	diags := Diagnostics{
+		{
+			Severity: DiagWarning,
+			Summary:  "No subject",
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Later",
+			Detail:   "Second in file.",
+			Subject: &Range{
+				Filename: "a.hcl",
+				Start:    Pos{Line: 2, Column: 1, Byte: 10},
+				End:      Pos{Line: 2, Column: 4, Byte: 13},
+			},
+			Extra: testDiagnosticExtraWrapper{testDiagnosticRuleID("HCL002")},
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Earlier",
+			Detail:   "First in file.",
+			Subject: &Range{
+				Filename: "a.hcl",
+				Start:    Pos{Line: 1, Column: 1, Byte: 0},
+				End:      Pos{Line: 1, Column: 2, Byte: 1},
+			},
+		},
+	}
+
+	got, err := MarshalDiagnosticsSARIF(diags, "example", "HCL001")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `{"version":"2.1.0","$schema":"https://json.schemastore.org/sarif-2.1.0.json","runs":[{` +
+		`"tool":{"driver":{"name":"example","rules":[` +
+		`{"id":"HCL001","shortDescription":{"text":"Earlier"}},` +
+		`{"id":"HCL002","shortDescription":{"text":"Later"}}` +
+		`]}},` +
+		`"columnKind":"unicodeCodePoints",` +
+		`"results":[` +
+		`{"ruleId":"HCL001","level":"error","message":{"text":"Earlier\n\nFirst in file."},` +
+		`"locations":[{"physicalLocation":{"artifactLocation":{"uri":"a.hcl"},"region":{"startLine":1,"startColumn":1,"endLine":1,"endColumn":2}}}]},` +
+		`{"ruleId":"HCL002","level":"error","message":{"text":"Later\n\nSecond in file."},` +
+		`"locations":[{"physicalLocation":{"artifactLocation":{"uri":"a.hcl"},"region":{"startLine":2,"startColumn":1,"endLine":2,"endColumn":4}}}]},` +
+		`{"ruleId":"HCL001","level":"warning","message":{"text":"No subject"}}` +
+		`]}]}`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+
+	got, err = MarshalDiagnosticsSARIF(nil, "example", "HCL001")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want = `{"version":"2.1.0","$schema":"https://json.schemastore.org/sarif-2.1.0.json","runs":[{` +
+		`"tool":{"driver":{"name":"example","rules":[]}},"columnKind":"unicodeCodePoints","results":[]}]}`
+	if string(got) != want {
+		t.Errorf("wrong result for no diagnostics\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsFilter(t *testing.T) {
+	err1 := &Diagnostic{Severity: DiagError, Summary: "err1"}
+	warn1 := &Diagnostic{Severity: DiagWarning, Summary: "warn1"}
+	err2 := &Diagnostic{Severity: DiagError, Summary: "err2"}
+	diags := Diagnostics{err1, warn1, err2}
+
+	if got, want := diags.Filter(DiagError), (Diagnostics{err1, err2}); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong errors\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := diags.Filter(DiagWarning), (Diagnostics{warn1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong warnings\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := diags.Filter(DiagInvalid); got != nil {
+		t.Errorf("wrong result for unused severity\ngot:  %#v\nwant: nil", got)
+	}
+	if got, want := diags, (Diagnostics{err1, warn1, err2}); !reflect.DeepEqual(got, want) {
+		t.Errorf("receiver was modified\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDiagnosticsSortByRange(t *testing.T) {
+	diag := func(summary, filename string, byteOffset int) *Diagnostic {
+		return &Diagnostic{
+			Severity: DiagError,
+			Summary:  summary,
+			Subject: &Range{
+				Filename: filename,
+				Start:    Pos{Byte: byteOffset},
+				End:      Pos{Byte: byteOffset + 1},
+			},
+		}
+	}
+	noSubject1 := &Diagnostic{Severity: DiagError, Summary: "no subject 1"}
+	noSubject2 := &Diagnostic{Severity: DiagWarning, Summary: "no subject 2"}
+	b10 := diag("b10", "b.hcl", 10)
+	a20 := diag("a20", "a.hcl", 20)
+	a5 := diag("a5", "a.hcl", 5)
+	b2 := diag("b2", "b.hcl", 2)
+	a5again := diag("a5 again", "a.hcl", 5)
+
+	diags := Diagnostics{noSubject1, b10, a20, noSubject2, a5, b2, a5again}
+	got := diags.SortByRange()
+	want := Diagnostics{a5, a5again, a20, b2, b10, noSubject1, noSubject2}
+	if !reflect.DeepEqual(got, want) {
+		var gotNames, wantNames []string
+		for _, d := range got {
+			gotNames = append(gotNames, d.Summary)
+		}
+		for _, d := range want {
+			wantNames = append(wantNames, d.Summary)
+		}
+		t.Errorf("wrong order\ngot:  %q\nwant: %q", gotNames, wantNames)
+	}
+	if got, want := diags, (Diagnostics{noSubject1, b10, a20, noSubject2, a5, b2, a5again}); !reflect.DeepEqual(got, want) {
+		t.Errorf("receiver was modified")
+	}
+
+	if got := Diagnostics(nil).SortByRange(); got != nil {
+		t.Errorf("wrong result for nil diagnostics: %#v", got)
+	}
+}
+
+func TestDiagnosticsDedupe(t *testing.T) {
+	rng := func(start int) *Range {
+		return &Range{
+			Filename: "test.hcl",
+			Start:    Pos{Line: 1, Column: start + 1, Byte: start},
+			End:      Pos{Line: 1, Column: start + 2, Byte: start + 1},
+		}
+	}
+	err1 := &Diagnostic{Severity: DiagError, Summary: "err", Detail: "detail", Subject: rng(0)}
+	err1again := &Diagnostic{Severity: DiagError, Summary: "err", Detail: "detail", Subject: rng(0), Context: rng(5)}
+	err1warn := &Diagnostic{Severity: DiagWarning, Summary: "err", Detail: "detail", Subject: rng(0)}
+	err1elsewhere := &Diagnostic{Severity: DiagError, Summary: "err", Detail: "detail", Subject: rng(1)}
+	err1detail := &Diagnostic{Severity: DiagError, Summary: "err", Detail: "other", Subject: rng(0)}
+	noSubject := &Diagnostic{Severity: DiagError, Summary: "err", Detail: "detail"}
+	noSubjectAgain := &Diagnostic{Severity: DiagError, Summary: "err", Detail: "detail"}
+
+	diags := Diagnostics{err1, noSubject, err1again, err1warn, err1elsewhere, noSubjectAgain, err1detail, err1}
+	got := diags.Dedupe()
+	want := Diagnostics{err1, noSubject, err1warn, err1elsewhere, err1detail}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := diags, (Diagnostics{err1, noSubject, err1again, err1warn, err1elsewhere, noSubjectAgain, err1detail, err1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("receiver was modified")
+	}
+
+	if got := Diagnostics(nil).Dedupe(); got != nil {
+		t.Errorf("wrong result for nil diagnostics: %#v", got)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// BatchResult is the result of evaluating an expression with one of the sets
+// of variables given to EvaluateBatch.
+type BatchResult struct {
+	Value       cty.Value
+	Diagnostics Diagnostics
+}
+
+// EvaluateBatch evaluates the given expression once for each of the given
+// maps of variables, such as for rendering a template for each row of some
+// tabular data, and returns the results in the same order.
+//
+// Each evaluation uses a child of the given base context whose variables are
+// the corresponding map, so the functions and any other variables of the
+// base context are shared by all of them. The base context may be nil.
+//
+// The variables that the expression refers to are determined only once. If
+// the expression doesn't refer to any of the variables in a particular map
+// then its result doesn't depend on that map, and so the expression is
+// evaluated only once with the base context for all such maps, which then
+// share the same result.
+func EvaluateBatch(expr Expression, base *EvalContext, rows []map[string]cty.Value) []BatchResult {
+	rootNames := make(map[string]struct{})
+	for _, traversal := range expr.Variables() {
+		rootNames[traversal.RootName()] = struct{}{}
+	}
+
+	ret := make([]BatchResult, len(rows))
+	var shared *BatchResult
+	for i, row := range rows {
+		if !rowDefinesAny(row, rootNames) {
+			if shared == nil {
+				val, diags := expr.Value(base)
+				shared = &BatchResult{Value: val, Diagnostics: diags}
+			}
+			ret[i] = *shared
+			continue
+		}
+
+		ctx := base.NewChild()
+		ctx.Variables = row
+		val, diags := expr.Value(ctx)
+		ret[i] = BatchResult{Value: val, Diagnostics: diags}
+	}
+	return ret
+}
+
+// EvaluateIterations evaluates each of the given named expressions once for
+// each of the given iterations, such as for evaluating the attributes of a
+// block that is repeated once for each element of a collection, and returns
+// the values keyed first by the iteration key and then by expression name.
+//
+// Each iteration is given as a map of the variables that are specific to
+// it, such as an "each" object with "key" and "value" attributes or a
+// "count" object with an "index" attribute. The expressions of an iteration
+// are evaluated in a child of the base context created by MergeContexts, so
+// the iteration variables shadow any variables of the same name in the base
+// context only for that iteration, and are no longer visible once the call
+// returns. The base context is not modified, and may be nil.
+//
+// The diagnostics from all of the evaluations are returned together, with
+// the iterations in lexical order of their keys and the expressions of each
+// iteration in lexical order of their names. Each diagnostic from an
+// evaluation refers to the child context of its iteration, so that a caller
+// can determine which iteration it belongs to.
+func EvaluateIterations(exprs map[string]Expression, base *EvalContext, iterations map[string]map[string]cty.Value) (map[string]map[string]cty.Value, Diagnostics) {
+	var diags Diagnostics
+	ret := make(map[string]map[string]cty.Value, len(iterations))
+	for _, key := range sortedMapKeys(iterations) {
+		ctx := MergeContexts(base, iterations[key])
+		vals := make(map[string]cty.Value, len(exprs))
+		for _, name := range sortedMapKeys(exprs) {
+			val, valDiags := exprs[name].Value(ctx)
+			diags = append(diags, valDiags...)
+			vals[name] = val
+		}
+		ret[key] = vals
+	}
+	return ret, diags
+}
+
+// sortedMapKeys returns the keys of the given map in lexical order.
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func rowDefinesAny(row map[string]cty.Value, names map[string]struct{}) bool {
+	for name := range names {
+		if _, exists := row[name]; exists {
+			return true
+		}
+	}
+	return false
+}
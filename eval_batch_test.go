@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+type traversalTestExpr struct {
+	staticExpr
+	traversal Traversal
+	calls     int
+}
+
+func (e *traversalTestExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	e.calls++
+	if e.traversal == nil {
+		return e.val, nil
+	}
+	return e.traversal.TraverseAbs(ctx)
+}
+
+func (e *traversalTestExpr) Variables() []Traversal {
+	if e.traversal == nil {
+		return nil
+	}
+	return []Traversal{e.traversal}
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"greeting": cty.StringVal("hello"),
+		},
+	}
+	rows := []map[string]cty.Value{
+		{"name": cty.StringVal("a")},
+		{"name": cty.StringVal("b")},
+		{"other": cty.StringVal("c")},
+		{"other": cty.StringVal("d")},
+	}
+
+	t.Run("row variable", func(t *testing.T) {
+		expr := &traversalTestExpr{traversal: Traversal{TraverseRoot{Name: "name"}}}
+		got := EvaluateBatch(expr, base, rows)
+		if len(got) != len(rows) {
+			t.Fatalf("wrong number of results %d; want %d", len(got), len(rows))
+		}
+		for i, want := range []string{"a", "b"} {
+			if got[i].Diagnostics.HasErrors() {
+				t.Errorf("row %d: unexpected diagnostics: %s", i, got[i].Diagnostics.Error())
+			}
+			if !got[i].Value.RawEquals(cty.StringVal(want)) {
+				t.Errorf("row %d: wrong value %#v", i, got[i].Value)
+			}
+		}
+		for i := 2; i < len(rows); i++ {
+			if !got[i].Diagnostics.HasErrors() {
+				t.Errorf("row %d: unexpected success", i)
+			}
+		}
+		if expr.calls != 3 {
+			t.Errorf("expression evaluated %d times; want 3", expr.calls)
+		}
+	})
+
+	t.Run("base variable", func(t *testing.T) {
+		expr := &traversalTestExpr{traversal: Traversal{TraverseRoot{Name: "greeting"}}}
+		got := EvaluateBatch(expr, base, rows)
+		for i, result := range got {
+			if result.Diagnostics.HasErrors() {
+				t.Errorf("row %d: unexpected diagnostics: %s", i, result.Diagnostics.Error())
+			}
+			if !result.Value.RawEquals(cty.StringVal("hello")) {
+				t.Errorf("row %d: wrong value %#v", i, result.Value)
+			}
+		}
+		if expr.calls != 1 {
+			t.Errorf("expression evaluated %d times; want 1", expr.calls)
+		}
+	})
+
+	t.Run("row overrides base", func(t *testing.T) {
+		expr := &traversalTestExpr{traversal: Traversal{TraverseRoot{Name: "greeting"}}}
+		got := EvaluateBatch(expr, base, []map[string]cty.Value{
+			{"greeting": cty.StringVal("hi")},
+			{},
+		})
+		if !got[0].Value.RawEquals(cty.StringVal("hi")) {
+			t.Errorf("wrong value for first row %#v", got[0].Value)
+		}
+		if !got[1].Value.RawEquals(cty.StringVal("hello")) {
+			t.Errorf("wrong value for second row %#v", got[1].Value)
+		}
+	})
+
+	t.Run("nil base", func(t *testing.T) {
+		expr := &traversalTestExpr{staticExpr: staticExpr{val: cty.True}}
+		got := EvaluateBatch(expr, nil, rows)
+		for i, result := range got {
+			if !result.Value.RawEquals(cty.True) {
+				t.Errorf("row %d: wrong value %#v", i, result.Value)
+			}
+		}
+		if expr.calls != 1 {
+			t.Errorf("expression evaluated %d times; want 1", expr.calls)
+		}
+	})
+}
+
+func TestEvaluateIterations(t *testing.T) {
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"greeting": cty.StringVal("hello"),
+			"each":     cty.StringVal("shadowed"),
+		},
+	}
+	each := func(key string, value cty.Value) map[string]cty.Value {
+		return map[string]cty.Value{
+			"each": cty.ObjectVal(map[string]cty.Value{
+				"key":   cty.StringVal(key),
+				"value": value,
+			}),
+		}
+	}
+	iterations := map[string]map[string]cty.Value{
+		"a": each("a", cty.NumberIntVal(1)),
+		"b": each("b", cty.NumberIntVal(2)),
+		"c": {}, // no "each" variable, so each.key is invalid
+	}
+	exprs := map[string]Expression{
+		"key": &traversalTestExpr{traversal: Traversal{
+			TraverseRoot{Name: "each"},
+			TraverseAttr{Name: "key"},
+		}},
+		"value": &traversalTestExpr{traversal: Traversal{
+			TraverseRoot{Name: "each"},
+			TraverseAttr{Name: "value"},
+		}},
+		"greeting": &traversalTestExpr{traversal: Traversal{
+			TraverseRoot{Name: "greeting"},
+		}},
+	}
+
+	got, diags := EvaluateIterations(exprs, base, iterations)
+	for _, key := range []string{"a", "b"} {
+		if !got[key]["key"].RawEquals(cty.StringVal(key)) {
+			t.Errorf("wrong key for %s: %#v", key, got[key]["key"])
+		}
+		if !got[key]["greeting"].RawEquals(cty.StringVal("hello")) {
+			t.Errorf("wrong greeting for %s: %#v", key, got[key]["greeting"])
+		}
+	}
+	if !got["b"]["value"].RawEquals(cty.NumberIntVal(2)) {
+		t.Errorf("wrong value for b: %#v", got["b"]["value"])
+	}
+
+	// Only the iteration without "each" has errors, where each.key and
+	// each.value refer to the base context's variable.
+	if got, want := len(diags), 2; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d: %s", got, want, diags.Error())
+	}
+	for _, diag := range diags {
+		if diag.Summary != "Unsupported attribute" {
+			t.Errorf("wrong diagnostic: %s", diag.Error())
+		}
+	}
+	if _, exists := base.Variables["key"]; exists || len(base.Variables) != 2 {
+		t.Errorf("base context was modified: %#v", base.Variables)
+	}
+}
@@ -4,6 +4,12 @@
 package hcl
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 )
@@ -13,12 +19,64 @@ import (
 type EvalContext struct {
 	Variables map[string]cty.Value
 	Functions map[string]function.Function
-	parent    *EvalContext
+
+	// VariableResolver, if non-nil, is called to look up a root variable
+	// name that is not present in Variables, allowing the application to
+	// produce variable values on demand rather than populating the whole
+	// map in advance. The second return value must be false if there is no
+	// variable of the given name, in which case lookup continues in the
+	// parent context.
+	//
+	// Because a child context is consulted before its parent, a resolver
+	// in a child context takes precedence over both the variables and the
+	// resolver of its parent. A child with no resolver of its own inherits
+	// its parent's resolver in the usual way.
+	VariableResolver func(name string) (cty.Value, bool)
+
+	// ValueFormatter, if non-nil, is used by FormatValue in place of the
+	// default formatting when an evaluator includes a value from the
+	// configuration in the detail message of a diagnostic. An application
+	// can use this to redact sensitive values from error messages, such as
+	// by checking for particular marks. A child context with no formatter
+	// of its own uses the formatter of its parent.
+	ValueFormatter func(cty.Value) string
+
+	// FunctionPolicy, if non-nil, is called by evaluators with the name of
+	// each function that an expression tries to call, before looking for
+	// the function. If it returns an error then the call is rejected with
+	// an error diagnostic that includes the error message, regardless of
+	// whether the function is available, so an application that evaluates
+	// untrusted expressions can enforce which functions they may call even
+	// if other functions are present in the context, and can explain why a
+	// call isn't allowed. AllowedFunctions returns a suitable policy for a
+	// fixed set of functions. A child context with no policy of its own
+	// uses the policy of its parent.
+	FunctionPolicy func(name string) error
+
+	parent *EvalContext
+
+	// goCtx and tracer are copied into each child when it is created, rather
+	// than found by searching the ancestors, so that evaluators can check
+	// for them cheaply before evaluating each expression.
+	goCtx  context.Context
+	tracer EvalTracer
+
+	// exhaustiveDiags and strictTemplates are copied into each child in the
+	// same way as goCtx and tracer.
+	exhaustiveDiags bool
+	strictTemplates bool
 }
 
 // NewChild returns a new EvalContext that is a child of the receiver.
 func (ctx *EvalContext) NewChild() *EvalContext {
-	return &EvalContext{parent: ctx}
+	ret := &EvalContext{parent: ctx}
+	if ctx != nil {
+		ret.goCtx = ctx.goCtx
+		ret.tracer = ctx.tracer
+		ret.exhaustiveDiags = ctx.exhaustiveDiags
+		ret.strictTemplates = ctx.strictTemplates
+	}
+	return ret
 }
 
 // Parent returns the parent of the receiver, or nil if the receiver has
@@ -26,3 +84,373 @@ func (ctx *EvalContext) NewChild() *EvalContext {
 func (ctx *EvalContext) Parent() *EvalContext {
 	return ctx.parent
 }
+
+// WithContext returns a new child of the receiver that carries the given
+// context.Context, which evaluators that support cooperative cancellation
+// can check between evaluation steps using Context. The receiver is not
+// modified.
+func (ctx *EvalContext) WithContext(goCtx context.Context) *EvalContext {
+	ret := ctx.NewChild()
+	ret.goCtx = goCtx
+	return ret
+}
+
+// Context returns the context.Context most recently attached to the receiver
+// or one of its ancestors using WithContext, or nil if there is none. The
+// receiver may be nil, in which case the result is always nil.
+func (ctx *EvalContext) Context() context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.goCtx
+}
+
+// WithTracer returns a new child of the receiver that carries the given
+// tracer, which evaluators that support tracing notify as they evaluate each
+// expression using that child or any of its descendents. The receiver is not
+// modified. Passing a nil tracer disables tracing for the child.
+func (ctx *EvalContext) WithTracer(tracer EvalTracer) *EvalContext {
+	ret := ctx.NewChild()
+	ret.tracer = tracer
+	return ret
+}
+
+// Tracer returns the tracer most recently attached to the receiver or one of
+// its ancestors using WithTracer, or nil if there is none. The receiver may
+// be nil, in which case the result is always nil.
+func (ctx *EvalContext) Tracer() EvalTracer {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.tracer
+}
+
+// WithExhaustiveDiagnostics returns a new child of the receiver that asks
+// evaluators to report problems in as many independent parts of an
+// expression as they can, such as evaluating all of the arguments to a call
+// of an unknown function, rather than stopping at the first problem that
+// prevents producing a result. This is intended for batch validation, where
+// reporting everything at once saves the user from fixing one problem only
+// to be told about the next. Evaluators still avoid reporting problems that
+// are consequences of another one.
+//
+// The setting applies to the child and all of its descendents. The receiver
+// is not modified.
+func (ctx *EvalContext) WithExhaustiveDiagnostics(enabled bool) *EvalContext {
+	ret := ctx.NewChild()
+	ret.exhaustiveDiags = enabled
+	return ret
+}
+
+// ExhaustiveDiagnostics returns true if the receiver or one of its ancestors
+// was created by WithExhaustiveDiagnostics with enabled set to true, and no
+// closer ancestor disabled it again. The receiver may be nil, in which case
+// the result is always false.
+func (ctx *EvalContext) ExhaustiveDiagnostics() bool {
+	if ctx == nil {
+		return false
+	}
+	return ctx.exhaustiveDiags
+}
+
+// WithStrictTemplates returns a new child of the receiver that asks
+// evaluators to reject template interpolations whose values would need an
+// implicit conversion to a string that may produce surprising output. In
+// strict mode only strings, numbers and bools may be interpolated into a
+// template, and any other value is an error even if it could be converted
+// to a string. Values that cannot be converted to a string are errors
+// regardless of this setting.
+//
+// The setting applies to the child and all of its descendents. The receiver
+// is not modified.
+func (ctx *EvalContext) WithStrictTemplates(enabled bool) *EvalContext {
+	ret := ctx.NewChild()
+	ret.strictTemplates = enabled
+	return ret
+}
+
+// StrictTemplates returns true if the receiver or one of its ancestors was
+// created by WithStrictTemplates with enabled set to true, and no closer
+// ancestor disabled it again. The receiver may be nil, in which case the
+// result is always false.
+func (ctx *EvalContext) StrictTemplates() bool {
+	if ctx == nil {
+		return false
+	}
+	return ctx.strictTemplates
+}
+
+// FormatValue returns a string representation of the given value for
+// inclusion in the detail message of a diagnostic, using the ValueFormatter
+// of the receiver or its nearest ancestor that has one.
+//
+// If there is no such formatter then a string is formatted as a quoted
+// string literal, and other values using a short description. The receiver
+// may be nil, in which case the default formatting is used.
+func (ctx *EvalContext) FormatValue(v cty.Value) string {
+	for current := ctx; current != nil; current = current.parent {
+		if current.ValueFormatter != nil {
+			return current.ValueFormatter(v)
+		}
+	}
+
+	v, _ = v.UnmarkDeep()
+	switch {
+	case !v.IsKnown():
+		return "(unknown value)"
+	case v.IsNull():
+		return "null"
+	case v.Type() == cty.String:
+		return strconv.Quote(v.AsString())
+	case v.Type() == cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	case v.Type() == cty.Bool:
+		return strconv.FormatBool(v.True())
+	default:
+		return v.Type().FriendlyName()
+	}
+}
+
+// CheckFunctionPolicy returns the error returned by the FunctionPolicy of
+// the receiver or its nearest ancestor that has one for a call to the
+// function of the given name, or nil if the call is permitted. The receiver
+// may be nil, in which case all calls are permitted.
+func (ctx *EvalContext) CheckFunctionPolicy(name string) error {
+	for current := ctx; current != nil; current = current.parent {
+		if current.FunctionPolicy != nil {
+			return current.FunctionPolicy(name)
+		}
+	}
+	return nil
+}
+
+// AllowedFunctions returns a function for use as EvalContext.FunctionPolicy
+// that permits calls only to the functions with the given names.
+func AllowedFunctions(names ...string) func(name string) error {
+	allowed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		allowed[name] = struct{}{}
+	}
+	return func(name string) error {
+		if _, ok := allowed[name]; !ok {
+			return fmt.Errorf("it is not one of the functions permitted in this context")
+		}
+		return nil
+	}
+}
+
+// MergeContexts returns a new child of the given base context whose
+// variables are the given overrides, which shadow any variables of the same
+// name in the base context. Functions and any other variables remain visible
+// from the base context via the usual parent lookup.
+//
+// The base context is not modified. The given map is copied, so later
+// changes to it do not affect the returned context. If base is nil then the
+// result is a root context containing only the overrides.
+func MergeContexts(base *EvalContext, overrides map[string]cty.Value) *EvalContext {
+	ret := base.NewChild()
+	ret.Variables = make(map[string]cty.Value, len(overrides))
+	for k, v := range overrides {
+		ret.Variables[k] = v
+	}
+	return ret
+}
+
+// MergeContextFunctions is like MergeContexts, but overlays functions
+// rather than variables. Variables remain visible from the base context.
+func MergeContextFunctions(base *EvalContext, overrides map[string]function.Function) *EvalContext {
+	ret := base.NewChild()
+	ret.Functions = make(map[string]function.Function, len(overrides))
+	for k, v := range overrides {
+		ret.Functions[k] = v
+	}
+	return ret
+}
+
+// SubsetContext returns a new root EvalContext containing only the root
+// variables from the given context, or its ancestors, that are referenced
+// by the given absolute traversals, such as those returned from the
+// Variables method of an expression. This allows evaluating an expression
+// without exposing any other variables to it.
+//
+// Each referenced variable is included with its entire value, even if the
+// traversals access only some of its attributes or elements. Variables are
+// looked up in the same way as during evaluation, including by calling any
+// VariableResolver, and the result contains only their values rather than
+// the resolvers themselves. Names that are not defined in the given context
+// are omitted, and relative traversals are ignored.
+//
+// Since traversals do not describe function calls, the result includes all
+// of the functions available in the given context, which a caller may
+// replace if needed. Any context.Context attached with WithContext, any
+// tracer attached with WithTracer, any settings from WithExhaustiveDiagnostics
+// and WithStrictTemplates, and any ValueFormatter and FunctionPolicy are also
+// retained.
+//
+// If the given context is nil then the result is nil.
+func SubsetContext(full *EvalContext, traversals []Traversal) *EvalContext {
+	if full == nil {
+		return nil
+	}
+
+	ret := &EvalContext{
+		Variables: map[string]cty.Value{},
+		goCtx:     full.goCtx,
+		tracer:    full.tracer,
+
+		exhaustiveDiags: full.exhaustiveDiags,
+		strictTemplates: full.strictTemplates,
+	}
+	for current := full; current != nil; current = current.parent {
+		if current.ValueFormatter != nil {
+			ret.ValueFormatter = current.ValueFormatter
+			break
+		}
+	}
+	for current := full; current != nil; current = current.parent {
+		if current.FunctionPolicy != nil {
+			ret.FunctionPolicy = current.FunctionPolicy
+			break
+		}
+	}
+
+	for _, traversal := range traversals {
+		if traversal.IsRelative() {
+			continue
+		}
+		name := traversal.RootName()
+		if _, exists := ret.Variables[name]; exists {
+			continue
+		}
+		for current := full; current != nil; current = current.parent {
+			if val, exists := current.Variables[name]; exists {
+				ret.Variables[name] = val
+				break
+			}
+			if current.VariableResolver != nil {
+				if val, exists := current.VariableResolver(name); exists {
+					ret.Variables[name] = val
+					break
+				}
+			}
+		}
+	}
+
+	if funcs := full.visibleFunctions(); len(funcs) > 0 {
+		ret.Functions = funcs
+	}
+
+	return ret
+}
+
+// CombineContextFunctions returns a new child of the given base context
+// whose functions are those of all of the given source contexts, such as
+// contexts contributed by different plugins. The functions of each source
+// include those inherited from its ancestors. The combined functions shadow
+// any functions of the same name in the base context.
+//
+// If more than one source defines a function of the same name then the
+// result is an error describing all such conflicts, unless allowOverride is
+// set, in which case the function from the latest source in the list is
+// used. Sources that have the same function for a name, defined at the same
+// depth in each of their chains of ancestors, such as when they inherit it
+// from a shared ancestor, do not conflict. The base context and the sources
+// are not modified.
+func CombineContextFunctions(base *EvalContext, sources []*EvalContext, allowOverride bool) (*EvalContext, error) {
+	type definition struct {
+		source, level int
+	}
+	ret := base.NewChild()
+	ret.Functions = map[string]function.Function{}
+	definedBy := map[string]definition{}
+	var conflicts []string
+	for i, source := range sources {
+		levels := map[string]int{}
+		for level, current := range source.chain() {
+			for name := range current.Functions {
+				levels[name] = level
+			}
+		}
+		for name, f := range source.visibleFunctions() {
+			if prev, exists := definedBy[name]; exists && !allowOverride {
+				if prev.level != levels[name] || ret.Functions[name] != f {
+					conflicts = append(conflicts, fmt.Sprintf("%q (sources %d and %d)", name, prev.source, i))
+				}
+				continue
+			}
+			ret.Functions[name] = f
+			definedBy[name] = definition{source: i, level: levels[name]}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("functions are defined by more than one context: %s", strings.Join(conflicts, ", "))
+	}
+	return ret, nil
+}
+
+// CombineContextVariables is like CombineContextFunctions, but combines the
+// variables of the source contexts rather than their functions, with the
+// same policy for conflicts. Variables that a source would produce only by
+// calling its VariableResolver are not included, since a resolver cannot
+// list the names it supports.
+func CombineContextVariables(base *EvalContext, sources []*EvalContext, allowOverride bool) (*EvalContext, error) {
+	ret := base.NewChild()
+	ret.Variables = map[string]cty.Value{}
+	definedBy := map[string]int{}
+	var conflicts []string
+	for i, source := range sources {
+		for name, v := range source.visibleVariables() {
+			if prev, exists := definedBy[name]; exists && !allowOverride {
+				conflicts = append(conflicts, fmt.Sprintf("%q (sources %d and %d)", name, prev, i))
+				continue
+			}
+			ret.Variables[name] = v
+			definedBy[name] = i
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("variables are defined by more than one context: %s", strings.Join(conflicts, ", "))
+	}
+	return ret, nil
+}
+
+// visibleFunctions returns all of the functions that are available for
+// evaluation in the receiver, including those from its ancestors.
+func (ctx *EvalContext) visibleFunctions() map[string]function.Function {
+	ret := map[string]function.Function{}
+	// We visit the ancestors first so that functions defined in descendents
+	// take precedence, as they would during evaluation.
+	for _, current := range ctx.chain() {
+		for name, f := range current.Functions {
+			ret[name] = f
+		}
+	}
+	return ret
+}
+
+// visibleVariables is like visibleFunctions but for variables, except those
+// that would be produced by a VariableResolver.
+func (ctx *EvalContext) visibleVariables() map[string]cty.Value {
+	ret := map[string]cty.Value{}
+	for _, current := range ctx.chain() {
+		for name, v := range current.Variables {
+			ret[name] = v
+		}
+	}
+	return ret
+}
+
+// chain returns the receiver and its ancestors, starting with the root, or
+// an empty slice if the receiver is nil.
+func (ctx *EvalContext) chain() []*EvalContext {
+	var ret []*EvalContext
+	for current := ctx; current != nil; current = current.parent {
+		ret = append(ret, current)
+	}
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret
+}
@@ -0,0 +1,558 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestMergeContexts(t *testing.T) {
+	upper := function.New(&function.Spec{})
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.StringVal("base a"),
+			"b": cty.StringVal("base b"),
+		},
+		Functions: map[string]function.Function{
+			"upper": upper,
+		},
+	}
+
+	overrides := map[string]cty.Value{
+		"b": cty.StringVal("override b"),
+		"c": cty.StringVal("override c"),
+	}
+	ctx := MergeContexts(base, overrides)
+	overrides["d"] = cty.StringVal("added later")
+
+	if ctx.Parent() != base {
+		t.Fatalf("result is not a child of the base context")
+	}
+	for name, want := range map[string]cty.Value{
+		"a": cty.StringVal("base a"),
+		"b": cty.StringVal("override b"),
+		"c": cty.StringVal("override c"),
+	} {
+		got, diags := Traversal{TraverseRoot{Name: name}}.TraverseAbs(ctx)
+		if diags.HasErrors() {
+			t.Errorf("unexpected diagnostics for %q: %s", name, diags.Error())
+			continue
+		}
+		if !got.RawEquals(want) {
+			t.Errorf("wrong value for %q\ngot:  %#v\nwant: %#v", name, got, want)
+		}
+	}
+	if _, exists := ctx.Variables["d"]; exists {
+		t.Errorf("later change to the overrides map is visible in the result")
+	}
+	if got, want := len(base.Variables), 2; got != want {
+		t.Errorf("base context has %d variables; want %d", got, want)
+	}
+	if got := base.Variables["b"]; !got.RawEquals(cty.StringVal("base b")) {
+		t.Errorf("base context was modified: b is %#v", got)
+	}
+}
+
+func TestMergeContextsNilBase(t *testing.T) {
+	ctx := MergeContexts(nil, map[string]cty.Value{
+		"a": cty.True,
+	})
+	if ctx.Parent() != nil {
+		t.Fatalf("result has a parent; want none")
+	}
+	got, diags := Traversal{TraverseRoot{Name: "a"}}.TraverseAbs(ctx)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if !got.RawEquals(cty.True) {
+		t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, cty.True)
+	}
+}
+
+func TestMergeContextFunctions(t *testing.T) {
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.True,
+		},
+	}
+	fn := function.New(&function.Spec{})
+	ctx := MergeContextFunctions(base, map[string]function.Function{
+		"fn": fn,
+	})
+
+	if ctx.Parent() != base {
+		t.Fatalf("result is not a child of the base context")
+	}
+	if _, exists := ctx.Functions["fn"]; !exists {
+		t.Errorf("result is missing function %q", "fn")
+	}
+	if base.Functions != nil {
+		t.Errorf("base context was modified: has functions %#v", base.Functions)
+	}
+	if ctx.Variables != nil {
+		t.Errorf("result has its own variables; want them inherited from the base")
+	}
+}
+
+func TestCombineContextFunctions(t *testing.T) {
+	upper := function.New(&function.Spec{Description: "first upper"})
+	lower := function.New(&function.Spec{Description: "lower"})
+	upper2 := function.New(&function.Spec{Description: "second upper"})
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.True,
+		},
+	}
+	parent := &EvalContext{
+		Functions: map[string]function.Function{
+			"lower": lower,
+		},
+	}
+	first := parent.NewChild()
+	first.Functions = map[string]function.Function{
+		"upper": upper,
+	}
+	second := &EvalContext{
+		Functions: map[string]function.Function{
+			"upper": upper2,
+			"lower": lower,
+		},
+	}
+
+	ctx, err := CombineContextFunctions(base, []*EvalContext{first, nil}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ctx.Parent() != base {
+		t.Fatalf("result is not a child of the base context")
+	}
+	if got, want := ctx.Functions["upper"].Description(), "first upper"; len(ctx.Functions) != 2 || got != want {
+		t.Errorf("wrong functions %#v", ctx.Functions)
+	}
+	if got, want := ctx.Functions["lower"].Description(), "lower"; got != want {
+		t.Errorf("wrong lower function %q; want %q", got, want)
+	}
+
+	_, err = CombineContextFunctions(base, []*EvalContext{first, second}, false)
+	if err == nil {
+		t.Fatalf("unexpected success")
+	}
+	if got, want := err.Error(), `functions are defined by more than one context: "upper" (sources 0 and 1)`; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// The same function inherited from a shared ancestor is not a conflict.
+	sibling := parent.NewChild()
+	ctx, err = CombineContextFunctions(base, []*EvalContext{first, sibling}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := len(ctx.Functions), 2; got != want {
+		t.Errorf("wrong number of functions %d; want %d", got, want)
+	}
+
+	// The same function defined at different levels is a conflict.
+	shadowing := sibling.NewChild()
+	shadowing.Functions = map[string]function.Function{
+		"lower": lower,
+	}
+	_, err = CombineContextFunctions(base, []*EvalContext{first, shadowing}, false)
+	if err == nil {
+		t.Fatalf("unexpected success")
+	}
+	if got, want := err.Error(), `functions are defined by more than one context: "lower" (sources 0 and 1)`; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	ctx, err = CombineContextFunctions(base, []*EvalContext{first, second}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := ctx.Functions["upper"].Description(), "second upper"; len(ctx.Functions) != 2 || got != want {
+		t.Errorf("wrong functions %#v", ctx.Functions)
+	}
+}
+
+func TestCombineContextVariables(t *testing.T) {
+	first := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.StringVal("first a"),
+		},
+	}
+	second := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.StringVal("second a"),
+			"b": cty.StringVal("second b"),
+		},
+	}
+
+	_, err := CombineContextVariables(nil, []*EvalContext{first, second}, false)
+	if got, want := fmt.Sprint(err), `variables are defined by more than one context: "a" (sources 0 and 1)`; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	ctx, err := CombineContextVariables(nil, []*EvalContext{first, second}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]cty.Value{
+		"a": cty.StringVal("second a"),
+		"b": cty.StringVal("second b"),
+	}
+	if !reflect.DeepEqual(ctx.Variables, want) {
+		t.Errorf("wrong variables\ngot:  %#v\nwant: %#v", ctx.Variables, want)
+	}
+	if ctx.Parent() != nil {
+		t.Errorf("result has a parent")
+	}
+}
+
+func TestEvalContextVariableResolver(t *testing.T) {
+	var calls []string
+	resolver := func(prefix string) func(string) (cty.Value, bool) {
+		return func(name string) (cty.Value, bool) {
+			calls = append(calls, prefix+name)
+			switch name {
+			case "lazy", "both":
+				return cty.StringVal(prefix + name), true
+			default:
+				return cty.NilVal, false
+			}
+		}
+	}
+
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"static": cty.StringVal("static"),
+			"both":   cty.StringVal("map both"),
+		},
+		VariableResolver: resolver("base "),
+	}
+	inherit := base.NewChild()
+	override := base.NewChild()
+	override.VariableResolver = resolver("child ")
+
+	tests := []struct {
+		ctx       *EvalContext
+		name      string
+		want      cty.Value
+		wantCalls []string
+		wantErr   string
+	}{
+		{base, "static", cty.StringVal("static"), nil, ""},
+		{base, "both", cty.StringVal("map both"), nil, ""},
+		{base, "lazy", cty.StringVal("base lazy"), []string{"base lazy"}, ""},
+		{inherit, "lazy", cty.StringVal("base lazy"), []string{"base lazy"}, ""},
+		{override, "lazy", cty.StringVal("child lazy"), []string{"child lazy"}, ""},
+		{override, "both", cty.StringVal("child both"), []string{"child both"}, ""},
+		{override, "static", cty.StringVal("static"), []string{"child static"}, ""},
+		{override, "missing", cty.DynamicVal, []string{"child missing", "base missing"}, "Unknown variable"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls = nil
+			got, diags := Traversal{TraverseRoot{Name: test.name}}.TraverseAbs(test.ctx)
+			if test.wantErr != "" {
+				if len(diags) != 1 || diags[0].Summary != test.wantErr {
+					t.Errorf("wrong diagnostics; want one %q error\ngot: %s", test.wantErr, diags.Error())
+				}
+			} else if diags.HasErrors() {
+				t.Errorf("unexpected diagnostics: %s", diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+			if !reflect.DeepEqual(calls, test.wantCalls) {
+				t.Errorf("wrong resolver calls\ngot:  %#v\nwant: %#v", calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEvalContextWithContext(t *testing.T) {
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.True,
+		},
+	}
+	if got := base.Context(); got != nil {
+		t.Fatalf("base has context %#v; want nil", got)
+	}
+
+	type ctxKey struct{}
+	goCtx := context.WithValue(context.Background(), ctxKey{}, "outer")
+	withCtx := base.WithContext(goCtx)
+	if got := withCtx.Context(); got != goCtx {
+		t.Errorf("wrong context %#v; want %#v", got, goCtx)
+	}
+	if got := withCtx.NewChild().Context(); got != goCtx {
+		t.Errorf("child did not inherit context: got %#v", got)
+	}
+	if base.Context() != nil {
+		t.Errorf("WithContext modified its receiver")
+	}
+	if withCtx.Parent() != base {
+		t.Errorf("result is not a child of the receiver")
+	}
+
+	innerCtx := context.WithValue(goCtx, ctxKey{}, "inner")
+	if got := withCtx.NewChild().WithContext(innerCtx).Context(); got != innerCtx {
+		t.Errorf("nearest context was not selected: got %#v", got)
+	}
+}
+
+func TestEvalContextWithTracer(t *testing.T) {
+	var nilCtx *EvalContext
+	if got := nilCtx.Tracer(); got != nil {
+		t.Fatalf("nil context has tracer %#v; want nil", got)
+	}
+
+	base := &EvalContext{}
+	tracer := &nopTracer{}
+	withTracer := base.WithTracer(tracer)
+	if got := withTracer.Tracer(); got != tracer {
+		t.Errorf("wrong tracer %#v; want %#v", got, tracer)
+	}
+	if got := withTracer.NewChild().Tracer(); got != tracer {
+		t.Errorf("child did not inherit tracer: got %#v", got)
+	}
+	if got := MergeContexts(withTracer, nil).Tracer(); got != tracer {
+		t.Errorf("merged context did not inherit tracer: got %#v", got)
+	}
+	if got := SubsetContext(withTracer, nil).Tracer(); got != tracer {
+		t.Errorf("subset context did not retain tracer: got %#v", got)
+	}
+	if base.Tracer() != nil {
+		t.Errorf("WithTracer modified its receiver")
+	}
+	if got := withTracer.WithTracer(nil).Tracer(); got != nil {
+		t.Errorf("nil tracer did not disable tracing: got %#v", got)
+	}
+}
+
+func TestEvalContextWithExhaustiveDiagnostics(t *testing.T) {
+	var nilCtx *EvalContext
+	if nilCtx.ExhaustiveDiagnostics() {
+		t.Fatalf("nil context has exhaustive diagnostics")
+	}
+
+	base := &EvalContext{}
+	exhaustive := base.WithExhaustiveDiagnostics(true)
+	if !exhaustive.ExhaustiveDiagnostics() {
+		t.Errorf("exhaustive diagnostics not enabled")
+	}
+	if !exhaustive.NewChild().ExhaustiveDiagnostics() {
+		t.Errorf("child did not inherit exhaustive diagnostics")
+	}
+	if !SubsetContext(exhaustive, nil).ExhaustiveDiagnostics() {
+		t.Errorf("subset context did not retain exhaustive diagnostics")
+	}
+	if base.ExhaustiveDiagnostics() {
+		t.Errorf("WithExhaustiveDiagnostics modified its receiver")
+	}
+	if exhaustive.WithExhaustiveDiagnostics(false).ExhaustiveDiagnostics() {
+		t.Errorf("exhaustive diagnostics not disabled")
+	}
+}
+
+func TestEvalContextWithStrictTemplates(t *testing.T) {
+	var nilCtx *EvalContext
+	if nilCtx.StrictTemplates() {
+		t.Fatalf("nil context has strict templates")
+	}
+
+	base := &EvalContext{}
+	strict := base.WithStrictTemplates(true)
+	if !strict.StrictTemplates() {
+		t.Errorf("strict templates not enabled")
+	}
+	if !strict.NewChild().StrictTemplates() {
+		t.Errorf("child did not inherit strict templates")
+	}
+	if !SubsetContext(strict, nil).StrictTemplates() {
+		t.Errorf("subset context did not retain strict templates")
+	}
+	if base.StrictTemplates() {
+		t.Errorf("WithStrictTemplates modified its receiver")
+	}
+	if strict.WithStrictTemplates(false).StrictTemplates() {
+		t.Errorf("strict templates not disabled")
+	}
+}
+
+type nopTracer struct{}
+
+func (*nopTracer) EnterExpression(Expression, *EvalContext) {}
+
+func (*nopTracer) LeaveExpression(Expression, *EvalContext, cty.Value, Diagnostics) {}
+
+func TestEvalContextFormatValue(t *testing.T) {
+	tests := []struct {
+		val  cty.Value
+		want string
+	}{
+		{cty.StringVal("hello \"world\""), `"hello \"world\""`},
+		{cty.StringVal("secret").Mark("sensitive"), `"secret"`},
+		{cty.NumberIntVal(12), "12"},
+		{cty.NumberFloatVal(1.5), "1.5"},
+		{cty.True, "true"},
+		{cty.NullVal(cty.String), "null"},
+		{cty.UnknownVal(cty.String), "(unknown value)"},
+		{cty.ListValEmpty(cty.String), "list of string"},
+	}
+
+	var nilCtx *EvalContext
+	for _, test := range tests {
+		t.Run(test.val.GoString(), func(t *testing.T) {
+			if got := nilCtx.FormatValue(test.val); got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+			if got := (&EvalContext{}).FormatValue(test.val); got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("custom", func(t *testing.T) {
+		base := &EvalContext{
+			ValueFormatter: func(v cty.Value) string {
+				if v.HasMark("sensitive") {
+					return "(sensitive value)"
+				}
+				return "(other value)"
+			},
+		}
+		child := base.NewChild()
+		if got, want := child.FormatValue(cty.StringVal("a").Mark("sensitive")), "(sensitive value)"; got != want {
+			t.Errorf("wrong result %q; want %q", got, want)
+		}
+		if got, want := child.FormatValue(cty.StringVal("a")), "(other value)"; got != want {
+			t.Errorf("wrong result %q; want %q", got, want)
+		}
+
+		child.ValueFormatter = func(v cty.Value) string {
+			return "(child)"
+		}
+		if got, want := child.FormatValue(cty.StringVal("a")), "(child)"; got != want {
+			t.Errorf("nearest formatter was not selected: got %q", got)
+		}
+
+		subset := SubsetContext(base.NewChild(), nil)
+		if got, want := subset.FormatValue(cty.StringVal("a")), "(other value)"; got != want {
+			t.Errorf("SubsetContext did not retain formatter: got %q", got)
+		}
+	})
+}
+
+func TestEvalContextCheckFunctionPolicy(t *testing.T) {
+	var nilCtx *EvalContext
+	if err := nilCtx.CheckFunctionPolicy("upper"); err != nil {
+		t.Errorf("unexpected error for nil context: %s", err)
+	}
+	if err := (&EvalContext{}).CheckFunctionPolicy("upper"); err != nil {
+		t.Errorf("unexpected error for context without policy: %s", err)
+	}
+
+	base := &EvalContext{
+		FunctionPolicy: AllowedFunctions("upper", "lower"),
+	}
+	child := base.NewChild()
+	if err := child.CheckFunctionPolicy("upper"); err != nil {
+		t.Errorf("unexpected error for allowed function: %s", err)
+	}
+	err := child.CheckFunctionPolicy("file")
+	if err == nil {
+		t.Fatalf("no error for function that is not allowed")
+	}
+	if got, want := err.Error(), "it is not one of the functions permitted in this context"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	child.FunctionPolicy = func(name string) error {
+		return nil
+	}
+	if err := child.CheckFunctionPolicy("file"); err != nil {
+		t.Errorf("nearest policy was not selected: %s", err)
+	}
+
+	subset := SubsetContext(base.NewChild(), nil)
+	if err := subset.CheckFunctionPolicy("file"); err == nil {
+		t.Errorf("SubsetContext did not retain policy")
+	}
+}
+
+func TestSubsetContext(t *testing.T) {
+	upper := function.New(&function.Spec{})
+	lower := function.New(&function.Spec{})
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.StringVal("base a"),
+			"b": cty.StringVal("base b"),
+			"c": cty.StringVal("base c"),
+		},
+		Functions: map[string]function.Function{
+			"upper": upper,
+		},
+	}
+	full := base.NewChild()
+	full.Variables = map[string]cty.Value{
+		"a": cty.ObjectVal(map[string]cty.Value{
+			"x": cty.True,
+			"y": cty.False,
+		}),
+	}
+	full.VariableResolver = func(name string) (cty.Value, bool) {
+		if name == "resolved" {
+			return cty.NumberIntVal(1), true
+		}
+		return cty.NilVal, false
+	}
+	full.Functions = map[string]function.Function{
+		"lower": lower,
+	}
+
+	traversals := []Traversal{
+		{TraverseRoot{Name: "a"}, TraverseAttr{Name: "x"}},
+		{TraverseRoot{Name: "a"}, TraverseAttr{Name: "y"}},
+		{TraverseRoot{Name: "c"}},
+		{TraverseRoot{Name: "resolved"}},
+		{TraverseRoot{Name: "undefined"}},
+		{TraverseAttr{Name: "b"}}, // relative traversals are ignored
+	}
+	got := SubsetContext(full, traversals)
+
+	if got.Parent() != nil {
+		t.Errorf("result has a parent")
+	}
+	wantVars := map[string]cty.Value{
+		"a":        full.Variables["a"],
+		"c":        cty.StringVal("base c"),
+		"resolved": cty.NumberIntVal(1),
+	}
+	if len(got.Variables) != len(wantVars) {
+		t.Errorf("wrong variables %#v; want %#v", got.Variables, wantVars)
+	}
+	for name, want := range wantVars {
+		if !got.Variables[name].RawEquals(want) {
+			t.Errorf("wrong value for %q: %#v; want %#v", name, got.Variables[name], want)
+		}
+	}
+	if len(got.Functions) != 2 || got.Functions["upper"] != upper || got.Functions["lower"] != lower {
+		t.Errorf("wrong functions %#v", got.Functions)
+	}
+	if got.VariableResolver != nil {
+		t.Errorf("result has a variable resolver")
+	}
+
+	if SubsetContext(nil, traversals) != nil {
+		t.Errorf("result for nil context is not nil")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// EvalTracer is implemented by callers that want to observe the evaluation
+// of an expression one step at a time, such as to produce a trace for
+// debugging why an expression has an unexpected value, or to measure how
+// long each function call takes.
+//
+// A tracer is attached to an EvalContext using its WithTracer method. An
+// evaluator that supports tracing calls EnterExpression before evaluating
+// each node of an expression and LeaveExpression after, so the calls for
+// the nodes nested inside an expression appear between the two calls for
+// that expression. Not all syntaxes support tracing; see the documentation
+// of each one for details.
+//
+// The methods are called from the goroutine doing the evaluation, and so a
+// tracer must be safe for concurrent use if it is shared between
+// evaluations running concurrently.
+type EvalTracer interface {
+	// EnterExpression is called before the given expression is evaluated
+	// in the given context.
+	EnterExpression(expr Expression, ctx *EvalContext)
+
+	// LeaveExpression is called after the given expression is evaluated in
+	// the given context, with the result and diagnostics returned from
+	// that evaluation.
+	LeaveExpression(expr Expression, ctx *EvalContext, val cty.Value, diags Diagnostics)
+}
@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExpressionDependencyOrder analyzes the references between a set of named
+// expressions, such as the "locals" of a configuration language, and returns
+// their names in an order in which they can be evaluated such that each
+// expression is evaluated only after all of the expressions it refers to.
+//
+// The references are found using each expression's Variables method. If
+// prefix is empty, a traversal refers to the expression whose name is the
+// traversal's root name. Otherwise, a traversal refers to a named expression
+// only if its root name is the prefix and its next step is an attribute
+// access naming the expression, as in "local.foo" for the prefix "local".
+// Traversals that don't refer to one of the given expressions are ignored,
+// since they are presumably resolved some other way.
+//
+// If some of the expressions refer to one another in a cycle then the
+// result has error diagnostics, one for each cycle, whose details name the
+// members of the cycle and the location of each reference that forms it.
+// The returned order then omits the members of cycles, but still includes
+// any other expressions, including those that depend on the cycles, so that
+// a caller can still evaluate as much as possible for analysis purposes.
+//
+// Expressions with no dependency between them are ordered lexically by
+// name, so the result is deterministic.
+func ExpressionDependencyOrder(exprs map[string]Expression, prefix string) ([]string, Diagnostics) {
+	names := make([]string, 0, len(exprs))
+	for name := range exprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// refs records, for each expression, the first reference it makes to
+	// each other expression it depends on.
+	refs := make(map[string]map[string]Traversal, len(exprs))
+	deps := make(map[string][]string, len(exprs))
+	for _, name := range names {
+		refs[name] = map[string]Traversal{}
+		for _, traversal := range exprs[name].Variables() {
+			dep, ok := dependencyName(traversal, prefix)
+			if !ok {
+				continue
+			}
+			if _, exists := exprs[dep]; !exists {
+				continue
+			}
+			if _, exists := refs[name][dep]; exists {
+				continue
+			}
+			refs[name][dep] = traversal
+			deps[name] = append(deps[name], dep)
+		}
+		sort.Strings(deps[name])
+	}
+
+	// We use Tarjan's algorithm to find the strongly-connected components,
+	// which it produces with each component after all of the components it
+	// depends on: exactly the evaluation order we need.
+	t := &dependencyTarjan{
+		deps:    deps,
+		index:   make(map[string]int, len(exprs)),
+		lowLink: make(map[string]int, len(exprs)),
+		onStack: make(map[string]bool, len(exprs)),
+	}
+	for _, name := range names {
+		if _, visited := t.index[name]; !visited {
+			t.visit(name)
+		}
+	}
+
+	var diags Diagnostics
+	order := make([]string, 0, len(exprs))
+	for _, component := range t.components {
+		if len(component) == 1 {
+			name := component[0]
+			if _, selfRef := refs[name][name]; !selfRef {
+				order = append(order, name)
+				continue
+			}
+		}
+		diags = append(diags, dependencyCycleDiagnostic(component, deps, refs))
+	}
+	return order, diags
+}
+
+// dependencyName returns the name of the expression that the given traversal
+// refers to, if it's a reference to a named expression at all.
+func dependencyName(traversal Traversal, prefix string) (string, bool) {
+	if len(traversal) == 0 {
+		return "", false
+	}
+	if prefix == "" {
+		return traversal.RootName(), true
+	}
+	if traversal.RootName() != prefix || len(traversal) < 2 {
+		return "", false
+	}
+	if attr, ok := traversal[1].(TraverseAttr); ok {
+		return attr.Name, true
+	}
+	return "", false
+}
+
+type dependencyTarjan struct {
+	deps       map[string][]string
+	index      map[string]int
+	lowLink    map[string]int
+	onStack    map[string]bool
+	stack      []string
+	components [][]string
+}
+
+func (t *dependencyTarjan) visit(name string) {
+	t.index[name] = len(t.index)
+	t.lowLink[name] = t.index[name]
+	t.stack = append(t.stack, name)
+	t.onStack[name] = true
+
+	for _, dep := range t.deps[name] {
+		if _, visited := t.index[dep]; !visited {
+			t.visit(dep)
+			if t.lowLink[dep] < t.lowLink[name] {
+				t.lowLink[name] = t.lowLink[dep]
+			}
+		} else if t.onStack[dep] && t.index[dep] < t.lowLink[name] {
+			t.lowLink[name] = t.index[dep]
+		}
+	}
+
+	if t.lowLink[name] != t.index[name] {
+		return
+	}
+	var component []string
+	for {
+		last := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[last] = false
+		component = append(component, last)
+		if last == name {
+			break
+		}
+	}
+	sort.Strings(component)
+	t.components = append(t.components, component)
+}
+
+// dependencyCycleDiagnostic returns a diagnostic describing a cycle through
+// the given strongly-connected component. If the component contains more
+// than one cycle then the diagnostic describes the shortest one through its
+// lexically-first member.
+func dependencyCycleDiagnostic(component []string, deps map[string][]string, refs map[string]map[string]Traversal) *Diagnostic {
+	inComponent := make(map[string]bool, len(component))
+	for _, name := range component {
+		inComponent[name] = true
+	}
+
+	// Breadth-first search within the component for the shortest path from
+	// the first member back to itself.
+	start := component[0]
+	prev := map[string]string{}
+	queue := []string{start}
+	var last string
+	found := false
+	for len(queue) > 0 && !found {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range deps[name] {
+			if !inComponent[dep] {
+				continue
+			}
+			if dep == start {
+				last, found = name, true
+				break
+			}
+			if _, seen := prev[dep]; !seen {
+				prev[dep] = name
+				queue = append(queue, dep)
+			}
+		}
+	}
+	cycle := []string{last}
+	for name := last; name != start; {
+		name = prev[name]
+		cycle = append(cycle, name)
+	}
+	for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+		cycle[i], cycle[j] = cycle[j], cycle[i]
+	}
+
+	steps := make([]string, len(cycle))
+	for i, name := range cycle {
+		next := cycle[(i+1)%len(cycle)]
+		steps[i] = fmt.Sprintf("%q refers to %q at %s", name, next, refs[name][next].SourceRange())
+	}
+
+	var detail string
+	if len(cycle) == 1 {
+		detail = fmt.Sprintf("The expression for %q refers to itself: %s.", start, steps[0])
+	} else {
+		quoted := make([]string, len(cycle))
+		for i, name := range cycle {
+			quoted[i] = fmt.Sprintf("%q", name)
+		}
+		detail = fmt.Sprintf(
+			"The expressions for %s and %s refer to one another: %s.",
+			strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1],
+			strings.Join(steps, ", "),
+		)
+	}
+
+	return &Diagnostic{
+		Severity: DiagError,
+		Summary:  "Cycle in references",
+		Detail:   detail,
+		Subject:  refs[start][cycle[1%len(cycle)]].SourceRange().Ptr(),
+	}
+}
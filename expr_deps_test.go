@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type referencesTestExpr struct {
+	staticExpr
+	refs []Traversal
+}
+
+func (e *referencesTestExpr) Variables() []Traversal {
+	return e.refs
+}
+
+func TestExpressionDependencyOrder(t *testing.T) {
+	// ref returns a traversal "local.<name>" whose range is on the given
+	// line of a file named "locals.hcl".
+	ref := func(name string, line int) Traversal {
+		rng := Range{
+			Filename: "locals.hcl",
+			Start:    Pos{Line: line, Column: 5, Byte: 0},
+			End:      Pos{Line: line, Column: 11 + len(name), Byte: 6 + len(name)},
+		}
+		return Traversal{
+			TraverseRoot{Name: "local", SrcRange: rng},
+			TraverseAttr{Name: name, SrcRange: rng},
+		}
+	}
+	refsTo := func(refs ...Traversal) Expression {
+		return &referencesTestExpr{refs: refs}
+	}
+
+	tests := map[string]struct {
+		exprs     map[string]Expression
+		prefix    string
+		wantOrder []string
+		wantDiags []string
+	}{
+		"empty": {
+			exprs:     map[string]Expression{},
+			prefix:    "local",
+			wantOrder: []string{},
+		},
+		"chain": {
+			exprs: map[string]Expression{
+				"a": refsTo(ref("b", 1)),
+				"b": refsTo(ref("c", 2), Traversal{TraverseRoot{Name: "var"}, TraverseAttr{Name: "x"}}),
+				"c": refsTo(),
+				"d": refsTo(ref("unknown", 4)),
+			},
+			prefix:    "local",
+			wantOrder: []string{"c", "b", "a", "d"},
+		},
+		"diamond": {
+			exprs: map[string]Expression{
+				"top":   refsTo(ref("right", 1), ref("left", 1)),
+				"left":  refsTo(ref("base", 2)),
+				"right": refsTo(ref("base", 3), ref("base", 3)),
+				"base":  refsTo(),
+			},
+			prefix:    "local",
+			wantOrder: []string{"base", "left", "right", "top"},
+		},
+		"no prefix": {
+			exprs: map[string]Expression{
+				"a": refsTo(Traversal{TraverseRoot{Name: "b"}}),
+				"b": refsTo(Traversal{TraverseRoot{Name: "local"}, TraverseAttr{Name: "a"}}),
+			},
+			wantOrder: []string{"b", "a"},
+		},
+		"self reference": {
+			exprs: map[string]Expression{
+				"a": refsTo(ref("a", 1)),
+				"b": refsTo(),
+			},
+			prefix:    "local",
+			wantOrder: []string{"b"},
+			wantDiags: []string{
+				`locals.hcl:1,5-12: Cycle in references; The expression for "a" refers to itself: "a" refers to "a" at locals.hcl:1,5-12.`,
+			},
+		},
+		"cycle with dependents": {
+			exprs: map[string]Expression{
+				"a":    refsTo(ref("b", 1)),
+				"b":    refsTo(ref("c", 2), ref("base", 2)),
+				"c":    refsTo(ref("a", 3)),
+				"base": refsTo(),
+				"user": refsTo(ref("c", 5)),
+			},
+			prefix:    "local",
+			wantOrder: []string{"base", "user"},
+			wantDiags: []string{
+				`locals.hcl:1,5-12: Cycle in references; The expressions for "a", "b" and "c" refer to one another: "a" refers to "b" at locals.hcl:1,5-12, "b" refers to "c" at locals.hcl:2,5-12, "c" refers to "a" at locals.hcl:3,5-12.`,
+			},
+		},
+		"separate cycles": {
+			exprs: map[string]Expression{
+				"a": refsTo(ref("b", 1)),
+				"b": refsTo(ref("a", 2)),
+				"x": refsTo(ref("y", 3)),
+				"y": refsTo(ref("x", 4)),
+			},
+			prefix:    "local",
+			wantOrder: []string{},
+			wantDiags: []string{
+				`locals.hcl:1,5-12: Cycle in references; The expressions for "a" and "b" refer to one another: "a" refers to "b" at locals.hcl:1,5-12, "b" refers to "a" at locals.hcl:2,5-12.`,
+				`locals.hcl:3,5-12: Cycle in references; The expressions for "x" and "y" refer to one another: "x" refers to "y" at locals.hcl:3,5-12, "y" refers to "x" at locals.hcl:4,5-12.`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotOrder, diags := ExpressionDependencyOrder(test.exprs, test.prefix)
+			if diff := cmp.Diff(test.wantOrder, gotOrder); diff != "" {
+				t.Errorf("wrong order\n%s", diff)
+			}
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ExprAsLiteral attempts to interpret the given expression as a constant
+// literal, returning its value and true if possible, or cty.NilVal and false
+// if not.
+//
+// A constant literal, for the sake of this function, is a literal value such
+// as a number, possibly negative, a bool, null, or a string without any
+// template sequences, or a tuple or object constructor whose elements, keys
+// and values are all themselves constant literals. Because such an expression cannot refer to any
+// variables or functions, its value can be obtained without an EvalContext.
+//
+// A particular Expression implementation can support this function by
+// offering a method called AsLiteral that takes no arguments and returns
+// cty.Value and bool, with the same meaning as for this function.
+// Alternatively, an implementation can support UnwrapExpression to delegate
+// handling of this function to a wrapped Expression object.
+//
+// This function is intended for fast reads of settings that are required to
+// be constant, such as those that are decoded before any EvalContext can be
+// constructed. Other expressions must still be evaluated as normal, so it
+// should not be used in situations where a non-literal expression would be
+// valid.
+func ExprAsLiteral(expr Expression) (cty.Value, bool) {
+	type asLiteral interface {
+		AsLiteral() (cty.Value, bool)
+	}
+
+	physExpr := UnwrapExpressionUntil(expr, func(expr Expression) bool {
+		_, supported := expr.(asLiteral)
+		return supported
+	})
+
+	if asL, supported := physExpr.(asLiteral); supported {
+		if val, ok := asL.AsLiteral(); ok {
+			return val, true
+		}
+	}
+	return cty.NilVal, false
+}
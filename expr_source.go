@@ -0,0 +1,41 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ExpressionSource returns the source code of the given expression, exactly
+// as it was written in its configuration file, by finding the bytes
+// described by the expression's range within the given map of sources. The
+// map is keyed by filename, in the same form as returned by the Sources
+// method of hclparse.Parser.
+//
+// This works the same way for expressions from any syntax whose ranges
+// describe the byte offsets of the expression in the file it was parsed from,
+// which includes both the native syntax and the JSON syntax. For the JSON
+// syntax, the result is the JSON value with any quotes that it was written
+// with.
+//
+// The result is an empty string if the expression's file is not in the
+// given map. The result is not meaningful for an expression whose range
+// doesn't describe its source code, such as one constructed with StaticExpr
+// using a synthetic range.
+func ExpressionSource(expr Expression, files map[string][]byte) string {
+	rng := expr.Range()
+	src, ok := files[rng.Filename]
+	if !ok {
+		return ""
+	}
+	return string(rng.SliceBytes(src))
+}
+
+// ValueWithSource evaluates the given expression in the given context and
+// returns its value along with its source code, as returned by
+// ExpressionSource.
+func ValueWithSource(expr Expression, ctx *EvalContext, files map[string][]byte) (cty.Value, string, Diagnostics) {
+	val, diags := expr.Value(ctx)
+	return val, ExpressionSource(expr, files), diags
+}
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExpressionSource(t *testing.T) {
+	files := map[string][]byte{
+		"a.hcl":  []byte("foo = upper(\"bar\")\n"),
+		"b.json": []byte(`{"foo": "${upper(\"bar\")}"}`),
+	}
+
+	tests := map[string]struct {
+		rng  Range
+		want string
+	}{
+		"native syntax": {
+			Range{
+				Filename: "a.hcl",
+				Start:    Pos{Line: 1, Column: 7, Byte: 6},
+				End:      Pos{Line: 1, Column: 19, Byte: 18},
+			},
+			`upper("bar")`,
+		},
+		"json syntax": {
+			Range{
+				Filename: "b.json",
+				Start:    Pos{Line: 1, Column: 9, Byte: 8},
+				End:      Pos{Line: 1, Column: 28, Byte: 27},
+			},
+			`"${upper(\"bar\")}"`,
+		},
+		"unknown file": {
+			Range{
+				Filename: "c.hcl",
+				Start:    Pos{Line: 1, Column: 1, Byte: 0},
+				End:      Pos{Line: 1, Column: 4, Byte: 3},
+			},
+			``,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr := StaticExpr(cty.StringVal("BAR"), test.rng)
+			if got := ExpressionSource(expr, files); got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+
+			val, src, diags := ValueWithSource(expr, nil, files)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if !val.RawEquals(cty.StringVal("BAR")) {
+				t.Errorf("wrong value %#v", val)
+			}
+			if src != test.want {
+				t.Errorf("wrong source\ngot:  %q\nwant: %q", src, test.want)
+			}
+		})
+	}
+}
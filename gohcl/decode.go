@@ -4,6 +4,7 @@
 package gohcl
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
 
@@ -39,6 +40,40 @@ func DecodeBody(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.Diagno
 	return decodeBodyToValue(body, ctx, rv.Elem())
 }
 
+// DecodeOptions customizes the behavior of DecodeBodyWithOptions and
+// DecodeExpressionWithOptions. The zero value selects the behavior of
+// DecodeBody and DecodeExpression.
+type DecodeOptions struct {
+	// DecorateDiagnostic, if set, is called for each diagnostic produced
+	// while decoding, just before the diagnostics are returned. It may
+	// modify the given diagnostic in place, such as to set its Extra field
+	// to an error code or to adjust its Summary, so that callers can
+	// classify decoding errors without matching on their messages.
+	DecorateDiagnostic func(diag *hcl.Diagnostic)
+}
+
+// DecodeBodyWithOptions is like DecodeBody, but allows customizing the
+// behavior of the decoder using the given options.
+func DecodeBodyWithOptions(body hcl.Body, ctx *hcl.EvalContext, val interface{}, opts DecodeOptions) hcl.Diagnostics {
+	return opts.decorate(DecodeBody(body, ctx, val))
+}
+
+// DecodeExpressionWithOptions is like DecodeExpression, but allows
+// customizing the behavior of the decoder using the given options.
+func DecodeExpressionWithOptions(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}, opts DecodeOptions) hcl.Diagnostics {
+	return opts.decorate(DecodeExpression(expr, ctx, val))
+}
+
+func (opts DecodeOptions) decorate(diags hcl.Diagnostics) hcl.Diagnostics {
+	if opts.DecorateDiagnostic == nil {
+		return diags
+	}
+	for _, diag := range diags {
+		opts.DecorateDiagnostic(diag)
+	}
+	return diags
+}
+
 func decodeBodyToValue(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value) hcl.Diagnostics {
 	et := val.Type()
 	switch et.Kind() {
@@ -138,17 +173,24 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 
 		ty := field.Type
 		isSlice := false
+		isMap := false
 		isPtr := false
-		if ty.Kind() == reflect.Slice {
+		switch ty.Kind() {
+		case reflect.Slice:
 			isSlice = true
 			ty = ty.Elem()
+		case reflect.Map:
+			isMap = true
+			ty = ty.Elem()
 		}
 		if ty.Kind() == reflect.Ptr {
 			isPtr = true
 			ty = ty.Elem()
 		}
 
-		if len(blocks) > 1 && !isSlice {
+		attrName, discriminated := tags.Discriminators[typeName]
+
+		if len(blocks) > 1 && !isSlice && !isMap {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  fmt.Sprintf("Duplicate %s block", typeName),
@@ -162,11 +204,15 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		}
 
 		if len(blocks) == 0 {
-			if isSlice || isPtr {
+			if isMap {
+				if val.Field(fieldIdx).IsNil() {
+					val.Field(fieldIdx).Set(reflect.MakeMap(field.Type))
+				}
+			} else if isSlice || isPtr || discriminated {
 				if val.Field(fieldIdx).IsNil() {
 					val.Field(fieldIdx).Set(reflect.Zero(field.Type))
 				}
-			} else {
+			} else if !tags.Optional[typeName] {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  fmt.Sprintf("Missing %s block", typeName),
@@ -179,6 +225,9 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 
 		switch {
 
+		case discriminated:
+			diags = append(diags, decodeDiscriminatedBlocks(blocks, ctx, val.Field(fieldIdx), attrName)...)
+
 		case isSlice:
 			elemType := ty
 			if isPtr {
@@ -214,6 +263,43 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 
 			val.Field(fieldIdx).Set(sli)
 
+		case isMap:
+			mv := val.Field(fieldIdx)
+			if mv.IsNil() {
+				mv = reflect.MakeMap(field.Type)
+			}
+
+			// Blocks are keyed by their first label, so each label value
+			// may appear only once.
+			seen := make(map[string]*hcl.Block, len(blocks))
+			for _, block := range blocks {
+				key := block.Labels[0]
+				if prev, exists := seen[key]; exists {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  fmt.Sprintf("Duplicate %s block", typeName),
+						Detail: fmt.Sprintf(
+							"A %s block labeled %q was already defined at %s. Each %s block must have a unique label.",
+							typeName, key, prev.DefRange.String(), typeName,
+						),
+						Subject: &block.LabelRanges[0],
+					})
+					continue
+				}
+				seen[key] = block
+
+				kv := reflect.ValueOf(key).Convert(field.Type.Key())
+				v := reflect.New(ty)
+				diags = append(diags, decodeBlockToValue(block, ctx, v.Elem())...)
+				if isPtr {
+					mv.SetMapIndex(kv, v)
+				} else {
+					mv.SetMapIndex(kv, v.Elem())
+				}
+			}
+
+			val.Field(fieldIdx).Set(mv)
+
 		default:
 			block := blocks[0]
 			if isPtr {
@@ -234,6 +320,65 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 	return diags
 }
 
+// decodeDiscriminatedBlocks decodes the given blocks into the given field
+// whose type is an interface type, or a slice of or map of an interface type,
+// choosing the concrete type for each block using decodeDiscriminatedBlock.
+func decodeDiscriminatedBlocks(blocks hcl.Blocks, ctx *hcl.EvalContext, fieldV reflect.Value, attrName string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	switch fieldV.Kind() {
+	case reflect.Slice:
+		iface := fieldV.Type().Elem()
+		sli := reflect.MakeSlice(fieldV.Type(), 0, len(blocks))
+		for _, block := range blocks {
+			v, blockDiags := decodeDiscriminatedBlock(block, ctx, iface, attrName)
+			diags = append(diags, blockDiags...)
+			if !v.IsValid() {
+				v = reflect.Zero(iface)
+			}
+			sli = reflect.Append(sli, v)
+		}
+		fieldV.Set(sli)
+
+	case reflect.Map:
+		iface := fieldV.Type().Elem()
+		mv := reflect.MakeMap(fieldV.Type())
+		seen := make(map[string]*hcl.Block, len(blocks))
+		for _, block := range blocks {
+			key := block.Labels[0]
+			if prev, exists := seen[key]; exists {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("Duplicate %s block", block.Type),
+					Detail: fmt.Sprintf(
+						"A %s block labeled %q was already defined at %s. Each %s block must have a unique label.",
+						block.Type, key, prev.DefRange.String(), block.Type,
+					),
+					Subject: &block.LabelRanges[0],
+				})
+				continue
+			}
+			seen[key] = block
+
+			v, blockDiags := decodeDiscriminatedBlock(block, ctx, iface, attrName)
+			diags = append(diags, blockDiags...)
+			if v.IsValid() {
+				mv.SetMapIndex(reflect.ValueOf(key).Convert(fieldV.Type().Key()), v)
+			}
+		}
+		fieldV.Set(mv)
+
+	default:
+		v, blockDiags := decodeDiscriminatedBlock(blocks[0], ctx, fieldV.Type(), attrName)
+		diags = append(diags, blockDiags...)
+		if v.IsValid() {
+			fieldV.Set(v)
+		}
+	}
+
+	return diags
+}
+
 func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
 	attrs, diags := body.JustAttributes()
 	if attrs == nil {
@@ -266,9 +411,19 @@ func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value)
 	if len(block.Labels) > 0 {
 		blockTags := getFieldTags(v.Type())
 		for li, lv := range block.Labels {
+			if li >= len(blockTags.Labels) {
+				break
+			}
 			lfieldIdx := blockTags.Labels[li].FieldIndex
 			v.Field(lfieldIdx).Set(reflect.ValueOf(lv))
 		}
+		if blockTags.ExtraLabels != nil {
+			var extra []string
+			if len(block.Labels) > len(blockTags.Labels) {
+				extra = append(extra, block.Labels[len(blockTags.Labels):]...)
+			}
+			v.Field(*blockTags.ExtraLabels).Set(reflect.ValueOf(extra))
+		}
 	}
 
 	return diags
@@ -278,6 +433,13 @@ func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value)
 // value. This value must be something that gocty is able to decode into,
 // since the final decoding is delegated to that package.
 //
+// As an exception, if the value (or, for a pointer, the value it points to)
+// implements encoding.TextUnmarshaler then the expression result is instead
+// converted to a string and passed to its UnmarshalText method. Similarly,
+// values of types that have a function registered with
+// RegisterStringDecoder, such as time.Duration, are decoded from a string
+// using that function.
+//
 // The given EvalContext is used to resolve any variables or functions in
 // expressions encountered while decoding. This may be nil to require only
 // constant values, for simple applications that do not support variables or
@@ -291,6 +453,13 @@ func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value)
 func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}) hcl.Diagnostics {
 	srcVal, diags := expr.Value(ctx)
 
+	if target, ty, fn, ok := stringDecoderTarget(val); ok {
+		return append(diags, decodeString(expr, srcVal, target, ty, fn)...)
+	}
+	if target, ok := textUnmarshalerTarget(val); ok {
+		return append(diags, decodeText(expr, srcVal, target)...)
+	}
+
 	convTy, err := gocty.ImpliedType(val)
 	if err != nil {
 		panic(fmt.Sprintf("unsuitable DecodeExpression target: %s", err))
@@ -321,3 +490,104 @@ func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}
 
 	return diags
 }
+
+// textUnmarshalerTarget returns the value that DecodeExpression should treat
+// as implementing encoding.TextUnmarshaler, if any. val is the pointer given
+// to DecodeExpression, and so for a pointer-typed target the result is the
+// pointer-to-pointer's element.
+func textUnmarshalerTarget(val interface{}) (reflect.Value, bool) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return reflect.Value{}, false
+	}
+	if textUnmarshalerType(rv.Type()) {
+		return rv, true
+	}
+	if ev := rv.Elem(); ev.Kind() == reflect.Ptr && textUnmarshalerType(ev.Type()) {
+		return ev, true
+	}
+	return reflect.Value{}, false
+}
+
+func textUnmarshalerType(ty reflect.Type) bool {
+	// gocty has native support for the arbitrary-precision number types,
+	// which preserves more precision than round-tripping through text.
+	if ty == bigFloatPtrType || ty == bigIntPtrType {
+		return false
+	}
+	return ty.Implements(textUnmarshalerIface)
+}
+
+// decodeText decodes the given value into target, which must be a pointer
+// type that implements encoding.TextUnmarshaler. If target is itself a
+// settable pointer field then a new value is allocated, or the field is set
+// to nil if the given value is null.
+func decodeText(expr hcl.Expression, srcVal cty.Value, target reflect.Value) hcl.Diagnostics {
+	strVal, diags := stringValueForDecode(expr, srcVal)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if target.CanSet() {
+		// The target is a pointer-typed field, so a null value leaves it nil
+		// and otherwise we allocate a new value to unmarshal into.
+		if strVal.IsNull() {
+			target.Set(reflect.Zero(target.Type()))
+			return diags
+		}
+		target.Set(reflect.New(target.Type().Elem()))
+	} else if strVal.IsNull() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   "Unsuitable value: value must not be null",
+			Subject:  expr.StartRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+		return diags
+	}
+
+	tu := target.Interface().(encoding.TextUnmarshaler)
+	if err := tu.UnmarshalText([]byte(strVal.AsString())); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value",
+			Detail:   fmt.Sprintf("Invalid value: %s.", err.Error()),
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// stringValueForDecode converts the given value of the given expression to
+// a string for decoding by decodeText or decodeString, returning error
+// diagnostics if that isn't possible. The result may be null, but is always
+// known and unmarked.
+func stringValueForDecode(expr hcl.Expression, srcVal cty.Value) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	strVal, err := convert.Convert(srcVal, cty.String)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: %s", err.Error()),
+			Subject:  expr.StartRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+		return cty.NilVal, diags
+	}
+	if !strVal.IsWhollyKnown() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   "Unsuitable value: value must be known",
+			Subject:  expr.StartRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+		return cty.NilVal, diags
+	}
+	strVal, _ = strVal.Unmark()
+	return strVal, diags
+}
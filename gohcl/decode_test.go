@@ -6,11 +6,15 @@ package gohcl
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	hclJSON "github.com/hashicorp/hcl/v2/json"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -311,6 +315,69 @@ func TestDecodeBody(t *testing.T) {
 			},
 			1,
 		},
+		{
+			map[string]interface{}{},
+			makeInstantiateType(struct {
+				Noodle struct {
+					Name string `hcl:"name"`
+				} `hcl:"noodle,block,optional"`
+			}{}),
+			func(gotI interface{}) bool {
+				noodle := gotI.(struct {
+					Noodle struct {
+						Name string `hcl:"name"`
+					} `hcl:"noodle,block,optional"`
+				}).Noodle
+				return noodle.Name == ""
+			},
+			0,
+		},
+		{
+			map[string]interface{}{
+				"noodle": map[string]interface{}{
+					"name": "udon",
+				},
+			},
+			makeInstantiateType(struct {
+				Noodle struct {
+					Name string `hcl:"name"`
+				} `hcl:"noodle,block,optional"`
+			}{}),
+			func(gotI interface{}) bool {
+				noodle := gotI.(struct {
+					Noodle struct {
+						Name string `hcl:"name"`
+					} `hcl:"noodle,block,optional"`
+				}).Noodle
+				return noodle.Name == "udon"
+			},
+			0,
+		},
+		{
+			map[string]interface{}{
+				"noodle": []map[string]interface{}{{}, {}},
+			},
+			makeInstantiateType(struct {
+				Noodle struct{} `hcl:"noodle,block,optional"`
+			}{}),
+			func(gotI interface{}) bool {
+				// Generating one diagnostic is good enough for this one.
+				return true
+			},
+			1,
+		},
+		{
+			map[string]interface{}{},
+			makeInstantiateType(struct {
+				Noodle *struct{} `hcl:"noodle,block,optional"`
+			}{}),
+			func(gotI interface{}) bool {
+				return gotI.(struct {
+					Noodle *struct{} `hcl:"noodle,block,optional"`
+				}).Noodle == nil
+			},
+			0,
+		},
 		{
 			map[string]interface{}{
 				"noodle": map[string]interface{}{},
@@ -516,6 +583,110 @@ func TestDecodeBody(t *testing.T) {
 			0,
 		},
 
+		{
+			map[string]interface{}{},
+			makeInstantiateType(struct {
+				Noodles map[string]struct {
+					Name string `hcl:"name,label"`
+				} `hcl:"noodle,block"`
+			}{}),
+			func(gotI interface{}) bool {
+				noodles := gotI.(struct {
+					Noodles map[string]struct {
+						Name string `hcl:"name,label"`
+					} `hcl:"noodle,block"`
+				}).Noodles
+				return noodles != nil && len(noodles) == 0
+			},
+			0,
+		},
+		{
+			map[string]interface{}{
+				"noodle": map[string]interface{}{
+					"foo_foo": map[string]interface{}{
+						"type": "rice",
+					},
+					"bar_baz": map[string]interface{}{
+						"type": "wheat",
+					},
+				},
+			},
+			makeInstantiateType(struct {
+				Noodles map[string]struct {
+					Name string `hcl:"name,label"`
+					Type string `hcl:"type"`
+				} `hcl:"noodle,block"`
+			}{}),
+			func(gotI interface{}) bool {
+				noodles := gotI.(struct {
+					Noodles map[string]struct {
+						Name string `hcl:"name,label"`
+						Type string `hcl:"type"`
+					} `hcl:"noodle,block"`
+				}).Noodles
+				return len(noodles) == 2 &&
+					noodles["foo_foo"].Name == "foo_foo" && noodles["foo_foo"].Type == "rice" &&
+					noodles["bar_baz"].Name == "bar_baz" && noodles["bar_baz"].Type == "wheat"
+			},
+			0,
+		},
+		{
+			map[string]interface{}{
+				"noodle": map[string]interface{}{
+					"foo_foo": map[string]interface{}{
+						"type": "rice",
+					},
+				},
+			},
+			makeInstantiateType(struct {
+				Noodles map[string]*struct {
+					Name string `hcl:"name,label"`
+					Type string `hcl:"type"`
+				} `hcl:"noodle,block"`
+			}{}),
+			func(gotI interface{}) bool {
+				noodles := gotI.(struct {
+					Noodles map[string]*struct {
+						Name string `hcl:"name,label"`
+						Type string `hcl:"type"`
+					} `hcl:"noodle,block"`
+				}).Noodles
+				return len(noodles) == 1 && noodles["foo_foo"] != nil && noodles["foo_foo"].Type == "rice"
+			},
+			0,
+		},
+		{
+			map[string]interface{}{
+				"noodle": map[string]interface{}{
+					"foo_foo": []map[string]interface{}{
+						{
+							"type": "rice",
+						},
+						{
+							"type": "wheat",
+						},
+					},
+				},
+			},
+			makeInstantiateType(struct {
+				Noodles map[string]struct {
+					Name string `hcl:"name,label"`
+					Type string `hcl:"type"`
+				} `hcl:"noodle,block"`
+			}{}),
+			func(gotI interface{}) bool {
+				noodles := gotI.(struct {
+					Noodles map[string]struct {
+						Name string `hcl:"name,label"`
+						Type string `hcl:"type"`
+					} `hcl:"noodle,block"`
+				}).Noodles
+				// The first block wins, and the second generates an error.
+				return len(noodles) == 1 && noodles["foo_foo"].Type == "rice"
+			},
+			1, // duplicate noodle block
+		},
+
 		{
 			map[string]interface{}{
 				"name": "Ermintrude",
@@ -715,6 +886,188 @@ func TestDecodeBody(t *testing.T) {
 
 }
 
+func TestDecodeBodyMapOfBlocksDuplicate(t *testing.T) {
+	type Server struct {
+		Name string `hcl:"name,label"`
+		Port int    `hcl:"port"`
+	}
+	type Config struct {
+		Servers map[string]Server `hcl:"server,block"`
+	}
+
+	src := `
+server "a" {
+  port = 1
+}
+server "a" {
+  port = 2
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, nil, &got)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Duplicate server block"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	wantSubject := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 5, Column: 8, Byte: 34},
+		End:      hcl.Pos{Line: 5, Column: 11, Byte: 37},
+	}
+	if got := *diags[0].Subject; got != wantSubject {
+		t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, wantSubject)
+	}
+
+	want := Config{
+		Servers: map[string]Server{
+			"a": {Name: "a", Port: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestDecodeBodyRemainMapWithBlock(t *testing.T) {
+	type Config struct {
+		Name   string               `hcl:"name"`
+		Remain map[string]cty.Value `hcl:",remain"`
+	}
+
+	src := `
+name   = "Ermintrude"
+living = true
+extra {
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, nil, &got)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Unexpected \"extra\" block"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+
+	want := Config{
+		Name: "Ermintrude",
+		Remain: map[string]cty.Value{
+			"living": cty.True,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDecodeBodyExtraLabels(t *testing.T) {
+	type Route struct {
+		Method string   `hcl:"method,label"`
+		Path   []string `hcl:",labels"`
+		Target string   `hcl:"target"`
+	}
+	type Config struct {
+		Routes []Route `hcl:"route,block"`
+	}
+
+	src := `
+route "GET" "users" "list" {
+  target = "a"
+}
+route "POST" {
+  target = "b"
+}
+route {
+  target = "c"
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, nil, &got)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Missing method for route"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+
+	want := Config{
+		Routes: []Route{
+			{Method: "GET", Path: []string{"users", "list"}, Target: "a"},
+			{Method: "POST", Target: "b"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestDecodeBodyWithOptionsDecorateDiagnostic(t *testing.T) {
+	type Config struct {
+		Name string `hcl:"name"`
+	}
+
+	file, diags := hclsyntax.ParseConfig([]byte("name = [1]\nother = 2\n"), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	type errorCode string
+	opts := DecodeOptions{
+		DecorateDiagnostic: func(diag *hcl.Diagnostic) {
+			diag.Extra = errorCode("config")
+			diag.Summary = "Invalid configuration: " + diag.Summary
+		},
+	}
+
+	var got Config
+	diags = DecodeBodyWithOptions(file.Body, nil, &got, opts)
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2\n%s", len(diags), diags.Error())
+	}
+	wantSummaries := []string{
+		"Invalid configuration: Unsupported argument",
+		"Invalid configuration: Unsuitable value type",
+	}
+	for i, diag := range diags {
+		if got, want := diag.Summary, wantSummaries[i]; got != want {
+			t.Errorf("wrong summary %q; want %q", got, want)
+		}
+		if got, want := diag.Extra, errorCode("config"); got != want {
+			t.Errorf("wrong extra %#v; want %#v", got, want)
+		}
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`"a"`), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+	var num int
+	diags = DecodeExpressionWithOptions(expr, nil, &num, opts)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Extra, errorCode("config"); got != want {
+		t.Errorf("wrong extra %#v; want %#v", got, want)
+	}
+}
+
 func TestDecodeExpression(t *testing.T) {
 	tests := []struct {
 		Value     cty.Value
@@ -764,6 +1117,90 @@ func TestDecodeExpression(t *testing.T) {
 			false,
 			1, // bool required
 		},
+		{
+			cty.StringVal("10.0.0.1"),
+			net.IP(nil),
+			net.ParseIP("10.0.0.1"),
+			0, // decoded using encoding.TextUnmarshaler
+		},
+		{
+			cty.StringVal("not an ip"),
+			net.IP(nil),
+			net.IP(nil),
+			1, // UnmarshalText returns an error
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("10.0.0.1")}),
+			net.IP(nil),
+			net.IP(nil),
+			1, // string required
+		},
+		{
+			cty.StringVal("10.0.0.1"),
+			(*net.IP)(nil),
+			func() *net.IP { ip := net.ParseIP("10.0.0.1"); return &ip }(),
+			0,
+		},
+		{
+			cty.NullVal(cty.String),
+			(*net.IP)(nil),
+			(*net.IP)(nil),
+			0, // null leaves a pointer field nil
+		},
+		{
+			cty.StringVal("1m30s"),
+			time.Duration(0),
+			90 * time.Second,
+			0, // decoded using time.ParseDuration
+		},
+		{
+			cty.StringVal("30"),
+			time.Duration(0),
+			time.Duration(0),
+			1, // missing unit in duration
+		},
+		{
+			cty.NumberIntVal(30),
+			time.Duration(0),
+			time.Duration(0),
+			1, // numbers are converted to strings, so have no unit
+		},
+		{
+			cty.NullVal(cty.String),
+			time.Duration(0),
+			time.Duration(0),
+			1, // null value is not allowed
+		},
+		{
+			cty.StringVal("5s"),
+			(*time.Duration)(nil),
+			func() *time.Duration { d := 5 * time.Second; return &d }(),
+			0,
+		},
+		{
+			cty.NullVal(cty.String),
+			(*time.Duration)(nil),
+			(*time.Duration)(nil),
+			0, // null leaves a pointer field nil
+		},
+		{
+			cty.StringVal("https://example.com/path"),
+			(*url.URL)(nil),
+			&url.URL{Scheme: "https", Host: "example.com", Path: "/path"},
+			0, // decoded using url.Parse
+		},
+		{
+			cty.StringVal("://"),
+			(*url.URL)(nil),
+			(*url.URL)(nil),
+			1, // missing protocol scheme
+		},
+		{
+			cty.NullVal(cty.String),
+			(*url.URL)(nil),
+			(*url.URL)(nil),
+			0, // null leaves a pointer field nil
+		},
 	}
 
 	for i, test := range tests {
@@ -787,6 +1224,71 @@ func TestDecodeExpression(t *testing.T) {
 	}
 }
 
+func TestRegisterStringDecoder(t *testing.T) {
+	type level int
+	levelType := reflect.TypeOf(level(0))
+	RegisterStringDecoder(levelType, func(s string) (interface{}, error) {
+		switch s {
+		case "low":
+			return level(1), nil
+		case "high":
+			return level(2), nil
+		default:
+			return nil, fmt.Errorf("must be \"low\" or \"high\"")
+		}
+	})
+	defer RegisterStringDecoder(levelType, nil)
+
+	type config struct {
+		Level   level         `hcl:"level"`
+		Timeout time.Duration `hcl:"timeout,optional"`
+	}
+
+	tests := map[string]struct {
+		src       string
+		want      config
+		wantDiags []string
+	}{
+		"valid": {
+			"level = \"high\"\ntimeout = \"10s\"\n",
+			config{Level: 2, Timeout: 10 * time.Second},
+			nil,
+		},
+		"invalid level": {
+			"level = \"medium\"\n",
+			config{},
+			[]string{`test.hcl:1,9-17: Invalid value; Invalid value: must be "low" or "high".`},
+		},
+		"invalid timeout": {
+			"level = \"low\"\ntimeout = \"soon\"\n",
+			config{Level: 1},
+			[]string{`test.hcl:2,11-17: Invalid value; Invalid value: time: invalid duration "soon".`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			var got config
+			diags = DecodeBody(file.Body, nil, &got)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if !reflect.DeepEqual(gotDiags, test.wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.wantDiags)
+			}
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
 type fixedExpression struct {
 	val cty.Value
 }
@@ -811,3 +1313,144 @@ func makeInstantiateType(target interface{}) func() interface{} {
 		return reflect.New(reflect.TypeOf(target)).Interface()
 	}
 }
+
+type testStep interface {
+	stepName() string
+}
+
+type testHTTPStep struct {
+	Name string `hcl:"name,label"`
+	URL  string `hcl:"url"`
+}
+
+func (s *testHTTPStep) stepName() string { return s.Name }
+
+type testShellStep struct {
+	Name    string `hcl:"name,label"`
+	Type    string `hcl:"type"`
+	Command string `hcl:"command"`
+}
+
+func (s testShellStep) stepName() string { return s.Name }
+
+func TestRegisterBlockImpl(t *testing.T) {
+	stepType := reflect.TypeOf((*testStep)(nil)).Elem()
+	RegisterBlockImpl(stepType, "http", func() interface{} { return &testHTTPStep{} })
+	RegisterBlockImpl(stepType, "shell", func() interface{} { return &testShellStep{} })
+	defer RegisterBlockImpl(stepType, "http", nil)
+	defer RegisterBlockImpl(stepType, "shell", nil)
+
+	type config struct {
+		Steps []testStep          `hcl:"step,block,discriminator=type"`
+		Final testStep            `hcl:"final,block,discriminator=type"`
+		Named map[string]testStep `hcl:"named,block,discriminator=type"`
+	}
+
+	tests := map[string]struct {
+		src       string
+		want      config
+		wantDiags []string
+	}{
+		"valid": {
+			`
+step "a" {
+  type = "http"
+  url  = "https://example.com/"
+}
+step "b" {
+  type    = "shell"
+  command = "true"
+}
+final "c" {
+  type = "http"
+  url  = "https://example.net/"
+}
+named "d" {
+  type    = "shell"
+  command = "false"
+}
+`,
+			config{
+				Steps: []testStep{
+					&testHTTPStep{Name: "a", URL: "https://example.com/"},
+					&testShellStep{Name: "b", Type: "shell", Command: "true"},
+				},
+				Final: &testHTTPStep{Name: "c", URL: "https://example.net/"},
+				Named: map[string]testStep{
+					"d": &testShellStep{Name: "d", Type: "shell", Command: "false"},
+				},
+			},
+			nil,
+		},
+		"unsupported type": {
+			`
+step "a" {
+  type = "ftp"
+}
+`,
+			config{
+				Steps: []testStep{nil},
+				Named: map[string]testStep{},
+			},
+			[]string{`test.hcl:3,10-15: Unsupported step block type; A step block cannot have type "ftp". The supported values are: "http", "shell".`},
+		},
+		"missing type": {
+			`
+final "a" {
+  url = "https://example.com/"
+}
+`,
+			config{
+				Named: map[string]testStep{},
+			},
+			[]string{`test.hcl:2,11-11: Missing required argument; The argument "type" is required, but no definition was found.`},
+		},
+		"invalid type": {
+			`
+final "a" {
+  type = ["http"]
+}
+`,
+			config{
+				Named: map[string]testStep{},
+			},
+			[]string{`test.hcl:3,10-18: Invalid final block type; Unsuitable value for "type": string required.`},
+		},
+		"attribute of other type": {
+			`
+final "a" {
+  type    = "http"
+  url     = "https://example.com/"
+  command = "true"
+}
+`,
+			config{
+				Final: &testHTTPStep{Name: "a", URL: "https://example.com/"},
+				Named: map[string]testStep{},
+			},
+			[]string{`test.hcl:5,3-10: Unsupported argument; An argument named "command" is not expected here.`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			var got config
+			diags = DecodeBody(file.Body, nil, &got)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if !reflect.DeepEqual(gotDiags, test.wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.wantDiags)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// BlockImplFunc is the signature of a function that returns a new value to
+// decode a block into, for use with RegisterBlockImpl. The result must be a
+// non-nil pointer to a struct that uses the same tags as any other struct
+// used for blocks.
+type BlockImplFunc func() interface{}
+
+type blockImpls struct {
+	funcs       map[string]BlockImplFunc
+	labelNames  []string
+	extraLabels bool
+}
+
+var blockImplsByType = map[reflect.Type]*blockImpls{}
+var blockImplsLock sync.RWMutex
+
+// RegisterBlockImpl registers a function that DecodeBody will use to create
+// values to decode blocks into for "block" fields of the given interface
+// type, when the block's discriminator attribute has the given value.
+//
+// A "block" field whose type is an interface type, or a slice of or a map
+// with string keys whose elements are of an interface type, must have a tag
+// that names its discriminator attribute, as in the following example:
+//
+//	Steps []Step `hcl:"step,block,discriminator=type"`
+//
+// Each step block must then have an attribute named "type" whose value is a
+// string given in a call to RegisterBlockImpl for the Step interface, and
+// the rest of the block is decoded into the value returned by the
+// corresponding function. If the struct returned by the function has its own
+// field for the discriminator attribute then that field is populated too.
+// The pointer returned by the function is what is assigned to the field, and
+// so it is the pointer type that must implement the interface.
+//
+// All of the structs registered for a particular interface type must have
+// the same "label" fields, because the labels are decoded before the
+// discriminator attribute. RegisterBlockImpl panics if the function returns
+// a struct whose labels differ from those of a struct already registered,
+// or a pointer that doesn't implement the interface.
+// Registering a function for a value that already has one replaces it, and
+// registering a nil function removes the registration.
+//
+// The registrations are global, and so this is typically called from an
+// init function. It is safe to call concurrently with decoding. Fields of
+// interface type are not supported by the "Encode" family of functions.
+func RegisterBlockImpl(iface reflect.Type, value string, fn BlockImplFunc) {
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("cannot register block implementations for %s: interface type required", iface))
+	}
+
+	blockImplsLock.Lock()
+	defer blockImplsLock.Unlock()
+
+	impls := blockImplsByType[iface]
+	if fn == nil {
+		if impls != nil {
+			delete(impls.funcs, value)
+			if len(impls.funcs) == 0 {
+				delete(blockImplsByType, iface)
+			}
+		}
+		return
+	}
+
+	rv := reflect.ValueOf(fn())
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("block implementation %q for %s must be a non-nil pointer to a struct, not %s", value, iface, rv.Type()))
+	}
+	if !rv.Type().Implements(iface) {
+		panic(fmt.Sprintf("block implementation %q for %s does not implement it: %s", value, iface, rv.Type()))
+	}
+	tags := getFieldTags(rv.Elem().Type())
+	labelNames := make([]string, len(tags.Labels))
+	for i, l := range tags.Labels {
+		labelNames[i] = l.Name
+	}
+	extraLabels := tags.ExtraLabels != nil
+
+	if impls == nil {
+		blockImplsByType[iface] = &blockImpls{
+			funcs:       map[string]BlockImplFunc{value: fn},
+			labelNames:  labelNames,
+			extraLabels: extraLabels,
+		}
+		return
+	}
+	if strings.Join(labelNames, ",") != strings.Join(impls.labelNames, ",") || extraLabels != impls.extraLabels {
+		panic(fmt.Sprintf("block implementation %q for %s must have the same labels as the other implementations", value, iface))
+	}
+	impls.funcs[value] = fn
+}
+
+// blockImplLabels returns the label names of the structs registered for the
+// given interface type, for use in its implied block schema.
+func blockImplLabels(iface reflect.Type) ([]string, bool) {
+	blockImplsLock.RLock()
+	defer blockImplsLock.RUnlock()
+	impls := blockImplsByType[iface]
+	if impls == nil {
+		return nil, false
+	}
+	return impls.labelNames, impls.extraLabels
+}
+
+// blockImplFunc returns the function registered for the given interface type
+// and discriminator value, along with all of the values that have functions
+// registered, for use in error messages.
+func blockImplFunc(iface reflect.Type, value string) (BlockImplFunc, []string) {
+	blockImplsLock.RLock()
+	defer blockImplsLock.RUnlock()
+	impls := blockImplsByType[iface]
+	if impls == nil {
+		return nil, nil
+	}
+	if fn, ok := impls.funcs[value]; ok {
+		return fn, nil
+	}
+	values := make([]string, 0, len(impls.funcs))
+	for v := range impls.funcs {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return nil, values
+}
+
+// decodeDiscriminatedBlock decodes the given block into a new value of a
+// type registered for the given interface type, chosen by the value of the
+// block's discriminator attribute.
+func decodeDiscriminatedBlock(block *hcl.Block, ctx *hcl.EvalContext, iface reflect.Type, attrName string) (reflect.Value, hcl.Diagnostics) {
+	content, remain, diags := block.Body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: attrName, Required: true},
+		},
+	})
+	if content == nil || content.Attributes[attrName] == nil {
+		return reflect.Value{}, diags
+	}
+	attr := content.Attributes[attrName]
+
+	val, valDiags := attr.Expr.Value(ctx)
+	diags = append(diags, valDiags...)
+	if valDiags.HasErrors() {
+		return reflect.Value{}, diags
+	}
+	val, err := convert.Convert(val, cty.String)
+	if err == nil && (val.IsNull() || !val.IsWhollyKnown()) {
+		err = fmt.Errorf("a known string is required")
+	}
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     fmt.Sprintf("Invalid %s block %s", block.Type, attrName),
+			Detail:      fmt.Sprintf("Unsuitable value for %q: %s.", attrName, err),
+			Subject:     attr.Expr.Range().Ptr(),
+			Context:     attr.Range.Ptr(),
+			Expression:  attr.Expr,
+			EvalContext: ctx,
+		})
+		return reflect.Value{}, diags
+	}
+	value, _ := val.Unmark()
+
+	fn, values := blockImplFunc(iface, value.AsString())
+	if fn == nil {
+		detail := fmt.Sprintf("A %s block cannot have %s %q.", block.Type, attrName, value.AsString())
+		if len(values) > 0 {
+			detail = fmt.Sprintf("%s The supported values are: %s.", detail, strings.Join(quoteAll(values), ", "))
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     fmt.Sprintf("Unsupported %s block %s", block.Type, attrName),
+			Detail:      detail,
+			Subject:     attr.Expr.Range().Ptr(),
+			Context:     attr.Range.Ptr(),
+			Expression:  attr.Expr,
+			EvalContext: ctx,
+		})
+		return reflect.Value{}, diags
+	}
+
+	rv := reflect.ValueOf(fn())
+
+	// If the struct doesn't capture the discriminator attribute itself then
+	// we decode only the remainder of the body, so that the attribute is not
+	// reported as unexpected.
+	decodeBlock := *block
+	if schema, _ := ImpliedBodySchema(rv.Interface()); !schemaHasAttribute(schema, attrName) {
+		decodeBlock.Body = remain
+	}
+	diags = append(diags, decodeBlockToValue(&decodeBlock, ctx, rv.Elem())...)
+	return rv, diags
+}
+
+func schemaHasAttribute(schema *hcl.BodySchema, name string) bool {
+	for _, attrS := range schema.Attributes {
+		if attrS.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAll(strs []string) []string {
+	ret := make([]string, len(strs))
+	for i, s := range strs {
+		ret[i] = fmt.Sprintf("%q", s)
+	}
+	return ret
+}
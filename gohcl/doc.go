@@ -25,11 +25,32 @@
 //
 // "attr" fields may either be of type *hcl.Expression, in which case the raw
 // expression is assigned, or of any type accepted by gocty, in which case
-// gocty will be used to assign the value to a native Go type.
+// gocty will be used to assign the value to a native Go type. If the field
+// type implements encoding.TextUnmarshaler then the attribute value is
+// instead converted to a string and passed to its UnmarshalText method, and
+// encoding.TextMarshaler is used in the same way when encoding such fields.
+// Fields of type time.Duration or *url.URL are decoded from strings such as
+// "30s" or "https://example.com/", and RegisterStringDecoder can add
+// similar support for other types.
 //
 // "block" fields may be a struct that recursively uses the same tags, or a
 // slice of such structs, in which case multiple blocks of the corresponding
-// type are decoded into the slice.
+// type are decoded into the slice. A "block" field may also be a map with
+// string keys whose elements are such structs, in which case the struct must
+// have at least one "label" field and the first label of each block is used
+// as its map key. Each block must then have a distinct first label.
+//
+// A struct-typed "block" field is required unless its tag is written as
+// "name,block,optional", in which case an absent block leaves the field as
+// its zero value. A pointer-typed "block" field is always optional, and is
+// left as nil when the block is absent.
+//
+// A "block" field may also be of an interface type, or a slice or map of
+// such, if its tag includes a discriminator option as in
+// "name,block,discriminator=type". The value of the named attribute in each
+// block then selects the struct type to decode the rest of the block into,
+// from among those registered for the interface type with
+// RegisterBlockImpl. Such a field is left as nil when the block is absent.
 //
 // "body" can be placed on a single field of type hcl.Body to capture
 // the full hcl.Body that was decoded for a block. This does not allow leftover
@@ -43,6 +64,12 @@
 // the blocks being decoded. In this case, the name token is used only as
 // an identifier for the label in diagnostic messages.
 //
+// "labels" can be placed on a single field of type []string in such a struct,
+// declared after any "label" fields, to capture any number of additional
+// labels that follow those captured by the "label" fields. This is supported
+// only for the native syntax, because the JSON syntax requires a fixed
+// number of labels.
+//
 // "optional" fields behave like "attr" fields, but they are optional
 // and will not give parsing errors if they are missing.
 //
@@ -52,6 +79,12 @@
 // present then any attributes or blocks not matched by another valid tag
 // will cause an error diagnostic.
 //
+// A "remain" field may instead be a map, such as map[string]cty.Value, in
+// which case each remaining attribute is evaluated and decoded into an
+// element of the map in the same way as for an "attr" field. Remaining
+// blocks are not permitted in that case and will cause an error diagnostic,
+// so use a field of type hcl.Body to capture leftover blocks.
+//
 // Only a subset of this tagging/typing vocabulary is supported for the
 // "Encode" family of functions. See the EncodeIntoBody docs for full details
 // on the constraints there.
@@ -4,11 +4,13 @@
 package gohcl
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
 	"sort"
 
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
 
@@ -23,6 +25,9 @@ import (
 // into hcl.Attributes values. This function does not have enough information
 // to complete the decoding of these types.
 //
+// Attribute fields whose values implement encoding.TextMarshaler are encoded
+// as strings using their MarshalText method.
+//
 // Any fields tagged as "label" are ignored by this function. Use EncodeAsBlock
 // to produce a whole hclwrite.Block including block labels.
 //
@@ -35,8 +40,31 @@ import (
 // the struct fields, with blank lines around nested blocks of different types.
 // Fields representing attributes should usually precede those representing
 // blocks so that the attributes can group togather in the result. For more
-// control, use the hclwrite API directly.
+// control, use EncodeIntoBodyWithOptions or the hclwrite API directly.
 func EncodeIntoBody(val interface{}, dst *hclwrite.Body) {
+	EncodeIntoBodyWithOptions(val, dst, EncodeOptions{})
+}
+
+// EncodeOptions controls the layout of the HCL source produced by
+// EncodeIntoBodyWithOptions and EncodeAsBlockWithOptions. The zero value
+// selects the default layout used by EncodeIntoBody.
+//
+// The options apply recursively to the bodies of any nested blocks.
+type EncodeOptions struct {
+	// AttributesFirst causes all attributes to be written before any nested
+	// blocks, regardless of the order of the corresponding struct fields.
+	// Attributes and blocks otherwise retain their relative field order.
+	AttributesFirst bool
+
+	// BlankLineBetweenBlocks causes a blank line to be written between each
+	// pair of consecutive nested blocks, rather than only between blocks of
+	// different types.
+	BlankLineBetweenBlocks bool
+}
+
+// EncodeIntoBodyWithOptions is like EncodeIntoBody, but allows some control
+// over the layout of the result using the given options.
+func EncodeIntoBodyWithOptions(val interface{}, dst *hclwrite.Body, opts EncodeOptions) {
 	rv := reflect.ValueOf(val)
 	ty := rv.Type()
 	if ty.Kind() == reflect.Ptr {
@@ -48,7 +76,7 @@ func EncodeIntoBody(val interface{}, dst *hclwrite.Body) {
 	}
 
 	tags := getFieldTags(ty)
-	populateBody(rv, ty, tags, dst)
+	populateBody(rv, ty, tags, dst, opts)
 }
 
 // EncodeAsBlock creates a new hclwrite.Block populated with the data from
@@ -61,6 +89,12 @@ func EncodeIntoBody(val interface{}, dst *hclwrite.Body) {
 // This function has the same constraints as EncodeIntoBody and will panic
 // if they are violated.
 func EncodeAsBlock(val interface{}, blockType string) *hclwrite.Block {
+	return EncodeAsBlockWithOptions(val, blockType, EncodeOptions{})
+}
+
+// EncodeAsBlockWithOptions is like EncodeAsBlock, but allows some control
+// over the layout of the block body using the given options.
+func EncodeAsBlockWithOptions(val interface{}, blockType string, opts EncodeOptions) *hclwrite.Block {
 	rv := reflect.ValueOf(val)
 	ty := rv.Type()
 	if ty.Kind() == reflect.Ptr {
@@ -79,13 +113,16 @@ func EncodeAsBlock(val interface{}, blockType string) *hclwrite.Block {
 		// but if not then we'll still do something reasonable.
 		labels[i] = fmt.Sprintf("%s", lv.Interface())
 	}
+	if tags.ExtraLabels != nil {
+		labels = append(labels, rv.Field(*tags.ExtraLabels).Interface().([]string)...)
+	}
 
 	block := hclwrite.NewBlock(blockType, labels)
-	populateBody(rv, ty, tags, block.Body())
+	populateBody(rv, ty, tags, block.Body(), opts)
 	return block
 }
 
-func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwrite.Body) {
+func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwrite.Body, opts EncodeOptions) {
 	nameIdxs := make(map[string]int, len(tags.Attributes)+len(tags.Blocks))
 	namesOrder := make([]string, 0, len(tags.Attributes)+len(tags.Blocks))
 	for n, i := range tags.Attributes {
@@ -98,12 +135,26 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 	}
 	sort.SliceStable(namesOrder, func(i, j int) bool {
 		ni, nj := namesOrder[i], namesOrder[j]
+		if opts.AttributesFirst {
+			_, iIsAttr := tags.Attributes[ni]
+			_, jIsAttr := tags.Attributes[nj]
+			if iIsAttr != jIsAttr {
+				return iIsAttr
+			}
+		}
 		return nameIdxs[ni] < nameIdxs[nj]
 	})
 
 	dst.Clear()
 
 	prevWasBlock := false
+	appendBlock := func(block *hclwrite.Block) {
+		if !prevWasBlock || opts.BlankLineBetweenBlocks {
+			dst.AppendNewline()
+			prevWasBlock = true
+		}
+		dst.AppendBlock(block)
+	}
 	for _, name := range namesOrder {
 		fieldIdx := nameIdxs[name]
 		field := ty.Field(fieldIdx)
@@ -131,6 +182,15 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 				prevWasBlock = false
 			}
 
+			if tm, ok := textMarshalerValue(fieldVal); ok {
+				text, err := tm.MarshalText()
+				if err != nil {
+					panic(fmt.Sprintf("failed to encode %T as text: %s", fieldVal.Interface(), err))
+				}
+				dst.SetAttributeValue(name, cty.StringVal(string(text)))
+				continue
+			}
+
 			valTy, err := gocty.ImpliedType(fieldVal.Interface())
 			if err != nil {
 				panic(fmt.Sprintf("cannot encode %T as HCL expression: %s", fieldVal.Interface(), err))
@@ -148,9 +208,14 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 		} else { // must be a block, then
 			elemTy := fieldTy
 			isSeq := false
-			if elemTy.Kind() == reflect.Slice || elemTy.Kind() == reflect.Array {
+			isMap := false
+			switch elemTy.Kind() {
+			case reflect.Slice, reflect.Array:
 				isSeq = true
 				elemTy = elemTy.Elem()
+			case reflect.Map:
+				isMap = true
+				elemTy = elemTy.Elem()
 			}
 
 			if bodyType.AssignableTo(elemTy) || attrsType.AssignableTo(elemTy) {
@@ -158,7 +223,25 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 			}
 			prevWasBlock = false
 
-			if isSeq {
+			if isMap {
+				if !fieldVal.IsValid() {
+					continue // ignore (field value is nil pointer)
+				}
+				// The block labels come from the label fields of each
+				// element, so the keys are used only to produce the blocks
+				// in a predictable order.
+				keys := fieldVal.MapKeys()
+				sort.Slice(keys, func(i, j int) bool {
+					return keys[i].String() < keys[j].String()
+				})
+				for _, key := range keys {
+					elemVal := fieldVal.MapIndex(key)
+					if elemTy.Kind() == reflect.Ptr && elemVal.IsNil() {
+						continue // ignore
+					}
+					appendBlock(EncodeAsBlockWithOptions(elemVal.Interface(), name, opts))
+				}
+			} else if isSeq {
 				l := fieldVal.Len()
 				for i := 0; i < l; i++ {
 					elemVal := fieldVal.Index(i)
@@ -168,12 +251,7 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 					if elemTy.Kind() == reflect.Ptr && elemVal.IsNil() {
 						continue // ignore
 					}
-					block := EncodeAsBlock(elemVal.Interface(), name)
-					if !prevWasBlock {
-						dst.AppendNewline()
-						prevWasBlock = true
-					}
-					dst.AppendBlock(block)
+					appendBlock(EncodeAsBlockWithOptions(elemVal.Interface(), name, opts))
 				}
 			} else {
 				if !fieldVal.IsValid() {
@@ -182,13 +260,25 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 				if elemTy.Kind() == reflect.Ptr && fieldVal.IsNil() {
 					continue // ignore
 				}
-				block := EncodeAsBlock(fieldVal.Interface(), name)
-				if !prevWasBlock {
-					dst.AppendNewline()
-					prevWasBlock = true
-				}
-				dst.AppendBlock(block)
+				appendBlock(EncodeAsBlockWithOptions(fieldVal.Interface(), name, opts))
 			}
 		}
 	}
 }
+
+// textMarshalerValue returns the given value as an encoding.TextMarshaler if
+// either it or, when addressable, a pointer to it implements that interface.
+func textMarshalerValue(v reflect.Value) (encoding.TextMarshaler, bool) {
+	ty := v.Type()
+	if ty == bigFloatPtrType.Elem() || ty == bigIntPtrType.Elem() || ty == bigFloatPtrType || ty == bigIntPtrType {
+		// gocty encodes these natively as numbers
+		return nil, false
+	}
+	if ty.Implements(textMarshalerIface) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+	if v.CanAddr() && reflect.PtrTo(ty).Implements(textMarshalerIface) {
+		return v.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
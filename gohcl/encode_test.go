@@ -5,6 +5,7 @@ package gohcl_test
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclwrite"
@@ -65,3 +66,98 @@ func ExampleEncodeIntoBody() {
 	//   executable = ["./worker"]
 	// }
 }
+
+func ExampleEncodeIntoBody_mapOfBlocks() {
+	type Server struct {
+		Name string `hcl:"name,label"`
+		Port int    `hcl:"port"`
+	}
+	type Config struct {
+		Servers map[string]Server `hcl:"server,block"`
+	}
+
+	config := Config{
+		Servers: map[string]Server{
+			"web":    {Name: "web", Port: 8080},
+			"backup": {Name: "backup", Port: 8081},
+		},
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&config, f.Body())
+	fmt.Printf("%s", f.Bytes())
+
+	// Output:
+	// server "backup" {
+	//   port = 8081
+	// }
+	// server "web" {
+	//   port = 8080
+	// }
+}
+
+func ExampleEncodeIntoBody_textMarshaler() {
+	type Listener struct {
+		Address net.IP `hcl:"address"`
+		Port    int    `hcl:"port"`
+	}
+
+	listener := Listener{
+		Address: net.ParseIP("10.0.0.1"),
+		Port:    8080,
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&listener, f.Body())
+	fmt.Printf("%s", f.Bytes())
+
+	// Output:
+	// address = "10.0.0.1"
+	// port    = 8080
+}
+
+func ExampleEncodeIntoBodyWithOptions() {
+	type Service struct {
+		Name string   `hcl:"name,label"`
+		Exe  []string `hcl:"executable"`
+	}
+	type App struct {
+		Services []Service `hcl:"service,block"`
+		Name     string    `hcl:"name"`
+		Desc     string    `hcl:"description"`
+	}
+
+	app := App{
+		Name: "awesome-app",
+		Desc: "Such an awesome application",
+		Services: []Service{
+			{
+				Name: "web",
+				Exe:  []string{"./web", "--listen=:8080"},
+			},
+			{
+				Name: "worker",
+				Exe:  []string{"./worker"},
+			},
+		},
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBodyWithOptions(&app, f.Body(), gohcl.EncodeOptions{
+		AttributesFirst:        true,
+		BlankLineBetweenBlocks: true,
+	})
+	fmt.Printf("%s", f.Bytes())
+
+	// Output:
+	// name        = "awesome-app"
+	// description = "Such an awesome application"
+	//
+	// service "web" {
+	//   executable = ["./web", "--listen=:8080"]
+	// }
+	//
+	// service "worker" {
+	//   executable = ["./worker"]
+	// }
+}
@@ -77,18 +77,52 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 		idx := tags.Blocks[n]
 		field := ty.Field(idx)
 		fty := field.Type
-		if fty.Kind() == reflect.Slice {
+		isMap := false
+		switch fty.Kind() {
+		case reflect.Slice:
+			fty = fty.Elem()
+		case reflect.Map:
+			if fty.Key().Kind() != reflect.String {
+				panic(fmt.Sprintf(
+					"hcl 'block' tag kind cannot be applied to %s field %s: map key must be string", field.Type.String(), field.Name,
+				))
+			}
+			isMap = true
 			fty = fty.Elem()
 		}
 		if fty.Kind() == reflect.Ptr {
 			fty = fty.Elem()
 		}
+		if _, ok := tags.Discriminators[n]; ok {
+			if fty.Kind() != reflect.Interface {
+				panic(fmt.Sprintf(
+					"hcl 'discriminator' option cannot be applied to %s field %s: interface required", field.Type.String(), field.Name,
+				))
+			}
+			labelNames, extraLabels := blockImplLabels(fty)
+			if isMap && len(labelNames) == 0 {
+				panic(fmt.Sprintf(
+					"hcl 'block' tag kind cannot be applied to %s field %s: map element implementations must have at least one label", field.Type.String(), field.Name,
+				))
+			}
+			blockSchemas = append(blockSchemas, hcl.BlockHeaderSchema{
+				Type:        n,
+				LabelNames:  labelNames,
+				ExtraLabels: extraLabels,
+			})
+			continue
+		}
 		if fty.Kind() != reflect.Struct {
 			panic(fmt.Sprintf(
 				"hcl 'block' tag kind cannot be applied to %s field %s: struct required", field.Type.String(), field.Name,
 			))
 		}
 		ftags := getFieldTags(fty)
+		if isMap && len(ftags.Labels) == 0 {
+			panic(fmt.Sprintf(
+				"hcl 'block' tag kind cannot be applied to %s field %s: map element struct must have at least one label", field.Type.String(), field.Name,
+			))
+		}
 		var labelNames []string
 		if len(ftags.Labels) > 0 {
 			labelNames = make([]string, len(ftags.Labels))
@@ -98,8 +132,9 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 		}
 
 		blockSchemas = append(blockSchemas, hcl.BlockHeaderSchema{
-			Type:       n,
-			LabelNames: labelNames,
+			Type:        n,
+			LabelNames:  labelNames,
+			ExtraLabels: ftags.ExtraLabels != nil,
 		})
 	}
 
@@ -112,12 +147,17 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 }
 
 type fieldTags struct {
-	Attributes map[string]int
-	Blocks     map[string]int
-	Labels     []labelField
-	Remain     *int
-	Body       *int
-	Optional   map[string]bool
+	Attributes  map[string]int
+	Blocks      map[string]int
+	Labels      []labelField
+	ExtraLabels *int
+	Remain      *int
+	Body        *int
+	Optional    map[string]bool
+
+	// Discriminators maps the names of "block" fields whose elements are
+	// of an interface type to the name of their discriminator attribute.
+	Discriminators map[string]string
 }
 
 type labelField struct {
@@ -130,6 +170,8 @@ func getFieldTags(ty reflect.Type) *fieldTags {
 		Attributes: map[string]int{},
 		Blocks:     map[string]int{},
 		Optional:   map[string]bool{},
+
+		Discriminators: map[string]string{},
 	}
 
 	ct := ty.NumField()
@@ -150,16 +192,39 @@ func getFieldTags(ty reflect.Type) *fieldTags {
 			kind = "attr"
 		}
 
+		if rest, attrName, ok := strings.Cut(kind, ",discriminator="); ok {
+			if rest != "block" && rest != "block,optional" {
+				panic(fmt.Sprintf("hcl 'discriminator' option is valid only for 'block' fields, not %q on %s %q", rest, field.Type.String(), field.Name))
+			}
+			kind = rest
+			ret.Discriminators[name] = attrName
+		}
+
 		switch kind {
 		case "attr":
 			ret.Attributes[name] = i
 		case "block":
 			ret.Blocks[name] = i
+		case "block,optional":
+			ret.Blocks[name] = i
+			ret.Optional[name] = true
 		case "label":
+			if ret.ExtraLabels != nil {
+				panic(fmt.Sprintf("'label' field %q must be declared before the 'labels' field", field.Name))
+			}
 			ret.Labels = append(ret.Labels, labelField{
 				FieldIndex: i,
 				Name:       name,
 			})
+		case "labels":
+			if ret.ExtraLabels != nil {
+				panic("only one 'labels' tag is permitted")
+			}
+			if field.Type != stringSliceType {
+				panic(fmt.Sprintf("hcl 'labels' tag kind cannot be applied to %s field %s: []string required", field.Type.String(), field.Name))
+			}
+			idx := i // copy, because this loop will continue assigning to i
+			ret.ExtraLabels = &idx
 		case "remain":
 			if ret.Remain != nil {
 				panic("only one 'remain' tag is permitted")
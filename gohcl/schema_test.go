@@ -93,6 +93,24 @@ func TestImpliedBodySchema(t *testing.T) {
 			},
 			false,
 		},
+		{
+			struct {
+				Thing struct {
+					Type string   `hcl:"type,label"`
+					Rest []string `hcl:",labels"`
+				} `hcl:"thing,block"`
+			}{},
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:        "thing",
+						LabelNames:  []string{"type"},
+						ExtraLabels: true,
+					},
+				},
+			},
+			false,
+		},
 		{
 			struct {
 				Thing []struct {
@@ -127,6 +145,22 @@ func TestImpliedBodySchema(t *testing.T) {
 			},
 			false,
 		},
+		{
+			struct {
+				Things map[string]*struct {
+					Name string `hcl:"name,label"`
+				} `hcl:"thing,block"`
+			}{},
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:       "thing",
+						LabelNames: []string{"name"},
+					},
+				},
+			},
+			false,
+		},
 		{
 			struct {
 				Thing struct {
@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// StringDecoderFunc is the signature of a function that decodes a string into
+// a Go value of a particular type, for use with RegisterStringDecoder. It
+// returns an error if the string is not a valid representation of a value of
+// that type.
+type StringDecoderFunc func(s string) (interface{}, error)
+
+var stringDecoders = map[reflect.Type]StringDecoderFunc{
+	reflect.TypeOf(time.Duration(0)): func(s string) (interface{}, error) {
+		return time.ParseDuration(s)
+	},
+	reflect.TypeOf((*url.URL)(nil)): func(s string) (interface{}, error) {
+		return url.Parse(s)
+	},
+}
+var stringDecodersLock sync.RWMutex
+
+// RegisterStringDecoder registers a function that DecodeExpression and
+// DecodeBody will use to decode values into Go values of the given type.
+// The expression result is converted to a string and passed to the given
+// function, and any error it returns is reported as an error diagnostic on
+// the expression. The function must return values that are assignable to
+// the given type.
+//
+// The registered function is also used for a pointer to the given type. A
+// null expression result leaves a pointer nil, and is an error for any other
+// type.
+//
+// Functions are registered by default for time.Duration, using
+// time.ParseDuration, and for *url.URL, using url.Parse. Registering a
+// function for a type replaces any function previously registered for it,
+// including the default ones, and takes precedence over any
+// encoding.TextUnmarshaler implementation of the type. Registering a nil
+// function removes the registration.
+//
+// The registrations are global, and so this is typically called from an
+// init function. It is safe to call concurrently with decoding.
+func RegisterStringDecoder(ty reflect.Type, fn StringDecoderFunc) {
+	stringDecodersLock.Lock()
+	defer stringDecodersLock.Unlock()
+	if fn == nil {
+		delete(stringDecoders, ty)
+		return
+	}
+	stringDecoders[ty] = fn
+}
+
+// stringDecoderTarget returns the value that DecodeExpression should decode
+// into using a registered StringDecoderFunc, along with that function and
+// the type it was registered for, if any. val is the pointer given to
+// DecodeExpression, and so the result is always settable. The result is
+// either of the registered type or a pointer to it.
+func stringDecoderTarget(val interface{}) (reflect.Value, reflect.Type, StringDecoderFunc, bool) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return reflect.Value{}, nil, nil, false
+	}
+	ev := rv.Elem()
+
+	stringDecodersLock.RLock()
+	defer stringDecodersLock.RUnlock()
+	if fn, ok := stringDecoders[ev.Type()]; ok {
+		return ev, ev.Type(), fn, true
+	}
+	if ev.Kind() == reflect.Ptr {
+		if fn, ok := stringDecoders[ev.Type().Elem()]; ok {
+			return ev, ev.Type().Elem(), fn, true
+		}
+	}
+	return reflect.Value{}, nil, nil, false
+}
+
+// decodeString decodes the given value into target using the given function,
+// which was registered for type ty. The target is either of that type or a
+// pointer to it. If the given value is null then a pointer-typed target is
+// set to nil.
+func decodeString(expr hcl.Expression, srcVal cty.Value, target reflect.Value, ty reflect.Type, fn StringDecoderFunc) hcl.Diagnostics {
+	strVal, diags := stringValueForDecode(expr, srcVal)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if strVal.IsNull() {
+		if target.Kind() == reflect.Ptr {
+			target.Set(reflect.Zero(target.Type()))
+			return diags
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   "Unsuitable value: value must not be null",
+			Subject:  expr.StartRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+		return diags
+	}
+
+	raw, err := fn(strVal.AsString())
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value",
+			Detail:   fmt.Sprintf("Invalid value: %s.", err.Error()),
+			Subject:  expr.Range().Ptr(),
+		})
+		return diags
+	}
+	result := reflect.ValueOf(raw)
+	if !result.IsValid() || !result.Type().AssignableTo(ty) {
+		panic(fmt.Sprintf("string decoder for %s returned %T", ty, raw))
+	}
+
+	if target.Type() == ty {
+		target.Set(result)
+	} else {
+		ptr := reflect.New(ty)
+		ptr.Elem().Set(result)
+		target.Set(ptr)
+	}
+	return diags
+}
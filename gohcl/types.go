@@ -4,6 +4,8 @@
 package gohcl
 
 import (
+	"encoding"
+	"math/big"
 	"reflect"
 
 	"github.com/hashicorp/hcl/v2"
@@ -17,3 +19,8 @@ var bodyType = reflect.TypeOf(&victimBody).Elem()
 var blockType = reflect.TypeOf((*hcl.Block)(nil))
 var attrType = reflect.TypeOf((*hcl.Attribute)(nil))
 var attrsType = reflect.TypeOf(hcl.Attributes(nil))
+var stringSliceType = reflect.TypeOf([]string(nil))
+var textUnmarshalerIface = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var textMarshalerIface = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var bigFloatPtrType = reflect.TypeOf((*big.Float)(nil))
+var bigIntPtrType = reflect.TypeOf((*big.Int)(nil))
@@ -9,6 +9,28 @@ import (
 )
 
 func decode(body hcl.Body, blockLabels []blockLabel, ctx *hcl.EvalContext, spec Spec, partial bool) (cty.Value, hcl.Body, hcl.Diagnostics) {
+	if bs, ok := spec.(bodySpec); ok {
+		val, diags := bs.decodeBody(body, blockLabels, ctx)
+		var leftovers hcl.Body
+		if partial {
+			// A bodySpec consumes the whole body, so nothing is left over.
+			leftovers = hcl.EmptyBody()
+		}
+		return val, leftovers, diags
+	}
+	if bound, ok := bindBody(spec, body); ok {
+		// The wrapped bodySpec consumes the whole body, so the rest of the
+		// body is not unexpected even though the wrappers' schema omits it.
+		content, _, diags := body.PartialContent(ImpliedSchema(bound))
+		val, valDiags := bound.decode(content, blockLabels, ctx)
+		diags = append(diags, valDiags...)
+		var leftovers hcl.Body
+		if partial {
+			leftovers = hcl.EmptyBody()
+		}
+		return val, leftovers, diags
+	}
+
 	schema := ImpliedSchema(spec)
 
 	var content *hcl.BodyContent
@@ -37,3 +59,54 @@ func sourceRange(body hcl.Body, blockLabels []blockLabel, spec Spec) hcl.Range {
 
 	return spec.sourceRange(content, blockLabels)
 }
+
+// bindBody returns a copy of the given spec in which each bodySpec that is
+// reachable only through wrapperSpecs is bound to the given body, so that the
+// wrappers can decode it from their content like any other spec. The result
+// is false if there is no such bodySpec, in which case the spec is returned
+// unchanged.
+func bindBody(spec Spec, body hcl.Body) (Spec, bool) {
+	switch s := spec.(type) {
+	case bodySpec:
+		return &boundBodySpec{spec: spec, body: body}, true
+	case wrapperSpec:
+		bound := false
+		ret := s.withSameBodyChildren(func(child Spec) Spec {
+			child, ok := bindBody(child, body)
+			bound = bound || ok
+			return child
+		})
+		if bound {
+			return ret, true
+		}
+	}
+	return spec, false
+}
+
+// boundBodySpec is a bodySpec bound to the body it is to decode, produced by
+// bindBody.
+type boundBodySpec struct {
+	spec Spec
+	body hcl.Body
+}
+
+func (s *boundBodySpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node: the bound spec decodes the body directly
+}
+
+func (s *boundBodySpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	return s.spec.(bodySpec).decodeBody(s.body, blockLabels, ctx)
+}
+
+// specNeedingVariables implementation
+func (s *boundBodySpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
+	return s.spec.(bodySpec).variablesNeededBody(s.body)
+}
+
+func (s *boundBodySpec) impliedType() cty.Type {
+	return s.spec.impliedType()
+}
+
+func (s *boundBodySpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.spec.sourceRange(content, blockLabels)
+}
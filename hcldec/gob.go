@@ -15,12 +15,28 @@ func init() {
 	gob.Register(ObjectSpec(nil))
 	gob.Register(TupleSpec(nil))
 	gob.Register((*AttrSpec)(nil))
+	gob.Register((*TupleAttrSpec)(nil))
+	gob.Register((*OneOfSpec)(nil))
+	gob.Register((*RequiredIfSpec)(nil))
 	gob.Register((*LiteralSpec)(nil))
 	gob.Register((*ExprSpec)(nil))
 	gob.Register((*BlockSpec)(nil))
+	gob.Register((*AttrOrBlockSpec)(nil))
 	gob.Register((*BlockListSpec)(nil))
 	gob.Register((*BlockSetSpec)(nil))
 	gob.Register((*BlockMapSpec)(nil))
+	gob.Register((*OrderedBlocksSpec)(nil))
 	gob.Register((*BlockLabelSpec)(nil))
+	gob.Register((*BlockLabelPatternSpec)(nil))
+	gob.Register((*BlockPresenceSpec)(nil))
+	gob.Register((*BodyAttrsSpec)(nil))
+	gob.Register((*VariantSpec)(nil))
 	gob.Register((*DefaultSpec)(nil))
+	gob.Register((*EnumSpec)(nil))
+	gob.Register((*LengthSpec)(nil))
+	gob.Register((*NumberRangeSpec)(nil))
+	gob.Register((*DurationSpec)(nil))
+	gob.Register((*ByteSizeSpec)(nil))
+	gob.Register((*ElementTypeSpec)(nil))
+	gob.Register((*WithRangeSpec)(nil))
 }
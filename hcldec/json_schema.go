@@ -0,0 +1,339 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldec
+
+import (
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// SpecToJSONSchema returns an approximate JSON Schema (draft-07) document
+// describing the configuration accepted by the given spec, as it would be
+// written in the JSON variant of HCL. The result is a tree of maps and slices
+// that can be serialized using encoding/json, and is intended for generating
+// documentation rather than for validation.
+//
+// Attributes are described using the JSON types corresponding to their
+// declared cty types, with DynamicPseudoType accepting any value. A block
+// type that appears at most once is described as a nested object, a block
+// type that may appear more than once as an array of objects, and each block
+// label as an additional level of object nesting. Attributes and blocks that
+// the spec requires are listed as required properties.
+//
+// The schema is only approximate. It does not capture HCL JSON's more
+// permissive forms, such as writing a single block as an array or writing a
+// string template in place of a value of some other type, nor constraints
+// that can only be checked during decoding, such as those of ValidateSpec.
+// The blocks of an OrderedBlocksSpec are described as separate arrays, since
+// JSON Schema cannot describe their relative order.
+func SpecToJSONSchema(spec Spec) map[string]interface{} {
+	ret := jsonSchemaForBody(spec)
+	ret["$schema"] = "http://json-schema.org/draft-07/schema#"
+	return ret
+}
+
+// jsonSchemaForBody returns a schema for an object describing a body with
+// the given spec.
+func jsonSchemaForBody(spec Spec) map[string]interface{} {
+	if s, ok := spec.(*BodyAttrsSpec); ok {
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": jsonSchemaForType(s.ElementType),
+		}
+	}
+
+	if s, ok := spec.(*VariantSpec); ok {
+		return jsonSchemaForVariants(s)
+	}
+
+	b := &jsonSchemaBody{
+		properties: map[string]interface{}{},
+		required:   map[string]bool{},
+	}
+	if spec != nil {
+		b.add(spec, false)
+	}
+
+	ret := map[string]interface{}{
+		"type":                 "object",
+		"properties":           b.properties,
+		"additionalProperties": false,
+	}
+	if len(b.required) != 0 {
+		required := make([]string, 0, len(b.required))
+		for name := range b.required {
+			required = append(required, name)
+		}
+		sort.Strings(required)
+		ret["required"] = required
+	}
+	return ret
+}
+
+// jsonSchemaForVariants returns a schema that accepts an object described by
+// any one of the variants of the given spec, each with its discriminator
+// property set to the corresponding value.
+func jsonSchemaForVariants(s *VariantSpec) map[string]interface{} {
+	names := make([]string, 0, len(s.Variants))
+	for name := range s.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	alts := make([]interface{}, 0, len(names)+1)
+	for _, name := range names {
+		alt := jsonSchemaForBody(s.Variants[name])
+		if props, ok := alt["properties"].(map[string]interface{}); ok {
+			props[s.Discriminator] = map[string]interface{}{"const": name}
+			required, _ := alt["required"].([]string)
+			if !containsString(required, s.Discriminator) {
+				required = append(required, s.Discriminator)
+				sort.Strings(required)
+			}
+			alt["required"] = required
+		}
+		alts = append(alts, alt)
+	}
+	if s.Default != nil {
+		// The default variant is used only when the discriminator property
+		// is absent.
+		alt := jsonSchemaForBody(s.Default)
+		if _, ok := alt["properties"]; ok {
+			alt["not"] = map[string]interface{}{
+				"required": []string{s.Discriminator},
+			}
+		}
+		alts = append(alts, alt)
+	}
+	return map[string]interface{}{
+		"anyOf": alts,
+	}
+}
+
+func containsString(strs []string, s string) bool {
+	for _, candidate := range strs {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSchemaBody accumulates the properties of the object describing a
+// single body.
+type jsonSchemaBody struct {
+	properties map[string]interface{}
+	required   map[string]bool
+}
+
+// add adds the properties for the attributes and blocks that the given spec
+// decodes from the body. If optional is set then none of them are required,
+// regardless of what the spec declares.
+func (b *jsonSchemaBody) add(spec Spec, optional bool) {
+	switch s := spec.(type) {
+	case *AttrSpec:
+		b.set(s.Name, jsonSchemaForType(s.Type), s.Required && !optional)
+	case *TupleAttrSpec:
+		b.set(s.Name, jsonSchemaForType(s.impliedType()), s.Required && !optional)
+	case *OneOfSpec:
+		// The constraint on how many of the attributes are set is not
+		// represented, so none of them are required.
+		for _, attrS := range s.Attrs {
+			b.add(attrS, true)
+		}
+	case *AttrOrBlockSpec:
+		b.set(s.Name, jsonSchemaForBody(s.Nested), s.Required && !optional)
+	case *BlockSpec:
+		b.set(s.TypeName, jsonSchemaForBody(s.Nested), s.Required && !optional)
+	case *BlockListSpec:
+		b.set(s.TypeName, jsonSchemaForBlocks(s.Nested, s.MinItems, s.MaxItems, false), s.MinItems > 0 && !optional)
+	case *BlockTupleSpec:
+		b.set(s.TypeName, jsonSchemaForBlocks(s.Nested, s.MinItems, s.MaxItems, false), s.MinItems > 0 && !optional)
+	case *BlockSetSpec:
+		b.set(s.TypeName, jsonSchemaForBlocks(s.Nested, s.MinItems, s.MaxItems, true), s.MinItems > 0 && !optional)
+	case *OrderedBlocksSpec:
+		for typeName, nested := range s.Nested {
+			b.set(typeName, jsonSchemaForBlocks(nested, 0, s.MaxItems, false), false)
+		}
+	case *BlockMapSpec:
+		b.set(s.TypeName, jsonSchemaForLabels(len(s.LabelNames), jsonSchemaForBody(s.Nested)), false)
+	case *BlockObjectSpec:
+		b.set(s.TypeName, jsonSchemaForLabels(len(s.LabelNames), jsonSchemaForBody(s.Nested)), false)
+	case *BlockAttrsSpec:
+		b.set(s.TypeName, jsonSchemaForType(cty.Map(s.ElementType)), s.Required && !optional)
+	case *BlockPresenceSpec:
+		b.set(s.TypeName, jsonSchemaForBody(nil), false)
+	case *DefaultSpec:
+		// The default takes effect when the primary spec's item is absent,
+		// so the items are never required.
+		b.add(s.Primary, true)
+		b.add(s.Default, true)
+	case *EnumSpec:
+		b.add(s.Wrapped, optional)
+		if name, ok := jsonSchemaEnumAttr(s.Wrapped); ok {
+			if prop, ok := b.properties[name].(map[string]interface{}); ok {
+				prop["enum"] = s.Allowed
+			}
+		}
+	case *LengthSpec:
+		b.add(s.Wrapped, optional)
+		if name, ok := jsonSchemaEnumAttr(s.Wrapped); ok {
+			if prop, ok := b.properties[name].(map[string]interface{}); ok {
+				minKey, maxKey := "minItems", "maxItems"
+				if prop["type"] == "object" {
+					minKey, maxKey = "minProperties", "maxProperties"
+				}
+				if s.MinItems > 0 {
+					prop[minKey] = s.MinItems
+				}
+				if s.MaxItems > 0 {
+					prop[maxKey] = s.MaxItems
+				}
+			}
+		}
+	case *NumberRangeSpec:
+		b.add(s.Wrapped, optional)
+		if name, ok := jsonSchemaEnumAttr(s.Wrapped); ok {
+			if prop, ok := b.properties[name].(map[string]interface{}); ok {
+				if s.Min != cty.NilVal {
+					prop["minimum"], _ = s.Min.AsBigFloat().Float64()
+				}
+				if s.Max != cty.NilVal {
+					prop["maximum"], _ = s.Max.AsBigFloat().Float64()
+				}
+			}
+		}
+	default:
+		// All of the other specs either wrap other specs that decode from
+		// the same body or don't decode anything from the body at all.
+		spec.visitSameBodyChildren(func(child Spec) {
+			b.add(child, optional)
+		})
+	}
+}
+
+func (b *jsonSchemaBody) set(name string, schema map[string]interface{}, required bool) {
+	b.properties[name] = schema
+	if required {
+		b.required[name] = true
+	}
+}
+
+// jsonSchemaEnumAttr returns the name of the attribute whose value is
+// constrained by an EnumSpec or other validating spec wrapping the given
+// spec, if any.
+func jsonSchemaEnumAttr(spec Spec) (string, bool) {
+	for {
+		if attrS, ok := spec.(*AttrSpec); ok {
+			return attrS.Name, true
+		}
+		var next Spec
+		count := 0
+		spec.visitSameBodyChildren(func(child Spec) {
+			next = child
+			count++
+		})
+		if count != 1 {
+			return "", false
+		}
+		spec = next
+	}
+}
+
+// jsonSchemaForBlocks returns a schema for an array of objects describing
+// blocks with the given nested spec.
+func jsonSchemaForBlocks(nested Spec, minItems, maxItems int, unique bool) map[string]interface{} {
+	ret := map[string]interface{}{
+		"type":  "array",
+		"items": jsonSchemaForBody(nested),
+	}
+	if minItems > 0 {
+		ret["minItems"] = minItems
+	}
+	if maxItems > 0 {
+		ret["maxItems"] = maxItems
+	}
+	if unique {
+		ret["uniqueItems"] = true
+	}
+	return ret
+}
+
+// jsonSchemaForLabels wraps the given body schema in one level of object
+// nesting for each block label, since HCL JSON represents labels as object
+// property names.
+func jsonSchemaForLabels(count int, body map[string]interface{}) map[string]interface{} {
+	ret := body
+	for i := 0; i < count; i++ {
+		ret = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": ret,
+		}
+	}
+	return ret
+}
+
+// jsonSchemaForType returns a schema describing the JSON values that can be
+// converted to the given type.
+func jsonSchemaForType(ty cty.Type) map[string]interface{} {
+	switch {
+	case ty == cty.String:
+		return map[string]interface{}{"type": "string"}
+	case ty == cty.Number:
+		return map[string]interface{}{"type": "number"}
+	case ty == cty.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case ty.IsListType():
+		return map[string]interface{}{
+			"type":  "array",
+			"items": jsonSchemaForType(ty.ElementType()),
+		}
+	case ty.IsSetType():
+		return map[string]interface{}{
+			"type":        "array",
+			"items":       jsonSchemaForType(ty.ElementType()),
+			"uniqueItems": true,
+		}
+	case ty.IsMapType():
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": jsonSchemaForType(ty.ElementType()),
+		}
+	case ty.IsObjectType():
+		properties := map[string]interface{}{}
+		var required []string
+		for name, aty := range ty.AttributeTypes() {
+			properties[name] = jsonSchemaForType(aty)
+			if !ty.AttributeOptional(name) {
+				required = append(required, name)
+			}
+		}
+		ret := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) != 0 {
+			sort.Strings(required)
+			ret["required"] = required
+		}
+		return ret
+	case ty.IsTupleType():
+		etys := ty.TupleElementTypes()
+		items := make([]interface{}, len(etys))
+		for i, ety := range etys {
+			items[i] = jsonSchemaForType(ety)
+		}
+		return map[string]interface{}{
+			"type":     "array",
+			"items":    items,
+			"minItems": len(etys),
+			"maxItems": len(etys),
+		}
+	default:
+		// cty.DynamicPseudoType and capsule types accept any JSON value,
+		// as far as we can tell statically.
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldec
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSpecToJSONSchema(t *testing.T) {
+	spec := ObjectSpec{
+		"name": &AttrSpec{
+			Name:     "name",
+			Type:     cty.String,
+			Required: true,
+		},
+		"mode": &EnumSpec{
+			Wrapped: &AttrSpec{
+				Name: "mode",
+				Type: cty.String,
+			},
+			Allowed: []string{"fast", "slow"},
+		},
+		"count": &DefaultSpec{
+			Primary: &AttrSpec{
+				Name:     "count",
+				Type:     cty.Number,
+				Required: true,
+			},
+			Default: &LiteralSpec{
+				Value: cty.NumberIntVal(1),
+			},
+		},
+		"tags": &ValidateSpec{
+			Wrapped: &AttrSpec{
+				Name: "tags",
+				Type: cty.Map(cty.String),
+			},
+		},
+		"pair": &AttrSpec{
+			Name: "pair",
+			Type: cty.Tuple([]cty.Type{cty.String, cty.Bool}),
+		},
+		"opts": &AttrSpec{
+			Name: "opts",
+			Type: cty.ObjectWithOptionalAttrs(map[string]cty.Type{
+				"a": cty.List(cty.Number),
+				"b": cty.DynamicPseudoType,
+			}, []string{"b"}),
+		},
+		"rules": &BlockListSpec{
+			TypeName: "rule",
+			Nested: ObjectSpec{
+				"port": &AttrSpec{
+					Name:     "port",
+					Type:     cty.Number,
+					Required: true,
+				},
+				"kind": &BlockLabelSpec{
+					Index: 0,
+					Name:  "kind",
+				},
+			},
+			MinItems: 1,
+		},
+		"services": &BlockMapSpec{
+			TypeName:   "service",
+			LabelNames: []string{"name"},
+			Nested: &BlockSpec{
+				TypeName: "check",
+				Nested:   ObjectSpec{},
+				Required: true,
+			},
+		},
+		"env": &BlockAttrsSpec{
+			TypeName:    "env",
+			ElementType: cty.Set(cty.String),
+		},
+	}
+
+	want := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "additionalProperties": false,
+  "properties": {
+    "count": {
+      "type": "number"
+    },
+    "env": {
+      "additionalProperties": {
+        "items": {
+          "type": "string"
+        },
+        "type": "array",
+        "uniqueItems": true
+      },
+      "type": "object"
+    },
+    "mode": {
+      "enum": [
+        "fast",
+        "slow"
+      ],
+      "type": "string"
+    },
+    "name": {
+      "type": "string"
+    },
+    "opts": {
+      "properties": {
+        "a": {
+          "items": {
+            "type": "number"
+          },
+          "type": "array"
+        },
+        "b": {}
+      },
+      "required": [
+        "a"
+      ],
+      "type": "object"
+    },
+    "pair": {
+      "items": [
+        {
+          "type": "string"
+        },
+        {
+          "type": "boolean"
+        }
+      ],
+      "maxItems": 2,
+      "minItems": 2,
+      "type": "array"
+    },
+    "rule": {
+      "items": {
+        "additionalProperties": false,
+        "properties": {
+          "port": {
+            "type": "number"
+          }
+        },
+        "required": [
+          "port"
+        ],
+        "type": "object"
+      },
+      "minItems": 1,
+      "type": "array"
+    },
+    "service": {
+      "additionalProperties": {
+        "additionalProperties": false,
+        "properties": {
+          "check": {
+            "additionalProperties": false,
+            "properties": {},
+            "type": "object"
+          }
+        },
+        "required": [
+          "check"
+        ],
+        "type": "object"
+      },
+      "type": "object"
+    },
+    "tags": {
+      "additionalProperties": {
+        "type": "string"
+      },
+      "type": "object"
+    }
+  },
+  "required": [
+    "name",
+    "rule"
+  ],
+  "type": "object"
+}`
+
+	src, err := json.Marshal(SpecToJSONSchema(spec))
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, src, "", "  "); err != nil {
+		t.Fatalf("failed to indent schema: %s", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("wrong schema\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSpecToJSONSchemaConstraints(t *testing.T) {
+	spec := ObjectSpec{
+		"zones": &LengthSpec{
+			Wrapped: &AttrSpec{
+				Name: "zones",
+				Type: cty.List(cty.String),
+			},
+			MinItems: 1,
+			MaxItems: 3,
+		},
+		"port": &NumberRangeSpec{
+			Wrapped: &AttrSpec{
+				Name: "port",
+				Type: cty.Number,
+			},
+			Min: cty.NumberIntVal(1),
+			Max: cty.NumberIntVal(65535),
+		},
+	}
+	want := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "additionalProperties": false,
+  "properties": {
+    "port": {
+      "maximum": 65535,
+      "minimum": 1,
+      "type": "number"
+    },
+    "zones": {
+      "items": {
+        "type": "string"
+      },
+      "maxItems": 3,
+      "minItems": 1,
+      "type": "array"
+    }
+  },
+  "type": "object"
+}`
+
+	src, err := json.Marshal(SpecToJSONSchema(spec))
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, src, "", "  "); err != nil {
+		t.Fatalf("failed to indent schema: %s", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("wrong schema\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSpecToJSONSchemaVariants(t *testing.T) {
+	spec := &VariantSpec{
+		Discriminator: "type",
+		Variants: map[string]Spec{
+			"http": ObjectSpec{
+				"url": &AttrSpec{Name: "url", Type: cty.String, Required: true},
+			},
+			"file": ObjectSpec{
+				"path": &AttrSpec{Name: "path", Type: cty.String},
+			},
+		},
+		Default: ObjectSpec{
+			"path": &AttrSpec{Name: "path", Type: cty.String},
+		},
+	}
+	want := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "anyOf": [
+    {
+      "additionalProperties": false,
+      "properties": {
+        "path": {
+          "type": "string"
+        },
+        "type": {
+          "const": "file"
+        }
+      },
+      "required": [
+        "type"
+      ],
+      "type": "object"
+    },
+    {
+      "additionalProperties": false,
+      "properties": {
+        "type": {
+          "const": "http"
+        },
+        "url": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "type",
+        "url"
+      ],
+      "type": "object"
+    },
+    {
+      "additionalProperties": false,
+      "not": {
+        "required": [
+          "type"
+        ]
+      },
+      "properties": {
+        "path": {
+          "type": "string"
+        }
+      },
+      "type": "object"
+    }
+  ]
+}`
+
+	src, err := json.Marshal(SpecToJSONSchema(spec))
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, src, "", "  "); err != nil {
+		t.Fatalf("failed to indent schema: %s", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("wrong schema\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldec
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DecodeWithProvenance is like Decode, but additionally returns the set of
+// paths within the result whose values were produced by the Default spec of
+// a DefaultSpec, because its Primary spec produced a null value, rather than
+// being set in the given body. Values nested inside a defaulted value are
+// not reported separately.
+//
+// The paths are accurate for values produced within the common container
+// specs: ObjectSpec, TupleSpec and all of the block specs, along with the
+// specs that wrap another spec without changing its value, such as
+// ValidateSpec. A path within a BlockSetSpec element uses the element's
+// value as the index key. Because the transform specs can change the shape
+// of a value, a default used within one of them is reported only if it
+// produced the transform's entire input, in which case the path is that of
+// the transform's result.
+//
+// To determine whether a default was used, each DefaultSpec's Primary spec
+// is decoded a second time, and so any expressions it contains are
+// evaluated twice. If decoding produces any errors then the returned set is
+// empty.
+func DecodeWithProvenance(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, cty.PathSet, hcl.Diagnostics) {
+	val, diags := Decode(body, spec, ctx)
+	defaulted := cty.NewPathSet()
+	if diags.HasErrors() {
+		return val, defaulted, diags
+	}
+
+	content, _ := body.Content(ImpliedSchema(spec))
+	w := &provenanceWalker{
+		ctx:       ctx,
+		defaulted: defaulted,
+	}
+	w.walk(content, nil, spec, nil)
+	return val, defaulted, diags
+}
+
+type provenanceWalker struct {
+	ctx       *hcl.EvalContext
+	defaulted cty.PathSet
+}
+
+// walk records the paths of the defaulted values within the value that the
+// given spec would produce for the given content, where path is the path of
+// that value.
+func (w *provenanceWalker) walk(content *hcl.BodyContent, blockLabels []blockLabel, spec Spec, path cty.Path) {
+	switch s := spec.(type) {
+	case ObjectSpec:
+		for k, child := range s {
+			w.walk(content, blockLabels, child, path.GetAttr(k))
+		}
+	case TupleSpec:
+		for i, child := range s {
+			w.walk(content, blockLabels, child, path.IndexInt(i))
+		}
+	case *DefaultSpec:
+		primaryVal, _ := s.Primary.decode(content, blockLabels, w.ctx)
+		if primaryVal.IsNull() {
+			w.defaulted.Add(path)
+			return
+		}
+		w.walk(content, blockLabels, s.Primary, path)
+
+	case *BlockSpec:
+		for _, block := range content.Blocks {
+			if block.Type == s.TypeName {
+				w.walkBlock(block, s.Nested, path)
+				break
+			}
+		}
+	case *AttrOrBlockSpec:
+		for _, block := range content.Blocks {
+			if block.Type == s.Name {
+				w.walkBlock(block, s.Nested, path)
+				break
+			}
+		}
+	case *BlockListSpec:
+		w.walkBlockSequence(content, s.TypeName, s.Nested, path)
+	case *BlockTupleSpec:
+		w.walkBlockSequence(content, s.TypeName, s.Nested, path)
+	case *BlockSetSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName {
+				continue
+			}
+			elem, _, _ := decode(block.Body, labelsForBlock(block), w.ctx, s.Nested, false)
+			w.walkBlock(block, s.Nested, path.Index(elem))
+		}
+	case *BlockMapSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName || len(block.Labels) != len(s.LabelNames) {
+				continue
+			}
+			blockPath := path
+			for _, label := range block.Labels {
+				blockPath = blockPath.Index(cty.StringVal(label))
+			}
+			w.walkBlock(block, s.Nested, blockPath)
+		}
+	case *BlockObjectSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName || len(block.Labels) != len(s.LabelNames) {
+				continue
+			}
+			blockPath := path
+			for _, label := range block.Labels {
+				blockPath = blockPath.GetAttr(label)
+			}
+			w.walkBlock(block, s.Nested, blockPath)
+		}
+	case *OrderedBlocksSpec:
+		i := 0
+		for _, block := range content.Blocks {
+			nested, ok := s.Nested[block.Type]
+			if !ok {
+				continue
+			}
+			w.walkBlock(block, nested, path.IndexInt(i).GetAttr("value"))
+			i++
+		}
+
+	case *TransformExprSpec:
+		w.walkTransformed(content, blockLabels, s.Wrapped, path)
+	case *TransformFuncSpec:
+		w.walkTransformed(content, blockLabels, s.Wrapped, path)
+	case *TransformCallbackSpec:
+		w.walkTransformed(content, blockLabels, s.Wrapped, path)
+
+	default:
+		// The remaining specs either wrap a single spec without changing
+		// its value or don't contain any other specs at all.
+		spec.visitSameBodyChildren(func(child Spec) {
+			w.walk(content, blockLabels, child, path)
+		})
+	}
+}
+
+func (w *provenanceWalker) walkBlock(block *hcl.Block, nested Spec, path cty.Path) {
+	content, _ := block.Body.Content(ImpliedSchema(nested))
+	w.walk(content, labelsForBlock(block), nested, path)
+}
+
+func (w *provenanceWalker) walkBlockSequence(content *hcl.BodyContent, typeName string, nested Spec, path cty.Path) {
+	i := 0
+	for _, block := range content.Blocks {
+		if block.Type != typeName {
+			continue
+		}
+		w.walkBlock(block, nested, path.IndexInt(i))
+		i++
+	}
+}
+
+// walkTransformed records the given path as defaulted only if the entire
+// value of the given wrapped spec is defaulted, since the paths within a
+// transformed value are unrelated to the paths within its input.
+func (w *provenanceWalker) walkTransformed(content *hcl.BodyContent, blockLabels []blockLabel, wrapped Spec, path cty.Path) {
+	inner := &provenanceWalker{
+		ctx:       w.ctx,
+		defaulted: cty.NewPathSet(),
+	}
+	inner.walk(content, blockLabels, wrapped, nil)
+	if inner.defaulted.Has(nil) {
+		w.defaulted.Add(path)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldec
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeWithProvenance(t *testing.T) {
+	defaulted := func(name string, def cty.Value) Spec {
+		return &DefaultSpec{
+			Primary: &AttrSpec{Name: name, Type: def.Type()},
+			Default: &LiteralSpec{Value: def},
+		}
+	}
+	spec := ObjectSpec{
+		"set":   defaulted("set", cty.StringVal("default")),
+		"unset": defaulted("unset", cty.StringVal("default")),
+		"null":  defaulted("null", cty.NumberIntVal(1)),
+		"validated": &ValidateSpec{
+			Wrapped: defaulted("validated", cty.True),
+			Func: func(cty.Value) hcl.Diagnostics {
+				return nil
+			},
+		},
+		"transformed": &TransformCallbackSpec{
+			Wrapped: defaulted("transformed", cty.StringVal("a")),
+			Func: func(v cty.Value) (cty.Value, error) {
+				return cty.ListVal([]cty.Value{v}), nil
+			},
+			Type: cty.List(cty.String),
+		},
+		"single": &BlockSpec{
+			TypeName: "single",
+			Nested: ObjectSpec{
+				"port": defaulted("port", cty.NumberIntVal(80)),
+			},
+		},
+		"list": &BlockListSpec{
+			TypeName: "item",
+			Nested: ObjectSpec{
+				"size": defaulted("size", cty.NumberIntVal(1)),
+			},
+		},
+		"map": &BlockMapSpec{
+			TypeName:   "named",
+			LabelNames: []string{"name"},
+			Nested: TupleSpec{
+				defaulted("enabled", cty.False),
+			},
+		},
+	}
+
+	src := `
+set  = "explicit"
+null = null
+
+single {
+}
+
+item {
+  size = 2
+}
+item {
+}
+
+named "a" {
+  enabled = true
+}
+named "b" {
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	val, paths, diags := DecodeWithProvenance(f.Body, spec, nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected decode errors: %s", diags.Error())
+	}
+	if got, want := val.GetAttr("null"), cty.NumberIntVal(1); !got.RawEquals(want) {
+		t.Errorf("wrong value for null %#v; want %#v", got, want)
+	}
+
+	var got []string
+	for _, path := range paths.List() {
+		got = append(got, testProvenancePathString(path))
+	}
+	sort.Strings(got)
+	want := []string{
+		`.list[1].size`,
+		`.map["b"][0]`,
+		`.null`,
+		`.single.port`,
+		`.transformed`,
+		`.unset`,
+		`.validated`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrong paths\ngot:  %#v\nwant: %#v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wrong paths\ngot:  %#v\nwant: %#v", got, want)
+			break
+		}
+	}
+
+	// Every reported path must exist in the decoded value.
+	for _, path := range paths.List() {
+		if _, err := path.Apply(val); err != nil {
+			t.Errorf("reported path %s is not in the result: %s", testProvenancePathString(path), err)
+		}
+	}
+}
+
+func testProvenancePathString(path cty.Path) string {
+	var s string
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			s += "." + step.Name
+		case cty.IndexStep:
+			if step.Key.Type() == cty.String {
+				s += `["` + step.Key.AsString() + `"]`
+			} else {
+				s += "[" + step.Key.AsBigFloat().String() + "]"
+			}
+		}
+	}
+	return s
+}
@@ -12,6 +12,12 @@ import (
 // the resulting value. If the given body is not valid per the spec, error
 // diagnostics are returned and the returned value is likely to be incomplete.
 //
+// Decoding is strict: any attribute or block in the body, or in the body of
+// any nested block, that is not described by the spec produces an error
+// diagnostic such as "Unsupported argument" whose subject is the name of the
+// unexpected item. Use PartialDecode to permit leftover items at the top
+// level.
+//
 // The ctx argument may be nil, in which case any references to variables or
 // functions will produce error diagnostics.
 func Decode(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
@@ -29,6 +35,42 @@ func PartialDecode(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, h
 	return decode(body, nil, ctx, spec, true)
 }
 
+// DecodeOptions customizes the behavior of DecodeWithOptions and
+// PartialDecodeWithOptions. The zero value selects the behavior of Decode and
+// PartialDecode.
+type DecodeOptions struct {
+	// DecorateDiagnostic, if set, is called for each diagnostic produced
+	// while decoding, just before the diagnostics are returned. It may
+	// modify the given diagnostic in place, such as to set its Extra field
+	// to an error code or to adjust its Summary, so that callers can
+	// classify decoding errors without matching on their messages.
+	DecorateDiagnostic func(diag *hcl.Diagnostic)
+}
+
+// DecodeWithOptions is like Decode, but allows customizing the behavior of
+// the decoder using the given options.
+func DecodeWithOptions(body hcl.Body, spec Spec, ctx *hcl.EvalContext, opts DecodeOptions) (cty.Value, hcl.Diagnostics) {
+	val, diags := Decode(body, spec, ctx)
+	return val, opts.decorate(diags)
+}
+
+// PartialDecodeWithOptions is like PartialDecode, but allows customizing the
+// behavior of the decoder using the given options.
+func PartialDecodeWithOptions(body hcl.Body, spec Spec, ctx *hcl.EvalContext, opts DecodeOptions) (cty.Value, hcl.Body, hcl.Diagnostics) {
+	val, remain, diags := PartialDecode(body, spec, ctx)
+	return val, remain, opts.decorate(diags)
+}
+
+func (opts DecodeOptions) decorate(diags hcl.Diagnostics) hcl.Diagnostics {
+	if opts.DecorateDiagnostic == nil {
+		return diags
+	}
+	for _, diag := range diags {
+		opts.DecorateDiagnostic(diag)
+	}
+	return diags
+}
+
 // ImpliedType returns the value type that should result from decoding the
 // given spec.
 func ImpliedType(spec Spec) cty.Type {
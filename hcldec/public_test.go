@@ -389,6 +389,62 @@ b {
 		},
 		{
 			`
+enabled {}
+`,
+			&BlockPresenceSpec{
+				TypeName: "enabled",
+			},
+			nil,
+			cty.True,
+			0,
+		},
+		{
+			``,
+			&BlockPresenceSpec{
+				TypeName: "enabled",
+			},
+			nil,
+			cty.False,
+			0,
+		},
+		{
+			`
+enabled {}
+enabled {}
+`,
+			&BlockPresenceSpec{
+				TypeName: "enabled",
+			},
+			nil,
+			cty.True,
+			1, // duplicate enabled block
+		},
+		{
+			`
+enabled {
+  foo = "bar"
+}
+`,
+			&BlockPresenceSpec{
+				TypeName: "enabled",
+			},
+			nil,
+			cty.True,
+			1, // unsupported argument
+		},
+		{
+			`
+enabled "foo" {}
+`,
+			&BlockPresenceSpec{
+				TypeName: "enabled",
+			},
+			nil,
+			cty.False,
+			1, // extraneous label
+		},
+		{
+			`
 b {}
 b {}
 `,
@@ -1074,6 +1130,85 @@ b "bar" {
 	}
 }
 
+func TestDecodeUnsupportedArgument(t *testing.T) {
+	config := "count = 1\nnested {\n  cont = 3\n}\n"
+	spec := &ObjectSpec{
+		"count": &AttrSpec{
+			Name: "count",
+			Type: cty.Number,
+		},
+		"nested": &BlockSpec{
+			TypeName: "nested",
+			Nested: &ObjectSpec{
+				"count": &AttrSpec{
+					Name: "count",
+					Type: cty.Number,
+				},
+			},
+		},
+	}
+
+	file, parseDiags := hclsyntax.ParseConfig([]byte(config), "", hcl.InitialPos)
+	if parseDiags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", parseDiags.Error())
+	}
+
+	_, diags := Decode(file.Body, spec, nil)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Unsupported argument"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	want := hcl.Range{
+		Start: hcl.Pos{Line: 3, Column: 3, Byte: 21},
+		End:   hcl.Pos{Line: 3, Column: 7, Byte: 25},
+	}
+	if got := *diags[0].Subject; got != want {
+		t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDecodeWithOptionsDecorateDiagnostic(t *testing.T) {
+	config := "count = \"many\"\nother = 1\n"
+	spec := &ObjectSpec{
+		"count": &AttrSpec{
+			Name: "count",
+			Type: cty.Number,
+		},
+	}
+
+	file, parseDiags := hclsyntax.ParseConfig([]byte(config), "", hcl.InitialPos)
+	if parseDiags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", parseDiags.Error())
+	}
+
+	type errorCode string
+	opts := DecodeOptions{
+		DecorateDiagnostic: func(diag *hcl.Diagnostic) {
+			diag.Extra = errorCode("E" + diag.Summary)
+		},
+	}
+
+	_, diags := DecodeWithOptions(file.Body, spec, nil, opts)
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2\n%s", len(diags), diags.Error())
+	}
+	for _, diag := range diags {
+		if got, want := diag.Extra, errorCode("E"+diag.Summary); got != want {
+			t.Errorf("wrong extra for %q: %#v; want %#v", diag.Summary, got, want)
+		}
+	}
+
+	_, _, diags = PartialDecodeWithOptions(file.Body, spec, nil, opts)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Extra, errorCode("EIncorrect attribute value type"); got != want {
+		t.Errorf("wrong extra %#v; want %#v", got, want)
+	}
+}
+
 func TestSourceRange(t *testing.T) {
 	tests := []struct {
 		config string
@@ -0,0 +1,288 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DecodeWithRanges is like Decode, but additionally returns the source
+// ranges of the values within the result that were set in the given body,
+// keyed by the result of PathString for the path of each value.
+//
+// The range recorded for an attribute is that of its value expression, and
+// the range recorded for a block is that of its header, as returned by
+// DefRange. The values of block labels are recorded with the ranges of the
+// labels. Values that were not set in the body, such as the nulls produced
+// for absent attributes or the values produced by the Default spec of a
+// DefaultSpec, have no ranges.
+//
+// The paths are those of the common container specs, as described for
+// DecodeWithProvenance. Because the transform specs can change the shape of
+// a value, a range within one of them is recorded only if it is the range
+// of the transform's entire input, in which case it is recorded for the
+// path of the transform's result.
+//
+// To find the ranges, some of the nested specs are decoded a second time,
+// and so any expressions they contain may be evaluated twice. If decoding
+// produces any errors then the returned map is empty.
+func DecodeWithRanges(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, map[string]hcl.Range, hcl.Diagnostics) {
+	val, diags := Decode(body, spec, ctx)
+	ranges := map[string]hcl.Range{}
+	if diags.HasErrors() {
+		return val, ranges, diags
+	}
+
+	w := &rangesWalker{
+		ctx:    ctx,
+		ranges: ranges,
+	}
+	w.walkBody(body, nil, spec, nil)
+	return val, ranges, diags
+}
+
+// PathString returns a canonical string representation of the given path,
+// as used for the keys of the map returned by DecodeWithRanges.
+//
+// Each attribute step is written as a period followed by the attribute
+// name, except for an attribute step at the start of the path, which is
+// written as just the name. Each index step is written in brackets, with a
+// string key quoted as for Go string literals and a number key written in
+// decimal. For example, the path to the "name" attribute of the second
+// element of the "items" attribute of an object is items[1].name, and the
+// empty path is the empty string.
+func PathString(path cty.Path) string {
+	var buf strings.Builder
+	for i, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			if i != 0 {
+				buf.WriteByte('.')
+			}
+			buf.WriteString(step.Name)
+		case cty.IndexStep:
+			key, _ := step.Key.Unmark()
+			switch {
+			case !key.IsKnown() || key.IsNull():
+				buf.WriteString("[?]")
+			case key.Type() == cty.String:
+				fmt.Fprintf(&buf, "[%q]", key.AsString())
+			case key.Type() == cty.Number:
+				fmt.Fprintf(&buf, "[%s]", key.AsBigFloat().Text('f', -1))
+			default:
+				// Set elements are indexed by their own values, which have
+				// no concise representation.
+				fmt.Fprintf(&buf, "[%#v]", key)
+			}
+		}
+	}
+	return buf.String()
+}
+
+type rangesWalker struct {
+	ctx    *hcl.EvalContext
+	ranges map[string]hcl.Range
+}
+
+func (w *rangesWalker) record(path cty.Path, rng hcl.Range) {
+	w.ranges[PathString(path)] = rng
+}
+
+// walkBody records the ranges of the values within the value that the given
+// spec would produce for the given body, where path is the path of that
+// value.
+func (w *rangesWalker) walkBody(body hcl.Body, blockLabels []blockLabel, spec Spec, path cty.Path) {
+	switch s := spec.(type) {
+	case *BodyAttrsSpec:
+		attrs, _ := body.JustAttributes()
+		asObject := false
+		if s.ElementType == cty.DynamicPseudoType {
+			val, _ := s.decodeBody(body, blockLabels, w.ctx)
+			asObject = val.Type().IsObjectType()
+		}
+		for name, attr := range attrs {
+			if asObject {
+				w.record(path.GetAttr(name), attr.Expr.Range())
+			} else {
+				w.record(path.Index(cty.StringVal(name)), attr.Expr.Range())
+			}
+		}
+	case *VariantSpec:
+		variant, variantBody, known, _ := s.selectVariant(body, w.ctx)
+		if known && variant != nil {
+			w.walkBody(variantBody, blockLabels, variant, path)
+		}
+	default:
+		content, _, _ := body.PartialContent(ImpliedSchema(spec))
+		w.walk(content, blockLabels, spec, path)
+	}
+}
+
+// walk records the ranges of the values within the value that the given
+// spec would produce for the given content, where path is the path of that
+// value.
+func (w *rangesWalker) walk(content *hcl.BodyContent, blockLabels []blockLabel, spec Spec, path cty.Path) {
+	switch s := spec.(type) {
+	case ObjectSpec:
+		for k, child := range s {
+			w.walk(content, blockLabels, child, path.GetAttr(k))
+		}
+	case TupleSpec:
+		for i, child := range s {
+			w.walk(content, blockLabels, child, path.IndexInt(i))
+		}
+	case *AttrSpec:
+		if attr, exists := content.Attributes[s.Name]; exists {
+			w.record(path, attr.Expr.Range())
+		}
+	case *TupleAttrSpec:
+		if attr, exists := content.Attributes[s.Name]; exists {
+			w.record(path, attr.Expr.Range())
+		}
+	case *OneOfSpec:
+		// The result has an attribute for each alternative, and only those
+		// that are set have ranges.
+		for _, attrS := range s.Attrs {
+			w.walk(content, blockLabels, attrS, path.GetAttr(attrS.Name))
+		}
+	case *BlockLabelSpec:
+		if s.Index < len(blockLabels) {
+			w.record(path, blockLabels[s.Index].Range)
+		}
+	case *BlockLabelPatternSpec:
+		if s.Index < len(blockLabels) {
+			w.record(path, blockLabels[s.Index].Range)
+		}
+	case *DefaultSpec:
+		primaryVal, _ := s.Primary.decode(content, blockLabels, w.ctx)
+		if primaryVal.IsNull() {
+			return
+		}
+		w.walk(content, blockLabels, s.Primary, path)
+
+	case *AttrOrBlockSpec:
+		if attr, exists := content.Attributes[s.Name]; exists {
+			w.record(path, attr.Expr.Range())
+			return
+		}
+		for _, block := range content.Blocks {
+			if block.Type == s.Name {
+				w.walkBlock(block, s.Nested, path)
+				break
+			}
+		}
+	case *BlockSpec:
+		for _, block := range content.Blocks {
+			if block.Type == s.TypeName {
+				w.walkBlock(block, s.Nested, path)
+				break
+			}
+		}
+	case *BlockAttrsSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName {
+				continue
+			}
+			w.record(path, block.DefRange)
+			attrs, _ := block.Body.JustAttributes()
+			for name, attr := range attrs {
+				w.record(path.Index(cty.StringVal(name)), attr.Expr.Range())
+			}
+			break
+		}
+	case *BlockListSpec:
+		w.walkBlockSequence(content, s.TypeName, s.Nested, path)
+	case *BlockTupleSpec:
+		w.walkBlockSequence(content, s.TypeName, s.Nested, path)
+	case *BlockSetSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName {
+				continue
+			}
+			elem, _, _ := decode(block.Body, labelsForBlock(block), w.ctx, s.Nested, false)
+			w.walkBlock(block, s.Nested, path.Index(elem))
+		}
+	case *BlockMapSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName || len(block.Labels) != len(s.LabelNames) {
+				continue
+			}
+			blockPath := path
+			for _, label := range block.Labels {
+				blockPath = blockPath.Index(cty.StringVal(label))
+			}
+			w.walkBlock(block, s.Nested, blockPath)
+		}
+	case *BlockObjectSpec:
+		for _, block := range content.Blocks {
+			if block.Type != s.TypeName || len(block.Labels) != len(s.LabelNames) {
+				continue
+			}
+			blockPath := path
+			for _, label := range block.Labels {
+				blockPath = blockPath.GetAttr(label)
+			}
+			w.walkBlock(block, s.Nested, blockPath)
+		}
+	case *OrderedBlocksSpec:
+		i := 0
+		for _, block := range content.Blocks {
+			nested, ok := s.Nested[block.Type]
+			if !ok {
+				continue
+			}
+			w.record(path.IndexInt(i), block.DefRange)
+			w.walkBlock(block, nested, path.IndexInt(i).GetAttr("value"))
+			i++
+		}
+
+	case *TransformExprSpec:
+		w.walkTransformed(content, blockLabels, s.Wrapped, path)
+	case *TransformFuncSpec:
+		w.walkTransformed(content, blockLabels, s.Wrapped, path)
+	case *TransformCallbackSpec:
+		w.walkTransformed(content, blockLabels, s.Wrapped, path)
+
+	default:
+		// The remaining specs either wrap a single spec without changing
+		// its value or don't contain any other specs at all.
+		spec.visitSameBodyChildren(func(child Spec) {
+			w.walk(content, blockLabels, child, path)
+		})
+	}
+}
+
+func (w *rangesWalker) walkBlock(block *hcl.Block, nested Spec, path cty.Path) {
+	w.record(path, block.DefRange)
+	w.walkBody(block.Body, labelsForBlock(block), nested, path)
+}
+
+func (w *rangesWalker) walkBlockSequence(content *hcl.BodyContent, typeName string, nested Spec, path cty.Path) {
+	i := 0
+	for _, block := range content.Blocks {
+		if block.Type != typeName {
+			continue
+		}
+		w.walkBlock(block, nested, path.IndexInt(i))
+		i++
+	}
+}
+
+// walkTransformed records the range of the given wrapped spec's entire
+// value for the given path, if it has one, since the paths within a
+// transformed value are unrelated to the paths within its input.
+func (w *rangesWalker) walkTransformed(content *hcl.BodyContent, blockLabels []blockLabel, wrapped Spec, path cty.Path) {
+	inner := &rangesWalker{
+		ctx:    w.ctx,
+		ranges: map[string]hcl.Range{},
+	}
+	inner.walk(content, blockLabels, wrapped, nil)
+	if rng, ok := inner.ranges[""]; ok {
+		w.record(path, rng)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeWithRanges(t *testing.T) {
+	spec := ObjectSpec{
+		"name":  &AttrSpec{Name: "name", Type: cty.String},
+		"unset": &AttrSpec{Name: "unset", Type: cty.String},
+		"port": &DefaultSpec{
+			Primary: &AttrSpec{Name: "port", Type: cty.Number},
+			Default: &LiteralSpec{Value: cty.NumberIntVal(80)},
+		},
+		"transformed": &TransformCallbackSpec{
+			Wrapped: &AttrSpec{Name: "transformed", Type: cty.String},
+			Func: func(v cty.Value) (cty.Value, error) {
+				return cty.ListVal([]cty.Value{v}), nil
+			},
+			Type: cty.List(cty.String),
+		},
+		"items": &BlockListSpec{
+			TypeName: "item",
+			Nested: ObjectSpec{
+				"kind": &BlockLabelSpec{Index: 0, Name: "kind"},
+				"size": &AttrSpec{Name: "size", Type: cty.Number},
+			},
+		},
+		"endpoint": &OneOfSpec{
+			Attrs: []*AttrSpec{
+				{Name: "url", Type: cty.String},
+				{Name: "path", Type: cty.String},
+			},
+		},
+		"named": &BlockMapSpec{
+			TypeName:   "named",
+			LabelNames: []string{"name"},
+			Nested: ObjectSpec{
+				"tags": &BlockAttrsSpec{TypeName: "tags", ElementType: cty.String},
+			},
+		},
+	}
+
+	src := `name        = "web"
+transformed = "a"
+
+item "big" {
+  size = 2
+}
+
+named "a" {
+  tags {
+    env = "prod"
+  }
+}
+
+path = "b"
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	gotVal, ranges, diags := DecodeWithRanges(f.Body, spec, nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected decode diagnostics: %s", diags.Error())
+	}
+	wantVal, _ := Decode(f.Body, spec, nil)
+	if !gotVal.RawEquals(wantVal) {
+		t.Errorf("wrong value\ngot:  %#v\nwant: %#v", gotVal, wantVal)
+	}
+
+	rng := func(line, startCol, startByte, endCol, endByte int) hcl.Range {
+		return hcl.Range{
+			Start: hcl.Pos{Line: line, Column: startCol, Byte: startByte},
+			End:   hcl.Pos{Line: line, Column: endCol, Byte: endByte},
+		}
+	}
+	want := map[string]hcl.Range{
+		`name`:                   rng(1, 15, 14, 20, 19),
+		`transformed`:            rng(2, 15, 34, 18, 37),
+		`items[0]`:               rng(4, 1, 39, 11, 49),
+		`items[0].kind`:          rng(4, 6, 44, 11, 49),
+		`items[0].size`:          rng(5, 10, 61, 11, 62),
+		`named["a"]`:             rng(8, 1, 66, 10, 75),
+		`named["a"].tags`:        rng(9, 3, 80, 7, 84),
+		`named["a"].tags["env"]`: rng(10, 11, 97, 17, 103),
+		`endpoint.path`:          rng(14, 8, 118, 11, 121),
+	}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("wrong ranges\ngot:  %#v\nwant: %#v", ranges, want)
+	}
+}
+
+func TestPathString(t *testing.T) {
+	tests := []struct {
+		path cty.Path
+		want string
+	}{
+		{nil, ``},
+		{cty.GetAttrPath("foo"), `foo`},
+		{cty.GetAttrPath("foo").GetAttr("bar"), `foo.bar`},
+		{cty.GetAttrPath("foo").IndexInt(2).GetAttr("bar"), `foo[2].bar`},
+		{cty.IndexStringPath("a b"), `["a b"]`},
+		{cty.Path{cty.IndexStep{Key: cty.UnknownVal(cty.String)}}, `[?]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := PathString(test.path); got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
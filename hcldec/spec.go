@@ -6,7 +6,13 @@ package hcldec
 import (
 	"bytes"
 	"fmt"
+	"math/big"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/customdecode"
@@ -67,6 +73,21 @@ type specNeedingVariables interface {
 	variablesNeeded(content *hcl.BodyContent) []hcl.Traversal
 }
 
+// bodySpec is implemented by specs that must decode the body directly, rather
+// than from the content selected by a schema.
+type bodySpec interface {
+	decodeBody(body hcl.Body, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
+	variablesNeededBody(body hcl.Body) []hcl.Traversal
+}
+
+// wrapperSpec is implemented by specs that decode their body only through
+// the specs they wrap, and so can wrap a bodySpec. withSameBodyChildren
+// returns a copy of the spec with each of its same-body children replaced
+// by the result of the given function.
+type wrapperSpec interface {
+	withSameBodyChildren(fn func(Spec) Spec) Spec
+}
+
 // UnknownBody can be optionally implemented by an hcl.Body instance which may
 // be entirely unknown.
 type UnknownBody interface {
@@ -156,6 +177,17 @@ func (s TupleSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabe
 // An AttrSpec is a Spec that evaluates a particular attribute expression in
 // the body and returns its resulting value converted to the requested type,
 // or produces a diagnostic if the type is incorrect.
+//
+// If Type is a type with a custom expression decoder, as defined by the
+// customdecode extension, then the decoder is responsible for producing the
+// value. In particular, customdecode.ExpressionType captures the attribute
+// expression without evaluating it, which allows deferring the evaluation of
+// one attribute while the rest of the spec tree produces values as normal.
+// The caller retrieves the expression from the decoded result using
+// customdecode.ExpressionFromVal, after checking that the value is not null,
+// since an absent optional attribute produces a null value as usual.
+// customdecode.ExpressionClosureType additionally captures the given
+// EvalContext, for evaluating the expression later in the same scope.
 type AttrSpec struct {
 	Name     string
 	Type     cty.Type
@@ -241,6 +273,421 @@ func (s *AttrSpec) impliedType() cty.Type {
 	return s.Type
 }
 
+// A TupleAttrSpec is a Spec that evaluates a particular attribute expression
+// in the body and returns its value as a tuple with a fixed number of
+// elements, each converted to the type at the corresponding position of
+// ElementTypes. For example, ElementTypes of cty.String and cty.Number accept
+// an attribute like bind = ["0.0.0.0", 8080].
+//
+// If the attribute expression is a tuple constructor then each of its
+// elements is evaluated and converted separately, so that a diagnostic about
+// an element of an unsuitable type refers to that element. Otherwise the
+// value of the whole expression is converted, as for AttrSpec with a tuple
+// type.
+type TupleAttrSpec struct {
+	Name         string
+	ElementTypes []cty.Type
+	Required     bool
+}
+
+func (s *TupleAttrSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node
+}
+
+// specNeedingVariables implementation
+func (s *TupleAttrSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
+	attr, exists := content.Attributes[s.Name]
+	if !exists {
+		return nil
+	}
+
+	return attr.Expr.Variables()
+}
+
+// attrSpec implementation
+func (s *TupleAttrSpec) attrSchemata() []hcl.AttributeSchema {
+	return []hcl.AttributeSchema{
+		{
+			Name:     s.Name,
+			Required: s.Required,
+		},
+	}
+}
+
+func (s *TupleAttrSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	attr, exists := content.Attributes[s.Name]
+	if !exists {
+		return content.MissingItemRange
+	}
+
+	return attr.Expr.Range()
+}
+
+func (s *TupleAttrSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	ty := s.impliedType().WithoutOptionalAttributesDeep()
+	attr, exists := content.Attributes[s.Name]
+	if !exists {
+		// We don't need to check required and emit a diagnostic here, because
+		// that would already have happened when building "content".
+		return cty.NullVal(ty), nil
+	}
+
+	exprs, listDiags := hcl.ExprList(attr.Expr)
+	if listDiags.HasErrors() {
+		// The expression isn't a tuple constructor, so we can only convert
+		// its value as a whole.
+		val, diags := attr.Expr.Value(ctx)
+		convVal, err := convert.Convert(val, s.impliedType())
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect attribute value type",
+				Detail: fmt.Sprintf(
+					"Inappropriate value for attribute %q: %s.",
+					s.Name, err.Error(),
+				),
+				Subject:     attr.Expr.Range().Ptr(),
+				Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+				Expression:  attr.Expr,
+				EvalContext: ctx,
+			})
+			return cty.UnknownVal(ty), diags
+		}
+		return convVal, diags
+	}
+
+	if len(exprs) != len(s.ElementTypes) {
+		return cty.UnknownVal(ty), hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect attribute value type",
+				Detail: fmt.Sprintf(
+					"Inappropriate value for attribute %q: a tuple of %d elements is required, but this has %d.",
+					s.Name, len(s.ElementTypes), len(exprs),
+				),
+				Subject:     attr.Expr.Range().Ptr(),
+				Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+				Expression:  attr.Expr,
+				EvalContext: ctx,
+			},
+		}
+	}
+
+	var diags hcl.Diagnostics
+	vals := make([]cty.Value, len(exprs))
+	for i, expr := range exprs {
+		val, valDiags := expr.Value(ctx)
+		diags = append(diags, valDiags...)
+
+		convVal, err := convert.Convert(val, s.ElementTypes[i])
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect attribute value type",
+				Detail: fmt.Sprintf(
+					"Inappropriate value for element %d of attribute %q: %s.",
+					i, s.Name, err.Error(),
+				),
+				Subject:     expr.Range().Ptr(),
+				Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+				Expression:  expr,
+				EvalContext: ctx,
+			})
+			convVal = cty.UnknownVal(s.ElementTypes[i].WithoutOptionalAttributesDeep())
+		}
+		vals[i] = convVal
+	}
+	if len(vals) == 0 {
+		return cty.EmptyTupleVal, diags
+	}
+	return cty.TupleVal(vals), diags
+}
+
+func (s *TupleAttrSpec) impliedType() cty.Type {
+	return cty.Tuple(s.ElementTypes)
+}
+
+// OneOfMode is the constraint that a OneOfSpec places on how many of its
+// attributes may be set.
+type OneOfMode int
+
+const (
+	// OneOfExactlyOne requires that exactly one of the attributes be set.
+	OneOfExactlyOne OneOfMode = iota
+
+	// OneOfAtMostOne permits at most one of the attributes to be set, so
+	// that they may all be omitted.
+	OneOfAtMostOne
+
+	// OneOfAtLeastOne requires that at least one of the attributes be set,
+	// but permits more than one.
+	OneOfAtLeastOne
+)
+
+// A OneOfSpec is a Spec that decodes several alternative attributes from the
+// body and requires that the number of them that are set meets the
+// constraint given by Mode, which is OneOfExactlyOne by default.
+//
+// The result is an object with an attribute for each of the nested specs,
+// named after the attribute it decodes, whose value is the result of that
+// spec. The attributes that are not set are null, so that the caller can
+// determine which of the alternatives was chosen.
+//
+// The Required field of each of the nested specs is ignored, since whether
+// each attribute is required is decided by the constraint instead.
+type OneOfSpec struct {
+	Attrs []*AttrSpec
+	Mode  OneOfMode
+}
+
+func (s *OneOfSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node: our nested specs are not visited, so that their Required
+	// fields are not included in the implied schema.
+}
+
+// specNeedingVariables implementation
+func (s *OneOfSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
+	var ret []hcl.Traversal
+	for _, attrS := range s.Attrs {
+		ret = append(ret, attrS.variablesNeeded(content)...)
+	}
+	return ret
+}
+
+// attrSpec implementation
+func (s *OneOfSpec) attrSchemata() []hcl.AttributeSchema {
+	ret := make([]hcl.AttributeSchema, len(s.Attrs))
+	for i, attrS := range s.Attrs {
+		ret[i] = hcl.AttributeSchema{
+			Name: attrS.Name,
+		}
+	}
+	return ret
+}
+
+func (s *OneOfSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	for _, attrS := range s.Attrs {
+		if _, exists := content.Attributes[attrS.Name]; exists {
+			return attrS.sourceRange(content, blockLabels)
+		}
+	}
+	return content.MissingItemRange
+}
+
+func (s *OneOfSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	var first *hcl.Attribute
+	for _, attrS := range s.Attrs {
+		attr, exists := content.Attributes[attrS.Name]
+		if !exists {
+			continue
+		}
+		if first == nil {
+			first = attr
+			continue
+		}
+		if s.Mode != OneOfAtLeastOne {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Conflicting arguments",
+				Detail: fmt.Sprintf(
+					"Only one of %s may be set, but %q was already set at %s.",
+					s.namesForHumans("or"), first.Name, first.NameRange,
+				),
+				Subject: attr.NameRange.Ptr(),
+				Context: attr.Range.Ptr(),
+			})
+		}
+	}
+	if first == nil && s.Mode != OneOfAtMostOne {
+		detail := fmt.Sprintf("Exactly one of %s must be set.", s.namesForHumans("or"))
+		if s.Mode == OneOfAtLeastOne {
+			detail = fmt.Sprintf("At least one of %s must be set.", s.namesForHumans("or"))
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail:   detail,
+			Subject:  content.MissingItemRange.Ptr(),
+		})
+	}
+	if diags.HasErrors() {
+		return cty.UnknownVal(s.impliedType()), diags
+	}
+
+	vals := make(map[string]cty.Value, len(s.Attrs))
+	for _, attrS := range s.Attrs {
+		val, valDiags := attrS.decode(content, blockLabels, ctx)
+		diags = append(diags, valDiags...)
+		vals[attrS.Name] = val
+	}
+	return cty.ObjectVal(vals), diags
+}
+
+func (s *OneOfSpec) impliedType() cty.Type {
+	attrTypes := make(map[string]cty.Type, len(s.Attrs))
+	for _, attrS := range s.Attrs {
+		attrTypes[attrS.Name] = attrS.impliedType().WithoutOptionalAttributesDeep()
+	}
+	return cty.Object(attrTypes)
+}
+
+// namesForHumans returns the names of the attributes as a quoted list,
+// joining the last two with the given conjunction.
+func (s *OneOfSpec) namesForHumans(conj string) string {
+	var buf bytes.Buffer
+	for i, attrS := range s.Attrs {
+		switch {
+		case i == 0:
+		case i == len(s.Attrs)-1 && len(s.Attrs) == 2:
+			fmt.Fprintf(&buf, " %s ", conj)
+		case i == len(s.Attrs)-1:
+			fmt.Fprintf(&buf, ", %s ", conj)
+		default:
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%q", attrS.Name)
+	}
+	return buf.String()
+}
+
+// RequiredIfSpec is a spec that wraps another spec and requires the
+// attribute named Name to be set whenever the attribute named When is set
+// to the value Equals. For example, a Name of "cert_file", a When of "mode"
+// and an Equals of cty.StringVal("tls") requires cert_file to be set in any
+// body that sets mode = "tls".
+//
+// Both attributes must be among those decoded by the wrapped spec, which is
+// typically an ObjectSpec, and its result is returned verbatim. The
+// condition is checked against the value that the wrapped spec decoded for
+// the When attribute, and so takes into account any default, conversion or
+// validation that the wrapped spec applies to it. To allow this, the
+// AttrSpec for the When attribute must be reachable from the wrapped spec
+// only through ObjectSpec, TupleSpec, DefaultSpec and the validation specs
+// that return their wrapped value unchanged; otherwise the condition never
+// holds.
+//
+// Equals must be a known, non-null value of a primitive type, and the
+// decoded value of the When attribute is converted to that type for the
+// comparison. If that value is unknown, or cannot be converted, then the
+// condition is not considered to hold.
+type RequiredIfSpec struct {
+	Wrapped Spec
+	Name    string
+	When    string
+	Equals  cty.Value
+}
+
+func (s *RequiredIfSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+func (s *RequiredIfSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		// We won't try to check our condition in this case, because it'll
+		// probably generate confusing additional errors that will distract
+		// from the root cause.
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	if _, exists := content.Attributes[s.Name]; exists {
+		return wrappedVal, diags
+	}
+	path, found := decodedAttrPath(s.Wrapped, s.When)
+	if !found {
+		return wrappedVal, diags
+	}
+	whenVal, err := path.Apply(wrappedVal)
+	if err != nil {
+		return wrappedVal, diags
+	}
+	whenVal, _ = whenVal.UnmarkDeep()
+	whenVal, err = convert.Convert(whenVal, s.Equals.Type())
+	if err != nil || whenVal.IsNull() || !whenVal.IsKnown() || !whenVal.Equals(s.Equals).True() {
+		return wrappedVal, diags
+	}
+
+	detail := fmt.Sprintf(
+		"The argument %q is required when %q is %s",
+		s.Name, s.When, primitiveValueForHumans(s.Equals),
+	)
+	if whenAttr, exists := content.Attributes[s.When]; exists {
+		detail += fmt.Sprintf(", as set at %s", whenAttr.NameRange)
+	}
+	diags = append(diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Missing required argument",
+		Detail:   detail + ".",
+		Subject:  content.MissingItemRange.Ptr(),
+	})
+	return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+}
+
+// decodedAttrPath returns the path within the value decoded by the given
+// spec to the value of the attribute with the given name, if that value is
+// included in the result without being transformed.
+func decodedAttrPath(spec Spec, name string) (cty.Path, bool) {
+	switch s := spec.(type) {
+	case *AttrSpec:
+		return nil, s.Name == name
+	case ObjectSpec:
+		for k, child := range s {
+			if path, found := decodedAttrPath(child, name); found {
+				return append(cty.GetAttrPath(k), path...), true
+			}
+		}
+	case TupleSpec:
+		for i, child := range s {
+			if path, found := decodedAttrPath(child, name); found {
+				return append(cty.IndexIntPath(i), path...), true
+			}
+		}
+	case *DefaultSpec:
+		return decodedAttrPath(s.Primary, name)
+	case *RequiredIfSpec:
+		return decodedAttrPath(s.Wrapped, name)
+	case *ValidateSpec:
+		return decodedAttrPath(s.Wrapped, name)
+	case *EnumSpec:
+		return decodedAttrPath(s.Wrapped, name)
+	case *LengthSpec:
+		return decodedAttrPath(s.Wrapped, name)
+	case *NumberRangeSpec:
+		return decodedAttrPath(s.Wrapped, name)
+	case *RefineValueSpec:
+		return decodedAttrPath(s.Wrapped, name)
+	}
+	return nil, false
+}
+
+func (s *RequiredIfSpec) impliedType() cty.Type {
+	return s.Wrapped.impliedType()
+}
+
+func (s *RequiredIfSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+// primitiveValueForHumans returns the given known, non-null primitive value
+// as it would be written in configuration.
+func primitiveValueForHumans(val cty.Value) string {
+	switch val.Type() {
+	case cty.String:
+		return fmt.Sprintf("%q", val.AsString())
+	case cty.Number:
+		return val.AsBigFloat().Text('f', -1)
+	case cty.Bool:
+		if val.True() {
+			return "true"
+		}
+		return "false"
+	default:
+		return fmt.Sprintf("%#v", val)
+	}
+}
+
 // A LiteralSpec is a Spec that produces the given literal value, ignoring
 // the given body.
 type LiteralSpec struct {
@@ -414,99 +861,312 @@ func (s *BlockSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLab
 	return sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested)
 }
 
-// A BlockListSpec is a Spec that produces a cty list of the results of
-// decoding all of the nested blocks of a given type, using a nested spec.
-type BlockListSpec struct {
-	TypeName string
+// An AttrOrBlockSpec is a Spec that produces a cty.Value from either an
+// attribute or a single nested block of the given name, whichever is
+// present, which is useful when migrating a setting from one form to the
+// other.
+//
+// The nested spec describes the block form, and its implied type is also
+// the type of the result. The value of the attribute form is converted to
+// that same type, with any attributes omitted from an object value taken
+// as null, so that the result has the same shape regardless of which form
+// was used. Labels are not supported in the block form.
+//
+// If DeprecatedAttr or DeprecatedBlock is set then a warning is produced
+// when the corresponding form is used. It is an error to use both forms
+// in the same body. Syntaxes that cannot distinguish attributes from
+// blocks, such as JSON, always present the setting as an attribute.
+type AttrOrBlockSpec struct {
+	Name     string
 	Nested   Spec
-	MinItems int
-	MaxItems int
+	Required bool
+
+	DeprecatedAttr  bool
+	DeprecatedBlock bool
 }
 
-func (s *BlockListSpec) visitSameBodyChildren(cb visitFunc) {
+func (s *AttrOrBlockSpec) visitSameBodyChildren(cb visitFunc) {
 	// leaf node ("Nested" does not use the same body)
 }
 
+// attrSpec implementation
+func (s *AttrOrBlockSpec) attrSchemata() []hcl.AttributeSchema {
+	return []hcl.AttributeSchema{
+		{
+			Name: s.Name,
+		},
+	}
+}
+
 // blockSpec implementation
-func (s *BlockListSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
+func (s *AttrOrBlockSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 	return []hcl.BlockHeaderSchema{
 		{
-			Type:       s.TypeName,
-			LabelNames: findLabelSpecs(s.Nested),
+			Type: s.Name,
 		},
 	}
 }
 
 // blockSpec implementation
-func (s *BlockListSpec) nestedSpec() Spec {
+func (s *AttrOrBlockSpec) nestedSpec() Spec {
 	return s.Nested
 }
 
-// specNeedingVariables implementation
-func (s *BlockListSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
-	var ret []hcl.Traversal
-
-	for _, childBlock := range content.Blocks {
-		if childBlock.Type != s.TypeName {
-			continue
+// find returns the attribute and the first block of the receiver's name
+// present in the given content, either of which may be nil.
+func (s *AttrOrBlockSpec) find(content *hcl.BodyContent) (*hcl.Attribute, *hcl.Block) {
+	attr := content.Attributes[s.Name]
+	var block *hcl.Block
+	for _, candidate := range content.Blocks {
+		if candidate.Type == s.Name {
+			block = candidate
+			break
 		}
-
-		ret = append(ret, Variables(childBlock.Body, s.Nested)...)
 	}
+	return attr, block
+}
 
-	return ret
+// specNeedingVariables implementation
+func (s *AttrOrBlockSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
+	attr, block := s.find(content)
+	switch {
+	case attr != nil:
+		return attr.Expr.Variables()
+	case block != nil:
+		return Variables(block.Body, s.Nested)
+	default:
+		return nil
+	}
 }
 
-func (s *BlockListSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (s *AttrOrBlockSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	if s.Nested == nil {
-		panic("BlockListSpec with no Nested Spec")
-	}
-
-	var elems []cty.Value
-	var sourceRanges []hcl.Range
-	for _, childBlock := range content.Blocks {
-		if childBlock.Type != s.TypeName {
-			continue
-		}
-
-		val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
-		diags = append(diags, childDiags...)
-
-		if u, ok := childBlock.Body.(UnknownBody); ok {
-			if u.Unknown() {
-				// If any block Body is unknown, then the entire block value
-				// must be unknown
-				return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
-			}
-		}
-
-		elems = append(elems, val)
-		sourceRanges = append(sourceRanges, sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested))
+		panic("AttrOrBlockSpec with no Nested Spec")
 	}
+	ty := s.Nested.impliedType()
 
-	if len(elems) < s.MinItems {
-		diags = append(diags, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  fmt.Sprintf("Insufficient %s blocks", s.TypeName),
-			Detail:   fmt.Sprintf("At least %d %q blocks are required.", s.MinItems, s.TypeName),
-			Subject:  &content.MissingItemRange,
-		})
-	} else if s.MaxItems > 0 && len(elems) > s.MaxItems {
+	attr, block := s.find(content)
+	if attr != nil && block != nil {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
-			Summary:  fmt.Sprintf("Too many %s blocks", s.TypeName),
-			Detail:   fmt.Sprintf("No more than %d %q blocks are allowed", s.MaxItems, s.TypeName),
-			Subject:  &sourceRanges[s.MaxItems],
+			Summary:  fmt.Sprintf("Duplicate %s definition", s.Name),
+			Detail: fmt.Sprintf(
+				"The %q setting may be defined either as an argument or as a block, but not both. The argument was defined at %s.",
+				s.Name, attr.NameRange.String(),
+			),
+			Subject: &block.DefRange,
 		})
+		return cty.UnknownVal(ty.WithoutOptionalAttributesDeep()), diags
 	}
 
-	if len(elems) == 0 {
-		return cty.ListValEmpty(s.Nested.impliedType()), diags
-	}
-
-	// Since our target is a list, all of the decoded elements must have the
+	switch {
+	case attr != nil:
+		if s.DeprecatedAttr {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Deprecated %s argument", s.Name),
+				Detail: fmt.Sprintf(
+					"Defining %q as an argument is deprecated. Use a nested block of type %q instead.",
+					s.Name, s.Name,
+				),
+				Subject: &attr.NameRange,
+			})
+		}
+
+		val, valDiags := attr.Expr.Value(ctx)
+		diags = append(diags, valDiags...)
+		convVal, err := convert.Convert(val, allAttributesOptional(ty))
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect attribute value type",
+				Detail: fmt.Sprintf(
+					"Inappropriate value for attribute %q: %s.",
+					s.Name, err.Error(),
+				),
+				Subject:     attr.Expr.Range().Ptr(),
+				Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+				Expression:  attr.Expr,
+				EvalContext: ctx,
+			})
+			return cty.UnknownVal(ty.WithoutOptionalAttributesDeep()), diags
+		}
+		return convVal, diags
+
+	case block != nil:
+		if s.DeprecatedBlock {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Deprecated %s block", s.Name),
+				Detail: fmt.Sprintf(
+					"Defining %q as a nested block is deprecated. Use an argument named %q instead.",
+					s.Name, s.Name,
+				),
+				Subject: &block.DefRange,
+			})
+		}
+
+		for _, candidate := range content.Blocks {
+			if candidate.Type != s.Name || candidate == block {
+				continue
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate %s block", s.Name),
+				Detail: fmt.Sprintf(
+					"Only one block of type %q is allowed. Previous definition was at %s.",
+					s.Name, block.DefRange.String(),
+				),
+				Subject: &candidate.DefRange,
+			})
+			break
+		}
+
+		val, _, childDiags := decode(block.Body, nil, ctx, s.Nested, false)
+		diags = append(diags, childDiags...)
+		return val, diags
+
+	default:
+		if s.Required {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Missing %s argument or block", s.Name),
+				Detail: fmt.Sprintf(
+					"The %q setting is required, either as an argument or as a nested block.", s.Name,
+				),
+				Subject: &content.MissingItemRange,
+			})
+		}
+		return cty.NullVal(ty.WithoutOptionalAttributesDeep()), diags
+	}
+}
+
+// allAttributesOptional returns the given type with all of its attributes
+// marked as optional, if it is an object type that has no optional
+// attributes already. Any other type is returned as-is.
+func allAttributesOptional(ty cty.Type) cty.Type {
+	if !ty.IsObjectType() || len(ty.OptionalAttributes()) > 0 {
+		return ty
+	}
+	atys := ty.AttributeTypes()
+	names := make([]string, 0, len(atys))
+	for name := range atys {
+		names = append(names, name)
+	}
+	return cty.ObjectWithOptionalAttrs(atys, names)
+}
+
+func (s *AttrOrBlockSpec) impliedType() cty.Type {
+	return s.Nested.impliedType()
+}
+
+func (s *AttrOrBlockSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	attr, block := s.find(content)
+	switch {
+	case attr != nil:
+		return attr.Expr.Range()
+	case block != nil:
+		return sourceRange(block.Body, nil, s.Nested)
+	default:
+		return content.MissingItemRange
+	}
+}
+
+// A BlockListSpec is a Spec that produces a cty list of the results of
+// decoding all of the nested blocks of a given type, using a nested spec.
+type BlockListSpec struct {
+	TypeName string
+	Nested   Spec
+	MinItems int
+	MaxItems int
+}
+
+func (s *BlockListSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node ("Nested" does not use the same body)
+}
+
+// blockSpec implementation
+func (s *BlockListSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
+	return []hcl.BlockHeaderSchema{
+		{
+			Type:       s.TypeName,
+			LabelNames: findLabelSpecs(s.Nested),
+		},
+	}
+}
+
+// blockSpec implementation
+func (s *BlockListSpec) nestedSpec() Spec {
+	return s.Nested
+}
+
+// specNeedingVariables implementation
+func (s *BlockListSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
+	var ret []hcl.Traversal
+
+	for _, childBlock := range content.Blocks {
+		if childBlock.Type != s.TypeName {
+			continue
+		}
+
+		ret = append(ret, Variables(childBlock.Body, s.Nested)...)
+	}
+
+	return ret
+}
+
+func (s *BlockListSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	if s.Nested == nil {
+		panic("BlockListSpec with no Nested Spec")
+	}
+
+	var elems []cty.Value
+	var sourceRanges []hcl.Range
+	for _, childBlock := range content.Blocks {
+		if childBlock.Type != s.TypeName {
+			continue
+		}
+
+		val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
+		diags = append(diags, childDiags...)
+
+		if u, ok := childBlock.Body.(UnknownBody); ok {
+			if u.Unknown() {
+				// If any block Body is unknown, then the entire block value
+				// must be unknown
+				return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+			}
+		}
+
+		elems = append(elems, val)
+		sourceRanges = append(sourceRanges, sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested))
+	}
+
+	if len(elems) < s.MinItems {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Insufficient %s blocks", s.TypeName),
+			Detail:   fmt.Sprintf("At least %d %q blocks are required.", s.MinItems, s.TypeName),
+			Subject:  &content.MissingItemRange,
+		})
+	} else if s.MaxItems > 0 && len(elems) > s.MaxItems {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Too many %s blocks", s.TypeName),
+			Detail:   fmt.Sprintf("No more than %d %q blocks are allowed", s.MaxItems, s.TypeName),
+			Subject:  &sourceRanges[s.MaxItems],
+		})
+	}
+
+	if len(elems) == 0 {
+		return cty.ListValEmpty(s.Nested.impliedType()), diags
+	}
+
+	// Since our target is a list, all of the decoded elements must have the
 	// same type or cty.ListVal will panic below. Different types can arise
 	// if there is an attribute spec of type cty.DynamicPseudoType in the
 	// nested spec; all given values must be convertable to a single type
@@ -698,6 +1358,153 @@ func (s *BlockTupleSpec) sourceRange(content *hcl.BodyContent, blockLabels []blo
 	return sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested)
 }
 
+// An OrderedBlocksSpec is a Spec that produces a cty tuple of the results of
+// decoding all of the nested blocks of several different types, preserving
+// the order in which the blocks were declared even when blocks of different
+// types are interleaved.
+//
+// Each element of the resulting tuple is an object with two attributes:
+// "type" is a string giving the block's type name, and "value" is the result
+// of decoding the block using the nested spec given for its type in Nested.
+//
+// MinItems and MaxItems constrain the total number of blocks across all of
+// the types. Because the nested spec differs by block type, blocks decoded
+// by this spec are not included in the result of ChildBlockTypes.
+type OrderedBlocksSpec struct {
+	Nested   map[string]Spec
+	MinItems int
+	MaxItems int
+}
+
+func (s *OrderedBlocksSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node ("Nested" does not use the same body)
+}
+
+// blockSpec implementation
+func (s *OrderedBlocksSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
+	ret := make([]hcl.BlockHeaderSchema, 0, len(s.Nested))
+	for _, typeName := range s.typeNames() {
+		ret = append(ret, hcl.BlockHeaderSchema{
+			Type:       typeName,
+			LabelNames: findLabelSpecs(s.Nested[typeName]),
+		})
+	}
+	return ret
+}
+
+// blockSpec implementation
+func (s *OrderedBlocksSpec) nestedSpec() Spec {
+	// There is no single nested spec, so we opt out of this interface.
+	return nil
+}
+
+// specNeedingVariables implementation
+func (s *OrderedBlocksSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
+	var ret []hcl.Traversal
+
+	for _, childBlock := range content.Blocks {
+		nested, ok := s.Nested[childBlock.Type]
+		if !ok {
+			continue
+		}
+
+		ret = append(ret, Variables(childBlock.Body, nested)...)
+	}
+
+	return ret
+}
+
+func (s *OrderedBlocksSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	var elems []cty.Value
+	var sourceRanges []hcl.Range
+	for _, childBlock := range content.Blocks {
+		nested, ok := s.Nested[childBlock.Type]
+		if !ok {
+			continue
+		}
+		if nested == nil {
+			panic(fmt.Sprintf("OrderedBlocksSpec with no Nested Spec for %q", childBlock.Type))
+		}
+
+		val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, nested, false)
+		diags = append(diags, childDiags...)
+
+		if u, ok := childBlock.Body.(UnknownBody); ok {
+			if u.Unknown() {
+				// If any block Body is unknown, then the entire block value
+				// must be unknown
+				return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+			}
+		}
+
+		elems = append(elems, cty.ObjectVal(map[string]cty.Value{
+			"type":  cty.StringVal(childBlock.Type),
+			"value": val,
+		}))
+		sourceRanges = append(sourceRanges, sourceRange(childBlock.Body, labelsForBlock(childBlock), nested))
+	}
+
+	if len(elems) < s.MinItems {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Insufficient blocks",
+			Detail:   fmt.Sprintf("At least %d blocks of types %s are required.", s.MinItems, s.typeNamesForHumans()),
+			Subject:  &content.MissingItemRange,
+		})
+	} else if s.MaxItems > 0 && len(elems) > s.MaxItems {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Too many blocks",
+			Detail:   fmt.Sprintf("No more than %d blocks of types %s are allowed", s.MaxItems, s.typeNamesForHumans()),
+			Subject:  &sourceRanges[s.MaxItems],
+		})
+	}
+
+	if len(elems) == 0 {
+		return cty.EmptyTupleVal, diags
+	}
+
+	return cty.TupleVal(elems), diags
+}
+
+func (s *OrderedBlocksSpec) impliedType() cty.Type {
+	// We can't predict our type, because we don't know how many blocks
+	// there will be, or of which types, until we decode.
+	return cty.DynamicPseudoType
+}
+
+func (s *OrderedBlocksSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	// We return the source range of the _first_ block of any of the given
+	// types, since they are not guaranteed to form a contiguous range.
+	for _, childBlock := range content.Blocks {
+		if nested, ok := s.Nested[childBlock.Type]; ok {
+			return sourceRange(childBlock.Body, labelsForBlock(childBlock), nested)
+		}
+	}
+
+	return content.MissingItemRange
+}
+
+func (s *OrderedBlocksSpec) typeNames() []string {
+	names := make([]string, 0, len(s.Nested))
+	for name := range s.Nested {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *OrderedBlocksSpec) typeNamesForHumans() string {
+	names := s.typeNames()
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // A BlockSetSpec is a Spec that produces a cty set of the results of
 // decoding all of the nested blocks of a given type, using a nested spec.
 type BlockSetSpec struct {
@@ -861,7 +1668,10 @@ func (s *BlockSetSpec) sourceRange(content *hcl.BodyContent, blockLabels []block
 // decoding all of the nested blocks of a given type, using a nested spec.
 //
 // One level of map structure is created for each of the given label names.
-// There must be at least one given label name.
+// There must be at least one given label name. For example, with the label
+// names "group" and "name" the result is a map of maps, keyed first by the
+// group label and then by the name label of each block. It is an error for
+// two blocks to have the same labels for all of the given label names.
 type BlockMapSpec struct {
 	TypeName   string
 	LabelNames []string
@@ -903,61 +1713,22 @@ func (s *BlockMapSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal
 }
 
 func (s *BlockMapSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	var diags hcl.Diagnostics
-
 	if s.Nested == nil {
 		panic("BlockMapSpec with no Nested Spec")
 	}
+	if len(s.LabelNames) == 0 {
+		panic("BlockMapSpec with no LabelNames")
+	}
 	if ImpliedType(s).HasDynamicTypes() {
 		panic("cty.DynamicPseudoType attributes may not be used inside a BlockMapSpec")
 	}
 
-	elems := map[string]interface{}{}
-	for _, childBlock := range content.Blocks {
-		if childBlock.Type != s.TypeName {
-			continue
-		}
-
-		if u, ok := childBlock.Body.(UnknownBody); ok {
-			if u.Unknown() {
-				// If any block Body is unknown, then the entire block value
-				// must be unknown
-				return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
-			}
-		}
-
-		childLabels := labelsForBlock(childBlock)
-		val, _, childDiags := decode(childBlock.Body, childLabels[len(s.LabelNames):], ctx, s.Nested, false)
-		targetMap := elems
-		for _, key := range childBlock.Labels[:len(s.LabelNames)-1] {
-			if _, exists := targetMap[key]; !exists {
-				targetMap[key] = make(map[string]interface{})
-			}
-			targetMap = targetMap[key].(map[string]interface{})
-		}
-
-		diags = append(diags, childDiags...)
-
-		key := childBlock.Labels[len(s.LabelNames)-1]
-		if _, exists := targetMap[key]; exists {
-			labelsBuf := bytes.Buffer{}
-			for _, label := range childBlock.Labels {
-				fmt.Fprintf(&labelsBuf, " %q", label)
-			}
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  fmt.Sprintf("Duplicate %s block", s.TypeName),
-				Detail: fmt.Sprintf(
-					"A block for %s%s was already defined. The %s labels must be unique.",
-					s.TypeName, labelsBuf.String(), s.TypeName,
-				),
-				Subject: &childBlock.DefRange,
-			})
-			continue
-		}
-
-		targetMap[key] = val
-	}
+	elems, known, diags := decodeLabeledBlocks(content, s.TypeName, s.LabelNames, s.Nested, ctx)
+	if !known {
+		// If any block Body is unknown, then the entire block value
+		// must be unknown
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
 
 	if len(elems) == 0 {
 		return cty.MapValEmpty(s.Nested.impliedType()), diags
@@ -1060,57 +1831,18 @@ func (s *BlockObjectSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traver
 }
 
 func (s *BlockObjectSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	var diags hcl.Diagnostics
-
 	if s.Nested == nil {
 		panic("BlockObjectSpec with no Nested Spec")
 	}
+	if len(s.LabelNames) == 0 {
+		panic("BlockObjectSpec with no LabelNames")
+	}
 
-	elems := map[string]interface{}{}
-	for _, childBlock := range content.Blocks {
-		if childBlock.Type != s.TypeName {
-			continue
-		}
-
-		if u, ok := childBlock.Body.(UnknownBody); ok {
-			if u.Unknown() {
-				// If any block Body is unknown, then the entire block value
-				// must be unknown
-				return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
-			}
-		}
-
-		childLabels := labelsForBlock(childBlock)
-		val, _, childDiags := decode(childBlock.Body, childLabels[len(s.LabelNames):], ctx, s.Nested, false)
-		targetMap := elems
-		for _, key := range childBlock.Labels[:len(s.LabelNames)-1] {
-			if _, exists := targetMap[key]; !exists {
-				targetMap[key] = make(map[string]interface{})
-			}
-			targetMap = targetMap[key].(map[string]interface{})
-		}
-
-		diags = append(diags, childDiags...)
-
-		key := childBlock.Labels[len(s.LabelNames)-1]
-		if _, exists := targetMap[key]; exists {
-			labelsBuf := bytes.Buffer{}
-			for _, label := range childBlock.Labels {
-				fmt.Fprintf(&labelsBuf, " %q", label)
-			}
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  fmt.Sprintf("Duplicate %s block", s.TypeName),
-				Detail: fmt.Sprintf(
-					"A block for %s%s was already defined. The %s labels must be unique.",
-					s.TypeName, labelsBuf.String(), s.TypeName,
-				),
-				Subject: &childBlock.DefRange,
-			})
-			continue
-		}
-
-		targetMap[key] = val
+	elems, known, diags := decodeLabeledBlocks(content, s.TypeName, s.LabelNames, s.Nested, ctx)
+	if !known {
+		// If any block Body is unknown, then the entire block value
+		// must be unknown
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
 
 	if len(elems) == 0 {
@@ -1162,6 +1894,104 @@ func (s *BlockObjectSpec) sourceRange(content *hcl.BodyContent, blockLabels []bl
 	return sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested)
 }
 
+// decodeLabeledBlocks is the shared implementation of BlockMapSpec and
+// BlockObjectSpec. It decodes each of the blocks of the given type using the
+// given nested spec and arranges the results into nested Go maps, with one
+// level for each of the given label names and the decoded cty.Value of each
+// block at the innermost level.
+//
+// A block whose labels don't match the given label names and any label
+// names of the nested spec, or whose labels for the given label names
+// duplicate those of an earlier block, is reported in the returned
+// diagnostics and otherwise ignored.
+//
+// If any of the blocks has an unknown body then the second return value is
+// false, and the caller should return an unknown value.
+func decodeLabeledBlocks(content *hcl.BodyContent, typeName string, labelNames []string, nested Spec, ctx *hcl.EvalContext) (map[string]interface{}, bool, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	nestedLabelNames := findLabelSpecs(nested)
+	allLabelNames := make([]string, 0, len(labelNames)+len(nestedLabelNames))
+	allLabelNames = append(allLabelNames, labelNames...)
+	allLabelNames = append(allLabelNames, nestedLabelNames...)
+
+	elems := map[string]interface{}{}
+	defRanges := map[string]hcl.Range{}
+	for _, childBlock := range content.Blocks {
+		if childBlock.Type != typeName {
+			continue
+		}
+
+		if u, ok := childBlock.Body.(UnknownBody); ok {
+			if u.Unknown() {
+				return nil, false, diags
+			}
+		}
+
+		// The schema we return from blockHeaderSchemata normally makes the
+		// body reject blocks with the wrong number of labels, but we check
+		// again here so that the keys below are always complete.
+		switch {
+		case len(childBlock.Labels) < len(allLabelNames):
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Missing %s for %s", allLabelNames[len(childBlock.Labels)], typeName),
+				Detail: fmt.Sprintf(
+					"All %s blocks must have %d labels (%s).",
+					typeName, len(allLabelNames), strings.Join(allLabelNames, ", "),
+				),
+				Subject: &childBlock.DefRange,
+			})
+			continue
+		case len(childBlock.Labels) > len(allLabelNames):
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Extraneous label for %s", typeName),
+				Detail: fmt.Sprintf(
+					"Only %d labels (%s) are expected for %s blocks.",
+					len(allLabelNames), strings.Join(allLabelNames, ", "), typeName,
+				),
+				Subject: &childBlock.DefRange,
+			})
+			continue
+		}
+
+		childLabels := labelsForBlock(childBlock)
+		val, _, childDiags := decode(childBlock.Body, childLabels[len(labelNames):], ctx, nested, false)
+		diags = append(diags, childDiags...)
+
+		keys := childBlock.Labels[:len(labelNames)]
+		labelsBuf := bytes.Buffer{}
+		for _, label := range keys {
+			fmt.Fprintf(&labelsBuf, " %q", label)
+		}
+		if prevRange, exists := defRanges[labelsBuf.String()]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate %s block", typeName),
+				Detail: fmt.Sprintf(
+					"A block for %s%s was already defined at %s. The %s labels must be unique.",
+					typeName, labelsBuf.String(), prevRange, typeName,
+				),
+				Subject: &childBlock.DefRange,
+			})
+			continue
+		}
+		defRanges[labelsBuf.String()] = childBlock.DefRange
+
+		targetMap := elems
+		for _, key := range keys[:len(keys)-1] {
+			if _, exists := targetMap[key]; !exists {
+				targetMap[key] = make(map[string]interface{})
+			}
+			targetMap = targetMap[key].(map[string]interface{})
+		}
+		targetMap[keys[len(keys)-1]] = val
+	}
+
+	return elems, true, diags
+}
+
 // A BlockAttrsSpec is a Spec that interprets a single block as if it were
 // a map of some element type. That is, each attribute within the block
 // becomes a key in the resulting map and the attribute's value becomes the
@@ -1332,370 +2162,1569 @@ func (s *BlockAttrsSpec) findBlock(content *hcl.BodyContent) (block *hcl.Block,
 	return block, nil
 }
 
-// A BlockLabelSpec is a Spec that returns a cty.String representing the
-// label of the block its given body belongs to, if indeed its given body
-// belongs to a block. It is a programming error to use this in a non-block
-// context, so this spec will panic in that case.
+// A BlockPresenceSpec is a Spec that produces a cty.Bool value that is true
+// if a nested block of the given type is present and false if it is absent,
+// for schemas where an optional block acts as a switch, like this:
 //
-// This spec only works in the nested spec within a BlockSpec, BlockListSpec,
-// BlockSetSpec or BlockMapSpec.
+//	enabled {}
 //
-// The full set of label specs used against a particular block must have a
-// consecutive set of indices starting at zero. The maximum index found
-// defines how many labels the corresponding blocks must have in cty source.
-type BlockLabelSpec struct {
-	Index int
-	Name  string
+// The block accepts no labels and its body must be empty. An error
+// diagnostic is produced if there is more than one block of the given type.
+type BlockPresenceSpec struct {
+	TypeName string
 }
 
-func (s *BlockLabelSpec) visitSameBodyChildren(cb visitFunc) {
+func (s *BlockPresenceSpec) visitSameBodyChildren(cb visitFunc) {
 	// leaf node
 }
 
-func (s *BlockLabelSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	if s.Index >= len(blockLabels) {
-		panic("BlockListSpec used in non-block context")
+// blockSpec implementation
+func (s *BlockPresenceSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
+	return []hcl.BlockHeaderSchema{
+		{
+			Type: s.TypeName,
+		},
 	}
-
-	return cty.StringVal(blockLabels[s.Index].Value), nil
 }
 
-func (s *BlockLabelSpec) impliedType() cty.Type {
-	return cty.String // labels are always strings
+// blockSpec implementation
+func (s *BlockPresenceSpec) nestedSpec() Spec {
+	// The body must be empty, which is what an empty ObjectSpec requires.
+	return ObjectSpec{}
 }
 
-func (s *BlockLabelSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
-	if s.Index >= len(blockLabels) {
-		panic("BlockListSpec used in non-block context")
+func (s *BlockPresenceSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	block, other := s.findBlock(content)
+	if block == nil {
+		return cty.False, diags
+	}
+	if other != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Duplicate %s block", s.TypeName),
+			Detail: fmt.Sprintf(
+				"Only one block of type %q is allowed. Previous definition was at %s.",
+				s.TypeName, block.DefRange.String(),
+			),
+			Subject: &other.DefRange,
+		})
 	}
 
-	return blockLabels[s.Index].Range
+	_, _, childDiags := decode(block.Body, nil, ctx, s.nestedSpec(), false)
+	diags = append(diags, childDiags...)
+	return cty.True, diags
 }
 
-func findLabelSpecs(spec Spec) []string {
-	maxIdx := -1
-	var names map[int]string
-
-	var visit visitFunc
-	visit = func(s Spec) {
-		if ls, ok := s.(*BlockLabelSpec); ok {
-			if maxIdx < ls.Index {
-				maxIdx = ls.Index
-			}
-			if names == nil {
-				names = make(map[int]string)
-			}
-			names[ls.Index] = ls.Name
-		}
-		s.visitSameBodyChildren(visit)
-	}
-
-	visit(spec)
+func (s *BlockPresenceSpec) impliedType() cty.Type {
+	return cty.Bool
+}
 
-	if maxIdx < 0 {
-		return nil // no labels at all
+func (s *BlockPresenceSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	block, _ := s.findBlock(content)
+	if block == nil {
+		return content.MissingItemRange
 	}
+	return block.DefRange
+}
 
-	ret := make([]string, maxIdx+1)
-	for i := range ret {
-		name := names[i]
-		if name == "" {
-			// Should never happen if the spec is conformant, since we require
-			// consecutive indices starting at zero.
-			name = fmt.Sprintf("missing%02d", i)
+func (s *BlockPresenceSpec) findBlock(content *hcl.BodyContent) (block *hcl.Block, other *hcl.Block) {
+	for _, candidate := range content.Blocks {
+		if candidate.Type != s.TypeName {
+			continue
 		}
-		ret[i] = name
+		if block != nil {
+			return block, candidate
+		}
+		block = candidate
 	}
 
-	return ret
+	return block, nil
 }
 
-// DefaultSpec is a spec that wraps two specs, evaluating the primary first
-// and then evaluating the default if the primary returns a null value.
-//
-// The two specifications must have the same implied result type for correct
-// operation. If not, the result is undefined.
+// A BodyAttrsSpec is a Spec that interprets all of the attributes in a body
+// as a map from attribute name to attribute value, in the same way as
+// BlockAttrsSpec does for the body of a nested block. Blocks are not
+// permitted in the body.
 //
-// Any requirements imposed by the "Default" spec apply even if "Primary" does
-// not return null. For example, if the "Default" spec is for a required
-// attribute then that attribute is always required, regardless of the result
-// of the "Primary" spec.
+// If ElementType is cty.DynamicPseudoType then the element type is inferred
+// from the attribute values: the result is a map if the values all have, or
+// can all be converted to, a single type, and otherwise an object.
 //
-// The "Default" spec must not describe a nested block, since otherwise the
-// result of ChildBlockTypes would not be decidable without evaluation. If
-// the default spec _does_ describe a nested block then the result is
-// undefined.
-type DefaultSpec struct {
-	Primary Spec
-	Default Spec
-}
-
-func (s *DefaultSpec) visitSameBodyChildren(cb visitFunc) {
-	cb(s.Primary)
-	cb(s.Default)
+// Because it consumes the entire body, a BodyAttrsSpec must be the only spec
+// for its body: either the spec passed to Decode or the Nested spec of a
+// block spec, optionally wrapped in specs that only transform or check the
+// result of the spec they wrap, such as ValidateSpec. It will panic if
+// combined with other specs for the same body, such as by placing it in an
+// ObjectSpec.
+type BodyAttrsSpec struct {
+	ElementType cty.Type
 }
 
-func (s *DefaultSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	val, diags := s.Primary.decode(content, blockLabels, ctx)
-	if val.IsNull() {
-		var moreDiags hcl.Diagnostics
-		val, moreDiags = s.Default.decode(content, blockLabels, ctx)
-		diags = append(diags, moreDiags...)
-	}
-	return val, diags
+func (s *BodyAttrsSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node
 }
 
-func (s *DefaultSpec) impliedType() cty.Type {
-	return s.Primary.impliedType()
+func (s *BodyAttrsSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	// We only get here if this spec was combined with others, since
+	// otherwise the decoder calls decodeBody instead.
+	panic("BodyAttrsSpec must be the only spec for its body")
 }
 
-// attrSpec implementation
-func (s *DefaultSpec) attrSchemata() []hcl.AttributeSchema {
-	// We must pass through the union of both of our nested specs so that
-	// we'll have both values available in the result.
-	var ret []hcl.AttributeSchema
-	if as, ok := s.Primary.(attrSpec); ok {
-		ret = append(ret, as.attrSchemata()...)
-	}
-	if as, ok := s.Default.(attrSpec); ok {
-		ret = append(ret, as.attrSchemata()...)
-	}
-	return ret
-}
+// bodySpec implementation
+func (s *BodyAttrsSpec) decodeBody(body hcl.Body, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	attrs, diags := body.JustAttributes()
 
-// blockSpec implementation
-func (s *DefaultSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
-	// Only the primary spec may describe a block, since otherwise
-	// our nestedSpec method below can't know which to return.
-	if bs, ok := s.Primary.(blockSpec); ok {
-		return bs.blockHeaderSchemata()
-	}
+	vals := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		if decodeFn := customdecode.CustomExpressionDecoderForType(s.ElementType); decodeFn != nil {
+			attrVal, attrDiags := decodeFn(attr.Expr, ctx)
+			diags = append(diags, attrDiags...)
+			if attrVal == cty.NilVal {
+				attrVal = cty.UnknownVal(s.ElementType)
+			}
+			vals[name] = attrVal
+			continue
+		}
+
+		attrVal, attrDiags := attr.Expr.Value(ctx)
+		diags = append(diags, attrDiags...)
+
+		attrVal, err := convert.Convert(attrVal, s.ElementType)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity:    hcl.DiagError,
+				Summary:     "Invalid attribute value",
+				Detail:      fmt.Sprintf("Invalid value for attribute %q: %s.", name, err),
+				Subject:     attr.Expr.Range().Ptr(),
+				Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+				Expression:  attr.Expr,
+				EvalContext: ctx,
+			})
+			attrVal = cty.UnknownVal(s.ElementType)
+		}
+
+		vals[name] = attrVal
+	}
+
+	if s.ElementType != cty.DynamicPseudoType {
+		if len(vals) == 0 {
+			return cty.MapValEmpty(s.ElementType), diags
+		}
+		return cty.MapVal(vals), diags
+	}
+
+	if len(vals) == 0 {
+		return cty.EmptyObjectVal, diags
+	}
+	names := make([]string, 0, len(vals))
+	tys := make([]cty.Type, 0, len(vals))
+	for name, val := range vals {
+		names = append(names, name)
+		tys = append(tys, val.Type())
+	}
+	ety, conversions := convert.UnifyUnsafe(tys)
+	if ety == cty.NilType || ety == cty.DynamicPseudoType {
+		return cty.ObjectVal(vals), diags
+	}
+	elems := make(map[string]cty.Value, len(vals))
+	for i, name := range names {
+		val := vals[name]
+		if conv := conversions[i]; conv != nil {
+			var err error
+			val, err = conv(val)
+			if err != nil {
+				return cty.ObjectVal(vals), diags
+			}
+		}
+		elems[name] = val
+	}
+	return cty.MapVal(elems), diags
+}
+
+// bodySpec implementation
+func (s *BodyAttrsSpec) variablesNeededBody(body hcl.Body) []hcl.Traversal {
+	attrs, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	var vars []hcl.Traversal
+	for _, attr := range attrs {
+		vars = append(vars, attr.Expr.Variables()...)
+	}
+
+	// We'll return the variables references in source order so that any
+	// error messages that result are also in source order.
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].SourceRange().Start.Byte < vars[j].SourceRange().Start.Byte
+	})
+
+	return vars
+}
+
+func (s *BodyAttrsSpec) impliedType() cty.Type {
+	if s.ElementType == cty.DynamicPseudoType {
+		return cty.DynamicPseudoType
+	}
+	return cty.Map(s.ElementType)
+}
+
+func (s *BodyAttrsSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return content.MissingItemRange
+}
+
+// A VariantSpec is a Spec that decodes a body using one of several
+// alternative specs, selected by the value of a discriminator attribute in
+// the body. For example, with a Discriminator of "type", a body containing
+// type = "http" is decoded using the spec in Variants with the key "http".
+//
+// If the spec for the selected variant doesn't itself decode the
+// discriminator attribute then the body is decoded without it, so that it
+// isn't reported as unexpected. If the discriminator attribute is absent
+// then the body is decoded using Default, or an error is reported if Default
+// is nil. A discriminator value that isn't a key of Variants is always an
+// error, reported at the discriminator attribute's value. If the value is
+// unknown then the result is an unknown value.
+//
+// The result has the type implied by the selected variant's spec. If the
+// specs of all of the variants and Default imply the same type then that is
+// the type implied by the VariantSpec, and otherwise it's
+// cty.DynamicPseudoType.
+//
+// Because it consumes the entire body, a VariantSpec must be the only spec
+// for its body in the same way as BodyAttrsSpec, and it will panic if
+// combined with other specs for the same body. It may be wrapped in specs
+// that only transform or check the result of the spec they wrap, such as
+// ValidateSpec, TransformExprSpec or DefaultSpec.
+type VariantSpec struct {
+	Discriminator string
+	Variants      map[string]Spec
+	Default       Spec
+}
+
+func (s *VariantSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node: the variants decode the body directly, without the content
+	// selected by a schema
+}
+
+func (s *VariantSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	// We only get here if this spec was combined with others, since
+	// otherwise the decoder calls decodeBody instead.
+	panic("VariantSpec must be the only spec for its body")
+}
+
+// bodySpec implementation
+func (s *VariantSpec) decodeBody(body hcl.Body, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	variant, variantBody, known, diags := s.selectVariant(body, ctx)
+	if !known {
+		return cty.UnknownVal(s.impliedType()), diags
+	}
+	if variant == nil {
+		// We already reported the problem while selecting the variant.
+		return cty.UnknownVal(s.impliedType()), diags
+	}
+
+	val, _, moreDiags := decode(variantBody, blockLabels, ctx, variant, false)
+	diags = append(diags, moreDiags...)
+	return val, diags
+}
+
+// selectVariant returns the spec of the variant selected by the given body,
+// and the body to decode with it. If the variant can't be determined
+// because the discriminator is unknown then known is false, and if it can't
+// be determined because of errors then the returned spec is nil.
+func (s *VariantSpec) selectVariant(body hcl.Body, ctx *hcl.EvalContext) (variant Spec, variantBody hcl.Body, known bool, diags hcl.Diagnostics) {
+	content, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: s.Discriminator, Required: s.Default == nil},
+		},
+	})
+	attr, exists := content.Attributes[s.Discriminator]
+	if !exists {
+		return s.Default, body, true, diags
+	}
+
+	val, valDiags := attr.Expr.Value(ctx)
+	diags = append(diags, valDiags...)
+	if valDiags.HasErrors() {
+		return nil, nil, true, diags
+	}
+	val, _ = val.Unmark()
+	val, err := convert.Convert(val, cty.String)
+	if err == nil && val.IsNull() {
+		err = fmt.Errorf("a string is required")
+	}
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     "Incorrect attribute value type",
+			Detail:      fmt.Sprintf("Inappropriate value for attribute %q: %s.", s.Discriminator, err.Error()),
+			Subject:     attr.Expr.Range().Ptr(),
+			Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+			Expression:  attr.Expr,
+			EvalContext: ctx,
+		})
+		return nil, nil, true, diags
+	}
+	if !val.IsKnown() {
+		return nil, nil, false, diags
+	}
+
+	name := val.AsString()
+	variant, ok := s.Variants[name]
+	if !ok {
+		names := make([]string, 0, len(s.Variants))
+		for name := range s.Variants {
+			names = append(names, strconv.Quote(name))
+		}
+		sort.Strings(names)
+		diags = append(diags, &hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     "Unsupported value",
+			Detail:      fmt.Sprintf("The value %q is not allowed for %q. The valid values are: %s.", name, s.Discriminator, strings.Join(names, ", ")),
+			Subject:     attr.Expr.Range().Ptr(),
+			Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+			Expression:  attr.Expr,
+			EvalContext: ctx,
+		})
+		return nil, nil, true, diags
+	}
+
+	variantBody = remain
+	for _, attrS := range ImpliedSchema(variant).Attributes {
+		if attrS.Name == s.Discriminator {
+			variantBody = body
+			break
+		}
+	}
+	return variant, variantBody, true, diags
+}
+
+// bodySpec implementation
+func (s *VariantSpec) variablesNeededBody(body hcl.Body) []hcl.Traversal {
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: s.Discriminator},
+		},
+	})
+	attr, exists := content.Attributes[s.Discriminator]
+
+	var vars []hcl.Traversal
+	switch {
+	case !exists:
+		if s.Default != nil {
+			vars = Variables(body, s.Default)
+		}
+	case len(attr.Expr.Variables()) == 0:
+		// The discriminator doesn't depend on any variables, so we can
+		// determine the variant now.
+		variant, variantBody, _, _ := s.selectVariant(body, nil)
+		if variant != nil {
+			vars = Variables(variantBody, variant)
+		}
+	default:
+		// We can't determine which variant will be selected without the
+		// variables, so we must return the variables for all of them.
+		vars = attr.Expr.Variables()
+		for _, variant := range s.Variants {
+			vars = append(vars, Variables(body, variant)...)
+		}
+		sort.SliceStable(vars, func(i, j int) bool {
+			ri, rj := vars[i].SourceRange(), vars[j].SourceRange()
+			if ri.Filename != rj.Filename {
+				return ri.Filename < rj.Filename
+			}
+			return ri.Start.Byte < rj.Start.Byte
+		})
+	}
+	return vars
+}
+
+func (s *VariantSpec) impliedType() cty.Type {
+	var ty cty.Type
+	check := func(spec Spec) bool {
+		specTy := spec.impliedType()
+		if ty == cty.NilType {
+			ty = specTy
+			return true
+		}
+		return ty.Equals(specTy)
+	}
+	for _, variant := range s.Variants {
+		if !check(variant) {
+			return cty.DynamicPseudoType
+		}
+	}
+	if s.Default != nil && !check(s.Default) {
+		return cty.DynamicPseudoType
+	}
+	if ty == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return ty
+}
+
+func (s *VariantSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return content.MissingItemRange
+}
+
+// A BlockLabelSpec is a Spec that returns a cty.String representing the
+// label of the block its given body belongs to, if indeed its given body
+// belongs to a block. It is a programming error to use this in a non-block
+// context, so this spec will panic in that case.
+//
+// This spec only works in the nested spec within a BlockSpec, BlockListSpec,
+// BlockSetSpec or BlockMapSpec.
+//
+// The full set of label specs used against a particular block must have a
+// consecutive set of indices starting at zero. The maximum index found
+// defines how many labels the corresponding blocks must have in cty source.
+type BlockLabelSpec struct {
+	Index int
+	Name  string
+}
+
+func (s *BlockLabelSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node
+}
+
+func (s *BlockLabelSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if s.Index >= len(blockLabels) {
+		panic("BlockListSpec used in non-block context")
+	}
+
+	return cty.StringVal(blockLabels[s.Index].Value), nil
+}
+
+func (s *BlockLabelSpec) impliedType() cty.Type {
+	return cty.String // labels are always strings
+}
+
+func (s *BlockLabelSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	if s.Index >= len(blockLabels) {
+		panic("BlockListSpec used in non-block context")
+	}
+
+	return blockLabels[s.Index].Range
+}
+
+// A BlockLabelPatternSpec is a Spec that requires the label of the block its
+// given body belongs to to match a regular expression, returning an object
+// with a string attribute for each named capture group in the expression.
+// For example, the Pattern (?P<env>[a-z]+)-(?P<tier>[a-z]+) accepts the
+// label "env-prod" and returns an object with env = "env" and tier = "prod".
+// Any group that doesn't take part in the match is null.
+//
+// Pattern uses the syntax of the regexp package and must match the whole
+// label, as if it were anchored at both ends. If the label doesn't match
+// then the error diagnostic is reported at the range of the label. It is a
+// programming error to give an invalid pattern or to use this in a
+// non-block context, so this spec will panic in those cases.
+//
+// Each BlockLabelPatternSpec counts as a label spec with the given Index and
+// Name, in the same way as a BlockLabelSpec, and the two can be used
+// together for the same label to decode both the label itself and its
+// components.
+type BlockLabelPatternSpec struct {
+	Index   int
+	Name    string
+	Pattern string
+
+	// The compiled pattern is cached on first use. These fields are
+	// unexported so that they are not included in gob encodings.
+	compileOnce sync.Once
+	compiled    *regexp.Regexp
+	compileErr  error
+}
+
+func (s *BlockLabelPatternSpec) visitSameBodyChildren(cb visitFunc) {
+	// leaf node
+}
+
+func (s *BlockLabelPatternSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if s.Index >= len(blockLabels) {
+		panic("BlockLabelPatternSpec used in non-block context")
+	}
+	label := blockLabels[s.Index]
+	re := s.regexp()
+
+	match := re.FindStringSubmatchIndex(label.Value)
+	if match == nil {
+		return cty.UnknownVal(s.impliedType()), hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid block label",
+				Detail: fmt.Sprintf(
+					"The %s %q does not match the required pattern %s.",
+					s.Name, label.Value, s.Pattern,
+				),
+				Subject: label.Range.Ptr(),
+			},
+		}
+	}
+
+	attrs := map[string]cty.Value{}
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if start, end := match[2*i], match[2*i+1]; start >= 0 {
+			attrs[name] = cty.StringVal(label.Value[start:end])
+		} else {
+			attrs[name] = cty.NullVal(cty.String)
+		}
+	}
+	return cty.ObjectVal(attrs), nil
+}
+
+func (s *BlockLabelPatternSpec) impliedType() cty.Type {
+	attrTypes := map[string]cty.Type{}
+	for _, name := range s.regexp().SubexpNames() {
+		if name != "" {
+			attrTypes[name] = cty.String
+		}
+	}
+	return cty.Object(attrTypes)
+}
+
+func (s *BlockLabelPatternSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	if s.Index >= len(blockLabels) {
+		panic("BlockLabelPatternSpec used in non-block context")
+	}
+
+	return blockLabels[s.Index].Range
+}
+
+// regexp returns the compiled form of the pattern, anchored to match the
+// whole of its input. The pattern is compiled only once for each spec.
+func (s *BlockLabelPatternSpec) regexp() *regexp.Regexp {
+	s.compileOnce.Do(func() {
+		s.compiled, s.compileErr = regexp.Compile(`^(?:` + s.Pattern + `)$`)
+	})
+	if s.compileErr != nil {
+		panic(fmt.Sprintf("invalid BlockLabelPatternSpec pattern: %s", s.compileErr))
+	}
+	return s.compiled
+}
+
+func findLabelSpecs(spec Spec) []string {
+	maxIdx := -1
+	var names map[int]string
+
+	addLabel := func(index int, name string) {
+		if maxIdx < index {
+			maxIdx = index
+		}
+		if names == nil {
+			names = make(map[int]string)
+		}
+		names[index] = name
+	}
+
+	var visit visitFunc
+	visit = func(s Spec) {
+		switch ls := s.(type) {
+		case *BlockLabelSpec:
+			addLabel(ls.Index, ls.Name)
+		case *BlockLabelPatternSpec:
+			addLabel(ls.Index, ls.Name)
+		}
+		if vs, ok := s.(*VariantSpec); ok {
+			// The variants decode the same body, so they share its labels.
+			for _, variant := range vs.Variants {
+				visit(variant)
+			}
+			if vs.Default != nil {
+				visit(vs.Default)
+			}
+		}
+		s.visitSameBodyChildren(visit)
+	}
+
+	visit(spec)
+
+	if maxIdx < 0 {
+		return nil // no labels at all
+	}
+
+	ret := make([]string, maxIdx+1)
+	for i := range ret {
+		name := names[i]
+		if name == "" {
+			// Should never happen if the spec is conformant, since we require
+			// consecutive indices starting at zero.
+			name = fmt.Sprintf("missing%02d", i)
+		}
+		ret[i] = name
+	}
+
+	return ret
+}
+
+// DefaultSpec is a spec that wraps two specs, evaluating the primary first
+// and then evaluating the default if the primary returns a null value.
+//
+// An attribute that is explicitly set to null decodes to a null value just
+// as an absent attribute does, so for a primary AttrSpec the default is used
+// in both cases.
+//
+// The two specifications must have the same implied result type for correct
+// operation. If not, the result is undefined.
+//
+// Any requirements imposed by the "Default" spec apply even if "Primary" does
+// not return null. For example, if the "Default" spec is for a required
+// attribute then that attribute is always required, regardless of the result
+// of the "Primary" spec.
+//
+// The "Default" spec must not describe a nested block, since otherwise the
+// result of ChildBlockTypes would not be decidable without evaluation. If
+// the default spec _does_ describe a nested block then the result is
+// undefined.
+type DefaultSpec struct {
+	Primary Spec
+	Default Spec
+}
+
+func (s *DefaultSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Primary)
+	cb(s.Default)
+}
+
+// wrapperSpec implementation
+func (s *DefaultSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Primary = fn(s.Primary)
+	ret.Default = fn(s.Default)
+	return &ret
+}
+
+func (s *DefaultSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	val, diags := s.Primary.decode(content, blockLabels, ctx)
+	if val.IsNull() {
+		var moreDiags hcl.Diagnostics
+		val, moreDiags = s.Default.decode(content, blockLabels, ctx)
+		diags = append(diags, moreDiags...)
+	}
+	return val, diags
+}
+
+func (s *DefaultSpec) impliedType() cty.Type {
+	return s.Primary.impliedType()
+}
+
+// attrSpec implementation
+func (s *DefaultSpec) attrSchemata() []hcl.AttributeSchema {
+	// We must pass through the union of both of our nested specs so that
+	// we'll have both values available in the result.
+	var ret []hcl.AttributeSchema
+	if as, ok := s.Primary.(attrSpec); ok {
+		ret = append(ret, as.attrSchemata()...)
+	}
+	if as, ok := s.Default.(attrSpec); ok {
+		ret = append(ret, as.attrSchemata()...)
+	}
+	return ret
+}
+
+// blockSpec implementation
+func (s *DefaultSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
+	// Only the primary spec may describe a block, since otherwise
+	// our nestedSpec method below can't know which to return.
+	if bs, ok := s.Primary.(blockSpec); ok {
+		return bs.blockHeaderSchemata()
+	}
 	return nil
 }
 
-// blockSpec implementation
-func (s *DefaultSpec) nestedSpec() Spec {
-	if bs, ok := s.Primary.(blockSpec); ok {
-		return bs.nestedSpec()
-	}
-	return nil
+// blockSpec implementation
+func (s *DefaultSpec) nestedSpec() Spec {
+	if bs, ok := s.Primary.(blockSpec); ok {
+		return bs.nestedSpec()
+	}
+	return nil
+}
+
+func (s *DefaultSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	// We can't tell from here which of the two specs will ultimately be used
+	// in our result, so we'll just assume the first. This is usually the right
+	// choice because the default is often a literal spec that doesn't have a
+	// reasonable source range to return anyway.
+	return s.Primary.sourceRange(content, blockLabels)
+}
+
+// TransformExprSpec is a spec that wraps another and then evaluates a given
+// hcl.Expression on the result.
+//
+// The implied type of this spec is determined by evaluating the expression
+// with an unknown value of the nested spec's implied type, which may cause
+// the result to be imprecise. This spec should not be used in situations where
+// precise result type information is needed.
+type TransformExprSpec struct {
+	Wrapped      Spec
+	Expr         hcl.Expression
+	TransformCtx *hcl.EvalContext
+	VarName      string
+}
+
+func (s *TransformExprSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *TransformExprSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *TransformExprSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		// We won't try to run our function in this case, because it'll probably
+		// generate confusing additional errors that will distract from the
+		// root cause.
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	chiCtx := s.TransformCtx.NewChild()
+	chiCtx.Variables = map[string]cty.Value{
+		s.VarName: wrappedVal,
+	}
+	resultVal, resultDiags := s.Expr.Value(chiCtx)
+	diags = append(diags, resultDiags...)
+	return resultVal, diags
+}
+
+func (s *TransformExprSpec) impliedType() cty.Type {
+	wrappedTy := s.Wrapped.impliedType()
+	chiCtx := s.TransformCtx.NewChild()
+	chiCtx.Variables = map[string]cty.Value{
+		s.VarName: cty.UnknownVal(wrappedTy),
+	}
+	resultVal, _ := s.Expr.Value(chiCtx)
+	return resultVal.Type()
+}
+
+func (s *TransformExprSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	// We'll just pass through our wrapped range here, even though that's
+	// not super-accurate, because there's nothing better to return.
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+// TransformFuncSpec is a spec that wraps another and then evaluates a given
+// cty function with the result. The given function must expect exactly one
+// argument, where the result of the wrapped spec will be passed.
+//
+// The implied type of this spec is determined by type-checking the function
+// with an unknown value of the nested spec's implied type, which may cause
+// the result to be imprecise. This spec should not be used in situations where
+// precise result type information is needed.
+//
+// If the given function produces an error when run, this spec will produce
+// a non-user-actionable diagnostic message. It's the caller's responsibility
+// to ensure that the given function cannot fail for any non-error result
+// of the wrapped spec.
+type TransformFuncSpec struct {
+	Wrapped Spec
+	Func    function.Function
+}
+
+func (s *TransformFuncSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *TransformFuncSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *TransformFuncSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		// We won't try to run our function in this case, because it'll probably
+		// generate confusing additional errors that will distract from the
+		// root cause.
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	resultVal, err := s.Func.Call([]cty.Value{wrappedVal})
+	if err != nil {
+		// This is not a good example of a diagnostic because it is reporting
+		// a programming error in the calling application, rather than something
+		// an end-user could act on.
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Transform function failed",
+			Detail:   fmt.Sprintf("Decoder transform returned an error: %s", err),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	return resultVal, diags
+}
+
+func (s *TransformFuncSpec) impliedType() cty.Type {
+	wrappedTy := s.Wrapped.impliedType()
+	resultTy, err := s.Func.ReturnType([]cty.Type{wrappedTy})
+	if err != nil {
+		// Should never happen with a correctly-configured spec
+		return cty.DynamicPseudoType
+	}
+
+	return resultTy
+}
+
+func (s *TransformFuncSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	// We'll just pass through our wrapped range here, even though that's
+	// not super-accurate, because there's nothing better to return.
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+// TransformCallbackSpec is a spec that wraps another and then passes the
+// result to a given Go function, whose result becomes the result of this
+// spec. This is similar to TransformFuncSpec, but is more convenient when the
+// transformation is specific to the calling application and so there is no
+// benefit to describing it as a cty function.
+//
+// Because a Go function cannot be type-checked in advance, the implied type
+// of this spec is the given Type, or cty.DynamicPseudoType if Type is not
+// set. The function should always return a value conforming to Type.
+//
+// If the given function returns an error then it is reported as an error
+// diagnostic whose detail is the error message and whose subject is the
+// source range of the wrapped spec. The function is not called if the
+// wrapped spec produces errors.
+type TransformCallbackSpec struct {
+	Wrapped Spec
+	Func    func(cty.Value) (cty.Value, error)
+	Type    cty.Type
+}
+
+func (s *TransformCallbackSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *TransformCallbackSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *TransformCallbackSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		// We won't try to run our function in this case, because it'll probably
+		// generate confusing additional errors that will distract from the
+		// root cause.
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	resultVal, err := s.Func(wrappedVal)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value",
+			Detail:   err.Error(),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	return resultVal, diags
+}
+
+func (s *TransformCallbackSpec) impliedType() cty.Type {
+	if s.Type == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return s.Type
+}
+
+func (s *TransformCallbackSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+// WithRangeSpec is a spec that wraps another and produces an object that
+// contains both the wrapped spec's result and the source range it was
+// decoded from, so that the calling application can retain the location
+// of each decoded value after the configuration AST has been discarded.
+//
+// The result has two attributes: "value" is the result of the wrapped spec,
+// and "range" is the source range of the wrapped spec encoded as described
+// for RangeValue.
+type WithRangeSpec struct {
+	Wrapped Spec
+}
+
+func (s *WithRangeSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *WithRangeSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *WithRangeSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	val, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	rng := s.Wrapped.sourceRange(content, blockLabels)
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"value": val,
+		"range": RangeValue(rng),
+	}), diags
+}
+
+func (s *WithRangeSpec) impliedType() cty.Type {
+	return cty.Object(map[string]cty.Type{
+		"value": s.Wrapped.impliedType(),
+		"range": rangeType,
+	})
+}
+
+func (s *WithRangeSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+var posType = cty.Object(map[string]cty.Type{
+	"line":   cty.Number,
+	"column": cty.Number,
+	"byte":   cty.Number,
+})
+
+var rangeType = cty.Object(map[string]cty.Type{
+	"filename": cty.String,
+	"start":    posType,
+	"end":      posType,
+})
+
+// RangeValue returns a cty object value representing the given source range,
+// as used in the results of WithRangeSpec.
+//
+// The object has the attributes "filename", "start" and "end", where the
+// latter two are themselves objects with the number attributes "line",
+// "column" and "byte", corresponding to the fields of hcl.Pos.
+func RangeValue(rng hcl.Range) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"filename": cty.StringVal(rng.Filename),
+		"start":    posValue(rng.Start),
+		"end":      posValue(rng.End),
+	})
+}
+
+func posValue(pos hcl.Pos) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"line":   cty.NumberIntVal(int64(pos.Line)),
+		"column": cty.NumberIntVal(int64(pos.Column)),
+		"byte":   cty.NumberIntVal(int64(pos.Byte)),
+	})
+}
+
+// RefineValueSpec is a spec that wraps another and applies a fixed set of [cty]
+// value refinements to whatever value it produces.
+//
+// Refinements serve to constrain the range of any unknown values, and act as
+// assertions for known values by panicking if the final value does not meet
+// the refinement. Therefore applications using this spec must guarantee that
+// any value passing through the RefineValueSpec will always be consistent with
+// the refinements; if not then that is a bug in the application.
+//
+// The wrapped spec should typically be a [ValidateSpec], a [TransformFuncSpec],
+// or some other adapter that guarantees that the inner result cannot possibly
+// violate the refinements.
+type RefineValueSpec struct {
+	Wrapped Spec
+
+	// Refine is a function which accepts a builder for a refinement in
+	// progress and uses the builder pattern to add extra refinements to it,
+	// finally returning the same builder with those modifications applied.
+	Refine func(*cty.RefinementBuilder) *cty.RefinementBuilder
+}
+
+func (s *RefineValueSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *RefineValueSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *RefineValueSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		// We won't try to run our function in this case, because it'll probably
+		// generate confusing additional errors that will distract from the
+		// root cause.
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	return wrappedVal.RefineWith(s.Refine), diags
+}
+
+func (s *RefineValueSpec) impliedType() cty.Type {
+	return s.Wrapped.impliedType()
+}
+
+func (s *RefineValueSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+// ValidateSpec is a spec that allows for extended
+// developer-defined validation. The validation function receives the
+// result of the wrapped spec.
+//
+// The Subject field of the returned Diagnostic is optional. If not
+// specified, it is automatically populated with the range covered by
+// the wrapped spec.
+type ValidateSpec struct {
+	Wrapped Spec
+	Func    func(value cty.Value) hcl.Diagnostics
+}
+
+func (s *ValidateSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *ValidateSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *ValidateSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		// We won't try to run our function in this case, because it'll probably
+		// generate confusing additional errors that will distract from the
+		// root cause.
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	validateDiags := s.Func(wrappedVal)
+	// Auto-populate the Subject fields if they weren't set.
+	for i := range validateDiags {
+		if validateDiags[i].Subject == nil {
+			validateDiags[i].Subject = s.sourceRange(content, blockLabels).Ptr()
+		}
+	}
+
+	diags = append(diags, validateDiags...)
+	return wrappedVal, diags
+}
+
+func (s *ValidateSpec) impliedType() cty.Type {
+	return s.Wrapped.impliedType()
 }
 
-func (s *DefaultSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
-	// We can't tell from here which of the two specs will ultimately be used
-	// in our result, so we'll just assume the first. This is usually the right
-	// choice because the default is often a literal spec that doesn't have a
-	// reasonable source range to return anyway.
-	return s.Primary.sourceRange(content, blockLabels)
+func (s *ValidateSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
 }
 
-// TransformExprSpec is a spec that wraps another and then evaluates a given
-// hcl.Expression on the result.
+// EnumSpec is a spec that wraps another spec producing a string and requires
+// that the result be one of a fixed set of allowed values.
 //
-// The implied type of this spec is determined by evaluating the expression
-// with an unknown value of the nested spec's implied type, which may cause
-// the result to be imprecise. This spec should not be used in situations where
-// precise result type information is needed.
-type TransformExprSpec struct {
-	Wrapped      Spec
-	Expr         hcl.Expression
-	TransformCtx *hcl.EvalContext
-	VarName      string
+// The wrapped spec is typically an AttrSpec of type cty.String. To give the
+// attribute a default value, wrap a DefaultSpec instead so that the default
+// is subject to the same validation as a value given in configuration.
+//
+// Null and unknown results are not validated, and so are returned verbatim.
+type EnumSpec struct {
+	Wrapped Spec
+	Allowed []string
 }
 
-func (s *TransformExprSpec) visitSameBodyChildren(cb visitFunc) {
+func (s *EnumSpec) visitSameBodyChildren(cb visitFunc) {
 	cb(s.Wrapped)
 }
 
-func (s *TransformExprSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+// wrapperSpec implementation
+func (s *EnumSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *EnumSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
 	if diags.HasErrors() {
-		// We won't try to run our function in this case, because it'll probably
+		// We won't try to validate in this case, because it'll probably
 		// generate confusing additional errors that will distract from the
 		// root cause.
 		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
 
-	chiCtx := s.TransformCtx.NewChild()
-	chiCtx.Variables = map[string]cty.Value{
-		s.VarName: wrappedVal,
+	val, _ := wrappedVal.Unmark()
+	if val.IsNull() || !val.IsKnown() {
+		return wrappedVal, diags
+	}
+	val, err := convert.Convert(val, cty.String)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: %s", err.Error()),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
-	resultVal, resultDiags := s.Expr.Value(chiCtx)
-	diags = append(diags, resultDiags...)
-	return resultVal, diags
-}
 
-func (s *TransformExprSpec) impliedType() cty.Type {
-	wrappedTy := s.Wrapped.impliedType()
-	chiCtx := s.TransformCtx.NewChild()
-	chiCtx.Variables = map[string]cty.Value{
-		s.VarName: cty.UnknownVal(wrappedTy),
+	str := val.AsString()
+	for _, allowed := range s.Allowed {
+		if str == allowed {
+			return wrappedVal, diags
+		}
 	}
-	resultVal, _ := s.Expr.Value(chiCtx)
-	return resultVal.Type()
+
+	var buf bytes.Buffer
+	for i, allowed := range s.Allowed {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%q", allowed)
+	}
+	diags = append(diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsupported value",
+		Detail:   fmt.Sprintf("The value %q is not allowed here. The valid values are: %s.", str, buf.String()),
+		Subject:  s.sourceRange(content, blockLabels).Ptr(),
+	})
+	return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 }
 
-func (s *TransformExprSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
-	// We'll just pass through our wrapped range here, even though that's
-	// not super-accurate, because there's nothing better to return.
+func (s *EnumSpec) impliedType() cty.Type {
+	return s.Wrapped.impliedType()
+}
+
+func (s *EnumSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
 	return s.Wrapped.sourceRange(content, blockLabels)
 }
 
-// TransformFuncSpec is a spec that wraps another and then evaluates a given
-// cty function with the result. The given function must expect exactly one
-// argument, where the result of the wrapped spec will be passed.
+// LengthSpec is a spec that wraps another spec producing a collection or
+// structural value and requires that the result have a number of elements
+// within the given bounds.
 //
-// The implied type of this spec is determined by type-checking the function
-// with an unknown value of the nested spec's implied type, which may cause
-// the result to be imprecise. This spec should not be used in situations where
-// precise result type information is needed.
+// MinItems and MaxItems are interpreted in the same way as for
+// BlockListSpec: a MaxItems of zero means that there is no upper bound.
 //
-// If the given function produces an error when run, this spec will produce
-// a non-user-actionable diagnostic message. It's the caller's responsibility
-// to ensure that the given function cannot fail for any non-error result
-// of the wrapped spec.
-type TransformFuncSpec struct {
-	Wrapped Spec
-	Func    function.Function
+// Null and unknown results are not validated, and so are returned verbatim.
+type LengthSpec struct {
+	Wrapped  Spec
+	MinItems int
+	MaxItems int
 }
 
-func (s *TransformFuncSpec) visitSameBodyChildren(cb visitFunc) {
+func (s *LengthSpec) visitSameBodyChildren(cb visitFunc) {
 	cb(s.Wrapped)
 }
 
-func (s *TransformFuncSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+// wrapperSpec implementation
+func (s *LengthSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *LengthSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
 	if diags.HasErrors() {
-		// We won't try to run our function in this case, because it'll probably
+		// We won't try to validate in this case, because it'll probably
 		// generate confusing additional errors that will distract from the
 		// root cause.
 		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
 
-	resultVal, err := s.Func.Call([]cty.Value{wrappedVal})
-	if err != nil {
-		// This is not a good example of a diagnostic because it is reporting
-		// a programming error in the calling application, rather than something
-		// an end-user could act on.
+	val, _ := wrappedVal.Unmark()
+	if val.IsNull() || !val.IsKnown() {
+		return wrappedVal, diags
+	}
+	ty := val.Type()
+	if !(ty.IsCollectionType() || ty.IsTupleType() || ty.IsObjectType()) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
-			Summary:  "Transform function failed",
-			Detail:   fmt.Sprintf("Decoder transform returned an error: %s", err),
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: a collection is required, not %s.", ty.FriendlyName()),
 			Subject:  s.sourceRange(content, blockLabels).Ptr(),
 		})
 		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
 
-	return resultVal, diags
-}
-
-func (s *TransformFuncSpec) impliedType() cty.Type {
-	wrappedTy := s.Wrapped.impliedType()
-	resultTy, err := s.Func.ReturnType([]cty.Type{wrappedTy})
-	if err != nil {
-		// Should never happen with a correctly-configured spec
-		return cty.DynamicPseudoType
+	count := val.LengthInt()
+	switch {
+	case count < s.MinItems:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Insufficient elements",
+			Detail:   fmt.Sprintf("At least %d elements are required, but this value has %d.", s.MinItems, count),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+	case s.MaxItems > 0 && count > s.MaxItems:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Too many elements",
+			Detail:   fmt.Sprintf("No more than %d elements are allowed, but this value has %d.", s.MaxItems, count),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+	default:
+		return wrappedVal, diags
 	}
+	return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+}
 
-	return resultTy
+func (s *LengthSpec) impliedType() cty.Type {
+	return s.Wrapped.impliedType()
 }
 
-func (s *TransformFuncSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
-	// We'll just pass through our wrapped range here, even though that's
-	// not super-accurate, because there's nothing better to return.
+func (s *LengthSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
 	return s.Wrapped.sourceRange(content, blockLabels)
 }
 
-// RefineValueSpec is a spec that wraps another and applies a fixed set of [cty]
-// value refinements to whatever value it produces.
+// NumberRangeSpec is a spec that wraps another spec producing a number and
+// requires that the result be within the given inclusive bounds.
 //
-// Refinements serve to constrain the range of any unknown values, and act as
-// assertions for known values by panicking if the final value does not meet
-// the refinement. Therefore applications using this spec must guarantee that
-// any value passing through the RefineValueSpec will always be consistent with
-// the refinements; if not then that is a bug in the application.
+// Min and Max must each be either a known number or cty.NilVal, where
+// cty.NilVal means that there is no bound in that direction.
 //
-// The wrapped spec should typically be a [ValidateSpec], a [TransformFuncSpec],
-// or some other adapter that guarantees that the inner result cannot possibly
-// violate the refinements.
-type RefineValueSpec struct {
+// Null and unknown results are not validated, and so are returned verbatim.
+type NumberRangeSpec struct {
 	Wrapped Spec
-
-	// Refine is a function which accepts a builder for a refinement in
-	// progress and uses the builder pattern to add extra refinements to it,
-	// finally returning the same builder with those modifications applied.
-	Refine func(*cty.RefinementBuilder) *cty.RefinementBuilder
+	Min     cty.Value
+	Max     cty.Value
 }
 
-func (s *RefineValueSpec) visitSameBodyChildren(cb visitFunc) {
+func (s *NumberRangeSpec) visitSameBodyChildren(cb visitFunc) {
 	cb(s.Wrapped)
 }
 
-func (s *RefineValueSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+// wrapperSpec implementation
+func (s *NumberRangeSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *NumberRangeSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
 	if diags.HasErrors() {
-		// We won't try to run our function in this case, because it'll probably
+		// We won't try to validate in this case, because it'll probably
 		// generate confusing additional errors that will distract from the
 		// root cause.
 		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
 
-	return wrappedVal.RefineWith(s.Refine), diags
+	val, _ := wrappedVal.Unmark()
+	if val.IsNull() || !val.IsKnown() {
+		return wrappedVal, diags
+	}
+	val, err := convert.Convert(val, cty.Number)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: %s", err.Error()),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
+	}
+
+	num := val.AsBigFloat()
+	tooLow := s.Min != cty.NilVal && num.Cmp(s.Min.AsBigFloat()) < 0
+	tooHigh := s.Max != cty.NilVal && num.Cmp(s.Max.AsBigFloat()) > 0
+	if !tooLow && !tooHigh {
+		return wrappedVal, diags
+	}
+
+	var detail string
+	switch {
+	case s.Min != cty.NilVal && s.Max != cty.NilVal:
+		detail = fmt.Sprintf("The value must be between %s and %s, inclusive.", s.Min.AsBigFloat().Text('f', -1), s.Max.AsBigFloat().Text('f', -1))
+	case s.Min != cty.NilVal:
+		detail = fmt.Sprintf("The value must be at least %s.", s.Min.AsBigFloat().Text('f', -1))
+	default:
+		detail = fmt.Sprintf("The value must be no more than %s.", s.Max.AsBigFloat().Text('f', -1))
+	}
+	diags = append(diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Value out of range",
+		Detail:   detail,
+		Subject:  s.sourceRange(content, blockLabels).Ptr(),
+	})
+	return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 }
 
-func (s *RefineValueSpec) impliedType() cty.Type {
+func (s *NumberRangeSpec) impliedType() cty.Type {
 	return s.Wrapped.impliedType()
 }
 
-func (s *RefineValueSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+func (s *NumberRangeSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
 	return s.Wrapped.sourceRange(content, blockLabels)
 }
 
-// ValidateSpec is a spec that allows for extended
-// developer-defined validation. The validation function receives the
-// result of the wrapped spec.
+// ElementTypeSpec is a spec that wraps another spec producing a collection or
+// structural value and converts the result to the given type, so that the
+// result has that type even when the configuration doesn't determine its
+// element types.
 //
-// The Subject field of the returned Diagnostic is optional. If not
-// specified, it is automatically populated with the range covered by
-// the wrapped spec.
-type ValidateSpec struct {
+// This is useful when the wrapped spec accepts collections with any element
+// type, such as an AttrSpec of type cty.List(cty.DynamicPseudoType). An empty
+// literal like [] then produces a list of cty.DynamicPseudoType, which can
+// cause "element types do not match" errors when combined with typed values
+// later. Wrapping the AttrSpec in an ElementTypeSpec of type
+// cty.List(cty.String) instead produces an empty list of strings, and a
+// list of numbers is converted to a list of strings in the same way as for
+// an AttrSpec of that type.
+//
+// It is an error if the result cannot be converted to the given type. The
+// implied type of this spec is the given type.
+type ElementTypeSpec struct {
 	Wrapped Spec
-	Func    func(value cty.Value) hcl.Diagnostics
+	Type    cty.Type
 }
 
-func (s *ValidateSpec) visitSameBodyChildren(cb visitFunc) {
+func (s *ElementTypeSpec) visitSameBodyChildren(cb visitFunc) {
 	cb(s.Wrapped)
 }
 
-func (s *ValidateSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+// wrapperSpec implementation
+func (s *ElementTypeSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *ElementTypeSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	wrappedVal, diags := s.Wrapped.decode(content, blockLabels, ctx)
 	if diags.HasErrors() {
-		// We won't try to run our function in this case, because it'll probably
+		// We won't try to convert in this case, because it'll probably
 		// generate confusing additional errors that will distract from the
 		// root cause.
 		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
 
-	validateDiags := s.Func(wrappedVal)
-	// Auto-populate the Subject fields if they weren't set.
-	for i := range validateDiags {
-		if validateDiags[i].Subject == nil {
-			validateDiags[i].Subject = s.sourceRange(content, blockLabels).Ptr()
-		}
+	val, marks := wrappedVal.UnmarkDeepWithPaths()
+	val, err := convert.Convert(val, s.Type)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: %s.", err.Error()),
+			Subject:  s.sourceRange(content, blockLabels).Ptr(),
+		})
+		return cty.UnknownVal(s.impliedType().WithoutOptionalAttributesDeep()), diags
 	}
+	return val.MarkWithPaths(marks), diags
+}
 
-	diags = append(diags, validateDiags...)
-	return wrappedVal, diags
+func (s *ElementTypeSpec) impliedType() cty.Type {
+	return s.Type
 }
 
-func (s *ValidateSpec) impliedType() cty.Type {
-	return s.Wrapped.impliedType()
+func (s *ElementTypeSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
 }
 
-func (s *ValidateSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+// DurationSpec is a spec that wraps another spec producing a string, such
+// as an AttrSpec of type cty.String, and parses the result using the duration
+// syntax of the Go standard library, such as "30s" or "1h30m", to produce a
+// number of nanoseconds.
+//
+// Null and unknown results produce null and unknown numbers respectively.
+type DurationSpec struct {
+	Wrapped Spec
+}
+
+func (s *DurationSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *DurationSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *DurationSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	return decodeUnitString(s.Wrapped, content, blockLabels, ctx, func(str string) (*big.Rat, hcl.Diagnostics) {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return nil, hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid duration",
+					Detail:   fmt.Sprintf("The value %q is not a valid duration. A duration is a sequence of decimal numbers, each with a unit suffix, such as \"30s\" or \"1h30m\". The valid units are \"ns\", \"us\", \"ms\", \"s\", \"m\" and \"h\".", str),
+				},
+			}
+		}
+		return new(big.Rat).SetInt64(int64(d)), nil
+	})
+}
+
+func (s *DurationSpec) impliedType() cty.Type {
+	return cty.Number
+}
+
+func (s *DurationSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
+	return s.Wrapped.sourceRange(content, blockLabels)
+}
+
+// ByteSizeSpec is a spec that wraps another spec producing a string, such as
+// an AttrSpec of type cty.String, and parses the result as a size such as
+// "512MiB" or "1.5GB" to produce a whole number of bytes.
+//
+// A size is a non-negative decimal number, written as digits with an
+// optional fractional part, followed by an optional unit suffix, which may
+// be separated from the number by spaces. The suffix is
+// either "B" for bytes, one of the decimal units "kB", "MB", "GB", "TB", "PB"
+// and "EB" that are powers of 1000, or one of the binary units "KiB", "MiB",
+// "GiB", "TiB", "PiB" and "EiB" that are powers of 1024. The suffixes are
+// matched without regard to letter case. A number without a suffix is a
+// number of bytes.
+//
+// Null and unknown results produce null and unknown numbers respectively.
+type ByteSizeSpec struct {
+	Wrapped Spec
+}
+
+func (s *ByteSizeSpec) visitSameBodyChildren(cb visitFunc) {
+	cb(s.Wrapped)
+}
+
+// wrapperSpec implementation
+func (s *ByteSizeSpec) withSameBodyChildren(fn func(Spec) Spec) Spec {
+	ret := *s
+	ret.Wrapped = fn(s.Wrapped)
+	return &ret
+}
+
+func (s *ByteSizeSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	return decodeUnitString(s.Wrapped, content, blockLabels, ctx, parseByteSize)
+}
+
+func (s *ByteSizeSpec) impliedType() cty.Type {
+	return cty.Number
+}
+
+func (s *ByteSizeSpec) sourceRange(content *hcl.BodyContent, blockLabels []blockLabel) hcl.Range {
 	return s.Wrapped.sourceRange(content, blockLabels)
 }
 
+// byteSizeUnits are the multipliers for the unit suffixes accepted by
+// ByteSizeSpec, keyed by their lowercase forms.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"eb":  1000 * 1000 * 1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+}
+
+func parseByteSize(str string) (*big.Rat, hcl.Diagnostics) {
+	numStr := strings.TrimRight(str, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	unit := strings.ToLower(str[len(numStr):])
+	numStr = strings.TrimRight(numStr, " ")
+
+	mult, unitOk := byteSizeUnits[unit]
+	num, numOk := new(big.Rat).SetString(numStr)
+	if !unitOk || !numOk || !isPlainDecimal(numStr) {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid byte size",
+				Detail:   fmt.Sprintf("The value %q is not a valid byte size. A byte size is a non-negative number with an optional unit suffix, such as \"512MiB\" or \"1.5GB\". The valid units are \"B\", the decimal units \"kB\", \"MB\", \"GB\", \"TB\", \"PB\" and \"EB\", and the binary units \"KiB\", \"MiB\", \"GiB\", \"TiB\", \"PiB\" and \"EiB\".", str),
+			},
+		}
+	}
+
+	num.Mul(num, new(big.Rat).SetInt64(mult))
+	if !num.IsInt() {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid byte size",
+				Detail:   fmt.Sprintf("The value %q is not a whole number of bytes.", str),
+			},
+		}
+	}
+	return num, nil
+}
+
+// isPlainDecimal returns true if the given string consists only of decimal
+// digits with an optional fractional part, excluding the signs, exponents,
+// fractions and base prefixes that big.Rat would otherwise accept.
+func isPlainDecimal(str string) bool {
+	intPart, fracPart, hasFrac := strings.Cut(str, ".")
+	if intPart == "" || (hasFrac && fracPart == "") {
+		return false
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// decodeUnitString decodes the given spec, which must produce a string, and
+// then uses the given function to parse the result as a number. The parse
+// function's diagnostics are given the spec's source range as their subject.
+func decodeUnitString(wrapped Spec, content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext, parse func(string) (*big.Rat, hcl.Diagnostics)) (cty.Value, hcl.Diagnostics) {
+	wrappedVal, diags := wrapped.decode(content, blockLabels, ctx)
+	if diags.HasErrors() {
+		return cty.UnknownVal(cty.Number), diags
+	}
+
+	val, marks := wrappedVal.Unmark()
+	switch {
+	case val.IsNull():
+		return cty.NullVal(cty.Number).WithMarks(marks), diags
+	case !val.IsKnown():
+		return cty.UnknownVal(cty.Number).WithMarks(marks), diags
+	}
+	val, err := convert.Convert(val, cty.String)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: %s.", err.Error()),
+			Subject:  wrapped.sourceRange(content, blockLabels).Ptr(),
+		})
+		return cty.UnknownVal(cty.Number), diags
+	}
+
+	num, parseDiags := parse(strings.TrimSpace(val.AsString()))
+	if parseDiags.HasErrors() {
+		rng := wrapped.sourceRange(content, blockLabels)
+		for _, diag := range parseDiags {
+			diag.Subject = rng.Ptr()
+		}
+		return cty.UnknownVal(cty.Number), append(diags, parseDiags...)
+	}
+	return cty.NumberVal(new(big.Float).SetRat(num)).WithMarks(marks), diags
+}
+
 // noopSpec is a placeholder spec that does nothing, used in situations where
 // a non-nil placeholder spec is required. It is not exported because there is
 // no reason to use it directly; it is always an implementation detail only.
@@ -6,7 +6,9 @@ package hcldec
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/apparentlymart/go-dump/dump"
 	"github.com/google/go-cmp/cmp"
@@ -15,43 +17,72 @@ import (
 	"github.com/zclconf/go-cty/cty/function"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/customdecode"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
 )
 
 // Verify that all of our spec types implement the necessary interfaces
 var _ Spec = ObjectSpec(nil)
 var _ Spec = TupleSpec(nil)
 var _ Spec = (*AttrSpec)(nil)
+var _ Spec = (*TupleAttrSpec)(nil)
+var _ Spec = (*OneOfSpec)(nil)
+var _ Spec = (*RequiredIfSpec)(nil)
 var _ Spec = (*LiteralSpec)(nil)
 var _ Spec = (*ExprSpec)(nil)
 var _ Spec = (*BlockSpec)(nil)
+var _ Spec = (*AttrOrBlockSpec)(nil)
 var _ Spec = (*BlockListSpec)(nil)
 var _ Spec = (*BlockSetSpec)(nil)
 var _ Spec = (*BlockMapSpec)(nil)
+var _ Spec = (*OrderedBlocksSpec)(nil)
 var _ Spec = (*BlockAttrsSpec)(nil)
+var _ Spec = (*BlockPresenceSpec)(nil)
+var _ Spec = (*BodyAttrsSpec)(nil)
+var _ Spec = (*VariantSpec)(nil)
 var _ Spec = (*BlockLabelSpec)(nil)
+var _ Spec = (*BlockLabelPatternSpec)(nil)
 var _ Spec = (*DefaultSpec)(nil)
 var _ Spec = (*TransformExprSpec)(nil)
 var _ Spec = (*TransformFuncSpec)(nil)
+var _ Spec = (*TransformCallbackSpec)(nil)
 var _ Spec = (*ValidateSpec)(nil)
+var _ Spec = (*EnumSpec)(nil)
+var _ Spec = (*LengthSpec)(nil)
+var _ Spec = (*NumberRangeSpec)(nil)
+var _ Spec = (*DurationSpec)(nil)
+var _ Spec = (*ByteSizeSpec)(nil)
+var _ Spec = (*ElementTypeSpec)(nil)
+var _ Spec = (*WithRangeSpec)(nil)
 
 var _ attrSpec = (*AttrSpec)(nil)
+var _ attrSpec = (*TupleAttrSpec)(nil)
 var _ attrSpec = (*DefaultSpec)(nil)
+var _ attrSpec = (*AttrOrBlockSpec)(nil)
+var _ attrSpec = (*OneOfSpec)(nil)
 
 var _ blockSpec = (*BlockSpec)(nil)
+var _ blockSpec = (*AttrOrBlockSpec)(nil)
 var _ blockSpec = (*BlockListSpec)(nil)
 var _ blockSpec = (*BlockSetSpec)(nil)
 var _ blockSpec = (*BlockMapSpec)(nil)
 var _ blockSpec = (*BlockAttrsSpec)(nil)
+var _ blockSpec = (*BlockPresenceSpec)(nil)
 var _ blockSpec = (*DefaultSpec)(nil)
 
 var _ specNeedingVariables = (*AttrSpec)(nil)
+var _ specNeedingVariables = (*TupleAttrSpec)(nil)
+var _ specNeedingVariables = (*OneOfSpec)(nil)
 var _ specNeedingVariables = (*BlockSpec)(nil)
+var _ specNeedingVariables = (*AttrOrBlockSpec)(nil)
 var _ specNeedingVariables = (*BlockListSpec)(nil)
 var _ specNeedingVariables = (*BlockSetSpec)(nil)
 var _ specNeedingVariables = (*BlockMapSpec)(nil)
 var _ specNeedingVariables = (*BlockAttrsSpec)(nil)
 
+var _ bodySpec = (*BodyAttrsSpec)(nil)
+
 func TestDefaultSpec(t *testing.T) {
 	config := `
 foo = fooval
@@ -146,6 +177,58 @@ bar = barval
 			t.Errorf("wrong Decode result\ngot:  %#v\nwant: %#v", got, want)
 		}
 	})
+
+	t.Run("primary explicitly null", func(t *testing.T) {
+		f, diags := hclsyntax.ParseConfig([]byte("foo = null\nbar = \"bar value\"\n"), "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		spec := &DefaultSpec{
+			Primary: &AttrSpec{
+				Name: "foo",
+				Type: cty.String,
+			},
+			Default: &AttrSpec{
+				Name: "bar",
+				Type: cty.String,
+			},
+		}
+
+		got, err := Decode(f.Body, spec, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := cty.StringVal("bar value")
+		if !got.RawEquals(want) {
+			t.Errorf("wrong Decode result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+
+	t.Run("primary absent", func(t *testing.T) {
+		f, diags := hclsyntax.ParseConfig([]byte("bar = \"bar value\"\n"), "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		spec := &DefaultSpec{
+			Primary: &AttrSpec{
+				Name: "foo",
+				Type: cty.String,
+			},
+			Default: &AttrSpec{
+				Name: "bar",
+				Type: cty.String,
+			},
+		}
+
+		got, err := Decode(f.Body, spec, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := cty.StringVal("bar value")
+		if !got.RawEquals(want) {
+			t.Errorf("wrong Decode result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
 }
 
 func TestValidateFuncSpec(t *testing.T) {
@@ -214,6 +297,589 @@ foo = "invalid"
 	}
 }
 
+func TestTransformCallbackSpec(t *testing.T) {
+	config := `
+foo = "  Hello  "
+bar = ""
+`
+	f, diags := hclsyntax.ParseConfig([]byte(config), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	normalize := func(v cty.Value) (cty.Value, error) {
+		s := strings.TrimSpace(v.AsString())
+		if s == "" {
+			return cty.NilVal, fmt.Errorf("The value must not be empty")
+		}
+		return cty.StringVal(strings.ToLower(s)), nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		spec := &TransformCallbackSpec{
+			Wrapped: &AttrSpec{
+				Name: "foo",
+				Type: cty.String,
+			},
+			Func: normalize,
+			Type: cty.String,
+		}
+
+		got, _, diags := PartialDecode(f.Body, spec, nil)
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+		if want := cty.StringVal("hello"); !got.RawEquals(want) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+		if got, want := ImpliedType(spec), cty.String; !got.Equals(want) {
+			t.Errorf("wrong implied type\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		spec := &TransformCallbackSpec{
+			Wrapped: &AttrSpec{
+				Name: "bar",
+				Type: cty.String,
+			},
+			Func: normalize,
+		}
+
+		got, _, diags := PartialDecode(f.Body, spec, nil)
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+		}
+		if got, want := diags[0].Detail, "The value must not be empty"; got != want {
+			t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+		}
+		wantRange := hcl.Range{
+			Start: hcl.Pos{Line: 3, Column: 7, Byte: 25},
+			End:   hcl.Pos{Line: 3, Column: 9, Byte: 27},
+		}
+		if got := *diags[0].Subject; got != wantRange {
+			t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, wantRange)
+		}
+		if want := cty.DynamicVal; !got.RawEquals(want) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+}
+
+func TestEnumSpec(t *testing.T) {
+	spec := func(name string) Spec {
+		return &EnumSpec{
+			Wrapped: &DefaultSpec{
+				Primary: &AttrSpec{
+					Name: name,
+					Type: cty.String,
+				},
+				Default: &LiteralSpec{
+					Value: cty.StringVal("medium"),
+				},
+			},
+			Allowed: []string{"small", "medium", "large"},
+		}
+	}
+
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantDiags []string
+	}{
+		"allowed": {
+			`size = "large"`,
+			spec("size"),
+			cty.StringVal("large"),
+			nil,
+		},
+		"not allowed": {
+			`size = "huge"`,
+			spec("size"),
+			cty.UnknownVal(cty.String),
+			[]string{
+				`:1,8-14: Unsupported value; The value "huge" is not allowed here. The valid values are: "small", "medium", "large".`,
+			},
+		},
+		"default": {
+			``,
+			spec("size"),
+			cty.StringVal("medium"),
+			nil,
+		},
+		"invalid default": {
+			``,
+			&EnumSpec{
+				Wrapped: &DefaultSpec{
+					Primary: &AttrSpec{
+						Name: "size",
+						Type: cty.String,
+					},
+					Default: &LiteralSpec{
+						Value: cty.StringVal("tiny"),
+					},
+				},
+				Allowed: []string{"small", "large"},
+			},
+			cty.UnknownVal(cty.String),
+			[]string{
+				`:1,1-1: Unsupported value; The value "tiny" is not allowed here. The valid values are: "small", "large".`,
+			},
+		},
+		"null": {
+			`size = null`,
+			&EnumSpec{
+				Wrapped: &AttrSpec{
+					Name: "size",
+					Type: cty.String,
+				},
+				Allowed: []string{"small"},
+			},
+			cty.NullVal(cty.String),
+			nil,
+		},
+		"unknown": {
+			`size = unk`,
+			spec("size"),
+			cty.UnknownVal(cty.String),
+			nil,
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"unk": cty.UnknownVal(cty.String),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, test.spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLengthAndNumberRangeSpecs(t *testing.T) {
+	lengthSpec := &LengthSpec{
+		Wrapped: &AttrSpec{
+			Name: "items",
+			Type: cty.List(cty.String),
+		},
+		MinItems: 1,
+		MaxItems: 2,
+	}
+	rangeSpec := func(min, max cty.Value) Spec {
+		return &NumberRangeSpec{
+			Wrapped: &AttrSpec{
+				Name: "count",
+				Type: cty.Number,
+			},
+			Min: min,
+			Max: max,
+		}
+	}
+
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantDiags []string
+	}{
+		"length ok": {
+			`items = ["a"]`,
+			lengthSpec,
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			nil,
+		},
+		"too few": {
+			`items = []`,
+			lengthSpec,
+			cty.UnknownVal(cty.List(cty.String)),
+			[]string{
+				`:1,9-11: Insufficient elements; At least 1 elements are required, but this value has 0.`,
+			},
+		},
+		"too many": {
+			`items = ["a", "b", "c"]`,
+			lengthSpec,
+			cty.UnknownVal(cty.List(cty.String)),
+			[]string{
+				`:1,9-24: Too many elements; No more than 2 elements are allowed, but this value has 3.`,
+			},
+		},
+		"length null": {
+			``,
+			lengthSpec,
+			cty.NullVal(cty.List(cty.String)),
+			nil,
+		},
+		"length unknown": {
+			`items = unk`,
+			lengthSpec,
+			cty.UnknownVal(cty.List(cty.String)),
+			nil,
+		},
+		"in range": {
+			`count = 3`,
+			rangeSpec(cty.NumberIntVal(0), cty.NumberIntVal(10)),
+			cty.NumberIntVal(3),
+			nil,
+		},
+		"below range": {
+			`count = -1`,
+			rangeSpec(cty.NumberIntVal(0), cty.NumberIntVal(10)),
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,9-11: Value out of range; The value must be between 0 and 10, inclusive.`,
+			},
+		},
+		"below minimum": {
+			`count = -0.5`,
+			rangeSpec(cty.NumberIntVal(0), cty.NilVal),
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,9-13: Value out of range; The value must be at least 0.`,
+			},
+		},
+		"above maximum": {
+			`count = 11`,
+			rangeSpec(cty.NilVal, cty.NumberFloatVal(10.5)),
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,9-11: Value out of range; The value must be no more than 10.5.`,
+			},
+		},
+		"range unknown": {
+			`count = unk`,
+			rangeSpec(cty.NumberIntVal(0), cty.NilVal),
+			cty.UnknownVal(cty.Number),
+			nil,
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"unk": cty.DynamicVal,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, test.spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnitStringSpecs(t *testing.T) {
+	durationSpec := &DurationSpec{
+		Wrapped: &AttrSpec{
+			Name: "timeout",
+			Type: cty.String,
+		},
+	}
+	sizeSpec := &ByteSizeSpec{
+		Wrapped: &AttrSpec{
+			Name: "size",
+			Type: cty.String,
+		},
+	}
+
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantDiags []string
+	}{
+		"duration": {
+			`timeout = "1h30m"`,
+			durationSpec,
+			cty.NumberIntVal(int64(90 * time.Minute)),
+			nil,
+		},
+		"fractional duration": {
+			`timeout = "1.5s"`,
+			durationSpec,
+			cty.NumberIntVal(int64(1500 * time.Millisecond)),
+			nil,
+		},
+		"invalid duration": {
+			`timeout = "30"`,
+			durationSpec,
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,11-15: Invalid duration; The value "30" is not a valid duration. A duration is a sequence of decimal numbers, each with a unit suffix, such as "30s" or "1h30m". The valid units are "ns", "us", "ms", "s", "m" and "h".`,
+			},
+		},
+		"duration null": {
+			``,
+			durationSpec,
+			cty.NullVal(cty.Number),
+			nil,
+		},
+		"duration unknown": {
+			`timeout = unk`,
+			durationSpec,
+			cty.UnknownVal(cty.Number),
+			nil,
+		},
+		"binary size": {
+			`size = "512MiB"`,
+			sizeSpec,
+			cty.NumberIntVal(512 << 20),
+			nil,
+		},
+		"decimal size": {
+			`size = "1.5 GB"`,
+			sizeSpec,
+			cty.NumberIntVal(1500000000),
+			nil,
+		},
+		"size without unit": {
+			`size = 1024`,
+			sizeSpec,
+			cty.NumberIntVal(1024),
+			nil,
+		},
+		"lowercase unit": {
+			`size = "2kib"`,
+			sizeSpec,
+			cty.NumberIntVal(2048),
+			nil,
+		},
+		"unknown unit": {
+			`size = "2XB"`,
+			sizeSpec,
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,8-13: Invalid byte size; The value "2XB" is not a valid byte size. A byte size is a non-negative number with an optional unit suffix, such as "512MiB" or "1.5GB". The valid units are "B", the decimal units "kB", "MB", "GB", "TB", "PB" and "EB", and the binary units "KiB", "MiB", "GiB", "TiB", "PiB" and "EiB".`,
+			},
+		},
+		"negative size": {
+			`size = "-1KB"`,
+			sizeSpec,
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,8-14: Invalid byte size; The value "-1KB" is not a valid byte size. A byte size is a non-negative number with an optional unit suffix, such as "512MiB" or "1.5GB". The valid units are "B", the decimal units "kB", "MB", "GB", "TB", "PB" and "EB", and the binary units "KiB", "MiB", "GiB", "TiB", "PiB" and "EiB".`,
+			},
+		},
+		"hexadecimal size": {
+			`size = "0x10MB"`,
+			sizeSpec,
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,8-16: Invalid byte size; The value "0x10MB" is not a valid byte size. A byte size is a non-negative number with an optional unit suffix, such as "512MiB" or "1.5GB". The valid units are "B", the decimal units "kB", "MB", "GB", "TB", "PB" and "EB", and the binary units "KiB", "MiB", "GiB", "TiB", "PiB" and "EiB".`,
+			},
+		},
+		"binary literal size": {
+			`size = "0b1KiB"`,
+			sizeSpec,
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,8-16: Invalid byte size; The value "0b1KiB" is not a valid byte size. A byte size is a non-negative number with an optional unit suffix, such as "512MiB" or "1.5GB". The valid units are "B", the decimal units "kB", "MB", "GB", "TB", "PB" and "EB", and the binary units "KiB", "MiB", "GiB", "TiB", "PiB" and "EiB".`,
+			},
+		},
+		"fractional bytes": {
+			`size = "1.5B"`,
+			sizeSpec,
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,8-14: Invalid byte size; The value "1.5B" is not a whole number of bytes.`,
+			},
+		},
+		"size wrong type": {
+			`size = ["1B"]`,
+			&ByteSizeSpec{
+				Wrapped: &AttrSpec{
+					Name: "size",
+					Type: cty.DynamicPseudoType,
+				},
+			},
+			cty.UnknownVal(cty.Number),
+			[]string{
+				`:1,8-14: Unsuitable value type; Unsuitable value: string required.`,
+			},
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"unk": cty.DynamicVal,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, test.spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestElementTypeSpec(t *testing.T) {
+	listSpec := &ElementTypeSpec{
+		Wrapped: &AttrSpec{
+			Name: "items",
+			Type: cty.List(cty.DynamicPseudoType),
+		},
+		Type: cty.List(cty.String),
+	}
+	anySpec := &ElementTypeSpec{
+		Wrapped: &AttrSpec{
+			Name: "items",
+			Type: cty.DynamicPseudoType,
+		},
+		Type: cty.Map(cty.String),
+	}
+
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantDiags []string
+	}{
+		"empty list": {
+			`items = []`,
+			listSpec,
+			cty.ListValEmpty(cty.String),
+			nil,
+		},
+		"typed list": {
+			`items = [1, 2]`,
+			listSpec,
+			cty.ListVal([]cty.Value{cty.StringVal("1"), cty.StringVal("2")}),
+			nil,
+		},
+		"unconvertible list": {
+			`items = [[1]]`,
+			listSpec,
+			cty.UnknownVal(cty.List(cty.String)),
+			[]string{
+				`:1,9-14: Unsuitable value type; Unsuitable value: incorrect list element type: string required.`,
+			},
+		},
+		"list of nulls": {
+			`items = [null]`,
+			listSpec,
+			cty.ListVal([]cty.Value{cty.NullVal(cty.String)}),
+			nil,
+		},
+		"absent": {
+			``,
+			listSpec,
+			cty.NullVal(cty.List(cty.String)),
+			nil,
+		},
+		"unknown": {
+			`items = unk`,
+			listSpec,
+			cty.UnknownVal(cty.List(cty.String)),
+			nil,
+		},
+		"empty object": {
+			`items = {}`,
+			anySpec,
+			cty.MapValEmpty(cty.String),
+			nil,
+		},
+		"typed object": {
+			`items = { a = 1 }`,
+			anySpec,
+			cty.MapVal(map[string]cty.Value{"a": cty.StringVal("1")}),
+			nil,
+		},
+		"wrong kind": {
+			`items = []`,
+			anySpec,
+			cty.UnknownVal(cty.Map(cty.String)),
+			[]string{
+				`:1,9-11: Unsuitable value type; Unsuitable value: map of string required.`,
+			},
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"unk": cty.DynamicVal,
+		},
+	}
+
+	if got, want := ImpliedType(anySpec), cty.Map(cty.String); !got.Equals(want) {
+		t.Errorf("wrong implied type %#v; want %#v", got, want)
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, test.spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !test.want.IsKnown() {
+				// Conversion can add refinements to unknown results, so
+				// we compare only their types.
+				if got.IsKnown() || !got.Type().Equals(test.want.Type()) {
+					t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+				}
+			} else if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
 func TestRefineValueSpec(t *testing.T) {
 	config := `
 foo = "hello"
@@ -316,3 +982,1094 @@ marked = mark(unk)
 		t.Errorf("wrong result\n%s", diff)
 	}
 }
+
+func TestOrderedBlocksSpec(t *testing.T) {
+	config := `
+rule "a" {
+  n = 1
+}
+group {
+  n = 2
+}
+rule "b" {
+  n = 3
+}
+other {}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(config), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	spec := &OrderedBlocksSpec{
+		Nested: map[string]Spec{
+			"rule": ObjectSpec{
+				"name": &BlockLabelSpec{Index: 0, Name: "name"},
+				"n":    &AttrSpec{Name: "n", Type: cty.Number},
+			},
+			"group": ObjectSpec{
+				"n": &AttrSpec{Name: "n", Type: cty.Number},
+			},
+		},
+		MaxItems: 3,
+	}
+
+	got, _, diags := PartialDecode(f.Body, spec, nil)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	want := cty.TupleVal([]cty.Value{
+		cty.ObjectVal(map[string]cty.Value{
+			"type": cty.StringVal("rule"),
+			"value": cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("a"),
+				"n":    cty.NumberIntVal(1),
+			}),
+		}),
+		cty.ObjectVal(map[string]cty.Value{
+			"type": cty.StringVal("group"),
+			"value": cty.ObjectVal(map[string]cty.Value{
+				"n": cty.NumberIntVal(2),
+			}),
+		}),
+		cty.ObjectVal(map[string]cty.Value{
+			"type": cty.StringVal("rule"),
+			"value": cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("b"),
+				"n":    cty.NumberIntVal(3),
+			}),
+		}),
+	})
+	if diff := cmp.Diff(want, got, ctydebug.CmpOptions); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	spec.MaxItems = 2
+	_, _, diags = PartialDecode(f.Body, spec, nil)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Too many blocks"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diags[0].Subject.Start.Line, 8; got != want {
+		t.Errorf("wrong subject line %d; want %d", got, want)
+	}
+}
+
+func TestWithRangeSpec(t *testing.T) {
+	config := `
+foo = "hello"
+`
+	f, diags := hclsyntax.ParseConfig([]byte(config), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	spec := ObjectSpec{
+		"foo": &WithRangeSpec{
+			Wrapped: &AttrSpec{Name: "foo", Type: cty.String},
+		},
+		"bar": &WithRangeSpec{
+			Wrapped: &AttrSpec{Name: "bar", Type: cty.String},
+		},
+	}
+
+	got, diags := Decode(f.Body, spec, nil)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"foo": cty.ObjectVal(map[string]cty.Value{
+			"value": cty.StringVal("hello"),
+			"range": RangeValue(hcl.Range{
+				Filename: "test.hcl",
+				Start:    hcl.Pos{Line: 2, Column: 7, Byte: 7},
+				End:      hcl.Pos{Line: 2, Column: 14, Byte: 14},
+			}),
+		}),
+		"bar": cty.ObjectVal(map[string]cty.Value{
+			"value": cty.NullVal(cty.String),
+			"range": RangeValue(f.Body.MissingItemRange()),
+		}),
+	})
+	if diff := cmp.Diff(want, got, ctydebug.CmpOptions); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+	if got, want := ImpliedType(spec), got.Type(); !got.Equals(want) {
+		t.Errorf("wrong implied type\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := RangeValue(hcl.Range{}).Type(), rangeType; !got.Equals(want) {
+		t.Errorf("wrong range type\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestAttrOrBlockSpec(t *testing.T) {
+	nested := ObjectSpec{
+		"size": &AttrSpec{Name: "size", Type: cty.Number},
+		"mode": &AttrSpec{Name: "mode", Type: cty.String},
+	}
+	spec := &AttrOrBlockSpec{
+		Name:   "options",
+		Nested: nested,
+	}
+	wantVal := cty.ObjectVal(map[string]cty.Value{
+		"size": cty.NumberIntVal(2),
+		"mode": cty.NullVal(cty.String),
+	})
+
+	tests := map[string]struct {
+		config    string
+		json      bool
+		spec      *AttrOrBlockSpec
+		want      cty.Value
+		wantDiags []string
+	}{
+		"attribute": {
+			config: "options = {\n  size = 2\n}\n",
+			want:   wantVal,
+		},
+		"block": {
+			config: "options {\n  size = 2\n}\n",
+			want:   wantVal,
+		},
+		"json": {
+			config: `{"options": {"size": 2}}`,
+			json:   true,
+			want:   wantVal,
+		},
+		"neither": {
+			config: "",
+			want:   cty.NullVal(wantVal.Type()),
+		},
+		"neither but required": {
+			config:    "",
+			spec:      &AttrOrBlockSpec{Name: "options", Nested: nested, Required: true},
+			want:      cty.NullVal(wantVal.Type()),
+			wantDiags: []string{"Missing options argument or block"},
+		},
+		"deprecated attribute": {
+			config:    "options = {\n  size = 2\n}\n",
+			spec:      &AttrOrBlockSpec{Name: "options", Nested: nested, DeprecatedAttr: true},
+			want:      wantVal,
+			wantDiags: []string{"Deprecated options argument"},
+		},
+		"deprecated block": {
+			config:    "options {\n  size = 2\n}\n",
+			spec:      &AttrOrBlockSpec{Name: "options", Nested: nested, DeprecatedBlock: true},
+			want:      wantVal,
+			wantDiags: []string{"Deprecated options block"},
+		},
+		"both": {
+			config:    "options = {}\noptions {\n  size = 2\n}\n",
+			want:      cty.UnknownVal(wantVal.Type()),
+			wantDiags: []string{"Duplicate options definition"},
+		},
+		"two blocks": {
+			config:    "options {\n  size = 2\n}\noptions {\n  size = 3\n}\n",
+			want:      wantVal,
+			wantDiags: []string{"Duplicate options block"},
+		},
+		"wrong attribute type": {
+			config:    "options = \"big\"\n",
+			want:      cty.UnknownVal(wantVal.Type()),
+			wantDiags: []string{"Incorrect attribute value type"},
+		},
+		"unexpected block argument": {
+			config: "options {\n  colour = 2\n}\n",
+			want: cty.ObjectVal(map[string]cty.Value{
+				"size": cty.NullVal(cty.Number),
+				"mode": cty.NullVal(cty.String),
+			}),
+			wantDiags: []string{"Unsupported argument"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var f *hcl.File
+			var diags hcl.Diagnostics
+			if test.json {
+				f, diags = json.Parse([]byte(test.config), "test.json")
+			} else {
+				f, diags = hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.InitialPos)
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			spec := spec
+			if test.spec != nil {
+				spec = test.spec
+			}
+			got, diags := Decode(f.Body, spec, nil)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Summary)
+			}
+			if !reflect.DeepEqual(gotDiags, test.wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.wantDiags)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	if got, want := ChildBlockTypes(spec), map[string]Spec{"options": spec.Nested}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong child block types\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestTupleAttrSpec(t *testing.T) {
+	spec := &TupleAttrSpec{
+		Name:         "bind",
+		ElementTypes: []cty.Type{cty.String, cty.Number},
+		Required:     true,
+	}
+
+	tests := map[string]struct {
+		config    string
+		want      cty.Value
+		wantDiags []string
+	}{
+		"valid": {
+			config: "bind = [\"0.0.0.0\", 8080]\n",
+			want:   cty.TupleVal([]cty.Value{cty.StringVal("0.0.0.0"), cty.NumberIntVal(8080)}),
+		},
+		"converted": {
+			config: "bind = [\"0.0.0.0\", \"8080\"]\n",
+			want:   cty.TupleVal([]cty.Value{cty.StringVal("0.0.0.0"), cty.NumberIntVal(8080)}),
+		},
+		"wrong element type": {
+			config: "bind = [\"0.0.0.0\", \"http\"]\n",
+			want:   cty.TupleVal([]cty.Value{cty.StringVal("0.0.0.0"), cty.UnknownVal(cty.Number)}),
+			wantDiags: []string{
+				`:1,20-26: Incorrect attribute value type; Inappropriate value for element 1 of attribute "bind": a number is required.`,
+			},
+		},
+		"wrong element count": {
+			config: "bind = [\"0.0.0.0\"]\n",
+			want:   cty.UnknownVal(cty.Tuple([]cty.Type{cty.String, cty.Number})),
+			wantDiags: []string{
+				`:1,8-19: Incorrect attribute value type; Inappropriate value for attribute "bind": a tuple of 2 elements is required, but this has 1.`,
+			},
+		},
+		"not a tuple constructor": {
+			config: "bind = addr\n",
+			want:   cty.TupleVal([]cty.Value{cty.StringVal("127.0.0.1"), cty.NumberIntVal(80)}),
+		},
+		"not a tuple constructor with wrong type": {
+			config: "bind = \"0.0.0.0:8080\"\n",
+			want:   cty.UnknownVal(cty.Tuple([]cty.Type{cty.String, cty.Number})),
+			wantDiags: []string{
+				`:1,8-22: Incorrect attribute value type; Inappropriate value for attribute "bind": tuple required.`,
+			},
+		},
+		"missing": {
+			config: "",
+			want:   cty.NullVal(cty.Tuple([]cty.Type{cty.String, cty.Number})),
+			wantDiags: []string{
+				`:1,1-1: Missing required argument; The argument "bind" is required, but no definition was found.`,
+			},
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"addr": cty.TupleVal([]cty.Value{cty.StringVal("127.0.0.1"), cty.NumberIntVal(80)}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOneOfSpec(t *testing.T) {
+	attrs := []*AttrSpec{
+		{Name: "a", Type: cty.String},
+		{Name: "b", Type: cty.Number, Required: true},
+		{Name: "c", Type: cty.Bool},
+	}
+	obj := func(a, b, c cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{"a": a, "b": b, "c": c})
+	}
+	nullA, nullB, nullC := cty.NullVal(cty.String), cty.NullVal(cty.Number), cty.NullVal(cty.Bool)
+	unknown := cty.UnknownVal(cty.Object(map[string]cty.Type{
+		"a": cty.String,
+		"b": cty.Number,
+		"c": cty.Bool,
+	}))
+
+	tests := map[string]struct {
+		config    string
+		mode      OneOfMode
+		want      cty.Value
+		wantDiags []string
+	}{
+		"exactly one": {
+			config: "b = 2\n",
+			mode:   OneOfExactlyOne,
+			want:   obj(nullA, cty.NumberIntVal(2), nullC),
+		},
+		"exactly one with none": {
+			config:    "",
+			mode:      OneOfExactlyOne,
+			want:      unknown,
+			wantDiags: []string{`test.hcl:1,1-1: Missing required argument; Exactly one of "a", "b", or "c" must be set.`},
+		},
+		"exactly one with several": {
+			config: "a = \"x\"\nb = 2\nc = true\n",
+			mode:   OneOfExactlyOne,
+			want:   unknown,
+			wantDiags: []string{
+				`test.hcl:2,1-2: Conflicting arguments; Only one of "a", "b", or "c" may be set, but "a" was already set at test.hcl:1,1-2.`,
+				`test.hcl:3,1-2: Conflicting arguments; Only one of "a", "b", or "c" may be set, but "a" was already set at test.hcl:1,1-2.`,
+			},
+		},
+		"at most one with none": {
+			config: "",
+			mode:   OneOfAtMostOne,
+			want:   obj(nullA, nullB, nullC),
+		},
+		"at most one with several": {
+			config:    "a = \"x\"\nc = true\n",
+			mode:      OneOfAtMostOne,
+			want:      unknown,
+			wantDiags: []string{`test.hcl:2,1-2: Conflicting arguments; Only one of "a", "b", or "c" may be set, but "a" was already set at test.hcl:1,1-2.`},
+		},
+		"at least one with several": {
+			config: "a = \"x\"\nc = true\n",
+			mode:   OneOfAtLeastOne,
+			want:   obj(cty.StringVal("x"), nullB, cty.True),
+		},
+		"at least one with none": {
+			config:    "",
+			mode:      OneOfAtLeastOne,
+			want:      unknown,
+			wantDiags: []string{`test.hcl:1,1-1: Missing required argument; At least one of "a", "b", or "c" must be set.`},
+		},
+		"wrong type": {
+			config:    "b = \"x\"\n",
+			mode:      OneOfExactlyOne,
+			want:      obj(nullA, cty.UnknownVal(cty.Number), nullC),
+			wantDiags: []string{`test.hcl:1,5-8: Incorrect attribute value type; Inappropriate value for attribute "b": a number is required.`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			spec := &OneOfSpec{Attrs: attrs, Mode: test.mode}
+			got, diags := Decode(f.Body, spec, nil)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	spec := &OneOfSpec{Attrs: attrs}
+	wantSchema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}
+	if got := ImpliedSchema(spec); !reflect.DeepEqual(got, wantSchema) {
+		t.Errorf("wrong schema\ngot:  %#v\nwant: %#v", got, wantSchema)
+	}
+}
+
+func TestRequiredIfSpec(t *testing.T) {
+	spec := &RequiredIfSpec{
+		Wrapped: ObjectSpec{
+			"mode":      &AttrSpec{Name: "mode", Type: cty.String},
+			"cert_file": &AttrSpec{Name: "cert_file", Type: cty.String},
+		},
+		Name:   "cert_file",
+		When:   "mode",
+		Equals: cty.StringVal("tls"),
+	}
+	obj := func(mode, certFile cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{"mode": mode, "cert_file": certFile})
+	}
+	unknown := cty.UnknownVal(cty.Object(map[string]cty.Type{
+		"mode":      cty.String,
+		"cert_file": cty.String,
+	}))
+
+	tests := map[string]struct {
+		config    string
+		ctx       *hcl.EvalContext
+		want      cty.Value
+		wantDiags []string
+	}{
+		"condition holds and set": {
+			config: "mode = \"tls\"\ncert_file = \"cert.pem\"\n",
+			want:   obj(cty.StringVal("tls"), cty.StringVal("cert.pem")),
+		},
+		"condition holds and absent": {
+			config:    "mode = \"tls\"\n",
+			want:      unknown,
+			wantDiags: []string{`test.hcl:1,1-1: Missing required argument; The argument "cert_file" is required when "mode" is "tls", as set at test.hcl:1,1-5.`},
+		},
+		"condition doesn't hold": {
+			config: "mode = \"plain\"\n",
+			want:   obj(cty.StringVal("plain"), cty.NullVal(cty.String)),
+		},
+		"controlling attribute absent": {
+			config: "",
+			want:   obj(cty.NullVal(cty.String), cty.NullVal(cty.String)),
+		},
+		"controlling attribute unknown": {
+			config: "mode = var.mode\n",
+			ctx: &hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"var": cty.ObjectVal(map[string]cty.Value{
+						"mode": cty.UnknownVal(cty.String),
+					}),
+				},
+			},
+			want: obj(cty.UnknownVal(cty.String), cty.NullVal(cty.String)),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, spec, test.ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRequiredIfSpecDecodedWhen(t *testing.T) {
+	// The condition is checked against the value decoded by the wrapped
+	// spec, so it sees the default and the validation of the When attribute.
+	spec := &RequiredIfSpec{
+		Wrapped: ObjectSpec{
+			"mode": &DefaultSpec{
+				Primary: &EnumSpec{
+					Wrapped: &AttrSpec{Name: "mode", Type: cty.String},
+					Allowed: []string{"tls", "plain"},
+				},
+				Default: &LiteralSpec{Value: cty.StringVal("tls")},
+			},
+			"cert_file": &AttrSpec{Name: "cert_file", Type: cty.String},
+		},
+		Name:   "cert_file",
+		When:   "mode",
+		Equals: cty.StringVal("tls"),
+	}
+
+	tests := map[string]struct {
+		config    string
+		wantDiags []string
+	}{
+		"default holds": {
+			config:    "",
+			wantDiags: []string{`test.hcl:1,1-1: Missing required argument; The argument "cert_file" is required when "mode" is "tls".`},
+		},
+		"set value holds": {
+			config:    "mode = \"tls\"\n",
+			wantDiags: []string{`test.hcl:1,1-1: Missing required argument; The argument "cert_file" is required when "mode" is "tls", as set at test.hcl:1,1-5.`},
+		},
+		"invalid value": {
+			config:    "mode = \"bogus\"\n",
+			wantDiags: []string{`test.hcl:1,8-15: Unsupported value; The value "bogus" is not allowed here. The valid values are: "tls", "plain".`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			_, diags = Decode(f.Body, spec, nil)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBlockLabelPatternSpec(t *testing.T) {
+	spec := &BlockListSpec{
+		TypeName: "stage",
+		Nested: ObjectSpec{
+			"name": &BlockLabelSpec{Index: 0, Name: "name"},
+			"parts": &BlockLabelPatternSpec{
+				Index:   0,
+				Name:    "name",
+				Pattern: `(?P<env>[a-z]+)-(?P<tier>[a-z]+)(-(?P<suffix>\d+))?`,
+			},
+		},
+	}
+	parts := func(name string, env, tier, suffix cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal(name),
+			"parts": cty.ObjectVal(map[string]cty.Value{
+				"env":    env,
+				"tier":   tier,
+				"suffix": suffix,
+			}),
+		})
+	}
+
+	tests := map[string]struct {
+		config    string
+		want      cty.Value
+		wantDiags []string
+	}{
+		"match": {
+			config: "stage \"env-prod\" {}\nstage \"app-web-2\" {}\n",
+			want: cty.ListVal([]cty.Value{
+				parts("env-prod", cty.StringVal("env"), cty.StringVal("prod"), cty.NullVal(cty.String)),
+				parts("app-web-2", cty.StringVal("app"), cty.StringVal("web"), cty.StringVal("2")),
+			}),
+		},
+		"partial match": {
+			config:    "stage \"env-prod!\" {}\n",
+			wantDiags: []string{`test.hcl:1,7-18: Invalid block label; The name "env-prod!" does not match the required pattern (?P<env>[a-z]+)-(?P<tier>[a-z]+)(-(?P<suffix>\d+))?.`},
+		},
+		"no match": {
+			config:    "stage \"prod\" {}\n",
+			wantDiags: []string{`test.hcl:1,7-13: Invalid block label; The name "prod" does not match the required pattern (?P<env>[a-z]+)-(?P<tier>[a-z]+)(-(?P<suffix>\d+))?.`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, spec, nil)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if test.want != cty.NilVal && !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAttrSpecExpressionType(t *testing.T) {
+	config := `
+name    = "web"
+command = "echo ${var.message}"
+`
+	f, diags := hclsyntax.ParseConfig([]byte(config), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	spec := ObjectSpec{
+		"name": &AttrSpec{
+			Name: "name",
+			Type: cty.String,
+		},
+		"command": &AttrSpec{
+			Name: "command",
+			Type: customdecode.ExpressionType,
+		},
+		"deferred": &AttrSpec{
+			Name: "deferred",
+			Type: customdecode.ExpressionType,
+		},
+	}
+
+	// The deferred expression refers to a variable that isn't available
+	// during decoding, which is fine because it isn't evaluated.
+	got, diags := Decode(f.Body, spec, nil)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if got, want := got.GetAttr("name"), cty.StringVal("web"); !got.RawEquals(want) {
+		t.Errorf("wrong name\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := got.GetAttr("deferred"); !got.IsNull() {
+		t.Errorf("deferred is %#v; want null", got)
+	}
+
+	expr := customdecode.ExpressionFromVal(got.GetAttr("command"))
+	val, diags := expr.Value(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"message": cty.StringVal("hello"),
+			}),
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if want := cty.StringVal("echo hello"); !val.RawEquals(want) {
+		t.Errorf("wrong command result\ngot:  %#v\nwant: %#v", val, want)
+	}
+}
+
+func TestBodyAttrsSpec(t *testing.T) {
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantVars  int
+		wantDiags []string
+	}{
+		"typed": {
+			config: "a = 1\nb = \"2\"\n",
+			spec:   &BodyAttrsSpec{ElementType: cty.String},
+			want: cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("1"),
+				"b": cty.StringVal("2"),
+			}),
+		},
+		"inferred map": {
+			config: "a = 1\nb = \"2\"\n",
+			spec:   &BodyAttrsSpec{ElementType: cty.DynamicPseudoType},
+			want: cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("1"),
+				"b": cty.StringVal("2"),
+			}),
+		},
+		"inferred object": {
+			config: "a = 1\nb = [true]\n",
+			spec:   &BodyAttrsSpec{ElementType: cty.DynamicPseudoType},
+			want: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.NumberIntVal(1),
+				"b": cty.TupleVal([]cty.Value{cty.True}),
+			}),
+		},
+		"empty": {
+			config: "",
+			spec:   &BodyAttrsSpec{ElementType: cty.String},
+			want:   cty.MapValEmpty(cty.String),
+		},
+		"variables": {
+			config:   "a = foo\nb = bar\n",
+			spec:     &BodyAttrsSpec{ElementType: cty.DynamicPseudoType},
+			want:     cty.MapVal(map[string]cty.Value{"a": cty.StringVal("x"), "b": cty.StringVal("x")}),
+			wantVars: 2,
+		},
+		"wrong type": {
+			config: "a = [1]\n",
+			spec:   &BodyAttrsSpec{ElementType: cty.String},
+			want:   cty.MapVal(map[string]cty.Value{"a": cty.UnknownVal(cty.String)}),
+			wantDiags: []string{
+				`:1,5-8: Invalid attribute value; Invalid value for attribute "a": string required.`,
+			},
+		},
+		"block": {
+			config: "a = 1\nb {}\n",
+			spec:   &BodyAttrsSpec{ElementType: cty.String},
+			want:   cty.MapVal(map[string]cty.Value{"a": cty.StringVal("1")}),
+			wantDiags: []string{
+				`:2,1-2: Unexpected "b" block; Blocks are not allowed here.`,
+			},
+		},
+		"nested": {
+			config: "tags {\n  a = 1\n}\n",
+			spec: &BlockSpec{
+				TypeName: "tags",
+				Nested:   &BodyAttrsSpec{ElementType: cty.String},
+			},
+			want: cty.MapVal(map[string]cty.Value{"a": cty.StringVal("1")}),
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"foo": cty.StringVal("x"),
+			"bar": cty.StringVal("x"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			if got := len(Variables(f.Body, test.spec)); got != test.wantVars {
+				t.Errorf("wrong number of variables %d; want %d", got, test.wantVars)
+			}
+
+			got, diags := Decode(f.Body, test.spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVariantSpec(t *testing.T) {
+	spec := &VariantSpec{
+		Discriminator: "type",
+		Variants: map[string]Spec{
+			"http": ObjectSpec{
+				"url": &AttrSpec{Name: "url", Type: cty.String, Required: true},
+			},
+			"file": ObjectSpec{
+				"type": &AttrSpec{Name: "type", Type: cty.String},
+				"path": &AttrSpec{Name: "path", Type: cty.String, Required: true},
+			},
+		},
+	}
+	withDefault := &VariantSpec{
+		Discriminator: spec.Discriminator,
+		Variants:      spec.Variants,
+		Default: ObjectSpec{
+			"path": &AttrSpec{Name: "path", Type: cty.String},
+		},
+	}
+
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantVars  int
+		wantDiags []string
+	}{
+		"variant": {
+			config: "type = \"http\"\nurl = \"https://example.com/\"\n",
+			spec:   spec,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"url": cty.StringVal("https://example.com/"),
+			}),
+		},
+		"variant decoding discriminator": {
+			config: "type = \"file\"\npath = \"a.txt\"\n",
+			spec:   spec,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"type": cty.StringVal("file"),
+				"path": cty.StringVal("a.txt"),
+			}),
+		},
+		"variant with other variant's attribute": {
+			config: "type = \"http\"\nurl = \"https://example.com/\"\npath = \"a.txt\"\n",
+			spec:   spec,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"url": cty.StringVal("https://example.com/"),
+			}),
+			wantDiags: []string{
+				`:3,1-5: Unsupported argument; An argument named "path" is not expected here.`,
+			},
+		},
+		"default": {
+			config: "path = \"a.txt\"\n",
+			spec:   withDefault,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"path": cty.StringVal("a.txt"),
+			}),
+		},
+		"missing discriminator": {
+			config: "path = \"a.txt\"\n",
+			spec:   spec,
+			want:   cty.DynamicVal,
+			wantDiags: []string{
+				`:1,1-1: Missing required argument; The argument "type" is required, but no definition was found.`,
+			},
+		},
+		"unsupported value": {
+			config: "type = \"ftp\"\n",
+			spec:   spec,
+			want:   cty.DynamicVal,
+			wantDiags: []string{
+				`:1,8-13: Unsupported value; The value "ftp" is not allowed for "type". The valid values are: "file", "http".`,
+			},
+		},
+		"wrong type": {
+			config: "type = [\"http\"]\n",
+			spec:   spec,
+			want:   cty.DynamicVal,
+			wantDiags: []string{
+				`:1,8-16: Incorrect attribute value type; Inappropriate value for attribute "type": string required.`,
+			},
+		},
+		"unknown discriminator": {
+			config:   "type = unknown\nurl = \"https://example.com/\"\n",
+			spec:     spec,
+			want:     cty.DynamicVal,
+			wantVars: 2,
+		},
+		"variables of selected variant": {
+			config:   "type = \"http\"\nurl = foo\n",
+			spec:     spec,
+			want:     cty.ObjectVal(map[string]cty.Value{"url": cty.StringVal("x")}),
+			wantVars: 1,
+		},
+		"variables of default": {
+			config:   "path = foo\n",
+			spec:     withDefault,
+			want:     cty.ObjectVal(map[string]cty.Value{"path": cty.StringVal("x")}),
+			wantVars: 1,
+		},
+		"nested": {
+			config: "source \"a\" {\n  type = \"http\"\n  url  = \"https://example.com/\"\n}\n",
+			spec: &BlockSpec{
+				TypeName: "source",
+				Nested: &VariantSpec{
+					Discriminator: "type",
+					Variants: map[string]Spec{
+						"http": ObjectSpec{
+							"name": &BlockLabelSpec{Index: 0, Name: "name"},
+							"url":  &AttrSpec{Name: "url", Type: cty.String},
+						},
+					},
+				},
+			},
+			want: cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("a"),
+				"url":  cty.StringVal("https://example.com/"),
+			}),
+		},
+		"wrapped": {
+			config: "type = \"http\"\nurl = foo\n",
+			spec: &ValidateSpec{
+				Wrapped: spec,
+				Func: func(value cty.Value) hcl.Diagnostics {
+					return hcl.Diagnostics{
+						{
+							Severity: hcl.DiagError,
+							Summary:  "Validated",
+							Detail:   fmt.Sprintf("Got %#v.", value),
+						},
+					}
+				},
+			},
+			want: cty.ObjectVal(map[string]cty.Value{
+				"url": cty.StringVal("x"),
+			}),
+			wantVars: 1,
+			wantDiags: []string{
+				`:1,1-1: Validated; Got cty.ObjectVal(map[string]cty.Value{"url":cty.StringVal("x")}).`,
+			},
+		},
+		"wrapped in default": {
+			config: "path = \"a.txt\"\n",
+			spec: &DefaultSpec{
+				Primary: withDefault,
+				Default: &LiteralSpec{Value: cty.NullVal(cty.DynamicPseudoType)},
+			},
+			want: cty.ObjectVal(map[string]cty.Value{
+				"path": cty.StringVal("a.txt"),
+			}),
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"foo":     cty.StringVal("x"),
+			"unknown": cty.UnknownVal(cty.String),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			if got := len(Variables(f.Body, test.spec)); got != test.wantVars {
+				t.Errorf("wrong number of variables %d; want %d", got, test.wantVars)
+			}
+
+			got, diags := Decode(f.Body, test.spec, ctx)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBlockMapAndObjectSpecMultipleLabels(t *testing.T) {
+	nested := ObjectSpec{
+		"effect": &AttrSpec{Name: "effect", Type: cty.String},
+	}
+	mapSpec := &BlockMapSpec{
+		TypeName:   "policy",
+		LabelNames: []string{"group", "name"},
+		Nested:     nested,
+	}
+	objSpec := &BlockObjectSpec{
+		TypeName:   "policy",
+		LabelNames: []string{"group", "name"},
+		Nested:     nested,
+	}
+	effect := func(s string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{"effect": cty.StringVal(s)})
+	}
+
+	tests := map[string]struct {
+		config    string
+		spec      Spec
+		want      cty.Value
+		wantDiags []string
+	}{
+		"map": {
+			config: `
+policy "admins" "read" { effect = "allow" }
+policy "admins" "write" { effect = "allow" }
+policy "guests" "read" { effect = "deny" }
+`,
+			spec: mapSpec,
+			want: cty.MapVal(map[string]cty.Value{
+				"admins": cty.MapVal(map[string]cty.Value{
+					"read":  effect("allow"),
+					"write": effect("allow"),
+				}),
+				"guests": cty.MapVal(map[string]cty.Value{
+					"read": effect("deny"),
+				}),
+			}),
+		},
+		"object": {
+			config: `
+policy "admins" "read" { effect = "allow" }
+policy "guests" "read" { effect = "deny" }
+`,
+			spec: objSpec,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"admins": cty.ObjectVal(map[string]cty.Value{
+					"read": effect("allow"),
+				}),
+				"guests": cty.ObjectVal(map[string]cty.Value{
+					"read": effect("deny"),
+				}),
+			}),
+		},
+		"duplicate": {
+			config: `
+policy "admins" "read" { effect = "allow" }
+policy "admins" "write" { effect = "allow" }
+policy "admins" "read" { effect = "deny" }
+`,
+			spec: objSpec,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"admins": cty.ObjectVal(map[string]cty.Value{
+					"read":  effect("allow"),
+					"write": effect("allow"),
+				}),
+			}),
+			wantDiags: []string{
+				`test.hcl:4,1-23: Duplicate policy block; A block for policy "admins" "read" was already defined at test.hcl:2,1-23. The policy labels must be unique.`,
+			},
+		},
+		"missing label": {
+			config: `policy "admins" { effect = "allow" }`,
+			spec:   mapSpec,
+			want:   cty.MapValEmpty(nested.impliedType()),
+			wantDiags: []string{
+				`test.hcl:1,17-18: Missing name for policy; All policy blocks must have 2 labels (group, name).`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, diags := Decode(f.Body, test.spec, nil)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	// Bodies normally reject blocks with the wrong number of labels based on
+	// the schema, but the specs must also cope with a body that doesn't.
+	content := &hcl.BodyContent{
+		Blocks: hcl.Blocks{
+			{
+				Type:     "policy",
+				Labels:   []string{"admins"},
+				Body:     hcl.EmptyBody(),
+				DefRange: hcl.Range{Filename: "test.hcl", Start: hcl.InitialPos, End: hcl.InitialPos},
+			},
+		},
+	}
+	for _, spec := range []Spec{mapSpec, objSpec} {
+		_, diags := spec.decode(content, nil, nil)
+		if got, want := diags.Error(), `test.hcl:1,1-1: Missing name for policy; All policy blocks must have 2 labels (group, name).`; got != want {
+			t.Errorf("wrong diagnostics for %T\ngot:  %s\nwant: %s", spec, got, want)
+		}
+	}
+}
@@ -4,6 +4,8 @@
 package hcldec
 
 import (
+	"sort"
+
 	"github.com/hashicorp/hcl/v2"
 )
 
@@ -14,10 +16,21 @@ import (
 // This can be used to conditionally populate the variables in the EvalContext
 // passed to Decode, for applications where a static scope is insufficient.
 //
+// The result includes the references from every expression that Decode
+// would evaluate, including those in the bodies of nested blocks matched by
+// block specs such as BlockSpec, BlockListSpec and BlockMapSpec, at any
+// depth, and those in both alternatives of a DefaultSpec. The traversals are
+// returned in source order.
+//
 // If the given body is not compliant with the given schema, the result may
 // be incomplete, but that's assumed to be okay because the eventual call
 // to Decode will produce error diagnostics anyway.
 func Variables(body hcl.Body, spec Spec) []hcl.Traversal {
+	if bs, ok := spec.(bodySpec); ok {
+		return bs.variablesNeededBody(body)
+	}
+	spec, _ = bindBody(spec, body)
+
 	var vars []hcl.Traversal
 	schema := ImpliedSchema(spec)
 	content, _, _ := body.PartialContent(schema)
@@ -35,5 +48,16 @@ func Variables(body hcl.Body, spec Spec) []hcl.Traversal {
 	}
 	spec.visitSameBodyChildren(visitFn)
 
+	// The specs in an ObjectSpec are visited in an unpredictable order, so
+	// we'll sort the result to make it consistent. This also means that
+	// any error messages that result are in source order.
+	sort.SliceStable(vars, func(i, j int) bool {
+		ri, rj := vars[i].SourceRange(), vars[j].SourceRange()
+		if ri.Filename != rj.Filename {
+			return ri.Filename < rj.Filename
+		}
+		return ri.Start.Byte < rj.Start.Byte
+	})
+
 	return vars
 }
@@ -213,3 +213,101 @@ c {
 	}
 
 }
+
+func TestVariablesNestedBlocks(t *testing.T) {
+	config := `
+top = v_top
+svc "web" {
+  port = v_port
+  inner {
+    deep {
+      x = v_x
+      y = v_y
+    }
+  }
+  inner {
+    deep {
+      x = v_x2
+    }
+  }
+}
+svc "db" {
+  port = v_port2
+}
+tags {
+  a = v_tag
+}
+obj "o" {
+  z = v_obj
+}
+aob {
+  q = v_aob
+}
+set {
+  s = v_set
+}
+`
+	spec := ObjectSpec{
+		"top": &AttrSpec{Name: "top", Type: cty.String},
+		"svc": &BlockMapSpec{
+			TypeName:   "svc",
+			LabelNames: []string{"name"},
+			Nested: ObjectSpec{
+				"port": &ValidateSpec{
+					Wrapped: &AttrSpec{Name: "port", Type: cty.Number},
+					Func: func(cty.Value) hcl.Diagnostics {
+						return nil
+					},
+				},
+				"inner": &BlockListSpec{
+					TypeName: "inner",
+					Nested: &BlockSpec{
+						TypeName: "deep",
+						Nested: ObjectSpec{
+							"x": &DefaultSpec{
+								Primary: &AttrSpec{Name: "x", Type: cty.String},
+								Default: &AttrSpec{Name: "y", Type: cty.String},
+							},
+						},
+					},
+				},
+			},
+		},
+		"tags": &BlockAttrsSpec{TypeName: "tags", ElementType: cty.String},
+		"obj": &BlockObjectSpec{
+			TypeName:   "obj",
+			LabelNames: []string{"name"},
+			Nested:     &AttrSpec{Name: "z", Type: cty.String},
+		},
+		"aob": &AttrOrBlockSpec{
+			Name:   "aob",
+			Nested: &AttrSpec{Name: "q", Type: cty.String},
+		},
+		"set": &BlockSetSpec{
+			TypeName: "set",
+			Nested:   &AttrSpec{Name: "s", Type: cty.String},
+		},
+	}
+
+	file, diags := hclsyntax.ParseConfig([]byte(config), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	want := []string{
+		"v_top", "v_port", "v_x", "v_y", "v_x2", "v_port2",
+		"v_tag", "v_obj", "v_aob", "v_set",
+	}
+
+	// The result should be in source order regardless of the order in
+	// which the items of each ObjectSpec happen to be visited.
+	for i := 0; i < 10; i++ {
+		var got []string
+		for _, traversal := range Variables(file.Body, spec) {
+			got = append(got, traversal.RootName())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	}
+}
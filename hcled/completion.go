@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Completions describes the items that could be inserted into a body at a
+// particular position, as returned by BodyCompletions.
+type Completions struct {
+	Attributes []AttributeCompletion
+	Blocks     []BlockCompletion
+}
+
+// AttributeCompletion describes an attribute that could be inserted into
+// a body.
+type AttributeCompletion struct {
+	Name     string
+	Required bool
+
+	// Type is the type given for the attribute in the schema, which callers
+	// may use as a hint about what sort of value to insert. It is
+	// cty.NilType if the schema does not specify a type.
+	Type cty.Type
+}
+
+// BlockCompletion describes a block type that could be inserted into a body.
+type BlockCompletion struct {
+	Type       string
+	LabelNames []string
+}
+
+// BodyCompletions returns the attributes and block types from the given
+// schema that could be inserted into the given body at the given position,
+// for use in offering completions in a text editor.
+//
+// The body is decoded with PartialContent, so it may contain items that are
+// not in the schema and it may be incomplete in other ways, as is common
+// while a file is being edited, as long as it can be parsed. Attributes that
+// are already set in the body are not included in the result, except for
+// one whose definition contains the given position, since that is
+// presumably the attribute that is currently being written. Block types are
+// always included, since a schema does not constrain how many blocks of each
+// type a body may contain.
+//
+// The candidates are returned in the order they are declared in the schema.
+func BodyCompletions(body hcl.Body, schema *hcl.BodySchema, pos hcl.Pos) Completions {
+	var ret Completions
+	content, _, _ := body.PartialContent(schema)
+
+	seen := make(map[string]struct{})
+	for _, attrS := range schema.Attributes {
+		if _, exists := seen[attrS.Name]; exists {
+			continue
+		}
+		seen[attrS.Name] = struct{}{}
+		if content != nil {
+			if attr, exists := content.Attributes[attrS.Name]; exists && !attr.Range.ContainsPos(pos) {
+				continue
+			}
+		}
+		ret.Attributes = append(ret.Attributes, AttributeCompletion{
+			Name:     attrS.Name,
+			Required: attrS.Required,
+			Type:     attrS.Type,
+		})
+	}
+
+	seen = make(map[string]struct{})
+	for _, blockS := range schema.Blocks {
+		if _, exists := seen[blockS.Type]; exists {
+			continue
+		}
+		seen[blockS.Type] = struct{}{}
+		ret.Blocks = append(ret.Blocks, BlockCompletion{
+			Type:       blockS.Type,
+			LabelNames: blockS.LabelNames,
+		})
+	}
+
+	return ret
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBodyCompletions(t *testing.T) {
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name", Required: true, Type: cty.String},
+			{Name: "count", Type: cty.Number},
+			{Name: "tags"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "network", LabelNames: []string{"name"}},
+			{Type: "lifecycle"},
+			{Type: "network", LabelNames: []string{"name"}},
+		},
+	}
+	src := "name = \"a\"\ncount = 2\nnetwork \"x\" {}\nother = true\n"
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	wantBlocks := []BlockCompletion{
+		{Type: "network", LabelNames: []string{"name"}},
+		{Type: "lifecycle"},
+	}
+
+	tests := map[string]struct {
+		pos  hcl.Pos
+		want Completions
+	}{
+		"end of file": {
+			hcl.Pos{Line: 5, Column: 1, Byte: len(src)},
+			Completions{
+				Attributes: []AttributeCompletion{
+					{Name: "tags"},
+				},
+				Blocks: wantBlocks,
+			},
+		},
+		"within count": {
+			hcl.Pos{Line: 2, Column: 3, Byte: 13},
+			Completions{
+				Attributes: []AttributeCompletion{
+					{Name: "count", Type: cty.Number},
+					{Name: "tags"},
+				},
+				Blocks: wantBlocks,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := BodyCompletions(file.Body, schema, test.pos)
+			if diff := cmp.Diff(test.want, got, cmp.Comparer(cty.Type.Equals)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
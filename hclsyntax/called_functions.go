@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ValueWithCalledFunctions evaluates the given expression in the given
+// context, as with its Value method, then also returns the names of the
+// functions that were called during that evaluation, in lexical order and
+// without duplicates.
+//
+// Unlike a static analysis of the expression, the result includes only the
+// functions whose calls were actually evaluated, including any calls in each
+// iteration of a 'for' expression, but not calls made only in the result of
+// a conditional expression that was not selected by a known condition, even
+// though both results are evaluated to determine the result type. If the
+// condition is unknown then the calls in both results are included. A
+// function counts as called only if its arguments were evaluated and
+// converted to the parameter types without errors, even if the function
+// itself then returned an error.
+//
+// The function calls are observed using an hcl.EvalTracer, and so any
+// tracer already attached to the given context continues to be notified as
+// usual.
+func ValueWithCalledFunctions(expr Expression, ctx *hcl.EvalContext) (cty.Value, []string, hcl.Diagnostics) {
+	tracer := &calledFunctionsTracer{
+		next:   ctx.Tracer(),
+		frames: []calledFunctionsFrame{{}},
+	}
+	val, diags := expr.Value(ctx.WithTracer(tracer))
+
+	seen := map[string]bool{}
+	var names []string
+	for _, child := range tracer.frames[0].children {
+		for _, name := range child.names {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return val, names, diags
+}
+
+type calledFunctionsTracer struct {
+	next hcl.EvalTracer
+
+	// frames has an element for each expression that is being evaluated,
+	// with the outermost first, along with an initial element that
+	// collects the result for the expression as a whole.
+	frames []calledFunctionsFrame
+}
+
+// calledFunctionsFrame records the results of the nested expressions that
+// were evaluated while evaluating a particular expression.
+type calledFunctionsFrame struct {
+	children []calledFunctionsResult
+}
+
+// calledFunctionsResult records the result of evaluating an expression,
+// along with the names of the functions called in doing so.
+type calledFunctionsResult struct {
+	expr  hcl.Expression
+	val   cty.Value
+	names []string
+}
+
+var _ hcl.EvalTracer = (*calledFunctionsTracer)(nil)
+
+func (t *calledFunctionsTracer) EnterExpression(expr hcl.Expression, ctx *hcl.EvalContext) {
+	t.frames = append(t.frames, calledFunctionsFrame{})
+	if t.next != nil {
+		t.next.EnterExpression(expr, ctx)
+	}
+}
+
+func (t *calledFunctionsTracer) LeaveExpression(expr hcl.Expression, ctx *hcl.EvalContext, val cty.Value, diags hcl.Diagnostics) {
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	var names []string
+	switch e := expr.(type) {
+	case *ConditionalExpr:
+		for _, child := range frame.children {
+			switch child.expr {
+			case e.TrueResult:
+				if conditionSelects(frame.children, e.Condition, false) {
+					continue
+				}
+			case e.FalseResult:
+				if conditionSelects(frame.children, e.Condition, true) {
+					continue
+				}
+			}
+			names = append(names, child.names...)
+		}
+	case *FunctionCallExpr:
+		for _, child := range frame.children {
+			names = append(names, child.names...)
+		}
+		if functionWasCalled(e, diags) {
+			names = append(names, e.Name)
+		}
+	default:
+		for _, child := range frame.children {
+			names = append(names, child.names...)
+		}
+	}
+
+	parent := &t.frames[len(t.frames)-1]
+	parent.children = append(parent.children, calledFunctionsResult{
+		expr:  expr,
+		val:   val,
+		names: names,
+	})
+
+	if t.next != nil {
+		t.next.LeaveExpression(expr, ctx, val, diags)
+	}
+}
+
+// conditionSelects returns true if the given results include a result for
+// the given condition expression that is known to be the given boolean
+// value.
+func conditionSelects(results []calledFunctionsResult, cond Expression, want bool) bool {
+	for _, result := range results {
+		if result.expr != cond {
+			continue
+		}
+		val, _ := result.val.UnmarkDeep()
+		val, err := convert.Convert(val, cty.Bool)
+		if err != nil || val.IsNull() || !val.IsKnown() {
+			return false
+		}
+		return val.True() == want
+	}
+	return false
+}
+
+// functionWasCalled returns true if the given diagnostics, returned from
+// evaluating the given function call, show that the function's
+// implementation was called.
+func functionWasCalled(e *FunctionCallExpr, diags hcl.Diagnostics) bool {
+	if !diags.HasErrors() {
+		return true
+	}
+	for _, diag := range diags {
+		extra, ok := hcl.DiagnosticExtra[FunctionCallDiagExtra](diag)
+		if ok && extra.CalledFunctionName() == e.Name && extra.FunctionCallError() != nil {
+			return true
+		}
+	}
+	return false
+}
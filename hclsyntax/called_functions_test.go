@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestValueWithCalledFunctions(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"yes":     cty.True,
+			"unknown": cty.UnknownVal(cty.Bool),
+			"names":   cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			"empty":   cty.ListValEmpty(cty.String),
+		},
+		Functions: map[string]function.Function{
+			"upper":  stdlib.UpperFunc,
+			"lower":  stdlib.LowerFunc,
+			"length": stdlib.LengthFunc,
+			"concat": stdlib.ConcatFunc,
+			"fail": function.New(&function.Spec{
+				Type: function.StaticReturnType(cty.String),
+				Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+					return cty.UnknownVal(retType), function.NewArgErrorf(0, "failed")
+				},
+			}),
+		},
+	}
+
+	tests := map[string]struct {
+		src       string
+		want      []string
+		wantError bool
+	}{
+		"no calls": {
+			src:  `"hello"`,
+			want: nil,
+		},
+		"nested calls": {
+			src:  `upper(lower("A"))`,
+			want: []string{"lower", "upper"},
+		},
+		"duplicate calls": {
+			src:  `upper("a") == upper("b")`,
+			want: []string{"upper"},
+		},
+		"known true condition": {
+			src:  `yes ? upper("a") : lower("a")`,
+			want: []string{"upper"},
+		},
+		"known false condition": {
+			src:  `!yes ? upper("a") : lower("a")`,
+			want: []string{"lower"},
+		},
+		"calls in condition": {
+			src:  `length(names) > 1 ? "many" : lower("A")`,
+			want: []string{"length"},
+		},
+		"unknown condition": {
+			src:  `unknown ? upper("a") : lower("a")`,
+			want: []string{"lower", "upper"},
+		},
+		"for expression body": {
+			src:  `[for n in names : upper(n)]`,
+			want: []string{"upper"},
+		},
+		"for expression with no elements": {
+			src:  `[for n in empty : upper(n)]`,
+			want: nil,
+		},
+		"nested conditional in for expression": {
+			src:  `[for n in names : n == "a" ? upper(n) : yes ? n : lower(n)]`,
+			want: []string{"upper"},
+		},
+		"function error": {
+			src:       `fail()`,
+			want:      []string{"fail"},
+			wantError: true,
+		},
+		"argument error": {
+			src:       `upper(nope)`,
+			want:      nil,
+			wantError: true,
+		},
+		"undefined function": {
+			src:       `upper(nope("a"))`,
+			want:      nil,
+			wantError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+
+			wantVal, wantDiags := expr.Value(ctx)
+			gotVal, got, gotDiags := ValueWithCalledFunctions(expr, ctx)
+			if !gotVal.RawEquals(wantVal) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", gotVal, wantVal)
+			}
+			if len(gotDiags) != len(wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", gotDiags.Error(), wantDiags.Error())
+			}
+			if gotDiags.HasErrors() != test.wantError {
+				t.Errorf("wrong error result %t; want %t", gotDiags.HasErrors(), test.wantError)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong functions\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValueWithCalledFunctionsExistingTracer(t *testing.T) {
+	src := `upper("a")`
+	expr, diags := ParseExpression([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	tracer := &testTracer{src: src}
+	ctx := (&hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+		},
+	}).WithTracer(tracer)
+
+	_, got, diags := ValueWithCalledFunctions(expr, ctx)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if diff := cmp.Diff([]string{"upper"}, got); diff != "" {
+		t.Errorf("wrong functions\n%s", diff)
+	}
+	if len(tracer.events) == 0 {
+		t.Errorf("existing tracer was not notified")
+	}
+}
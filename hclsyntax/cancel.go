@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValueContext is like calling Value on the given expression, but supports
+// cooperative cancellation using the given context.Context.
+//
+// Before evaluating each node of the expression, the evaluator checks
+// whether the context is done, and if so produces an error diagnostic
+// rather than continuing. A function call that is already in progress is
+// not interrupted, but no further nodes are evaluated once it returns. The
+// result includes at most one cancellation diagnostic.
+//
+// The context is attached to a child of the given EvalContext using
+// hcl.EvalContext.WithContext, so any child contexts created during
+// evaluation also carry it.
+func ValueContext(goCtx context.Context, expr Expression, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if ctx == nil {
+		// Evaluating without an EvalContext cannot call any functions, so
+		// we only need to check once before we begin.
+		if diags := checkCancelled(goCtx, expr); diags != nil {
+			return cty.DynamicVal, diags
+		}
+		return expr.Value(nil)
+	}
+
+	val, diags := expr.Value(ctx.WithContext(goCtx))
+
+	// Once cancelled, every remaining node reports its own diagnostic, but
+	// they all describe the same problem so we return only the first.
+	var ret hcl.Diagnostics
+	seenCancelled := false
+	for _, diag := range diags {
+		if _, ok := diag.Extra.(evalCancelledDiagExtra); ok {
+			if seenCancelled {
+				continue
+			}
+			seenCancelled = true
+		}
+		ret = append(ret, diag)
+	}
+	return val, ret
+}
+
+// evalCancelledDiagExtra is the Extra value of the diagnostics returned by
+// checkCancelled, so that ValueContext can recognize them.
+type evalCancelledDiagExtra struct{}
+
+// checkEvalCancelled returns an error diagnostic if the given EvalContext
+// carries a context.Context that is done, and nil otherwise. Each
+// expression's Value method calls it before doing any other work.
+func checkEvalCancelled(ctx *hcl.EvalContext, expr Expression) hcl.Diagnostics {
+	if ctx == nil {
+		return nil
+	}
+	return checkCancelled(ctx.Context(), expr)
+}
+
+func checkCancelled(goCtx context.Context, expr Expression) hcl.Diagnostics {
+	if goCtx == nil {
+		return nil
+	}
+	err := goCtx.Err()
+	if err == nil {
+		return nil
+	}
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Evaluation cancelled",
+			Detail:   fmt.Sprintf("Evaluation stopped before completing this expression: %s.", err),
+			Subject:  expr.Range().Ptr(),
+			Extra:    evalCancelledDiagExtra{},
+		},
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestValueContext(t *testing.T) {
+	goCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"items": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+		},
+		Functions: map[string]function.Function{
+			// slow stands in for a function that takes long enough that
+			// the caller gives up while it is running.
+			"slow": function.New(&function.Spec{
+				Params: []function.Parameter{{Name: "v", Type: cty.String}},
+				Type:   function.StaticReturnType(cty.String),
+				Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+					calls++
+					cancel()
+					return args[0], nil
+				},
+			}),
+		},
+	}
+
+	expr, diags := ParseExpression([]byte(`[for v in items : slow(v)]`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	_, diags = ValueContext(goCtx, expr, ctx)
+	if calls != 1 {
+		t.Errorf("function was called %d times; want 1", calls)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Evaluation cancelled"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diags[0].Detail, "Evaluation stopped before completing this expression: context canceled."; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// The given EvalContext must not be modified.
+	if ctx.Context() != nil {
+		t.Errorf("ValueContext attached its context.Context to the caller's EvalContext")
+	}
+}
+
+func TestValueContextNotCancelled(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`upper("a") == "A" ? 1 : 2`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"upper": function.New(&function.Spec{
+				Params: []function.Parameter{{Name: "v", Type: cty.String}},
+				Type:   function.StaticReturnType(cty.String),
+				Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+					return cty.StringVal("A"), nil
+				},
+			}),
+		},
+	}
+
+	got, diags := ValueContext(context.Background(), expr, ctx)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if want := cty.NumberIntVal(1); !got.RawEquals(want) {
+		t.Errorf("wrong result %#v; want %#v", got, want)
+	}
+}
+
+func TestValueContextNilEvalContext(t *testing.T) {
+	goCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expr, diags := ParseExpression([]byte(`"hello"`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	_, diags = ValueContext(goCtx, expr, nil)
+	if len(diags) != 1 || diags[0].Summary != "Evaluation cancelled" {
+		t.Fatalf("wrong diagnostics: %s", diags.Error())
+	}
+}
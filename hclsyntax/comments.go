@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// LeadingComments returns the text of the comments immediately preceding
+// each attribute and block in the given body and in the bodies of all of its
+// nested blocks, such as for use as documentation of those items. The given
+// tokens must be those the body was parsed from, as returned by
+// ParseConfigReturningTokens.
+//
+// The result is keyed by the Range of each attribute and the DefRange of each
+// block, as used in hcl.Attribute and hcl.Block, and includes only the items
+// that have leading comments.
+//
+// Leading comments are those on whole lines directly above an item, with no
+// blank lines between, along with any comments on the same line before it.
+// A comment that follows another item on the same line is not included. The
+// text has the comment markers removed, along with the single space that
+// conventionally follows # or //, and the text of consecutive comments is
+// joined with newlines.
+func LeadingComments(body *Body, tokens Tokens) map[hcl.Range]string {
+	ret := make(map[hcl.Range]string)
+	collectLeadingComments(body, tokens, ret)
+	return ret
+}
+
+func collectLeadingComments(body *Body, tokens Tokens, into map[hcl.Range]string) {
+	for _, attr := range body.Attributes {
+		if text, ok := leadingCommentText(tokens, attr.SrcRange.Start.Byte); ok {
+			into[attr.SrcRange] = text
+		}
+	}
+	for _, block := range body.Blocks {
+		if text, ok := leadingCommentText(tokens, block.TypeRange.Start.Byte); ok {
+			into[block.DefRange()] = text
+		}
+		collectLeadingComments(block.Body, tokens, into)
+	}
+}
+
+// leadingCommentText returns the text of the leading comments of the item
+// whose first token starts at the given byte offset.
+func leadingCommentText(tokens Tokens, startByte int) (string, bool) {
+	i := sort.Search(len(tokens), func(i int) bool {
+		return tokens[i].Range.Start.Byte >= startByte
+	})
+
+	// We walk backwards from the item through the comments, allowing the
+	// newline that ends a line containing a block comment, since only the
+	// single-line comments include their own newlines.
+	first := i
+	for j := i - 1; j >= 0; j-- {
+		tok := tokens[j]
+		if tok.Type == TokenComment {
+			first = j
+			continue
+		}
+		if tok.Type == TokenNewline && j > 0 && tokens[j-1].Type == TokenComment && !commentEndsLine(tokens[j-1]) {
+			continue
+		}
+		break
+	}
+
+	// Any comments at the start of the sequence that don't begin a line
+	// belong to whatever precedes them on that line.
+	for first < i && !tokenStartsLine(tokens, first) {
+		first++
+	}
+
+	var lines []string
+	for _, tok := range tokens[first:i] {
+		if tok.Type == TokenComment {
+			lines = append(lines, commentText(tok.Bytes))
+		}
+	}
+	if len(lines) == 0 {
+		return "", false
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+func tokenStartsLine(tokens Tokens, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := tokens[i-1]
+	return prev.Type == TokenNewline || (prev.Type == TokenComment && commentEndsLine(prev))
+}
+
+func commentEndsLine(tok Token) bool {
+	return bytes.HasSuffix(tok.Bytes, []byte{'\n'})
+}
+
+// commentText returns the text of the given comment without its markers.
+func commentText(src []byte) string {
+	s := strings.TrimRight(string(src), "\r\n")
+	switch {
+	case strings.HasPrefix(s, "#"):
+		return strings.TrimPrefix(s[1:], " ")
+	case strings.HasPrefix(s, "//"):
+		return strings.TrimPrefix(s[2:], " ")
+	case strings.HasPrefix(s, "/*"):
+		// The extra asterisk of a documentation-style /** comment is also
+		// a marker.
+		s = strings.TrimSuffix(s[2:], "*/")
+		return strings.TrimSpace(strings.TrimPrefix(s, "*"))
+	default:
+		return s
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestLeadingComments(t *testing.T) {
+	src := `# The name of the service.
+name = "web" # not a leading comment
+
+// Ignored, because of the blank line.
+
+# Describes a listener.
+# Several may be given.
+listener "http" {
+  /* The port to listen on. */
+  port = 80
+
+  /*
+    Whether to enable TLS.
+  */
+  tls = false
+  /** Inline. */ timeout = 5
+  retries = 3 # belongs to retries, not backoff
+  backoff = 2
+}
+
+count = 1
+`
+	f, tokens, diags := ParseConfigReturningTokens([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	body := f.Body.(*Body)
+	listener := body.Blocks[0]
+
+	got := LeadingComments(body, tokens)
+	want := map[hcl.Range]string{
+		body.Attributes["name"].SrcRange:             "The name of the service.",
+		listener.DefRange():                          "Describes a listener.\nSeveral may be given.",
+		listener.Body.Attributes["port"].SrcRange:    "The port to listen on.",
+		listener.Body.Attributes["tls"].SrcRange:     "Whether to enable TLS.",
+		listener.Body.Attributes["timeout"].SrcRange: "Inline.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
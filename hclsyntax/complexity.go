@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Complexity summarizes the size and shape of an AST, as returned by
+// MeasureComplexity.
+type Complexity struct {
+	// Nodes is the total number of AST nodes, including the given node
+	// itself.
+	Nodes int
+
+	// FunctionCalls is the number of function call expressions.
+	FunctionCalls int
+
+	// Traversals is the number of scope and relative traversal expressions.
+	Traversals int
+
+	// ForExprs is the number of for expressions.
+	ForExprs int
+
+	// MaxDepth is the number of nodes on the longest path from the given
+	// node to a leaf, including both ends.
+	MaxDepth int
+}
+
+// MeasureComplexity walks the AST beginning at the given node, typically an
+// Expression, and counts the nodes within it.
+//
+// This is intended for applications that evaluate expressions from
+// untrusted sources and wish to reject those that seem too expensive, such
+// as expressions that call more than a certain number of functions, before
+// evaluating them. The counts are purely syntactic and so don't account
+// for the cost of each function or for the number of times the body of a
+// for expression is evaluated.
+func MeasureComplexity(node Node) Complexity {
+	w := &complexityWalker{}
+	Walk(node, w)
+	return w.result
+}
+
+type complexityWalker struct {
+	result Complexity
+	depth  int
+}
+
+func (w *complexityWalker) Enter(n Node) hcl.Diagnostics {
+	if _, synthetic := n.(ChildScope); synthetic {
+		// ChildScope nodes are synthesized during the walk, and so don't
+		// really exist in the AST.
+		return nil
+	}
+
+	w.depth++
+	if w.depth > w.result.MaxDepth {
+		w.result.MaxDepth = w.depth
+	}
+	w.result.Nodes++
+
+	switch n.(type) {
+	case *FunctionCallExpr:
+		w.result.FunctionCalls++
+	case *ScopeTraversalExpr, *RelativeTraversalExpr:
+		w.result.Traversals++
+	case *ForExpr:
+		w.result.ForExprs++
+	}
+	return nil
+}
+
+func (w *complexityWalker) Exit(n Node) hcl.Diagnostics {
+	if _, synthetic := n.(ChildScope); !synthetic {
+		w.depth--
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestMeasureComplexity(t *testing.T) {
+	tests := []struct {
+		src  string
+		want Complexity
+	}{
+		{
+			`1`,
+			Complexity{Nodes: 1, MaxDepth: 1},
+		},
+		{
+			`a.b`,
+			Complexity{Nodes: 1, Traversals: 1, MaxDepth: 1},
+		},
+		{
+			`upper(a) + 1`,
+			// BinaryOpExpr, FunctionCallExpr, ScopeTraversalExpr, LiteralValueExpr
+			Complexity{Nodes: 4, FunctionCalls: 1, Traversals: 1, MaxDepth: 3},
+		},
+		{
+			`f(g(h(x)))`,
+			Complexity{Nodes: 4, FunctionCalls: 3, Traversals: 1, MaxDepth: 4},
+		},
+		{
+			`[for v in list : upper(v)]`,
+			// ForExpr, list, FunctionCallExpr, v
+			Complexity{Nodes: 4, FunctionCalls: 1, Traversals: 2, ForExprs: 1, MaxDepth: 3},
+		},
+		{
+			`foo(a)[0].b`,
+			// RelativeTraversalExpr, FunctionCallExpr, ScopeTraversalExpr
+			Complexity{Nodes: 3, FunctionCalls: 1, Traversals: 2, MaxDepth: 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			got := MeasureComplexity(expr)
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// DeprecatedSyntaxWarnings walks the AST beginning at the given node,
+// typically the Body of a parsed file, and returns a warning diagnostic for
+// each use of syntax that is still accepted but that modern style avoids:
+//
+//   - A string template that consists only of a single interpolation
+//     sequence, such as "${var.foo}", which has the same value as the
+//     interpolated expression written alone, as var.foo, or in parentheses
+//     as (var.foo) when it is an object key.
+//   - An attribute-only splat expression, such as foo.*.bar, which is a
+//     more limited form of the full splat expression foo[*].bar.
+//
+// The given source code must be that which the node was parsed from, such as
+// the Bytes field of the hcl.File it belongs to. It is used to tell the two
+// forms of splat expression apart and to suggest a replacement for each
+// deprecated expression.
+//
+// This is a purely syntactic analysis for tools that enforce a particular
+// style, and the parser itself never reports these warnings.
+func DeprecatedSyntaxWarnings(node Node, src []byte) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	objectKeys := make(map[*TemplateWrapExpr]bool)
+	VisitAll(node, func(n Node) hcl.Diagnostics {
+		switch e := n.(type) {
+		case *ObjectConsKeyExpr:
+			// An object key written without the quotes would be taken
+			// literally if it's a single identifier, so we'll suggest
+			// parentheses instead. Parents are visited before their
+			// children, so this is recorded before we visit the template.
+			if wrap, ok := e.Wrapped.(*TemplateWrapExpr); ok {
+				objectKeys[wrap] = true
+			}
+		case *TemplateWrapExpr:
+			detail := "A string template consisting only of a single interpolation sequence has the same value as the interpolated expression, which can be written without the quotes and interpolation markers."
+			if wrapped, ok := sourceForRange(e.Wrapped.Range(), src); ok {
+				if objectKeys[e] {
+					wrapped = "(" + wrapped + ")"
+				}
+				detail = fmt.Sprintf("%s Write this as %s.", detail, wrapped)
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Interpolation-only expression",
+				Detail:   detail,
+				Subject:  e.SrcRange.Ptr(),
+			})
+		case *SplatExpr:
+			marker, ok := sourceForRange(e.MarkerRange, src)
+			if !ok || marker == "" || marker[0] != '.' {
+				break
+			}
+			detail := "The attribute-only splat operator \".*\" is a legacy form of the full splat operator \"[*]\", which supports any traversal of each element."
+			before, okBefore := sourceForRange(hcl.Range{Start: e.SrcRange.Start, End: e.MarkerRange.Start}, src)
+			after, okAfter := sourceForRange(hcl.Range{Start: e.MarkerRange.End, End: e.SrcRange.End}, src)
+			if okBefore && okAfter {
+				detail = fmt.Sprintf("%s Write this as %s[*]%s.", detail, before, after)
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Legacy attribute-only splat expression",
+				Detail:   detail,
+				Subject:  e.MarkerRange.Ptr(),
+				Context:  e.SrcRange.Ptr(),
+			})
+		}
+		return nil
+	})
+	return diags
+}
+
+// sourceForRange returns the source code within the given range, or false
+// if the range is not within the given source.
+func sourceForRange(rng hcl.Range, src []byte) (string, bool) {
+	if rng.Start.Byte < 0 || rng.Start.Byte > rng.End.Byte || rng.End.Byte > len(src) {
+		return "", false
+	}
+	return string(src[rng.Start.Byte:rng.End.Byte]), true
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDeprecatedSyntaxWarnings(t *testing.T) {
+	const interpDetail = "A string template consisting only of a single interpolation sequence has the same value as the interpolated expression, which can be written without the quotes and interpolation markers."
+	const splatDetail = "The attribute-only splat operator \".*\" is a legacy form of the full splat operator \"[*]\", which supports any traversal of each element."
+
+	tests := map[string]struct {
+		src  string
+		want []string
+	}{
+		"interpolation only": {
+			`a = "${var.x}"`,
+			[]string{
+				`test.hcl:1,5-15: Interpolation-only expression; ` + interpDetail + ` Write this as var.x.`,
+			},
+		},
+		"interpolation with spaces": {
+			`a = "${ var.x }"`,
+			[]string{
+				`test.hcl:1,5-17: Interpolation-only expression; ` + interpDetail + ` Write this as var.x.`,
+			},
+		},
+		"with literal text": {
+			`a = "prefix-${var.x}"`,
+			nil,
+		},
+		"escaped": {
+			`a = "$${var.x}"`,
+			nil,
+		},
+		"nested": {
+			`a = "${upper("${var.x}")}"`,
+			[]string{
+				`test.hcl:1,5-27: Interpolation-only expression; ` + interpDetail + ` Write this as upper("${var.x}").`,
+				`test.hcl:1,14-24: Interpolation-only expression; ` + interpDetail + ` Write this as var.x.`,
+			},
+		},
+		"in nested block": {
+			"b {\n  c = [\"${x}\"]\n}\n",
+			[]string{
+				`test.hcl:2,8-14: Interpolation-only expression; ` + interpDetail + ` Write this as x.`,
+			},
+		},
+		"object key": {
+			`a = { "${k}" = "${v}" }`,
+			[]string{
+				`test.hcl:1,7-13: Interpolation-only expression; ` + interpDetail + ` Write this as (k).`,
+				`test.hcl:1,16-22: Interpolation-only expression; ` + interpDetail + ` Write this as v.`,
+			},
+		},
+		"template directive": {
+			`a = "%{ if x }${y}%{ endif }"`,
+			nil,
+		},
+		"heredoc": {
+			"a = <<EOT\n${x}\nEOT\n",
+			nil,
+		},
+		"attribute-only splat": {
+			`a = foo.*.bar`,
+			[]string{
+				`test.hcl:1,8-10: Legacy attribute-only splat expression; ` + splatDetail + ` Write this as foo[*].bar.`,
+			},
+		},
+		"full splat": {
+			`a = foo[*].bar`,
+			nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+
+			var got []string
+			for _, diag := range DeprecatedSyntaxWarnings(f.Body.(*Body), f.Bytes) {
+				if diag.Severity != hcl.DiagWarning {
+					t.Errorf("diagnostic is not a warning: %s", diag.Error())
+				}
+				got = append(got, diag.Error())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDeprecatedSyntaxWarningsNoSource(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`"${var.x}"`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+	got := DeprecatedSyntaxWarnings(expr, nil)
+	if len(got) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1", len(got))
+	}
+	want := "A string template consisting only of a single interpolation sequence has the same value as the interpolated expression, which can be written without the quotes and interpolation markers."
+	if got[0].Detail != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got[0].Detail, want)
+	}
+}
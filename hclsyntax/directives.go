@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apparentlymart/go-textseg/v15/textseg"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// featureDirectives are the feature names recognized in an "hcl:feature"
+// directive, each with a function that enables the feature in the given
+// options.
+var featureDirectives = map[string]func(opts *ParseOptions){
+	"colon_assignment": func(opts *ParseOptions) {
+		if opts.ColonAssignment == ColonAssignmentError {
+			opts.ColonAssignment = ColonAssignmentAllowed
+		}
+	},
+	"exact_integers":       func(opts *ParseOptions) { opts.ExactIntegers = true },
+	"raw_strings":          func(opts *ParseOptions) { opts.RawStrings = true },
+	"splat_null_is_error":  func(opts *ParseOptions) { opts.SplatNullIsError = true },
+	"unquoted_line_values": func(opts *ParseOptions) { opts.UnquotedLineValues = true },
+	"warn_unquoted_labels": func(opts *ParseOptions) { opts.WarnUnquotedLabels = true },
+}
+
+// applyFeatureDirectives returns a copy of the given options with the
+// features enabled by any directive comments at the start of the given tokens,
+// as described for ParseOptions.FeatureDirectives, along with warnings for any
+// directives that are not recognized.
+func applyFeatureDirectives(tokens Tokens, opts ParseOptions) (ParseOptions, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	for _, tok := range tokens {
+		if tok.Type == TokenNewline {
+			continue
+		}
+		if tok.Type != TokenComment {
+			break
+		}
+
+		text := tok.Bytes
+		switch {
+		case bytes.HasPrefix(text, []byte{'#'}):
+			text = text[1:]
+		case bytes.HasPrefix(text, []byte("//")):
+			text = text[2:]
+		default:
+			// Block comments cannot contain directives.
+			continue
+		}
+		words := directiveWords(tok, text)
+		if len(words) == 0 || !bytes.HasPrefix(words[0].text, []byte("hcl:")) {
+			continue
+		}
+
+		if name := string(words[0].text); name != "hcl:feature" {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Unknown parser directive",
+				Detail:   fmt.Sprintf("The directive %q is not recognized and will be ignored. The only supported directive is \"hcl:feature\".", name),
+				Subject:  words[0].rng.Ptr(),
+			})
+			continue
+		}
+		if len(words) == 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Invalid parser directive",
+				Detail:   "An \"hcl:feature\" directive must be followed by the names of one or more features to enable.",
+				Subject:  words[0].rng.Ptr(),
+			})
+			continue
+		}
+		for _, word := range words[1:] {
+			enable, ok := featureDirectives[string(word.text)]
+			if !ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Unknown parser feature",
+					Detail:   fmt.Sprintf("The parser feature %q is not recognized and will be ignored.", word.text),
+					Subject:  word.rng.Ptr(),
+				})
+				continue
+			}
+			enable(&opts)
+		}
+	}
+	return opts, diags
+}
+
+type directiveWord struct {
+	text []byte
+	rng  hcl.Range
+}
+
+// directiveWords splits the given text, which is the part of the given line
+// comment token after its comment marker, into words separated by spaces and
+// tabs, along with the source range of each word.
+func directiveWords(tok Token, text []byte) []directiveWord {
+	var words []directiveWord
+	pos := tok.Range.Start
+	advance := func(b []byte) {
+		chars, _ := textseg.TokenCount(b, textseg.ScanGraphemeClusters)
+		pos.Byte += len(b)
+		pos.Column += chars
+	}
+	advance(tok.Bytes[:len(tok.Bytes)-len(text)])
+
+	for {
+		trimmed := bytes.TrimLeft(text, " \t")
+		advance(text[:len(text)-len(trimmed)])
+		text = trimmed
+
+		end := bytes.IndexAny(text, " \t\r\n")
+		if end < 0 {
+			end = len(text)
+		}
+		if end == 0 {
+			return words
+		}
+		start := pos
+		advance(text[:end])
+		words = append(words, directiveWord{
+			text: text[:end],
+			rng: hcl.Range{
+				Filename: tok.Range.Filename,
+				Start:    start,
+				End:      pos,
+			},
+		})
+		text = text[end:]
+	}
+}
@@ -7,4 +7,11 @@
 // In normal use applications should rarely depend on this package directly,
 // instead preferring the higher-level interface of the main hcl package and
 // its companion package hclparse.
+//
+// The Value method of each expression in this package notifies any tracer
+// attached to the given EvalContext using hcl.EvalContext.WithTracer, once
+// for each node of the expression that is evaluated. Expressions also honor
+// hcl.EvalContext.WithExhaustiveDiagnostics, such as by still evaluating the
+// arguments of a function call that cannot succeed in order to report any
+// problems with them.
 package hclsyntax
@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DumpAST returns a human-readable representation of the AST beginning with
+// the given node, for use when debugging or in golden-file tests.
+//
+// Each node is written on its own line, indented to show its depth in the
+// tree, giving the node's type name followed by any details that are
+// particular to that type of node, such as the name of an attribute, the
+// operator of an operation or the value of a literal, and then finally its
+// source range. The exact format is intended only for human consumption, but
+// it is stable for a given AST: the attributes of a body are written in the
+// order they appear in the source, rather than the arbitrary order of the map
+// that holds them.
+func DumpAST(node Node) string {
+	var buf bytes.Buffer
+	WriteAST(&buf, node)
+	return buf.String()
+}
+
+// WriteAST is like DumpAST but writes the result to the given writer rather
+// than returning it as a string. It returns the first error returned by the
+// writer, if any.
+func WriteAST(w io.Writer, node Node) error {
+	d := &astDumper{w: w}
+	d.dump(node, 0)
+	return d.err
+}
+
+type astDumper struct {
+	w   io.Writer
+	err error
+}
+
+func (d *astDumper) dump(node Node, depth int) {
+	if d.err != nil {
+		return
+	}
+
+	line := strings.Repeat("  ", depth) + dumpNodeTypeName(node)
+	if details := dumpNodeDetails(node); details != "" {
+		line += " " + details
+	}
+	switch node.(type) {
+	case Attributes, Blocks:
+		// These are just grouping constructs whose ranges are not meaningful.
+	default:
+		line += " " + dumpRange(node.Range())
+	}
+	_, d.err = io.WriteString(d.w, line+"\n")
+
+	var children []Node
+	node.walkChildNodes(func(child Node) {
+		children = append(children, child)
+	})
+	if _, isAttrs := node.(Attributes); isAttrs {
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].Range().Start.Byte < children[j].Range().Start.Byte
+		})
+	}
+	for _, child := range children {
+		d.dump(child, depth+1)
+	}
+}
+
+func dumpNodeTypeName(node Node) string {
+	name := fmt.Sprintf("%T", node)
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return name
+}
+
+func dumpNodeDetails(node Node) string {
+	switch n := node.(type) {
+	case *Attribute:
+		return fmt.Sprintf("%q", n.Name)
+	case *Block:
+		parts := []string{fmt.Sprintf("%q", n.Type)}
+		for _, label := range n.Labels {
+			parts = append(parts, fmt.Sprintf("%q", label))
+		}
+		return strings.Join(parts, " ")
+	case ChildScope:
+		names := make([]string, 0, len(n.LocalNames))
+		for name := range n.LocalNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "(" + strings.Join(names, ", ") + ")"
+	case *LiteralValueExpr:
+		return dumpValue(n.Val)
+	case *ScopeTraversalExpr:
+		return dumpTraversal(n.Traversal)
+	case *RelativeTraversalExpr:
+		return dumpTraversal(n.Traversal)
+	case *FunctionCallExpr:
+		if n.ExpandFinal {
+			return n.Name + " expand-final"
+		}
+		return n.Name
+	case *ObjectConsKeyExpr:
+		if n.ForceNonLiteral {
+			return "non-literal"
+		}
+		if name := n.literalName(); name != "" {
+			return fmt.Sprintf("%q", name)
+		}
+	case *ForExpr:
+		vars := n.ValVar
+		if n.KeyVar != "" {
+			vars = n.KeyVar + ", " + n.ValVar
+		}
+		if n.Group {
+			return vars + " grouped"
+		}
+		return vars
+	case *BinaryOpExpr:
+		return dumpOperation(n.Op)
+	case *UnaryOpExpr:
+		return dumpOperation(n.Op)
+	}
+	return ""
+}
+
+func dumpOperation(op *Operation) string {
+	switch op {
+	case OpLogicalOr:
+		return "||"
+	case OpLogicalAnd:
+		return "&&"
+	case OpLogicalNot:
+		return "!"
+	case OpEqual:
+		return "=="
+	case OpNotEqual:
+		return "!="
+	case OpGreaterThan:
+		return ">"
+	case OpGreaterThanOrEqual:
+		return ">="
+	case OpLessThan:
+		return "<"
+	case OpLessThanOrEqual:
+		return "<="
+	case OpAdd:
+		return "+"
+	case OpSubtract, OpNegate:
+		return "-"
+	case OpMultiply:
+		return "*"
+	case OpDivide:
+		return "/"
+	case OpModulo:
+		return "%"
+	default:
+		return "(unknown operation)"
+	}
+}
+
+func dumpTraversal(traversal hcl.Traversal) string {
+	var buf strings.Builder
+	for _, step := range traversal {
+		switch ts := step.(type) {
+		case hcl.TraverseRoot:
+			buf.WriteString(ts.Name)
+		case hcl.TraverseAttr:
+			buf.WriteString(".")
+			buf.WriteString(ts.Name)
+		case hcl.TraverseIndex:
+			buf.WriteString("[")
+			buf.WriteString(dumpValue(ts.Key))
+			buf.WriteString("]")
+		case hcl.TraverseSplat:
+			buf.WriteString("[*]")
+		}
+	}
+	return buf.String()
+}
+
+func dumpValue(v cty.Value) string {
+	if v == cty.NilVal {
+		return "(no value)"
+	}
+	ty := v.Type()
+	switch {
+	case !v.IsKnown() || v.IsNull():
+		return fmt.Sprintf("%s %s", (*hcl.EvalContext)(nil).FormatValue(v), ty.FriendlyName())
+	case !ty.IsPrimitiveType():
+		return ty.FriendlyName()
+	default:
+		return (*hcl.EvalContext)(nil).FormatValue(v)
+	}
+}
+
+func dumpRange(rng hcl.Range) string {
+	return fmt.Sprintf("%d,%d-%d,%d", rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDumpAST(t *testing.T) {
+	src := `b = !a.c[0] + -1
+a = "x${y}"
+svc "web" {
+  v = [for k, v in var.m : upper(v...) if k != null]
+  o = { a = 1, (b) = null }
+  s = foo[*].bar ?? (2 * 3)
+}
+`
+	want := `Body 1,1-8,1
+  Attributes
+    Attribute "b" 1,1-1,17
+      BinaryOpExpr + 1,5-1,17
+        UnaryOpExpr ! 1,5-1,12
+          ScopeTraversalExpr a.c[0] 1,6-1,12
+        UnaryOpExpr - 1,15-1,17
+          LiteralValueExpr 1 1,16-1,17
+    Attribute "a" 2,1-2,12
+      TemplateExpr 2,5-2,12
+        LiteralValueExpr "x" 2,6-2,7
+        ScopeTraversalExpr y 2,9-2,10
+  Blocks
+    Block "svc" "web" 3,1-7,2
+      Body 3,11-7,2
+        Attributes
+          Attribute "v" 4,3-4,53
+            ForExpr k, v 4,7-4,53
+              ScopeTraversalExpr var.m 4,20-4,25
+              ChildScope (k, v) 4,28-4,39
+                FunctionCallExpr upper expand-final 4,28-4,39
+                  ScopeTraversalExpr v 4,34-4,35
+              ChildScope (k, v) 4,43-4,52
+                BinaryOpExpr != 4,43-4,52
+                  ScopeTraversalExpr k 4,43-4,44
+                  LiteralValueExpr null dynamic 4,48-4,52
+          Attribute "o" 5,3-5,28
+            ObjectConsExpr 5,7-5,28
+              ObjectConsKeyExpr "a" 5,9-5,10
+              LiteralValueExpr 1 5,13-5,14
+              ObjectConsKeyExpr non-literal 5,16-5,19
+                ParenthesesExpr 5,16-5,19
+                  ScopeTraversalExpr b 5,17-5,18
+              LiteralValueExpr null dynamic 5,22-5,26
+          Attribute "s" 6,3-6,28
+            CoalesceExpr 6,7-6,28
+              SplatExpr 6,7-6,17
+                ScopeTraversalExpr foo 6,7-6,10
+                RelativeTraversalExpr .bar 6,10-6,17
+                  AnonSymbolExpr 6,10-6,13
+              ParenthesesExpr 6,21-6,28
+                BinaryOpExpr * 6,22-6,27
+                  LiteralValueExpr 2 6,22-6,23
+                  LiteralValueExpr 3 6,26-6,27
+        Blocks
+`
+
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	// The attributes are held in a map, so we dump several times to make
+	// sure the output doesn't depend on the map iteration order.
+	for i := 0; i < 10; i++ {
+		got := DumpAST(f.Body.(*Body))
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("wrong result\n%s", diff)
+		}
+	}
+}
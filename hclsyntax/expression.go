@@ -4,7 +4,9 @@
 package hclsyntax
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"sort"
 	"strings"
 	"sync"
@@ -58,6 +60,11 @@ func (e *ParenthesesExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Expression)
 }
 
+// Implementation for hcl.ExprAsLiteral.
+func (e *ParenthesesExpr) AsLiteral() (cty.Value, bool) {
+	return hcl.ExprAsLiteral(e.Expression)
+}
+
 // LiteralValueExpr is an expression that just always returns a given value.
 type LiteralValueExpr struct {
 	Val      cty.Value
@@ -68,10 +75,19 @@ func (e *LiteralValueExpr) walkChildNodes(w internalWalkFunc) {
 	// Literal values have no child nodes
 }
 
-func (e *LiteralValueExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *LiteralValueExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	return e.Val, nil
 }
 
+// Implementation for hcl.ExprAsLiteral.
+func (e *LiteralValueExpr) AsLiteral() (cty.Value, bool) {
+	return e.Val, true
+}
+
 func (e *LiteralValueExpr) Range() hcl.Range {
 	return e.SrcRange
 }
@@ -136,7 +152,11 @@ func (e *ScopeTraversalExpr) walkChildNodes(w internalWalkFunc) {
 	// Scope traversals have no child nodes
 }
 
-func (e *ScopeTraversalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ScopeTraversalExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	val, diags := e.Traversal.TraverseAbs(ctx)
 	setDiagEvalContext(diags, e, ctx)
 	return val, diags
@@ -167,7 +187,11 @@ func (e *RelativeTraversalExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Source)
 }
 
-func (e *RelativeTraversalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *RelativeTraversalExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	src, diags := e.Source.Value(ctx)
 	ret, travDiags := e.Traversal.TraverseRel(src)
 	setDiagEvalContext(travDiags, e, ctx)
@@ -218,9 +242,26 @@ func (e *FunctionCallExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *FunctionCallExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	var diags hcl.Diagnostics
 
+	if err := ctx.CheckFunctionPolicy(e.Name); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     "Function not permitted",
+			Detail:      fmt.Sprintf("The function %q cannot be called here: %s.", e.Name, err),
+			Subject:     &e.NameRange,
+			Context:     e.Range().Ptr(),
+			Expression:  e,
+			EvalContext: ctx,
+		})
+		return cty.DynamicVal, append(diags, skippedArgDiags(ctx, e.Args)...)
+	}
+
 	var f function.Function
 	exists := false
 	hasNonNilMap := false
@@ -240,16 +281,15 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 
 	if !exists {
 		if !hasNonNilMap {
-			return cty.DynamicVal, hcl.Diagnostics{
-				{
-					Severity:    hcl.DiagError,
-					Summary:     "Function calls not allowed",
-					Detail:      "Functions may not be called here.",
-					Subject:     e.Range().Ptr(),
-					Expression:  e,
-					EvalContext: ctx,
-				},
-			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity:    hcl.DiagError,
+				Summary:     "Function calls not allowed",
+				Detail:      "Functions may not be called here.",
+				Subject:     e.Range().Ptr(),
+				Expression:  e,
+				EvalContext: ctx,
+			})
+			return cty.DynamicVal, append(diags, skippedArgDiags(ctx, e.Args)...)
 		}
 
 		extraUnknown := &functionCallUnknown{
@@ -289,36 +329,34 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 				// the function names than the namespaces, because in many
 				// applications there will be relatively few namespaces compared
 				// to the number of distinct functions.
-				return cty.DynamicVal, hcl.Diagnostics{
-					{
-						Severity:    hcl.DiagError,
-						Summary:     "Call to unknown function",
-						Detail:      fmt.Sprintf("There are no functions in namespace %q.", namespace),
-						Subject:     &e.NameRange,
-						Context:     e.Range().Ptr(),
-						Expression:  e,
-						EvalContext: ctx,
-						Extra:       extraUnknown,
-					},
-				}
+				diags = append(diags, &hcl.Diagnostic{
+					Severity:    hcl.DiagError,
+					Summary:     "Call to unknown function",
+					Detail:      fmt.Sprintf("There are no functions in namespace %q.", namespace),
+					Subject:     &e.NameRange,
+					Context:     e.Range().Ptr(),
+					Expression:  e,
+					EvalContext: ctx,
+					Extra:       extraUnknown,
+				})
+				return cty.DynamicVal, append(diags, skippedArgDiags(ctx, e.Args)...)
 			} else {
 				suggestion := nameSuggestion(name, avail)
 				if suggestion != "" {
 					suggestion = fmt.Sprintf(" Did you mean %s%s?", namespace, suggestion)
 				}
 
-				return cty.DynamicVal, hcl.Diagnostics{
-					{
-						Severity:    hcl.DiagError,
-						Summary:     "Call to unknown function",
-						Detail:      fmt.Sprintf("There is no function named %q in namespace %s.%s", name, namespace, suggestion),
-						Subject:     &e.NameRange,
-						Context:     e.Range().Ptr(),
-						Expression:  e,
-						EvalContext: ctx,
-						Extra:       extraUnknown,
-					},
-				}
+				diags = append(diags, &hcl.Diagnostic{
+					Severity:    hcl.DiagError,
+					Summary:     "Call to unknown function",
+					Detail:      fmt.Sprintf("There is no function named %q in namespace %s.%s", name, namespace, suggestion),
+					Subject:     &e.NameRange,
+					Context:     e.Range().Ptr(),
+					Expression:  e,
+					EvalContext: ctx,
+					Extra:       extraUnknown,
+				})
+				return cty.DynamicVal, append(diags, skippedArgDiags(ctx, e.Args)...)
 			}
 		}
 
@@ -331,18 +369,17 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
 		}
 
-		return cty.DynamicVal, hcl.Diagnostics{
-			{
-				Severity:    hcl.DiagError,
-				Summary:     "Call to unknown function",
-				Detail:      fmt.Sprintf("There is no function named %q.%s", e.Name, suggestion),
-				Subject:     &e.NameRange,
-				Context:     e.Range().Ptr(),
-				Expression:  e,
-				EvalContext: ctx,
-				Extra:       extraUnknown,
-			},
-		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     "Call to unknown function",
+			Detail:      fmt.Sprintf("There is no function named %q.%s", e.Name, suggestion),
+			Subject:     &e.NameRange,
+			Context:     e.Range().Ptr(),
+			Expression:  e,
+			EvalContext: ctx,
+			Extra:       extraUnknown,
+		})
+		return cty.DynamicVal, append(diags, skippedArgDiags(ctx, e.Args)...)
 	}
 
 	diagExtra := functionCallDiagExtra{
@@ -362,7 +399,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 		expandVal, expandDiags := expandExpr.Value(ctx)
 		diags = append(diags, expandDiags...)
 		if expandDiags.HasErrors() {
-			return cty.DynamicVal, diags
+			return cty.DynamicVal, append(diags, skippedArgDiags(ctx, args[:len(args)-1])...)
 		}
 
 		switch {
@@ -378,7 +415,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 					EvalContext: ctx,
 					Extra:       &diagExtra,
 				})
-				return cty.DynamicVal, diags
+				return cty.DynamicVal, append(diags, skippedArgDiags(ctx, args[:len(args)-1])...)
 			}
 			return cty.DynamicVal, diags
 		case expandVal.Type().IsTupleType() || expandVal.Type().IsListType() || expandVal.Type().IsSetType():
@@ -393,7 +430,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 					EvalContext: ctx,
 					Extra:       &diagExtra,
 				})
-				return cty.DynamicVal, diags
+				return cty.DynamicVal, append(diags, skippedArgDiags(ctx, args[:len(args)-1])...)
 			}
 			if !expandVal.IsKnown() {
 				return cty.DynamicVal, diags
@@ -425,7 +462,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 				EvalContext: ctx,
 				Extra:       &diagExtra,
 			})
-			return cty.DynamicVal, diags
+			return cty.DynamicVal, append(diags, skippedArgDiags(ctx, args[:len(args)-1])...)
 		}
 	}
 
@@ -435,39 +472,37 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 		if varParam != nil {
 			qual = " at least"
 		}
-		return cty.DynamicVal, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Not enough function arguments",
-				Detail: fmt.Sprintf(
-					"Function %q expects%s %d argument(s). Missing value for %q.",
-					e.Name, qual, len(params), missing.Name,
-				),
-				Subject:     &e.CloseParenRange,
-				Context:     e.Range().Ptr(),
-				Expression:  e,
-				EvalContext: ctx,
-				Extra:       &diagExtra,
-			},
-		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Not enough function arguments",
+			Detail: fmt.Sprintf(
+				"Function %q expects%s %d argument(s). Missing value for %q.",
+				e.Name, qual, len(params), missing.Name,
+			),
+			Subject:     &e.CloseParenRange,
+			Context:     e.Range().Ptr(),
+			Expression:  e,
+			EvalContext: ctx,
+			Extra:       &diagExtra,
+		})
+		return cty.DynamicVal, append(diags, skippedArgDiags(ctx, args)...)
 	}
 
 	if varParam == nil && len(args) > len(params) {
-		return cty.DynamicVal, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Too many function arguments",
-				Detail: fmt.Sprintf(
-					"Function %q expects only %d argument(s).",
-					e.Name, len(params),
-				),
-				Subject:     args[len(params)].StartRange().Ptr(),
-				Context:     e.Range().Ptr(),
-				Expression:  e,
-				EvalContext: ctx,
-				Extra:       &diagExtra,
-			},
-		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Too many function arguments",
+			Detail: fmt.Sprintf(
+				"Function %q expects only %d argument(s).",
+				e.Name, len(params),
+			),
+			Subject:     args[len(params)].StartRange().Ptr(),
+			Context:     e.Range().Ptr(),
+			Expression:  e,
+			EvalContext: ctx,
+			Extra:       &diagExtra,
+		})
+		return cty.DynamicVal, append(diags, skippedArgDiags(ctx, args)...)
 	}
 
 	argVals := make([]cty.Value, len(args))
@@ -531,8 +566,12 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 		// call error via an extra method on our "diagnostic extra" value.
 		diagExtra.functionCallError = err
 
-		switch terr := err.(type) {
-		case function.ArgError:
+		// The function may have wrapped an ArgError in some other error to
+		// add context, so we need to look through any wrapping to find
+		// which argument the error relates to.
+		var terr function.ArgError
+		switch {
+		case errors.As(err, &terr):
 			i := terr.Index
 			var param *function.Parameter
 			if i < len(params) {
@@ -632,6 +671,23 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 	return resultVal, diags
 }
 
+// skippedArgDiags returns the diagnostics from evaluating the given argument
+// expressions if the given context asks for exhaustive diagnostics, or nil
+// otherwise. It is used when a function call fails before its arguments are
+// evaluated, since any problems with the arguments are independent of the
+// problem with the call itself.
+func skippedArgDiags(ctx *hcl.EvalContext, args []Expression) hcl.Diagnostics {
+	if !ctx.ExhaustiveDiagnostics() {
+		return nil
+	}
+	var diags hcl.Diagnostics
+	for _, arg := range args {
+		_, argDiags := arg.Value(ctx)
+		diags = append(diags, argDiags...)
+	}
+	return diags
+}
+
 func (e *FunctionCallExpr) Range() hcl.Range {
 	return hcl.RangeBetween(e.NameRange, e.CloseParenRange)
 }
@@ -723,7 +779,11 @@ func (e *ConditionalExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.FalseResult)
 }
 
-func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ConditionalExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	trueResult, trueDiags := e.TrueResult.Value(ctx)
 	falseResult, falseDiags := e.FalseResult.Value(ctx)
 	var diags hcl.Diagnostics
@@ -755,23 +815,43 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 	default:
 		// Try to find a type that both results can be converted to.
 		resultType, convs = convert.UnifyUnsafe([]cty.Type{trueResult.Type(), falseResult.Type()})
+
+		// A null value can be represented in any type, so if one result is
+		// a known null whose type doesn't unify with the other, such as a
+		// null string variable alongside a list, we take the other result's
+		// type instead of failing.
+		if resultType == cty.NilType {
+			switch {
+			case trueResult.IsKnown() && trueResult.IsNull():
+				resultType = falseResult.Type()
+				convs = []convert.Conversion{nullConversion(resultType), nil}
+			case falseResult.IsKnown() && falseResult.IsNull():
+				resultType = trueResult.Type()
+				convs = []convert.Conversion{nil, nullConversion(resultType)}
+			}
+		}
 	}
 
 	if resultType == cty.NilType {
-		return cty.DynamicVal, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Inconsistent conditional result types",
-				Detail: fmt.Sprintf(
-					"The true and false result expressions must have consistent types. %s.",
-					describeConditionalTypeMismatch(trueResult.Type(), falseResult.Type()),
-				),
-				Subject:     hcl.RangeBetween(e.TrueResult.Range(), e.FalseResult.Range()).Ptr(),
-				Context:     &e.SrcRange,
-				Expression:  e,
-				EvalContext: ctx,
-			},
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Inconsistent conditional result types",
+			Detail: fmt.Sprintf(
+				"The true and false result expressions must have consistent types. %s.",
+				describeConditionalTypeMismatch(trueResult.Type(), falseResult.Type()),
+			),
+			Subject:     hcl.RangeBetween(e.TrueResult.Range(), e.FalseResult.Range()).Ptr(),
+			Context:     &e.SrcRange,
+			Expression:  e,
+			EvalContext: ctx,
+		})
+		if ctx.ExhaustiveDiagnostics() {
+			// The condition is independent of the result types, so we can
+			// still report any problems with it.
+			_, condDiags := e.Condition.Value(ctx)
+			diags = append(diags, condDiags...)
 		}
+		return cty.DynamicVal, diags
 	}
 
 	condResult, condDiags := e.Condition.Value(ctx)
@@ -943,6 +1023,15 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 	}
 }
 
+// nullConversion returns a conversion that replaces a null value of any type
+// with a null value of the given type, preserving its marks.
+func nullConversion(ty cty.Type) convert.Conversion {
+	return func(in cty.Value) (cty.Value, error) {
+		_, marks := in.Unmark()
+		return cty.NullVal(ty).WithMarks(marks), nil
+	}
+}
+
 // describeConditionalTypeMismatch makes a best effort to describe the
 // difference between types in the true and false arms of a conditional
 // expression in a way that would be useful to someone trying to understand
@@ -1103,7 +1192,11 @@ func (e *IndexExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Key)
 }
 
-func (e *IndexExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *IndexExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	var diags hcl.Diagnostics
 	coll, collDiags := e.Collection.Value(ctx)
 	key, keyDiags := e.Key.Value(ctx)
@@ -1137,7 +1230,11 @@ func (e *TupleConsExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *TupleConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *TupleConsExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	var vals []cty.Value
 	var diags hcl.Diagnostics
 
@@ -1159,6 +1256,19 @@ func (e *TupleConsExpr) StartRange() hcl.Range {
 	return e.OpenRange
 }
 
+// Implementation for hcl.ExprAsLiteral.
+func (e *TupleConsExpr) AsLiteral() (cty.Value, bool) {
+	vals := make([]cty.Value, len(e.Exprs))
+	for i, expr := range e.Exprs {
+		val, ok := hcl.ExprAsLiteral(expr)
+		if !ok {
+			return cty.NilVal, false
+		}
+		vals[i] = val
+	}
+	return cty.TupleVal(vals), true
+}
+
 // Implementation for hcl.ExprList
 func (e *TupleConsExpr) ExprList() []hcl.Expression {
 	ret := make([]hcl.Expression, len(e.Exprs))
@@ -1187,7 +1297,11 @@ func (e *ObjectConsExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ObjectConsExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	var vals map[string]cty.Value
 	var diags hcl.Diagnostics
 	var marks []cty.ValueMarks
@@ -1203,7 +1317,27 @@ func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics
 	known := true
 
 	vals = make(map[string]cty.Value, len(e.Items))
+	tracing := ctx.Tracer() != nil
 	for _, item := range e.Items {
+		// Most keys are naked identifiers, for which we can skip producing
+		// and converting a key value. When tracing, we evaluate every key
+		// so that the tracer sees the same nodes either way.
+		if keyExpr, ok := item.KeyExpr.(*ObjectConsKeyExpr); ok && !tracing {
+			// Skipping the key's Value method also skips its check for
+			// cancellation, so we must make that check here instead.
+			if cancelDiags := checkEvalCancelled(ctx, keyExpr); cancelDiags != nil {
+				diags = append(diags, cancelDiags...)
+				known = false
+				continue
+			}
+			if name, ok := keyExpr.staticName(); ok {
+				val, valDiags := item.ValueExpr.Value(ctx)
+				diags = append(diags, valDiags...)
+				vals[cty.NormalizeString(name)] = val
+				continue
+			}
+		}
+
 		key, keyDiags := item.KeyExpr.Value(ctx)
 		diags = append(diags, keyDiags...)
 
@@ -1229,7 +1363,9 @@ func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics
 		}
 
 		key, keyMarks := key.Unmark()
-		marks = append(marks, keyMarks)
+		if len(keyMarks) != 0 {
+			marks = append(marks, keyMarks)
+		}
 
 		var err error
 		key, err = convert.Convert(key, cty.String)
@@ -1271,6 +1407,36 @@ func (e *ObjectConsExpr) StartRange() hcl.Range {
 	return e.OpenRange
 }
 
+// Implementation for hcl.ExprAsLiteral.
+func (e *ObjectConsExpr) AsLiteral() (cty.Value, bool) {
+	vals := make(map[string]cty.Value, len(e.Items))
+	for _, item := range e.Items {
+		keyExpr := item.KeyExpr
+		if ck, ok := keyExpr.(*ObjectConsKeyExpr); ok {
+			if name := ck.literalName(); name != "" {
+				keyExpr = &LiteralValueExpr{Val: cty.StringVal(name)}
+			} else {
+				keyExpr = ck.Wrapped
+			}
+		}
+		key, ok := hcl.ExprAsLiteral(keyExpr)
+		if !ok {
+			return cty.NilVal, false
+		}
+		key, err := convert.Convert(key, cty.String)
+		if err != nil || key.IsNull() {
+			return cty.NilVal, false
+		}
+
+		val, ok := hcl.ExprAsLiteral(item.ValueExpr)
+		if !ok {
+			return cty.NilVal, false
+		}
+		vals[key.AsString()] = val
+	}
+	return cty.ObjectVal(vals), true
+}
+
 // Implementation for hcl.ExprMap
 func (e *ObjectConsExpr) ExprMap() []hcl.KeyValuePair {
 	ret := make([]hcl.KeyValuePair, len(e.Items))
@@ -1300,6 +1466,21 @@ func (e *ObjectConsKeyExpr) literalName() string {
 	return hcl.ExprAsKeyword(e.Wrapped)
 }
 
+// staticName returns the literal string that the key evaluates to, if it is
+// a naked identifier or keyword that is interpreted as a literal name rather
+// than evaluated.
+func (e *ObjectConsKeyExpr) staticName() (string, bool) {
+	if e.ForceNonLiteral {
+		return "", false
+	}
+	if travExpr, isTraversal := e.Wrapped.(*ScopeTraversalExpr); isTraversal && len(travExpr.Traversal) > 1 {
+		// This is an error, which we leave for the value method to report.
+		return "", false
+	}
+	ln := e.literalName()
+	return ln, ln != ""
+}
+
 func (e *ObjectConsKeyExpr) walkChildNodes(w internalWalkFunc) {
 	// We only treat our wrapped expression as a real expression if we're
 	// not going to interpret it as a literal.
@@ -1308,7 +1489,11 @@ func (e *ObjectConsKeyExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *ObjectConsKeyExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ObjectConsKeyExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	// Because we accept a naked identifier as a literal key rather than a
 	// reference, it's confusing to accept a traversal containing periods
 	// here since we can't tell if the user intends to create a key with
@@ -1388,7 +1573,11 @@ type ForExpr struct {
 	CloseRange hcl.Range
 }
 
-func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ForExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	var diags hcl.Diagnostics
 	var marks []cty.ValueMarks
 
@@ -1481,10 +1670,15 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		// Producing an object
 		var vals map[string]cty.Value
 		var groupVals map[string][]cty.Value
+		// elemKeys records the collection key of the element that produced
+		// each result key, so that we can identify both elements if another
+		// produces the same key.
+		var elemKeys map[string]cty.Value
 		if e.Group {
 			groupVals = map[string][]cty.Value{}
 		} else {
 			vals = map[string]cty.Value{}
+			elemKeys = map[string]cty.Value{}
 		}
 
 		it := collVal.ElementIterator()
@@ -1597,22 +1791,25 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 				k := key.AsString()
 				groupVals[k] = append(groupVals[k], val)
 			} else {
+				elemKey := k
 				k := key.AsString()
 				if _, exists := vals[k]; exists {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Duplicate object key",
 						Detail: fmt.Sprintf(
-							"Two different items produced the key %q in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.",
-							k,
+							"Two different items, with the keys %s and %s in the source collection, produced the key %s in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.",
+							childCtx.FormatValue(elemKeys[k]), childCtx.FormatValue(elemKey),
+							childCtx.FormatValue(key.WithMarks(keyMarks)),
 						),
-						Subject:     e.KeyExpr.Range().Ptr(),
+						Subject:     e.collElemRange(elemKey).Ptr(),
 						Context:     &e.SrcRange,
 						Expression:  e.KeyExpr,
 						EvalContext: childCtx,
 					})
 				} else {
-					vals[key.AsString()] = val
+					vals[k] = val
+					elemKeys[k] = elemKey
 				}
 			}
 		}
@@ -1712,6 +1909,18 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	}
 }
 
+// collElemRange returns the source range of the element of the collection
+// with the given key, if the collection is given directly as a tuple
+// constructor expression, or the range of the key expression otherwise.
+func (e *ForExpr) collElemRange(key cty.Value) hcl.Range {
+	if tupleExpr, ok := e.CollExpr.(*TupleConsExpr); ok && key.Type() == cty.Number {
+		if idx, acc := key.AsBigFloat().Int64(); acc == big.Exact && idx >= 0 && idx < int64(len(tupleExpr.Exprs)) {
+			return tupleExpr.Exprs[idx].Range()
+		}
+	}
+	return e.KeyExpr.Range()
+}
+
 func (e *ForExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.CollExpr)
 
@@ -1749,16 +1958,39 @@ func (e *ForExpr) StartRange() hcl.Range {
 	return e.OpenRange
 }
 
+// SplatExpr represents a splat expression, like "foo[*].bar", which applies
+// the expression after the splat operator to each element of a sequence.
+//
+// If the source value is a list, set, or tuple then the result is a list or
+// tuple respectively, with one element per element of the source value. Any
+// other value, including a map or an object, is instead treated as if it
+// were a one-element tuple containing that value, and so the result is a
+// one-element tuple. A null value of any other type is treated as an empty
+// tuple unless NullIsError is set. A null list, set, or tuple is always an
+// error.
+//
+// If the source value is unknown and could be null once known, the result
+// is entirely unknown because it is not yet known how many elements it will
+// have. Marks on the source value are preserved on the result in all cases.
 type SplatExpr struct {
 	Source Expression
 	Each   Expression
 	Item   *AnonSymbolExpr
 
+	// NullIsError, if set, causes evaluation to produce an error when the
+	// source value is null and not of a sequence type, rather than treating
+	// it as an empty tuple as is the default.
+	NullIsError bool
+
 	SrcRange    hcl.Range
 	MarkerRange hcl.Range
 }
 
-func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *SplatExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	sourceVal, diags := e.Source.Value(ctx)
 	if diags.HasErrors() {
 		// We'll evaluate our "Each" expression here just to see if it
@@ -1779,8 +2011,8 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	autoUpgrade := !(sourceTy.IsTupleType() || sourceTy.IsListType() || sourceTy.IsSetType())
 
 	if sourceVal.IsNull() {
-		if autoUpgrade {
-			return cty.EmptyTupleVal, diags
+		if autoUpgrade && !e.NullIsError {
+			return cty.EmptyTupleVal.WithSameMarks(sourceVal), diags
 		}
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
@@ -1802,6 +2034,7 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	}
 
 	upgradedUnknown := false
+	var upgradedVal cty.Value
 	if autoUpgrade {
 		// If we're upgrading an unknown value to a tuple/list, the result
 		// cannot be known. Otherwise a tuple containing an unknown value will
@@ -1813,12 +2046,13 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		// list of a single attribute, but we still need to check if that
 		// attribute actually exists.
 		if !sourceVal.IsKnown() {
-			sourceRng := sourceVal.Range()
-			if sourceRng.CouldBeNull() {
+			sv, _ := sourceVal.Unmark()
+			if sv.Range().CouldBeNull() {
 				upgradedUnknown = true
 			}
 		}
 
+		upgradedVal = sourceVal
 		sourceVal = cty.TupleVal([]cty.Value{sourceVal})
 		sourceTy = sourceVal.Type()
 	}
@@ -1900,14 +2134,14 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	e.Item.clearValue(ctx) // clean up our temporary value
 
 	if upgradedUnknown {
-		return cty.DynamicVal, diags
+		return cty.DynamicVal.WithSameMarks(upgradedVal), diags
 	}
 
 	if !isKnown {
 		// We'll ingore the resultTy diagnostics in this case since they
 		// will just be the same errors we saw while iterating above.
 		ty, _ := resultTy()
-		return cty.UnknownVal(ty), diags
+		return cty.UnknownVal(ty).WithMarks(marks), diags
 	}
 
 	switch {
@@ -1915,7 +2149,7 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		if len(vals) == 0 {
 			ty, tyDiags := resultTy()
 			diags = append(diags, tyDiags...)
-			return cty.ListValEmpty(ty.ElementType()), diags
+			return cty.ListValEmpty(ty.ElementType()).WithMarks(marks), diags
 		}
 		return cty.ListVal(vals).WithMarks(marks), diags
 	default:
@@ -1959,7 +2193,11 @@ type AnonSymbolExpr struct {
 	valuesLock sync.RWMutex
 }
 
-func (e *AnonSymbolExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *AnonSymbolExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	if ctx == nil {
 		return cty.DynamicVal, nil
 	}
@@ -128,7 +128,8 @@ type BinaryOpExpr struct {
 	Op  *Operation
 	RHS Expression
 
-	SrcRange hcl.Range
+	SrcRange    hcl.Range
+	SymbolRange hcl.Range
 }
 
 func (e *BinaryOpExpr) walkChildNodes(w internalWalkFunc) {
@@ -136,7 +137,11 @@ func (e *BinaryOpExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.RHS)
 }
 
-func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *BinaryOpExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	impl := e.Op.Impl // assumed to be a function taking exactly two arguments
 	params := impl.Params()
 	lhsParam := params[0]
@@ -145,6 +150,9 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 	var diags hcl.Diagnostics
 
 	givenLHSVal, lhsDiags := e.LHS.Value(ctx)
+	if result, ok := e.shortCircuit(givenLHSVal, lhsParam.Type); ok {
+		return result, lhsDiags
+	}
 	givenRHSVal, rhsDiags := e.RHS.Value(ctx)
 	diags = append(diags, lhsDiags...)
 	diags = append(diags, rhsDiags...)
@@ -156,7 +164,7 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 			Summary:     "Invalid operand",
 			Detail:      fmt.Sprintf("Unsuitable value for left operand: %s.", err),
 			Subject:     e.LHS.Range().Ptr(),
-			Context:     &e.SrcRange,
+			Context:     e.operandContext(e.LHS),
 			Expression:  e.LHS,
 			EvalContext: ctx,
 		})
@@ -168,12 +176,39 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 			Summary:     "Invalid operand",
 			Detail:      fmt.Sprintf("Unsuitable value for right operand: %s.", err),
 			Subject:     e.RHS.Range().Ptr(),
-			Context:     &e.SrcRange,
+			Context:     e.operandContext(e.RHS),
 			Expression:  e.RHS,
 			EvalContext: ctx,
 		})
 	}
 
+	if !diags.HasErrors() {
+		// The operator functions would reject null operands anyway, but
+		// with an error that doesn't say which operand was null.
+		if !lhsParam.AllowNull && lhsVal.IsNull() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity:    hcl.DiagError,
+				Summary:     "Invalid operand",
+				Detail:      "Unsuitable value for left operand: must not be null.",
+				Subject:     e.LHS.Range().Ptr(),
+				Context:     e.operandContext(e.LHS),
+				Expression:  e.LHS,
+				EvalContext: ctx,
+			})
+		}
+		if !rhsParam.AllowNull && rhsVal.IsNull() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity:    hcl.DiagError,
+				Summary:     "Invalid operand",
+				Detail:      "Unsuitable value for right operand: must not be null.",
+				Subject:     e.RHS.Range().Ptr(),
+				Context:     e.operandContext(e.RHS),
+				Expression:  e.RHS,
+				EvalContext: ctx,
+			})
+		}
+	}
+
 	if diags.HasErrors() {
 		// Don't actually try the call if we have errors already, since the
 		// this will probably just produce a confusing duplicative diagnostic.
@@ -198,6 +233,52 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 	return result, diags
 }
 
+// shortCircuit determines whether the result of a logical operator can be
+// decided by its left operand alone, in which case the right operand must
+// not be evaluated. If so, it returns the result and true.
+//
+// Only a known, non-null boolean left operand can decide the result; in all
+// other cases both operands are evaluated as normal.
+func (e *BinaryOpExpr) shortCircuit(givenLHSVal cty.Value, lhsType cty.Type) (cty.Value, bool) {
+	var decisive cty.Value
+	switch e.Op {
+	case OpLogicalAnd:
+		decisive = cty.False
+	case OpLogicalOr:
+		decisive = cty.True
+	default:
+		return cty.NilVal, false
+	}
+
+	lhsVal, err := convert.Convert(givenLHSVal, lhsType)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	// The left operand decides the result, and so its marks apply to it.
+	lhsVal, marks := lhsVal.Unmark()
+	if !lhsVal.IsKnown() || lhsVal.IsNull() || !lhsVal.RawEquals(decisive) {
+		return cty.NilVal, false
+	}
+	return decisive.WithMarks(marks), true
+}
+
+// symbolRange returns the range of the operator symbol, falling back on the
+// range of the whole expression for synthetic expressions constructed
+// without one.
+func (e *BinaryOpExpr) symbolRange() hcl.Range {
+	if e.SymbolRange.Empty() {
+		return e.SrcRange
+	}
+	return e.SymbolRange
+}
+
+// operandContext returns a context range for a diagnostic about the given
+// operand, spanning from the operand to the operator symbol.
+func (e *BinaryOpExpr) operandContext(operand Expression) *hcl.Range {
+	rng := hcl.RangeOver(operand.Range(), e.symbolRange())
+	return &rng
+}
+
 func (e *BinaryOpExpr) Range() hcl.Range {
 	return e.SrcRange
 }
@@ -206,6 +287,64 @@ func (e *BinaryOpExpr) StartRange() hcl.Range {
 	return e.LHS.StartRange()
 }
 
+// CoalesceExpr represents the null coalescing operator, as in a ?? b, which
+// produces the value of LHS unless it is null, in which case it produces the
+// value of RHS instead.
+//
+// As with the logical operators represented by BinaryOpExpr, RHS is evaluated
+// only if it is needed, so that it may refer to things that are valid only
+// when LHS is null. The result is not converted to any particular type, so the two
+// operands may have different types.
+type CoalesceExpr struct {
+	LHS Expression
+	RHS Expression
+
+	SrcRange    hcl.Range
+	SymbolRange hcl.Range
+}
+
+func (e *CoalesceExpr) walkChildNodes(w internalWalkFunc) {
+	w(e.LHS)
+	w(e.RHS)
+}
+
+func (e *CoalesceExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
+	lhsVal, diags := e.LHS.Value(ctx)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+
+	// Whether the left operand is null decides which value we return, and
+	// so its marks apply to the result, as for the condition of a
+	// conditional expression.
+	unmarkedLHS, marks := lhsVal.Unmark()
+
+	switch {
+	case !unmarkedLHS.IsKnown() && unmarkedLHS.Range().CouldBeNull():
+		// We can't know yet which operand will be selected, and so we
+		// don't even know the type of the result.
+		return cty.DynamicVal.WithMarks(marks), diags
+	case !unmarkedLHS.IsNull():
+		return lhsVal, diags
+	}
+
+	rhsVal, rhsDiags := e.RHS.Value(ctx)
+	diags = append(diags, rhsDiags...)
+	return rhsVal.WithMarks(marks), diags
+}
+
+func (e *CoalesceExpr) Range() hcl.Range {
+	return e.SrcRange
+}
+
+func (e *CoalesceExpr) StartRange() hcl.Range {
+	return e.LHS.StartRange()
+}
+
 type UnaryOpExpr struct {
 	Op  *Operation
 	Val Expression
@@ -218,7 +357,11 @@ func (e *UnaryOpExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Val)
 }
 
-func (e *UnaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *UnaryOpExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	impl := e.Op.Impl // assumed to be a function taking exactly one argument
 	params := impl.Params()
 	param := params[0]
@@ -262,6 +405,19 @@ func (e *UnaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	return result, diags
 }
 
+// Implementation for hcl.ExprAsLiteral. Only the negation of a number
+// literal, such as -1, is a literal.
+func (e *UnaryOpExpr) AsLiteral() (cty.Value, bool) {
+	if e.Op != OpNegate {
+		return cty.NilVal, false
+	}
+	val, ok := hcl.ExprAsLiteral(e.Val)
+	if !ok || val.IsNull() || val.Type() != cty.Number {
+		return cty.NilVal, false
+	}
+	return val.Negate(), true
+}
+
 func (e *UnaryOpExpr) Range() hcl.Range {
 	return e.SrcRange
 }
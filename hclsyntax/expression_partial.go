@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Unresolved describes the names that ValuePartial could not find in the
+// given EvalContext and so substituted with unknown values.
+type Unresolved struct {
+	// Variables are the root names of any traversals whose root variable
+	// is not defined, deduplicated and sorted lexically.
+	Variables []string
+
+	// Functions are the names of any called functions that are not
+	// defined, deduplicated and sorted lexically.
+	Functions []string
+}
+
+// Empty returns true if there are no unresolved names.
+func (u Unresolved) Empty() bool {
+	return len(u.Variables) == 0 && len(u.Functions) == 0
+}
+
+// ValuePartial is a variant of Expression.Value that tolerates variables and
+// functions that are not defined in the given EvalContext.
+//
+// Each traversal whose root name is not defined in the context (or any of its
+// parents) evaluates as cty.DynamicVal, and each call to a function that is
+// not defined in the context accepts any arguments and returns cty.DynamicVal.
+// Evaluation then proceeds as normal, so the result is the best-effort value
+// of the expression, which will typically be unknown or contain unknowns if
+// anything was unresolved.
+//
+// The names that could not be resolved are returned so that the caller can
+// report them or decide whether the result is useful. Other errors, such as
+// type mismatches or traversals to attributes that don't exist on a defined
+// variable, are still returned as diagnostics.
+//
+// The given EvalContext may be nil, in which case all variables and functions
+// are considered unresolved.
+func ValuePartial(expr Expression, ctx *hcl.EvalContext) (cty.Value, Unresolved, hcl.Diagnostics) {
+	var unresolved Unresolved
+
+	// Values returned by a VariableResolver are kept so that we can evaluate
+	// with them directly, rather than calling the resolver again for the
+	// same names during evaluation.
+	resolved := make(map[string]cty.Value)
+
+	seenVars := make(map[string]struct{})
+	for _, traversal := range expr.Variables() {
+		name := traversal.RootName()
+		if _, seen := seenVars[name]; seen {
+			continue
+		}
+		seenVars[name] = struct{}{}
+		val, exists, fromResolver := ctxLookupVariable(ctx, name)
+		switch {
+		case !exists:
+			unresolved.Variables = append(unresolved.Variables, name)
+		case fromResolver:
+			resolved[name] = val
+		}
+	}
+	for _, name := range Functions(expr) {
+		if !ctxHasFunction(ctx, name) {
+			unresolved.Functions = append(unresolved.Functions, name)
+		}
+	}
+
+	if unresolved.Empty() && len(resolved) == 0 {
+		val, diags := expr.Value(ctx)
+		return val, unresolved, diags
+	}
+
+	sort.Strings(unresolved.Variables)
+
+	var partialCtx *hcl.EvalContext
+	if ctx != nil {
+		partialCtx = ctx.NewChild()
+	} else {
+		partialCtx = &hcl.EvalContext{}
+	}
+	if len(unresolved.Variables) != 0 || len(resolved) != 0 {
+		partialCtx.Variables = make(map[string]cty.Value, len(unresolved.Variables)+len(resolved))
+		for name, val := range resolved {
+			partialCtx.Variables[name] = val
+		}
+		for _, name := range unresolved.Variables {
+			partialCtx.Variables[name] = cty.DynamicVal
+		}
+	}
+	if len(unresolved.Functions) != 0 {
+		partialCtx.Functions = make(map[string]function.Function, len(unresolved.Functions))
+		for _, name := range unresolved.Functions {
+			partialCtx.Functions[name] = unknownFunction
+		}
+	}
+
+	val, diags := expr.Value(partialCtx)
+	return val, unresolved, diags
+}
+
+// ctxLookupVariable finds the value of the given root variable name in the
+// given context or its parents, in the same order as for evaluation. The
+// fromResolver result is true if the value was returned by a
+// VariableResolver rather than found in a Variables map.
+func ctxLookupVariable(ctx *hcl.EvalContext, name string) (val cty.Value, exists, fromResolver bool) {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.Parent() {
+		if val, exists := thisCtx.Variables[name]; exists {
+			return val, true, false
+		}
+		if thisCtx.VariableResolver != nil {
+			if val, exists := thisCtx.VariableResolver(name); exists {
+				return val, true, true
+			}
+		}
+	}
+	return cty.NilVal, false, false
+}
+
+func ctxHasFunction(ctx *hcl.EvalContext, name string) bool {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.Parent() {
+		if _, exists := thisCtx.Functions[name]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownFunction is the placeholder that ValuePartial uses for functions
+// that are not defined. It accepts any number of arguments of any type and
+// always returns an unknown value of unknown type.
+var unknownFunction = function.New(&function.Spec{
+	VarParam: &function.Parameter{
+		Name:             "args",
+		Type:             cty.DynamicPseudoType,
+		AllowNull:        true,
+		AllowUnknown:     true,
+		AllowDynamicType: true,
+		AllowMarked:      true,
+	},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.DynamicVal, nil
+	},
+})
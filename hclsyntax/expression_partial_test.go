@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestValuePartial(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"known": cty.StringVal("hello"),
+			"obj": cty.ObjectVal(map[string]cty.Value{
+				"attr": cty.True,
+			}),
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+		},
+	}
+
+	resolverCtx := ctx.NewChild()
+	resolverCtx.VariableResolver = func(name string) (cty.Value, bool) {
+		if name == "resolved" {
+			return cty.StringVal("a"), true
+		}
+		return cty.NilVal, false
+	}
+
+	tests := []struct {
+		src            string
+		ctx            *hcl.EvalContext
+		want           cty.Value
+		wantUnresolved Unresolved
+		wantDiags      int
+	}{
+		{
+			`upper(known)`,
+			ctx,
+			cty.StringVal("HELLO"),
+			Unresolved{},
+			0,
+		},
+		{
+			`upper(unknown)`,
+			ctx,
+			cty.UnknownVal(cty.String).RefineNotNull(),
+			Unresolved{
+				Variables: []string{"unknown"},
+			},
+			0,
+		},
+		{
+			`"${known} ${missing.foo[0]} ${lower(missing.bar)}"`,
+			ctx,
+			cty.UnknownVal(cty.String).Refine().NotNull().StringPrefixFull("hello ").NewValue(),
+			Unresolved{
+				Variables: []string{"missing"},
+				Functions: []string{"lower"},
+			},
+			0,
+		},
+		{
+			`obj.attr ? upper(known) : nope()`,
+			ctx,
+			cty.StringVal("HELLO"),
+			Unresolved{
+				Functions: []string{"nope"},
+			},
+			0,
+		},
+		{
+			`[for x in xs : upper(x)]`,
+			ctx,
+			cty.DynamicVal,
+			Unresolved{
+				Variables: []string{"xs"},
+			},
+			0,
+		},
+		{
+			`[for x in ["a"] : upper(x)]`,
+			nil,
+			cty.TupleVal([]cty.Value{cty.DynamicVal}),
+			Unresolved{
+				Functions: []string{"upper"},
+			},
+			0,
+		},
+		{
+			`obj.nonexist`,
+			ctx,
+			cty.DynamicVal,
+			Unresolved{},
+			1, // Unsupported attribute; obj is defined, so this is still an error
+		},
+		{
+			`known + missing`,
+			ctx,
+			cty.UnknownVal(cty.Number),
+			Unresolved{
+				Variables: []string{"missing"},
+			},
+			1, // Unsuitable value type; known is a string that can't be a number
+		},
+		{
+			`upper(resolved) == upper(missing)`,
+			resolverCtx.NewChild(),
+			cty.UnknownVal(cty.Bool).RefineNotNull(),
+			Unresolved{
+				Variables: []string{"missing"},
+			},
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			got, gotUnresolved, diags := ValuePartial(expr, test.ctx)
+			if len(diags) != test.wantDiags {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.wantDiags)
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+			}
+			if !test.want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+			if !reflect.DeepEqual(gotUnresolved, test.wantUnresolved) {
+				t.Errorf("wrong unresolved names\ngot:  %#v\nwant: %#v", gotUnresolved, test.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestValuePartialResolvesOnce(t *testing.T) {
+	calls := make(map[string]int)
+	ctx := &hcl.EvalContext{
+		VariableResolver: func(name string) (cty.Value, bool) {
+			calls[name]++
+			if name == "resolved" {
+				return cty.StringVal("a"), true
+			}
+			return cty.NilVal, false
+		},
+	}
+
+	for _, src := range []string{`resolved`, `"${resolved}${resolved}${missing}"`} {
+		t.Run(src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			calls = make(map[string]int)
+			_, _, diags = ValuePartial(expr, ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			for name, n := range calls {
+				if n != 1 {
+					t.Errorf("resolver called %d times for %q; want 1", n, name)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package hclsyntax
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
@@ -24,13 +25,18 @@ func (e *TemplateExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	buf := &bytes.Buffer{}
+func (e *TemplateExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
+	var buf strings.Builder
 	var diags hcl.Diagnostics
 	isKnown := true
 
-	// Maintain a set of marks for values used in the template
-	marks := make(cty.ValueMarks)
+	// Maintain a set of marks for values used in the template, allocated
+	// only once we find a marked part.
+	var marks cty.ValueMarks
 
 	for _, part := range e.Parts {
 		partVal, partDiags := part.Value(ctx)
@@ -51,10 +57,29 @@ func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 
 		// Unmark the part and merge its marks into the set
 		unmarkedVal, partMarks := partVal.Unmark()
+		if len(partMarks) != 0 && marks == nil {
+			marks = make(cty.ValueMarks)
+		}
 		for k, v := range partMarks {
 			marks[k] = v
 		}
 
+		if ty := unmarkedVal.Type(); ctx.StrictTemplates() && ty != cty.String && ty != cty.Number && ty != cty.Bool && ty != cty.DynamicPseudoType {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid template interpolation value",
+				Detail: fmt.Sprintf(
+					"Cannot include the given value in a string template: a string, number or bool is required in strict template mode, not %s.",
+					ty.FriendlyName(),
+				),
+				Subject:     part.Range().Ptr(),
+				Context:     &e.SrcRange,
+				Expression:  part,
+				EvalContext: ctx,
+			})
+			continue
+		}
+
 		if !partVal.IsKnown() {
 			// If any part is unknown then the result as a whole must be
 			// unknown too. We'll keep on processing the rest of the parts
@@ -106,13 +131,13 @@ func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 				ret = ret.Refine().StringPrefix(knownPrefix[:byteLen]).NewValue()
 			}
 		}
+		// A template rendering result is never null. (A known result is a
+		// string value, and so is not null either.)
+		ret = ret.RefineNotNull()
 	} else {
 		ret = cty.StringVal(buf.String())
 	}
 
-	// A template rendering result is never null.
-	ret = ret.RefineNotNull()
-
 	// Apply the full set of marks to the returned value
 	return ret.WithMarks(marks), diags
 }
@@ -145,6 +170,15 @@ func (e *TemplateExpr) IsStringLiteral() bool {
 	return ok
 }
 
+// Implementation for hcl.ExprAsLiteral. Only a template that is a string
+// literal, as for IsStringLiteral, is a literal.
+func (e *TemplateExpr) AsLiteral() (cty.Value, bool) {
+	if !e.IsStringLiteral() {
+		return cty.NilVal, false
+	}
+	return e.Parts[0].(*LiteralValueExpr).Val, true
+}
+
 // TemplateJoinExpr is used to convert tuples of strings produced by template
 // constructs (i.e. for loops) into flat strings, by converting the values
 // tos strings and joining them. This AST node is not used directly; it's
@@ -157,7 +191,11 @@ func (e *TemplateJoinExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Tuple)
 }
 
-func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *TemplateJoinExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	tuple, diags := e.Tuple.Value(ctx)
 
 	if tuple.IsNull() {
@@ -249,7 +287,11 @@ func (e *TemplateWrapExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Wrapped)
 }
 
-func (e *TemplateWrapExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *TemplateWrapExpr) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if diags := checkEvalCancelled(ctx, e); diags != nil {
+		return cty.DynamicVal, diags
+	}
+
 	return e.Wrapped.Value(ctx)
 }
 
@@ -260,3 +302,113 @@ func (e *TemplateWrapExpr) Range() hcl.Range {
 func (e *TemplateWrapExpr) StartRange() hcl.Range {
 	return e.SrcRange
 }
+
+// TemplateValuePreservingUnresolved is like calling Value on the given
+// template expression, except that any interpolation sequence whose
+// expression refers to a variable that cannot be resolved in the given
+// context is reproduced verbatim in the result, delimiters included, rather
+// than producing an error. This allows a template to be rendered in several
+// passes, giving each pass only some of the variables, with the result of
+// one pass being parsed again as a template for the next.
+//
+// The src argument must be the source code that the template was parsed
+// from, which is used to recover the text of each interpolation sequence.
+//
+// Only interpolation sequences are preserved. Template directives, such as
+// %{ if ... } and %{ for ... }, are always evaluated as usual, as are any
+// interpolations nested inside them. Escape sequences such as $${ produce
+// literal text in the usual way, and so a template produced by an earlier
+// pass should not rely on them to protect text from later passes.
+//
+// If the given expression is not a TemplateExpr or a TemplateWrapExpr then
+// this is equivalent to calling its Value method.
+func TemplateValuePreservingUnresolved(expr Expression, ctx *hcl.EvalContext, src []byte) (cty.Value, hcl.Diagnostics) {
+	switch expr := expr.(type) {
+	case *TemplateExpr:
+		parts := make([]Expression, len(expr.Parts))
+		for i, part := range expr.Parts {
+			parts[i] = preserveUnresolvedInterp(part, ctx, src)
+		}
+		return (&TemplateExpr{
+			Parts:    parts,
+			SrcRange: expr.SrcRange,
+		}).Value(ctx)
+	case *TemplateWrapExpr:
+		if lit, ok := preserveUnresolvedInterp(expr.Wrapped, ctx, src).(*LiteralValueExpr); ok {
+			return lit.Value(ctx)
+		}
+		return expr.Value(ctx)
+	default:
+		return expr.Value(ctx)
+	}
+}
+
+// preserveUnresolvedInterp returns a literal expression containing the
+// source code of the interpolation sequence that wraps the given template
+// part if the part refers to any variable that cannot be resolved in the
+// given context. Otherwise, it returns the part unchanged.
+func preserveUnresolvedInterp(part Expression, ctx *hcl.EvalContext, src []byte) Expression {
+	if _, isLit := part.(*LiteralValueExpr); isLit {
+		return part
+	}
+
+	unresolved := false
+	for _, traversal := range part.Variables() {
+		if _, diags := traversal.TraverseAbs(ctx); diags.HasErrors() {
+			unresolved = true
+			break
+		}
+	}
+	if !unresolved {
+		return part
+	}
+
+	start, end, ok := templateInterpBounds(part.Range(), src)
+	if !ok {
+		// The part isn't an interpolation sequence, so it must be a
+		// directive, which we don't preserve.
+		return part
+	}
+	return &LiteralValueExpr{
+		Val:      cty.StringVal(string(src[start:end])),
+		SrcRange: part.Range(),
+	}
+}
+
+// templateInterpBounds returns the byte offsets of the start and end of the
+// interpolation sequence around an expression with the given range, including
+// the "${" and "}" delimiters and any strip markers and whitespace inside
+// them. The final result is false if the expression isn't delimited in that
+// way in the given source.
+func templateInterpBounds(exprRange hcl.Range, src []byte) (int, int, bool) {
+	start, end := exprRange.Start.Byte, exprRange.End.Byte
+	if start < 0 || end > len(src) || start > end {
+		return 0, 0, false
+	}
+
+	isSpace := func(b byte) bool {
+		return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+	}
+
+	for start > 0 && isSpace(src[start-1]) {
+		start--
+	}
+	if start > 0 && src[start-1] == '~' {
+		start--
+	}
+	if start < 2 || src[start-2] != '$' || src[start-1] != '{' {
+		return 0, 0, false
+	}
+	start -= 2
+
+	for end < len(src) && isSpace(src[end]) {
+		end++
+	}
+	if end < len(src) && src[end] == '~' {
+		end++
+	}
+	if end >= len(src) || src[end] != '}' {
+		return 0, 0, false
+	}
+	return start, end + 1, true
+}
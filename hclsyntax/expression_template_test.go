@@ -478,3 +478,177 @@ func TestTemplateExprIsStringLiteral(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateValuePreservingUnresolved(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"now": cty.StringVal("early"),
+			}),
+		},
+	}
+
+	tests := []struct {
+		input     string
+		want      cty.Value
+		diagCount int
+	}{
+		{
+			`hello ${name}`,
+			cty.StringVal("hello world"),
+			0,
+		},
+		{
+			`${var.now} then ${var.later}`,
+			cty.StringVal("early then ${var.later}"),
+			0,
+		},
+		{
+			`${later}`,
+			cty.StringVal("${later}"),
+			0,
+		},
+		{
+			`${name}`,
+			cty.StringVal("world"),
+			0,
+		},
+		{
+			`a ${ upper(later, "x") } b`,
+			cty.StringVal("a ${ upper(later, \"x\") } b"),
+			0,
+		},
+		{
+			"a  ${~ later ~}  b",
+			cty.StringVal("a${~ later ~}b"),
+			0,
+		},
+		{
+			`$${later} ${name}`,
+			cty.StringVal("${later} world"),
+			0,
+		},
+		{
+			`%{ if name == "world" }${later}%{ endif }`,
+			cty.NilVal,
+			1, // interpolations inside directives are not preserved
+		},
+		{
+			`%{ if later }yes%{ endif }`,
+			cty.NilVal,
+			1, // directives are always evaluated
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			src := []byte(test.input)
+			expr, diags := ParseTemplate(src, "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+
+			got, diags := TemplateValuePreservingUnresolved(expr, ctx, src)
+			if len(diags) != test.diagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.diagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+			if test.diagCount == 0 && !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTemplateExprStrict(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"str":     cty.StringVal("a"),
+			"num":     cty.NumberIntVal(1),
+			"flag":    cty.True,
+			"list":    cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			"unkFlag": cty.UnknownVal(cty.Bool),
+			"unk":     cty.DynamicVal,
+		},
+	}
+
+	tests := []struct {
+		input       string
+		want        cty.Value
+		wantDiag    string
+		wantLenient string
+	}{
+		{
+			`${str} ${num}`,
+			cty.StringVal("a 1"),
+			``,
+			``,
+		},
+		{
+			`${unk}!`,
+			cty.UnknownVal(cty.String).RefineNotNull(),
+			``,
+			``,
+		},
+		{
+			`flag is ${flag}`,
+			cty.StringVal("flag is true"),
+			``,
+			``,
+		},
+		{
+			`flag is ${unkFlag}`,
+			cty.UnknownVal(cty.String).Refine().NotNull().StringPrefixFull("flag is ").NewValue(),
+			``,
+			``,
+		},
+		{
+			`${list}!`,
+			cty.UnknownVal(cty.String).RefineNotNull(),
+			`:1,3-7: Invalid template interpolation value; Cannot include the given value in a string template: a string, number or bool is required in strict template mode, not list of string.`,
+			`:1,3-7: Invalid template interpolation value; Cannot include the given value in a string template: string required.`,
+		},
+		{
+			// An interpolation-only template returns the value unchanged,
+			// without converting it to a string.
+			`${flag}`,
+			cty.True,
+			``,
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseTemplate([]byte(test.input), "", hcl.InitialPos)
+			if parseDiags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", parseDiags.Error())
+			}
+
+			got, diags := expr.Value(ctx.WithStrictTemplates(true))
+			var gotDiag string
+			if len(diags) != 0 {
+				gotDiag = diags.Error()
+			}
+			if gotDiag != test.wantDiag {
+				t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", gotDiag, test.wantDiag)
+			}
+			if test.wantDiag == "" && !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+
+			// The default lenient mode is unchanged.
+			_, diags = expr.Value(ctx)
+			gotDiag = ""
+			if len(diags) != 0 {
+				gotDiag = diags.Error()
+			}
+			if gotDiag != test.wantLenient {
+				t.Errorf("wrong lenient diagnostics\ngot:  %s\nwant: %s", gotDiag, test.wantLenient)
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ package hclsyntax
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -53,6 +56,108 @@ func TestExpressionParseAndValue(t *testing.T) {
 			cty.NumberIntVal(1),
 			0,
 		},
+		{
+			`1_000_000`,
+			nil,
+			cty.NumberIntVal(1000000),
+			0,
+		},
+		{
+			`1_000.000_5`,
+			nil,
+			cty.MustParseNumberVal("1000.0005"),
+			0,
+		},
+		{
+			`1.5_5e1_0`,
+			nil,
+			cty.MustParseNumberVal("1.55e10"),
+			0,
+		},
+		{
+			`1_000+2_000`,
+			nil,
+			cty.NumberIntVal(3000),
+			0,
+		},
+		{
+			`[1_000][0]`,
+			nil,
+			cty.NumberIntVal(1000),
+			0,
+		},
+		{
+			`0xFF`,
+			nil,
+			cty.NumberIntVal(255),
+			0,
+		},
+		{
+			`0Xff + 0b1010`,
+			nil,
+			cty.NumberIntVal(265),
+			0,
+		},
+		{
+			`0B0`,
+			nil,
+			cty.NumberIntVal(0),
+			0,
+		},
+		{
+			`0xFFFF_FFFF_FFFF_FFFF_FFFF`,
+			nil,
+			cty.NumberVal(new(big.Float).SetInt(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 80), big.NewInt(1)))),
+			0,
+		},
+		{
+			`-0x8000_0000_0000_0000`,
+			nil,
+			cty.NumberIntVal(math.MinInt64),
+			0,
+		},
+		{
+			`[10, 20][0b1]`,
+			nil,
+			cty.NumberIntVal(20),
+			0,
+		},
+		{
+			`0x1G`,
+			nil,
+			cty.UnknownVal(cty.Number),
+			1, // Invalid hexadecimal digit
+		},
+		{
+			`0b102`,
+			nil,
+			cty.UnknownVal(cty.Number),
+			1, // Invalid binary digit
+		},
+		{
+			`0x`,
+			nil,
+			cty.UnknownVal(cty.Number),
+			1, // Invalid number literal
+		},
+		{
+			`1__000`,
+			nil,
+			cty.UnknownVal(cty.Number),
+			1, // Invalid digit separator
+		},
+		{
+			`1000_`,
+			nil,
+			cty.UnknownVal(cty.Number),
+			1, // Invalid digit separator
+		},
+		{
+			`1_.5`,
+			nil,
+			cty.UnknownVal(cty.Number),
+			1, // Invalid digit separator
+		},
 		{
 			`(2+unk)`,
 			&hcl.EvalContext{
@@ -297,6 +402,55 @@ upper(
 			cty.StringVal("FOO"),
 			0,
 		},
+		{
+			`upper("foo",)`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"upper": stdlib.UpperFunc,
+				},
+			},
+			cty.StringVal("FOO"),
+			0,
+		},
+		{
+			`
+concat(
+    ["a"],
+    ["b"],
+)
+`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"concat": stdlib.ConcatFunc,
+				},
+			},
+			cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			0,
+		},
+		{
+			`
+upper(
+    ["foo"]...,
+)
+`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"upper": stdlib.UpperFunc,
+				},
+			},
+			cty.StringVal("FOO"),
+			0,
+		},
+		{
+			`upper(["foo"]..., "bar")`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"upper": stdlib.UpperFunc,
+				},
+			},
+			cty.StringVal("FOO"), // "bar" is skipped during recovery
+			1,                    // expanded argument must be the last
+		},
 		{
 			`upper("foo", "bar")`,
 			&hcl.EvalContext{
@@ -1779,6 +1933,33 @@ EOT
 			0,
 		},
 
+		{
+			"<<EOT\r\nFoo\r\n${bar}\nBaz\r\nEOT\r\n", // intentional mixed line endings
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"bar": cty.StringVal("Bar"),
+				},
+			},
+			cty.StringVal("Foo\r\nBar\nBaz\r\n"),
+			0,
+		},
+		{
+			"[\r\n  <<-EOT\r\n  Foo\r\n\r\n    Bar\n  Baz\r\n  EOT\r\n]\r\n", // intentional mixed line endings
+			nil,
+			cty.TupleVal([]cty.Value{cty.StringVal("Foo\r\n\r\n  Bar\nBaz\r\n")}),
+			0,
+		},
+		{
+			"<<EOT\r\n${bar}EOT\r\nEOT\r\n", // marker is only recognized at the start of a line
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"bar": cty.StringVal("Bar"),
+				},
+			},
+			cty.StringVal("BarEOT\r\n"),
+			0,
+		},
+
 		{
 			`unk["baz"]`,
 			&hcl.EvalContext{
@@ -1955,6 +2136,88 @@ EOT
 			cty.DynamicVal,
 			0,
 		},
+		{
+			`true ? str : list`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"str":  cty.NullVal(cty.String),
+					"list": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				},
+			},
+			cty.NullVal(cty.List(cty.String)),
+			0,
+		},
+		{
+			`false ? str : list`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"str":  cty.NullVal(cty.String),
+					"list": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				},
+			},
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			0,
+		},
+		{
+			`true ? list : str`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"str":  cty.NullVal(cty.String).Mark("sensitive"),
+					"list": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				},
+			},
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			0,
+		},
+		{
+			`false ? list : str`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"str":  cty.NullVal(cty.String).Mark("sensitive"),
+					"list": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				},
+			},
+			cty.NullVal(cty.List(cty.String)).Mark("sensitive"),
+			0,
+		},
+		{
+			`unknown ? str : list`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"unknown": cty.UnknownVal(cty.Bool),
+					"str":     cty.NullVal(cty.String),
+					"list":    cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				},
+			},
+			cty.UnknownVal(cty.List(cty.String)),
+			0,
+		},
+		{
+			`true ? [] : list`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"list": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				},
+			},
+			cty.ListValEmpty(cty.String),
+			0,
+		},
+		{
+			`true ? {} : map`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"map": cty.MapVal(map[string]cty.Value{"a": cty.StringVal("a")}),
+				},
+			},
+			cty.MapValEmpty(cty.String),
+			0,
+		},
+		{
+			`true ? [] : [{ a = 1 }, { a = 2 }]`,
+			nil,
+			cty.ListValEmpty(cty.Object(map[string]cty.Type{"a": cty.Number})),
+			0,
+		},
 		{
 			`unknown ? 1 : 0`,
 			&hcl.EvalContext{
@@ -2156,79 +2419,277 @@ EOT
 			cty.ListVal([]cty.Value{cty.UnknownVal(cty.String)}), // deduced through refinements
 			0,
 		},
-		{ // marked conditional
-			`var.foo ? 1 : 0`,
+		{
+			`a ?? "default"`,
 			&hcl.EvalContext{
 				Variables: map[string]cty.Value{
-					"var": cty.ObjectVal(map[string]cty.Value{
-						"foo": cty.BoolVal(true),
-					}).Mark("sensitive"),
+					"a": cty.StringVal("given"),
 				},
 			},
-			cty.NumberIntVal(1),
+			cty.StringVal("given"),
 			0,
 		},
-		{ // auto-converts collection types
-			`true ? listOf1Tuple : listOf0Tuple`,
+		{
+			`a ?? "default"`,
 			&hcl.EvalContext{
 				Variables: map[string]cty.Value{
-					"listOf1Tuple": cty.ListVal([]cty.Value{cty.TupleVal([]cty.Value{cty.True})}),
-					"listOf0Tuple": cty.ListVal([]cty.Value{cty.EmptyTupleVal}),
+					"a": cty.NullVal(cty.String),
 				},
 			},
-			cty.ListVal([]cty.Value{cty.ListVal([]cty.Value{cty.True})}),
+			cty.StringVal("default"),
 			0,
 		},
 		{
-			`true ? setOf1Tuple : setOf0Tuple`,
+			`a ?? b ?? c`,
 			&hcl.EvalContext{
 				Variables: map[string]cty.Value{
-					"setOf1Tuple": cty.SetVal([]cty.Value{cty.TupleVal([]cty.Value{cty.True})}),
-					"setOf0Tuple": cty.SetVal([]cty.Value{cty.EmptyTupleVal}),
+					"a": cty.NullVal(cty.String),
+					"b": cty.NullVal(cty.Number),
+					"c": cty.True,
 				},
 			},
-			cty.SetVal([]cty.Value{cty.ListVal([]cty.Value{cty.True})}),
+			cty.True,
 			0,
 		},
-		{ // marked argument expansion
-			`min(xs...)`,
+		{ // right operand is not evaluated when the left is not null
+			`a ?? nonexist`,
 			&hcl.EvalContext{
-				Functions: map[string]function.Function{
-					"min": stdlib.MinFunc,
+				Variables: map[string]cty.Value{
+					"a": cty.NumberIntVal(1),
 				},
+			},
+			cty.NumberIntVal(1),
+			0,
+		},
+		{
+			`a ?? nonexist`,
+			&hcl.EvalContext{
 				Variables: map[string]cty.Value{
-					"xs": cty.ListVal([]cty.Value{
-						cty.NumberIntVal(3),
-						cty.NumberIntVal(1),
-						cty.NumberIntVal(4),
-					}).Mark("sensitive"),
+					"a": cty.NullVal(cty.Number),
+				},
+			},
+			cty.DynamicVal,
+			1, // Unknown variable
+		},
+		{
+			`a ?? 1`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.UnknownVal(cty.Number),
+				},
+			},
+			cty.DynamicVal,
+			0,
+		},
+		{
+			`a ?? 1`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.UnknownVal(cty.Number).RefineNotNull(),
+				},
+			},
+			cty.UnknownVal(cty.Number).RefineNotNull(),
+			0,
+		},
+		{
+			`a ?? 1`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.NullVal(cty.Number).Mark("sensitive"),
 				},
 			},
 			cty.NumberIntVal(1).Mark("sensitive"),
 			0,
 		},
 		{
-			`test ? sensitiveString : ""`,
+			`a ?? 1`,
 			&hcl.EvalContext{
-				Functions: map[string]function.Function{},
 				Variables: map[string]cty.Value{
-					"test":            cty.UnknownVal(cty.Bool),
-					"sensitiveString": cty.StringVal("test").Mark("sensitive"),
+					"a": cty.UnknownVal(cty.Number).Mark("sensitive"),
 				},
 			},
-			cty.UnknownVal(cty.String).RefineNotNull().Mark("sensitive"),
+			cty.DynamicVal.Mark("sensitive"),
 			0,
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.input, func(t *testing.T) {
-			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
-			var got cty.Value
-			var valDiags hcl.Diagnostics
-
-			if expr != nil {
-				got, valDiags = expr.Value(test.ctx)
+		{
+			`a ?? 1`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.UnknownVal(cty.Number).RefineNotNull().Mark("sensitive"),
+				},
+			},
+			cty.UnknownVal(cty.Number).RefineNotNull().Mark("sensitive"),
+			0,
+		},
+		{ // lower precedence than any other binary operator
+			`a ?? 1 + 2 == 3`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.NullVal(cty.Bool),
+				},
+			},
+			cty.True,
+			0,
+		},
+		{ // higher precedence than the conditional operator
+			`a ?? true ? "yes" : "no"`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.NullVal(cty.Bool),
+				},
+			},
+			cty.StringVal("yes"),
+			0,
+		},
+		{
+			`"${a ?? "b"}c"`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.NullVal(cty.String),
+				},
+			},
+			cty.StringVal("bc"),
+			0,
+		},
+		{ // right operand is not evaluated when the left decides the result
+			`false && nonexist`,
+			nil,
+			cty.False,
+			0,
+		},
+		{
+			`true || nonexist`,
+			nil,
+			cty.True,
+			0,
+		},
+		{
+			`true && nonexist`,
+			nil,
+			cty.UnknownVal(cty.Bool),
+			1, // Variables not allowed
+		},
+		{
+			`false || nonexist`,
+			nil,
+			cty.UnknownVal(cty.Bool),
+			1, // Variables not allowed
+		},
+		{
+			`a && b`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.False.Mark("sensitive"),
+				},
+			},
+			cty.False.Mark("sensitive"),
+			0,
+		},
+		{
+			`a && b`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.UnknownVal(cty.Bool),
+					"b": cty.True,
+				},
+			},
+			cty.UnknownVal(cty.Bool).RefineNotNull(),
+			0,
+		},
+		{
+			`a || b`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.UnknownVal(cty.Bool),
+					"b": cty.True,
+				},
+			},
+			cty.UnknownVal(cty.Bool).RefineNotNull(),
+			0,
+		},
+		{
+			`a || nonexist`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"a": cty.UnknownVal(cty.Bool),
+				},
+			},
+			cty.UnknownVal(cty.Bool),
+			1, // Unknown variable
+		},
+		{ // marked conditional
+			`var.foo ? 1 : 0`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"var": cty.ObjectVal(map[string]cty.Value{
+						"foo": cty.BoolVal(true),
+					}).Mark("sensitive"),
+				},
+			},
+			cty.NumberIntVal(1),
+			0,
+		},
+		{ // auto-converts collection types
+			`true ? listOf1Tuple : listOf0Tuple`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"listOf1Tuple": cty.ListVal([]cty.Value{cty.TupleVal([]cty.Value{cty.True})}),
+					"listOf0Tuple": cty.ListVal([]cty.Value{cty.EmptyTupleVal}),
+				},
+			},
+			cty.ListVal([]cty.Value{cty.ListVal([]cty.Value{cty.True})}),
+			0,
+		},
+		{
+			`true ? setOf1Tuple : setOf0Tuple`,
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"setOf1Tuple": cty.SetVal([]cty.Value{cty.TupleVal([]cty.Value{cty.True})}),
+					"setOf0Tuple": cty.SetVal([]cty.Value{cty.EmptyTupleVal}),
+				},
+			},
+			cty.SetVal([]cty.Value{cty.ListVal([]cty.Value{cty.True})}),
+			0,
+		},
+		{ // marked argument expansion
+			`min(xs...)`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"min": stdlib.MinFunc,
+				},
+				Variables: map[string]cty.Value{
+					"xs": cty.ListVal([]cty.Value{
+						cty.NumberIntVal(3),
+						cty.NumberIntVal(1),
+						cty.NumberIntVal(4),
+					}).Mark("sensitive"),
+				},
+			},
+			cty.NumberIntVal(1).Mark("sensitive"),
+			0,
+		},
+		{
+			`test ? sensitiveString : ""`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{},
+				Variables: map[string]cty.Value{
+					"test":            cty.UnknownVal(cty.Bool),
+					"sensitiveString": cty.StringVal("test").Mark("sensitive"),
+				},
+			},
+			cty.UnknownVal(cty.String).RefineNotNull().Mark("sensitive"),
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			var got cty.Value
+			var valDiags hcl.Diagnostics
+
+			if expr != nil {
+				got, valDiags = expr.Value(test.ctx)
 			}
 
 			diagCount := len(parseDiags) + len(valDiags)
@@ -2258,6 +2719,19 @@ func TestExpressionErrorMessages(t *testing.T) {
 		wantSummary string
 		wantDetail  string
 	}{
+		// Error messages describing null operands of operators.
+		{
+			"null < 1",
+			nil,
+			"Invalid operand",
+			"Unsuitable value for left operand: must not be null.",
+		},
+		{
+			"1 + null",
+			nil,
+			"Invalid operand",
+			"Unsuitable value for right operand: must not be null.",
+		},
 		// Error messages describing inconsistent result types for conditional expressions.
 		{
 			"true ? 1 : true",
@@ -2265,6 +2739,16 @@ func TestExpressionErrorMessages(t *testing.T) {
 			"Inconsistent conditional result types",
 			"The true and false result expressions must have consistent types. The 'true' value is number, but the 'false' value is bool.",
 		},
+		{
+			"true ? str : [1]",
+			&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"str": cty.StringVal("a"),
+				},
+			},
+			"Inconsistent conditional result types",
+			"The true and false result expressions must have consistent types. The 'true' value is string, but the 'false' value is tuple.",
+		},
 		{
 			"true ? [1] : [true]",
 			nil,
@@ -2376,6 +2860,308 @@ func TestExpressionErrorMessages(t *testing.T) {
 	}
 }
 
+func TestBinaryOpExprDiagnosticRanges(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantSubject hcl.Range
+		wantContext hcl.Range
+	}{
+		{
+			`"a" - 1`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 4, Byte: 3},
+			},
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 6, Byte: 5},
+			},
+		},
+		{
+			`1 + "b"`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 5, Byte: 4},
+				End:   hcl.Pos{Line: 1, Column: 8, Byte: 7},
+			},
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 3, Byte: 2},
+				End:   hcl.Pos{Line: 1, Column: 8, Byte: 7},
+			},
+		},
+		{
+			`null < 1`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 5, Byte: 4},
+			},
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 7, Byte: 6},
+			},
+		},
+		{
+			`1 + 2 * "c"`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 9, Byte: 8},
+				End:   hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			},
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 7, Byte: 6},
+				End:   hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			_, diags = expr.Value(nil)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			diag := diags[0]
+			if got, want := *diag.Subject, test.wantSubject; got != want {
+				t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := *diag.Context, test.wantContext; got != want {
+				t.Errorf("wrong context\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestBinaryOpExprNumberComparison(t *testing.T) {
+	// These pin down the exact results of comparisons in cases where
+	// comparing 64-bit floating point numbers would give a different answer.
+	tests := []struct {
+		input string
+		want  cty.Value
+	}{
+		{`0.1 + 0.2 == 0.3`, cty.True},
+		{`0.1 + 0.2 > 0.3`, cty.False},
+		{`0.1 * 3 == 0.3`, cty.True},
+		{`0.3 == 0.30000000000000001`, cty.False},
+		{`0.3 < 0.30000000000000001`, cty.True},
+		{`9007199254740993 == 9007199254740992`, cty.False},
+		{`9007199254740993 > 9007199254740992`, cty.True},
+		{`big == 9007199254740993`, cty.True},
+		{`big >= 9007199254740994`, cty.False},
+		{`1e400 > 1e399`, cty.True},
+		{`1 / 3 == 0.3333333333333333`, cty.False},
+		{`1 == 1.0`, cty.True},
+		{`null == 1`, cty.False},
+		{`null != 1`, cty.True},
+		{`null == null`, cty.True},
+		{`unknown == 1`, cty.UnknownVal(cty.Bool).RefineNotNull()},
+		{`unknown < 1`, cty.UnknownVal(cty.Bool).RefineNotNull()},
+		{`dynamic <= 1`, cty.UnknownVal(cty.Bool).RefineNotNull()},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"big":     cty.MustParseNumberVal("9007199254740993"),
+			"unknown": cty.UnknownVal(cty.Number),
+			"dynamic": cty.DynamicVal,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNumberLitDigitSeparatorDiagnostics(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantColumn int
+	}{
+		{`1__000`, 2},
+		{`1000_`, 5},
+		{`1_.5`, 2},
+		{`1.5_e3`, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, diags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Summary, "Invalid digit separator"; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+			want := hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: test.wantColumn, Byte: test.wantColumn - 1},
+				End:   hcl.Pos{Line: 1, Column: test.wantColumn + 1, Byte: test.wantColumn},
+			}
+			if got := *diags[0].Subject; got != want {
+				t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestNumberLitRadixDiagnostics(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantSummary string
+		wantDetail  string
+		wantStart   int
+		wantEnd     int
+	}{
+		{
+			`0x1G`,
+			"Invalid hexadecimal digit",
+			`The character 'G' is not a valid digit in a hexadecimal number literal, which may contain only the digits 0-9 and A-F.`,
+			3, 4,
+		},
+		{
+			`0b1012`,
+			"Invalid binary digit",
+			`The character '2' is not a valid digit in a binary number literal, which may contain only the digits 0 and 1.`,
+			5, 6,
+		},
+		{
+			`0xFF-1`,
+			"Invalid hexadecimal digit",
+			`The character '-' is not a valid digit in a hexadecimal number literal, which may contain only the digits 0-9 and A-F. To subtract, add spaces around the minus operator.`,
+			4, 5,
+		},
+		{
+			`0x1é`,
+			"Invalid hexadecimal digit",
+			`The character 'é' is not a valid digit in a hexadecimal number literal, which may contain only the digits 0-9 and A-F.`,
+			3, 5,
+		},
+		{
+			`0x_FF`,
+			"Invalid digit separator",
+			`An underscore in a hexadecimal number literal must be placed between two digits, as in 0x1111_0000.`,
+			2, 3,
+		},
+		{
+			`0B`,
+			"Invalid number literal",
+			`A binary number literal must have at least one digit after its 0B prefix.`,
+			0, 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, diags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Summary, test.wantSummary; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+			if got, want := diags[0].Detail, test.wantDetail; got != want {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+			}
+			if got := diags[0].Subject; got.Start.Byte != test.wantStart || got.End.Byte != test.wantEnd || got.Start.Column != test.wantStart+1 {
+				t.Errorf("wrong subject %#v; want bytes %d-%d", got, test.wantStart, test.wantEnd)
+			}
+		})
+	}
+}
+
+func TestFunctionCallExprArgErrorRange(t *testing.T) {
+	// checkPort validates only its second argument, returning an error that
+	// refers to that argument by index.
+	checkPort := function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "host", Type: cty.String},
+			{Name: "port", Type: cty.Number},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			port, _ := args[1].AsBigFloat().Int64()
+			if port < 1 || port > 65535 {
+				return cty.DynamicVal, function.NewArgErrorf(1, "must be between 1 and 65535")
+			}
+			return cty.StringVal(fmt.Sprintf("%s:%d", args[0].AsString(), port)), nil
+		},
+	})
+	// wrappedCheckPort is the same except that it wraps the argument error
+	// with some additional context.
+	wrappedCheckPort := function.New(&function.Spec{
+		Params: checkPort.Params(),
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			ret, err := checkPort.Call(args)
+			if err != nil {
+				return cty.DynamicVal, fmt.Errorf("invalid address: %w", err)
+			}
+			return ret, nil
+		},
+	})
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"check_port":         checkPort,
+			"wrapped_check_port": wrappedCheckPort,
+		},
+	}
+
+	tests := []struct {
+		input       string
+		wantSubject hcl.Range
+	}{
+		{
+			`check_port("localhost", 70000)`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 25, Byte: 24},
+				End:   hcl.Pos{Line: 1, Column: 30, Byte: 29},
+			},
+		},
+		{
+			`wrapped_check_port("localhost", 0)`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 33, Byte: 32},
+				End:   hcl.Pos{Line: 1, Column: 34, Byte: 33},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			_, diags = expr.Value(ctx)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			diag := diags[0]
+			if got, want := diag.Summary, "Invalid function argument"; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+			if got := *diag.Subject; got != test.wantSubject {
+				t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, test.wantSubject)
+			}
+			if got, want := *diag.Context, expr.Range(); got != want {
+				t.Errorf("wrong context\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
 func TestFunctionCallExprValue(t *testing.T) {
 	funcs := map[string]function.Function{
 		"length":     stdlib.StrlenFunc,
@@ -2680,3 +3466,503 @@ func TestParseExpression_incompleteFunctionCall(t *testing.T) {
 		})
 	}
 }
+
+func TestSplatExprNullIsError(t *testing.T) {
+	objTy := cty.Object(map[string]cty.Type{"x": cty.Number})
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"null_obj":  cty.NullVal(objTy),
+			"null_list": cty.NullVal(cty.List(objTy)),
+			"unknown":   cty.UnknownVal(objTy),
+			"obj":       cty.ObjectVal(map[string]cty.Value{"x": cty.NumberIntVal(1)}),
+		},
+	}
+
+	tests := []struct {
+		src        string
+		strict     bool
+		want       cty.Value
+		wantErrors bool
+	}{
+		{"null_obj[*].x", false, cty.EmptyTupleVal, false},
+		{"null_obj[*].x", true, cty.DynamicVal, true},
+		{"null_obj.*.x", true, cty.DynamicVal, true},
+		{"null_list[*].x", false, cty.DynamicVal, true},
+		{"null_list[*].x", true, cty.DynamicVal, true},
+		{"unknown[*].x", false, cty.DynamicVal, false},
+		{"unknown[*].x", true, cty.DynamicVal, false},
+		{"obj[*].x", false, cty.TupleVal([]cty.Value{cty.NumberIntVal(1)}), false},
+		{"obj[*].x", true, cty.TupleVal([]cty.Value{cty.NumberIntVal(1)}), false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s strict=%t", test.src, test.strict), func(t *testing.T) {
+			f, diags := ParseConfigWithOptions([]byte("a = "+test.src+"\n"), "", hcl.InitialPos, ParseOptions{
+				SplatNullIsError: test.strict,
+			})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+			expr := f.Body.(*Body).Attributes["a"].Expr
+
+			got, diags := expr.Value(ctx)
+			if diags.HasErrors() != test.wantErrors {
+				t.Errorf("wrong error status %t; want %t\n%s", diags.HasErrors(), test.wantErrors, diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplatExprSingleValue(t *testing.T) {
+	objTy := cty.Object(map[string]cty.Type{"x": cty.String})
+	obj := cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("a")})
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"obj":            obj,
+			"map":            cty.MapVal(map[string]cty.Value{"x": cty.StringVal("b")}),
+			"str":            cty.StringVal("c"),
+			"null_obj":       cty.NullVal(objTy),
+			"null_map":       cty.NullVal(cty.Map(cty.String)),
+			"null_dyn":       cty.NullVal(cty.DynamicPseudoType),
+			"unknown_obj":    cty.UnknownVal(objTy),
+			"unknown_map":    cty.UnknownVal(cty.Map(cty.String)),
+			"notnull_obj":    cty.UnknownVal(objTy).RefineNotNull(),
+			"marked_obj":     obj.Mark("sensitive"),
+			"marked_null":    cty.NullVal(objTy).Mark("sensitive"),
+			"marked_unknown": cty.UnknownVal(objTy).Mark("sensitive"),
+			"marked_list":    cty.ListValEmpty(objTy).Mark("sensitive"),
+		},
+	}
+
+	tests := []struct {
+		src  string
+		want cty.Value
+	}{
+		{"obj[*].x", cty.TupleVal([]cty.Value{cty.StringVal("a")})},
+		{"obj.*.x", cty.TupleVal([]cty.Value{cty.StringVal("a")})},
+		{"obj[*]", cty.TupleVal([]cty.Value{obj})},
+		{"map[*].x", cty.TupleVal([]cty.Value{cty.StringVal("b")})},
+		{"map[*]", cty.TupleVal([]cty.Value{cty.MapVal(map[string]cty.Value{"x": cty.StringVal("b")})})},
+		{"str[*]", cty.TupleVal([]cty.Value{cty.StringVal("c")})},
+		{"null_obj[*].x", cty.EmptyTupleVal},
+		{"null_map[*]", cty.EmptyTupleVal},
+		{"null_dyn[*]", cty.EmptyTupleVal},
+		{"unknown_obj[*].x", cty.DynamicVal},
+		{"unknown_map[*].x", cty.DynamicVal},
+		{"notnull_obj[*].x", cty.TupleVal([]cty.Value{cty.UnknownVal(cty.String)})},
+		{"marked_obj[*].x", cty.TupleVal([]cty.Value{cty.StringVal("a").Mark("sensitive")})},
+		{"marked_null[*].x", cty.EmptyTupleVal.Mark("sensitive")},
+		{"marked_unknown[*].x", cty.DynamicVal.Mark("sensitive")},
+		{"marked_list[*].x", cty.ListValEmpty(cty.String).Mark("sensitive")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+
+			got, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestForExprDuplicateKeyValueFormatter(t *testing.T) {
+	expr, parseDiags := ParseExpression([]byte(`{for v in vals : v => v}`), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if parseDiags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", parseDiags.Error())
+	}
+	vals := cty.TupleVal([]cty.Value{
+		cty.StringVal("secret").Mark("sensitive"),
+		cty.StringVal("secret").Mark("sensitive"),
+	})
+
+	tests := map[string]struct {
+		formatter func(cty.Value) string
+		want      string
+	}{
+		"default": {
+			nil,
+			`Two different items, with the keys 0 and 1 in the source collection, produced the key "secret" in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		"custom": {
+			func(v cty.Value) string {
+				if v.HasMark("sensitive") {
+					return "(sensitive value)"
+				}
+				return v.GoString()
+			},
+			`Two different items, with the keys cty.NumberIntVal(0) and cty.NumberIntVal(1) in the source collection, produced the key (sensitive value) in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := &hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"vals": vals,
+				},
+				ValueFormatter: test.formatter,
+			}
+			_, diags := expr.Value(ctx)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Detail; got != test.want {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestForExprDuplicateKeyRange(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"vals": cty.TupleVal([]cty.Value{
+				cty.StringVal("a"),
+				cty.StringVal("b"),
+				cty.StringVal("a"),
+			}),
+			"unknown": cty.UnknownVal(cty.String),
+		},
+	}
+
+	tests := []struct {
+		src         string
+		want        cty.Value
+		wantDiag    string
+		wantSubject hcl.Range
+	}{
+		{
+			`{for v in vals : v => v}`,
+			cty.DynamicVal,
+			`Two different items, with the keys 0 and 2 in the source collection, produced the key "a" in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 18, Byte: 17},
+				End:   hcl.Pos{Line: 1, Column: 19, Byte: 18},
+			},
+		},
+		{
+			`{for v in ["a", "b", "a"] : v => v}`,
+			cty.DynamicVal,
+			`Two different items, with the keys 0 and 2 in the source collection, produced the key "a" in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 22, Byte: 21},
+				End:   hcl.Pos{Line: 1, Column: 25, Byte: 24},
+			},
+		},
+		{
+			`{for v in ["a", "b"] : unknown => v}`,
+			cty.DynamicVal,
+			``,
+			hcl.Range{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if parseDiags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", parseDiags.Error())
+			}
+			got, diags := expr.Value(ctx)
+			if test.wantDiag == "" {
+				if len(diags) != 0 {
+					t.Fatalf("unexpected diagnostics: %s", diags.Error())
+				}
+				if !got.RawEquals(test.want) {
+					t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Detail; got != test.wantDiag {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.wantDiag)
+			}
+			if got := *diags[0].Subject; got != test.wantSubject {
+				t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, test.wantSubject)
+			}
+		})
+	}
+}
+
+func TestFunctionCallExprFunctionPolicy(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+			"lower": stdlib.LowerFunc,
+		},
+		FunctionPolicy: hcl.AllowedFunctions("upper"),
+	}
+
+	tests := []struct {
+		input      string
+		want       cty.Value
+		wantDetail string
+	}{
+		{
+			`upper("a")`,
+			cty.StringVal("A"),
+			"",
+		},
+		{
+			// Rejected even though the function is in the context.
+			`lower("A")`,
+			cty.DynamicVal,
+			`The function "lower" cannot be called here: it is not one of the functions permitted in this context.`,
+		},
+		{
+			// Rejected by the policy before being reported as unknown.
+			`file("secrets.txt")`,
+			cty.DynamicVal,
+			`The function "file" cannot be called here: it is not one of the functions permitted in this context.`,
+		},
+		{
+			`upper(lower("A"))`,
+			cty.DynamicVal,
+			`The function "lower" cannot be called here: it is not one of the functions permitted in this context.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.InitialPos)
+			if parseDiags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", parseDiags.Error())
+			}
+
+			got, diags := expr.Value(ctx)
+			if test.wantDetail == "" {
+				if len(diags) != 0 {
+					t.Fatalf("unexpected diagnostics: %s", diags.Error())
+				}
+			} else {
+				if len(diags) != 1 {
+					t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+				}
+				if got, want := diags[0].Summary, "Function not permitted"; got != want {
+					t.Errorf("wrong summary %q; want %q", got, want)
+				}
+				if got := diags[0].Detail; got != test.wantDetail {
+					t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.wantDetail)
+				}
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExpressionExhaustiveDiagnostics(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"obj": cty.EmptyObjectVal,
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+		},
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			`nope(obj.a, missing)`,
+			[]string{
+				"Call to unknown function",
+				"Unsupported attribute",
+				"Unknown variable",
+			},
+		},
+		{
+			`upper("a", obj.a)`,
+			[]string{
+				"Too many function arguments",
+				"Unsupported attribute",
+			},
+		},
+		{
+			`upper(obj.a, missing...)`,
+			[]string{
+				"Unknown variable",
+				"Unsupported attribute",
+			},
+		},
+		{
+			`missing ? "a" : {}`,
+			[]string{
+				"Inconsistent conditional result types",
+				"Unknown variable",
+			},
+		},
+		{
+			// Arguments are still not passed to the function if any of
+			// them have errors, to avoid cascading errors.
+			`upper(obj.a)`,
+			[]string{
+				"Unsupported attribute",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.InitialPos)
+			if parseDiags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", parseDiags.Error())
+			}
+
+			_, diags := expr.Value(ctx)
+			if got, want := len(diags), 1; got != want {
+				t.Errorf("got %d diagnostics by default; want %d\n%s", got, want, diags.Error())
+			}
+
+			_, diags = expr.Value(ctx.WithExhaustiveDiagnostics(true))
+			var got []string
+			for _, diag := range diags {
+				got = append(got, diag.Summary)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong exhaustive diagnostics\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExprAsLiteral(t *testing.T) {
+	tests := []struct {
+		input string
+		want  cty.Value // cty.NilVal if the expression is not a literal
+	}{
+		{`1`, cty.NumberIntVal(1)},
+		{`-1.5`, cty.NumberFloatVal(-1.5)},
+		{`true`, cty.True},
+		{`null`, cty.NullVal(cty.DynamicPseudoType)},
+		{`(1)`, cty.NumberIntVal(1)},
+		{`"hello"`, cty.StringVal("hello")},
+		{"<<EOT\nhello\nEOT\n", cty.StringVal("hello\n")},
+		{`[]`, cty.EmptyTupleVal},
+		{`{}`, cty.EmptyObjectVal},
+		{
+			`["a", 1, [true]]`,
+			cty.TupleVal([]cty.Value{
+				cty.StringVal("a"),
+				cty.NumberIntVal(1),
+				cty.TupleVal([]cty.Value{cty.True}),
+			}),
+		},
+		{
+			`{a = 1, "b c" = "d", 2 = {e = null}}`,
+			cty.ObjectVal(map[string]cty.Value{
+				"a":   cty.NumberIntVal(1),
+				"b c": cty.StringVal("d"),
+				"2": cty.ObjectVal(map[string]cty.Value{
+					"e": cty.NullVal(cty.DynamicPseudoType),
+				}),
+			}),
+		},
+		{`a`, cty.NilVal},
+		{`"${a}"`, cty.NilVal},
+		{`"a${"b"}"`, cty.NilVal},
+		{`1 + 1`, cty.NilVal},
+		{`upper("a")`, cty.NilVal},
+		{`[1, a]`, cty.NilVal},
+		{`{a = b}`, cty.NilVal},
+		{`{(a) = 1}`, cty.NilVal},
+		{`{null = 1}`, cty.ObjectVal(map[string]cty.Value{"null": cty.NumberIntVal(1)})},
+		{`{(null) = 1}`, cty.NilVal},
+		{`-"a"`, cty.NilVal},
+		{`!true`, cty.NilVal},
+		{`{[] = 1}`, cty.NilVal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.input), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			got, ok := hcl.ExprAsLiteral(expr)
+			if test.want == cty.NilVal {
+				if ok {
+					t.Fatalf("unexpected literal %#v", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("not a literal; want %#v", test.want)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+
+			// The result must always match evaluation.
+			val, diags := expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics from Value: %s", diags.Error())
+			}
+			if !got.RawEquals(val) {
+				t.Errorf("result differs from Value\ngot:  %#v\nwant: %#v", got, val)
+			}
+		})
+	}
+}
+
+func BenchmarkObjectConsExprValue(b *testing.B) {
+	var buf strings.Builder
+	buf.WriteString("{\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&buf, "  key%d = %d\n", i, i)
+	}
+	buf.WriteString("}\n")
+	expr, diags := ParseExpression([]byte(buf.String()), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		b.Fatal(diags.Error())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, diags := expr.Value(nil)
+		if diags.HasErrors() {
+			b.Fatal(diags.Error())
+		}
+	}
+}
+
+func BenchmarkTupleConsExprValue(b *testing.B) {
+	var buf strings.Builder
+	buf.WriteString("[\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&buf, "  \"item%d\",\n", i)
+	}
+	buf.WriteString("]\n")
+	expr, diags := ParseExpression([]byte(buf.String()), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		b.Fatal(diags.Error())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, diags := expr.Value(nil)
+		if diags.HasErrors() {
+			b.Fatal(diags.Error())
+		}
+	}
+}
@@ -18,6 +18,10 @@ func (e *BinaryOpExpr) Variables() []hcl.Traversal {
 	return Variables(e)
 }
 
+func (e *CoalesceExpr) Variables() []hcl.Traversal {
+	return Variables(e)
+}
+
 func (e *ConditionalExpr) Variables() []hcl.Traversal {
 	return Variables(e)
 }
@@ -2,10 +2,11 @@
 // SPDX-License-Identifier: MPL-2.0
 
 // This is a 'go generate'-oriented program for producing the "Variables"
-// method on every Expression implementation found within this package.
-// All expressions share the same implementation for this method, which
-// just wraps the package-level function "Variables" and uses an AST walk
-// to do its work.
+// and "Value" methods on every Expression implementation found within this
+// package. All expressions share the same implementation for each of these
+// methods. "Variables" just wraps the package-level function "Variables" and
+// uses an AST walk to do its work, while "Value" wraps the expression's own
+// unexported "value" method to notify any tracer in the EvalContext.
 
 //go:build ignore
 // +build ignore
@@ -30,7 +31,7 @@ func main() {
 	}
 	pkg := pkgs["hclsyntax"]
 
-	// Walk all the files and collect the receivers of any "Value" methods
+	// Walk all the files and collect the receivers of any "value" methods
 	// that look like they are trying to implement Expression.
 	var recvs []string
 	for _, f := range pkg.Files {
@@ -39,7 +40,7 @@ func main() {
 			if !ok {
 				continue
 			}
-			if fd.Name.Name != "Value" {
+			if fd.Name.Name != "value" {
 				continue
 			}
 			results := fd.Type.Results.List
@@ -53,7 +54,7 @@ func main() {
 				continue
 			}
 
-			// If we have a method called Value and it returns something in
+			// If we have a method called value and it returns something in
 			// "cty" followed by something in "hcl" then that's specific enough
 			// for now, even though this is not 100% exact as a correct
 			// implementation of Value.
@@ -73,21 +74,38 @@ func main() {
 
 	sort.Strings(recvs)
 
-	of, err := os.OpenFile("expression_vars.go", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	writeMethods("expression_vars.go", varsPreamble, varsMethodFmt, recvs)
+	writeMethods("expression_value.go", valuePreamble, valueMethodFmt, recvs)
+}
+
+func writeMethods(filename, preamble, methodFmt string, recvs []string) {
+	of, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to open output file: %s\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Fprint(of, outputPreamble)
+	fmt.Fprint(of, preamble)
 	for _, recv := range recvs {
-		fmt.Fprintf(of, outputMethodFmt, recv)
+		fmt.Fprintf(of, methodFmt, recv)
 	}
 	fmt.Fprint(of, "\n")
-
+	of.Close()
 }
 
-const outputPreamble = `// Copyright (c) HashiCorp, Inc.
+const varsPreamble = `// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+// Generated by expression_vars_get.go. DO NOT EDIT.
+// Run 'go generate' on this package to update the set of functions here.
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)`
+
+const valuePreamble = `// Copyright (c) HashiCorp, Inc.
 // SPDX-License-Identifier: MPL-2.0
 
 package hclsyntax
@@ -97,10 +115,20 @@ package hclsyntax
 
 import (
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 )`
 
-const outputMethodFmt = `
+const varsMethodFmt = `
 
 func (e %s) Variables() []hcl.Traversal {
 	return Variables(e)
 }`
+
+const valueMethodFmt = `
+
+func (e %s) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if tracer := ctx.Tracer(); tracer != nil {
+		return traceValue(tracer, e, ctx, e.value)
+	}
+	return e.value(ctx)
+}`
@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// FoldConstants returns an expression equivalent to the given expression
+// but with each of its constant sub-expressions replaced by a
+// LiteralValueExpr containing its value.
+//
+// A sub-expression is constant if it does not refer to any variables and
+// calls only functions that appear in the given map, which must therefore
+// contain only functions whose results depend on nothing but their
+// arguments. The functions are also used to evaluate the constant
+// sub-expressions. Each replacement covers the full source range of the
+// sub-expression it replaces, so diagnostics from later evaluation still
+// refer to the relevant part of the source.
+//
+// The given expression is not modified. Any nodes that contain replaced
+// sub-expressions are copied, and any other nodes are shared between the
+// given and returned expressions.
+//
+// A constant sub-expression that produces errors, or whose value is not
+// wholly known, is not replaced. Any error diagnostics from evaluating
+// constant sub-expressions are returned so that callers can report them
+// early, but they are also returned again when the result is evaluated.
+func FoldConstants(expr Expression, funcs map[string]function.Function) (Expression, hcl.Diagnostics) {
+	f := &constantFolder{
+		ctx:   &hcl.EvalContext{Functions: funcs},
+		funcs: funcs,
+	}
+	ret := f.fold(expr)
+	return ret, f.diags
+}
+
+type constantFolder struct {
+	ctx   *hcl.EvalContext
+	funcs map[string]function.Function
+	diags hcl.Diagnostics
+}
+
+func (f *constantFolder) fold(expr Expression) Expression {
+	if _, isLit := expr.(*LiteralValueExpr); isLit {
+		return expr
+	}
+	if f.isConstant(expr) {
+		val, diags := expr.Value(f.ctx)
+		if diags.HasErrors() {
+			// Its constant sub-expressions would only produce the same
+			// errors again, so there's nothing more to fold here.
+			f.diags = append(f.diags, diags...)
+			return expr
+		}
+		if val.IsWhollyKnown() {
+			return &LiteralValueExpr{
+				Val:      val,
+				SrcRange: expr.Range(),
+			}
+		}
+	}
+
+	// If the expression as a whole isn't constant then some of its
+	// sub-expressions might still be.
+	switch e := expr.(type) {
+	case *BinaryOpExpr:
+		ne := *e
+		ne.LHS = f.fold(e.LHS)
+		ne.RHS = f.fold(e.RHS)
+		return &ne
+	case *CoalesceExpr:
+		ne := *e
+		ne.LHS = f.fold(e.LHS)
+		ne.RHS = f.fold(e.RHS)
+		return &ne
+	case *UnaryOpExpr:
+		ne := *e
+		ne.Val = f.fold(e.Val)
+		return &ne
+	case *ConditionalExpr:
+		ne := *e
+		ne.Condition = f.fold(e.Condition)
+		ne.TrueResult = f.fold(e.TrueResult)
+		ne.FalseResult = f.fold(e.FalseResult)
+		return &ne
+	case *FunctionCallExpr:
+		ne := *e
+		ne.Args = f.foldAll(e.Args)
+		return &ne
+	case *TupleConsExpr:
+		ne := *e
+		ne.Exprs = f.foldAll(e.Exprs)
+		return &ne
+	case *ObjectConsExpr:
+		// The keys are left as-is because a naked identifier in a key is
+		// interpreted as a literal string only within an ObjectConsKeyExpr.
+		ne := *e
+		ne.Items = make([]ObjectConsItem, len(e.Items))
+		for i, item := range e.Items {
+			ne.Items[i] = ObjectConsItem{
+				KeyExpr:   item.KeyExpr,
+				ValueExpr: f.fold(item.ValueExpr),
+			}
+		}
+		return &ne
+	case *IndexExpr:
+		ne := *e
+		ne.Collection = f.fold(e.Collection)
+		ne.Key = f.fold(e.Key)
+		return &ne
+	case *RelativeTraversalExpr:
+		ne := *e
+		ne.Source = f.fold(e.Source)
+		return &ne
+	case *SplatExpr:
+		// The "Each" expression refers to the current element via an
+		// AnonSymbolExpr, so it can only be evaluated as part of the splat.
+		ne := *e
+		ne.Source = f.fold(e.Source)
+		return &ne
+	case *ForExpr:
+		// The other expressions are evaluated once per element, with the
+		// iterator symbols in scope.
+		ne := *e
+		ne.CollExpr = f.fold(e.CollExpr)
+		return &ne
+	case *TemplateExpr:
+		ne := *e
+		ne.Parts = f.foldAll(e.Parts)
+		return &ne
+	case *TemplateWrapExpr:
+		ne := *e
+		ne.Wrapped = f.fold(e.Wrapped)
+		return &ne
+	case *ParenthesesExpr:
+		ne := *e
+		ne.Expression = f.fold(e.Expression)
+		return &ne
+	default:
+		return expr
+	}
+}
+
+func (f *constantFolder) foldAll(exprs []Expression) []Expression {
+	if exprs == nil {
+		return nil
+	}
+	ret := make([]Expression, len(exprs))
+	for i, expr := range exprs {
+		ret[i] = f.fold(expr)
+	}
+	return ret
+}
+
+// isConstant returns true if the given expression refers to no variables
+// and calls only the folder's functions.
+func (f *constantFolder) isConstant(expr Expression) bool {
+	if _, isAnon := expr.(*AnonSymbolExpr); isAnon {
+		return false
+	}
+	return IsStatic(expr, f.funcs)
+}
+
+// IsStatic returns true if the given expression is static, meaning that it
+// doesn't refer to any variables and calls only functions that appear in
+// the given map. Local symbols declared within the expression itself, such
+// as the iterator symbols of a for expression, don't count as variables.
+//
+// Unlike a LiteralValueExpr, a static expression can include operations
+// such as arithmetic, templates and for expressions. Its result depends only
+// on its source and on the given functions, which the caller must therefore
+// ensure depend on nothing but their arguments, and so it can be evaluated
+// once and its result cached.
+//
+// A static expression can be evaluated with a nil EvalContext only if it
+// calls no functions at all, which is what IsStatic checks when the given
+// map is nil.
+//
+// Evaluating a static expression may still produce error diagnostics, such
+// as for an operation on values of the wrong type.
+func IsStatic(expr Expression, funcs map[string]function.Function) bool {
+	if len(Variables(expr)) != 0 {
+		return false
+	}
+	static := true
+	VisitAll(expr, func(node Node) hcl.Diagnostics {
+		if call, isCall := node.(*FunctionCallExpr); isCall {
+			if _, pure := funcs[call.Name]; !pure {
+				static = false
+			}
+		}
+		return nil
+	})
+	return static
+}
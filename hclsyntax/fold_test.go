@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestFoldConstants(t *testing.T) {
+	funcs := map[string]function.Function{
+		"upper": stdlib.UpperFunc,
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+			"list": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+			"lower": stdlib.LowerFunc,
+		},
+	}
+
+	tests := []struct {
+		src      string
+		wantLit  bool // whether the whole expression folds to a literal
+		wantVal  cty.Value
+		wantDiag bool
+	}{
+		{`1 + 2 * 3`, true, cty.NumberIntVal(7), false},
+		{`"hello ${"wor"}${"ld"}"`, true, cty.StringVal("hello world"), false},
+		{`upper("a")`, true, cty.StringVal("A"), false},
+		{`lower("A")`, false, cty.StringVal("a"), false},
+		{`name`, false, cty.StringVal("world"), false},
+		{`"hello ${upper(name)} ${1 + 1}"`, false, cty.StringVal("hello WORLD 2"), false},
+		{`[for x in [1, 2]: x * 2]`, true, cty.TupleVal([]cty.Value{cty.NumberIntVal(2), cty.NumberIntVal(4)}), false},
+		{`[for x in list: "${x}${1 + 1}"]`, false, cty.TupleVal([]cty.Value{cty.StringVal("a2"), cty.StringVal("b2")}), false},
+		{`[{a = 1}, {a = 2}][*].a`, true, cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}), false},
+		{`{ name = 1 + 1 }`, true, cty.ObjectVal(map[string]cty.Value{"name": cty.NumberIntVal(2)}), false},
+		{`true ? name : "x"`, false, cty.StringVal("world"), false},
+		{`"a" + 1`, false, cty.DynamicVal, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			folded, diags := FoldConstants(expr, funcs)
+			if got := diags.HasErrors(); got != test.wantDiag {
+				t.Fatalf("wrong error state %t; want %t\n%s", got, test.wantDiag, diags.Error())
+			}
+			lit, isLit := folded.(*LiteralValueExpr)
+			if isLit != test.wantLit {
+				t.Fatalf("wrong result type %T", folded)
+			}
+			if isLit && lit.SrcRange != expr.Range() {
+				t.Errorf("wrong range\ngot:  %#v\nwant: %#v", lit.SrcRange, expr.Range())
+			}
+			if test.wantDiag {
+				return
+			}
+
+			got, diags := folded.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors evaluating result: %s", diags.Error())
+			}
+			if !got.RawEquals(test.wantVal) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.wantVal)
+			}
+		})
+	}
+}
+
+func TestFoldConstantsPartial(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`name + (2 * 3)`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	folded, diags := FoldConstants(expr, nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+	op, ok := folded.(*BinaryOpExpr)
+	if !ok {
+		t.Fatalf("result is %T, not *BinaryOpExpr", folded)
+	}
+	if _, ok := op.LHS.(*ScopeTraversalExpr); !ok {
+		t.Errorf("LHS is %T, not *ScopeTraversalExpr", op.LHS)
+	}
+	rhs, ok := op.RHS.(*LiteralValueExpr)
+	if !ok {
+		t.Fatalf("RHS is %T, not *LiteralValueExpr", op.RHS)
+	}
+	if !rhs.Val.RawEquals(cty.NumberIntVal(6)) {
+		t.Errorf("wrong RHS value %#v", rhs.Val)
+	}
+	if got, want := rhs.SrcRange, expr.(*BinaryOpExpr).RHS.Range(); got != want {
+		t.Errorf("wrong RHS range\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if _, ok := expr.(*BinaryOpExpr).RHS.(*LiteralValueExpr); ok {
+		t.Errorf("original expression was modified")
+	}
+}
+
+func TestFoldConstantsErrorsOnce(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`name + (((1 + "a") * 2) - 3)`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	folded, diags := FoldConstants(expr, nil)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Invalid operand"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := folded.(*BinaryOpExpr).RHS, expr.(*BinaryOpExpr).RHS; got != want {
+		t.Errorf("erroring sub-expression was replaced with %#v", got)
+	}
+}
+
+func TestIsStatic(t *testing.T) {
+	funcs := map[string]function.Function{
+		"upper": stdlib.UpperFunc,
+	}
+
+	tests := []struct {
+		src       string
+		want      bool
+		wantFuncs bool // the result when given funcs
+	}{
+		{`1`, true, true},
+		{`1 + 2 * 3`, true, true},
+		{`"hello ${"world"}"`, true, true},
+		{`[for x in [1, 2]: x * 2]`, true, true},
+		{`{for k, v in {a = 1}: k => v if v > 0}`, true, true},
+		{`[{a = 1}][*].a`, true, true},
+		{`true ? 1 : 2`, true, true},
+		{`name`, false, false},
+		{`[for x in list: x]`, false, false},
+		{`[for x in [1]: y]`, false, false},
+		{`upper("a")`, false, true},
+		{`lower("a")`, false, false},
+		{`"${upper("a")} ${lower("b")}"`, false, false},
+		{`upper(name)`, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			if got := IsStatic(expr, nil); got != test.want {
+				t.Errorf("wrong result without functions %t; want %t", got, test.want)
+			}
+			if got := IsStatic(expr, funcs); got != test.wantFuncs {
+				t.Errorf("wrong result with functions %t; want %t", got, test.wantFuncs)
+			}
+			if test.want {
+				if _, diags := expr.Value(nil); diags.HasErrors() {
+					t.Errorf("unexpected errors evaluating with nil context: %s", diags.Error())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Functions returns the names of all of the functions called within a given
+// expression, deduplicated and sorted lexically.
+//
+// Calls nested anywhere in the expression tree are included, such as those
+// within conditional branches, for expressions, and template interpolations.
+// Namespaced function names are returned in their full "::"-separated form,
+// exactly as they would appear as keys in hcl.EvalContext.Functions.
+func Functions(expr Expression) []string {
+	seen := make(map[string]struct{})
+
+	walker := &functionsWalker{
+		Callback: func(name string) {
+			seen[name] = struct{}{}
+		},
+	}
+
+	Walk(expr, walker)
+
+	if len(seen) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// functionsWalker is a Walker implementation that calls its callback for
+// the name of each function call found while walking.
+type functionsWalker struct {
+	Callback func(string)
+}
+
+func (w *functionsWalker) Enter(n Node) hcl.Diagnostics {
+	if call, ok := n.(*FunctionCallExpr); ok {
+		w.Callback(call.Name)
+	}
+	return nil
+}
+
+func (w *functionsWalker) Exit(n Node) hcl.Diagnostics {
+	return nil
+}
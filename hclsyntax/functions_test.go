@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		Src  string
+		Want []string
+	}{
+		{
+			`1`,
+			nil,
+		},
+		{
+			`foo.bar`,
+			nil,
+		},
+		{
+			`upper("a")`,
+			[]string{"upper"},
+		},
+		{
+			`upper(lower("a"))`,
+			[]string{"lower", "upper"},
+		},
+		{
+			`max(1, 2) + max(3, 4)`,
+			[]string{"max"},
+		},
+		{
+			`cond ? upper(a) : lower(b)`,
+			[]string{"lower", "upper"},
+		},
+		{
+			`[for k, v in keys(foo) : tostring(v) if length(k) > 1]`,
+			[]string{"keys", "length", "tostring"},
+		},
+		{
+			`{ for k, v in foo : lower(k) => upper(v) }`,
+			[]string{"lower", "upper"},
+		},
+		{
+			`"hello ${title(name)}%{ if enabled() }!%{ endif }"`,
+			[]string{"enabled", "title"},
+		},
+		{
+			`foo[index(bar, "a")].baz[*].qux`,
+			[]string{"index"},
+		},
+		{
+			`provider::aws::arn_parse(x)`,
+			[]string{"provider::aws::arn_parse"},
+		},
+		{
+			`(zipmap(ks, vs))`,
+			[]string{"zipmap"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.Src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			got := Functions(expr)
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
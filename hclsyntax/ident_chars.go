@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// identCharsForbidden are the characters that delimit other syntax in a way
+// that would make the language ambiguous if they were allowed in
+// identifiers, and so cannot be used with ParseOptions.ExtraIdentifierChars.
+const identCharsForbidden = `[](){}.,="#`
+
+// validateExtraIdentChars returns an error diagnostic for each character in
+// the given extra identifier characters that cannot be used in an
+// identifier. The diagnostics are reported at the given start of the source,
+// since they relate to the parsing options rather than to the source itself.
+func validateExtraIdentChars(extra string, filename string, start hcl.Pos) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, r := range extra {
+		if unicode.IsSpace(r) || strings.ContainsRune(identCharsForbidden, r) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid identifier character option",
+				Detail:   fmt.Sprintf("The character %q cannot be used as an extra identifier character, because it delimits other syntax.", r),
+				Subject: &hcl.Range{
+					Filename: filename,
+					Start:    start,
+					End:      start,
+				},
+			})
+		}
+	}
+	return diags
+}
+
+// mergeExtraIdentChars returns a copy of the given tokens where each
+// identifier that is immediately followed by one or more of the given extra
+// identifier characters, along with the identifier characters that follow
+// them, has been replaced with a single identifier token. For example, with
+// the extra character ":" the tokens for foo:bar become a single identifier
+// "foo:bar".
+//
+// The caller must first use validateExtraIdentChars to check that extra
+// includes only characters that can be used in an identifier.
+func mergeExtraIdentChars(tokens Tokens, extra string) Tokens {
+	isExtra := func(tok Token) bool {
+		switch tok.Type {
+		case TokenIdent, TokenNewline, TokenEOF:
+			return false
+		}
+		r, size := utf8.DecodeRune(tok.Bytes)
+		return size > 0 && size == len(tok.Bytes) && strings.ContainsRune(extra, r)
+	}
+	isContinue := func(tok Token) bool {
+		switch tok.Type {
+		case TokenIdent:
+			return true
+		case TokenNumberLit:
+			// Digits and exponent markers are valid identifier characters,
+			// but decimal points and explicit exponent signs are not.
+			return !strings.ContainsAny(string(tok.Bytes), ".+")
+		default:
+			return isExtra(tok)
+		}
+	}
+
+	ret := make(Tokens, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Type != TokenIdent {
+			ret = append(ret, tok)
+			continue
+		}
+
+		// We'll consume all of the adjacent tokens that can continue the
+		// identifier, as long as the first of them is an extra character.
+		// Otherwise the scanner would already have included it in the
+		// identifier token.
+		end := i
+		for end+1 < len(tokens) {
+			next := tokens[end+1]
+			if next.Range.Start.Byte != tokens[end].Range.End.Byte {
+				break // separated by whitespace
+			}
+			if !isContinue(next) || (end == i && !isExtra(next)) {
+				break
+			}
+			end++
+		}
+		if end == i {
+			ret = append(ret, tok)
+			continue
+		}
+
+		var buf []byte
+		for _, part := range tokens[i : end+1] {
+			buf = append(buf, part.Bytes...)
+		}
+		ret = append(ret, Token{
+			Type:  TokenIdent,
+			Bytes: buf,
+			Range: hcl.RangeBetween(tok.Range, tokens[end].Range),
+		})
+		i = end
+	}
+	return ret
+}
@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// LexConfigIncremental updates the result of an earlier call to LexConfig
+// after an edit that replaces the bytes covered by the given range of the
+// previous source with the given replacement text, returning the new source
+// along with its tokens and diagnostics.
+//
+// Rather than scanning the whole of the new source, only the lines around
+// the edit are scanned again, starting and ending at the boundaries between
+// lines that are not inside any template or heredoc. The previous tokens
+// outside of that region are reused, with their ranges adjusted to account
+// for any change in length and line count. If the edit affects how the
+// remainder of the file is scanned, such as by opening or closing a quoted
+// string, then the whole of the new source is scanned instead, so the
+// result is always identical to the result of calling LexConfig with the
+// new source.
+//
+// The given tokens must be the complete result of calling LexConfig with
+// the given source and start position, and must not have been modified. Only the byte offsets
+// of the edit range are used, and they are interpreted in the same way as
+// the byte offsets of the token ranges. The tokens of the previous result
+// may share memory with the returned tokens, so callers should not retain
+// the previous source or tokens for any other purpose.
+func LexConfigIncremental(prevSrc []byte, prevTokens Tokens, start hcl.Pos, edit hcl.Range, replacement []byte) ([]byte, Tokens, hcl.Diagnostics) {
+	if len(prevTokens) == 0 || prevTokens[len(prevTokens)-1].Type != TokenEOF {
+		panic("LexConfigIncremental requires a complete token sequence")
+	}
+	eof := prevTokens[len(prevTokens)-1]
+	filename := eof.Range.Filename
+
+	base := start.Byte
+
+	editStart, editEnd := edit.Start.Byte-base, edit.End.Byte-base
+	if editStart < 0 || editEnd < editStart || editEnd > len(prevSrc) {
+		panic("LexConfigIncremental edit range is outside of the previous source")
+	}
+	src := make([]byte, 0, len(prevSrc)-(editEnd-editStart)+len(replacement))
+	src = append(src, prevSrc[:editStart]...)
+	src = append(src, replacement...)
+	src = append(src, prevSrc[editEnd:]...)
+	delta := len(src) - len(prevSrc)
+
+	full := func() ([]byte, Tokens, hcl.Diagnostics) {
+		tokens, diags := LexConfig(src, filename, start)
+		return src, tokens, diags
+	}
+	if eof.Range.End.Byte-base != len(prevSrc) {
+		// The tokens don't match the source, so we can't reuse them.
+		return full()
+	}
+	for _, tok := range prevTokens {
+		if tok.Range.End.Byte-base < editStart || tok.Range.Start.Byte-base > editEnd {
+			continue
+		}
+		switch tok.Type {
+		case TokenComment, TokenOQuote, TokenCQuote, TokenOHeredoc, TokenCHeredoc:
+			// The edit touches the boundary of a comment or string, and so
+			// might change where it ends.
+			return full()
+		}
+	}
+
+	// We can resume scanning only where the scanner is at the start of a
+	// line and outside of any template, since then its state doesn't depend
+	// on anything before that point. We'll use the last such point before
+	// the edit and the first such point after it, each with one extra line
+	// of margin in case the edit interacts with the adjacent tokens.
+	safe := safeLexResumePoints(prevTokens)
+	before, after := -1, -1
+	for i, idx := range safe {
+		end := prevTokens[idx].Range.End.Byte - base
+		if end <= editStart {
+			before = i
+		}
+		if end > editEnd && after == -1 {
+			after = i
+		}
+	}
+	if before < 1 || after == -1 || after+1 >= len(safe) {
+		// The edit is too close to the start or end of the file for there
+		// to be any benefit to scanning incrementally.
+		return full()
+	}
+	resumeIdx := safe[before-1]
+	syncIdx := safe[after+1]
+	if hasUnterminatedComment(prevTokens[:resumeIdx+1]) {
+		// The edit might add a "*/" that terminates the comment, which
+		// would change how everything between the two is scanned.
+		return full()
+	}
+
+	resumePos := prevTokens[resumeIdx].Range.End
+	syncOld := prevTokens[syncIdx].Range.End
+	chunkStart := resumePos.Byte - base
+	chunkEnd := syncOld.Byte - base + delta
+	if bytes.HasPrefix(src[chunkStart:], utf8BOM) {
+		// The scanner would skip a byte order mark at the start of the
+		// chunk, which is incorrect in the middle of a file.
+		return full()
+	}
+
+	chunk := scanTokens(src[chunkStart:chunkEnd], filename, resumePos, scanNormal, false, nil)
+	chunk = chunk[:len(chunk)-1] // discard the EOF token
+	if len(chunk) == 0 {
+		return full()
+	}
+	if safe := safeLexResumePoints(chunk); len(safe) == 0 || safe[len(safe)-1] != len(chunk)-1 {
+		// The edit has changed which parts of the source are inside
+		// templates, and so the scanner's state at the end of the chunk is
+		// different than before.
+		return full()
+	}
+	if hasUnterminatedComment(chunk) {
+		// The comment might be terminated by a "*/" after the end of the
+		// chunk.
+		return full()
+	}
+	syncNew := chunk[len(chunk)-1].Range.End
+	lineDelta := syncNew.Line - syncOld.Line
+
+	tokens := make(Tokens, 0, resumeIdx+1+len(chunk)+len(prevTokens)-syncIdx-1)
+	tokens = append(tokens, prevTokens[:resumeIdx+1]...)
+	tokens = append(tokens, chunk...)
+	for _, tok := range prevTokens[syncIdx+1:] {
+		// All of these tokens are on lines after the end of the chunk, so
+		// their columns are unchanged.
+		tok.Range.Start.Byte += delta
+		tok.Range.Start.Line += lineDelta
+		tok.Range.End.Byte += delta
+		tok.Range.End.Line += lineDelta
+		tok.Bytes = src[tok.Range.Start.Byte-base : tok.Range.End.Byte-base]
+		tokens = append(tokens, tok)
+	}
+	for i := range tokens[:resumeIdx+1] {
+		tok := &tokens[i]
+		tok.Bytes = src[tok.Range.Start.Byte-base : tok.Range.End.Byte-base]
+	}
+
+	return src, tokens, checkInvalidTokens(tokens)
+}
+
+// safeLexResumePoints returns the indices of the tokens after which the
+// scanner is at the start of a line and outside of any template or heredoc,
+// and so could resume scanning without any other state.
+func safeLexResumePoints(tokens Tokens) []int {
+	var ret []int
+	depth := 0
+	for i, tok := range tokens {
+		switch tok.Type {
+		case TokenOQuote, TokenOHeredoc:
+			depth++
+		case TokenCQuote, TokenCHeredoc:
+			if depth > 0 {
+				depth--
+			}
+		case TokenNewline:
+			if depth == 0 {
+				ret = append(ret, i)
+			}
+		case TokenComment:
+			if depth == 0 && bytes.HasSuffix(tok.Bytes, []byte{'\n'}) {
+				ret = append(ret, i)
+			}
+		}
+	}
+	return ret
+}
+
+// hasUnterminatedComment returns true if the given tokens include the start
+// of an unterminated block comment, which the scanner produces as separate
+// operator tokens.
+func hasUnterminatedComment(tokens Tokens) bool {
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i].Type == TokenStar && tokens[i-1].Type == TokenSlash && tokens[i-1].Range.End.Byte == tokens[i].Range.Start.Byte {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestLexConfigIncremental(t *testing.T) {
+	srcs := []string{`# leading comment
+a = 1
+b = "hello ${name}"
+
+block "label" {
+  c = <<EOT
+  heredoc ${with} %{ if true }interp%{ endif }
+EOT
+  d = [
+    1, 2, // trailing
+  ]
+  /* block
+  comment */
+  e = "${"nested ${"template"}"}"
+}
+
+f = true
+g = {
+  h = null
+}
+`,
+		"  \n/* unterminated\na = 1\n\nb = 2\n\nc = \"c\"\n\nd = 4\n\ne = 5\n",
+	}
+	edits := []string{"", "x", "\n", "\"", "${", "}", "<<EOT\n", "EOT\n", "#", "/*", "*/"}
+
+	for _, src := range srcs {
+		testLexConfigIncremental(t, src, edits)
+	}
+}
+
+func testLexConfigIncremental(t *testing.T, src string, edits []string) {
+	for start := 0; start <= len(src); start++ {
+		for _, length := range []int{0, 1, 5} {
+			end := start + length
+			if end > len(src) {
+				continue
+			}
+			for _, repl := range edits {
+				if length == 0 && repl == "" {
+					continue
+				}
+				name := fmt.Sprintf("%d-%d %q", start, end, repl)
+				prevTokens, _ := LexConfig([]byte(src), "test.hcl", hcl.InitialPos)
+				edit := hcl.Range{
+					Filename: "test.hcl",
+					Start:    hcl.Pos{Byte: start},
+					End:      hcl.Pos{Byte: end},
+				}
+				gotSrc, gotTokens, gotDiags := LexConfigIncremental([]byte(src), prevTokens, hcl.InitialPos, edit, []byte(repl))
+
+				wantSrc := src[:start] + repl + src[end:]
+				wantTokens, wantDiags := LexConfig([]byte(wantSrc), "test.hcl", hcl.InitialPos)
+
+				if string(gotSrc) != wantSrc {
+					t.Fatalf("%s: wrong source\ngot:  %q\nwant: %q", name, gotSrc, wantSrc)
+				}
+				if !reflect.DeepEqual(gotTokens, wantTokens) {
+					t.Fatalf("%s: wrong tokens\ngot:  %#v\nwant: %#v", name, gotTokens, wantTokens)
+				}
+				if len(gotDiags) != len(wantDiags) {
+					t.Fatalf("%s: wrong diagnostics\ngot:  %s\nwant: %s", name, gotDiags.Error(), wantDiags.Error())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+
+	"github.com/apparentlymart/go-textseg/v15/textseg"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// captureLineValues returns a copy of the given tokens where the value of
+// each argument definition that begins its own line in a body, and that
+// isn't a valid expression written entirely on that line, has been replaced
+// with a single TokenStringLit token containing the remainder of the line,
+// as described for ParseOptions.UnquotedLineValues.
+//
+// The given scan function must produce tokens for the given part of src in
+// the same way as the tokens were originally produced, and is used to
+// re-scan the parts of the source whose original tokens were confused by
+// the text that has been captured, such as by an unterminated quote.
+func captureLineValues(src []byte, start hcl.Pos, tokens Tokens, scan func([]byte, hcl.Pos) Tokens) Tokens {
+	ret := make(Tokens, 0, len(tokens))
+
+	// braces has an element for each open brace that hasn't yet been closed,
+	// which is true if the brace begins a block body rather than an object
+	// constructor expression.
+	var braces []bool
+	// depth is the nesting depth of brackets, quotes and template sequences
+	// within expressions, which is zero only at the level of a body.
+	depth := 0
+	lineStart := true
+	sawEqual := false
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if lineStart && depth == 0 && tok.Type == TokenIdent && i+2 < len(tokens) && tokens[i+1].Type == TokenEqual {
+			if captured, rest, ok := captureLineValue(src, start, tokens[i+2:], scan); ok {
+				ret = append(ret, tok, tokens[i+1], captured)
+				tokens = rest
+				i = -1
+				lineStart = false
+				sawEqual = true
+				continue
+			}
+		}
+
+		ret = append(ret, tok)
+		switch tok.Type {
+		case TokenNewline:
+			if depth == 0 {
+				lineStart = true
+				sawEqual = false
+			}
+			continue
+		case TokenComment:
+			// Single-line comments include their terminating newline.
+			if depth == 0 && bytes.HasSuffix(tok.Bytes, []byte{'\n'}) {
+				lineStart = true
+				sawEqual = false
+			}
+			continue
+		case TokenEqual:
+			if depth == 0 {
+				sawEqual = true
+			}
+		case TokenOBrace:
+			isBody := depth == 0 && !sawEqual
+			braces = append(braces, isBody)
+			if !isBody {
+				depth++
+			}
+		case TokenCBrace:
+			if n := len(braces); n > 0 {
+				if !braces[n-1] && depth > 0 {
+					depth--
+				}
+				braces = braces[:n-1]
+			}
+		case TokenOParen, TokenOBrack, TokenOQuote, TokenOHeredoc, TokenTemplateInterp, TokenTemplateControl:
+			depth++
+		case TokenCParen, TokenCBrack, TokenCQuote, TokenCHeredoc, TokenTemplateSeqEnd:
+			if depth > 0 {
+				depth--
+			}
+		}
+		lineStart = false
+	}
+
+	return ret
+}
+
+// captureLineValue decides whether the argument value beginning with the
+// first of the given tokens should be captured as the remainder of its line.
+// If so, it returns the token for the captured value along with the tokens
+// for the source that follows it.
+func captureLineValue(src []byte, start hcl.Pos, tokens Tokens, scan func([]byte, hcl.Pos) Tokens) (Token, Tokens, bool) {
+	first := tokens[0]
+	switch first.Type {
+	case TokenNewline, TokenComment, TokenEOF, TokenOQuote, TokenOHeredoc:
+		return Token{}, nil, false
+	}
+
+	startOfs := first.Range.Start.Byte - start.Byte
+	endOfs := len(src)
+	if nl := bytes.IndexByte(src[startOfs:], '\n'); nl >= 0 {
+		endOfs = startOfs + nl
+	}
+	raw := bytes.TrimRight(src[startOfs:endOfs], " \t\r")
+	if lineValueIsExpression(scan(raw, first.Range.Start)) {
+		return Token{}, nil, false
+	}
+
+	end := first.Range.Start
+	end.Byte += len(raw)
+	chars, _ := textseg.TokenCount(raw, textseg.ScanGraphemeClusters)
+	end.Column += chars
+	captured := Token{
+		Type:  TokenStringLit,
+		Bytes: raw,
+		Range: hcl.Range{
+			Filename: first.Range.Filename,
+			Start:    first.Range.Start,
+			End:      end,
+		},
+	}
+
+	// We can keep the original tokens for the rest of the source if they
+	// continue with the newline that ends the line, since otherwise the
+	// scanner was confused by the captured text, such as by taking an
+	// unterminated quote to continue onto the next line.
+	for i, tok := range tokens {
+		if tok.Range.Start.Byte < end.Byte {
+			continue
+		}
+		if (tok.Type == TokenNewline || tok.Type == TokenEOF) && tokens[i-1].Range.End.Byte <= end.Byte {
+			return captured, tokens[i:], true
+		}
+		break
+	}
+	return captured, scan(src[end.Byte-start.Byte:], end), true
+}
+
+// lineValueIsExpression returns true if the given tokens, scanned from the
+// remainder of a line after an equals sign, are a valid expression or the
+// beginning of an expression that continues onto later lines.
+func lineValueIsExpression(tokens Tokens) bool {
+	if checkInvalidTokens(tokens).HasErrors() {
+		return false
+	}
+
+	depth := 0
+	for _, tok := range tokens {
+		switch tok.Type {
+		case TokenOParen, TokenOBrack, TokenOBrace:
+			depth++
+		case TokenCParen, TokenCBrack, TokenCBrace:
+			depth--
+		}
+	}
+	if depth > 0 {
+		return true
+	}
+
+	p := &parser{peeker: newPeeker(tokens, false)}
+	p.PushIncludeNewlines(false)
+	_, diags := p.ParseExpression()
+	next := p.Peek()
+	p.PopIncludeNewlines()
+	return !diags.HasErrors() && next.Type == TokenEOF
+}
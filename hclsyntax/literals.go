@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// StringLiteral is a string that appears literally in the source code of an
+// expression, as returned by StringLiterals.
+type StringLiteral struct {
+	// Value is the string, after any escape sequences have been processed.
+	Value string
+
+	// Range is the source range of the literal, which does not include any
+	// quotes or interpolation markers around it.
+	Range hcl.Range
+}
+
+// StringLiterals returns all of the non-empty strings that appear literally
+// anywhere within the given expression, including in the literal parts of
+// templates and in the arguments of function calls, in the order they
+// appear in the source. Interpolated parts of templates are not included,
+// although any literals nested within their expressions are.
+//
+// This is intended for tools that inspect configuration without evaluating
+// it, such as to find secrets that have been written directly into
+// configuration files. Names written as bare identifiers, such as the
+// attribute names in an object constructor, are not string literals.
+func StringLiterals(expr Expression) []StringLiteral {
+	var ret []StringLiteral
+	VisitAll(expr, func(node Node) hcl.Diagnostics {
+		lit, ok := node.(*LiteralValueExpr)
+		if !ok {
+			return nil
+		}
+		val := lit.Val
+		if val.Type() != cty.String || val.IsNull() || !val.IsKnown() {
+			return nil
+		}
+		val, _ = val.Unmark()
+		if s := val.AsString(); s != "" {
+			ret = append(ret, StringLiteral{
+				Value: s,
+				Range: lit.SrcRange,
+			})
+		}
+		return nil
+	})
+	return ret
+}
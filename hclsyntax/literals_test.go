@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{
+			`"hello"`,
+			[]string{"hello"},
+		},
+		{
+			`1 + 2`,
+			nil,
+		},
+		{
+			`"a ${b} c"`,
+			[]string{"a ", " c"},
+		},
+		{
+			`"a ${upper("b")} \"c\""`,
+			[]string{"a ", "b", ` "c"`},
+		},
+		{
+			`{ foo = "bar", "baz" = [lookup(x, "key", "default")] }`,
+			[]string{"bar", "baz", "key", "default"},
+		},
+		{
+			`"%{ if x }yes%{ endif }"`,
+			[]string{"yes"},
+		},
+		{
+			`[for k, v in x : "${k}=${v}" if v != ""]`,
+			[]string{"="},
+		},
+		{
+			"<<EOT\nsecret ${x}\nEOT\n",
+			[]string{"secret ", "\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			var got []string
+			for _, lit := range StringLiterals(expr) {
+				got = append(got, lit.Value)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStringLiteralsRange(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`f("abc")`), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	got := StringLiterals(expr)
+	want := []StringLiteral{
+		{
+			Value: "abc",
+			Range: hcl.Range{
+				Filename: "test.hcl",
+				Start:    hcl.Pos{Line: 1, Column: 4, Byte: 3},
+				End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -6,6 +6,7 @@ package hclsyntax
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"strconv"
 	"unicode/utf8"
 
@@ -22,6 +23,46 @@ type parser struct {
 	// in recovery mode, assuming that the recovery heuristics have failed
 	// in this case and left the peeker in a wrong place.
 	recovery bool
+
+	// maxDepth is the maximum number of nested bodies and expression terms
+	// the parser will descend into before giving up with an error. Zero
+	// selects DefaultMaxNestingDepth.
+	maxDepth int
+
+	// depth is the current nesting depth, counted against maxDepth.
+	depth int
+
+	// recoverItems enables a recovery strategy that skips only the current
+	// line after encountering an invalid body item, rather than the rest of
+	// the body, so that later items can still be parsed. It also re-enables
+	// error reporting at the start of each subsequent item.
+	recoverItems bool
+
+	// splatNullIsError is used to populate SplatExpr.NullIsError for all
+	// splat expressions produced by the parser.
+	splatNullIsError bool
+
+	// exactIntegers causes integer number literals to be given exactly as
+	// much precision as they need, rather than the usual fixed precision.
+	exactIntegers bool
+
+	// duplicateAttrs is the policy for handling an argument that is set
+	// more than once in the same body.
+	duplicateAttrs DuplicateAttributePolicy
+
+	// warnBareLabels causes a warning for each block label that is written
+	// as an identifier rather than as a quoted string.
+	warnBareLabels bool
+
+	// colonAssignment is the policy for an argument definition that uses a
+	// colon rather than an equals sign.
+	colonAssignment ColonAssignmentPolicy
+}
+
+// isAssignToken returns true if the given token introduces the value of an
+// argument definition.
+func (p *parser) isAssignToken(tok Token) bool {
+	return tok.Type == TokenEqual || (tok.Type == TokenColon && p.colonAssignment != ColonAssignmentError)
 }
 
 func (p *parser) ParseBody(end TokenType) (*Body, hcl.Diagnostics) {
@@ -32,6 +73,25 @@ func (p *parser) ParseBody(end TokenType) (*Body, hcl.Diagnostics) {
 	startRange := p.PrevRange()
 	var endRange hcl.Range
 
+	if p.depth >= p.maxNestingDepth() {
+		diags = append(diags, p.nestingTooDeep(p.NextRange()))
+		p.recover(end)
+		endRange = p.PrevRange()
+		return &Body{
+			Attributes: attrs,
+			Blocks:     blocks,
+
+			SrcRange: hcl.RangeBetween(startRange, endRange),
+			EndRange: hcl.Range{
+				Filename: endRange.Filename,
+				Start:    endRange.End,
+				End:      endRange.End,
+			},
+		}, diags
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
 Token:
 	for {
 		next := p.Peek()
@@ -46,6 +106,11 @@ Token:
 			p.Read()
 			continue
 		case TokenIdent:
+			if p.recoverItems {
+				// We're at the start of a new item, so any earlier recovery
+				// has succeeded and we can report errors in this one.
+				p.recovery = false
+			}
 			item, itemDiags := p.ParseBodyItem()
 			diags = append(diags, itemDiags...)
 			switch titem := item.(type) {
@@ -53,15 +118,10 @@ Token:
 				blocks = append(blocks, titem)
 			case *Attribute:
 				if existing, exists := attrs[titem.Name]; exists {
-					diags = append(diags, &hcl.Diagnostic{
-						Severity: hcl.DiagError,
-						Summary:  "Attribute redefined",
-						Detail: fmt.Sprintf(
-							"The argument %q was already set at %s. Each argument may be set only once.",
-							titem.Name, existing.NameRange.String(),
-						),
-						Subject: &titem.NameRange,
-					})
+					diags = append(diags, p.attributeRedefined(existing, titem))
+					if p.duplicateAttrs == DuplicateAttributesLastWins {
+						attrs[titem.Name] = titem
+					}
 				} else {
 					attrs[titem.Name] = titem
 				}
@@ -118,6 +178,13 @@ Token:
 					})
 				}
 			}
+			if p.recoverItems && bad.Type != TokenEOF {
+				// Skip only the remainder of the current line, so that any
+				// later items in this body can still be parsed.
+				p.recoverAfterBodyItem()
+				continue
+			}
+
 			endRange = p.PrevRange() // arbitrary, but somewhere inside the body means better diagnostics
 
 			p.recover(end) // attempt to recover to the token after the end of this body
@@ -138,6 +205,36 @@ Token:
 	}, diags
 }
 
+// attributeRedefined returns a diagnostic reporting that the argument
+// defined by dup was already defined by existing, whose severity and
+// wording depend on the parser's duplicate attribute policy.
+func (p *parser) attributeRedefined(existing, dup *Attribute) *hcl.Diagnostic {
+	diag := &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Attribute redefined",
+		Subject:  &dup.NameRange,
+	}
+	switch p.duplicateAttrs {
+	case DuplicateAttributesLastWins:
+		diag.Detail = fmt.Sprintf(
+			"The argument %q was already set at %s. This definition overrides the earlier one.",
+			dup.Name, existing.NameRange.String(),
+		)
+	case DuplicateAttributesFirstWins:
+		diag.Detail = fmt.Sprintf(
+			"The argument %q was already set at %s. This definition is ignored.",
+			dup.Name, existing.NameRange.String(),
+		)
+	default:
+		diag.Severity = hcl.DiagError
+		diag.Detail = fmt.Sprintf(
+			"The argument %q was already set at %s. Each argument may be set only once.",
+			dup.Name, existing.NameRange.String(),
+		)
+	}
+	return diag
+}
+
 func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 	ident := p.Read()
 	if ident.Type != TokenIdent {
@@ -154,9 +251,11 @@ func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 
 	next := p.Peek()
 
-	switch next.Type {
-	case TokenEqual:
+	if p.isAssignToken(next) {
 		return p.finishParsingBodyAttribute(ident, false)
+	}
+
+	switch next.Type {
 	case TokenOQuote, TokenOBrace, TokenIdent:
 		return p.finishParsingBodyBlock(ident)
 	default:
@@ -195,12 +294,12 @@ func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
 
 	next := p.Peek()
 
-	switch next.Type {
-	case TokenEqual:
+	switch {
+	case p.isAssignToken(next):
 		node, attrDiags := p.finishParsingBodyAttribute(ident, true)
 		diags = append(diags, attrDiags...)
 		attr = node.(*Attribute)
-	case TokenOQuote, TokenOBrace, TokenIdent:
+	case next.Type == TokenOQuote || next.Type == TokenOBrace || next.Type == TokenIdent:
 		p.recoverAfterBodyItem()
 		return nil, hcl.Diagnostics{
 			{
@@ -239,14 +338,26 @@ func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
 
 func (p *parser) finishParsingBodyAttribute(ident Token, singleLine bool) (Node, hcl.Diagnostics) {
 	eqTok := p.Read() // eat equals token
-	if eqTok.Type != TokenEqual {
+	if !p.isAssignToken(eqTok) {
 		// should never happen if caller behaves
 		panic("finishParsingBodyAttribute called with next not equals")
 	}
 
+	var diags hcl.Diagnostics
+	if eqTok.Type == TokenColon && p.colonAssignment == ColonAssignmentWarning {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated colon in argument definition",
+			Detail:   "Argument definitions should use the equals sign \"=\" rather than a colon to introduce the argument value.",
+			Subject:  &eqTok.Range,
+			Context:  hcl.RangeBetween(ident.Range, eqTok.Range).Ptr(),
+		})
+	}
+
 	var endRange hcl.Range
 
-	expr, diags := p.ParseExpression()
+	expr, exprDiags := p.ParseExpression()
+	diags = append(diags, exprDiags...)
 	if p.recovery && diags.HasErrors() {
 		// recovery within expressions tends to be tricky, so we've probably
 		// landed somewhere weird. We'll try to reset to the start of a body
@@ -326,6 +437,15 @@ Token:
 			label, labelRange := string(tok.Bytes), tok.Range
 			labels = append(labels, label)
 			labelRanges = append(labelRanges, labelRange)
+			if p.warnBareLabels {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Deprecated unquoted block label",
+					Detail:   fmt.Sprintf("Block labels should be written as quoted strings. To silence this warning, write this label as %q.", label),
+					Subject:  &labelRange,
+					Context:  hcl.RangeBetween(ident.Range, labelRange).Ptr(),
+				})
+			}
 
 		default:
 			switch tok.Type {
@@ -492,7 +612,7 @@ func (p *parser) parseTernaryConditional() (Expression, hcl.Diagnostics) {
 	var condExpr, trueExpr, falseExpr Expression
 	var diags hcl.Diagnostics
 
-	condExpr, condDiags := p.parseBinaryOps(binaryOps)
+	condExpr, condDiags := p.parseCoalesce()
 	diags = append(diags, condDiags...)
 	if p.recovery && condDiags.HasErrors() {
 		return condExpr, diags
@@ -540,6 +660,35 @@ func (p *parser) parseTernaryConditional() (Expression, hcl.Diagnostics) {
 	}, diags
 }
 
+// parseCoalesce parses the null coalescing operator (.. ?? ..), which has
+// a lower precedence than any of the operators in binaryOps but is otherwise
+// parsed in the same way, with left-to-right associativity.
+func (p *parser) parseCoalesce() (Expression, hcl.Diagnostics) {
+	lhs, diags := p.parseBinaryOps(binaryOps)
+	if p.recovery && diags.HasErrors() {
+		return lhs, diags
+	}
+
+	for p.Peek().Type == TokenNullCoalesce {
+		opRange := p.Read().Range // eat operator token
+		rhs, rhsDiags := p.parseBinaryOps(binaryOps)
+		diags = append(diags, rhsDiags...)
+		if p.recovery && rhsDiags.HasErrors() {
+			return lhs, diags
+		}
+
+		lhs = &CoalesceExpr{
+			LHS: lhs,
+			RHS: rhs,
+
+			SrcRange:    hcl.RangeBetween(lhs.Range(), rhs.Range()),
+			SymbolRange: opRange,
+		}
+	}
+
+	return lhs, diags
+}
+
 // parseBinaryOps calls itself recursively to work through all of the
 // operator precedence groups, and then eventually calls parseExpressionTerm
 // for each operand.
@@ -554,6 +703,7 @@ func (p *parser) parseBinaryOps(ops []map[TokenType]*Operation) (Expression, hcl
 
 	var lhs, rhs Expression
 	var operation *Operation
+	var opRange hcl.Range
 	var diags hcl.Diagnostics
 
 	// Parse a term that might be the first operand of a binary
@@ -588,12 +738,13 @@ func (p *parser) parseBinaryOps(ops []map[TokenType]*Operation) (Expression, hcl
 				Op:  operation,
 				RHS: rhs,
 
-				SrcRange: hcl.RangeBetween(lhs.Range(), rhs.Range()),
+				SrcRange:    hcl.RangeBetween(lhs.Range(), rhs.Range()),
+				SymbolRange: opRange,
 			}
 		}
 
 		operation = newOp
-		p.Read() // eat operator token
+		opRange = p.Read().Range // eat operator token
 		var rhsDiags hcl.Diagnostics
 		rhs, rhsDiags = p.parseBinaryOps(remaining)
 		diags = append(diags, rhsDiags...)
@@ -611,7 +762,8 @@ func (p *parser) parseBinaryOps(ops []map[TokenType]*Operation) (Expression, hcl
 		Op:  operation,
 		RHS: rhs,
 
-		SrcRange: hcl.RangeBetween(lhs.Range(), rhs.Range()),
+		SrcRange:    hcl.RangeBetween(lhs.Range(), rhs.Range()),
+		SymbolRange: opRange,
 	}, diags
 }
 
@@ -796,6 +948,8 @@ Traversal:
 					Each:   travExpr,
 					Item:   itemExpr,
 
+					NullIsError: p.splatNullIsError,
+
 					SrcRange:    hcl.RangeBetween(from.Range(), lastRange),
 					MarkerRange: hcl.RangeBetween(dot.Range, marker.Range),
 				}
@@ -855,6 +1009,8 @@ Traversal:
 					Each:   travExpr,
 					Item:   itemExpr,
 
+					NullIsError: p.splatNullIsError,
+
 					SrcRange:    hcl.RangeBetween(from.Range(), travExpr.Range()),
 					MarkerRange: hcl.RangeBetween(open.Range, close.Range),
 				}
@@ -944,6 +1100,20 @@ func makeRelativeTraversal(expr Expression, next hcl.Traverser, rng hcl.Range) E
 func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 	start := p.Peek()
 
+	if p.depth >= p.maxNestingDepth() {
+		diags := hcl.Diagnostics{p.nestingTooDeep(start.Range)}
+
+		// Return a placeholder so that the AST is still structurally sound.
+		// The caller is responsible for recovering past whatever nested
+		// construct begins here.
+		return &LiteralValueExpr{
+			Val:      cty.DynamicVal,
+			SrcRange: start.Range,
+		}, diags
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
 	switch start.Type {
 	case TokenOParen:
 		oParen := p.Read() // eat open paren
@@ -987,6 +1157,31 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 
 		return expr, diags
 
+	case TokenRawLit:
+		tok := p.Read() // eat raw string token
+
+		// The token includes its delimiters, but the closing one is absent
+		// if the string is unterminated; the scanner has already reported
+		// that as an error.
+		raw := tok.Bytes[1:]
+		if len(raw) > 0 && raw[len(raw)-1] == '`' {
+			raw = raw[:len(raw)-1]
+		}
+		return &LiteralValueExpr{
+			Val:      cty.StringVal(string(raw)),
+			SrcRange: tok.Range,
+		}, nil
+
+	case TokenStringLit:
+		// A string literal token appears directly in an expression only for
+		// a value captured by ParseOptions.UnquotedLineValues, and contains
+		// the captured text verbatim.
+		tok := p.Read() // eat string token
+		return &LiteralValueExpr{
+			Val:      cty.StringVal(string(tok.Bytes)),
+			SrcRange: tok.Range,
+		}, nil
+
 	case TokenNumberLit:
 		tok := p.Read() // eat number token
 
@@ -1123,10 +1318,51 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 }
 
 func (p *parser) numberLitValue(tok Token) (cty.Value, hcl.Diagnostics) {
+	src := tok.Bytes
+	if len(src) >= 2 && src[0] == '0' {
+		switch src[1] {
+		case 'x', 'X':
+			return radixNumberLitValue(tok, 16, "hexadecimal", "0-9 and A-F")
+		case 'b', 'B':
+			return radixNumberLitValue(tok, 2, "binary", "0 and 1")
+		}
+	}
+	if bytes.IndexByte(src, '_') >= 0 {
+		// Underscores are permitted only as separators between two digits,
+		// and have no effect on the value.
+		for i, c := range src {
+			if c != '_' {
+				continue
+			}
+			if i > 0 && isDigit(src[i-1]) && i+1 < len(src) && isDigit(src[i+1]) {
+				continue
+			}
+			// Number literals are always ASCII and on a single line, so
+			// we can find the underscore's position by counting bytes.
+			rng := numberLitCharRange(tok, i, 1)
+			return cty.UnknownVal(cty.Number), hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid digit separator",
+					Detail:   "An underscore in a number literal must be placed between two digits, as in 1_000_000.",
+					Subject:  &rng,
+				},
+			}
+		}
+		src = bytes.ReplaceAll(src, []byte{'_'}, nil)
+	}
+
+	if p.exactIntegers && isAllDigits(src) {
+		// Setting a big.Float from a big.Int with no precision selected
+		// uses the precision needed to represent the integer exactly.
+		bi, _ := new(big.Int).SetString(string(src), 10)
+		return cty.NumberVal(new(big.Float).SetInt(bi)), nil
+	}
+
 	// The cty.ParseNumberVal is always the same behavior as converting a
 	// string to a number, ensuring we always interpret decimal numbers in
 	// the same way.
-	numVal, err := cty.ParseNumberVal(string(tok.Bytes))
+	numVal, err := cty.ParseNumberVal(string(src))
 	if err != nil {
 		ret := cty.UnknownVal(cty.Number)
 		return ret, hcl.Diagnostics{
@@ -1143,6 +1379,94 @@ func (p *parser) numberLitValue(tok Token) (cty.Value, hcl.Diagnostics) {
 	return numVal, nil
 }
 
+// radixNumberLitValue returns the value of a number literal written in the
+// given base with a two-character prefix, such as 0xFF.
+func radixNumberLitValue(tok Token, base int, baseName, digitsDesc string) (cty.Value, hcl.Diagnostics) {
+	src := tok.Bytes
+	digits := src[2:]
+	if len(digits) == 0 {
+		return cty.UnknownVal(cty.Number), hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid number literal",
+				Detail:   fmt.Sprintf("A %s number literal must have at least one digit after its %s prefix.", baseName, src[:2]),
+				Subject:  &tok.Range,
+			},
+		}
+	}
+
+	isRadixDigit := func(c byte) bool {
+		return isDigit(c) && int(c-'0') < base || base == 16 && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F')
+	}
+	for i, c := range digits {
+		switch {
+		case isRadixDigit(c):
+			continue
+		case c == '_':
+			if i > 0 && isRadixDigit(digits[i-1]) && i+1 < len(digits) && isRadixDigit(digits[i+1]) {
+				continue
+			}
+			rng := numberLitCharRange(tok, i+2, 1)
+			return cty.UnknownVal(cty.Number), hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid digit separator",
+					Detail:   fmt.Sprintf("An underscore in a %s number literal must be placed between two digits, as in %s1111_0000.", baseName, src[:2]),
+					Subject:  &rng,
+				},
+			}
+		}
+
+		// Everything before the invalid character is ASCII, so we can find
+		// its position by counting bytes, but the character itself might
+		// be any character that's valid in an identifier.
+		r, size := utf8.DecodeRune(digits[i:])
+		detail := fmt.Sprintf("The character %q is not a valid digit in a %s number literal, which may contain only the digits %s.", r, baseName, digitsDesc)
+		if r == '-' {
+			detail += " To subtract, add spaces around the minus operator."
+		}
+		rng := numberLitCharRange(tok, i+2, size)
+		return cty.UnknownVal(cty.Number), hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid %s digit", baseName),
+				Detail:   detail,
+				Subject:  &rng,
+			},
+		}
+	}
+
+	digits = bytes.ReplaceAll(digits, []byte{'_'}, nil)
+	bi, _ := new(big.Int).SetString(string(digits), base)
+	return cty.NumberVal(new(big.Float).SetInt(bi)), nil
+}
+
+// numberLitCharRange returns the range of the character of the given size
+// at the given byte offset within the given number literal token, which must
+// be preceded only by ASCII characters.
+func numberLitCharRange(tok Token, offset, size int) hcl.Range {
+	rng := tok.Range
+	rng.Start.Byte += offset
+	rng.Start.Column += offset
+	rng.End = rng.Start
+	rng.End.Byte += size
+	rng.End.Column++
+	return rng
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAllDigits(src []byte) bool {
+	for _, c := range src {
+		if !isDigit(c) {
+			return false
+		}
+	}
+	return len(src) > 0
+}
+
 // finishParsingFunctionCall parses a function call assuming that the function
 // name was already read, and so the peeker should be pointing at the opening
 // parenthesis after the name, or at the double-colon after the initial
@@ -1244,6 +1568,12 @@ Token:
 		if sep.Type == TokenEllipsis {
 			expandFinal = true
 
+			if p.Peek().Type == TokenComma {
+				// A trailing comma is allowed after an expanded final
+				// argument, just as after any other final argument.
+				p.Read() // eat comma
+			}
+
 			if p.Peek().Type != TokenCParen {
 				if !p.recovery {
 					diags = append(diags, &hcl.Diagnostic{
@@ -2038,6 +2368,28 @@ Slices:
 	return string(ret), diags
 }
 
+// maxNestingDepth returns the maximum nesting depth the parser will accept.
+func (p *parser) maxNestingDepth() int {
+	if p.maxDepth <= 0 {
+		return DefaultMaxNestingDepth
+	}
+	return p.maxDepth
+}
+
+// nestingTooDeep returns a diagnostic reporting that the input exceeds the
+// parser's maximum nesting depth at the given range, and puts the parser in
+// recovery mode so that the caller's recovery heuristics can skip over the
+// remainder of the over-nested construct without further errors.
+func (p *parser) nestingTooDeep(rng hcl.Range) *hcl.Diagnostic {
+	p.setRecovery()
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Nesting too deep",
+		Detail:   fmt.Sprintf("Blocks and expressions may be nested no more than %d levels deep.", p.maxNestingDepth()),
+		Subject:  &rng,
+	}
+}
+
 // setRecovery turns on recovery mode without actually doing any recovery.
 // This can be used when a parser knowingly leaves the peeker in a useless
 // place and wants to suppress errors that might result from that decision.
@@ -2116,6 +2468,12 @@ func (p *parser) recoverAfterBodyItem() {
 
 Token:
 	for {
+		if p.recoverItems && p.Peek().Type == TokenCBrace && !containsTokenType(open, TokenOBrace) {
+			// This brace closes the body containing the item, so we must
+			// leave it for the body parser to find if it's going to
+			// continue parsing the items in that body.
+			break Token
+		}
 		tok := p.Read()
 
 		switch tok.Type {
@@ -2152,6 +2510,15 @@ Token:
 	}
 }
 
+func containsTokenType(types []TokenType, ty TokenType) bool {
+	for _, t := range types {
+		if t == ty {
+			return true
+		}
+	}
+	return false
+}
+
 // oppositeBracket finds the bracket that opposes the given bracketer, or
 // NilToken if the given token isn't a bracketer.
 //
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+// Operator precedence levels, for use with the results of OperatorPrecedence
+// and ExpressionPrecedence. Higher values bind more tightly. The binary
+// operators have levels between PrecedenceCoalesce and PrecedenceUnary.
+const (
+	// PrecedenceConditional is the precedence of the conditional operator
+	// (...?...:...), which binds less tightly than any other operator.
+	PrecedenceConditional = 0
+
+	// PrecedenceCoalesce is the precedence of the null coalescing operator
+	// (...??...), which binds less tightly than any other binary operator.
+	PrecedenceCoalesce = 1
+
+	// PrecedenceUnary is the precedence of the unary operators - and !,
+	// which bind more tightly than any binary operator.
+	PrecedenceUnary = 8
+
+	// PrecedenceTerm is the precedence of all expressions that are not
+	// operators, such as literals, function calls, traversals and
+	// parenthesized expressions. Traversal and splat operators are
+	// considered part of the term they apply to.
+	PrecedenceTerm = 9
+)
+
+// OperatorPrecedence returns the precedence of the given operation, which
+// must be one of the Op... values defined in this package. The result is
+// -1 for any other operation. The null coalescing operator is not described
+// by an Operation, so its precedence is available only as
+// PrecedenceCoalesce.
+//
+// Binary operators with the same precedence are left-associative, so that
+// a - b - c is equivalent to (a - b) - c.
+func OperatorPrecedence(op *Operation) int {
+	if op == OpNegate || op == OpLogicalNot {
+		return PrecedenceUnary
+	}
+	for i, group := range binaryOps {
+		for _, candidate := range group {
+			if candidate == op {
+				return PrecedenceCoalesce + 1 + i
+			}
+		}
+	}
+	return -1
+}
+
+// ExpressionPrecedence returns the precedence of the outermost operator of
+// the given expression, or PrecedenceTerm if it is not an operator
+// expression.
+func ExpressionPrecedence(expr Expression) int {
+	switch expr := expr.(type) {
+	case *ConditionalExpr:
+		return PrecedenceConditional
+	case *CoalesceExpr:
+		return PrecedenceCoalesce
+	case *BinaryOpExpr:
+		return OperatorPrecedence(expr.Op)
+	case *UnaryOpExpr:
+		return OperatorPrecedence(expr.Op)
+	default:
+		return PrecedenceTerm
+	}
+}
+
+// NeedsParens returns true if the given child expression, which must be one
+// of the direct operands of the given parent expression, must be wrapped in
+// parentheses when the parent is written out as source code in order for it
+// to be parsed back into the same tree.
+//
+// Any ParenthesesExpr around an operand of the parent is looked through when
+// deciding which operand the child is, so that a serializer can ask about
+// the unwrapped operand when deciding whether to keep existing parentheses.
+//
+// Parentheses are never needed around operands that are delimited by other
+// syntax, such as function call arguments and tuple elements, and so the
+// result is always false for parents other than operator, traversal, index
+// and splat expressions.
+func NeedsParens(parent, child Expression) bool {
+	prec := ExpressionPrecedence(child)
+	switch parent := parent.(type) {
+	case *ConditionalExpr:
+		// The result expressions are delimited by ? and :, but the
+		// condition is parsed as a binary operand and so must not itself
+		// be a conditional.
+		return isOperand(parent.Condition, child) && prec == PrecedenceConditional
+	case *CoalesceExpr:
+		if isOperand(parent.RHS, child) {
+			return prec <= PrecedenceCoalesce
+		}
+		return prec < PrecedenceCoalesce
+	case *BinaryOpExpr:
+		parentPrec := OperatorPrecedence(parent.Op)
+		if isOperand(parent.RHS, child) {
+			return prec <= parentPrec
+		}
+		return prec < parentPrec
+	case *UnaryOpExpr:
+		return prec < PrecedenceUnary
+	case *RelativeTraversalExpr:
+		return prec < PrecedenceTerm
+	case *IndexExpr:
+		return isOperand(parent.Collection, child) && prec < PrecedenceTerm
+	case *SplatExpr:
+		return isOperand(parent.Source, child) && prec < PrecedenceTerm
+	default:
+		return false
+	}
+}
+
+// isOperand returns true if the given child is the given operand, or is
+// wrapped in one or more ParenthesesExpr to produce the given operand.
+func isOperand(operand, child Expression) bool {
+	for {
+		if operand == child {
+			return true
+		}
+		paren, ok := operand.(*ParenthesesExpr)
+		if !ok {
+			return false
+		}
+		operand = paren.Expression
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		op   *Operation
+		want int
+	}{
+		{OpLogicalOr, 2},
+		{OpLogicalAnd, 3},
+		{OpEqual, 4},
+		{OpNotEqual, 4},
+		{OpLessThan, 5},
+		{OpGreaterThanOrEqual, 5},
+		{OpAdd, 6},
+		{OpSubtract, 6},
+		{OpMultiply, 7},
+		{OpModulo, 7},
+		{OpNegate, PrecedenceUnary},
+		{OpLogicalNot, PrecedenceUnary},
+		{&Operation{}, -1},
+	}
+
+	for _, test := range tests {
+		if got := OperatorPrecedence(test.op); got != test.want {
+			t.Errorf("wrong precedence for %#v: got %d, want %d", test.op.Impl, got, test.want)
+		}
+	}
+
+	if got, want := PrecedenceUnary, PrecedenceCoalesce+len(binaryOps)+1; got != want {
+		t.Errorf("PrecedenceUnary is %d, but the parser has %d binary operator groups", got, want-PrecedenceCoalesce-1)
+	}
+}
+
+func TestNeedsParens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a + b * c", "a + b * c"},
+		{"(a + b) * c", "(a + b) * c"},
+		{"a * (b + c)", "a * (b + c)"},
+		{"(a * b) + c", "a * b + c"},
+		{"(a - b) - c", "a - b - c"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"((a)) || ((b && c))", "a || b && c"},
+		{"(a || b) && c", "(a || b) && c"},
+		{"-(a + b)", "-(a + b)"},
+		{"(-a) + b", "-a + b"},
+		{"!(!a)", "!!a"},
+		{"!(a == b)", "!(a == b)"},
+		{"(a ? b : c) ? d : e", "(a ? b : c) ? d : e"},
+		{"a ? (b ? c : d) : (e ? f : g)", "a ? b ? c : d : e ? f : g"},
+		{"(a || b) ? c : d", "a || b ? c : d"},
+		{"a + (b ? c : d)", "a + (b ? c : d)"},
+		{"(a + b).c", "(a + b).c"},
+		{"(-a)[b + c]", "(-a)[b + c]"},
+		{"(a ? b : c)[*].d", "(a ? b : c)[*].d"},
+		{"(a.b)[*]", "a.b[*]"},
+		{"(a ?? b) ?? c", "a ?? b ?? c"},
+		{"a ?? (b ?? c)", "a ?? (b ?? c)"},
+		{"(a || b) ?? c", "a || b ?? c"},
+		{"a || (b ?? c)", "a || (b ?? c)"},
+		{"(a ?? b) ? c : d", "a ?? b ? c : d"},
+		{"(a ?? b).c", "(a ?? b).c"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.input), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			got := testMinimalParensString(t, expr)
+			if got != test.want {
+				t.Errorf("wrong result\ninput: %s\ngot:   %s\nwant:  %s", test.input, got, test.want)
+			}
+
+			// The result must parse back into an equivalent tree.
+			reparsed, diags := ParseExpression([]byte(got), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics for result: %s", diags.Error())
+			}
+			if again := testMinimalParensString(t, reparsed); again != got {
+				t.Errorf("result does not round-trip\ngot:  %s\nwant: %s", again, got)
+			}
+		})
+	}
+}
+
+// testMinimalParensString serializes the subset of expressions used in
+// TestNeedsParens, discarding all of the parentheses in the input and adding
+// back only those that NeedsParens considers necessary.
+func testMinimalParensString(t *testing.T, expr Expression) string {
+	operand := func(parent, child Expression) string {
+		for {
+			paren, ok := child.(*ParenthesesExpr)
+			if !ok {
+				break
+			}
+			child = paren.Expression
+		}
+		s := testMinimalParensString(t, child)
+		if NeedsParens(parent, child) {
+			return "(" + s + ")"
+		}
+		return s
+	}
+
+	switch expr := expr.(type) {
+	case *ParenthesesExpr:
+		return testMinimalParensString(t, expr.Expression)
+	case *ScopeTraversalExpr:
+		names := []string{expr.Traversal.RootName()}
+		for _, step := range expr.Traversal[1:] {
+			names = append(names, step.(hcl.TraverseAttr).Name)
+		}
+		return strings.Join(names, ".")
+	case *BinaryOpExpr:
+		symbols := map[*Operation]string{
+			OpLogicalOr:  "||",
+			OpLogicalAnd: "&&",
+			OpEqual:      "==",
+			OpAdd:        "+",
+			OpSubtract:   "-",
+			OpMultiply:   "*",
+		}
+		return fmt.Sprintf("%s %s %s", operand(expr, expr.LHS), symbols[expr.Op], operand(expr, expr.RHS))
+	case *CoalesceExpr:
+		return fmt.Sprintf("%s ?? %s", operand(expr, expr.LHS), operand(expr, expr.RHS))
+	case *UnaryOpExpr:
+		symbol := "-"
+		if expr.Op == OpLogicalNot {
+			symbol = "!"
+		}
+		return symbol + operand(expr, expr.Val)
+	case *ConditionalExpr:
+		return fmt.Sprintf(
+			"%s ? %s : %s",
+			operand(expr, expr.Condition),
+			operand(expr, expr.TrueResult),
+			operand(expr, expr.FalseResult),
+		)
+	case *RelativeTraversalExpr:
+		var names []string
+		for _, step := range expr.Traversal {
+			names = append(names, step.(hcl.TraverseAttr).Name)
+		}
+		return operand(expr, expr.Source) + "." + strings.Join(names, ".")
+	case *IndexExpr:
+		return fmt.Sprintf("%s[%s]", operand(expr, expr.Collection), operand(expr, expr.Key))
+	case *SplatExpr:
+		s := operand(expr, expr.Source) + "[*]"
+		if rel, ok := expr.Each.(*RelativeTraversalExpr); ok {
+			s += "." + rel.Traversal[0].(hcl.TraverseAttr).Name
+		}
+		return s
+	default:
+		t.Fatalf("unsupported expression type %T", expr)
+		return ""
+	}
+}
@@ -4,6 +4,13 @@
 package hclsyntax
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/apparentlymart/go-textseg/v15/textseg"
 	"github.com/hashicorp/hcl/v2"
 )
 
@@ -18,9 +25,311 @@ import (
 // should be served using the hcl.Body interface to ensure compatibility with
 // other configurationg syntaxes, such as JSON.
 func ParseConfig(src []byte, filename string, start hcl.Pos) (*hcl.File, hcl.Diagnostics) {
-	tokens, diags := LexConfig(src, filename, start)
+	file, _, diags := ParseConfigReturningTokens(src, filename, start)
+	return file, diags
+}
+
+// DefaultMaxNestingDepth is the maximum nesting depth of blocks and
+// expressions accepted by the parser when no other limit is specified.
+const DefaultMaxNestingDepth = 4096
+
+// ParseOptions customizes the behavior of ParseConfigWithOptions.
+type ParseOptions struct {
+	// MaxNestingDepth is the maximum combined nesting depth of blocks and
+	// expressions that the parser will accept. Input nested more deeply
+	// than this produces an error diagnostic rather than exhausting the
+	// stack. Zero selects DefaultMaxNestingDepth.
+	MaxNestingDepth int
+
+	// SplatNullIsError causes splat expressions to produce an error when
+	// applied to a null value, rather than returning an empty tuple. See
+	// SplatExpr.NullIsError for details.
+	SplatNullIsError bool
+
+	// RecoverInvalidItems makes the parser recover from an invalid argument
+	// or block definition by skipping only the line it appears on, so that
+	// all of the valid items that follow it in the same body are still
+	// included in the result, each with its own diagnostics. This is
+	// intended for editor integrations that need a best-effort AST for
+	// configuration that is still being written.
+	//
+	// By default the parser skips the remainder of the body containing an
+	// invalid item, to avoid reporting a cascade of confusing errors caused
+	// by the first one.
+	RecoverInvalidItems bool
+
+	// ExactIntegers causes each number literal written as a whole number
+	// without a fractional part or exponent to be given exactly as much
+	// precision as is needed to represent its value.
+	//
+	// By default, number literals are parsed with the same 512 bits of
+	// binary precision used when converting a string to a number, so that
+	// all integers whose magnitude is no greater than 2^512, which includes
+	// all 64-bit integers, are already represented exactly. Integer
+	// literals beyond that are rounded to the nearest representable value
+	// unless this option is set. Arithmetic on the resulting values may
+	// still round, as described in the language specification.
+	ExactIntegers bool
+
+	// ExtraIdentifierChars is a set of additional characters that are
+	// permitted after the first character of an identifier, for languages
+	// that embed HCL and need identifiers such as namespaced keys. For
+	// example, setting it to ":" allows foo:bar to be a single identifier.
+	// The default rules, which follow Unicode's identifier syntax, are
+	// unchanged.
+	//
+	// Because identifiers are used for argument names, block types, variable
+	// names and attribute access, all of these accept the extra characters:
+	// foo:bar = 1 defines an argument named "foo:bar", and foo:bar.baz is
+	// a traversal of the attribute "baz" of the variable "foo:bar". An extra
+	// character is treated as part of an identifier only when it directly
+	// follows an identifier with no intervening space, so syntax that uses
+	// the same character as an operator must then separate it with spaces,
+	// as in a ? b : c rather than a ? b:c. Multi-character operators are
+	// unaffected, and so foo::bar remains a namespaced function name even
+	// if ":" is an extra character.
+	//
+	// Characters that delimit other syntax, such as brackets, ".", ",",
+	// "=", quotes and "#", cannot be added. Including any of them produces
+	// an error diagnostic, and the source is then parsed without any of the
+	// extra characters.
+	// The extra characters are not known to ValidIdentifier, to the other
+	// parsing functions in this package, or to other packages such as
+	// hclwrite, so names using them may not be accepted elsewhere.
+	ExtraIdentifierChars string
+
+	// DuplicateAttributes selects how the parser handles an argument that
+	// is set more than once in the same body. The zero value,
+	// DuplicateAttributesError, treats it as an error.
+	DuplicateAttributes DuplicateAttributePolicy
+
+	// WarnUnquotedLabels causes the parser to report a warning for each
+	// block label written as a bare identifier, as in resource aws_instance
+	// web { ... }, rather than as a quoted string. The syntax accepts both
+	// forms, and an unquoted label is recorded as a label string either way,
+	// but applications migrating their configurations to the quoted form
+	// can use this to flag the remaining unquoted labels as deprecated.
+	WarnUnquotedLabels bool
+
+	// ColonAssignment selects whether an argument definition may use a colon
+	// in place of the equals sign, as in foo: "bar", for applications whose
+	// users are accustomed to that syntax from other languages. The zero
+	// value, ColonAssignmentError, accepts only the equals sign.
+	//
+	// Only the token directly after an argument name is affected, and so
+	// colons within expressions, such as in conditional expressions and
+	// 'for' expressions, keep their usual meaning. A colon used in this way
+	// must be separated from the argument name by a space if ":" is also
+	// one of the ExtraIdentifierChars, since otherwise it is taken to be
+	// part of the name. The EqualsRange of the resulting attribute is the
+	// range of the colon.
+	ColonAssignment ColonAssignmentPolicy
+
+	// UnquotedLineValues causes the parser to accept argument values written
+	// as unquoted text running to the end of the line, as in the INI-like
+	// formats of some other tools. For example, name = John Smith sets the
+	// argument "name" to the string "John Smith".
+	//
+	// This applies only to an argument whose definition begins its own line
+	// and whose value, scanned as far as the end of that line, isn't a valid
+	// expression or the beginning of an expression that continues onto later
+	// lines, and so values such as 10, "quoted" and var.foo keep their usual
+	// meaning. A value beginning with a quote or a heredoc marker is always
+	// parsed as an expression. The captured string is the exact source text
+	// with any trailing whitespace removed, including any characters that
+	// would otherwise begin a comment, and the range of the resulting
+	// literal expression is the range of that text.
+	UnquotedLineValues bool
+
+	// RawStrings causes the parser to accept raw string literals delimited
+	// by backticks, as in `C:\Program Files`, whose value is exactly the
+	// characters between the backticks, including any newlines. Neither
+	// escape sequences nor template sequences are recognized within them,
+	// and so they cannot contain a backtick. Otherwise, a backtick is an
+	// invalid character.
+	RawStrings bool
+
+	// FeatureDirectives allows each file to enable some of the other options
+	// for itself using directive comments at the start of the file, so that
+	// files can opt into those features individually during a migration.
+	//
+	// A directive is a single-line comment, beginning with either "#" or
+	// "//", whose text begins with "hcl:". The only recognized directive is
+	// hcl:feature, followed by the names of one or more features separated
+	// by spaces, as in:
+	//
+	//	# hcl:feature exact_integers unquoted_line_values
+	//
+	// The recognized feature names are colon_assignment, which sets
+	// ColonAssignment to ColonAssignmentAllowed unless another policy was
+	// already selected, and exact_integers, raw_strings,
+	// splat_null_is_error, unquoted_line_values and warn_unquoted_labels,
+	// which set the corresponding boolean options. A directive can only enable features,
+	// and so it does not affect any options that are already set.
+	//
+	// Directives are recognized only among the comments and blank lines that
+	// precede the first argument or block in the file, and any other comments
+	// are ignored as usual. An unknown directive or feature name produces a
+	// warning, rather than an error, and is otherwise ignored.
+	FeatureDirectives bool
+}
+
+// ColonAssignmentPolicy is the type of ParseOptions.ColonAssignment.
+type ColonAssignmentPolicy int
+
+const (
+	// ColonAssignmentError reports an error for an argument definition that
+	// uses a colon, as for any other token that is not an equals sign.
+	ColonAssignmentError ColonAssignmentPolicy = iota
+
+	// ColonAssignmentAllowed accepts a colon in an argument definition as
+	// equivalent to the equals sign.
+	ColonAssignmentAllowed
+
+	// ColonAssignmentWarning accepts a colon in an argument definition as
+	// equivalent to the equals sign, but reports a warning for each use so
+	// that applications can flag the alternative syntax as deprecated.
+	ColonAssignmentWarning
+)
+
+// DuplicateAttributePolicy is the type of ParseOptions.DuplicateAttributes.
+type DuplicateAttributePolicy int
+
+const (
+	// DuplicateAttributesError reports an error for each redefinition of
+	// an argument and keeps the first definition in the body.
+	DuplicateAttributesError DuplicateAttributePolicy = iota
+
+	// DuplicateAttributesLastWins reports a warning for each redefinition
+	// of an argument and keeps the last definition in the body, so that
+	// later definitions override earlier ones.
+	DuplicateAttributesLastWins
+
+	// DuplicateAttributesFirstWins reports a warning for each redefinition
+	// of an argument and keeps the first definition in the body, so that
+	// later definitions are ignored.
+	DuplicateAttributesFirstWins
+)
+
+// ParseConfigWithOptions is like ParseConfig, but allows customizing the
+// parser's behavior using the given options.
+func ParseConfigWithOptions(src []byte, filename string, start hcl.Pos, opts ParseOptions) (*hcl.File, hcl.Diagnostics) {
+	file, _, diags := parseConfig(src, filename, start, opts)
+	return file, diags
+}
+
+// ParseConfigFromReader is like ParseConfig, but reads the source from the
+// given reader, which is read to completion before parsing begins. The
+// source is taken to begin at hcl.InitialPos.
+//
+// If the reader returns an error then the result is an error diagnostic
+// whose subject is the position just after the last byte that was read
+// successfully, along with a file whose Bytes field contains the bytes read
+// so far, so that the diagnostic can be rendered with a source snippet. In
+// that case the file's body is empty, since the incomplete source is not
+// parsed.
+func ParseConfigFromReader(r io.Reader, filename string) (*hcl.File, hcl.Diagnostics) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		// The incomplete source isn't scanned, so we find the position just
+		// after it by counting its lines and the characters of its last line.
+		lastLine := src[bytes.LastIndexByte(src, '\n')+1:]
+		chars, _ := textseg.TokenCount(lastLine, textseg.ScanGraphemeClusters)
+		endPos := hcl.Pos{
+			Line:   1 + bytes.Count(src, []byte{'\n'}),
+			Column: 1 + chars,
+			Byte:   len(src),
+		}
+		rng := hcl.Range{
+			Filename: filename,
+			Start:    endPos,
+			End:      endPos,
+		}
+		body := &Body{
+			SrcRange: rng,
+			EndRange: rng,
+		}
+		file := &hcl.File{
+			Body:  body,
+			Bytes: src,
+
+			Nav: navigation{
+				root: body,
+			},
+		}
+		return file, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("An error occurred while reading %q after %d bytes: %s.", filename, len(src), err),
+				Subject:  &rng,
+			},
+		}
+	}
+
+	return ParseConfig(src, filename, hcl.InitialPos)
+}
+
+// ParseConfigReturningTokens is like ParseConfig, but additionally returns
+// the full sequence of tokens that the file was parsed from, including
+// comments and newlines, in the same form as returned by LexConfig.
+//
+// This is intended for applications such as editor integrations that need
+// both the AST and the tokens it was built from. Using the tokens returned
+// here guarantees that they are consistent with the ranges in the AST,
+// whereas lexing the source separately would require a redundant pass.
+func ParseConfigReturningTokens(src []byte, filename string, start hcl.Pos) (*hcl.File, Tokens, hcl.Diagnostics) {
+	return parseConfig(src, filename, start, ParseOptions{})
+}
+
+func parseConfig(src []byte, filename string, start hcl.Pos, opts ParseOptions) (*hcl.File, Tokens, hcl.Diagnostics) {
+	var optDiags hcl.Diagnostics
+	if opts.FeatureDirectives {
+		// Some features change how the source is scanned, so we must find
+		// the directives before scanning the whole file. They can appear
+		// only among the leading comments, which are scanned the same way
+		// regardless of those features.
+		var leading Tokens
+		scanTokens(src, filename, start, scanNormal, opts.RawStrings, func(tok Token) bool {
+			if tok.Type != TokenComment && tok.Type != TokenNewline {
+				return false
+			}
+			leading = append(leading, tok)
+			return true
+		})
+		opts, optDiags = applyFeatureDirectives(leading, opts)
+	}
+	tokens := scanTokens(src, filename, start, scanNormal, opts.RawStrings, nil)
+	if opts.ExtraIdentifierChars != "" {
+		charDiags := validateExtraIdentChars(opts.ExtraIdentifierChars, filename, start)
+		optDiags = append(optDiags, charDiags...)
+		if charDiags.HasErrors() {
+			opts.ExtraIdentifierChars = ""
+		} else {
+			tokens = mergeExtraIdentChars(tokens, opts.ExtraIdentifierChars)
+		}
+	}
+	if opts.UnquotedLineValues {
+		tokens = captureLineValues(src, start, tokens, func(b []byte, pos hcl.Pos) Tokens {
+			tokens := scanTokens(b, filename, pos, scanNormal, opts.RawStrings, nil)
+			if opts.ExtraIdentifierChars != "" {
+				tokens = mergeExtraIdentChars(tokens, opts.ExtraIdentifierChars)
+			}
+			return tokens
+		})
+	}
+	diags := append(optDiags, checkInvalidTokens(tokens)...)
 	peeker := newPeeker(tokens, false)
-	parser := &parser{peeker: peeker}
+	parser := &parser{
+		peeker:           peeker,
+		maxDepth:         opts.MaxNestingDepth,
+		recoverItems:     opts.RecoverInvalidItems,
+		splatNullIsError: opts.SplatNullIsError,
+		exactIntegers:    opts.ExactIntegers,
+		duplicateAttrs:   opts.DuplicateAttributes,
+		warnBareLabels:   opts.WarnUnquotedLabels,
+		colonAssignment:  opts.ColonAssignment,
+	}
 	body, parseDiags := parser.ParseBody(TokenEOF)
 	diags = append(diags, parseDiags...)
 
@@ -36,7 +345,7 @@ func ParseConfig(src []byte, filename string, start hcl.Pos) (*hcl.File, hcl.Dia
 		Nav: navigation{
 			root: body,
 		},
-	}, diags
+	}, tokens, diags
 }
 
 // ParseExpression parses the given buffer as a standalone HCL expression,
@@ -118,6 +427,31 @@ func ParseTraversalAbs(src []byte, filename string, start hcl.Pos) (hcl.Traversa
 	return expr, diags
 }
 
+// ParseTraversalString is a convenience wrapper around ParseTraversalAbs for
+// parsing a traversal written by a user outside of any configuration file,
+// such as one given as a command line argument like var.foo.bar[0].
+//
+// If the string is not a valid absolute traversal then the result is an
+// error describing the first problem, including the column where it was
+// detected, instead of diagnostics.
+func ParseTraversalString(s string) (hcl.Traversal, error) {
+	traversal, diags := ParseTraversalAbs([]byte(s), "", hcl.InitialPos)
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		msg := strings.TrimSuffix(diag.Detail, ".")
+		if msg == "" {
+			msg = diag.Summary
+		}
+		if diag.Subject != nil {
+			return nil, fmt.Errorf("invalid traversal at column %d: %s", diag.Subject.Start.Column, msg)
+		}
+		return nil, fmt.Errorf("invalid traversal: %s", msg)
+	}
+	return traversal, nil
+}
+
 // LexConfig performs lexical analysis on the given buffer, treating it as a
 // whole HCL config file, and returns the resulting tokens.
 //
@@ -126,11 +460,32 @@ func ParseTraversalAbs(src []byte, filename string, start hcl.Pos) (hcl.Traversa
 // encodings or unrecognized characters, but full parsing is required to
 // detect _all_ syntax errors.
 func LexConfig(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnostics) {
-	tokens := scanTokens(src, filename, start, scanNormal)
+	tokens := scanTokens(src, filename, start, scanNormal, false, nil)
 	diags := checkInvalidTokens(tokens)
 	return tokens, diags
 }
 
+// LexConfigFunc is a variant of LexConfig that passes each token to the
+// given callback as soon as it is scanned, rather than collecting all of
+// the tokens into a slice. If the callback returns false then it will not
+// be called again and no further tokens will be produced.
+//
+// This is useful when scanning large inputs for only a small number of
+// tokens, such as when looking for a particular block header, because the
+// memory used does not grow with the size of the input.
+//
+// The returned diagnostics are the same as LexConfig would return for the
+// tokens that were passed to the callback.
+func LexConfigFunc(src []byte, filename string, start hcl.Pos, yield func(Token) bool) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	var checker invalidTokenChecker
+	scanTokens(src, filename, start, scanNormal, false, func(tok Token) bool {
+		diags = append(diags, checker.Check(tok)...)
+		return yield(tok)
+	})
+	return diags
+}
+
 // LexExpression performs lexical analysis on the given buffer, treating it as
 // a standalone HCL expression, and returns the resulting tokens.
 //
@@ -141,7 +496,7 @@ func LexConfig(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnost
 func LexExpression(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnostics) {
 	// This is actually just the same thing as LexConfig, since configs
 	// and expressions lex in the same way.
-	tokens := scanTokens(src, filename, start, scanNormal)
+	tokens := scanTokens(src, filename, start, scanNormal, false, nil)
 	diags := checkInvalidTokens(tokens)
 	return tokens, diags
 }
@@ -154,7 +509,7 @@ func LexExpression(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diag
 // encodings or unrecognized characters, but full parsing is required to
 // detect _all_ syntax errors.
 func LexTemplate(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnostics) {
-	tokens := scanTokens(src, filename, start, scanTemplate)
+	tokens := scanTokens(src, filename, start, scanTemplate, false, nil)
 	diags := checkInvalidTokens(tokens)
 	return tokens, diags
 }
@@ -169,6 +524,6 @@ func ValidIdentifier(s string) bool {
 	// This is a kinda-expensive way to do something pretty simple, but it
 	// is easiest to do with our existing scanner-related infrastructure here
 	// and nobody should be validating identifiers in a tight loop.
-	tokens := scanTokens([]byte(s), "", hcl.Pos{}, scanIdentOnly)
+	tokens := scanTokens([]byte(s), "", hcl.Pos{}, scanIdentOnly, false, nil)
 	return len(tokens) == 2 && tokens[0].Type == TokenIdent && tokens[1].Type == TokenEOF
 }
@@ -4,9 +4,18 @@
 package hclsyntax
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestValidIdentifier(t *testing.T) {
@@ -50,6 +59,56 @@ func TestValidIdentifier(t *testing.T) {
 	}
 }
 
+func TestLexConfigFunc(t *testing.T) {
+	src := []byte("a = 1\nb = 'x'\nc = 2 ** 3\n")
+	start := hcl.Pos{Line: 1, Column: 1, Byte: 0}
+
+	t.Run("all tokens", func(t *testing.T) {
+		wantTokens, wantDiags := LexConfig(src, "test.hcl", start)
+
+		var gotTokens Tokens
+		gotDiags := LexConfigFunc(src, "test.hcl", start, func(tok Token) bool {
+			gotTokens = append(gotTokens, tok)
+			return true
+		})
+
+		if !reflect.DeepEqual(gotTokens, wantTokens) {
+			t.Errorf("wrong tokens\ngot:  %#v\nwant: %#v", gotTokens, wantTokens)
+		}
+		if !reflect.DeepEqual(gotDiags, wantDiags) {
+			t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, wantDiags)
+		}
+	})
+	t.Run("stop early", func(t *testing.T) {
+		var gotTypes []TokenType
+		diags := LexConfigFunc(src, "test.hcl", start, func(tok Token) bool {
+			gotTypes = append(gotTypes, tok.Type)
+			return tok.Type != TokenNewline
+		})
+
+		wantTypes := []TokenType{TokenIdent, TokenEqual, TokenNumberLit, TokenNewline}
+		if !reflect.DeepEqual(gotTypes, wantTypes) {
+			t.Errorf("wrong token types\ngot:  %#v\nwant: %#v", gotTypes, wantTypes)
+		}
+		if len(diags) != 0 {
+			// The invalid tokens appear only after the point where we stopped.
+			t.Errorf("unexpected diagnostics: %s", diags.Error())
+		}
+	})
+	t.Run("stop inside template", func(t *testing.T) {
+		var gotTypes []TokenType
+		LexConfigFunc([]byte(`a = "${b} <<EOT`), "test.hcl", start, func(tok Token) bool {
+			gotTypes = append(gotTypes, tok.Type)
+			return tok.Type != TokenTemplateInterp
+		})
+
+		wantTypes := []TokenType{TokenIdent, TokenEqual, TokenOQuote, TokenTemplateInterp}
+		if !reflect.DeepEqual(gotTypes, wantTypes) {
+			t.Errorf("wrong token types\ngot:  %#v\nwant: %#v", gotTypes, wantTypes)
+		}
+	})
+}
+
 var T Tokens
 
 func BenchmarkLexConfig(b *testing.B) {
@@ -65,3 +124,834 @@ func BenchmarkLexConfig(b *testing.B) {
 
 	T = tokens
 }
+
+func TestParseConfigReturningTokens(t *testing.T) {
+	src := []byte("# comment\nfoo = \"bar\"\n\nblock \"label\" {\n  baz = 1\n}\n")
+	start := hcl.Pos{Line: 1, Column: 1, Byte: 0}
+
+	file, tokens, diags := ParseConfigReturningTokens(src, "test.hcl", start)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	wantTokens, _ := LexConfig(src, "test.hcl", start)
+	if !reflect.DeepEqual(tokens, wantTokens) {
+		t.Errorf("wrong tokens\ngot:  %#v\nwant: %#v", tokens, wantTokens)
+	}
+
+	// The tokens must agree with the ranges recorded in the AST.
+	body := file.Body.(*Body)
+	attr := body.Attributes["foo"]
+	var found bool
+	for _, tok := range tokens {
+		if tok.Type == TokenIdent && tok.Range == attr.NameRange {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("no token matches the range %s of attribute name", attr.NameRange)
+	}
+
+	wantFile, _ := ParseConfig(src, "test.hcl", start)
+	if !reflect.DeepEqual(file.Body, wantFile.Body) {
+		t.Errorf("body differs from the result of ParseConfig")
+	}
+}
+
+func TestParseConfigUTF8BOM(t *testing.T) {
+	src := "\xef\xbb\xbfservice \"web\" {\n  port = 80\n}\n"
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	body := f.Body.(*Body)
+	if len(body.Blocks) != 1 {
+		t.Fatalf("wrong number of blocks %d; want 1", len(body.Blocks))
+	}
+	block := body.Blocks[0]
+	if got, want := block.Type, "service"; got != want {
+		t.Errorf("wrong block type %q; want %q", got, want)
+	}
+
+	// Byte offsets are relative to the original source, including the BOM,
+	// but the BOM does not occupy a column.
+	want := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 1, Column: 1, Byte: 3},
+		End:      hcl.Pos{Line: 1, Column: 8, Byte: 10},
+	}
+	if got := block.TypeRange; got != want {
+		t.Errorf("wrong type range\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := string(block.TypeRange.SliceBytes([]byte(src))), "service"; got != want {
+		t.Errorf("wrong source for type range %q; want %q", got, want)
+	}
+}
+
+func TestParseTraversalString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    hcl.Traversal
+		wantErr string
+	}{
+		{
+			input: `var.foo.bar[0]`,
+			want: hcl.Traversal{
+				hcl.TraverseRoot{Name: "var"},
+				hcl.TraverseAttr{Name: "foo"},
+				hcl.TraverseAttr{Name: "bar"},
+				hcl.TraverseIndex{Key: cty.NumberIntVal(0)},
+			},
+		},
+		{
+			input: `foo["key"]`,
+			want: hcl.Traversal{
+				hcl.TraverseRoot{Name: "foo"},
+				hcl.TraverseIndex{Key: cty.StringVal("key")},
+			},
+		},
+		{
+			input:   ``,
+			wantErr: `invalid traversal at column 1: Must begin with a variable name`,
+		},
+		{
+			input:   `var.foo[`,
+			wantErr: `invalid traversal at column 9: Index brackets must contain either a literal number or a literal string`,
+		},
+		{
+			input:   `var.foo + 1`,
+			wantErr: `invalid traversal at column 9: Expected an attribute access or an index operator`,
+		},
+		{
+			input:   `var.*`,
+			wantErr: `invalid traversal at column 5: Splat expressions (.*) may not be used here`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseTraversalString(test.input)
+			if test.wantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %#v", got)
+				}
+				if got := err.Error(); got != test.wantErr {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// The source ranges are not interesting here, so we compare only
+			// the steps.
+			if len(got) != len(test.want) {
+				t.Fatalf("wrong number of steps %d; want %d", len(got), len(test.want))
+			}
+			for i := range got {
+				if !traverserEqual(got[i], test.want[i]) {
+					t.Errorf("wrong step %d\ngot:  %#v\nwant: %#v", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func traverserEqual(a, b hcl.Traverser) bool {
+	switch a := a.(type) {
+	case hcl.TraverseRoot:
+		b, ok := b.(hcl.TraverseRoot)
+		return ok && a.Name == b.Name
+	case hcl.TraverseAttr:
+		b, ok := b.(hcl.TraverseAttr)
+		return ok && a.Name == b.Name
+	case hcl.TraverseIndex:
+		b, ok := b.(hcl.TraverseIndex)
+		return ok && a.Key.RawEquals(b.Key)
+	default:
+		return false
+	}
+}
+
+func TestParseConfigWithOptionsMaxNestingDepth(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		maxDepth  int
+		wantError bool
+	}{
+		"shallow expression": {
+			"a = ((1))\n",
+			8,
+			false,
+		},
+		"deep expression": {
+			"a = " + strings.Repeat("(", 20) + "1" + strings.Repeat(")", 20) + "\n",
+			8,
+			true,
+		},
+		"deep unary operators": {
+			"a = " + strings.Repeat("!", 20) + "true\n",
+			8,
+			true,
+		},
+		"shallow blocks": {
+			"a {\n  b {\n  }\n}\n",
+			8,
+			false,
+		},
+		"deep blocks": {
+			strings.Repeat("a {\n", 20) + strings.Repeat("}\n", 20),
+			8,
+			true,
+		},
+		"pathological default limit": {
+			"a = " + strings.Repeat("(", 100000) + "1" + strings.Repeat(")", 100000) + "\n",
+			0,
+			true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseConfigWithOptions([]byte(test.src), "", hcl.InitialPos, ParseOptions{
+				MaxNestingDepth: test.maxDepth,
+			})
+			if !test.wantError {
+				if diags.HasErrors() {
+					t.Fatalf("unexpected diagnostics: %s", diags.Error())
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Summary, "Nesting too deep"; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseConfigWithOptionsRecoverInvalidItems(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		want      []string
+		wantDiags int
+	}{
+		"quoted argument name": {
+			"a = 1\n\"bad\" = 2\nb {\n}\nc = 3\n",
+			[]string{"a", "b", "c"},
+			1,
+		},
+		"stray token": {
+			"a = 1\n!\nb = 2\n",
+			[]string{"a", "b"},
+			1,
+		},
+		"errors in multiple items": {
+			"a = 1\n!\nb = 2\n]\nc {\n  ]\n  d = 1\n}\ne = 3\n",
+			[]string{"a", "b", "c", "c.d", "e"},
+			3,
+		},
+		"bad last item in nested block": {
+			"a {\n  b {\n    c = 1\n    d = !}\n  e = 2\n}\nf = 3\n",
+			[]string{"a", "a.b", "a.b.c", "a.b.d", "a.e", "f"},
+			1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfigWithOptions([]byte(test.src), "", hcl.InitialPos, ParseOptions{
+				RecoverInvalidItems: true,
+			})
+			if len(diags) != test.wantDiags {
+				t.Errorf("wrong number of diagnostics %d; want %d\n%s", len(diags), test.wantDiags, diags.Error())
+			}
+
+			var got []string
+			var collect func(prefix string, body *Body)
+			collect = func(prefix string, body *Body) {
+				for name := range body.Attributes {
+					got = append(got, prefix+name)
+				}
+				for _, block := range body.Blocks {
+					got = append(got, prefix+block.Type)
+					collect(prefix+block.Type+".", block.Body)
+				}
+			}
+			collect("", f.Body.(*Body))
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong items\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseConfigFromReader(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		file, diags := ParseConfigFromReader(strings.NewReader("a = 1\n"), "test.hcl")
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		attrs, _ := file.Body.JustAttributes()
+		if _, ok := attrs["a"]; !ok {
+			t.Errorf("missing attribute \"a\"")
+		}
+		if got, want := string(file.Bytes), "a = 1\n"; got != want {
+			t.Errorf("wrong bytes %q; want %q", got, want)
+		}
+	})
+	t.Run("read error", func(t *testing.T) {
+		r := io.MultiReader(
+			strings.NewReader("a = 1\nb = \"hé"),
+			iotest.ErrReader(errors.New("connection reset")),
+		)
+		file, diags := ParseConfigFromReader(r, "test.hcl")
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+		}
+		diag := diags[0]
+		if got, want := diag.Summary, "Failed to read file"; got != want {
+			t.Errorf("wrong summary %q; want %q", got, want)
+		}
+		if got, want := diag.Detail, `An error occurred while reading "test.hcl" after 14 bytes: connection reset.`; got != want {
+			t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+		}
+		wantPos := hcl.Pos{Line: 2, Column: 8, Byte: 14}
+		wantRange := hcl.Range{Filename: "test.hcl", Start: wantPos, End: wantPos}
+		if got := *diag.Subject; got != wantRange {
+			t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, wantRange)
+		}
+		if got, want := string(file.Bytes), "a = 1\nb = \"hé"; got != want {
+			t.Errorf("wrong bytes %q; want %q", got, want)
+		}
+		if attrs, _ := file.Body.JustAttributes(); len(attrs) != 0 {
+			t.Errorf("body is not empty: %#v", attrs)
+		}
+		if _, ok := file.Nav.(navigation); !ok {
+			t.Errorf("wrong Nav %#v; want a navigation", file.Nav)
+		}
+	})
+}
+
+func TestParseConfigWithOptionsExactIntegers(t *testing.T) {
+	pow := func(n uint) *big.Int {
+		return new(big.Int).Lsh(big.NewInt(1), n)
+	}
+	tests := map[string]struct {
+		src          *big.Int
+		exactDefault bool
+	}{
+		"max uint64":  {new(big.Int).Sub(pow(64), big.NewInt(1)), true},
+		"2^64":        {pow(64), true},
+		"2^64 + 1":    {new(big.Int).Add(pow(64), big.NewInt(1)), true},
+		"2^512 - 1":   {new(big.Int).Sub(pow(512), big.NewInt(1)), true},
+		"2^512":       {pow(512), true},
+		"2^512 + 1":   {new(big.Int).Add(pow(512), big.NewInt(1)), false},
+		"10^200 + 17": {new(big.Int).Add(new(big.Int).Exp(big.NewInt(10), big.NewInt(200), nil), big.NewInt(17)), false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := []byte("a = " + test.src.String() + "\n")
+			for _, exact := range []bool{false, true} {
+				file, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+					ExactIntegers: exact,
+				})
+				if diags.HasErrors() {
+					t.Fatalf("unexpected errors: %s", diags.Error())
+				}
+				attrs, _ := file.Body.JustAttributes()
+				val, diags := attrs["a"].Expr.Value(nil)
+				if diags.HasErrors() {
+					t.Fatalf("unexpected errors: %s", diags.Error())
+				}
+				got, _ := val.AsBigFloat().Int(nil)
+				wantExact := exact || test.exactDefault
+				if isExact := got.Cmp(test.src) == 0; isExact != wantExact {
+					t.Errorf("with ExactIntegers %t, got %s; exact is %t, want %t", exact, got, isExact, wantExact)
+				}
+			}
+		})
+	}
+
+	// Literals that aren't whole numbers are unaffected.
+	file, diags := ParseConfigWithOptions([]byte("a = 1.5\nb = 1e3\n"), "", hcl.InitialPos, ParseOptions{
+		ExactIntegers: true,
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+	attrs, _ := file.Body.JustAttributes()
+	for name, want := range map[string]cty.Value{
+		"a": cty.NumberFloatVal(1.5),
+		"b": cty.NumberIntVal(1000),
+	} {
+		got, _ := attrs[name].Expr.Value(nil)
+		if !got.Equals(want).True() {
+			t.Errorf("wrong value for %s: got %#v, want %#v", name, got, want)
+		}
+	}
+}
+
+func TestParseConfigWithOptionsExtraIdentifierChars(t *testing.T) {
+	src := []byte(`
+ns:name = ns:value.attr
+cond    = ns:flag ? ns:a : other
+num     = ns:1
+many    = a:b@c:
+spaced  = 1 ? 2 : 3
+`)
+	file, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+		ExtraIdentifierChars: ":@",
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+	var names []string
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if got, want := names, []string{"cond", "many", "ns:name", "num", "spaced"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong attribute names\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	wantVars := map[string][]string{
+		"ns:name": {"ns:value"},
+		"cond":    {"ns:flag", "ns:a", "other"},
+		"num":     {"ns:1"},
+		"many":    {"a:b@c:"},
+		"spaced":  nil,
+	}
+	for name, want := range wantVars {
+		var got []string
+		for _, traversal := range attrs[name].Expr.Variables() {
+			got = append(got, traversal.RootName())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong variables for %s\ngot:  %#v\nwant: %#v", name, got, want)
+		}
+	}
+
+	// The grammar is unchanged when the option isn't set.
+	_, diags = ParseConfig(src, "", hcl.InitialPos)
+	if !diags.HasErrors() {
+		t.Errorf("unexpected success without ExtraIdentifierChars")
+	}
+}
+
+func TestParseConfigWithOptionsExtraIdentifierCharsForbidden(t *testing.T) {
+	_, diags := ParseConfigWithOptions([]byte("a = 1\n"), "test.hcl", hcl.InitialPos, ParseOptions{
+		ExtraIdentifierChars: ":.",
+	})
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Error())
+	}
+	want := []string{
+		`test.hcl:1,1-1: Invalid identifier character option; The character '.' cannot be used as an extra identifier character, because it delimits other syntax.`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestParseConfigWithOptionsDuplicateAttributes(t *testing.T) {
+	src := []byte("a = 1\nb = 2\na = 3\na = 4\n")
+
+	tests := map[string]struct {
+		policy    DuplicateAttributePolicy
+		want      cty.Value
+		wantDiags []string
+	}{
+		"error": {
+			DuplicateAttributesError,
+			cty.NumberIntVal(1),
+			[]string{
+				`:3,1-2: Attribute redefined; The argument "a" was already set at :1,1-2. Each argument may be set only once.`,
+				`:4,1-2: Attribute redefined; The argument "a" was already set at :1,1-2. Each argument may be set only once.`,
+			},
+		},
+		"last wins": {
+			DuplicateAttributesLastWins,
+			cty.NumberIntVal(4),
+			[]string{
+				`:3,1-2: Attribute redefined; The argument "a" was already set at :1,1-2. This definition overrides the earlier one.`,
+				`:4,1-2: Attribute redefined; The argument "a" was already set at :3,1-2. This definition overrides the earlier one.`,
+			},
+		},
+		"first wins": {
+			DuplicateAttributesFirstWins,
+			cty.NumberIntVal(1),
+			[]string{
+				`:3,1-2: Attribute redefined; The argument "a" was already set at :1,1-2. This definition is ignored.`,
+				`:4,1-2: Attribute redefined; The argument "a" was already set at :1,1-2. This definition is ignored.`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+				DuplicateAttributes: test.policy,
+			})
+
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if !reflect.DeepEqual(gotDiags, test.wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.wantDiags)
+			}
+			if got, want := diags.HasErrors(), test.policy == DuplicateAttributesError; got != want {
+				t.Errorf("wrong HasErrors %t; want %t", got, want)
+			}
+
+			attrs := f.Body.(*Body).Attributes
+			if got, want := len(attrs), 2; got != want {
+				t.Fatalf("wrong number of attributes %d; want %d", got, want)
+			}
+			got, _ := attrs["a"].Expr.Value(nil)
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong value for a\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseConfigWithOptionsColonAssignment(t *testing.T) {
+	src := []byte("a: 1\nb = 2\nc: true ? { x: 1 } : { for k, v in {} : k => v }\nblk { d: 4 }\n")
+
+	t.Run("error", func(t *testing.T) {
+		_, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want error")
+		}
+		if got, want := diags[0].Error(), `:1,1-2: Argument or block definition required; An argument or block definition is required here. To set an argument, use the equals sign "=" to introduce the argument value.`; got != want {
+			t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+
+	tests := map[string]struct {
+		policy    ColonAssignmentPolicy
+		wantDiags []string
+	}{
+		"allowed": {
+			ColonAssignmentAllowed,
+			nil,
+		},
+		"warning": {
+			ColonAssignmentWarning,
+			[]string{
+				`:1,2-3: Deprecated colon in argument definition; Argument definitions should use the equals sign "=" rather than a colon to introduce the argument value.`,
+				`:3,2-3: Deprecated colon in argument definition; Argument definitions should use the equals sign "=" rather than a colon to introduce the argument value.`,
+				`:4,8-9: Deprecated colon in argument definition; Argument definitions should use the equals sign "=" rather than a colon to introduce the argument value.`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+				ColonAssignment: test.policy,
+			})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if !reflect.DeepEqual(gotDiags, test.wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.wantDiags)
+			}
+
+			body := f.Body.(*Body)
+			want := map[string]cty.Value{
+				"a": cty.NumberIntVal(1),
+				"b": cty.NumberIntVal(2),
+				"c": cty.MapVal(map[string]cty.Value{
+					"x": cty.NumberIntVal(1),
+				}),
+			}
+			for name, wantVal := range want {
+				attr := body.Attributes[name]
+				if attr == nil {
+					t.Errorf("missing attribute %q", name)
+					continue
+				}
+				got, valDiags := attr.Expr.Value(nil)
+				if valDiags.HasErrors() {
+					t.Errorf("unexpected diagnostics for %q: %s", name, valDiags.Error())
+				}
+				if !got.RawEquals(wantVal) {
+					t.Errorf("wrong value for %q\ngot:  %#v\nwant: %#v", name, got, wantVal)
+				}
+			}
+			if got, want := body.Attributes["a"].EqualsRange, (hcl.Range{Start: hcl.Pos{Line: 1, Column: 2, Byte: 1}, End: hcl.Pos{Line: 1, Column: 3, Byte: 2}}); got != want {
+				t.Errorf("wrong EqualsRange for a\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got := len(body.Blocks); got != 1 {
+				t.Fatalf("wrong number of blocks %d; want 1", got)
+			}
+			if attr := body.Blocks[0].Body.Attributes["d"]; attr == nil {
+				t.Errorf("missing attribute d in block")
+			}
+		})
+	}
+}
+
+func TestParseConfigWithOptionsWarnUnquotedLabels(t *testing.T) {
+	src := []byte("resource aws_instance \"web\" {\n}\nresource \"a\" b {}\n")
+
+	for _, warn := range []bool{false, true} {
+		t.Run(fmt.Sprintf("warn=%t", warn), func(t *testing.T) {
+			f, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+				WarnUnquotedLabels: warn,
+			})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			var wantDiags []string
+			if warn {
+				wantDiags = []string{
+					`:1,10-22: Deprecated unquoted block label; Block labels should be written as quoted strings. To silence this warning, write this label as "aws_instance".`,
+					`:3,14-15: Deprecated unquoted block label; Block labels should be written as quoted strings. To silence this warning, write this label as "b".`,
+				}
+			}
+			if !reflect.DeepEqual(gotDiags, wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, wantDiags)
+			}
+
+			blocks := f.Body.(*Body).Blocks
+			if got, want := len(blocks), 2; got != want {
+				t.Fatalf("wrong number of blocks %d; want %d", got, want)
+			}
+			if got, want := blocks[0].Labels, []string{"aws_instance", "web"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("wrong labels for first block\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := blocks[1].Labels, []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("wrong labels for second block\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseConfigWithOptionsUnquotedLineValues(t *testing.T) {
+	src := []byte(`name = John Smith
+count = 10
+quoted = "it's fine"
+ref = true
+addr = 10.0.0.1
+url = http://example.com/#top
+quote = it's "unterminated
+after = 2
+block {
+  msg = héllo wörld   
+  list = [
+    1,
+  ]
+}
+`)
+
+	f, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+		UnquotedLineValues: true,
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	body := f.Body.(*Body)
+	want := map[string]cty.Value{
+		"name":   cty.StringVal("John Smith"),
+		"count":  cty.NumberIntVal(10),
+		"quoted": cty.StringVal("it's fine"),
+		"ref":    cty.True,
+		"addr":   cty.StringVal("10.0.0.1"),
+		"url":    cty.StringVal("http://example.com/#top"),
+		"quote":  cty.StringVal(`it's "unterminated`),
+		"after":  cty.NumberIntVal(2),
+	}
+	for name, wantVal := range want {
+		attr := body.Attributes[name]
+		if attr == nil {
+			t.Errorf("missing attribute %q", name)
+			continue
+		}
+		got, valDiags := attr.Expr.Value(nil)
+		if valDiags.HasErrors() {
+			t.Errorf("unexpected diagnostics for %q: %s", name, valDiags.Error())
+		}
+		if !got.RawEquals(wantVal) {
+			t.Errorf("wrong value for %q\ngot:  %#v\nwant: %#v", name, got, wantVal)
+		}
+	}
+
+	if got, want := body.Attributes["name"].Expr.Range(), (hcl.Range{Start: hcl.Pos{Line: 1, Column: 8, Byte: 7}, End: hcl.Pos{Line: 1, Column: 18, Byte: 17}}); got != want {
+		t.Errorf("wrong range for name\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	block := body.Blocks[0].Body
+	msg := block.Attributes["msg"]
+	got, _ := msg.Expr.Value(nil)
+	if want := cty.StringVal("héllo wörld"); !got.RawEquals(want) {
+		t.Errorf("wrong value for msg\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := msg.Expr.Range(), (hcl.Range{Start: hcl.Pos{Line: 10, Column: 9, Byte: 160}, End: hcl.Pos{Line: 10, Column: 20, Byte: 173}}); got != want {
+		t.Errorf("wrong range for msg\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if _, ok := block.Attributes["list"].Expr.(*TupleConsExpr); !ok {
+		t.Errorf("list is %T, not *TupleConsExpr", block.Attributes["list"].Expr)
+	}
+
+	// Without the option, the same source is invalid.
+	_, diags = ParseConfig(src, "", hcl.InitialPos)
+	if !diags.HasErrors() {
+		t.Errorf("unexpected success without UnquotedLineValues")
+	}
+}
+
+func TestParseConfigWithOptionsRawStrings(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		want      cty.Value
+		wantDiags []string
+	}{
+		"escapes and templates": {
+			"a = `C:\\Program Files\\${app}\\%{x}`\n",
+			cty.StringVal("C:\\Program Files\\${app}\\%{x}"),
+			nil,
+		},
+		"quotes": {
+			"a = `^[a-z]+\\d\\\"$`\n",
+			cty.StringVal("^[a-z]+\\d\\\"$"),
+			nil,
+		},
+		"multiple lines": {
+			"a = `line one\n  line two`\n",
+			cty.StringVal("line one\n  line two"),
+			nil,
+		},
+		"empty": {
+			"a = ``\n",
+			cty.StringVal(""),
+			nil,
+		},
+		"inside template": {
+			"a = \"${`raw ${\"}`}\"\n",
+			cty.StringVal("raw ${\"}"),
+			nil,
+		},
+		"unterminated": {
+			"a = `unterminated",
+			cty.StringVal("unterminated"),
+			[]string{
+				"test.hcl:1,5-6: Unterminated raw string; There is no closing backtick (\"`\") for this raw string before the end of the file.",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfigWithOptions([]byte(test.src), "test.hcl", hcl.InitialPos, ParseOptions{
+				RawStrings: true,
+			})
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if !reflect.DeepEqual(gotDiags, test.wantDiags) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.wantDiags)
+			}
+
+			got, valDiags := f.Body.(*Body).Attributes["a"].Expr.Value(nil)
+			if valDiags.HasErrors() {
+				t.Errorf("unexpected diagnostics: %s", valDiags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	// Without the option, a backtick is an invalid character.
+	_, diags := ParseConfig([]byte("a = `str`\n"), "", hcl.InitialPos)
+	if !diags.HasErrors() {
+		t.Errorf("unexpected success without RawStrings")
+	}
+}
+
+func TestParseConfigWithOptionsFeatureDirectives(t *testing.T) {
+	src := []byte(`# Managed by tooling.
+// hcl:feature colon_assignment raw_strings
+/* hcl:feature ignored_in_block_comments */
+
+#   hcl:feature	unquoted_line_values
+# hcl:feature
+# hcl:unknown
+name = John Smith
+count: 1
+path = ` + "`C:\\temp`" + `
+# hcl:feature exact_integers
+`)
+
+	t.Run("enabled", func(t *testing.T) {
+		f, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{
+			FeatureDirectives: true,
+		})
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+
+		var gotDiags []string
+		for _, diag := range diags {
+			gotDiags = append(gotDiags, diag.Error())
+		}
+		wantDiags := []string{
+			`:6,3-14: Invalid parser directive; An "hcl:feature" directive must be followed by the names of one or more features to enable.`,
+			`:7,3-14: Unknown parser directive; The directive "hcl:unknown" is not recognized and will be ignored. The only supported directive is "hcl:feature".`,
+		}
+		if !reflect.DeepEqual(gotDiags, wantDiags) {
+			t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, wantDiags)
+		}
+
+		attrs := f.Body.(*Body).Attributes
+		want := map[string]cty.Value{
+			"name":  cty.StringVal("John Smith"),
+			"count": cty.NumberIntVal(1),
+			"path":  cty.StringVal(`C:\temp`),
+		}
+		if got, want := len(attrs), len(want); got != want {
+			t.Fatalf("wrong number of attributes %d; want %d", got, want)
+		}
+		for name, wantVal := range want {
+			got, _ := attrs[name].Expr.Value(nil)
+			if !got.RawEquals(wantVal) {
+				t.Errorf("wrong value for %s\ngot:  %#v\nwant: %#v", name, got, wantVal)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, diags := ParseConfigWithOptions(src, "", hcl.InitialPos, ParseOptions{})
+		if !diags.HasErrors() {
+			t.Fatalf("unexpected success; directives should be ignored by default")
+		}
+	})
+}
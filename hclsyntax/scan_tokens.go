@@ -33,12 +33,13 @@ var _hcltok_actions []byte = []byte{
 	1, 75, 1, 76, 1, 77, 1, 78,
 	1, 79, 1, 80, 1, 81, 1, 82,
 	1, 83, 1, 84, 1, 85, 1, 86,
-	2, 0, 14, 2, 0, 25, 2, 0,
-	29, 2, 0, 37, 2, 0, 41, 2,
-	1, 2, 2, 4, 5, 2, 4, 6,
-	2, 4, 21, 2, 4, 22, 2, 4,
-	33, 2, 4, 34, 2, 4, 45, 2,
-	4, 46, 2, 4, 54, 2, 4, 55,
+	1, 87, 1, 88, 1, 89, 2, 0,
+	14, 2, 0, 25, 2, 0, 29, 2,
+	0, 37, 2, 0, 41, 2, 1, 2,
+	2, 4, 5, 2, 4, 6, 2, 4,
+	21, 2, 4, 22, 2, 4, 33, 2,
+	4, 34, 2, 4, 45, 2, 4, 46,
+	2, 4, 54, 2, 4, 55,
 }
 
 var _hcltok_key_offsets []int16 = []int16{
@@ -224,22 +225,57 @@ var _hcltok_key_offsets []int16 = []int16{
 	9153, 9171, 9172, 9182, 9183, 9192, 9200, 9202,
 	9205, 9207, 9209, 9211, 9216, 9229, 9233, 9248,
 	9277, 9288, 9290, 9294, 9298, 9303, 9307, 9309,
-	9316, 9320, 9328, 9332, 9408, 9410, 9411, 9412,
-	9413, 9414, 9415, 9417, 9422, 9423, 9425, 9427,
-	9428, 9472, 9473, 9474, 9476, 9481, 9485, 9485,
-	9487, 9489, 9500, 9510, 9518, 9519, 9521, 9522,
-	9526, 9530, 9540, 9544, 9551, 9562, 9569, 9573,
-	9579, 9590, 9622, 9671, 9686, 9701, 9706, 9708,
-	9713, 9745, 9753, 9755, 9777, 9799, 9801, 9817,
-	9833, 9835, 9837, 9837, 9838, 9839, 9840, 9842,
-	9843, 9855, 9857, 9859, 9861, 9875, 9889, 9891,
-	9894, 9897, 9899, 9900, 9901, 9903, 9905, 9907,
-	9921, 9935, 9937, 9940, 9943, 9945, 9946, 9947,
-	9949, 9951, 9953, 10002, 10046, 10048, 10053, 10057,
-	10057, 10059, 10061, 10072, 10082, 10090, 10091, 10093,
-	10094, 10098, 10102, 10112, 10116, 10123, 10134, 10141,
-	10145, 10151, 10162, 10194, 10243, 10258, 10273, 10278,
-	10280, 10285, 10317, 10325, 10327, 10349, 10371,
+	9316, 9320, 9328, 9332, 9334, 9338, 9344, 9344,
+	9346, 9348, 9357, 9363, 9370, 9371, 9374, 9375,
+	9379, 9384, 9393, 9397, 9401, 9409, 9411, 9413,
+	9415, 9418, 9450, 9452, 9454, 9458, 9462, 9465,
+	9476, 9489, 9508, 9521, 9537, 9549, 9565, 9580,
+	9601, 9611, 9623, 9634, 9648, 9663, 9673, 9685,
+	9694, 9706, 9708, 9712, 9733, 9742, 9752, 9758,
+	9764, 9765, 9814, 9816, 9820, 9822, 9828, 9835,
+	9843, 9850, 9853, 9859, 9863, 9867, 9869, 9873,
+	9877, 9881, 9887, 9895, 9903, 9909, 9911, 9915,
+	9917, 9923, 9927, 9931, 9935, 9939, 9944, 9951,
+	9957, 9959, 9961, 9965, 9967, 9973, 9977, 9981,
+	9991, 9996, 10010, 10025, 10027, 10035, 10037, 10042,
+	10056, 10061, 10063, 10067, 10068, 10072, 10078, 10084,
+	10094, 10104, 10115, 10123, 10126, 10129, 10133, 10137,
+	10139, 10142, 10142, 10145, 10147, 10177, 10179, 10181,
+	10185, 10190, 10194, 10199, 10201, 10203, 10205, 10214,
+	10218, 10222, 10228, 10230, 10238, 10246, 10258, 10261,
+	10267, 10271, 10273, 10277, 10297, 10299, 10301, 10312,
+	10318, 10320, 10322, 10324, 10328, 10334, 10340, 10342,
+	10347, 10351, 10353, 10361, 10379, 10419, 10429, 10433,
+	10435, 10437, 10438, 10442, 10446, 10450, 10454, 10458,
+	10463, 10467, 10471, 10475, 10477, 10479, 10483, 10493,
+	10497, 10499, 10503, 10507, 10511, 10524, 10526, 10528,
+	10532, 10534, 10538, 10540, 10542, 10572, 10576, 10580,
+	10584, 10587, 10594, 10599, 10610, 10614, 10630, 10644,
+	10648, 10653, 10657, 10661, 10667, 10669, 10675, 10677,
+	10681, 10683, 10689, 10694, 10699, 10709, 10711, 10713,
+	10717, 10721, 10723, 10736, 10738, 10742, 10746, 10754,
+	10756, 10760, 10762, 10763, 10766, 10771, 10773, 10775,
+	10779, 10781, 10785, 10791, 10811, 10817, 10823, 10825,
+	10826, 10836, 10837, 10845, 10852, 10854, 10857, 10859,
+	10861, 10863, 10868, 10872, 10876, 10881, 10891, 10901,
+	10905, 10909, 10923, 10949, 10959, 10961, 10963, 10966,
+	10968, 10971, 10973, 10977, 10979, 10980, 10984, 10986,
+	11064, 11066, 11067, 11068, 11069, 11070, 11071, 11073,
+	11079, 11080, 11081, 11083, 11085, 11086, 11130, 11131,
+	11132, 11134, 11139, 11143, 11143, 11145, 11147, 11158,
+	11168, 11176, 11177, 11179, 11180, 11184, 11188, 11198,
+	11202, 11209, 11220, 11227, 11231, 11237, 11248, 11280,
+	11329, 11344, 11359, 11364, 11366, 11371, 11403, 11411,
+	11413, 11435, 11457, 11459, 11469, 11473, 11483, 11527,
+	11543, 11559, 11561, 11563, 11563, 11564, 11565, 11566,
+	11568, 11569, 11581, 11583, 11585, 11587, 11601, 11615,
+	11617, 11620, 11623, 11625, 11626, 11627, 11629, 11631,
+	11633, 11647, 11661, 11663, 11666, 11669, 11671, 11672,
+	11673, 11675, 11677, 11679, 11728, 11772, 11774, 11779,
+	11783, 11783, 11785, 11787, 11798, 11808, 11816, 11817,
+	11819, 11820, 11824, 11828, 11838, 11842, 11849, 11860,
+	11867, 11871, 11877, 11888, 11920, 11969, 11984, 11999,
+	12004, 12006, 12011, 12043, 12051, 12053, 12075, 12097,
 }
 
 var _hcltok_trans_keys []byte = []byte{
@@ -1409,9 +1445,216 @@ var _hcltok_trans_keys []byte = []byte{
 	191, 192, 255, 158, 159, 186, 128, 185,
 	187, 191, 192, 255, 162, 191, 192, 255,
 	160, 168, 128, 159, 161, 167, 169, 191,
-	158, 191, 192, 255, 9, 10, 13, 32,
-	33, 34, 35, 38, 46, 47, 58, 60,
-	61, 62, 64, 92, 95, 123, 124, 125,
+	158, 191, 192, 255, 48, 57, 170, 181,
+	183, 186, 128, 150, 152, 182, 184, 255,
+	192, 255, 0, 127, 173, 130, 133, 146,
+	159, 165, 171, 175, 255, 181, 190, 184,
+	185, 192, 255, 140, 134, 138, 142, 161,
+	163, 255, 182, 130, 136, 137, 176, 151,
+	152, 154, 160, 190, 136, 144, 192, 255,
+	135, 129, 130, 132, 133, 144, 170, 176,
+	178, 144, 154, 160, 191, 128, 169, 174,
+	255, 148, 169, 157, 158, 189, 190, 192,
+	255, 144, 255, 139, 140, 178, 255, 186,
+	128, 181, 160, 161, 162, 163, 164, 165,
+	166, 167, 168, 169, 170, 171, 172, 173,
+	174, 175, 176, 177, 178, 179, 180, 181,
+	182, 183, 184, 185, 186, 187, 188, 189,
+	190, 191, 128, 173, 128, 155, 160, 180,
+	182, 189, 148, 161, 163, 255, 176, 164,
+	165, 132, 169, 177, 141, 142, 145, 146,
+	179, 181, 186, 187, 158, 133, 134, 137,
+	138, 143, 150, 152, 155, 164, 165, 178,
+	255, 188, 129, 131, 133, 138, 143, 144,
+	147, 168, 170, 176, 178, 179, 181, 182,
+	184, 185, 190, 255, 157, 131, 134, 137,
+	138, 142, 144, 146, 152, 159, 165, 182,
+	255, 129, 131, 133, 141, 143, 145, 147,
+	168, 170, 176, 178, 179, 181, 185, 188,
+	255, 134, 138, 142, 143, 145, 159, 164,
+	165, 176, 184, 186, 255, 129, 131, 133,
+	140, 143, 144, 147, 168, 170, 176, 178,
+	179, 181, 185, 188, 191, 177, 128, 132,
+	135, 136, 139, 141, 150, 151, 156, 157,
+	159, 163, 166, 175, 156, 130, 131, 133,
+	138, 142, 144, 146, 149, 153, 154, 158,
+	159, 163, 164, 168, 170, 174, 185, 190,
+	191, 144, 151, 128, 130, 134, 136, 138,
+	141, 166, 175, 128, 131, 133, 140, 142,
+	144, 146, 168, 170, 185, 189, 255, 133,
+	137, 151, 142, 148, 155, 159, 164, 165,
+	176, 255, 128, 131, 133, 140, 142, 144,
+	146, 168, 170, 179, 181, 185, 188, 191,
+	158, 128, 132, 134, 136, 138, 141, 149,
+	150, 160, 163, 166, 175, 177, 178, 129,
+	131, 133, 140, 142, 144, 146, 186, 189,
+	255, 133, 137, 143, 147, 152, 158, 164,
+	165, 176, 185, 192, 255, 189, 130, 131,
+	133, 150, 154, 177, 179, 187, 138, 150,
+	128, 134, 143, 148, 152, 159, 166, 175,
+	178, 179, 129, 186, 128, 142, 144, 153,
+	132, 138, 141, 165, 167, 129, 130, 135,
+	136, 148, 151, 153, 159, 161, 163, 170,
+	171, 173, 185, 187, 189, 134, 128, 132,
+	136, 141, 144, 153, 156, 159, 128, 181,
+	183, 185, 152, 153, 160, 169, 190, 191,
+	128, 135, 137, 172, 177, 191, 128, 132,
+	134, 151, 153, 188, 134, 128, 129, 130,
+	131, 137, 138, 139, 140, 141, 142, 143,
+	144, 153, 154, 155, 156, 157, 158, 159,
+	160, 161, 162, 163, 164, 165, 166, 167,
+	168, 169, 170, 173, 175, 176, 177, 178,
+	179, 181, 182, 183, 188, 189, 190, 191,
+	132, 152, 172, 184, 185, 187, 128, 191,
+	128, 137, 144, 255, 158, 159, 134, 187,
+	136, 140, 142, 143, 137, 151, 153, 142,
+	143, 158, 159, 137, 177, 142, 143, 182,
+	183, 191, 255, 128, 130, 133, 136, 150,
+	152, 255, 145, 150, 151, 155, 156, 160,
+	168, 178, 255, 128, 143, 160, 255, 182,
+	183, 190, 255, 129, 255, 173, 174, 192,
+	255, 129, 154, 160, 255, 171, 173, 185,
+	255, 128, 140, 142, 148, 160, 180, 128,
+	147, 160, 172, 174, 176, 178, 179, 148,
+	150, 152, 155, 158, 159, 170, 255, 139,
+	141, 144, 153, 160, 255, 184, 255, 128,
+	170, 176, 255, 182, 255, 128, 158, 160,
+	171, 176, 187, 134, 173, 176, 180, 128,
+	171, 176, 255, 138, 143, 155, 255, 128,
+	155, 160, 255, 159, 189, 190, 192, 255,
+	167, 128, 137, 144, 153, 176, 189, 140,
+	143, 154, 170, 180, 255, 180, 255, 128,
+	183, 128, 137, 141, 189, 128, 136, 144,
+	146, 148, 182, 184, 185, 128, 181, 187,
+	191, 150, 151, 158, 159, 152, 154, 156,
+	158, 134, 135, 142, 143, 190, 255, 190,
+	128, 180, 182, 188, 130, 132, 134, 140,
+	144, 147, 150, 155, 160, 172, 178, 180,
+	182, 188, 128, 129, 130, 131, 132, 133,
+	134, 176, 177, 178, 179, 180, 181, 182,
+	183, 191, 255, 129, 147, 149, 176, 178,
+	190, 192, 255, 144, 156, 161, 144, 156,
+	165, 176, 130, 135, 149, 164, 166, 168,
+	138, 147, 152, 157, 170, 185, 188, 191,
+	142, 133, 137, 160, 255, 137, 255, 128,
+	174, 176, 255, 159, 165, 170, 180, 255,
+	167, 173, 128, 165, 176, 255, 168, 174,
+	176, 190, 192, 255, 128, 150, 160, 166,
+	168, 174, 176, 182, 184, 190, 128, 134,
+	136, 142, 144, 150, 152, 158, 160, 191,
+	128, 129, 130, 131, 132, 133, 134, 135,
+	144, 145, 255, 133, 135, 161, 175, 177,
+	181, 184, 188, 160, 151, 152, 187, 192,
+	255, 133, 173, 177, 255, 143, 159, 187,
+	255, 176, 191, 182, 183, 184, 191, 192,
+	255, 150, 255, 128, 146, 147, 148, 152,
+	153, 154, 155, 156, 158, 159, 160, 161,
+	162, 163, 164, 165, 166, 167, 168, 169,
+	170, 171, 172, 173, 174, 175, 176, 129,
+	255, 141, 255, 144, 189, 141, 143, 172,
+	255, 191, 128, 175, 180, 189, 151, 159,
+	162, 255, 175, 137, 138, 184, 255, 183,
+	255, 168, 255, 128, 179, 188, 134, 143,
+	154, 159, 184, 186, 190, 255, 128, 173,
+	176, 255, 148, 159, 189, 255, 129, 142,
+	154, 159, 191, 255, 128, 182, 128, 141,
+	144, 153, 160, 182, 186, 255, 128, 130,
+	155, 157, 160, 175, 178, 182, 129, 134,
+	137, 142, 145, 150, 160, 166, 168, 174,
+	176, 255, 155, 166, 175, 128, 170, 172,
+	173, 176, 185, 158, 159, 160, 255, 164,
+	175, 135, 138, 188, 255, 164, 169, 171,
+	172, 173, 174, 175, 180, 181, 182, 183,
+	184, 185, 187, 188, 189, 190, 191, 165,
+	186, 174, 175, 154, 255, 190, 128, 134,
+	147, 151, 157, 168, 170, 182, 184, 188,
+	128, 129, 131, 132, 134, 255, 147, 255,
+	190, 255, 144, 145, 136, 175, 188, 255,
+	128, 143, 160, 175, 179, 180, 141, 143,
+	176, 180, 182, 255, 189, 255, 191, 144,
+	153, 161, 186, 129, 154, 166, 255, 191,
+	255, 130, 135, 138, 143, 146, 151, 154,
+	156, 144, 145, 146, 147, 148, 150, 151,
+	152, 155, 157, 158, 160, 170, 171, 172,
+	175, 161, 169, 128, 129, 130, 131, 133,
+	135, 138, 139, 140, 141, 142, 143, 144,
+	145, 146, 147, 148, 149, 152, 156, 157,
+	160, 161, 162, 163, 164, 166, 168, 169,
+	170, 171, 172, 173, 174, 176, 177, 153,
+	155, 178, 179, 128, 139, 141, 166, 168,
+	186, 188, 189, 191, 255, 142, 143, 158,
+	255, 187, 255, 128, 180, 189, 128, 156,
+	160, 255, 145, 159, 161, 255, 128, 159,
+	176, 255, 139, 143, 187, 255, 128, 157,
+	160, 255, 144, 132, 135, 150, 255, 158,
+	159, 170, 175, 148, 151, 188, 255, 128,
+	167, 176, 255, 164, 255, 183, 255, 128,
+	149, 160, 167, 136, 188, 128, 133, 138,
+	181, 183, 184, 191, 255, 150, 159, 183,
+	255, 128, 158, 160, 178, 180, 181, 128,
+	149, 160, 185, 128, 183, 190, 191, 191,
+	128, 131, 133, 134, 140, 147, 149, 151,
+	153, 179, 184, 186, 160, 188, 128, 156,
+	128, 135, 137, 166, 128, 181, 128, 149,
+	160, 178, 128, 145, 128, 178, 129, 130,
+	131, 132, 133, 135, 136, 138, 139, 140,
+	141, 144, 145, 146, 147, 150, 151, 152,
+	153, 154, 155, 156, 162, 163, 171, 176,
+	177, 178, 128, 134, 135, 165, 176, 190,
+	144, 168, 176, 185, 128, 180, 182, 191,
+	182, 144, 179, 155, 133, 137, 141, 143,
+	157, 255, 190, 128, 145, 147, 183, 136,
+	128, 134, 138, 141, 143, 157, 159, 168,
+	176, 255, 171, 175, 186, 255, 128, 131,
+	133, 140, 143, 144, 147, 168, 170, 176,
+	178, 179, 181, 185, 188, 191, 144, 151,
+	128, 132, 135, 136, 139, 141, 157, 163,
+	166, 172, 176, 180, 128, 138, 144, 153,
+	134, 136, 143, 154, 255, 128, 181, 184,
+	255, 129, 151, 158, 255, 129, 131, 133,
+	143, 154, 255, 128, 137, 128, 153, 157,
+	171, 176, 185, 160, 255, 170, 190, 192,
+	255, 128, 184, 128, 136, 138, 182, 184,
+	191, 128, 144, 153, 178, 255, 168, 144,
+	145, 183, 255, 128, 142, 145, 149, 129,
+	141, 144, 146, 147, 148, 175, 255, 132,
+	255, 128, 144, 129, 143, 144, 153, 145,
+	152, 135, 255, 160, 168, 169, 171, 172,
+	173, 174, 188, 189, 190, 191, 161, 167,
+	185, 255, 128, 158, 160, 169, 144, 173,
+	176, 180, 128, 131, 144, 153, 163, 183,
+	189, 255, 144, 255, 133, 143, 191, 255,
+	143, 159, 160, 128, 129, 255, 159, 160,
+	171, 172, 255, 173, 255, 179, 255, 128,
+	176, 177, 178, 128, 129, 171, 175, 189,
+	255, 128, 136, 144, 153, 157, 158, 133,
+	134, 137, 144, 145, 146, 147, 148, 149,
+	154, 155, 156, 157, 158, 159, 168, 169,
+	170, 150, 153, 165, 169, 173, 178, 187,
+	255, 131, 132, 140, 169, 174, 255, 130,
+	132, 149, 157, 173, 186, 188, 160, 161,
+	163, 164, 167, 168, 132, 134, 149, 157,
+	186, 139, 140, 191, 255, 134, 128, 132,
+	138, 144, 146, 255, 166, 167, 129, 155,
+	187, 149, 181, 143, 175, 137, 169, 131,
+	140, 141, 192, 255, 128, 182, 187, 255,
+	173, 180, 182, 255, 132, 155, 159, 161,
+	175, 128, 160, 163, 164, 165, 184, 185,
+	186, 161, 162, 128, 134, 136, 152, 155,
+	161, 163, 164, 166, 170, 133, 143, 151,
+	255, 139, 143, 154, 255, 164, 167, 185,
+	187, 128, 131, 133, 159, 161, 162, 169,
+	178, 180, 183, 130, 135, 137, 139, 148,
+	151, 153, 155, 157, 159, 164, 190, 141,
+	143, 145, 146, 161, 162, 167, 170, 172,
+	178, 180, 183, 185, 188, 128, 137, 139,
+	155, 161, 163, 165, 169, 171, 187, 155,
+	156, 151, 255, 156, 157, 160, 181, 255,
+	186, 187, 255, 162, 255, 160, 168, 161,
+	167, 158, 255, 160, 132, 135, 133, 134,
+	176, 255, 9, 10, 13, 32, 33, 34,
+	35, 38, 46, 47, 48, 58, 60, 61,
+	62, 63, 64, 92, 95, 123, 124, 125,
 	126, 127, 194, 195, 198, 199, 203, 204,
 	205, 206, 207, 210, 212, 213, 214, 215,
 	216, 217, 219, 220, 221, 222, 223, 224,
@@ -1420,126 +1663,135 @@ var _hcltok_trans_keys []byte = []byte{
 	59, 63, 65, 90, 91, 96, 97, 122,
 	192, 193, 196, 218, 229, 236, 241, 247,
 	9, 32, 10, 61, 10, 38, 46, 42,
-	47, 46, 69, 101, 48, 57, 58, 60,
-	61, 61, 62, 61, 45, 95, 194, 195,
-	198, 199, 203, 204, 205, 206, 207, 210,
-	212, 213, 214, 215, 216, 217, 219, 220,
-	221, 222, 223, 224, 225, 226, 227, 228,
-	233, 234, 237, 239, 240, 243, 48, 57,
-	65, 90, 97, 122, 196, 218, 229, 236,
-	124, 125, 128, 191, 170, 181, 186, 128,
-	191, 151, 183, 128, 255, 192, 255, 0,
-	127, 173, 130, 133, 146, 159, 165, 171,
-	175, 191, 192, 255, 181, 190, 128, 175,
-	176, 183, 184, 185, 186, 191, 134, 139,
-	141, 162, 128, 135, 136, 255, 182, 130,
-	137, 176, 151, 152, 154, 160, 136, 191,
-	192, 255, 128, 143, 144, 170, 171, 175,
-	176, 178, 179, 191, 128, 159, 160, 191,
-	176, 128, 138, 139, 173, 174, 255, 148,
-	150, 164, 167, 173, 176, 185, 189, 190,
-	192, 255, 144, 128, 145, 146, 175, 176,
-	191, 128, 140, 141, 255, 166, 176, 178,
-	191, 192, 255, 186, 128, 137, 138, 170,
-	171, 179, 180, 181, 182, 191, 160, 161,
-	162, 164, 165, 166, 167, 168, 169, 170,
-	171, 172, 173, 174, 175, 176, 177, 178,
-	179, 180, 181, 182, 183, 184, 185, 186,
-	187, 188, 189, 190, 128, 191, 128, 129,
-	130, 131, 137, 138, 139, 140, 141, 142,
-	143, 144, 153, 154, 155, 156, 157, 158,
-	159, 160, 161, 162, 163, 164, 165, 166,
-	167, 168, 169, 170, 171, 172, 173, 174,
-	175, 176, 177, 178, 179, 180, 182, 183,
-	184, 188, 189, 190, 191, 132, 187, 129,
-	130, 132, 133, 134, 176, 177, 178, 179,
-	180, 181, 182, 183, 128, 191, 128, 129,
-	130, 131, 132, 133, 134, 135, 144, 136,
-	143, 145, 191, 192, 255, 182, 183, 184,
-	128, 191, 128, 191, 191, 128, 190, 192,
-	255, 128, 146, 147, 148, 152, 153, 154,
-	155, 156, 158, 159, 160, 161, 162, 163,
-	164, 165, 166, 167, 168, 169, 170, 171,
-	172, 173, 174, 175, 176, 129, 191, 192,
-	255, 158, 159, 128, 157, 160, 191, 192,
-	255, 128, 191, 164, 169, 171, 172, 173,
-	174, 175, 180, 181, 182, 183, 184, 185,
-	187, 188, 189, 190, 191, 128, 163, 165,
-	186, 144, 145, 146, 147, 148, 150, 151,
-	152, 155, 157, 158, 160, 170, 171, 172,
-	175, 128, 159, 161, 169, 173, 191, 128,
-	191, 10, 13, 34, 36, 37, 92, 128,
-	191, 192, 223, 224, 239, 240, 247, 248,
-	255, 10, 13, 34, 92, 36, 37, 128,
-	191, 192, 223, 224, 239, 240, 247, 248,
-	255, 10, 13, 36, 123, 123, 126, 126,
-	37, 123, 126, 10, 13, 128, 191, 192,
-	223, 224, 239, 240, 247, 248, 255, 128,
-	191, 128, 191, 128, 191, 10, 13, 36,
-	37, 128, 191, 192, 223, 224, 239, 240,
-	247, 248, 255, 10, 13, 36, 37, 128,
-	191, 192, 223, 224, 239, 240, 247, 248,
-	255, 10, 13, 10, 13, 123, 10, 13,
-	126, 10, 13, 126, 126, 128, 191, 128,
+	47, 46, 69, 95, 101, 48, 57, 58,
+	63, 60, 61, 61, 62, 61, 45, 95,
+	194, 195, 198, 199, 203, 204, 205, 206,
+	207, 210, 212, 213, 214, 215, 216, 217,
+	219, 220, 221, 222, 223, 224, 225, 226,
+	227, 228, 233, 234, 237, 239, 240, 243,
+	48, 57, 65, 90, 97, 122, 196, 218,
+	229, 236, 124, 125, 128, 191, 170, 181,
+	186, 128, 191, 151, 183, 128, 255, 192,
+	255, 0, 127, 173, 130, 133, 146, 159,
+	165, 171, 175, 191, 192, 255, 181, 190,
+	128, 175, 176, 183, 184, 185, 186, 191,
+	134, 139, 141, 162, 128, 135, 136, 255,
+	182, 130, 137, 176, 151, 152, 154, 160,
+	136, 191, 192, 255, 128, 143, 144, 170,
+	171, 175, 176, 178, 179, 191, 128, 159,
+	160, 191, 176, 128, 138, 139, 173, 174,
+	255, 148, 150, 164, 167, 173, 176, 185,
+	189, 190, 192, 255, 144, 128, 145, 146,
+	175, 176, 191, 128, 140, 141, 255, 166,
+	176, 178, 191, 192, 255, 186, 128, 137,
+	138, 170, 171, 179, 180, 181, 182, 191,
+	160, 161, 162, 164, 165, 166, 167, 168,
+	169, 170, 171, 172, 173, 174, 175, 176,
+	177, 178, 179, 180, 181, 182, 183, 184,
+	185, 186, 187, 188, 189, 190, 128, 191,
+	128, 129, 130, 131, 137, 138, 139, 140,
+	141, 142, 143, 144, 153, 154, 155, 156,
+	157, 158, 159, 160, 161, 162, 163, 164,
+	165, 166, 167, 168, 169, 170, 171, 172,
+	173, 174, 175, 176, 177, 178, 179, 180,
+	182, 183, 184, 188, 189, 190, 191, 132,
+	187, 129, 130, 132, 133, 134, 176, 177,
+	178, 179, 180, 181, 182, 183, 128, 191,
+	128, 129, 130, 131, 132, 133, 134, 135,
+	144, 136, 143, 145, 191, 192, 255, 182,
+	183, 184, 128, 191, 128, 191, 191, 128,
+	190, 192, 255, 128, 146, 147, 148, 152,
+	153, 154, 155, 156, 158, 159, 160, 161,
+	162, 163, 164, 165, 166, 167, 168, 169,
+	170, 171, 172, 173, 174, 175, 176, 129,
+	191, 192, 255, 158, 159, 128, 157, 160,
+	191, 192, 255, 128, 191, 164, 169, 171,
+	172, 173, 174, 175, 180, 181, 182, 183,
+	184, 185, 187, 188, 189, 190, 191, 128,
+	163, 165, 186, 144, 145, 146, 147, 148,
+	150, 151, 152, 155, 157, 158, 160, 170,
+	171, 172, 175, 128, 159, 161, 169, 173,
+	191, 128, 191, 46, 69, 95, 101, 48,
+	57, 65, 90, 97, 122, 43, 45, 48,
+	57, 46, 66, 69, 88, 95, 98, 101,
+	120, 48, 57, 45, 95, 194, 195, 198,
+	199, 203, 204, 205, 206, 207, 210, 212,
+	213, 214, 215, 216, 217, 219, 220, 221,
+	222, 223, 224, 225, 226, 227, 228, 233,
+	234, 237, 239, 240, 243, 48, 57, 65,
+	90, 97, 122, 196, 218, 229, 236, 10,
+	13, 34, 36, 37, 92, 128, 191, 192,
+	223, 224, 239, 240, 247, 248, 255, 10,
+	13, 34, 92, 36, 37, 128, 191, 192,
+	223, 224, 239, 240, 247, 248, 255, 10,
+	13, 36, 123, 123, 126, 126, 37, 123,
+	126, 10, 13, 128, 191, 192, 223, 224,
+	239, 240, 247, 248, 255, 128, 191, 128,
 	191, 128, 191, 10, 13, 36, 37, 128,
 	191, 192, 223, 224, 239, 240, 247, 248,
 	255, 10, 13, 36, 37, 128, 191, 192,
 	223, 224, 239, 240, 247, 248, 255, 10,
 	13, 10, 13, 123, 10, 13, 126, 10,
 	13, 126, 126, 128, 191, 128, 191, 128,
-	191, 95, 194, 195, 198, 199, 203, 204,
+	191, 10, 13, 36, 37, 128, 191, 192,
+	223, 224, 239, 240, 247, 248, 255, 10,
+	13, 36, 37, 128, 191, 192, 223, 224,
+	239, 240, 247, 248, 255, 10, 13, 10,
+	13, 123, 10, 13, 126, 10, 13, 126,
+	126, 128, 191, 128, 191, 128, 191, 95,
+	194, 195, 198, 199, 203, 204, 205, 206,
+	207, 210, 212, 213, 214, 215, 216, 217,
+	219, 220, 221, 222, 223, 224, 225, 226,
+	227, 228, 233, 234, 237, 238, 239, 240,
+	65, 90, 97, 122, 128, 191, 192, 193,
+	196, 218, 229, 236, 241, 247, 248, 255,
+	45, 95, 194, 195, 198, 199, 203, 204,
 	205, 206, 207, 210, 212, 213, 214, 215,
 	216, 217, 219, 220, 221, 222, 223, 224,
-	225, 226, 227, 228, 233, 234, 237, 238,
-	239, 240, 65, 90, 97, 122, 128, 191,
-	192, 193, 196, 218, 229, 236, 241, 247,
-	248, 255, 45, 95, 194, 195, 198, 199,
-	203, 204, 205, 206, 207, 210, 212, 213,
-	214, 215, 216, 217, 219, 220, 221, 222,
-	223, 224, 225, 226, 227, 228, 233, 234,
-	237, 239, 240, 243, 48, 57, 65, 90,
-	97, 122, 196, 218, 229, 236, 128, 191,
-	170, 181, 186, 128, 191, 151, 183, 128,
-	255, 192, 255, 0, 127, 173, 130, 133,
-	146, 159, 165, 171, 175, 191, 192, 255,
-	181, 190, 128, 175, 176, 183, 184, 185,
-	186, 191, 134, 139, 141, 162, 128, 135,
-	136, 255, 182, 130, 137, 176, 151, 152,
-	154, 160, 136, 191, 192, 255, 128, 143,
-	144, 170, 171, 175, 176, 178, 179, 191,
-	128, 159, 160, 191, 176, 128, 138, 139,
-	173, 174, 255, 148, 150, 164, 167, 173,
-	176, 185, 189, 190, 192, 255, 144, 128,
-	145, 146, 175, 176, 191, 128, 140, 141,
-	255, 166, 176, 178, 191, 192, 255, 186,
-	128, 137, 138, 170, 171, 179, 180, 181,
-	182, 191, 160, 161, 162, 164, 165, 166,
-	167, 168, 169, 170, 171, 172, 173, 174,
-	175, 176, 177, 178, 179, 180, 181, 182,
-	183, 184, 185, 186, 187, 188, 189, 190,
-	128, 191, 128, 129, 130, 131, 137, 138,
-	139, 140, 141, 142, 143, 144, 153, 154,
-	155, 156, 157, 158, 159, 160, 161, 162,
-	163, 164, 165, 166, 167, 168, 169, 170,
-	171, 172, 173, 174, 175, 176, 177, 178,
-	179, 180, 182, 183, 184, 188, 189, 190,
-	191, 132, 187, 129, 130, 132, 133, 134,
-	176, 177, 178, 179, 180, 181, 182, 183,
-	128, 191, 128, 129, 130, 131, 132, 133,
-	134, 135, 144, 136, 143, 145, 191, 192,
-	255, 182, 183, 184, 128, 191, 128, 191,
-	191, 128, 190, 192, 255, 128, 146, 147,
-	148, 152, 153, 154, 155, 156, 158, 159,
-	160, 161, 162, 163, 164, 165, 166, 167,
-	168, 169, 170, 171, 172, 173, 174, 175,
-	176, 129, 191, 192, 255, 158, 159, 128,
-	157, 160, 191, 192, 255, 128, 191, 164,
-	169, 171, 172, 173, 174, 175, 180, 181,
-	182, 183, 184, 185, 187, 188, 189, 190,
-	191, 128, 163, 165, 186, 144, 145, 146,
-	147, 148, 150, 151, 152, 155, 157, 158,
-	160, 170, 171, 172, 175, 128, 159, 161,
-	169, 173, 191, 128, 191,
+	225, 226, 227, 228, 233, 234, 237, 239,
+	240, 243, 48, 57, 65, 90, 97, 122,
+	196, 218, 229, 236, 128, 191, 170, 181,
+	186, 128, 191, 151, 183, 128, 255, 192,
+	255, 0, 127, 173, 130, 133, 146, 159,
+	165, 171, 175, 191, 192, 255, 181, 190,
+	128, 175, 176, 183, 184, 185, 186, 191,
+	134, 139, 141, 162, 128, 135, 136, 255,
+	182, 130, 137, 176, 151, 152, 154, 160,
+	136, 191, 192, 255, 128, 143, 144, 170,
+	171, 175, 176, 178, 179, 191, 128, 159,
+	160, 191, 176, 128, 138, 139, 173, 174,
+	255, 148, 150, 164, 167, 173, 176, 185,
+	189, 190, 192, 255, 144, 128, 145, 146,
+	175, 176, 191, 128, 140, 141, 255, 166,
+	176, 178, 191, 192, 255, 186, 128, 137,
+	138, 170, 171, 179, 180, 181, 182, 191,
+	160, 161, 162, 164, 165, 166, 167, 168,
+	169, 170, 171, 172, 173, 174, 175, 176,
+	177, 178, 179, 180, 181, 182, 183, 184,
+	185, 186, 187, 188, 189, 190, 128, 191,
+	128, 129, 130, 131, 137, 138, 139, 140,
+	141, 142, 143, 144, 153, 154, 155, 156,
+	157, 158, 159, 160, 161, 162, 163, 164,
+	165, 166, 167, 168, 169, 170, 171, 172,
+	173, 174, 175, 176, 177, 178, 179, 180,
+	182, 183, 184, 188, 189, 190, 191, 132,
+	187, 129, 130, 132, 133, 134, 176, 177,
+	178, 179, 180, 181, 182, 183, 128, 191,
+	128, 129, 130, 131, 132, 133, 134, 135,
+	144, 136, 143, 145, 191, 192, 255, 182,
+	183, 184, 128, 191, 128, 191, 191, 128,
+	190, 192, 255, 128, 146, 147, 148, 152,
+	153, 154, 155, 156, 158, 159, 160, 161,
+	162, 163, 164, 165, 166, 167, 168, 169,
+	170, 171, 172, 173, 174, 175, 176, 129,
+	191, 192, 255, 158, 159, 128, 157, 160,
+	191, 192, 255, 128, 191, 164, 169, 171,
+	172, 173, 174, 175, 180, 181, 182, 183,
+	184, 185, 187, 188, 189, 190, 191, 128,
+	163, 165, 186, 144, 145, 146, 147, 148,
+	150, 151, 152, 155, 157, 158, 160, 170,
+	171, 172, 175, 128, 159, 161, 169, 173,
+	191, 128, 191,
 }
 
 var _hcltok_single_lengths []byte = []byte{
@@ -1725,22 +1977,57 @@ var _hcltok_single_lengths []byte = []byte{
 	12, 1, 4, 1, 5, 2, 0, 3,
 	2, 2, 2, 1, 7, 0, 7, 17,
 	3, 0, 2, 0, 3, 0, 0, 1,
-	0, 2, 0, 54, 2, 1, 1, 1,
-	1, 1, 2, 3, 1, 2, 2, 1,
-	34, 1, 1, 0, 3, 2, 0, 0,
-	0, 1, 2, 4, 1, 0, 1, 0,
-	0, 0, 0, 1, 1, 1, 0, 0,
-	1, 30, 47, 13, 9, 3, 0, 1,
-	28, 2, 0, 18, 16, 0, 6, 4,
-	2, 2, 0, 1, 1, 1, 2, 1,
-	2, 0, 0, 0, 4, 2, 2, 3,
-	3, 2, 1, 1, 0, 0, 0, 4,
-	2, 2, 3, 3, 2, 1, 1, 0,
-	0, 0, 33, 34, 0, 3, 2, 0,
-	0, 0, 1, 2, 4, 1, 0, 1,
-	0, 0, 0, 0, 1, 1, 1, 0,
-	0, 1, 30, 47, 13, 9, 3, 0,
-	1, 28, 2, 0, 18, 16, 0,
+	0, 2, 0, 0, 4, 0, 0, 0,
+	0, 1, 2, 1, 1, 1, 1, 0,
+	1, 1, 0, 0, 2, 0, 0, 0,
+	1, 32, 0, 0, 0, 0, 1, 3,
+	1, 1, 1, 0, 2, 0, 1, 1,
+	2, 0, 3, 0, 1, 0, 2, 1,
+	2, 0, 0, 5, 1, 4, 0, 0,
+	1, 43, 0, 0, 0, 2, 3, 2,
+	1, 1, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 1, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 4,
+	1, 0, 15, 0, 0, 0, 1, 6,
+	1, 0, 0, 1, 0, 2, 0, 0,
+	0, 9, 0, 1, 1, 0, 0, 0,
+	3, 0, 1, 0, 28, 0, 0, 0,
+	1, 0, 1, 0, 0, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 1, 0,
+	2, 0, 0, 18, 0, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 1,
+	0, 0, 0, 16, 36, 0, 0, 0,
+	0, 1, 0, 0, 0, 0, 0, 1,
+	0, 0, 0, 0, 0, 0, 2, 0,
+	0, 0, 0, 0, 1, 0, 0, 0,
+	0, 0, 0, 0, 28, 0, 0, 0,
+	1, 1, 1, 1, 0, 0, 2, 0,
+	1, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 1, 1, 4, 0, 0, 2,
+	2, 0, 11, 0, 0, 0, 0, 0,
+	0, 0, 1, 1, 3, 0, 0, 4,
+	0, 0, 0, 18, 0, 0, 0, 1,
+	4, 1, 4, 1, 0, 3, 2, 2,
+	2, 1, 0, 0, 1, 8, 0, 0,
+	0, 4, 12, 0, 2, 0, 3, 0,
+	1, 0, 2, 0, 1, 2, 0, 56,
+	2, 1, 1, 1, 1, 1, 2, 4,
+	1, 1, 2, 2, 1, 34, 1, 1,
+	0, 3, 2, 0, 0, 0, 1, 2,
+	4, 1, 0, 1, 0, 0, 0, 0,
+	1, 1, 1, 0, 0, 1, 30, 47,
+	13, 9, 3, 0, 1, 28, 2, 0,
+	18, 16, 0, 4, 2, 8, 34, 6,
+	4, 2, 2, 0, 1, 1, 1, 2,
+	1, 2, 0, 0, 0, 4, 2, 2,
+	3, 3, 2, 1, 1, 0, 0, 0,
+	4, 2, 2, 3, 3, 2, 1, 1,
+	0, 0, 0, 33, 34, 0, 3, 2,
+	0, 0, 0, 1, 2, 4, 1, 0,
+	1, 0, 0, 0, 0, 1, 1, 1,
+	0, 0, 1, 30, 47, 13, 9, 3,
+	0, 1, 28, 2, 0, 18, 16, 0,
 }
 
 var _hcltok_range_lengths []byte = []byte{
@@ -1926,22 +2213,57 @@ var _hcltok_range_lengths []byte = []byte{
 	3, 0, 3, 0, 2, 3, 1, 0,
 	0, 0, 0, 2, 3, 2, 4, 6,
 	4, 1, 1, 2, 1, 2, 1, 3,
-	2, 3, 2, 11, 0, 0, 0, 0,
-	0, 0, 0, 1, 0, 0, 0, 0,
-	5, 0, 0, 1, 1, 1, 0, 1,
-	1, 5, 4, 2, 0, 1, 0, 2,
-	2, 5, 2, 3, 5, 3, 2, 3,
-	5, 1, 1, 1, 3, 1, 1, 2,
-	2, 3, 1, 2, 3, 1, 5, 6,
-	0, 0, 0, 0, 0, 0, 0, 0,
-	5, 1, 1, 1, 5, 6, 0, 0,
-	0, 0, 0, 0, 1, 1, 1, 5,
-	6, 0, 0, 0, 0, 0, 0, 1,
-	1, 1, 8, 5, 1, 1, 1, 0,
-	1, 1, 5, 4, 2, 0, 1, 0,
-	2, 2, 5, 2, 3, 5, 3, 2,
-	3, 5, 1, 1, 1, 3, 1, 1,
-	2, 2, 3, 1, 2, 3, 1,
+	2, 3, 2, 1, 0, 3, 0, 1,
+	1, 4, 2, 3, 0, 1, 0, 2,
+	2, 4, 2, 2, 3, 1, 1, 1,
+	1, 0, 1, 1, 2, 2, 1, 4,
+	6, 9, 6, 8, 5, 8, 7, 10,
+	4, 6, 4, 7, 7, 5, 5, 4,
+	5, 1, 2, 8, 4, 3, 3, 3,
+	0, 3, 1, 2, 1, 2, 2, 3,
+	3, 1, 3, 2, 2, 1, 2, 2,
+	2, 3, 4, 4, 3, 1, 2, 1,
+	3, 2, 2, 2, 2, 2, 3, 3,
+	1, 1, 2, 1, 3, 2, 2, 3,
+	2, 7, 0, 1, 4, 1, 2, 4,
+	2, 1, 2, 0, 2, 2, 3, 5,
+	5, 1, 4, 1, 1, 2, 2, 1,
+	0, 0, 1, 1, 1, 1, 1, 2,
+	2, 2, 2, 1, 1, 1, 4, 2,
+	2, 3, 1, 4, 4, 6, 1, 3,
+	1, 1, 2, 1, 1, 1, 5, 3,
+	1, 1, 1, 2, 3, 3, 1, 2,
+	2, 1, 4, 1, 2, 5, 2, 1,
+	1, 0, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 1, 1, 2, 4, 2,
+	1, 2, 2, 2, 6, 1, 1, 2,
+	1, 2, 1, 1, 1, 2, 2, 2,
+	1, 3, 2, 5, 2, 8, 6, 2,
+	2, 2, 2, 3, 1, 3, 1, 2,
+	1, 3, 2, 2, 3, 1, 1, 1,
+	1, 1, 1, 1, 2, 2, 4, 1,
+	2, 1, 0, 1, 1, 1, 1, 0,
+	1, 2, 3, 1, 3, 3, 1, 0,
+	3, 0, 2, 3, 1, 0, 0, 0,
+	0, 2, 2, 2, 2, 1, 5, 2,
+	2, 5, 7, 5, 0, 1, 0, 1,
+	1, 1, 1, 1, 0, 1, 1, 11,
+	0, 0, 0, 0, 0, 0, 0, 1,
+	0, 0, 0, 0, 0, 5, 0, 0,
+	1, 1, 1, 0, 1, 1, 5, 4,
+	2, 0, 1, 0, 2, 2, 5, 2,
+	3, 5, 3, 2, 3, 5, 1, 1,
+	1, 3, 1, 1, 2, 2, 3, 1,
+	2, 3, 1, 3, 1, 1, 5, 5,
+	6, 0, 0, 0, 0, 0, 0, 0,
+	0, 5, 1, 1, 1, 5, 6, 0,
+	0, 0, 0, 0, 0, 1, 1, 1,
+	5, 6, 0, 0, 0, 0, 0, 0,
+	1, 1, 1, 8, 5, 1, 1, 1,
+	0, 1, 1, 5, 4, 2, 0, 1,
+	0, 2, 2, 5, 2, 3, 5, 3,
+	2, 3, 5, 1, 1, 1, 3, 1,
+	1, 2, 2, 3, 1, 2, 3, 1,
 }
 
 var _hcltok_index_offsets []int16 = []int16{
@@ -2127,22 +2449,57 @@ var _hcltok_index_offsets []int16 = []int16{
 	7187, 7203, 7205, 7213, 7215, 7223, 7229, 7231,
 	7235, 7238, 7241, 7244, 7248, 7259, 7262, 7274,
 	7298, 7306, 7308, 7312, 7315, 7320, 7323, 7325,
-	7330, 7333, 7339, 7342, 7408, 7411, 7413, 7415,
-	7417, 7419, 7421, 7424, 7429, 7431, 7434, 7437,
-	7439, 7479, 7481, 7483, 7485, 7490, 7494, 7495,
-	7497, 7499, 7506, 7513, 7520, 7522, 7524, 7526,
-	7529, 7532, 7538, 7541, 7546, 7553, 7558, 7561,
-	7565, 7572, 7604, 7653, 7668, 7681, 7686, 7688,
-	7692, 7723, 7729, 7731, 7752, 7772, 7774, 7786,
-	7797, 7800, 7803, 7804, 7806, 7808, 7810, 7813,
-	7815, 7823, 7825, 7827, 7829, 7839, 7848, 7851,
-	7855, 7859, 7862, 7864, 7866, 7868, 7870, 7872,
-	7882, 7891, 7894, 7898, 7902, 7905, 7907, 7909,
-	7911, 7913, 7915, 7957, 7997, 7999, 8004, 8008,
-	8009, 8011, 8013, 8020, 8027, 8034, 8036, 8038,
-	8040, 8043, 8046, 8052, 8055, 8060, 8067, 8072,
-	8075, 8079, 8086, 8118, 8167, 8182, 8195, 8200,
-	8202, 8206, 8237, 8243, 8245, 8266, 8286,
+	7330, 7333, 7339, 7342, 7344, 7349, 7353, 7354,
+	7356, 7358, 7364, 7369, 7374, 7376, 7379, 7381,
+	7384, 7388, 7394, 7397, 7400, 7406, 7408, 7410,
+	7412, 7415, 7448, 7450, 7452, 7455, 7458, 7461,
+	7469, 7477, 7488, 7496, 7505, 7513, 7522, 7531,
+	7543, 7550, 7557, 7565, 7573, 7582, 7588, 7596,
+	7602, 7610, 7612, 7615, 7629, 7635, 7643, 7647,
+	7651, 7653, 7700, 7702, 7705, 7707, 7712, 7718,
+	7724, 7729, 7732, 7736, 7739, 7742, 7744, 7747,
+	7750, 7753, 7757, 7762, 7767, 7771, 7773, 7776,
+	7778, 7782, 7785, 7788, 7791, 7794, 7798, 7803,
+	7807, 7809, 7811, 7814, 7816, 7820, 7823, 7826,
+	7834, 7838, 7846, 7862, 7864, 7869, 7871, 7875,
+	7886, 7890, 7892, 7895, 7897, 7900, 7905, 7909,
+	7915, 7921, 7932, 7937, 7940, 7943, 7946, 7949,
+	7951, 7955, 7956, 7959, 7961, 7991, 7993, 7995,
+	7998, 8002, 8005, 8009, 8011, 8013, 8015, 8021,
+	8024, 8027, 8031, 8033, 8038, 8043, 8050, 8053,
+	8057, 8061, 8063, 8066, 8086, 8088, 8090, 8097,
+	8101, 8103, 8105, 8107, 8110, 8114, 8118, 8120,
+	8124, 8127, 8129, 8134, 8152, 8191, 8197, 8200,
+	8202, 8204, 8206, 8209, 8212, 8215, 8218, 8221,
+	8225, 8228, 8231, 8234, 8236, 8238, 8241, 8248,
+	8251, 8253, 8256, 8259, 8262, 8270, 8272, 8274,
+	8277, 8279, 8282, 8284, 8286, 8316, 8319, 8322,
+	8325, 8328, 8333, 8337, 8344, 8347, 8356, 8365,
+	8368, 8372, 8375, 8378, 8382, 8384, 8388, 8390,
+	8393, 8395, 8399, 8403, 8407, 8415, 8417, 8419,
+	8423, 8427, 8429, 8442, 8444, 8447, 8450, 8455,
+	8457, 8460, 8462, 8464, 8467, 8472, 8474, 8476,
+	8481, 8483, 8486, 8490, 8510, 8514, 8518, 8520,
+	8522, 8530, 8532, 8539, 8544, 8546, 8550, 8553,
+	8556, 8559, 8563, 8566, 8569, 8573, 8583, 8589,
+	8592, 8595, 8605, 8625, 8631, 8634, 8636, 8640,
+	8642, 8645, 8647, 8651, 8653, 8655, 8659, 8661,
+	8729, 8732, 8734, 8736, 8738, 8740, 8742, 8745,
+	8751, 8753, 8755, 8758, 8761, 8763, 8803, 8805,
+	8807, 8809, 8814, 8818, 8819, 8821, 8823, 8830,
+	8837, 8844, 8846, 8848, 8850, 8853, 8856, 8862,
+	8865, 8870, 8877, 8882, 8885, 8889, 8896, 8928,
+	8977, 8992, 9005, 9010, 9012, 9016, 9047, 9053,
+	9055, 9076, 9096, 9098, 9106, 9110, 9120, 9160,
+	9172, 9183, 9186, 9189, 9190, 9192, 9194, 9196,
+	9199, 9201, 9209, 9211, 9213, 9215, 9225, 9234,
+	9237, 9241, 9245, 9248, 9250, 9252, 9254, 9256,
+	9258, 9268, 9277, 9280, 9284, 9288, 9291, 9293,
+	9295, 9297, 9299, 9301, 9343, 9383, 9385, 9390,
+	9394, 9395, 9397, 9399, 9406, 9413, 9420, 9422,
+	9424, 9426, 9429, 9432, 9438, 9441, 9446, 9453,
+	9458, 9461, 9465, 9472, 9504, 9553, 9568, 9581,
+	9586, 9588, 9592, 9623, 9629, 9631, 9652, 9672,
 }
 
 var _hcltok_indicies []int16 = []int16{
@@ -3063,134 +3420,308 @@ var _hcltok_indicies []int16 = []int16{
 	1046, 1045, 795, 1138, 1050, 1139, 1059, 801,
 	1046, 1045, 795, 1046, 795, 1140, 1059, 1047,
 	1045, 801, 1046, 1045, 795, 1050, 1141, 1047,
-	1059, 1047, 1045, 1046, 1045, 795, 1142, 1143,
-	1144, 1142, 1145, 1146, 1147, 1149, 1150, 1151,
-	1152, 1153, 1154, 1155, 670, 670, 419, 1156,
-	1157, 1158, 1159, 670, 1162, 1163, 1165, 1166,
-	1167, 1161, 1168, 1169, 1170, 1171, 1172, 1173,
-	1174, 1175, 1176, 1177, 1178, 1179, 1180, 1181,
-	1182, 1183, 1184, 1185, 1186, 1187, 1189, 1190,
-	1191, 1192, 1193, 1194, 670, 1148, 7, 1148,
-	419, 1148, 419, 1161, 1164, 1188, 1195, 1160,
-	1142, 1142, 1196, 1143, 1197, 1199, 1198, 4,
-	1147, 1201, 1198, 1202, 1198, 2, 1147, 1198,
-	6, 8, 8, 7, 1203, 1204, 1198, 1205,
-	1206, 1198, 1207, 1208, 1198, 1209, 1198, 419,
-	419, 1211, 1212, 489, 470, 1213, 470, 1214,
-	1215, 1216, 1217, 1218, 1219, 1220, 1221, 1222,
-	1223, 1224, 544, 1225, 520, 1226, 1227, 1228,
-	1229, 1230, 1231, 1232, 1233, 1234, 1235, 1236,
-	1237, 419, 419, 419, 425, 565, 1210, 1238,
-	1198, 1239, 1198, 670, 1240, 419, 419, 419,
-	670, 1240, 670, 670, 419, 1240, 419, 1240,
-	419, 1240, 419, 670, 670, 670, 670, 670,
-	1240, 419, 670, 670, 670, 419, 670, 419,
-	1240, 419, 670, 670, 670, 670, 419, 1240,
-	670, 419, 670, 419, 670, 419, 670, 670,
-	419, 670, 1240, 419, 670, 419, 670, 419,
-	670, 1240, 670, 419, 1240, 670, 419, 670,
-	419, 1240, 670, 670, 670, 670, 670, 1240,
-	419, 419, 670, 419, 670, 1240, 670, 419,
-	1240, 670, 670, 1240, 419, 419, 670, 419,
-	670, 419, 670, 1240, 1241, 1242, 1243, 1244,
-	1245, 1246, 1247, 1248, 1249, 1250, 1251, 715,
-	1252, 1253, 1254, 1255, 1256, 1257, 1258, 1259,
-	1260, 1261, 1262, 1263, 1262, 1264, 1265, 1266,
-	1267, 1268, 671, 1240, 1269, 1270, 1271, 1272,
-	1273, 1274, 1275, 1276, 1277, 1278, 1279, 1280,
-	1281, 1282, 1283, 1284, 1285, 1286, 1287, 725,
-	1288, 1289, 1290, 692, 1291, 1292, 1293, 1294,
-	1295, 1296, 671, 1297, 1298, 1299, 1300, 1301,
-	1302, 1303, 1304, 674, 1305, 671, 674, 1306,
-	1307, 1308, 1309, 683, 1240, 1310, 1311, 1312,
-	1313, 703, 1314, 1315, 683, 1316, 1317, 1318,
-	1319, 1320, 671, 1240, 1321, 1280, 1322, 1323,
-	1324, 683, 1325, 1326, 674, 671, 683, 425,
-	1240, 1290, 671, 674, 683, 425, 683, 425,
-	1327, 683, 1240, 425, 674, 1328, 1329, 674,
-	1330, 1331, 681, 1332, 1333, 1334, 1335, 1336,
-	1286, 1337, 1338, 1339, 1340, 1341, 1342, 1343,
-	1344, 1345, 1346, 1347, 1348, 1305, 1349, 674,
-	683, 425, 1240, 1350, 1351, 683, 671, 1240,
-	425, 671, 1240, 674, 1352, 731, 1353, 1354,
-	1355, 1356, 1357, 1358, 1359, 1360, 671, 1361,
-	1362, 1363, 1364, 1365, 1366, 671, 683, 1240,
-	1368, 1369, 1370, 1371, 1372, 1373, 1374, 1375,
-	1376, 1377, 1378, 1374, 1380, 1381, 1382, 1383,
-	1367, 1379, 1367, 1240, 1367, 1240, 1384, 1384,
-	1385, 1386, 1387, 1388, 1389, 1390, 1391, 1392,
-	1389, 767, 1393, 1393, 1393, 1394, 1393, 1393,
-	768, 769, 770, 1393, 767, 1384, 1384, 1395,
-	1398, 1399, 1397, 1400, 1401, 1400, 1402, 1393,
-	1404, 1403, 1398, 1405, 1397, 1407, 1406, 1396,
-	1396, 1396, 768, 769, 770, 1396, 767, 767,
-	1408, 773, 1408, 1409, 1408, 775, 1410, 1411,
-	1412, 1413, 1414, 1415, 1416, 1413, 776, 775,
-	1410, 1417, 1417, 777, 779, 1418, 1417, 776,
-	1420, 1421, 1419, 1420, 1421, 1422, 1419, 775,
-	1410, 1423, 1417, 775, 1410, 1417, 1425, 1424,
-	1427, 1426, 776, 1428, 777, 1428, 779, 1428,
-	785, 1429, 1430, 1431, 1432, 1433, 1434, 1435,
-	1432, 786, 785, 1429, 1436, 1436, 787, 789,
-	1437, 1436, 786, 1439, 1440, 1438, 1439, 1440,
-	1441, 1438, 785, 1429, 1442, 1436, 785, 1429,
-	1436, 1444, 1443, 1446, 1445, 786, 1447, 787,
-	1447, 789, 1447, 795, 1450, 1451, 1453, 1454,
-	1455, 1449, 1456, 1457, 1458, 1459, 1460, 1461,
-	1462, 1463, 1464, 1465, 1466, 1467, 1468, 1469,
-	1470, 1471, 1472, 1473, 1474, 1475, 1477, 1478,
-	1479, 1480, 1481, 1482, 795, 795, 1448, 1449,
-	1452, 1476, 1483, 1448, 1046, 795, 795, 1485,
-	1486, 865, 846, 1487, 846, 1488, 1489, 1490,
-	1491, 1492, 1493, 1494, 1495, 1496, 1497, 1498,
-	920, 1499, 896, 1500, 1501, 1502, 1503, 1504,
-	1505, 1506, 1507, 1508, 1509, 1510, 1511, 795,
-	795, 795, 801, 941, 1484, 1046, 1512, 795,
-	795, 795, 1046, 1512, 1046, 1046, 795, 1512,
-	795, 1512, 795, 1512, 795, 1046, 1046, 1046,
-	1046, 1046, 1512, 795, 1046, 1046, 1046, 795,
-	1046, 795, 1512, 795, 1046, 1046, 1046, 1046,
-	795, 1512, 1046, 795, 1046, 795, 1046, 795,
-	1046, 1046, 795, 1046, 1512, 795, 1046, 795,
-	1046, 795, 1046, 1512, 1046, 795, 1512, 1046,
-	795, 1046, 795, 1512, 1046, 1046, 1046, 1046,
-	1046, 1512, 795, 795, 1046, 795, 1046, 1512,
-	1046, 795, 1512, 1046, 1046, 1512, 795, 795,
-	1046, 795, 1046, 795, 1046, 1512, 1513, 1514,
-	1515, 1516, 1517, 1518, 1519, 1520, 1521, 1522,
-	1523, 1091, 1524, 1525, 1526, 1527, 1528, 1529,
-	1530, 1531, 1532, 1533, 1534, 1535, 1534, 1536,
-	1537, 1538, 1539, 1540, 1047, 1512, 1541, 1542,
-	1543, 1544, 1545, 1546, 1547, 1548, 1549, 1550,
-	1551, 1552, 1553, 1554, 1555, 1556, 1557, 1558,
-	1559, 1101, 1560, 1561, 1562, 1068, 1563, 1564,
-	1565, 1566, 1567, 1568, 1047, 1569, 1570, 1571,
-	1572, 1573, 1574, 1575, 1576, 1050, 1577, 1047,
-	1050, 1578, 1579, 1580, 1581, 1059, 1512, 1582,
-	1583, 1584, 1585, 1079, 1586, 1587, 1059, 1588,
-	1589, 1590, 1591, 1592, 1047, 1512, 1593, 1552,
-	1594, 1595, 1596, 1059, 1597, 1598, 1050, 1047,
-	1059, 801, 1512, 1562, 1047, 1050, 1059, 801,
-	1059, 801, 1599, 1059, 1512, 801, 1050, 1600,
-	1601, 1050, 1602, 1603, 1057, 1604, 1605, 1606,
-	1607, 1608, 1558, 1609, 1610, 1611, 1612, 1613,
-	1614, 1615, 1616, 1617, 1618, 1619, 1620, 1577,
-	1621, 1050, 1059, 801, 1512, 1622, 1623, 1059,
-	1047, 1512, 801, 1047, 1512, 1050, 1624, 1107,
-	1625, 1626, 1627, 1628, 1629, 1630, 1631, 1632,
-	1047, 1633, 1634, 1635, 1636, 1637, 1638, 1047,
-	1059, 1512, 1640, 1641, 1642, 1643, 1644, 1645,
-	1646, 1647, 1648, 1649, 1650, 1646, 1652, 1653,
-	1654, 1655, 1639, 1651, 1639, 1512, 1639, 1512,
+	1059, 1047, 1045, 1046, 1045, 795, 1657, 1663,
+	1664, 1664, 1664, 1664, 1699, 1664, 1664, 1664,
+	1699, 1664, 1699, 1664, 1699, 1664, 1699, 1699,
+	1699, 1699, 1699, 1664, 1699, 1699, 1699, 1699,
+	1664, 1664, 1664, 1664, 1664, 1699, 1699, 1664,
+	1699, 1699, 1664, 1699, 1664, 1699, 1699, 1664,
+	1699, 1699, 1699, 1664, 1664, 1664, 1664, 1664,
+	1664, 1699, 1664, 1664, 1699, 1664, 1664, 1699,
+	1699, 1699, 1699, 1699, 1699, 1664, 1664, 1699,
+	1699, 1664, 1699, 1664, 1664, 1664, 1699, 1700,
+	1701, 1702, 1703, 1696, 1704, 1705, 1706, 1707,
+	1708, 1709, 1710, 1711, 1712, 1713, 1714, 1715,
+	1716, 1717, 1718, 1719, 1720, 1721, 1722, 1723,
+	1724, 1725, 1726, 1727, 1728, 1729, 1730, 1699,
+	1664, 1699, 1664, 1699, 1664, 1664, 1699, 1664,
+	1664, 1699, 1699, 1699, 1664, 1699, 1699, 1699,
+	1699, 1699, 1699, 1699, 1664, 1699, 1699, 1699,
+	1699, 1699, 1699, 1699, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1664, 1664, 1699,
+	1699, 1699, 1699, 1699, 1699, 1699, 1699, 1664,
+	1664, 1664, 1664, 1664, 1664, 1664, 1664, 1664,
+	1699, 1699, 1699, 1699, 1699, 1699, 1699, 1699,
+	1664, 1664, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1699, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1699, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1664, 1699, 1664,
+	1664, 1664, 1664, 1664, 1664, 1699, 1664, 1664,
+	1664, 1664, 1664, 1664, 1699, 1699, 1699, 1699,
+	1699, 1699, 1699, 1699, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1699, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1699, 1664, 1664,
+	1664, 1664, 1664, 1699, 1699, 1699, 1699, 1699,
+	1699, 1699, 1699, 1664, 1664, 1664, 1664, 1664,
+	1664, 1699, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1699, 1664, 1699, 1664, 1664, 1699, 1664,
+	1664, 1664, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1699, 1664, 1664, 1664,
+	1664, 1664, 1699, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1699, 1664, 1664, 1664, 1699, 1664,
+	1664, 1664, 1699, 1664, 1699, 1731, 1732, 1733,
+	1734, 1735, 1736, 1737, 1738, 1739, 1740, 1741,
+	1742, 1743, 1744, 1745, 1746, 1747, 1668, 1748,
+	1749, 1750, 1751, 1752, 1753, 1754, 1755, 1756,
+	1757, 1758, 1759, 1760, 1761, 1762, 1763, 1764,
+	1765, 1696, 1667, 1766, 1767, 1768, 1769, 1770,
+	1696, 1668, 1696, 1699, 1664, 1699, 1664, 1664,
+	1699, 1699, 1664, 1699, 1699, 1699, 1699, 1664,
+	1699, 1699, 1699, 1699, 1699, 1664, 1699, 1699,
+	1699, 1699, 1699, 1664, 1664, 1664, 1664, 1664,
+	1699, 1699, 1699, 1664, 1699, 1699, 1699, 1664,
+	1664, 1664, 1699, 1699, 1699, 1664, 1664, 1699,
+	1699, 1699, 1664, 1664, 1664, 1699, 1699, 1699,
+	1664, 1664, 1664, 1664, 1699, 1664, 1664, 1664,
+	1664, 1699, 1699, 1699, 1699, 1699, 1664, 1664,
+	1664, 1664, 1699, 1699, 1664, 1664, 1664, 1699,
+	1699, 1664, 1664, 1664, 1664, 1699, 1664, 1664,
+	1699, 1664, 1664, 1699, 1699, 1699, 1664, 1664,
+	1664, 1699, 1699, 1699, 1699, 1664, 1664, 1664,
+	1664, 1664, 1699, 1699, 1699, 1699, 1664, 1699,
+	1664, 1664, 1699, 1664, 1664, 1699, 1664, 1699,
+	1664, 1664, 1664, 1699, 1664, 1664, 1699, 1699,
+	1699, 1664, 1699, 1699, 1699, 1699, 1699, 1699,
+	1699, 1664, 1664, 1664, 1664, 1699, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1699, 1771, 1772,
+	1773, 1774, 1775, 1776, 1777, 1778, 1779, 1696,
+	1780, 1781, 1782, 1783, 1784, 1699, 1664, 1699,
+	1699, 1699, 1699, 1699, 1664, 1664, 1699, 1664,
+	1664, 1664, 1699, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1699, 1664, 1664,
+	1664, 1699, 1699, 1664, 1664, 1664, 1699, 1699,
+	1664, 1699, 1699, 1664, 1664, 1664, 1664, 1664,
+	1699, 1699, 1699, 1699, 1664, 1664, 1664, 1664,
+	1664, 1664, 1699, 1664, 1664, 1664, 1664, 1664,
+	1699, 1785, 1742, 1786, 1787, 1788, 1696, 1789,
+	1790, 1668, 1696, 1699, 1664, 1664, 1664, 1664,
+	1699, 1699, 1699, 1664, 1699, 1699, 1664, 1664,
+	1664, 1699, 1699, 1699, 1664, 1664, 1699, 1752,
+	1699, 1668, 1696, 1696, 1791, 1699, 1696, 1699,
+	1664, 1668, 1792, 1793, 1668, 1794, 1795, 1668,
+	1683, 1796, 1797, 1798, 1799, 1800, 1668, 1801,
+	1802, 1803, 1668, 1804, 1805, 1806, 1667, 1807,
+	1808, 1809, 1667, 1810, 1668, 1696, 1699, 1699,
+	1664, 1664, 1699, 1699, 1699, 1664, 1664, 1664,
+	1664, 1699, 1664, 1664, 1699, 1699, 1699, 1699,
+	1664, 1664, 1699, 1699, 1664, 1664, 1699, 1699,
+	1699, 1699, 1699, 1699, 1664, 1664, 1664, 1699,
+	1699, 1699, 1664, 1699, 1699, 1699, 1664, 1664,
+	1699, 1664, 1664, 1664, 1664, 1699, 1664, 1664,
+	1664, 1664, 1699, 1664, 1664, 1664, 1664, 1664,
+	1664, 1699, 1699, 1699, 1664, 1664, 1664, 1664,
+	1699, 1811, 1812, 1699, 1696, 1699, 1664, 1699,
+	1699, 1664, 1668, 1813, 1814, 1815, 1816, 1683,
+	1817, 1818, 1681, 1819, 1820, 1821, 1822, 1823,
+	1824, 1825, 1826, 1827, 1696, 1699, 1699, 1664,
+	1699, 1664, 1664, 1664, 1664, 1664, 1664, 1664,
+	1699, 1664, 1664, 1664, 1699, 1664, 1699, 1699,
+	1664, 1699, 1664, 1699, 1699, 1664, 1664, 1664,
+	1664, 1699, 1664, 1664, 1664, 1699, 1699, 1664,
+	1664, 1664, 1664, 1699, 1664, 1664, 1699, 1699,
+	1664, 1664, 1664, 1664, 1664, 1699, 1828, 1829,
+	1830, 1831, 1832, 1833, 1834, 1835, 1836, 1837,
+	1838, 1834, 1839, 1840, 1841, 1842, 1697, 1699,
+	1845, 1846, 1668, 1847, 1848, 1849, 1850, 1851,
+	1852, 1853, 1854, 1855, 1668, 1696, 1856, 1857,
+	1858, 1859, 1668, 1860, 1861, 1862, 1863, 1864,
+	1865, 1866, 1867, 1868, 1869, 1870, 1871, 1872,
+	1873, 1874, 1668, 1777, 1696, 1875, 1699, 1664,
+	1664, 1664, 1664, 1664, 1699, 1699, 1699, 1664,
+	1699, 1664, 1664, 1699, 1664, 1699, 1664, 1664,
+	1699, 1699, 1699, 1664, 1664, 1664, 1699, 1699,
+	1699, 1664, 1664, 1664, 1699, 1699, 1699, 1699,
+	1664, 1699, 1699, 1664, 1699, 1699, 1664, 1664,
+	1664, 1699, 1699, 1664, 1699, 1664, 1664, 1664,
+	1699, 1664, 1664, 1664, 1664, 1664, 1664, 1699,
+	1699, 1699, 1664, 1664, 1699, 1664, 1664, 1699,
+	1664, 1664, 1699, 1664, 1664, 1699, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1699, 1664, 1699,
+	1664, 1699, 1664, 1664, 1699, 1664, 1699, 1664,
+	1664, 1699, 1664, 1699, 1664, 1699, 1876, 1847,
+	1877, 1878, 1879, 1880, 1881, 1882, 1883, 1884,
+	1885, 1731, 1886, 1668, 1887, 1888, 1889, 1668,
+	1890, 1762, 1891, 1892, 1893, 1894, 1895, 1896,
+	1897, 1898, 1668, 1699, 1699, 1699, 1664, 1664,
+	1664, 1699, 1664, 1664, 1699, 1664, 1664, 1699,
+	1699, 1699, 1699, 1699, 1664, 1664, 1664, 1664,
+	1699, 1664, 1664, 1664, 1664, 1664, 1664, 1699,
+	1699, 1699, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1699, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1699, 1664, 1664, 1699,
+	1699, 1699, 1699, 1664, 1664, 1664, 1699, 1699,
+	1699, 1664, 1699, 1699, 1699, 1664, 1664, 1699,
+	1664, 1664, 1664, 1699, 1664, 1699, 1699, 1699,
+	1664, 1664, 1699, 1664, 1664, 1664, 1699, 1664,
+	1664, 1664, 1699, 1699, 1699, 1699, 1664, 1668,
+	1814, 1899, 1900, 1696, 1668, 1696, 1699, 1699,
+	1664, 1699, 1664, 1668, 1899, 1696, 1699, 1668,
+	1901, 1696, 1699, 1699, 1664, 1668, 1902, 1903,
+	1904, 1805, 1905, 1906, 1668, 1907, 1908, 1909,
+	1696, 1699, 1699, 1664, 1664, 1664, 1699, 1664,
+	1664, 1699, 1664, 1664, 1664, 1664, 1699, 1699,
+	1664, 1699, 1699, 1664, 1664, 1699, 1664, 1699,
+	1668, 1696, 1699, 1910, 1668, 1911, 1699, 1696,
+	1699, 1664, 1699, 1664, 1912, 1668, 1913, 1914,
+	1699, 1664, 1699, 1699, 1699, 1664, 1664, 1664,
+	1664, 1699, 1915, 1916, 1917, 1668, 1918, 1919,
+	1920, 1921, 1922, 1923, 1924, 1925, 1926, 1927,
+	1928, 1929, 1930, 1931, 1696, 1699, 1664, 1664,
+	1664, 1699, 1699, 1699, 1699, 1664, 1664, 1699,
+	1699, 1664, 1699, 1699, 1699, 1699, 1699, 1699,
+	1699, 1664, 1699, 1664, 1699, 1699, 1699, 1699,
+	1699, 1699, 1664, 1664, 1664, 1664, 1664, 1699,
+	1699, 1664, 1699, 1699, 1699, 1664, 1699, 1699,
+	1664, 1699, 1699, 1664, 1699, 1699, 1664, 1699,
+	1699, 1699, 1664, 1664, 1664, 1699, 1699, 1699,
+	1664, 1664, 1664, 1664, 1699, 1932, 1668, 1933,
+	1668, 1934, 1935, 1936, 1937, 1696, 1699, 1664,
+	1664, 1664, 1664, 1664, 1699, 1699, 1699, 1664,
+	1699, 1699, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1699, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1664, 1664, 1664,
+	1664, 1664, 1664, 1664, 1664, 1664, 1664, 1664,
+	1699, 1664, 1664, 1664, 1664, 1664, 1699, 1938,
+	1668, 1696, 1699, 1664, 1939, 1668, 1733, 1696,
+	1699, 1664, 1940, 1699, 1696, 1699, 1664, 1668,
+	1941, 1696, 1699, 1699, 1664, 1843, 1699, 1668,
+	1942, 1696, 1699, 1699, 1664, 1142, 1143, 1144,
+	1142, 1145, 1146, 1147, 1149, 1150, 1151, 1944,
+	1152, 1153, 1154, 1155, 1946, 670, 670, 419,
+	1156, 1157, 1158, 1159, 670, 1162, 1163, 1165,
+	1166, 1167, 1161, 1168, 1169, 1170, 1171, 1172,
+	1173, 1174, 1175, 1176, 1177, 1178, 1179, 1180,
+	1181, 1182, 1183, 1184, 1185, 1186, 1187, 1189,
+	1190, 1191, 1192, 1193, 1194, 670, 1148, 7,
+	1148, 419, 1148, 419, 1161, 1164, 1188, 1195,
+	1160, 1142, 1142, 1196, 1143, 1197, 1199, 1198,
+	4, 1147, 1201, 1198, 1202, 1198, 2, 1147,
+	1198, 6, 8, 1656, 8, 7, 1203, 1204,
+	1198, 1945, 1198, 1205, 1206, 1198, 1207, 1208,
+	1198, 1209, 1198, 419, 419, 1211, 1212, 489,
+	470, 1213, 470, 1214, 1215, 1216, 1217, 1218,
+	1219, 1220, 1221, 1222, 1223, 1224, 544, 1225,
+	520, 1226, 1227, 1228, 1229, 1230, 1231, 1232,
+	1233, 1234, 1235, 1236, 1237, 419, 419, 419,
+	425, 565, 1210, 1238, 1198, 1239, 1198, 670,
+	1240, 419, 419, 419, 670, 1240, 670, 670,
+	419, 1240, 419, 1240, 419, 1240, 419, 670,
+	670, 670, 670, 670, 1240, 419, 670, 670,
+	670, 419, 670, 419, 1240, 419, 670, 670,
+	670, 670, 419, 1240, 670, 419, 670, 419,
+	670, 419, 670, 670, 419, 670, 1240, 419,
+	670, 419, 670, 419, 670, 1240, 670, 419,
+	1240, 670, 419, 670, 419, 1240, 670, 670,
+	670, 670, 670, 1240, 419, 419, 670, 419,
+	670, 1240, 670, 419, 1240, 670, 670, 1240,
+	419, 419, 670, 419, 670, 419, 670, 1240,
+	1241, 1242, 1243, 1244, 1245, 1246, 1247, 1248,
+	1249, 1250, 1251, 715, 1252, 1253, 1254, 1255,
+	1256, 1257, 1258, 1259, 1260, 1261, 1262, 1263,
+	1262, 1264, 1265, 1266, 1267, 1268, 671, 1240,
+	1269, 1270, 1271, 1272, 1273, 1274, 1275, 1276,
+	1277, 1278, 1279, 1280, 1281, 1282, 1283, 1284,
+	1285, 1286, 1287, 725, 1288, 1289, 1290, 692,
+	1291, 1292, 1293, 1294, 1295, 1296, 671, 1297,
+	1298, 1299, 1300, 1301, 1302, 1303, 1304, 674,
+	1305, 671, 674, 1306, 1307, 1308, 1309, 683,
+	1240, 1310, 1311, 1312, 1313, 703, 1314, 1315,
+	683, 1316, 1317, 1318, 1319, 1320, 671, 1240,
+	1321, 1280, 1322, 1323, 1324, 683, 1325, 1326,
+	674, 671, 683, 425, 1240, 1290, 671, 674,
+	683, 425, 683, 425, 1327, 683, 1240, 425,
+	674, 1328, 1329, 674, 1330, 1331, 681, 1332,
+	1333, 1334, 1335, 1336, 1286, 1337, 1338, 1339,
+	1340, 1341, 1342, 1343, 1344, 1345, 1346, 1347,
+	1348, 1305, 1349, 674, 683, 425, 1240, 1350,
+	1351, 683, 671, 1240, 425, 671, 1240, 674,
+	1352, 731, 1353, 1354, 1355, 1356, 1357, 1358,
+	1359, 1360, 671, 1361, 1362, 1363, 1364, 1365,
+	1366, 671, 683, 1240, 1368, 1369, 1370, 1371,
+	1372, 1373, 1374, 1375, 1376, 1377, 1378, 1374,
+	1380, 1381, 1382, 1383, 1367, 1379, 1367, 1240,
+	1367, 1240, 1658, 1659, 1656, 1659, 1657, 1660,
+	1660, 1661, 1662, 1662, 1657, 1663, 6, 1943,
+	8, 1943, 1656, 1943, 8, 1943, 7, 1203,
+	1664, 1664, 1665, 1666, 1667, 1668, 1669, 1668,
+	1670, 1671, 1672, 1673, 1674, 1675, 1676, 1677,
+	1678, 1679, 1680, 1681, 1682, 1683, 1684, 1685,
+	1686, 1687, 1688, 1689, 1690, 1691, 1692, 1693,
+	1694, 1695, 1664, 1664, 1664, 1696, 1697, 1698,
+	1384, 1384, 1385, 1386, 1387, 1388, 1389, 1390,
+	1391, 1392, 1389, 767, 1393, 1393, 1393, 1394,
+	1393, 1393, 768, 769, 770, 1393, 767, 1384,
+	1384, 1395, 1398, 1399, 1397, 1400, 1401, 1400,
+	1402, 1393, 1404, 1403, 1398, 1405, 1397, 1407,
+	1406, 1396, 1396, 1396, 768, 769, 770, 1396,
+	767, 767, 1408, 773, 1408, 1409, 1408, 775,
+	1410, 1411, 1412, 1413, 1414, 1415, 1416, 1413,
+	776, 775, 1410, 1417, 1417, 777, 779, 1418,
+	1417, 776, 1420, 1421, 1419, 1420, 1421, 1422,
+	1419, 775, 1410, 1423, 1417, 775, 1410, 1417,
+	1425, 1424, 1427, 1426, 776, 1428, 777, 1428,
+	779, 1428, 785, 1429, 1430, 1431, 1432, 1433,
+	1434, 1435, 1432, 786, 785, 1429, 1436, 1436,
+	787, 789, 1437, 1436, 786, 1439, 1440, 1438,
+	1439, 1440, 1441, 1438, 785, 1429, 1442, 1436,
+	785, 1429, 1436, 1444, 1443, 1446, 1445, 786,
+	1447, 787, 1447, 789, 1447, 795, 1450, 1451,
+	1453, 1454, 1455, 1449, 1456, 1457, 1458, 1459,
+	1460, 1461, 1462, 1463, 1464, 1465, 1466, 1467,
+	1468, 1469, 1470, 1471, 1472, 1473, 1474, 1475,
+	1477, 1478, 1479, 1480, 1481, 1482, 795, 795,
+	1448, 1449, 1452, 1476, 1483, 1448, 1046, 795,
+	795, 1485, 1486, 865, 846, 1487, 846, 1488,
+	1489, 1490, 1491, 1492, 1493, 1494, 1495, 1496,
+	1497, 1498, 920, 1499, 896, 1500, 1501, 1502,
+	1503, 1504, 1505, 1506, 1507, 1508, 1509, 1510,
+	1511, 795, 795, 795, 801, 941, 1484, 1046,
+	1512, 795, 795, 795, 1046, 1512, 1046, 1046,
+	795, 1512, 795, 1512, 795, 1512, 795, 1046,
+	1046, 1046, 1046, 1046, 1512, 795, 1046, 1046,
+	1046, 795, 1046, 795, 1512, 795, 1046, 1046,
+	1046, 1046, 795, 1512, 1046, 795, 1046, 795,
+	1046, 795, 1046, 1046, 795, 1046, 1512, 795,
+	1046, 795, 1046, 795, 1046, 1512, 1046, 795,
+	1512, 1046, 795, 1046, 795, 1512, 1046, 1046,
+	1046, 1046, 1046, 1512, 795, 795, 1046, 795,
+	1046, 1512, 1046, 795, 1512, 1046, 1046, 1512,
+	795, 795, 1046, 795, 1046, 795, 1046, 1512,
+	1513, 1514, 1515, 1516, 1517, 1518, 1519, 1520,
+	1521, 1522, 1523, 1091, 1524, 1525, 1526, 1527,
+	1528, 1529, 1530, 1531, 1532, 1533, 1534, 1535,
+	1534, 1536, 1537, 1538, 1539, 1540, 1047, 1512,
+	1541, 1542, 1543, 1544, 1545, 1546, 1547, 1548,
+	1549, 1550, 1551, 1552, 1553, 1554, 1555, 1556,
+	1557, 1558, 1559, 1101, 1560, 1561, 1562, 1068,
+	1563, 1564, 1565, 1566, 1567, 1568, 1047, 1569,
+	1570, 1571, 1572, 1573, 1574, 1575, 1576, 1050,
+	1577, 1047, 1050, 1578, 1579, 1580, 1581, 1059,
+	1512, 1582, 1583, 1584, 1585, 1079, 1586, 1587,
+	1059, 1588, 1589, 1590, 1591, 1592, 1047, 1512,
+	1593, 1552, 1594, 1595, 1596, 1059, 1597, 1598,
+	1050, 1047, 1059, 801, 1512, 1562, 1047, 1050,
+	1059, 801, 1059, 801, 1599, 1059, 1512, 801,
+	1050, 1600, 1601, 1050, 1602, 1603, 1057, 1604,
+	1605, 1606, 1607, 1608, 1558, 1609, 1610, 1611,
+	1612, 1613, 1614, 1615, 1616, 1617, 1618, 1619,
+	1620, 1577, 1621, 1050, 1059, 801, 1512, 1622,
+	1623, 1059, 1047, 1512, 801, 1047, 1512, 1050,
+	1624, 1107, 1625, 1626, 1627, 1628, 1629, 1630,
+	1631, 1632, 1047, 1633, 1634, 1635, 1636, 1637,
+	1638, 1047, 1059, 1512, 1640, 1641, 1642, 1643,
+	1644, 1645, 1646, 1647, 1648, 1649, 1650, 1646,
+	1652, 1653, 1654, 1655, 1639, 1651, 1639, 1512,
+	1639, 1512,
 }
 
 var _hcltok_trans_targs []int16 = []int16{
-	1459, 1459, 2, 3, 1459, 1459, 4, 1467,
+	1735, 1735, 2, 3, 1735, 1735, 4, 1743,
 	5, 6, 8, 9, 286, 12, 13, 14,
 	15, 16, 287, 288, 19, 289, 21, 22,
 	290, 291, 292, 293, 294, 295, 296, 297,
 	298, 299, 328, 348, 353, 127, 128, 129,
-	356, 151, 371, 375, 1459, 10, 11, 17,
+	356, 151, 371, 375, 1735, 10, 11, 17,
 	18, 20, 23, 24, 25, 26, 27, 28,
 	29, 30, 31, 32, 64, 105, 120, 131,
 	154, 170, 283, 33, 34, 35, 36, 37,
@@ -3237,7 +3768,7 @@ var _hcltok_trans_targs []int16 = []int16{
 	385, 386, 387, 388, 389, 390, 391, 392,
 	393, 394, 395, 396, 397, 398, 399, 400,
 	401, 402, 403, 405, 406, 407, 408, 410,
-	412, 414, 1459, 1472, 1459, 437, 438, 439,
+	412, 414, 1735, 1749, 1735, 437, 438, 439,
 	440, 417, 441, 442, 443, 444, 445, 446,
 	447, 448, 449, 450, 451, 452, 453, 454,
 	455, 456, 457, 458, 459, 460, 461, 462,
@@ -3268,7 +3799,7 @@ var _hcltok_trans_targs []int16 = []int16{
 	655, 656, 657, 658, 659, 660, 661, 662,
 	663, 664, 665, 666, 667, 668, 669, 670,
 	671, 673, 674, 675, 676, 677, 678, 680,
-	682, 684, 686, 688, 689, 1459, 1459, 690,
+	682, 684, 686, 688, 689, 1735, 1735, 690,
 	827, 828, 759, 829, 830, 831, 832, 833,
 	834, 788, 835, 724, 836, 837, 838, 839,
 	840, 841, 842, 843, 744, 844, 845, 846,
@@ -3280,11 +3811,11 @@ var _hcltok_trans_targs []int16 = []int16{
 	888, 889, 890, 891, 892, 895, 896, 898,
 	899, 900, 902, 903, 904, 905, 906, 907,
 	908, 909, 910, 911, 912, 914, 915, 916,
-	917, 920, 922, 923, 925, 927, 1510, 1511,
-	929, 930, 931, 1510, 1510, 932, 1524, 1524,
-	1525, 935, 1524, 936, 1526, 1527, 1530, 1531,
-	1535, 1535, 1536, 941, 1535, 942, 1537, 1538,
-	1541, 1542, 1546, 1547, 1546, 968, 969, 970,
+	917, 920, 922, 923, 925, 927, 1791, 1792,
+	929, 930, 931, 1791, 1791, 932, 1805, 1805,
+	1806, 935, 1805, 936, 1807, 1808, 1811, 1812,
+	1816, 1816, 1817, 941, 1816, 942, 1818, 1819,
+	1822, 1823, 1827, 1828, 1827, 968, 969, 970,
 	971, 948, 972, 973, 974, 975, 976, 977,
 	978, 979, 980, 981, 982, 983, 984, 985,
 	986, 987, 988, 989, 990, 991, 992, 993,
@@ -3315,7 +3846,7 @@ var _hcltok_trans_targs []int16 = []int16{
 	1186, 1187, 1188, 1189, 1190, 1191, 1192, 1193,
 	1194, 1195, 1196, 1197, 1198, 1199, 1200, 1201,
 	1202, 1204, 1205, 1206, 1207, 1208, 1209, 1211,
-	1213, 1215, 1217, 1219, 1220, 1546, 1546, 1221,
+	1213, 1215, 1217, 1219, 1220, 1827, 1827, 1221,
 	1358, 1359, 1290, 1360, 1361, 1362, 1363, 1364,
 	1365, 1319, 1366, 1255, 1367, 1368, 1369, 1370,
 	1371, 1372, 1373, 1374, 1275, 1375, 1376, 1377,
@@ -3327,20 +3858,20 @@ var _hcltok_trans_targs []int16 = []int16{
 	1419, 1420, 1421, 1422, 1423, 1426, 1427, 1429,
 	1430, 1431, 1433, 1434, 1435, 1436, 1437, 1438,
 	1439, 1440, 1441, 1442, 1443, 1445, 1446, 1447,
-	1448, 1451, 1453, 1454, 1456, 1458, 1460, 1459,
-	1461, 1462, 1459, 1463, 1459, 1464, 1465, 1466,
-	1468, 1469, 1470, 1471, 1459, 1473, 1459, 1474,
-	1459, 1475, 1476, 1477, 1478, 1479, 1480, 1481,
-	1482, 1483, 1484, 1485, 1486, 1487, 1488, 1489,
-	1490, 1491, 1492, 1493, 1494, 1495, 1496, 1497,
-	1498, 1499, 1500, 1501, 1502, 1503, 1504, 1505,
-	1506, 1507, 1508, 1509, 1459, 1459, 1459, 1459,
-	1459, 1459, 1, 1459, 1459, 7, 1459, 1459,
-	1459, 1459, 1459, 415, 416, 420, 421, 422,
+	1448, 1451, 1453, 1454, 1456, 1458, 1736, 1735,
+	1737, 1738, 1735, 1739, 1735, 1740, 1741, 1742,
+	1744, 1746, 1747, 1748, 1735, 1750, 1735, 1751,
+	1735, 1752, 1753, 1754, 1755, 1756, 1757, 1758,
+	1759, 1760, 1761, 1762, 1763, 1764, 1765, 1766,
+	1767, 1768, 1769, 1770, 1771, 1772, 1773, 1774,
+	1775, 1776, 1777, 1778, 1779, 1780, 1781, 1782,
+	1783, 1784, 1785, 1786, 1735, 1735, 1735, 1735,
+	1735, 1735, 1, 1735, 1735, 7, 1735, 1735,
+	1735, 1735, 1735, 415, 416, 420, 421, 422,
 	423, 424, 425, 426, 427, 428, 429, 430,
 	431, 433, 435, 436, 468, 509, 524, 531,
-	533, 535, 555, 558, 574, 687, 1459, 1459,
-	1459, 691, 692, 693, 694, 695, 696, 697,
+	533, 535, 555, 558, 574, 687, 1735, 1735,
+	1735, 691, 692, 693, 694, 695, 696, 697,
 	698, 699, 700, 701, 703, 704, 705, 706,
 	707, 708, 709, 710, 711, 712, 713, 714,
 	715, 716, 717, 718, 719, 720, 721, 722,
@@ -3358,23 +3889,23 @@ var _hcltok_trans_targs []int16 = []int16{
 	818, 819, 820, 821, 822, 823, 824, 825,
 	826, 855, 880, 883, 884, 886, 893, 894,
 	897, 901, 913, 918, 919, 921, 924, 926,
-	1512, 1510, 1513, 1518, 1520, 1510, 1521, 1522,
-	1523, 1510, 928, 1510, 1510, 1514, 1515, 1517,
-	1510, 1516, 1510, 1510, 1510, 1519, 1510, 1510,
-	1510, 933, 934, 938, 939, 1524, 1532, 1533,
-	1534, 1524, 937, 1524, 1524, 934, 1528, 1529,
-	1524, 1524, 1524, 1524, 1524, 940, 944, 945,
-	1535, 1543, 1544, 1545, 1535, 943, 1535, 1535,
-	940, 1539, 1540, 1535, 1535, 1535, 1535, 1535,
-	1546, 1548, 1549, 1550, 1551, 1552, 1553, 1554,
-	1555, 1556, 1557, 1558, 1559, 1560, 1561, 1562,
-	1563, 1564, 1565, 1566, 1567, 1568, 1569, 1570,
-	1571, 1572, 1573, 1574, 1575, 1576, 1577, 1578,
-	1579, 1580, 1581, 1582, 1546, 946, 947, 951,
+	1793, 1791, 1794, 1799, 1801, 1791, 1802, 1803,
+	1804, 1791, 928, 1791, 1791, 1795, 1796, 1798,
+	1791, 1797, 1791, 1791, 1791, 1800, 1791, 1791,
+	1791, 933, 934, 938, 939, 1805, 1813, 1814,
+	1815, 1805, 937, 1805, 1805, 934, 1809, 1810,
+	1805, 1805, 1805, 1805, 1805, 940, 944, 945,
+	1816, 1824, 1825, 1826, 1816, 943, 1816, 1816,
+	940, 1820, 1821, 1816, 1816, 1816, 1816, 1816,
+	1827, 1829, 1830, 1831, 1832, 1833, 1834, 1835,
+	1836, 1837, 1838, 1839, 1840, 1841, 1842, 1843,
+	1844, 1845, 1846, 1847, 1848, 1849, 1850, 1851,
+	1852, 1853, 1854, 1855, 1856, 1857, 1858, 1859,
+	1860, 1861, 1862, 1863, 1827, 946, 947, 951,
 	952, 953, 954, 955, 956, 957, 958, 959,
 	960, 961, 962, 964, 966, 967, 999, 1040,
 	1055, 1062, 1064, 1066, 1086, 1089, 1105, 1218,
-	1546, 1222, 1223, 1224, 1225, 1226, 1227, 1228,
+	1827, 1222, 1223, 1224, 1225, 1226, 1227, 1228,
 	1229, 1230, 1231, 1232, 1234, 1235, 1236, 1237,
 	1238, 1239, 1240, 1241, 1242, 1243, 1244, 1245,
 	1246, 1247, 1248, 1249, 1250, 1251, 1252, 1253,
@@ -3392,15 +3923,52 @@ var _hcltok_trans_targs []int16 = []int16{
 	1349, 1350, 1351, 1352, 1353, 1354, 1355, 1356,
 	1357, 1386, 1411, 1414, 1415, 1417, 1424, 1425,
 	1428, 1432, 1444, 1449, 1450, 1452, 1455, 1457,
+	1787, 1743, 4, 1788, 1735, 1735, 1459, 1735,
+	1790, 1460, 1461, 1463, 1464, 1465, 1466, 1467,
+	1468, 1469, 1470, 1471, 1472, 1473, 1474, 1475,
+	1476, 1477, 1478, 1479, 1480, 1481, 1513, 1554,
+	1569, 1576, 1578, 1580, 1600, 1603, 1619, 1732,
+	1462, 1577, 1735, 1735, 1482, 1483, 1484, 1485,
+	1486, 1487, 1488, 1489, 1490, 1491, 1492, 1493,
+	1494, 1495, 1496, 1497, 1498, 1499, 1500, 1501,
+	1502, 1503, 1504, 1505, 1506, 1507, 1508, 1509,
+	1510, 1511, 1512, 1514, 1515, 1516, 1517, 1518,
+	1519, 1520, 1521, 1522, 1523, 1524, 1525, 1526,
+	1527, 1528, 1529, 1530, 1531, 1532, 1533, 1534,
+	1535, 1536, 1537, 1538, 1539, 1540, 1541, 1542,
+	1543, 1544, 1545, 1546, 1547, 1548, 1549, 1550,
+	1551, 1552, 1553, 1555, 1556, 1557, 1558, 1559,
+	1560, 1561, 1562, 1563, 1564, 1565, 1566, 1567,
+	1568, 1570, 1571, 1572, 1573, 1574, 1575, 1579,
+	1581, 1582, 1583, 1584, 1585, 1586, 1587, 1588,
+	1589, 1590, 1591, 1592, 1593, 1594, 1595, 1596,
+	1597, 1598, 1599, 1601, 1602, 1604, 1605, 1606,
+	1607, 1608, 1609, 1610, 1611, 1612, 1613, 1614,
+	1615, 1616, 1617, 1618, 1620, 1652, 1676, 1679,
+	1680, 1682, 1691, 1692, 1695, 1699, 1717, 1724,
+	1726, 1728, 1730, 1733, 1735, 1621, 1622, 1623,
+	1624, 1625, 1626, 1627, 1628, 1629, 1630, 1631,
+	1632, 1633, 1634, 1635, 1636, 1637, 1638, 1639,
+	1640, 1641, 1642, 1643, 1644, 1645, 1646, 1647,
+	1648, 1649, 1650, 1651, 1653, 1654, 1655, 1656,
+	1657, 1658, 1659, 1660, 1661, 1662, 1663, 1664,
+	1665, 1666, 1667, 1668, 1669, 1670, 1671, 1672,
+	1673, 1674, 1675, 1677, 1678, 1681, 1683, 1684,
+	1685, 1686, 1687, 1688, 1689, 1690, 1693, 1694,
+	1696, 1697, 1698, 1700, 1701, 1702, 1703, 1704,
+	1705, 1706, 1707, 1708, 1709, 1710, 1711, 1712,
+	1713, 1714, 1715, 1716, 1718, 1719, 1720, 1721,
+	1722, 1723, 1725, 1727, 1729, 1731, 1734, 1790,
+	1789, 1735, 1745,
 }
 
 var _hcltok_trans_actions []byte = []byte{
-	147, 109, 0, 0, 91, 143, 0, 7,
+	153, 111, 0, 0, 93, 147, 0, 7,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 123, 0, 0, 0,
+	0, 0, 0, 0, 127, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -3447,7 +4015,7 @@ var _hcltok_trans_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 145, 195, 151, 0, 0, 0,
+	0, 0, 151, 201, 157, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -3478,7 +4046,7 @@ var _hcltok_trans_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 149, 127, 0,
+	0, 0, 0, 0, 0, 155, 131, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -3490,11 +4058,11 @@ var _hcltok_trans_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 31, 171,
+	0, 0, 0, 0, 0, 0, 31, 177,
 	0, 0, 0, 35, 33, 0, 55, 41,
-	177, 0, 53, 0, 177, 177, 0, 0,
-	75, 61, 183, 0, 73, 0, 183, 183,
-	0, 0, 85, 189, 89, 0, 0, 0,
+	183, 0, 53, 0, 183, 183, 0, 0,
+	75, 61, 189, 0, 73, 0, 189, 189,
+	0, 0, 85, 195, 89, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -3537,20 +4105,20 @@ var _hcltok_trans_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 93,
-	0, 0, 121, 0, 113, 0, 7, 7,
-	0, 7, 0, 0, 115, 0, 117, 0,
-	125, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 95,
+	0, 0, 125, 0, 117, 0, 7, 7,
+	0, 7, 0, 0, 119, 0, 121, 0,
+	129, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 7,
-	7, 7, 198, 198, 198, 198, 198, 198,
-	7, 7, 198, 7, 129, 141, 137, 97,
-	135, 103, 0, 131, 107, 0, 101, 95,
-	111, 99, 133, 0, 0, 0, 0, 0,
+	7, 7, 204, 204, 204, 204, 204, 204,
+	7, 7, 204, 7, 133, 145, 141, 99,
+	139, 105, 0, 135, 109, 0, 103, 97,
+	113, 101, 137, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 105, 119,
-	139, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 107, 123,
+	143, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -3568,19 +4136,19 @@ var _hcltok_trans_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 13, 0, 0, 174, 17, 0, 7,
+	0, 13, 0, 0, 180, 17, 0, 7,
 	7, 23, 0, 25, 27, 0, 0, 0,
-	153, 0, 15, 19, 9, 0, 21, 11,
-	29, 0, 0, 0, 0, 43, 0, 180,
-	180, 49, 0, 159, 156, 1, 177, 177,
+	159, 0, 15, 19, 9, 0, 21, 11,
+	29, 0, 0, 0, 0, 43, 0, 186,
+	186, 49, 0, 165, 162, 1, 183, 183,
 	45, 37, 47, 39, 51, 0, 0, 0,
-	63, 0, 186, 186, 69, 0, 165, 162,
-	1, 183, 183, 65, 57, 67, 59, 71,
+	63, 0, 192, 192, 69, 0, 171, 168,
+	1, 189, 189, 65, 57, 67, 59, 71,
 	77, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 7,
-	7, 7, 192, 192, 192, 192, 192, 192,
-	7, 7, 192, 7, 81, 0, 0, 0,
+	7, 7, 198, 198, 198, 198, 198, 198,
+	7, 7, 198, 7, 81, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -3602,6 +4170,43 @@ var _hcltok_trans_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
+	7, 7, 0, 7, 91, 135, 0, 149,
+	7, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 135, 147, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 147, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 7,
+	7, 115, 0,
 }
 
 var _hcltok_to_state_actions []byte = []byte{
@@ -3787,22 +4392,57 @@ var _hcltok_to_state_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 3, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 3, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 168, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 168,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 3, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 3,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 3,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 174, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	174, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 3, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
 }
 
 var _hcltok_from_state_actions []byte = []byte{
@@ -3988,22 +4628,57 @@ var _hcltok_from_state_actions []byte = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 5, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 5, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 5, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 5,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 5,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 5, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 5, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	5, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 5, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0,
 }
 
 var _hcltok_eof_trans []int16 = []int16{
@@ -4189,37 +4864,72 @@ var _hcltok_eof_trans []int16 = []int16{
 	1046, 1046, 1046, 1046, 1046, 1046, 1046, 1046,
 	1046, 1046, 1046, 1046, 1046, 1046, 1046, 1046,
 	1046, 1046, 1046, 1046, 1046, 1046, 1046, 1046,
-	1046, 1046, 1046, 0, 1197, 1198, 1199, 1201,
-	1199, 1199, 1199, 1204, 1199, 1199, 1199, 1199,
-	1211, 1199, 1199, 1241, 1241, 1241, 1241, 1241,
+	1046, 1046, 1046, 1664, 1700, 1700, 1845, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 1700,
+	1700, 1700, 1700, 1700, 1700, 1700, 1700, 0,
+	1197, 1198, 1199, 1201, 1199, 1199, 1199, 1204,
+	1199, 1199, 1199, 1199, 1199, 1211, 1199, 1199,
 	1241, 1241, 1241, 1241, 1241, 1241, 1241, 1241,
 	1241, 1241, 1241, 1241, 1241, 1241, 1241, 1241,
 	1241, 1241, 1241, 1241, 1241, 1241, 1241, 1241,
-	1241, 1241, 1241, 1241, 1241, 1241, 0, 1394,
-	1396, 1397, 1401, 1401, 1394, 1404, 1397, 1407,
-	1397, 1409, 1409, 1409, 0, 1418, 1420, 1420,
-	1418, 1418, 1425, 1427, 1429, 1429, 1429, 0,
-	1437, 1439, 1439, 1437, 1437, 1444, 1446, 1448,
-	1448, 1448, 0, 1485, 1513, 1513, 1513, 1513,
+	1241, 1241, 1241, 1241, 1241, 1241, 1241, 1241,
+	1241, 1241, 1241, 1662, 1664, 1204, 1699, 0,
+	1394, 1396, 1397, 1401, 1401, 1394, 1404, 1397,
+	1407, 1397, 1409, 1409, 1409, 0, 1418, 1420,
+	1420, 1418, 1418, 1425, 1427, 1429, 1429, 1429,
+	0, 1437, 1439, 1439, 1437, 1437, 1444, 1446,
+	1448, 1448, 1448, 0, 1485, 1513, 1513, 1513,
+	1513, 1513, 1513, 1513, 1513, 1513, 1513, 1513,
 	1513, 1513, 1513, 1513, 1513, 1513, 1513, 1513,
 	1513, 1513, 1513, 1513, 1513, 1513, 1513, 1513,
 	1513, 1513, 1513, 1513, 1513, 1513, 1513, 1513,
-	1513, 1513, 1513, 1513, 1513, 1513, 1513,
 }
 
-const hcltok_start int = 1459
-const hcltok_first_final int = 1459
+const hcltok_start int = 1735
+const hcltok_first_final int = 1735
 const hcltok_error int = 0
 
-const hcltok_en_stringTemplate int = 1510
-const hcltok_en_heredocTemplate int = 1524
-const hcltok_en_bareTemplate int = 1535
-const hcltok_en_identOnly int = 1546
-const hcltok_en_main int = 1459
+const hcltok_en_stringTemplate int = 1791
+const hcltok_en_heredocTemplate int = 1805
+const hcltok_en_bareTemplate int = 1816
+const hcltok_en_identOnly int = 1827
+const hcltok_en_main int = 1735
 
 //line scan_tokens.rl:18
 
-func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []Token {
+func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode, rawStrings bool, callback func(Token) bool) []Token {
 	stripData := stripUTF8BOM(data)
 	start.Byte += len(data) - len(stripData)
 	data = stripData
@@ -4229,9 +4939,10 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 		Bytes:     data,
 		Pos:       start,
 		StartByte: start.Byte,
+		Callback:  callback,
 	}
 
-//line scan_tokens.rl:317
+//line scan_tokens.rl:349
 
 	// Ragel state
 	p := 0          // "Pointer" into data
@@ -4259,7 +4970,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 	var retBraces []int              // stack of brace levels that cause us to use fret
 	var heredocs []heredocInProgress // stack of heredocs we're currently processing
 
-//line scan_tokens.rl:352
+//line scan_tokens.rl:384
 
 	// Make Go compiler happy
 	_ = ts
@@ -4267,8 +4978,18 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 	_ = act
 	_ = eof
 
+	// stopIfRequested moves the scanner to the end of the input if the
+	// callback has asked us to stop, so that the rest of the input isn't
+	// scanned only to have its tokens discarded.
+	stopIfRequested := func() {
+		if f.stopped {
+			p = pe - 1
+			eof = -1
+		}
+	}
 	token := func(ty TokenType) {
 		f.emitToken(ty, ts, te)
+		stopIfRequested()
 	}
 	selfToken := func() {
 		b := data[ts:te]
@@ -4276,10 +4997,28 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 			// should never happen
 			panic("selfToken only works for single-character tokens")
 		}
+		if b[0] == '`' && rawStrings {
+			// If raw strings are enabled then a backtick begins a raw string
+			// literal, which continues verbatim up to the next backtick (or
+			// the end of the input, if unterminated) without any escapes or
+			// template sequences. We consume it here and then move the
+			// scanner past it. Otherwise it is just an invalid character.
+			end := bytes.IndexByte(data[te:], '`')
+			if end < 0 {
+				te = len(data)
+			} else {
+				te += end + 1
+			}
+			p = te - 1
+			f.emitToken(TokenRawLit, ts, te)
+			stopIfRequested()
+			return
+		}
 		f.emitToken(TokenType(b[0]), ts, te)
+		stopIfRequested()
 	}
 
-//line scan_tokens.go:4292
+//line scan_tokens.go:5031
 	{
 		top = 0
 		ts = 0
@@ -4287,7 +5026,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 		act = 0
 	}
 
-//line scan_tokens.go:4300
+//line scan_tokens.go:5039
 	{
 		var _klen int
 		var _trans int
@@ -4311,7 +5050,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 //line NONE:1
 				ts = p
 
-//line scan_tokens.go:4323
+//line scan_tokens.go:5062
 			}
 		}
 
@@ -4383,7 +5122,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 			_acts++
 			switch _hcltok_actions[_acts-1] {
 			case 0:
-//line scan_tokens.rl:235
+//line scan_tokens.rl:265
 				p--
 
 			case 4:
@@ -4391,13 +5130,13 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 				te = p + 1
 
 			case 5:
-//line scan_tokens.rl:259
+//line scan_tokens.rl:289
 				act = 4
 			case 6:
-//line scan_tokens.rl:261
+//line scan_tokens.rl:291
 				act = 6
 			case 7:
-//line scan_tokens.rl:171
+//line scan_tokens.rl:201
 				te = p + 1
 				{
 					token(TokenTemplateInterp)
@@ -4410,12 +5149,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 8:
-//line scan_tokens.rl:181
+//line scan_tokens.rl:211
 				te = p + 1
 				{
 					token(TokenTemplateControl)
@@ -4428,12 +5167,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 9:
-//line scan_tokens.rl:95
+//line scan_tokens.rl:125
 				te = p + 1
 				{
 					token(TokenCQuote)
@@ -4446,19 +5185,19 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 
 				}
 			case 10:
-//line scan_tokens.rl:259
+//line scan_tokens.rl:289
 				te = p + 1
 				{
 					token(TokenQuotedLit)
 				}
 			case 11:
-//line scan_tokens.rl:262
+//line scan_tokens.rl:292
 				te = p + 1
 				{
 					token(TokenBadUTF8)
 				}
 			case 12:
-//line scan_tokens.rl:171
+//line scan_tokens.rl:201
 				te = p
 				p--
 				{
@@ -4472,12 +5211,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 13:
-//line scan_tokens.rl:181
+//line scan_tokens.rl:211
 				te = p
 				p--
 				{
@@ -4491,46 +5230,46 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 14:
-//line scan_tokens.rl:259
+//line scan_tokens.rl:289
 				te = p
 				p--
 				{
 					token(TokenQuotedLit)
 				}
 			case 15:
-//line scan_tokens.rl:260
+//line scan_tokens.rl:290
 				te = p
 				p--
 				{
 					token(TokenQuotedNewline)
 				}
 			case 16:
-//line scan_tokens.rl:261
+//line scan_tokens.rl:291
 				te = p
 				p--
 				{
 					token(TokenInvalid)
 				}
 			case 17:
-//line scan_tokens.rl:262
+//line scan_tokens.rl:292
 				te = p
 				p--
 				{
 					token(TokenBadUTF8)
 				}
 			case 18:
-//line scan_tokens.rl:259
+//line scan_tokens.rl:289
 				p = (te) - 1
 				{
 					token(TokenQuotedLit)
 				}
 			case 19:
-//line scan_tokens.rl:262
+//line scan_tokens.rl:292
 				p = (te) - 1
 				{
 					token(TokenBadUTF8)
@@ -4551,13 +5290,13 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 				}
 
 			case 21:
-//line scan_tokens.rl:159
+//line scan_tokens.rl:189
 				act = 11
 			case 22:
-//line scan_tokens.rl:270
+//line scan_tokens.rl:300
 				act = 12
 			case 23:
-//line scan_tokens.rl:171
+//line scan_tokens.rl:201
 				te = p + 1
 				{
 					token(TokenTemplateInterp)
@@ -4570,12 +5309,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 24:
-//line scan_tokens.rl:181
+//line scan_tokens.rl:211
 				te = p + 1
 				{
 					token(TokenTemplateControl)
@@ -4588,12 +5327,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 25:
-//line scan_tokens.rl:122
+//line scan_tokens.rl:152
 				te = p + 1
 				{
 					// This action is called specificially when a heredoc literal
@@ -4638,13 +5377,13 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 					token(TokenStringLit)
 				}
 			case 26:
-//line scan_tokens.rl:270
+//line scan_tokens.rl:300
 				te = p + 1
 				{
 					token(TokenBadUTF8)
 				}
 			case 27:
-//line scan_tokens.rl:171
+//line scan_tokens.rl:201
 				te = p
 				p--
 				{
@@ -4658,12 +5397,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 28:
-//line scan_tokens.rl:181
+//line scan_tokens.rl:211
 				te = p
 				p--
 				{
@@ -4677,12 +5416,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 29:
-//line scan_tokens.rl:159
+//line scan_tokens.rl:189
 				te = p
 				p--
 				{
@@ -4693,14 +5432,14 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 					token(TokenStringLit)
 				}
 			case 30:
-//line scan_tokens.rl:270
+//line scan_tokens.rl:300
 				te = p
 				p--
 				{
 					token(TokenBadUTF8)
 				}
 			case 31:
-//line scan_tokens.rl:159
+//line scan_tokens.rl:189
 				p = (te) - 1
 				{
 					// This action is called when a heredoc literal _doesn't_ end
@@ -4735,13 +5474,13 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 				}
 
 			case 33:
-//line scan_tokens.rl:167
+//line scan_tokens.rl:197
 				act = 15
 			case 34:
-//line scan_tokens.rl:277
+//line scan_tokens.rl:307
 				act = 16
 			case 35:
-//line scan_tokens.rl:171
+//line scan_tokens.rl:201
 				te = p + 1
 				{
 					token(TokenTemplateInterp)
@@ -4754,12 +5493,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 36:
-//line scan_tokens.rl:181
+//line scan_tokens.rl:211
 				te = p + 1
 				{
 					token(TokenTemplateControl)
@@ -4772,24 +5511,24 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 37:
-//line scan_tokens.rl:167
+//line scan_tokens.rl:197
 				te = p + 1
 				{
 					token(TokenStringLit)
 				}
 			case 38:
-//line scan_tokens.rl:277
+//line scan_tokens.rl:307
 				te = p + 1
 				{
 					token(TokenBadUTF8)
 				}
 			case 39:
-//line scan_tokens.rl:171
+//line scan_tokens.rl:201
 				te = p
 				p--
 				{
@@ -4803,12 +5542,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 40:
-//line scan_tokens.rl:181
+//line scan_tokens.rl:211
 				te = p
 				p--
 				{
@@ -4822,26 +5561,26 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1459
+						cs = 1735
 						goto _again
 					}
 				}
 			case 41:
-//line scan_tokens.rl:167
+//line scan_tokens.rl:197
 				te = p
 				p--
 				{
 					token(TokenStringLit)
 				}
 			case 42:
-//line scan_tokens.rl:277
+//line scan_tokens.rl:307
 				te = p
 				p--
 				{
 					token(TokenBadUTF8)
 				}
 			case 43:
-//line scan_tokens.rl:167
+//line scan_tokens.rl:197
 				p = (te) - 1
 				{
 					token(TokenStringLit)
@@ -4868,45 +5607,45 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 				}
 
 			case 45:
-//line scan_tokens.rl:281
+//line scan_tokens.rl:311
 				act = 17
 			case 46:
-//line scan_tokens.rl:282
+//line scan_tokens.rl:312
 				act = 18
 			case 47:
-//line scan_tokens.rl:282
+//line scan_tokens.rl:312
 				te = p + 1
 				{
 					token(TokenBadUTF8)
 				}
 			case 48:
-//line scan_tokens.rl:283
+//line scan_tokens.rl:313
 				te = p + 1
 				{
 					token(TokenInvalid)
 				}
 			case 49:
-//line scan_tokens.rl:281
+//line scan_tokens.rl:311
 				te = p
 				p--
 				{
 					token(TokenIdent)
 				}
 			case 50:
-//line scan_tokens.rl:282
+//line scan_tokens.rl:312
 				te = p
 				p--
 				{
 					token(TokenBadUTF8)
 				}
 			case 51:
-//line scan_tokens.rl:281
+//line scan_tokens.rl:311
 				p = (te) - 1
 				{
 					token(TokenIdent)
 				}
 			case 52:
-//line scan_tokens.rl:282
+//line scan_tokens.rl:312
 				p = (te) - 1
 				{
 					token(TokenBadUTF8)
@@ -4927,92 +5666,111 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 				}
 
 			case 54:
-//line scan_tokens.rl:289
-				act = 22
+//line scan_tokens.rl:320
+				act = 23
 			case 55:
-//line scan_tokens.rl:313
-				act = 40
+//line scan_tokens.rl:345
+				act = 42
 			case 56:
-//line scan_tokens.rl:291
+//line scan_tokens.rl:109
 				te = p + 1
 				{
-					token(TokenComment)
+					// Back up to the first of the trailing underscores, so that
+					// we'll scan again from there once we've emitted the number.
+					te--
+					for data[te-1] == '_' {
+						te--
+					}
+					token(TokenNumberLit)
+					p = (te) - 1
 				}
 			case 57:
-//line scan_tokens.rl:292
+//line scan_tokens.rl:322
 				te = p + 1
 				{
-					token(TokenNewline)
+					token(TokenComment)
 				}
 			case 58:
-//line scan_tokens.rl:294
+//line scan_tokens.rl:323
 				te = p + 1
 				{
-					token(TokenEqualOp)
+					token(TokenNewline)
 				}
 			case 59:
-//line scan_tokens.rl:295
+//line scan_tokens.rl:325
 				te = p + 1
 				{
-					token(TokenNotEqual)
+					token(TokenEqualOp)
 				}
 			case 60:
-//line scan_tokens.rl:296
+//line scan_tokens.rl:326
 				te = p + 1
 				{
-					token(TokenGreaterThanEq)
+					token(TokenNotEqual)
 				}
 			case 61:
-//line scan_tokens.rl:297
+//line scan_tokens.rl:327
 				te = p + 1
 				{
-					token(TokenLessThanEq)
+					token(TokenGreaterThanEq)
 				}
 			case 62:
-//line scan_tokens.rl:298
+//line scan_tokens.rl:328
 				te = p + 1
 				{
-					token(TokenAnd)
+					token(TokenLessThanEq)
 				}
 			case 63:
-//line scan_tokens.rl:299
+//line scan_tokens.rl:329
 				te = p + 1
 				{
-					token(TokenOr)
+					token(TokenAnd)
 				}
 			case 64:
-//line scan_tokens.rl:300
+//line scan_tokens.rl:330
 				te = p + 1
 				{
-					token(TokenDoubleColon)
+					token(TokenOr)
 				}
 			case 65:
-//line scan_tokens.rl:301
+//line scan_tokens.rl:331
 				te = p + 1
 				{
-					token(TokenEllipsis)
+					token(TokenDoubleColon)
 				}
 			case 66:
-//line scan_tokens.rl:302
+//line scan_tokens.rl:332
 				te = p + 1
 				{
-					token(TokenFatArrow)
+					token(TokenEllipsis)
 				}
 			case 67:
-//line scan_tokens.rl:303
+//line scan_tokens.rl:333
 				te = p + 1
 				{
-					selfToken()
+					token(TokenFatArrow)
 				}
 			case 68:
-//line scan_tokens.rl:191
+//line scan_tokens.rl:334
+				te = p + 1
+				{
+					token(TokenNullCoalesce)
+				}
+			case 69:
+//line scan_tokens.rl:335
+				te = p + 1
+				{
+					selfToken()
+				}
+			case 70:
+//line scan_tokens.rl:221
 				te = p + 1
 				{
 					token(TokenOBrace)
 					braces++
 				}
-			case 69:
-//line scan_tokens.rl:196
+			case 71:
+//line scan_tokens.rl:226
 				te = p + 1
 				{
 					if len(retBraces) > 0 && retBraces[len(retBraces)-1] == braces {
@@ -5031,8 +5789,8 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						braces--
 					}
 				}
-			case 70:
-//line scan_tokens.rl:208
+			case 72:
+//line scan_tokens.rl:238
 				te = p + 1
 				{
 					// Only consume from the retBraces stack and return if we are at
@@ -5060,8 +5818,8 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						braces--
 					}
 				}
-			case 71:
-//line scan_tokens.rl:90
+			case 73:
+//line scan_tokens.rl:120
 				te = p + 1
 				{
 					token(TokenOQuote)
@@ -5069,12 +5827,12 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1510
+						cs = 1791
 						goto _again
 					}
 				}
-			case 72:
-//line scan_tokens.rl:100
+			case 74:
+//line scan_tokens.rl:130
 				te = p + 1
 				{
 					token(TokenOHeredoc)
@@ -5099,109 +5857,122 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 						stack = append(stack, 0)
 						stack[top] = cs
 						top++
-						cs = 1524
+						cs = 1805
 						goto _again
 					}
 				}
-			case 73:
-//line scan_tokens.rl:313
+			case 75:
+//line scan_tokens.rl:345
 				te = p + 1
 				{
 					token(TokenBadUTF8)
 				}
-			case 74:
-//line scan_tokens.rl:314
+			case 76:
+//line scan_tokens.rl:346
 				te = p + 1
 				{
 					token(TokenInvalid)
 				}
-			case 75:
-//line scan_tokens.rl:287
+			case 77:
+//line scan_tokens.rl:317
 				te = p
 				p--
 
-			case 76:
-//line scan_tokens.rl:288
+			case 78:
+//line scan_tokens.rl:318
 				te = p
 				p--
 				{
 					token(TokenNumberLit)
 				}
-			case 77:
-//line scan_tokens.rl:289
+			case 79:
+//line scan_tokens.rl:320
 				te = p
 				p--
 				{
 					token(TokenIdent)
 				}
-			case 78:
-//line scan_tokens.rl:291
+			case 80:
+//line scan_tokens.rl:322
 				te = p
 				p--
 				{
 					token(TokenComment)
 				}
-			case 79:
-//line scan_tokens.rl:303
+			case 81:
+//line scan_tokens.rl:335
 				te = p
 				p--
 				{
 					selfToken()
 				}
-			case 80:
-//line scan_tokens.rl:313
+			case 82:
+//line scan_tokens.rl:345
 				te = p
 				p--
 				{
 					token(TokenBadUTF8)
 				}
-			case 81:
-//line scan_tokens.rl:314
+			case 83:
+//line scan_tokens.rl:346
 				te = p
 				p--
 				{
 					token(TokenInvalid)
 				}
-			case 82:
-//line scan_tokens.rl:288
+			case 84:
+//line scan_tokens.rl:318
 				p = (te) - 1
 				{
 					token(TokenNumberLit)
 				}
-			case 83:
-//line scan_tokens.rl:289
+			case 85:
+//line scan_tokens.rl:109
+				p = (te) - 1
+				{
+					// Back up to the first of the trailing underscores, so that
+					// we'll scan again from there once we've emitted the number.
+					te--
+					for data[te-1] == '_' {
+						te--
+					}
+					token(TokenNumberLit)
+					p = (te) - 1
+				}
+			case 86:
+//line scan_tokens.rl:320
 				p = (te) - 1
 				{
 					token(TokenIdent)
 				}
-			case 84:
-//line scan_tokens.rl:303
+			case 87:
+//line scan_tokens.rl:335
 				p = (te) - 1
 				{
 					selfToken()
 				}
-			case 85:
-//line scan_tokens.rl:313
+			case 88:
+//line scan_tokens.rl:345
 				p = (te) - 1
 				{
 					token(TokenBadUTF8)
 				}
-			case 86:
+			case 89:
 //line NONE:1
 				switch act {
-				case 22:
+				case 23:
 					{
 						p = (te) - 1
 						token(TokenIdent)
 					}
-				case 40:
+				case 42:
 					{
 						p = (te) - 1
 						token(TokenBadUTF8)
 					}
 				}
 
-//line scan_tokens.go:5062
+//line scan_tokens.go:5833
 			}
 		}
 
@@ -5220,7 +5991,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 //line NONE:1
 				act = 0
 
-//line scan_tokens.go:5080
+//line scan_tokens.go:5851
 			}
 		}
 
@@ -5246,7 +6017,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 		}
 	}
 
-//line scan_tokens.rl:375
+//line scan_tokens.rl:435
 
 	// If we fall out here without being in a final state then we've
 	// encountered something that the scanner can't match, which we'll
@@ -17,7 +17,7 @@ import (
   write data;
 }%%
 
-func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []Token {
+func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode, rawStrings bool, callback func(Token) bool) []Token {
     stripData := stripUTF8BOM(data)
     start.Byte += len(data) - len(stripData)
     data = stripData
@@ -27,6 +27,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
         Bytes:     data,
         Pos:       start,
         StartByte: start.Byte,
+        Callback:  callback,
     }
 
     %%{
@@ -41,8 +42,25 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
         );
         BrokenUTF8 = any - AnyUTF8;
 
-        NumberLitContinue = (digit|'.'|('e'|'E') ('+'|'-')? digit);
-        NumberLit = digit ("" | (NumberLitContinue - '.') | (NumberLitContinue* (NumberLitContinue - '.')));
+        # Underscores are accepted as digit separators anywhere after the
+        # first digit, and the parser then validates their placement so that
+        # it can return helpful error messages for misplaced separators. A
+        # period directly followed by an underscore is excluded so that a
+        # traversal like foo.0._bar still has "_bar" as its attribute name.
+        NumberLitContinue = (digit|'_'|'.'|('e'|'E') ('+'|'-')? digit);
+        DecimalNumberLit = (digit ("" | (NumberLitContinue - '.') | (NumberLitContinue* (NumberLitContinue - '.')))) -- '._';
+
+        # Hexadecimal and binary number literals may contain any of the
+        # characters of an identifier after their prefix, and the parser then
+        # validates the digits so that it can report any that are invalid.
+        RadixNumberLit = '0' ('x'|'X'|'b'|'B') (ID_Continue | '-')*;
+        NumberLit = DecimalNumberLit | RadixNumberLit;
+
+        # Underscores at the end of a number literal that are directly
+        # followed by a letter are instead the beginning of an identifier, as
+        # in the "_ap" of foo_7.2_ap, so this matches one character beyond
+        # them and the numberLitPrefix action emits only the number itself.
+        NumberLitPrefix = (DecimalNumberLit & (any* '_')) alpha;
         Ident = (ID_Start | '_') (ID_Continue | '-')*;
 
         # Symbols that just represent themselves are handled as a single rule.
@@ -58,6 +76,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
         DoubleColon = "::";
         Ellipsis = "...";
         FatArrow = "=>";
+        NullCoalesce = "??";
 
         Newline = '\r' ? '\n';
         EndOfLine = Newline;
@@ -87,6 +106,17 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
         # automatically is to never use tabs).
         Spaces = (' ' | 0x09)+;
 
+        action numberLitPrefix {
+            // Back up to the first of the trailing underscores, so that
+            // we'll scan again from there once we've emitted the number.
+            te--;
+            for data[te-1] == '_' {
+                te--;
+            }
+            token(TokenNumberLit);
+            fexec te;
+        }
+
         action beginStringTemplate {
             token(TokenOQuote);
             fcall stringTemplate;
@@ -286,6 +316,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
         main := |*
             Spaces           => {};
             NumberLit        => { token(TokenNumberLit) };
+            NumberLitPrefix  => numberLitPrefix;
             Ident            => { token(TokenIdent) };
 
             Comment          => { token(TokenComment) };
@@ -300,6 +331,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
             DoubleColon      => { token(TokenDoubleColon); };
             Ellipsis         => { token(TokenEllipsis); };
             FatArrow         => { token(TokenFatArrow); };
+            NullCoalesce     => { token(TokenNullCoalesce); };
             SelfToken        => { selfToken() };
 
             "{"              => openBrace;
@@ -357,8 +389,18 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
     _ = act
     _ = eof
 
+    // stopIfRequested moves the scanner to the end of the input if the
+    // callback has asked us to stop, so that the rest of the input isn't
+    // scanned only to have its tokens discarded.
+    stopIfRequested := func () {
+        if f.stopped {
+            p = pe - 1
+            eof = -1
+        }
+    }
     token := func (ty TokenType) {
         f.emitToken(ty, ts, te)
+        stopIfRequested()
     }
     selfToken := func () {
         b := data[ts:te]
@@ -366,7 +408,25 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
             // should never happen
             panic("selfToken only works for single-character tokens")
         }
+        if b[0] == '`' && rawStrings {
+            // If raw strings are enabled then a backtick begins a raw string
+            // literal, which continues verbatim up to the next backtick (or
+            // the end of the input, if unterminated) without any escapes or
+            // template sequences. We consume it here and then move the
+            // scanner past it. Otherwise it is just an invalid character.
+            end := bytes.IndexByte(data[te:], '`')
+            if end < 0 {
+                te = len(data)
+            } else {
+                te += end + 1
+            }
+            p = te - 1
+            f.emitToken(TokenRawLit, ts, te)
+            stopIfRequested()
+            return
+        }
         f.emitToken(TokenType(b[0]), ts, te)
+        stopIfRequested()
     }
 
     %%{
@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ForIteratorShadowing inspects all of the for expressions within the given
+// node, which is typically a *Body or an Expression, and returns a warning
+// diagnostic for each iterator variable whose name shadows either a variable
+// in the given EvalContext or an iterator of an enclosing for expression.
+//
+// This is a static check intended for linting: shadowing is valid and the
+// inner name simply takes precedence during evaluation, but it is often
+// unintentional. The given context may be nil, in which case only shadowing
+// of enclosing iterators is reported. A name that is not in Variables is
+// looked up using the context's VariableResolver, if any.
+func ForIteratorShadowing(node Node, ctx *hcl.EvalContext) hcl.Diagnostics {
+	w := &forShadowingWalker{ctx: ctx}
+	return Walk(node, w)
+}
+
+type forShadowingWalker struct {
+	ctx         *hcl.EvalContext
+	localScopes []map[string]struct{}
+}
+
+func (w *forShadowingWalker) Enter(n Node) hcl.Diagnostics {
+	switch tn := n.(type) {
+	case *ForExpr:
+		var diags hcl.Diagnostics
+		for _, name := range []string{tn.KeyVar, tn.ValVar} {
+			if name == "" {
+				continue
+			}
+			if w.isLocal(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Iterator shadows outer iterator",
+					Detail:   fmt.Sprintf("The iterator name %q is already used by an enclosing for expression, so the outer iterator cannot be accessed within this for expression.", name),
+					Subject:  tn.SrcRange.Ptr(),
+				})
+			} else if w.isVariable(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Iterator shadows variable",
+					Detail:   fmt.Sprintf("The iterator name %q is also the name of a variable, so that variable cannot be accessed within this for expression.", name),
+					Subject:  tn.SrcRange.Ptr(),
+				})
+			}
+		}
+		return diags
+	case ChildScope:
+		w.localScopes = append(w.localScopes, tn.LocalNames)
+	}
+	return nil
+}
+
+func (w *forShadowingWalker) Exit(n Node) hcl.Diagnostics {
+	switch n.(type) {
+	case ChildScope:
+		w.localScopes = w.localScopes[:len(w.localScopes)-1]
+	}
+	return nil
+}
+
+func (w *forShadowingWalker) isLocal(name string) bool {
+	for _, names := range w.localScopes {
+		if _, exists := names[name]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *forShadowingWalker) isVariable(name string) bool {
+	for ctx := w.ctx; ctx != nil; ctx = ctx.Parent() {
+		if _, exists := ctx.Variables[name]; exists {
+			return true
+		}
+		if ctx.VariableResolver != nil {
+			if _, exists := ctx.VariableResolver(name); exists {
+				return true
+			}
+		}
+	}
+	return false
+}
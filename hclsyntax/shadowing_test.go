@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestForIteratorShadowing(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"v":    cty.True,
+			"list": cty.EmptyTupleVal,
+		},
+	}
+	child := ctx.NewChild()
+	child.VariableResolver = func(name string) (cty.Value, bool) {
+		return cty.True, name == "lazy"
+	}
+
+	tests := []struct {
+		src  string
+		ctx  *hcl.EvalContext
+		want []string
+	}{
+		{
+			`a = [for x in list : x]`,
+			ctx,
+			nil,
+		},
+		{
+			`a = [for v in list : v]`,
+			ctx,
+			[]string{"Iterator shadows variable"},
+		},
+		{
+			`a = {for k, v in list : k => v}`,
+			ctx,
+			[]string{"Iterator shadows variable"},
+		},
+		{
+			`a = [for v in list : v]`,
+			nil,
+			nil,
+		},
+		{
+			`a = [for lazy in list : lazy]`,
+			child,
+			[]string{"Iterator shadows variable"},
+		},
+		{
+			`a = [for x in list : [for x in x : x]]`,
+			ctx,
+			[]string{"Iterator shadows outer iterator"},
+		},
+		{
+			// The collection of a nested for expression is evaluated in
+			// the scope of the outer one, but that isn't shadowing.
+			`a = [for x in list : [for y in x : y]]`,
+			ctx,
+			nil,
+		},
+		{
+			`a = [for x in list : x]
+b = [for x in list : x]`,
+			ctx,
+			nil,
+		},
+		{
+			`block {
+  a = [for v in list : v]
+}`,
+			ctx,
+			[]string{"Iterator shadows variable"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			file, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			diags = ForIteratorShadowing(file.Body.(*Body), test.ctx)
+			var got []string
+			for _, diag := range diags {
+				if diag.Severity != hcl.DiagWarning {
+					t.Errorf("diagnostic is not a warning: %s", diag.Error())
+				}
+				got = append(got, diag.Summary)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ package hclsyntax
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -54,7 +55,14 @@ func (b *Body) Range() hcl.Range {
 }
 
 func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
-	content, remainHCL, diags := b.PartialContent(schema)
+	return b.ContentWithDecoder(hcl.NewBodyDecoder(schema))
+}
+
+// ContentWithDecoder implements hcl.DecoderBody, retrieving the content of
+// the body using the decoder's prepared schema.
+func (b *Body) ContentWithDecoder(d *hcl.BodyDecoder) (*hcl.BodyContent, hcl.Diagnostics) {
+	schema := d.Schema()
+	content, remainHCL, diags := b.PartialContentWithDecoder(d)
 
 	// No we'll see if anything actually remains, to produce errors about
 	// extraneous items.
@@ -124,7 +132,14 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 }
 
 func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
-	attrs := make(hcl.Attributes)
+	return b.PartialContentWithDecoder(hcl.NewBodyDecoder(schema))
+}
+
+// PartialContentWithDecoder implements hcl.DecoderBody, retrieving the
+// content of the body using the decoder's prepared schema.
+func (b *Body) PartialContentWithDecoder(d *hcl.BodyDecoder) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	schema := d.Schema()
+	attrs := make(hcl.Attributes, len(schema.Attributes))
 	var blocks hcl.Blocks
 	var diags hcl.Diagnostics
 	hiddenAttrs := make(map[string]struct{})
@@ -145,6 +160,21 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 		name := attrS.Name
 		attr, exists := b.Attributes[name]
 		_, hidden := hiddenAttrs[name]
+		if !exists && schema.CaseInsensitive {
+			if foldAttr := b.findAttributeFold(name, hiddenAttrs); foldAttr != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Non-canonical argument name",
+					Detail:   fmt.Sprintf("The argument name %q should be written as %q. Names that differ only in letter case are deprecated.", foldAttr.Name, name),
+					Subject:  &foldAttr.NameRange,
+				})
+				hiddenAttrs[foldAttr.Name] = struct{}{}
+				hclAttr := foldAttr.AsHCLAttribute()
+				hclAttr.Name = name
+				attrs[name] = hclAttr
+				continue
+			}
+		}
 		if hidden || !exists {
 			if attrS.Required {
 				diags = append(diags, &hcl.Diagnostic{
@@ -161,21 +191,28 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 		attrs[name] = attr.AsHCLAttribute()
 	}
 
-	blocksWanted := make(map[string]hcl.BlockHeaderSchema)
-	for _, blockS := range schema.Blocks {
-		blocksWanted[blockS.Type] = blockS
-	}
+	var foldBlockTypes []string
 
 	for _, block := range b.Blocks {
 		if _, hidden := hiddenBlocks[block.Type]; hidden {
 			continue
 		}
-		blockS, wanted := blocksWanted[block.Type]
+		blockS, wanted := d.BlockSchema(block.Type)
 		if !wanted {
-			continue
+			blockS, wanted = d.BlockSchemaFold(block.Type)
+			if !wanted {
+				continue
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Non-canonical block type",
+				Detail:   fmt.Sprintf("The block type %q should be written as %q. Names that differ only in letter case are deprecated.", block.Type, blockS.Type),
+				Subject:  &block.TypeRange,
+			})
+			foldBlockTypes = append(foldBlockTypes, block.Type)
 		}
 
-		if len(block.Labels) > len(blockS.LabelNames) {
+		if len(block.Labels) > len(blockS.LabelNames) && !blockS.ExtraLabels {
 			name := block.Type
 			if len(blockS.LabelNames) == 0 {
 				diags = append(diags, &hcl.Diagnostic{
@@ -217,7 +254,9 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 			continue
 		}
 
-		blocks = append(blocks, block.AsHCLBlock())
+		hclBlock := block.AsHCLBlock()
+		hclBlock.Type = blockS.Type
+		blocks = append(blocks, hclBlock)
 	}
 
 	// We hide blocks only after we've processed all of them, since otherwise
@@ -225,6 +264,9 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 	for _, blockS := range schema.Blocks {
 		hiddenBlocks[blockS.Type] = struct{}{}
 	}
+	for _, blockType := range foldBlockTypes {
+		hiddenBlocks[blockType] = struct{}{}
+	}
 
 	remain := &Body{
 		Attributes: b.Attributes,
@@ -245,16 +287,82 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 	}, remain, diags
 }
 
+// findAttributeFold returns the attribute whose name matches the given name
+// when ignoring case, excluding any that are hidden, or nil if there is no
+// such attribute. If there are several then the one whose name sorts first
+// is returned, so that the result is deterministic.
+func (b *Body) findAttributeFold(name string, hidden map[string]struct{}) *Attribute {
+	var ret *Attribute
+	for attrName, attr := range b.Attributes {
+		if _, isHidden := hidden[attrName]; isHidden {
+			continue
+		}
+		if !strings.EqualFold(attrName, name) {
+			continue
+		}
+		if ret == nil || attrName < ret.Name {
+			ret = attr
+		}
+	}
+	return ret
+}
+
+// InferredSchema returns a schema describing all of the attributes and
+// block types in the body, for use with hcl.BodyToValue. Each block type is
+// described with as many labels as the first block of that type has.
+func (b *Body) InferredSchema() *hcl.BodySchema {
+	schema := &hcl.BodySchema{}
+	for name := range b.Attributes {
+		if _, hidden := b.hiddenAttrs[name]; hidden {
+			continue
+		}
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+	sort.Slice(schema.Attributes, func(i, j int) bool {
+		return schema.Attributes[i].Name < schema.Attributes[j].Name
+	})
+
+	seen := make(map[string]struct{})
+	for _, block := range b.Blocks {
+		if _, hidden := b.hiddenBlocks[block.Type]; hidden {
+			continue
+		}
+		if _, exists := seen[block.Type]; exists {
+			continue
+		}
+		seen[block.Type] = struct{}{}
+		labelNames := make([]string, len(block.Labels))
+		for i := range labelNames {
+			labelNames[i] = fmt.Sprintf("label%d", i)
+		}
+		schema.Blocks = append(schema.Blocks, hcl.BlockHeaderSchema{
+			Type:       block.Type,
+			LabelNames: labelNames,
+		})
+	}
+	return schema
+}
+
 func (b *Body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	return b.justAttributes(hcl.DiagError, "Blocks are not allowed here.")
+}
+
+// JustAttributesPartial is like JustAttributes except that any blocks in
+// the body are ignored, producing only a warning diagnostic.
+func (b *Body) JustAttributesPartial() (hcl.Attributes, hcl.Diagnostics) {
+	return b.justAttributes(hcl.DiagWarning, "Blocks are not expected here and will be ignored.")
+}
+
+func (b *Body) justAttributes(blockSeverity hcl.DiagnosticSeverity, blockDetail string) (hcl.Attributes, hcl.Diagnostics) {
 	attrs := make(hcl.Attributes)
 	var diags hcl.Diagnostics
 
 	if len(b.Blocks) > 0 {
 		example := b.Blocks[0]
 		diags = append(diags, &hcl.Diagnostic{
-			Severity: hcl.DiagError,
+			Severity: blockSeverity,
 			Summary:  fmt.Sprintf("Unexpected %q block", example.Type),
-			Detail:   "Blocks are not allowed here.",
+			Detail:   blockDetail,
 			Subject:  &example.TypeRange,
 		})
 		// we will continue processing anyway, and return the attributes
@@ -276,6 +384,78 @@ func (b *Body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 	return attrs, diags
 }
 
+// BlockSummary describes the header of a single block, as returned by
+// Body.BlockTypes.
+type BlockSummary struct {
+	Type       string
+	LabelCount int
+
+	TypeRange   hcl.Range
+	LabelRanges []hcl.Range
+	DefRange    hcl.Range
+	Range       hcl.Range
+}
+
+// BlockTypes returns a summary of each of the blocks directly within the
+// receiving body, in the order they appear in the source.
+//
+// Unlike Content and PartialContent this is a purely structural operation
+// that does not require a schema and produces no diagnostics, which makes
+// it useful for tools such as editors that need to inspect configuration
+// that may not yet be valid. All blocks are included, even if they were
+// already consumed by an earlier call to PartialContent.
+func (b *Body) BlockTypes() []BlockSummary {
+	if len(b.Blocks) == 0 {
+		return nil
+	}
+
+	ret := make([]BlockSummary, len(b.Blocks))
+	for i, block := range b.Blocks {
+		ret[i] = BlockSummary{
+			Type:       block.Type,
+			LabelCount: len(block.Labels),
+
+			TypeRange:   block.TypeRange,
+			LabelRanges: block.LabelRanges,
+			DefRange:    block.DefRange(),
+			Range:       block.Range(),
+		}
+	}
+	return ret
+}
+
+// EnclosingBlocks returns the chain of blocks within the receiving body,
+// including those nested inside other blocks, whose ranges contain the
+// whole of the given range, ordered from the outermost to the innermost.
+// The result is nil if the range is not inside any block, or if it belongs
+// to a different file.
+//
+// This is a purely structural operation that is intended for adding context
+// to diagnostics, such as by rendering the headers of the blocks as a
+// breadcrumb trail leading to the subject of a diagnostic about an
+// attribute.
+func (b *Body) EnclosingBlocks(rng hcl.Range) []*Block {
+	if rng.Filename != b.SrcRange.Filename {
+		return nil
+	}
+
+	var ret []*Block
+	body := b
+Blocks:
+	for body != nil {
+		for _, block := range body.Blocks {
+			blockRange := block.Range()
+			if blockRange.ContainsOffset(rng.Start.Byte) && rng.End.Byte <= blockRange.End.Byte {
+				ret = append(ret, block)
+				body = block.Body
+				continue Blocks
+			}
+		}
+		break
+	}
+	return ret
+}
+
 func (b *Body) MissingItemRange() hcl.Range {
 	return hcl.Range{
 		Filename: b.SrcRange.Filename,
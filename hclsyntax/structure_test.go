@@ -6,6 +6,7 @@ package hclsyntax
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -367,6 +368,41 @@ func TestBodyContent(t *testing.T) {
 			},
 			1, // too many labels
 		},
+		{
+			&Body{
+				Blocks: Blocks{
+					&Block{
+						Type:   "foo",
+						Labels: []string{"bar", "baz"},
+
+						LabelRanges: []hcl.Range{{}, {}},
+					},
+				},
+			},
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:        "foo",
+						LabelNames:  []string{"name"},
+						ExtraLabels: true,
+					},
+				},
+			},
+			false,
+			&hcl.BodyContent{
+				Attributes: hcl.Attributes{},
+				Blocks: hcl.Blocks{
+					{
+						Type:   "foo",
+						Labels: []string{"bar", "baz"},
+						Body:   (*Body)(nil),
+
+						LabelRanges: []hcl.Range{{}, {}},
+					},
+				},
+			},
+			0, // extra labels allowed by the schema
+		},
 		{
 			&Body{
 				Attributes: Attributes{
@@ -534,3 +570,553 @@ func TestBodyJustAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestBodyJustAttributesPartial(t *testing.T) {
+	body := &Body{
+		Attributes: Attributes{
+			"foo": &Attribute{
+				Name: "foo",
+				Expr: &LiteralValueExpr{
+					Val: cty.StringVal("bar"),
+				},
+			},
+		},
+		Blocks: Blocks{
+			{
+				Type: "baz",
+			},
+		},
+	}
+
+	got, diags := hcl.JustAttributesPartial(body)
+	if diags.HasErrors() {
+		t.Errorf("unexpected errors: %s", diags.Error())
+	}
+	if len(diags) != 1 || diags[0].Severity != hcl.DiagWarning {
+		t.Errorf("wrong diagnostics; want a single warning about the block")
+		for _, diag := range diags {
+			t.Logf(" - %s", diag.Error())
+		}
+	}
+	want := hcl.Attributes{
+		"foo": &hcl.Attribute{
+			Name: "foo",
+			Expr: &LiteralValueExpr{
+				Val: cty.StringVal("bar"),
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\n%s", pretty.Compare(want, got))
+	}
+
+	// The strict behavior must be unaffected.
+	if _, diags := body.JustAttributes(); !diags.HasErrors() {
+		t.Errorf("JustAttributes succeeded; want an error for the block")
+	}
+}
+
+func TestBodyBlockTypes(t *testing.T) {
+	src := `
+foo = 1
+resource "a" "b" {
+  nested {}
+}
+provider "c" {
+}
+locals {
+}
+`
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	body := f.Body.(*Body)
+
+	got := body.BlockTypes()
+	want := []struct {
+		Type       string
+		LabelCount int
+		TypeLine   int
+	}{
+		{"resource", 2, 3},
+		{"provider", 1, 6},
+		{"locals", 0, 8},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of summaries %d; want %d\n%s", len(got), len(want), pretty.Sprint(got))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Type != w.Type || g.LabelCount != w.LabelCount || g.TypeRange.Start.Line != w.TypeLine {
+			t.Errorf("wrong summary %d\ngot:  %s %d at line %d\nwant: %s %d at line %d", i, g.Type, g.LabelCount, g.TypeRange.Start.Line, w.Type, w.LabelCount, w.TypeLine)
+		}
+		if len(g.LabelRanges) != g.LabelCount {
+			t.Errorf("summary %d has %d label ranges; want %d", i, len(g.LabelRanges), g.LabelCount)
+		}
+	}
+	if got, want := got[0].DefRange, body.Blocks[0].DefRange(); got != want {
+		t.Errorf("wrong DefRange\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if got := (&Body{}).BlockTypes(); got != nil {
+		t.Errorf("wrong result for empty body: %#v", got)
+	}
+}
+
+func TestBodyToValue(t *testing.T) {
+	src := `
+name = "example"
+count = num + 1
+broken = nope
+resource "a" "b" {
+  size = 2
+  tag {
+    key = "x"
+  }
+  tag {
+    key = "y"
+  }
+}
+resource "a" "c" {
+}
+locals {
+  enabled = true
+}
+name {
+}
+`
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"num": cty.NumberIntVal(1),
+		},
+	}
+
+	got, diags := hcl.BodyToValue(f.Body, ctx)
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name":   cty.StringVal("example"),
+		"count":  cty.NumberIntVal(2),
+		"broken": cty.DynamicVal,
+		"resource": cty.ObjectVal(map[string]cty.Value{
+			"a": cty.ObjectVal(map[string]cty.Value{
+				"b": cty.ObjectVal(map[string]cty.Value{
+					"size": cty.NumberIntVal(2),
+					"tag": cty.TupleVal([]cty.Value{
+						cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal("x")}),
+						cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal("y")}),
+					}),
+				}),
+				"c": cty.EmptyObjectVal,
+			}),
+		}),
+		"locals": cty.ObjectVal(map[string]cty.Value{
+			"enabled": cty.True,
+		}),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	var gotDiags []string
+	for _, diag := range diags {
+		gotDiags = append(gotDiags, diag.Error())
+	}
+	wantDiags := []string{
+		`test.hcl:4,10-14: Unknown variable; There is no variable named "nope".`,
+		`test.hcl:19,1-5: Conflicting block type; The "name" blocks are ignored, because there is also an argument named "name" at test.hcl:2,1-5.`,
+	}
+	if !reflect.DeepEqual(gotDiags, wantDiags) {
+		t.Errorf("wrong diagnostics\n%s", pretty.Compare(wantDiags, gotDiags))
+	}
+}
+
+func TestDiffBodies(t *testing.T) {
+	oldSrc := `
+name    = "example"
+ref     = var.foo
+removed = 1
+changed = 1
+call    = upper(var.foo)
+list    = [var.foo, { a = var.bar }]
+renamed = upper(var.foo)
+
+resource "a" "b" {
+  size = 2
+  tag {
+    key = "x"
+  }
+}
+resource "a" "c" {
+}
+locals {
+  enabled = true
+}
+`
+	newSrc := `
+changed = 2
+added   = now()
+ref     = var.foo
+name    = "example"
+call    = upper(var.foo)
+list    = [var.foo, { a = var.bar }]
+renamed = lower(var.foo)
+
+locals {
+  enabled = true
+}
+resource "a" "d" {
+}
+resource "a" "b" {
+  size = 3
+  tag {
+    key = "x"
+  }
+}
+`
+	oldFile, diags := ParseConfig([]byte(oldSrc), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	newFile, diags := ParseConfig([]byte(newSrc), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	d, diags := hcl.DiffBodies(oldFile.Body, newFile.Body, nil, nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []string
+	var describe func(prefix string, d *hcl.BodyDiff)
+	describe = func(prefix string, d *hcl.BodyDiff) {
+		for _, attr := range d.Attributes {
+			got = append(got, fmt.Sprintf("%s%c %s", prefix, attr.Action, attr.Name))
+		}
+		for _, block := range d.Blocks {
+			got = append(got, fmt.Sprintf("%s%c %s %q", prefix, block.Action, block.Type, block.Labels))
+			if block.Body != nil {
+				describe(prefix+"  ", block.Body)
+			}
+		}
+	}
+	describe("", d)
+
+	want := []string{
+		`+ added`,
+		`~ changed`,
+		`- removed`,
+		`~ renamed`,
+		`~ resource ["a" "b"]`,
+		`  ~ size`,
+		`- resource ["a" "c"]`,
+		`+ resource ["a" "d"]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong diff\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	changed := d.Attributes[1]
+	if got, want := changed.Old.Expr.Range().Start.Line, 5; got != want {
+		t.Errorf("wrong line for old changed attribute %d; want %d", got, want)
+	}
+	if got, want := changed.New.Expr.Range().Start.Line, 2; got != want {
+		t.Errorf("wrong line for new changed attribute %d; want %d", got, want)
+	}
+
+	same, diags := hcl.DiffBodies(oldFile.Body, oldFile.Body, nil, nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if !same.Empty() {
+		t.Errorf("diff of body with itself is not empty: %#v", same)
+	}
+}
+
+func TestBodyEnclosingBlocks(t *testing.T) {
+	src := `
+top = 1
+resource "aws_instance" "web" {
+  ami = "a"
+  lifecycle {
+    ignore_changes = []
+  }
+}
+provider "aws" {
+  region = "x"
+}
+`
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	body := f.Body.(*Body)
+	resource := body.Blocks[0]
+	lifecycle := resource.Body.Blocks[0]
+
+	breadcrumb := func(blocks []*Block) string {
+		var parts []string
+		for _, block := range blocks {
+			part := block.Type
+			for _, label := range block.Labels {
+				part += fmt.Sprintf(" %q", label)
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, " > ")
+	}
+
+	tests := map[string]struct {
+		rng  hcl.Range
+		want string
+	}{
+		"top-level attribute": {
+			body.Attributes["top"].SrcRange,
+			"",
+		},
+		"attribute in block": {
+			resource.Body.Attributes["ami"].Expr.Range(),
+			`resource "aws_instance" "web"`,
+		},
+		"attribute in nested block": {
+			lifecycle.Body.Attributes["ignore_changes"].NameRange,
+			`resource "aws_instance" "web" > lifecycle`,
+		},
+		"nested block header": {
+			lifecycle.TypeRange,
+			`resource "aws_instance" "web" > lifecycle`,
+		},
+		"other block": {
+			body.Blocks[1].Body.Attributes["region"].SrcRange,
+			`provider "aws"`,
+		},
+		"spanning blocks": {
+			hcl.RangeBetween(resource.TypeRange, body.Blocks[1].TypeRange),
+			"",
+		},
+		"other file": {
+			hcl.Range{
+				Filename: "other.hcl",
+				Start:    lifecycle.TypeRange.Start,
+				End:      lifecycle.TypeRange.End,
+			},
+			"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := breadcrumb(body.EnclosingBlocks(test.rng)); got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyContentCaseInsensitive(t *testing.T) {
+	src := `
+Region = "us-east-1"
+zone   = "a"
+Resource "foo" {}
+resource "bar" {}
+`
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "region", Required: true},
+			{Name: "zone"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "resource", LabelNames: []string{"name"}},
+		},
+	}
+
+	// Without the option, the non-canonical names don't match.
+	_, diags = f.Body.Content(schema)
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success with case-sensitive schema")
+	}
+
+	schema.CaseInsensitive = true
+	content, diags := f.Body.Content(schema)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+	if len(diags) != 2 {
+		t.Errorf("wrong number of diagnostics %d; want 2\n%s", len(diags), diags.Error())
+	}
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagWarning {
+			t.Errorf("unexpected diagnostic severity for %q", diag.Summary)
+		}
+	}
+
+	if attr, ok := content.Attributes["region"]; !ok {
+		t.Errorf("missing attribute \"region\"")
+	} else if attr.Name != "region" {
+		t.Errorf("wrong attribute name %q; want %q", attr.Name, "region")
+	}
+	if _, ok := content.Attributes["zone"]; !ok {
+		t.Errorf("missing attribute \"zone\"")
+	}
+	if len(content.Blocks) != 2 {
+		t.Fatalf("wrong number of blocks %d; want 2", len(content.Blocks))
+	}
+	for i, block := range content.Blocks {
+		if block.Type != "resource" {
+			t.Errorf("block %d has wrong type %q; want %q", i, block.Type, "resource")
+		}
+	}
+}
+
+func TestBodyDecoder(t *testing.T) {
+	src := `
+item "a" {
+  name = "first"
+  Setting {}
+}
+item "b" {
+  name  = "second"
+  extra = true
+}
+item "c" {
+}
+`
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	root, diags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "item", LabelNames: []string{"name"}}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name", Required: true},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "setting"},
+		},
+		CaseInsensitive: true,
+	}
+	d := hcl.NewBodyDecoder(schema)
+
+	var first *hcl.BodyContent
+	for i, block := range root.Blocks {
+		wantContent, wantDiags := block.Body.Content(schema)
+		gotContent, gotDiags := d.Decode(block.Body)
+		if !reflect.DeepEqual(gotContent, wantContent) {
+			t.Errorf("wrong content for block %d\ngot:  %#v\nwant: %#v", i, gotContent, wantContent)
+		}
+		if got, want := gotDiags.Error(), wantDiags.Error(); got != want {
+			t.Errorf("wrong diagnostics for block %d\ngot:  %s\nwant: %s", i, got, want)
+		}
+
+		wantContent, wantRemain, wantDiags := block.Body.PartialContent(schema)
+		gotContent, gotRemain, gotDiags := d.DecodePartial(block.Body)
+		if !reflect.DeepEqual(gotContent, wantContent) {
+			t.Errorf("wrong partial content for block %d\ngot:  %#v\nwant: %#v", i, gotContent, wantContent)
+		}
+		if !reflect.DeepEqual(gotRemain, wantRemain) {
+			t.Errorf("wrong remaining body for block %d\ngot:  %#v\nwant: %#v", i, gotRemain, wantRemain)
+		}
+		if got, want := gotDiags.Error(), wantDiags.Error(); got != want {
+			t.Errorf("wrong partial diagnostics for block %d\ngot:  %s\nwant: %s", i, got, want)
+		}
+
+		if first == nil {
+			first = gotContent
+		}
+	}
+
+	// Content returned from an earlier call must not be affected by later
+	// calls using the same decoder.
+	if got, want := len(first.Attributes), 1; got != want {
+		t.Errorf("wrong number of attributes in first content %d; want %d", got, want)
+	}
+	if got, want := len(first.Blocks), 1; got != want {
+		t.Errorf("wrong number of blocks in first content %d; want %d", got, want)
+	} else if got, want := first.Blocks[0].Type, "setting"; got != want {
+		t.Errorf("wrong block type in first content %q; want %q", got, want)
+	}
+
+	// A body that doesn't implement hcl.DecoderBody uses its own methods.
+	if _, diags := d.Decode(hcl.EmptyBody()); !diags.HasErrors() {
+		t.Errorf("missing diagnostic for required attribute in empty body")
+	}
+}
+
+func benchmarkBodyContentBodies(b *testing.B) ([]hcl.Body, *hcl.BodySchema) {
+	var buf strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&buf, "item {\n  name = \"item%d\"\n  size = %d\n  tags {}\n}\n", i, i)
+	}
+	f, diags := ParseConfig([]byte(buf.String()), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		b.Fatal(diags.Error())
+	}
+	content, diags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "item"}},
+	})
+	if diags.HasErrors() {
+		b.Fatal(diags.Error())
+	}
+	bodies := make([]hcl.Body, len(content.Blocks))
+	for i, block := range content.Blocks {
+		bodies[i] = block.Body
+	}
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name", Required: true},
+			{Name: "size"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "tags"},
+			{Type: "labels"},
+			{Type: "meta", LabelNames: []string{"key"}},
+		},
+	}
+	return bodies, schema
+}
+
+func BenchmarkBodyContent(b *testing.B) {
+	bodies, schema := benchmarkBodyContentBodies(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, body := range bodies {
+			_, diags := body.Content(schema)
+			if diags.HasErrors() {
+				b.Fatal(diags.Error())
+			}
+		}
+	}
+}
+
+func BenchmarkBodyDecoder(b *testing.B) {
+	bodies, schema := benchmarkBodyContentBodies(b)
+	d := hcl.NewBodyDecoder(schema)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, body := range bodies {
+			_, diags := d.Decode(body)
+			if diags.HasErrors() {
+				b.Fatal(diags.Error())
+			}
+		}
+	}
+}
@@ -67,8 +67,9 @@ const (
 	TokenEllipsis    TokenType = '…'
 	TokenFatArrow    TokenType = '⇒'
 
-	TokenQuestion TokenType = '?'
-	TokenColon    TokenType = ':'
+	TokenQuestion     TokenType = '?'
+	TokenColon        TokenType = ':'
+	TokenNullCoalesce TokenType = '⁇'
 
 	TokenTemplateInterp  TokenType = '∫'
 	TokenTemplateControl TokenType = 'λ'
@@ -76,6 +77,7 @@ const (
 
 	TokenQuotedLit TokenType = 'Q' // might contain backslash escapes
 	TokenStringLit TokenType = 'S' // cannot contain backslash escapes
+	TokenRawLit    TokenType = 'R' // includes the delimiting backticks; see ParseOptions.RawStrings
 	TokenNumberLit TokenType = 'N'
 	TokenIdent     TokenType = 'I'
 
@@ -126,9 +128,22 @@ type tokenAccum struct {
 	Pos       hcl.Pos
 	Tokens    []Token
 	StartByte int
+
+	// Callback, if set, receives each token as it is emitted instead of
+	// the token being appended to Tokens. If Callback returns false then
+	// no further tokens are emitted and the scanner stops at the end of the
+	// current token.
+	Callback func(Token) bool
+	stopped  bool
 }
 
 func (f *tokenAccum) emitToken(ty TokenType, startOfs, endOfs int) {
+	if f.stopped {
+		// The callback asked us to stop, so there's no reason to do the
+		// work of calculating positions for any remaining tokens.
+		return
+	}
+
 	// Walk through our buffer to figure out how much we need to adjust
 	// the start pos to get our end pos.
 
@@ -152,7 +167,7 @@ func (f *tokenAccum) emitToken(ty TokenType, startOfs, endOfs int) {
 
 	f.Pos = end
 
-	f.Tokens = append(f.Tokens, Token{
+	tok := Token{
 		Type:  ty,
 		Bytes: f.Bytes[startOfs:endOfs],
 		Range: hcl.Range{
@@ -160,7 +175,16 @@ func (f *tokenAccum) emitToken(ty TokenType, startOfs, endOfs int) {
 			Start:    start,
 			End:      end,
 		},
-	})
+	}
+
+	if f.Callback != nil {
+		if !f.Callback(tok) {
+			f.stopped = true
+		}
+		return
+	}
+
+	f.Tokens = append(f.Tokens, tok)
 }
 
 type heredocInProgress struct {
@@ -185,144 +209,207 @@ func tokenOpensFlushHeredoc(tok Token) bool {
 // repetition of the same information.
 func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 	var diags hcl.Diagnostics
+	var checker invalidTokenChecker
+	for _, tok := range tokens {
+		diags = append(diags, checker.Check(tok)...)
+	}
+	return diags
+}
 
-	toldBitwise := 0
-	toldExponent := 0
-	toldBacktick := 0
-	toldApostrophe := 0
-	toldSemicolon := 0
-	toldTabs := 0
-	toldBadUTF8 := 0
+// invalidTokenChecker is the stateful implementation of checkInvalidTokens,
+// which can also be used to check tokens one at a time as they are produced
+// by the scanner. The zero value is ready to use.
+type invalidTokenChecker struct {
+	toldBitwise    int
+	toldExponent   int
+	toldBacktick   int
+	toldApostrophe int
+	toldSemicolon  int
+	toldTabs       int
+	toldBadUTF8    int
+}
 
-	for _, tok := range tokens {
-		tokRange := func() *hcl.Range {
-			r := tok.Range
-			return &r
-		}
+// Check returns any diagnostics for the given token, taking into account
+// the tokens previously passed to Check on the same checker.
+func (c *invalidTokenChecker) Check(tok Token) hcl.Diagnostics {
+	var diags hcl.Diagnostics
 
-		switch tok.Type {
-		case TokenBitwiseAnd, TokenBitwiseOr, TokenBitwiseXor, TokenBitwiseNot:
-			if toldBitwise < 4 {
-				var suggestion string
-				switch tok.Type {
-				case TokenBitwiseAnd:
-					suggestion = " Did you mean boolean AND (\"&&\")?"
-				case TokenBitwiseOr:
-					suggestion = " Did you mean boolean OR (\"||\")?"
-				case TokenBitwiseNot:
-					suggestion = " Did you mean boolean NOT (\"!\")?"
-				}
-
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Unsupported operator",
-					Detail:   fmt.Sprintf("Bitwise operators are not supported.%s", suggestion),
-					Subject:  tokRange(),
-				})
-				toldBitwise++
-			}
-		case TokenStarStar:
-			if toldExponent < 1 {
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Unsupported operator",
-					Detail:   "\"**\" is not a supported operator. Exponentiation is not supported as an operator.",
-					Subject:  tokRange(),
-				})
-
-				toldExponent++
-			}
-		case TokenBacktick:
-			// Only report for alternating (even) backticks, so we won't report both start and ends of the same
-			// backtick-quoted string.
-			if (toldBacktick % 2) == 0 {
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character",
-					Detail:   "The \"`\" character is not valid. To create a multi-line string, use the \"heredoc\" syntax, like \"<<EOT\".",
-					Subject:  tokRange(),
-				})
-			}
-			if toldBacktick <= 2 {
-				toldBacktick++
-			}
-		case TokenApostrophe:
-			if (toldApostrophe % 2) == 0 {
-				newDiag := &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character",
-					Detail:   "Single quotes are not valid. Use double quotes (\") to enclose strings.",
-					Subject:  tokRange(),
-				}
-				diags = append(diags, newDiag)
-			}
-			if toldApostrophe <= 2 {
-				toldApostrophe++
-			}
-		case TokenSemicolon:
-			if toldSemicolon < 1 {
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character",
-					Detail:   "The \";\" character is not valid. Use newlines to separate arguments and blocks, and commas to separate items in collection values.",
-					Subject:  tokRange(),
-				})
-
-				toldSemicolon++
-			}
-		case TokenTabs:
-			if toldTabs < 1 {
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character",
-					Detail:   "Tab characters may not be used. The recommended indentation style is two spaces per indent.",
-					Subject:  tokRange(),
-				})
-
-				toldTabs++
-			}
-		case TokenBadUTF8:
-			if toldBadUTF8 < 1 {
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character encoding",
-					Detail:   "All input files must be UTF-8 encoded. Ensure that UTF-8 encoding is selected in your editor.",
-					Subject:  tokRange(),
-				})
-
-				toldBadUTF8++
+	tokRange := func() *hcl.Range {
+		r := tok.Range
+		return &r
+	}
+
+	switch tok.Type {
+	case TokenBitwiseAnd, TokenBitwiseOr, TokenBitwiseXor, TokenBitwiseNot:
+		if c.toldBitwise < 4 {
+			var suggestion string
+			switch tok.Type {
+			case TokenBitwiseAnd:
+				suggestion = " Did you mean boolean AND (\"&&\")?"
+			case TokenBitwiseOr:
+				suggestion = " Did you mean boolean OR (\"||\")?"
+			case TokenBitwiseNot:
+				suggestion = " Did you mean boolean NOT (\"!\")?"
 			}
-		case TokenQuotedNewline:
+
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported operator",
+				Detail:   fmt.Sprintf("Bitwise operators are not supported.%s", suggestion),
+				Subject:  tokRange(),
+			})
+			c.toldBitwise++
+		}
+	case TokenStarStar:
+		if c.toldExponent < 1 {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
-				Summary:  "Invalid multi-line string",
-				Detail:   "Quoted strings may not be split over multiple lines. To produce a multi-line string, either use the \\n escape to represent a newline character or use the \"heredoc\" multi-line template syntax.",
+				Summary:  "Unsupported operator",
+				Detail:   "\"**\" is not a supported operator. Exponentiation is not supported as an operator.",
 				Subject:  tokRange(),
 			})
-		case TokenInvalid:
-			chars := string(tok.Bytes)
-			switch chars {
-			case "“", "”":
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character",
-					Detail:   "\"Curly quotes\" are not valid here. These can sometimes be inadvertently introduced when sharing code via documents or discussion forums. It might help to replace the character with a \"straight quote\".",
-					Subject:  tokRange(),
-				})
-			default:
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid character",
-					Detail:   "This character is not used within the language.",
-					Subject:  tokRange(),
-				})
+
+			c.toldExponent++
+		}
+	case TokenBacktick:
+		// Only report for alternating (even) backticks, so we won't report both start and ends of the same
+		// backtick-quoted string.
+		if (c.toldBacktick % 2) == 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character",
+				Detail:   "The \"`\" character is not valid. To create a multi-line string, use the \"heredoc\" syntax, like \"<<EOT\".",
+				Subject:  tokRange(),
+			})
+		}
+		if c.toldBacktick <= 2 {
+			c.toldBacktick++
+		}
+	case TokenRawLit:
+		if len(tok.Bytes) < 2 || tok.Bytes[len(tok.Bytes)-1] != '`' {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unterminated raw string",
+				Detail:   "There is no closing backtick (\"`\") for this raw string before the end of the file.",
+				Subject: &hcl.Range{
+					Filename: tok.Range.Filename,
+					Start:    tok.Range.Start,
+					End:      hcl.Pos{Line: tok.Range.Start.Line, Column: tok.Range.Start.Column + 1, Byte: tok.Range.Start.Byte + 1},
+				},
+			})
+		}
+	case TokenApostrophe:
+		if (c.toldApostrophe % 2) == 0 {
+			newDiag := &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character",
+				Detail:   "Single quotes are not valid. Use double quotes (\") to enclose strings.",
+				Subject:  tokRange(),
 			}
+			diags = append(diags, newDiag)
+		}
+		if c.toldApostrophe <= 2 {
+			c.toldApostrophe++
+		}
+	case TokenSemicolon:
+		if c.toldSemicolon < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character",
+				Detail:   "The \";\" character is not valid. Use newlines to separate arguments and blocks, and commas to separate items in collection values.",
+				Subject:  tokRange(),
+			})
+
+			c.toldSemicolon++
+		}
+	case TokenTabs:
+		if c.toldTabs < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character",
+				Detail:   "Tab characters may not be used. The recommended indentation style is two spaces per indent.",
+				Subject:  tokRange(),
+			})
+
+			c.toldTabs++
+		}
+	case TokenBadUTF8:
+		if c.toldBadUTF8 < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character encoding",
+				Detail:   "All input files must be UTF-8 encoded. Ensure that UTF-8 encoding is selected in your editor.",
+				Subject:  tokRange(),
+				Extra: &invalidUTF8DiagExtra{
+					offset: tok.Range.Start.Byte,
+					bytes:  tok.Bytes,
+				},
+			})
+
+			c.toldBadUTF8++
+		}
+	case TokenQuotedNewline:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid multi-line string",
+			Detail:   "Quoted strings may not be split over multiple lines. To produce a multi-line string, either use the \\n escape to represent a newline character or use the \"heredoc\" multi-line template syntax.",
+			Subject:  tokRange(),
+		})
+	case TokenInvalid:
+		chars := string(tok.Bytes)
+		switch chars {
+		case "“", "”":
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character",
+				Detail:   "\"Curly quotes\" are not valid here. These can sometimes be inadvertently introduced when sharing code via documents or discussion forums. It might help to replace the character with a \"straight quote\".",
+				Subject:  tokRange(),
+			})
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid character",
+				Detail:   "This character is not used within the language.",
+				Subject:  tokRange(),
+			})
 		}
 	}
 	return diags
 }
 
-var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+// InvalidUTF8DiagExtra is an interface implemented by the value in the
+// "Extra" field of the diagnostic returned when the input is not valid UTF-8,
+// giving cooperating callers access to the location of the first invalid
+// byte sequence so that they can detect this situation programmatically.
+type InvalidUTF8DiagExtra interface {
+	// InvalidUTF8Offset returns the byte offset of the start of the first
+	// invalid byte sequence, in the same terms as hcl.Pos.Byte.
+	InvalidUTF8Offset() int
+
+	// InvalidUTF8Bytes returns the invalid byte sequence itself. A run of
+	// several invalid bytes may be reported as only its first byte.
+	InvalidUTF8Bytes() []byte
+}
+
+type invalidUTF8DiagExtra struct {
+	offset int
+	bytes  []byte
+}
+
+func (e *invalidUTF8DiagExtra) InvalidUTF8Offset() int {
+	return e.offset
+}
+
+func (e *invalidUTF8DiagExtra) InvalidUTF8Bytes() []byte {
+	return e.bytes
+}
+
+// UTF8BOM is the UTF-8 encoding of the byte order mark that the scanner
+// skips at the start of its input, for use by other packages that must
+// treat it in the same way.
+const UTF8BOM = "\xef\xbb\xbf"
+
+var utf8BOM = []byte(UTF8BOM)
 
 // stripUTF8BOM checks whether the given buffer begins with a UTF-8 byte order
 // mark (0xEF 0xBB 0xBF) and, if so, returns a truncated slice with the same
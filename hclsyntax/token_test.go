@@ -4,6 +4,7 @@
 package hclsyntax
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -61,3 +62,22 @@ func TestCheckInvalidTokensTest(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckInvalidTokensBadUTF8Extra(t *testing.T) {
+	src := []byte("foo = \"ok\"\nbar = \"\xff\xfe\"\n")
+	_, diags := LexConfig(src, "", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+
+	extra, ok := hcl.DiagnosticExtra[InvalidUTF8DiagExtra](diags[0])
+	if !ok {
+		t.Fatalf("diagnostic has no InvalidUTF8DiagExtra; got %#v", diags[0].Extra)
+	}
+	if got, want := extra.InvalidUTF8Offset(), 18; got != want {
+		t.Errorf("wrong offset %d; want %d", got, want)
+	}
+	if got, want := extra.InvalidUTF8Bytes(), []byte{0xff}; !bytes.Equal(got, want) {
+		t.Errorf("wrong bytes %#v; want %#v", got, want)
+	}
+}
@@ -5,7 +5,7 @@ package hclsyntax
 import "strconv"
 
 func _() {
-	// An "invalid array index" compiler error signifies that the constant values (55) have changed.
+	// An "invalid array index" compiler error signifies that the constant values (56) have changed.
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[TokenOBrace-123]
@@ -40,11 +40,13 @@ func _() {
 	_ = x[TokenFatArrow-8658]
 	_ = x[TokenQuestion-63]
 	_ = x[TokenColon-58]
+	_ = x[TokenNullCoalesce-8263]
 	_ = x[TokenTemplateInterp-8747]
 	_ = x[TokenTemplateControl-955]
 	_ = x[TokenTemplateSeqEnd-8718]
 	_ = x[TokenQuotedLit-81]
 	_ = x[TokenStringLit-83]
+	_ = x[TokenRawLit-82]
 	_ = x[TokenNumberLit-78]
 	_ = x[TokenIdent-73]
 	_ = x[TokenComment-67]
@@ -65,7 +67,7 @@ func _() {
 	_ = x[TokenNil-0]
 }
 
-const _TokenType_name = "TokenNilTokenNewlineTokenBangTokenPercentTokenBitwiseAndTokenApostropheTokenOParenTokenCParenTokenStarTokenPlusTokenCommaTokenMinusTokenDotTokenSlashTokenColonTokenSemicolonTokenLessThanTokenEqualTokenGreaterThanTokenQuestionTokenCommentTokenOHeredocTokenIdentTokenNumberLitTokenQuotedLitTokenStringLitTokenOBrackTokenCBrackTokenBitwiseXorTokenBacktickTokenCHeredocTokenOBraceTokenBitwiseOrTokenCBraceTokenBitwiseNotTokenOQuoteTokenCQuoteTokenTemplateControlTokenEllipsisTokenFatArrowTokenTemplateSeqEndTokenAndTokenOrTokenTemplateInterpTokenEqualOpTokenNotEqualTokenLessThanEqTokenGreaterThanEqTokenEOFTokenTabsTokenQuotedNewlineTokenStarStarTokenDoubleColonTokenInvalidTokenBadUTF8"
+const _TokenType_name = "TokenNilTokenNewlineTokenBangTokenPercentTokenBitwiseAndTokenApostropheTokenOParenTokenCParenTokenStarTokenPlusTokenCommaTokenMinusTokenDotTokenSlashTokenColonTokenSemicolonTokenLessThanTokenEqualTokenGreaterThanTokenQuestionTokenCommentTokenOHeredocTokenIdentTokenNumberLitTokenQuotedLitTokenRawLitTokenStringLitTokenOBrackTokenCBrackTokenBitwiseXorTokenBacktickTokenCHeredocTokenOBraceTokenBitwiseOrTokenCBraceTokenBitwiseNotTokenOQuoteTokenCQuoteTokenTemplateControlTokenEllipsisTokenNullCoalesceTokenFatArrowTokenTemplateSeqEndTokenAndTokenOrTokenTemplateInterpTokenEqualOpTokenNotEqualTokenLessThanEqTokenGreaterThanEqTokenEOFTokenTabsTokenQuotedNewlineTokenStarStarTokenDoubleColonTokenInvalidTokenBadUTF8"
 
 var _TokenType_map = map[TokenType]string{
 	0:      _TokenType_name[0:8],
@@ -93,36 +95,38 @@ var _TokenType_map = map[TokenType]string{
 	73:     _TokenType_name[250:260],
 	78:     _TokenType_name[260:274],
 	81:     _TokenType_name[274:288],
-	83:     _TokenType_name[288:302],
-	91:     _TokenType_name[302:313],
-	93:     _TokenType_name[313:324],
-	94:     _TokenType_name[324:339],
-	96:     _TokenType_name[339:352],
-	104:    _TokenType_name[352:365],
-	123:    _TokenType_name[365:376],
-	124:    _TokenType_name[376:390],
-	125:    _TokenType_name[390:401],
-	126:    _TokenType_name[401:416],
-	171:    _TokenType_name[416:427],
-	187:    _TokenType_name[427:438],
-	955:    _TokenType_name[438:458],
-	8230:   _TokenType_name[458:471],
-	8658:   _TokenType_name[471:484],
-	8718:   _TokenType_name[484:503],
-	8743:   _TokenType_name[503:511],
-	8744:   _TokenType_name[511:518],
-	8747:   _TokenType_name[518:537],
-	8788:   _TokenType_name[537:549],
-	8800:   _TokenType_name[549:562],
-	8804:   _TokenType_name[562:577],
-	8805:   _TokenType_name[577:595],
-	9220:   _TokenType_name[595:603],
-	9225:   _TokenType_name[603:612],
-	9252:   _TokenType_name[612:630],
-	10138:  _TokenType_name[630:643],
-	11820:  _TokenType_name[643:659],
-	65533:  _TokenType_name[659:671],
-	128169: _TokenType_name[671:683],
+	82:     _TokenType_name[288:299],
+	83:     _TokenType_name[299:313],
+	91:     _TokenType_name[313:324],
+	93:     _TokenType_name[324:335],
+	94:     _TokenType_name[335:350],
+	96:     _TokenType_name[350:363],
+	104:    _TokenType_name[363:376],
+	123:    _TokenType_name[376:387],
+	124:    _TokenType_name[387:401],
+	125:    _TokenType_name[401:412],
+	126:    _TokenType_name[412:427],
+	171:    _TokenType_name[427:438],
+	187:    _TokenType_name[438:449],
+	955:    _TokenType_name[449:469],
+	8230:   _TokenType_name[469:482],
+	8263:   _TokenType_name[482:499],
+	8658:   _TokenType_name[499:512],
+	8718:   _TokenType_name[512:531],
+	8743:   _TokenType_name[531:539],
+	8744:   _TokenType_name[539:546],
+	8747:   _TokenType_name[546:565],
+	8788:   _TokenType_name[565:577],
+	8800:   _TokenType_name[577:590],
+	8804:   _TokenType_name[590:605],
+	8805:   _TokenType_name[605:623],
+	9220:   _TokenType_name[623:631],
+	9225:   _TokenType_name[631:640],
+	9252:   _TokenType_name[640:658],
+	10138:  _TokenType_name[658:671],
+	11820:  _TokenType_name[671:687],
+	65533:  _TokenType_name[687:699],
+	128169: _TokenType_name[699:711],
 }
 
 func (i TokenType) String() string {
@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// traceValue evaluates the given expression using the given implementation
+// of its Value method, notifying the given tracer before and after.
+//
+// The Value method of each expression calls this only when the EvalContext
+// has a tracer, so that evaluation without a tracer pays only for checking
+// whether there is one.
+func traceValue(tracer hcl.EvalTracer, expr Expression, ctx *hcl.EvalContext, value func(*hcl.EvalContext) (cty.Value, hcl.Diagnostics)) (cty.Value, hcl.Diagnostics) {
+	tracer.EnterExpression(expr, ctx)
+	val, diags := value(ctx)
+	tracer.LeaveExpression(expr, ctx, val, diags)
+	return val, diags
+}
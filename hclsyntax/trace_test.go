@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestValueTracer(t *testing.T) {
+	src := `a ?? upper(b) == "X"`
+	expr, diags := ParseExpression([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	tracer := &testTracer{src: src}
+	ctx := (&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.NullVal(cty.Bool),
+			"b": cty.StringVal("x"),
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+		},
+	}).WithTracer(tracer)
+
+	got, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if !got.RawEquals(cty.True) {
+		t.Errorf("wrong result %#v; want %#v", got, cty.True)
+	}
+
+	want := []string{
+		`enter a ?? upper(b) == "X"`,
+		`enter a`,
+		`leave a = cty.NullVal(cty.Bool)`,
+		`enter upper(b) == "X"`,
+		`enter upper(b)`,
+		`enter b`,
+		`leave b = cty.StringVal("x")`,
+		`leave upper(b) = cty.StringVal("X")`,
+		`enter "X"`,
+		`enter X`,
+		`leave X = cty.StringVal("X")`,
+		`leave "X" = cty.StringVal("X")`,
+		`leave upper(b) == "X" = cty.True`,
+		`leave a ?? upper(b) == "X" = cty.True`,
+	}
+	if diff := cmp.Diff(want, tracer.events); diff != "" {
+		t.Errorf("wrong events\n%s", diff)
+	}
+
+	// Evaluating with the parent of the traced context must not notify
+	// the tracer.
+	tracer.events = nil
+	if _, diags := expr.Value(ctx.Parent()); diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if len(tracer.events) != 0 {
+		t.Errorf("tracer was notified without being attached: %#v", tracer.events)
+	}
+}
+
+func TestValueTracerDiagnostics(t *testing.T) {
+	src := `[for v in items : v.x]`
+	expr, diags := ParseExpression([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	tracer := &testTracer{src: src}
+	ctx := (&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"items": cty.TupleVal([]cty.Value{cty.True}),
+		},
+	}).WithTracer(tracer)
+
+	_, diags = expr.Value(ctx)
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success")
+	}
+
+	// The value expression is evaluated in a child of the given context,
+	// which must inherit the tracer.
+	want := []string{
+		`enter [for v in items : v.x]`,
+		`enter items`,
+		`leave items = cty.TupleVal([]cty.Value{cty.True})`,
+		`enter v.x`,
+		`leave v.x = cty.DynamicVal (1 errors)`,
+		`leave [for v in items : v.x] = cty.TupleVal([]cty.Value{cty.DynamicVal}) (1 errors)`,
+	}
+	if diff := cmp.Diff(want, tracer.events); diff != "" {
+		t.Errorf("wrong events\n%s", diff)
+	}
+}
+
+type testTracer struct {
+	src    string
+	events []string
+}
+
+func (t *testTracer) EnterExpression(expr hcl.Expression, ctx *hcl.EvalContext) {
+	t.events = append(t.events, "enter "+t.exprSrc(expr))
+}
+
+func (t *testTracer) LeaveExpression(expr hcl.Expression, ctx *hcl.EvalContext, val cty.Value, diags hcl.Diagnostics) {
+	event := fmt.Sprintf("leave %s = %#v", t.exprSrc(expr), val)
+	if diags.HasErrors() {
+		event += fmt.Sprintf(" (%d errors)", len(diags))
+	}
+	t.events = append(t.events, event)
+}
+
+func (t *testTracer) exprSrc(expr hcl.Expression) string {
+	rng := expr.Range()
+	return t.src[rng.Start.Byte:rng.End.Byte]
+}
@@ -0,0 +1,309 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// TransformTraversals returns an expression equivalent to the given
+// expression but with each of its traversals replaced by the result of
+// calling the given function with that traversal. This can be used to
+// implement refactoring such as renaming a variable, so that the result can
+// then be evaluated as normal.
+//
+// The function is called with the absolute traversal of each
+// ScopeTraversalExpr that refers to a variable, as would be returned by
+// Variables, and with the relative traversal of each RelativeTraversalExpr,
+// which it can distinguish using the traversal's IsRelative method. It is
+// not called for references to the symbols declared by a "for" expression,
+// nor for a naked identifier used as an object key. The function must
+// return the traversal unchanged if it doesn't wish to replace it, and must
+// not modify the traversal it is given. An absolute traversal must be
+// replaced by another absolute traversal, and a relative traversal by
+// another relative traversal.
+//
+// The given expression is not modified. Any nodes that contain replaced
+// traversals are copied, and any other nodes are shared between the given
+// and returned expressions. The source ranges of the copied nodes are not
+// changed, and so they may not correspond to the replacement traversals.
+func TransformTraversals(expr Expression, fn func(hcl.Traversal) hcl.Traversal) Expression {
+	t := &traversalTransformer{
+		fn: fn,
+	}
+	ret, _ := t.transform(expr)
+	return ret
+}
+
+type traversalTransformer struct {
+	fn     func(hcl.Traversal) hcl.Traversal
+	locals []map[string]struct{}
+}
+
+// transform returns the transformed version of the given expression, along
+// with true if it differs from the given expression.
+func (t *traversalTransformer) transform(expr Expression) (Expression, bool) {
+	switch e := expr.(type) {
+	case *ScopeTraversalExpr:
+		if t.isLocal(e.Traversal.RootName()) {
+			return expr, false
+		}
+		traversal, changed := t.traversal(e.Traversal)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Traversal = traversal
+		return &ne, true
+	case *RelativeTraversalExpr:
+		source, sourceChanged := t.transform(e.Source)
+		traversal, changed := t.traversal(e.Traversal)
+		if !sourceChanged && !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Source = source
+		ne.Traversal = traversal
+		return &ne, true
+	case *BinaryOpExpr:
+		lhs, lhsChanged := t.transform(e.LHS)
+		rhs, rhsChanged := t.transform(e.RHS)
+		if !lhsChanged && !rhsChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.LHS = lhs
+		ne.RHS = rhs
+		return &ne, true
+	case *CoalesceExpr:
+		lhs, lhsChanged := t.transform(e.LHS)
+		rhs, rhsChanged := t.transform(e.RHS)
+		if !lhsChanged && !rhsChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.LHS = lhs
+		ne.RHS = rhs
+		return &ne, true
+	case *UnaryOpExpr:
+		val, changed := t.transform(e.Val)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Val = val
+		return &ne, true
+	case *ConditionalExpr:
+		cond, condChanged := t.transform(e.Condition)
+		trueResult, trueChanged := t.transform(e.TrueResult)
+		falseResult, falseChanged := t.transform(e.FalseResult)
+		if !condChanged && !trueChanged && !falseChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.Condition = cond
+		ne.TrueResult = trueResult
+		ne.FalseResult = falseResult
+		return &ne, true
+	case *FunctionCallExpr:
+		args, changed := t.transformAll(e.Args)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Args = args
+		return &ne, true
+	case *TupleConsExpr:
+		exprs, changed := t.transformAll(e.Exprs)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Exprs = exprs
+		return &ne, true
+	case *ObjectConsExpr:
+		items := make([]ObjectConsItem, len(e.Items))
+		anyChanged := false
+		for i, item := range e.Items {
+			key, keyChanged := t.transform(item.KeyExpr)
+			val, valChanged := t.transform(item.ValueExpr)
+			items[i] = ObjectConsItem{
+				KeyExpr:   key,
+				ValueExpr: val,
+			}
+			anyChanged = anyChanged || keyChanged || valChanged
+		}
+		if !anyChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.Items = items
+		return &ne, true
+	case *ObjectConsKeyExpr:
+		// A naked identifier is interpreted as a literal string rather than
+		// as a reference, and so must not be transformed.
+		if e.literalName() != "" {
+			return expr, false
+		}
+		wrapped, changed := t.transform(e.Wrapped)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Wrapped = wrapped
+		return &ne, true
+	case *IndexExpr:
+		coll, collChanged := t.transform(e.Collection)
+		key, keyChanged := t.transform(e.Key)
+		if !collChanged && !keyChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.Collection = coll
+		ne.Key = key
+		return &ne, true
+	case *SplatExpr:
+		source, sourceChanged := t.transform(e.Source)
+		each, eachChanged := t.transform(e.Each)
+		if !sourceChanged && !eachChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.Source = source
+		ne.Each = each
+		return &ne, true
+	case *ForExpr:
+		coll, collChanged := t.transform(e.CollExpr)
+
+		// The other expressions are evaluated with the iterator symbols
+		// in scope, which shadow any variables of the same names.
+		locals := map[string]struct{}{
+			e.ValVar: {},
+		}
+		if e.KeyVar != "" {
+			locals[e.KeyVar] = struct{}{}
+		}
+		t.locals = append(t.locals, locals)
+		key, keyChanged := t.transformOptional(e.KeyExpr)
+		val, valChanged := t.transform(e.ValExpr)
+		cond, condChanged := t.transformOptional(e.CondExpr)
+		t.locals = t.locals[:len(t.locals)-1]
+
+		if !collChanged && !keyChanged && !valChanged && !condChanged {
+			return expr, false
+		}
+		ne := *e
+		ne.CollExpr = coll
+		ne.KeyExpr = key
+		ne.ValExpr = val
+		ne.CondExpr = cond
+		return &ne, true
+	case *TemplateExpr:
+		parts, changed := t.transformAll(e.Parts)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Parts = parts
+		return &ne, true
+	case *TemplateWrapExpr:
+		wrapped, changed := t.transform(e.Wrapped)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Wrapped = wrapped
+		return &ne, true
+	case *TemplateJoinExpr:
+		tuple, changed := t.transform(e.Tuple)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Tuple = tuple
+		return &ne, true
+	case *ParenthesesExpr:
+		inner, changed := t.transform(e.Expression)
+		if !changed {
+			return expr, false
+		}
+		ne := *e
+		ne.Expression = inner
+		return &ne, true
+	default:
+		return expr, false
+	}
+}
+
+func (t *traversalTransformer) transformAll(exprs []Expression) ([]Expression, bool) {
+	if exprs == nil {
+		return nil, false
+	}
+	ret := make([]Expression, len(exprs))
+	anyChanged := false
+	for i, expr := range exprs {
+		var changed bool
+		ret[i], changed = t.transform(expr)
+		anyChanged = anyChanged || changed
+	}
+	return ret, anyChanged
+}
+
+func (t *traversalTransformer) transformOptional(expr Expression) (Expression, bool) {
+	if expr == nil {
+		return nil, false
+	}
+	return t.transform(expr)
+}
+
+func (t *traversalTransformer) traversal(traversal hcl.Traversal) (hcl.Traversal, bool) {
+	ret := t.fn(traversal)
+	return ret, !traversalsEqual(ret, traversal)
+}
+
+func (t *traversalTransformer) isLocal(name string) bool {
+	for _, locals := range t.locals {
+		if _, ok := locals[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// traversalsEqual returns true if the given traversals have the same steps,
+// including their source ranges.
+func traversalsEqual(a, b hcl.Traversal) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		switch as := a[i].(type) {
+		case hcl.TraverseRoot:
+			bs, ok := b[i].(hcl.TraverseRoot)
+			if !ok || as != bs {
+				return false
+			}
+		case hcl.TraverseAttr:
+			bs, ok := b[i].(hcl.TraverseAttr)
+			if !ok || as != bs {
+				return false
+			}
+		case hcl.TraverseIndex:
+			bs, ok := b[i].(hcl.TraverseIndex)
+			if !ok || as.SrcRange != bs.SrcRange || !as.Key.RawEquals(bs.Key) {
+				return false
+			}
+		case hcl.TraverseSplat:
+			bs, ok := b[i].(hcl.TraverseSplat)
+			if !ok || as.SrcRange != bs.SrcRange {
+				return false
+			}
+		default:
+			// We can't compare other step types, so we'll conservatively
+			// assume that they differ.
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTransformTraversals(t *testing.T) {
+	// The transform renames data.old_type to data.new_type, and any
+	// relative attribute "old_attr" to "new_attr".
+	rename := func(traversal hcl.Traversal) hcl.Traversal {
+		if traversal.IsRelative() {
+			if len(traversal) > 0 {
+				if attr, ok := traversal[0].(hcl.TraverseAttr); ok && attr.Name == "old_attr" {
+					ret := make(hcl.Traversal, len(traversal))
+					copy(ret, traversal)
+					ret[0] = hcl.TraverseAttr{Name: "new_attr", SrcRange: attr.SrcRange}
+					return ret
+				}
+			}
+			return traversal
+		}
+		if len(traversal) < 2 || traversal.RootName() != "data" {
+			return traversal
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok && attr.Name == "old_type" {
+			ret := make(hcl.Traversal, len(traversal))
+			copy(ret, traversal)
+			ret[1] = hcl.TraverseAttr{Name: "new_type", SrcRange: attr.SrcRange}
+			return ret
+		}
+		return traversal
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"data": cty.ObjectVal(map[string]cty.Value{
+				"new_type": cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ObjectVal(map[string]cty.Value{
+						"id": cty.StringVal("new-a"),
+					}),
+				}),
+				"other": cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("other"),
+				}),
+			}),
+			"list": cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"new_attr": cty.StringVal("x"),
+				}),
+			}),
+		},
+	}
+
+	tests := []struct {
+		src      string
+		wantVars []string
+		wantVal  cty.Value
+	}{
+		{
+			`data.old_type.a.id`,
+			[]string{"data.new_type.a.id"},
+			cty.StringVal("new-a"),
+		},
+		{
+			`"${data.old_type.a.id}-${data.other.id}"`,
+			[]string{"data.new_type.a.id", "data.other.id"},
+			cty.StringVal("new-a-other"),
+		},
+		{
+			`{ (data.old_type.a.id) = data.old_type["a"] }.new-a.id`,
+			[]string{"data.new_type.a.id", `data.new_type["a"]`},
+			cty.StringVal("new-a"),
+		},
+		{
+			`[for data in [data.old_type.a]: data.id]`,
+			[]string{"data.new_type.a"},
+			cty.TupleVal([]cty.Value{cty.StringVal("new-a")}),
+		},
+		{
+			// The iterator symbol shadows the variable, so isn't renamed.
+			`[for data in [{ old_type = "local" }]: data.old_type]`,
+			nil,
+			cty.TupleVal([]cty.Value{cty.StringVal("local")}),
+		},
+		{
+			`true ? (list[0]).old_attr : data.old_type.a.id`,
+			[]string{"list[0]", "data.new_type.a.id"},
+			cty.StringVal("x"),
+		},
+		{
+			`list[*].old_attr`,
+			[]string{"list"},
+			cty.TupleVal([]cty.Value{cty.StringVal("x")}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			before := DumpAST(expr)
+
+			got := TransformTraversals(expr, rename)
+			if after := DumpAST(expr); after != before {
+				t.Errorf("original expression was modified\nbefore:\n%s\nafter:\n%s", before, after)
+			}
+
+			var gotVars []string
+			for _, traversal := range got.Variables() {
+				gotVars = append(gotVars, dumpTraversal(traversal))
+			}
+			if diff := cmp.Diff(test.wantVars, gotVars); diff != "" {
+				t.Errorf("wrong variables\n%s", diff)
+			}
+
+			val, diags := got.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors evaluating result: %s", diags.Error())
+			}
+			if !val.RawEquals(test.wantVal) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", val, test.wantVal)
+			}
+		})
+	}
+}
+
+func TestTransformTraversalsUnchanged(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`[foo.bar, { baz = upper(foo) }]`), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	calls := 0
+	got := TransformTraversals(expr, func(traversal hcl.Traversal) hcl.Traversal {
+		calls++
+		return traversal
+	})
+	if got != expr {
+		t.Errorf("expression was copied even though no traversals were replaced")
+	}
+	if calls != 2 {
+		t.Errorf("function called %d times; want 2", calls)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// UnknownSource describes a position within a value that is unknown, along
+// with the variable references that caused it to be unknown.
+type UnknownSource struct {
+	// Path is the path to the unknown value within the overall result. Any
+	// value nested inside a set is reported at the path of the set, since
+	// set elements cannot be addressed by a path.
+	Path cty.Path
+
+	// Traversals are the references in the expression that may have
+	// contributed the unknown value. This may be empty if the value is
+	// unknown for some reason other than a reference, such as the result of
+	// a function that returns unknown values itself.
+	Traversals []hcl.Traversal
+}
+
+// ValueWithUnknownSources evaluates the given expression in the given
+// context, as with its Value method, then also returns a description of
+// each position within the result that is unknown and of the references
+// responsible for it.
+//
+// The sources are found by following the structure of the expression
+// alongside the structure of its result. Tuple and object constructors,
+// parentheses, template wrappers and conditional expressions whose
+// condition is known are followed into the expression that produced each
+// nested value, and a reference is blamed for exactly the parts of its own
+// value that are unknown. For function calls, operators, templates and
+// conditional expressions whose condition is unknown, the references of
+// only those operands whose values aren't wholly known are blamed. For any
+// other kind of expression, such as a 'for' expression or an index, all of
+// the references in the expression are blamed for every unknown value it
+// produced.
+//
+// Working out the sources requires evaluating some nested expressions a
+// second time, and so this is more expensive than calling Value directly.
+// The result is only a best effort for describing the unknown values to an
+// end-user, and must not be relied upon for correctness.
+func ValueWithUnknownSources(expr Expression, ctx *hcl.EvalContext) (cty.Value, []UnknownSource, hcl.Diagnostics) {
+	val, diags := expr.Value(ctx)
+	if val.IsWhollyKnown() {
+		return val, nil, diags
+	}
+
+	f := &unknownSourceFinder{ctx: ctx}
+	unmarked, _ := val.UnmarkDeep()
+	f.find(expr, unmarked, nil)
+	return val, f.sources, diags
+}
+
+type unknownSourceFinder struct {
+	ctx     *hcl.EvalContext
+	sources []UnknownSource
+}
+
+// find records the sources of the unknown values within val, which is the
+// result of evaluating expr and is found at the given path in the overall
+// result.
+func (f *unknownSourceFinder) find(expr Expression, val cty.Value, path cty.Path) {
+	if val.IsWhollyKnown() {
+		return
+	}
+
+	switch e := expr.(type) {
+	case *ParenthesesExpr:
+		f.find(e.Expression, val, path)
+		return
+
+	case *TemplateWrapExpr:
+		f.find(e.Wrapped, val, path)
+		return
+
+	case *ScopeTraversalExpr:
+		f.blame(val, path, []hcl.Traversal{e.Traversal})
+		return
+
+	case *TupleConsExpr:
+		if val.IsKnown() && !val.IsNull() && (val.Type().IsTupleType() || val.Type().IsListType()) && val.LengthInt() == len(e.Exprs) {
+			for i, elemExpr := range e.Exprs {
+				f.find(elemExpr, val.Index(cty.NumberIntVal(int64(i))), copyPath(path).IndexInt(i))
+			}
+			return
+		}
+
+	case *ObjectConsExpr:
+		if val.IsKnown() && !val.IsNull() && (val.Type().IsObjectType() || val.Type().IsMapType()) {
+			for _, item := range e.Items {
+				keyVal, keyDiags := item.KeyExpr.Value(f.ctx)
+				if keyDiags.HasErrors() {
+					continue
+				}
+				keyVal, _ = keyVal.UnmarkDeep()
+				keyVal, err := convert.Convert(keyVal, cty.String)
+				if err != nil || keyVal.IsNull() || !keyVal.IsKnown() {
+					continue
+				}
+				key := keyVal.AsString()
+				if val.Type().IsObjectType() {
+					if val.Type().HasAttribute(key) {
+						f.find(item.ValueExpr, val.GetAttr(key), copyPath(path).GetAttr(key))
+					}
+				} else if val.HasIndex(keyVal).True() {
+					f.find(item.ValueExpr, val.Index(keyVal), copyPath(path).Index(keyVal))
+				}
+			}
+			return
+		}
+
+	case *ConditionalExpr:
+		condVal, condDiags := e.Condition.Value(f.ctx)
+		if !condDiags.HasErrors() {
+			condVal, _ = condVal.UnmarkDeep()
+			condVal, err := convert.Convert(condVal, cty.Bool)
+			if err == nil && !condVal.IsNull() && condVal.IsKnown() {
+				if condVal.True() {
+					f.find(e.TrueResult, val, path)
+				} else {
+					f.find(e.FalseResult, val, path)
+				}
+				return
+			}
+		}
+		f.blame(val, path, f.unknownOperandVariables(e.Condition, e.TrueResult, e.FalseResult))
+		return
+
+	case *FunctionCallExpr:
+		f.blame(val, path, f.unknownOperandVariables(e.Args...))
+		return
+
+	case *TemplateExpr:
+		f.blame(val, path, f.unknownOperandVariables(e.Parts...))
+		return
+
+	case *BinaryOpExpr:
+		f.blame(val, path, f.unknownOperandVariables(e.LHS, e.RHS))
+		return
+
+	case *UnaryOpExpr:
+		f.blame(val, path, f.unknownOperandVariables(e.Val))
+		return
+	}
+
+	f.blame(val, path, expr.Variables())
+}
+
+// blame records the given traversals as the sources of each of the unknown
+// values within val, which is found at the given path.
+func (f *unknownSourceFinder) blame(val cty.Value, path cty.Path, traversals []hcl.Traversal) {
+	switch {
+	case val.IsWhollyKnown():
+		return
+	case !val.IsKnown() || val.IsNull() || val.Type().IsSetType():
+		f.sources = append(f.sources, UnknownSource{
+			Path:       copyPath(path),
+			Traversals: traversals,
+		})
+	case val.Type().IsObjectType():
+		it := val.ElementIterator()
+		for it.Next() {
+			k, v := it.Element()
+			f.blame(v, copyPath(path).GetAttr(k.AsString()), traversals)
+		}
+	case val.CanIterateElements():
+		it := val.ElementIterator()
+		for it.Next() {
+			k, v := it.Element()
+			f.blame(v, copyPath(path).Index(k), traversals)
+		}
+	default:
+		// A known value of a capsule type can't contain unknown values, so
+		// we should not get here.
+		f.sources = append(f.sources, UnknownSource{
+			Path:       copyPath(path),
+			Traversals: traversals,
+		})
+	}
+}
+
+// unknownOperandVariables returns the references in those of the given
+// expressions whose values aren't wholly known.
+func (f *unknownSourceFinder) unknownOperandVariables(exprs ...Expression) []hcl.Traversal {
+	var ret []hcl.Traversal
+	for _, expr := range exprs {
+		val, _ := expr.Value(f.ctx)
+		if !val.IsWhollyKnown() {
+			ret = append(ret, expr.Variables()...)
+		}
+	}
+	return ret
+}
+
+// copyPath returns a copy of the given path, so that appending steps to the
+// result does not modify the backing array of a path used elsewhere.
+func copyPath(path cty.Path) cty.Path {
+	return append(cty.Path(nil), path...)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestValueWithUnknownSources(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"known":   cty.StringVal("a"),
+			"unknown": cty.UnknownVal(cty.String),
+			"partial": cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("a"),
+				"b": cty.UnknownVal(cty.String),
+			}),
+			"cond":      cty.UnknownVal(cty.Bool),
+			"sensitive": cty.UnknownVal(cty.String).Mark("sensitive"),
+		},
+		Functions: map[string]function.Function{
+			"upper":  stdlib.UpperFunc,
+			"concat": stdlib.ConcatFunc,
+		},
+	}
+
+	tests := []struct {
+		src  string
+		want map[string][]string
+	}{
+		{
+			`known`,
+			map[string][]string{},
+		},
+		{
+			`unknown`,
+			map[string][]string{
+				``: {`unknown`},
+			},
+		},
+		{
+			`partial`,
+			map[string][]string{
+				`.b`: {`partial`},
+			},
+		},
+		{
+			`{ a = known, b = unknown, c = [known, upper(unknown)], d = partial }`,
+			map[string][]string{
+				`.b`:    {`unknown`},
+				`.c[1]`: {`unknown`},
+				`.d.b`:  {`partial`},
+			},
+		},
+		{
+			`(true ? [unknown] : [known])`,
+			map[string][]string{
+				`[0]`: {`unknown`},
+			},
+		},
+		{
+			`cond ? known : "b"`,
+			map[string][]string{
+				``: {`cond`},
+			},
+		},
+		{
+			`upper(known) == unknown`,
+			map[string][]string{
+				``: {`unknown`},
+			},
+		},
+		{
+			`"${known}-${partial.b}"`,
+			map[string][]string{
+				``: {`partial.b`},
+			},
+		},
+		{
+			`[for v in [known, unknown] : v]`,
+			map[string][]string{
+				`[1]`: {`known`, `unknown`},
+			},
+		},
+		{
+			`{ "x" = sensitive }`,
+			map[string][]string{
+				`.x`: {`sensitive`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if parseDiags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", parseDiags.Error())
+			}
+
+			wantVal, _ := expr.Value(ctx)
+			gotVal, sources, diags := ValueWithUnknownSources(expr, ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if !gotVal.RawEquals(wantVal) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", gotVal, wantVal)
+			}
+
+			got := map[string][]string{}
+			for _, source := range sources {
+				var names []string
+				for _, traversal := range source.Traversals {
+					names = append(names, traversalSourceString(traversal))
+				}
+				got[pathString(source.Path)] = names
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong sources\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func traversalSourceString(traversal hcl.Traversal) string {
+	var buf strings.Builder
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			buf.WriteString(step.Name)
+		case hcl.TraverseAttr:
+			buf.WriteString("." + step.Name)
+		default:
+			buf.WriteString("[...]")
+		}
+	}
+	return buf.String()
+}
+
+func pathString(path cty.Path) string {
+	var buf strings.Builder
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			buf.WriteString("." + step.Name)
+		case cty.IndexStep:
+			if step.Key.Type() == cty.Number {
+				buf.WriteString(fmt.Sprintf("[%s]", step.Key.AsBigFloat().Text('f', -1)))
+			} else {
+				buf.WriteString(fmt.Sprintf("[%q]", step.Key.AsString()))
+			}
+		}
+	}
+	return buf.String()
+}
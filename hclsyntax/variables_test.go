@@ -305,3 +305,48 @@ func TestVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestVariablesTemplateDirectives(t *testing.T) {
+	tests := []struct {
+		Src  string
+		Want []string
+	}{
+		{
+			`%{ for x in list }${x}${y}%{ endfor }`,
+			[]string{"list", "y"},
+		},
+		{
+			`%{ for k, v in m }%{ for x in v }${k}${x}${z}%{ endfor }%{ endfor }`,
+			[]string{"m", "z"},
+		},
+		{
+			`%{ for x in list }%{ if x.ok }${x.name}%{ else }${w}%{ endif }%{ endfor }`,
+			[]string{"list", "w"},
+		},
+		{
+			`%{ if cond }%{ for x in x }${x}%{ endfor }%{ endif }${x}`,
+			[]string{"cond", "x", "x"},
+		},
+		{
+			`%{ for x in a }${x}%{ endfor }%{ for y in x }${y}%{ endfor }`,
+			[]string{"a", "x"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			expr, diags := ParseTemplate([]byte(test.Src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			var got []string
+			for _, traversal := range Variables(expr) {
+				got = append(got, traversal.RootName())
+			}
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
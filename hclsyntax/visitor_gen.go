@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// This is a 'go generate'-oriented program for producing the Visitor
+// interface, along with its no-op implementation NopVisitor and the function
+// that dispatches to its methods, with one method for every AST node type
+// found within this package. A type is taken to be an AST node type if it
+// has a "walkChildNodes" method, which all implementations of Node must have.
+
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+)
+
+func main() {
+	fs := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fs, ".", nil, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error while parsing: %s\n", err)
+		os.Exit(1)
+	}
+	pkg := pkgs["hclsyntax"]
+
+	type nodeType struct {
+		Name string // the name of the type
+		Recv string // the type as it appears in method signatures
+	}
+
+	var types []nodeType
+	for _, f := range pkg.Files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil {
+				continue
+			}
+			if fd.Name.Name != "walkChildNodes" {
+				continue
+			}
+
+			recvTy := fd.Recv.List[0].Type
+
+			switch rtt := recvTy.(type) {
+			case *ast.StarExpr:
+				name := rtt.X.(*ast.Ident).Name
+				types = append(types, nodeType{name, "*" + name})
+			case *ast.Ident:
+				types = append(types, nodeType{rtt.Name, rtt.Name})
+			default:
+				fmt.Fprintf(os.Stderr, "don't know what to do with a %T receiver\n", recvTy)
+			}
+		}
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+
+	of, err := os.OpenFile("visitor.go", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open output file: %s\n", err)
+		os.Exit(1)
+	}
+	defer of.Close()
+
+	fmt.Fprint(of, preamble)
+
+	fmt.Fprint(of, interfacePreamble)
+	for _, ty := range types {
+		fmt.Fprintf(of, "\tVisit%s(node %s) hcl.Diagnostics\n", ty.Name, ty.Recv)
+	}
+	fmt.Fprint(of, "}\n")
+
+	fmt.Fprint(of, nopPreamble)
+	for _, ty := range types {
+		fmt.Fprintf(of, nopMethodFmt, ty.Name, ty.Recv)
+	}
+
+	fmt.Fprint(of, dispatchPreamble)
+	for _, ty := range types {
+		fmt.Fprintf(of, dispatchCaseFmt, ty.Recv, ty.Name)
+	}
+	fmt.Fprint(of, dispatchEnd)
+}
+
+const preamble = `// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+// Generated by visitor_gen.go. DO NOT EDIT.
+// Run 'go generate' on this package to update the set of methods here.
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+)
+`
+
+const interfacePreamble = `
+// Visitor is an interface used with Visit, which has one method for each
+// type of AST node. Each method is called with a node of the corresponding
+// type, and may return diagnostics to be accumulated into the result of
+// Visit.
+//
+// This interface will grow new methods as new node types are added to this
+// package, so implementations should embed NopVisitor to remain compatible.
+type Visitor interface {
+`
+
+const nopPreamble = `
+// NopVisitor is an implementation of Visitor whose methods all do nothing.
+// Embed it in another type to implement Visitor by overriding only the
+// methods for the node types of interest.
+type NopVisitor struct{}
+`
+
+const nopMethodFmt = `
+func (NopVisitor) Visit%s(%s) hcl.Diagnostics {
+	return nil
+}
+`
+
+const dispatchPreamble = `
+// visitNode calls the method of the given visitor that corresponds to the
+// type of the given node.
+func visitNode(node Node, v Visitor) hcl.Diagnostics {
+	switch tn := node.(type) {
+`
+
+const dispatchCaseFmt = `	case %s:
+		return v.Visit%s(tn)
+`
+
+const dispatchEnd = `	default:
+		// should never happen, since this function is generated from the
+		// full set of node types
+		panic(fmt.Sprintf("unsupported node type %T", node))
+	}
+}
+`
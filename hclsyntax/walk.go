@@ -24,6 +24,19 @@ func VisitAll(node Node, f VisitFunc) hcl.Diagnostics {
 	return diags
 }
 
+// Visit is like VisitAll, but calls the method of the given Visitor that
+// corresponds to the type of each node, rather than a single function for
+// all nodes. This avoids the need for a type switch in callers that are
+// interested only in particular types of node.
+//
+// The diagnostics returned by the visitor methods are accumulated and
+// returned as a single set.
+func Visit(node Node, v Visitor) hcl.Diagnostics {
+	return VisitAll(node, func(node Node) hcl.Diagnostics {
+		return visitNode(node, v)
+	})
+}
+
 // Walker is an interface used with Walk.
 type Walker interface {
 	Enter(node Node) hcl.Diagnostics
@@ -182,3 +182,46 @@ func (w *testWalker) Exit(node Node) hcl.Diagnostics {
 	w.Calls = append(w.Calls, testWalkCall{testWalkExit, fmt.Sprintf("%T", node)})
 	return nil
 }
+
+type testFuncCallVisitor struct {
+	NopVisitor
+	names []string
+}
+
+func (v *testFuncCallVisitor) VisitFunctionCallExpr(node *FunctionCallExpr) hcl.Diagnostics {
+	v.names = append(v.names, node.Name)
+	if node.Name == "bad" {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagWarning,
+				Summary:  "Bad function",
+				Subject:  node.NameRange.Ptr(),
+			},
+		}
+	}
+	return nil
+}
+
+func TestVisit(t *testing.T) {
+	src := `
+a = upper(lower("x"))
+b {
+  c = bad([for v in list : length(v)])
+}
+`
+	file, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	v := &testFuncCallVisitor{}
+	diags = Visit(file.Body.(*Body), v)
+
+	want := []string{"upper", "lower", "bad", "length"}
+	if !reflect.DeepEqual(v.names, want) {
+		t.Errorf("wrong function names visited\ngot:  %#v\nwant: %#v", v.names, want)
+	}
+	if len(diags) != 1 || diags[0].Summary != "Bad function" {
+		t.Errorf("wrong diagnostics: %#v", diags)
+	}
+}
@@ -90,7 +90,7 @@ func (b mockBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.
 
 	for _, block := range b.C.Blocks {
 		if blockS, ok := wantedBlocks[block.Type]; ok {
-			if len(block.Labels) != len(blockS.LabelNames) {
+			if len(block.Labels) < len(blockS.LabelNames) || (len(block.Labels) > len(blockS.LabelNames) && !blockS.ExtraLabels) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Wrong number of block labels",
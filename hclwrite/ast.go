@@ -6,6 +6,9 @@ package hclwrite
 import (
 	"bytes"
 	"io"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 type File struct {
@@ -13,6 +16,10 @@ type File struct {
 
 	srcBytes []byte
 	body     *node
+
+	// bom records whether the source began with a UTF-8 byte order mark,
+	// which the lexer skips, so that WriteTo can preserve it.
+	bom bool
 }
 
 // NewEmptyFile constructs a new file with no content, ready to be mutated
@@ -35,11 +42,21 @@ func (f *File) Body() *Body {
 // WriteTo writes the tokens underlying the receiving file to the given writer.
 //
 // The tokens first have a simple formatting pass applied that adjusts only
-// the spaces between them.
+// the spaces between them. If the file was parsed from source code that
+// began with a UTF-8 byte order mark, the mark is written first.
 func (f *File) WriteTo(wr io.Writer) (int64, error) {
 	tokens := f.inTree.children.BuildTokens(nil)
 	format(tokens)
-	return tokens.WriteTo(wr)
+	if !f.bom {
+		return tokens.WriteTo(wr)
+	}
+
+	n, err := io.WriteString(wr, hclsyntax.UTF8BOM)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := tokens.WriteTo(wr)
+	return int64(n) + m, err
 }
 
 // Bytes returns a buffer containing the source code resulting from the
@@ -51,6 +68,104 @@ func (f *File) Bytes() []byte {
 	return buf.Bytes()
 }
 
+// LineEnding selects the characters that end each line of the source code
+// returned by File.BytesWithLineEnding.
+type LineEnding int
+
+const (
+	// LineEndingLF ends each line with a single newline character, which is
+	// the line ending used within the tokens and by File.Bytes for any
+	// content that isn't retained verbatim from CRLF source code.
+	LineEndingLF LineEnding = iota
+
+	// LineEndingCRLF ends each line with a carriage return followed by a
+	// newline, as is conventional on Windows.
+	LineEndingCRLF
+)
+
+// BytesWithLineEnding is like Bytes, except that every line of the result,
+// including those retained from the original source code, ends with the
+// given line ending style.
+//
+// The line endings are converted while writing the tokens, and so apply
+// equally to newlines between tokens, within comments, and within the
+// content of heredoc templates. Because the content of a heredoc template
+// includes its line endings, converting them changes the value of the
+// template in the same way as converting the line endings of the whole
+// file with another tool would. Quoted string literals cannot contain
+// literal newlines, and any escape sequences such as \n within them are not
+// changed.
+func (f *File) BytesWithLineEnding(style LineEnding) []byte {
+	eol := []byte{'\n'}
+	if style == LineEndingCRLF {
+		eol = []byte{'\r', '\n'}
+	}
+
+	tokens := f.inTree.children.BuildTokens(nil)
+	format(tokens)
+	converted := make(Tokens, len(tokens))
+	for i, tok := range tokens {
+		newTok := *tok
+		if tok.Type != hclsyntax.TokenQuotedLit && bytes.IndexByte(tok.Bytes, '\n') >= 0 {
+			newTok.Bytes = bytes.ReplaceAll(bytes.ReplaceAll(tok.Bytes, []byte{'\r', '\n'}, []byte{'\n'}), []byte{'\n'}, eol)
+		}
+		converted[i] = &newTok
+	}
+
+	buf := &bytes.Buffer{}
+	if f.bom {
+		buf.WriteString(hclsyntax.UTF8BOM)
+	}
+	converted.WriteTo(buf)
+	return buf.Bytes()
+}
+
+// NormalizeStrings rewrites the literal parts of each quoted string in the
+// receiving file, including quoted block labels, to use the same escaping
+// that would be used when generating the string from its value with
+// TokensForValue. This allows files that were edited by hand to be brought
+// into a consistent style without changing the string values.
+//
+// In the canonical form, newlines, carriage returns, tabs, quotes and
+// backslashes are written with their short escapes, any other unprintable
+// character is written with a \u or \U escape, and all other characters,
+// including those that were written as unicode escapes, are written
+// literally. Only the template introducers "${" and "%{" are doubled, and a
+// "$" or "%" that immediately precedes an interpolation or control sequence
+// of the same kind is written as a \u escape.
+//
+// Interpolation and control sequences within the strings are not changed,
+// although any quoted strings nested inside them are themselves normalized.
+// The content of heredoc templates is not changed, and nor is any literal
+// part whose escape sequences are invalid.
+func (f *File) NormalizeStrings() {
+	toks := f.inTree.children.BuildTokens(nil)
+	for i, tok := range toks {
+		if tok.Type != hclsyntax.TokenQuotedLit {
+			continue
+		}
+		val, diags := hclsyntax.ParseStringLiteralToken(tok.asHCLSyntax())
+		if diags.HasErrors() {
+			continue
+		}
+		lit := escapeQuotedStringLit(val)
+
+		// A literal "$" or "%" written just before an interpolation or
+		// control sequence of the same kind must stay escaped, or else it
+		// would combine with the following introducer to make an escaped
+		// introducer instead.
+		if i+1 < len(toks) {
+			switch {
+			case toks[i+1].Type == hclsyntax.TokenTemplateInterp && strings.HasSuffix(val, "$"):
+				lit = append(lit[:len(lit)-1], `\u0024`...)
+			case toks[i+1].Type == hclsyntax.TokenTemplateControl && strings.HasSuffix(val, "%"):
+				lit = append(lit[:len(lit)-1], `\u0025`...)
+			}
+		}
+		tok.Bytes = lit
+	}
+}
+
 type comments struct {
 	leafNode
 
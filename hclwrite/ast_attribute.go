@@ -4,6 +4,9 @@
 package hclwrite
 
 import (
+	"bytes"
+	"strings"
+
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
@@ -49,3 +52,39 @@ func (a *Attribute) init(name string, expr *Expression) {
 func (a *Attribute) Expr() *Expression {
 	return a.expr.content.(*Expression)
 }
+
+// Bytes returns the source code of just the receiving attribute, including
+// any comments that lead or trail it, formatted as a standalone snippet in
+// the same way as File.Bytes would format it at the top level of a file.
+//
+// The result has no indentation before the attribute name, regardless of
+// how deeply the attribute is nested in its file, and ends with a newline.
+func (a *Attribute) Bytes() []byte {
+	buf := &bytes.Buffer{}
+	FormatTokens(a.BuildTokens(nil)).WriteTo(buf)
+	return buf.Bytes()
+}
+
+// SetLeadingComment replaces any comments immediately preceding the attribute
+// with a sequence of single-line "#" comments, one for each of the given
+// lines. Calling it again replaces the comments from the previous call, and
+// calling it with no lines removes any leading comments.
+//
+// The comments are indented to match the attribute when the containing file
+// is formatted, such as by File.Bytes.
+func (a *Attribute) SetLeadingComment(lines []string) {
+	var toks Tokens
+	for _, line := range lines {
+		for _, part := range strings.Split(line, "\n") {
+			text := "#"
+			if part != "" {
+				text += " " + part
+			}
+			toks = append(toks, &Token{
+				Type:  hclsyntax.TokenComment,
+				Bytes: []byte(text + "\n"),
+			})
+		}
+	}
+	a.leadComments.content.(*comments).tokens = toks
+}
@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestAttributeSetLeadingComment(t *testing.T) {
+	tests := map[string]struct {
+		src   string
+		calls [][]string
+		want  string
+	}{
+		"new": {
+			"a = 1\nb = 2\n",
+			[][]string{{"Managed by tooling"}},
+			"a = 1\n# Managed by tooling\nb = 2\n",
+		},
+		"multiple lines": {
+			"a = 1\nb = 2\n",
+			[][]string{{"Managed by tooling", "", "Do not edit."}},
+			"a = 1\n# Managed by tooling\n#\n# Do not edit.\nb = 2\n",
+		},
+		"replaces previous call": {
+			"a = 1\nb = 2\n",
+			[][]string{{"first"}, {"second"}},
+			"a = 1\n# second\nb = 2\n",
+		},
+		"replaces existing": {
+			"a = 1\n// old comment\nb = 2\n",
+			[][]string{{"new comment"}},
+			"a = 1\n# new comment\nb = 2\n",
+		},
+		"removes": {
+			"a = 1\n# old comment\nb = 2\n",
+			[][]string{nil},
+			"a = 1\nb = 2\n",
+		},
+		"nested": {
+			"block {\n  a = 1\n  b = 2\n}\n",
+			[][]string{{"Managed by tooling"}},
+			"block {\n  a = 1\n  # Managed by tooling\n  b = 2\n}\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			body := f.Body()
+			if blocks := body.Blocks(); len(blocks) > 0 {
+				body = blocks[0].Body()
+			}
+			attr := body.GetAttribute("b")
+			for _, lines := range test.calls {
+				attr.SetLeadingComment(lines)
+			}
+
+			if got := string(f.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("new attribute", func(t *testing.T) {
+		f := NewEmptyFile()
+		f.Body().SetAttributeValue("a", cty.True)
+		f.Body().GetAttribute("a").SetLeadingComment([]string{"Generated"})
+		if got, want := string(f.Bytes()), "# Generated\na = true\n"; got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
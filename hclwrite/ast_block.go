@@ -4,6 +4,8 @@
 package hclwrite
 
 import (
+	"bytes"
+
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -71,6 +73,19 @@ func (b *Block) Body() *Body {
 	return b.body.content.(*Body)
 }
 
+// Bytes returns the source code of just the receiving block, including any
+// comments that lead it, formatted as a standalone snippet in the same way
+// as File.Bytes would format it at the top level of a file.
+//
+// The result has no indentation before the block type name, regardless of
+// how deeply the block is nested in its file, and the content of the block
+// is indented relative to that. The result ends with a newline.
+func (b *Block) Bytes() []byte {
+	buf := &bytes.Buffer{}
+	FormatTokens(b.BuildTokens(nil)).WriteTo(buf)
+	return buf.Bytes()
+}
+
 // Type returns the type name of the block.
 func (b *Block) Type() string {
 	typeNameObj := b.typeName.content.(*identifier)
@@ -89,9 +104,13 @@ func (b *Block) Labels() []string {
 	return b.labelsObj().Current()
 }
 
-// SetLabels updates the labels of the block to given labels.
-// Since we cannot assume that old and new labels are equal in length,
-// remove old labels and insert new ones before TokenOBrace.
+// SetLabels updates the labels of the block to given labels, each written
+// as a quoted string literal.
+//
+// Existing labels are replaced in place, so that any comments between them
+// are preserved. If there are more new labels than existing ones then the
+// extra labels are added after the last existing label, and if there are
+// fewer then the extra existing labels are removed.
 func (b *Block) SetLabels(labels []string) {
 	b.labelsObj().Replace(labels)
 }
@@ -122,18 +141,27 @@ func newBlockLabels(labels []string) *blockLabels {
 }
 
 func (bl *blockLabels) Replace(newLabels []string) {
-	bl.inTree.children.Clear()
-	bl.items.Clear()
+	oldItems := bl.items.List()
 
-	for _, label := range newLabels {
+	for i, label := range newLabels {
 		labelToks := TokensForValue(cty.StringVal(label))
 		// Force a new label to use the quoted form, which is the idiomatic
 		// form. The unquoted form is supported in HCL 2 only for compatibility
 		// with historical use in HCL 1.
 		labelObj := newQuoted(labelToks)
+		if i < len(oldItems) {
+			bl.items.Remove(oldItems[i])
+			bl.items.Add(oldItems[i].ReplaceWith(labelObj))
+			continue
+		}
 		labelNode := bl.children.Append(labelObj)
 		bl.items.Add(labelNode)
 	}
+
+	for i := len(newLabels); i < len(oldItems); i++ {
+		bl.items.Remove(oldItems[i])
+		oldItems[i].Detach()
+	}
 }
 
 func (bl *blockLabels) Current() []string {
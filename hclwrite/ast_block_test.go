@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestBlockType(t *testing.T) {
@@ -382,7 +383,7 @@ func TestBlockSetLabels(t *testing.T) {
 			`foo "hoge" /* fuga */ "piyo" {}`,
 			"foo",
 			[]string{"hoge", "piyo"},
-			[]string{"fuga"}, // force quoted form even if the old one is unquoted.
+			[]string{"fuga"}, // remove a label, preserving the comment
 			Tokens{
 				{
 					Type:         hclsyntax.TokenIdent,
@@ -404,6 +405,11 @@ func TestBlockSetLabels(t *testing.T) {
 					Bytes:        []byte(`"`),
 					SpacesBefore: 0,
 				},
+				{
+					Type:         hclsyntax.TokenComment,
+					Bytes:        []byte(`/* fuga */`),
+					SpacesBefore: 1,
+				},
 				{
 					Type:         hclsyntax.TokenOBrace,
 					Bytes:        []byte{'{'},
@@ -425,7 +431,7 @@ func TestBlockSetLabels(t *testing.T) {
 			`foo "hoge" /* foo */  "" {}`,
 			"foo",
 			[]string{"hoge", ""},
-			[]string{"fuga"}, // force quoted form even if the old one is unquoted.
+			[]string{"fuga"}, // remove a blank label, preserving the comment
 			Tokens{
 				{
 					Type:         hclsyntax.TokenIdent,
@@ -447,6 +453,11 @@ func TestBlockSetLabels(t *testing.T) {
 					Bytes:        []byte(`"`),
 					SpacesBefore: 0,
 				},
+				{
+					Type:         hclsyntax.TokenComment,
+					Bytes:        []byte(`/* foo */`),
+					SpacesBefore: 1,
+				},
 				{
 					Type:         hclsyntax.TokenOBrace,
 					Bytes:        []byte{'{'},
@@ -487,3 +498,189 @@ func TestBlockSetLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockSetLabelsPreservesComments(t *testing.T) {
+	tests := []struct {
+		src       string
+		newLabels []string
+		want      string
+	}{
+		{
+			"resource /* a */ \"old\" /* b */ name /* c */ {\n}\n",
+			[]string{"new", "renamed"},
+			"resource /* a */ \"new\" /* b */ \"renamed\" /* c */ {\n}\n",
+		},
+		{
+			"resource /* a */ \"old\" {\n}\n",
+			[]string{"new", "added"},
+			"resource /* a */ \"new\" \"added\" {\n}\n",
+		},
+		{
+			"resource \"old\" /* b */ \"name\" {\n}\n",
+			nil,
+			"resource /* b */ {\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			b := f.Body().Blocks()[0]
+			b.SetLabels(test.newLabels)
+			if got := string(f.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+			if got := b.Labels(); len(got) != 0 || len(test.newLabels) != 0 {
+				if !reflect.DeepEqual(got, test.newLabels) {
+					t.Errorf("wrong labels\ngot:  %#v\nwant: %#v", got, test.newLabels)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBlock(t *testing.T) {
+	tests := map[string]struct {
+		fragment  string
+		want      string
+		wantDiags []string
+	}{
+		"simple": {
+			`resource "x" "y" {
+  a = 1
+}
+`,
+			`existing = true
+
+resource "x" "y" {
+  a = 1
+}
+`,
+			nil,
+		},
+		"comments and no trailing newline": {
+			`# Lead comment
+resource "x" "y" {
+  # inner
+  a = [1, 2] # line
+}`,
+			`existing = true
+
+# Lead comment
+resource "x" "y" {
+  # inner
+  a = [1, 2] # line
+}
+`,
+			nil,
+		},
+		"syntax error": {
+			`resource "x" {
+  a =
+}
+`,
+			"",
+			[]string{`test.hcl:12,6-13,1: Invalid expression; Expected the start of an expression, but found an invalid expression token.`},
+		},
+		"empty": {
+			``,
+			"",
+			[]string{`test.hcl:11,1-1: Missing block; A block fragment must contain a single block.`},
+		},
+		"attribute": {
+			`a = 1
+resource "x" {}
+`,
+			"",
+			[]string{`test.hcl:11,1-2: Unexpected argument; A block fragment must contain only a single block, not arguments.`},
+		},
+		"two blocks": {
+			`resource "x" {}
+resource "y" {}
+`,
+			"",
+			[]string{`test.hcl:12,1-13: Extraneous block; A block fragment must contain only a single block.`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			block, diags := ParseBlock([]byte(test.fragment), "test.hcl", hcl.Pos{Line: 11, Column: 1, Byte: 100})
+
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Fatalf("wrong diagnostics\n%s", diff)
+			}
+			if test.want == "" {
+				if block != nil {
+					t.Fatalf("unexpected block")
+				}
+				return
+			}
+
+			f := NewFile()
+			f.Body().SetAttributeValue("existing", cty.True)
+			f.Body().AppendNewline()
+			f.Body().AppendBlock(block)
+			if diff := cmp.Diff(test.want, string(f.Bytes())); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBlockBytes(t *testing.T) {
+	src := `outer {
+    # The inner block.
+    inner "a" {
+  foo    = 1 # trailing
+      longer = "x"
+      nested {
+        bar = [
+          1,
+        ]
+      }
+    }
+    attr = 2
+}
+`
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	outer := f.Body().Blocks()[0]
+	inner := outer.Body().Blocks()[0]
+
+	want := `# The inner block.
+inner "a" {
+  foo    = 1 # trailing
+  longer = "x"
+  nested {
+    bar = [
+      1,
+    ]
+  }
+}
+`
+	if got := string(inner.Bytes()); got != want {
+		t.Errorf("wrong block result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := string(inner.Body().GetAttribute("foo").Bytes()), "foo = 1 # trailing\n"; got != want {
+		t.Errorf("wrong attribute result\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := string(outer.Body().GetAttribute("attr").Bytes()), "attr = 2\n"; got != want {
+		t.Errorf("wrong attribute result\ngot:  %q\nwant: %q", got, want)
+	}
+
+	// Serializing a node alone must not change the tokens in its file.
+	if got, want := inner.BuildTokens(nil)[0].SpacesBefore, 4; got != want {
+		t.Errorf("block tokens were modified: first token has %d spaces before; want %d", got, want)
+	}
+}
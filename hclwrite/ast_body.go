@@ -4,7 +4,9 @@
 package hclwrite
 
 import (
+	"bytes"
 	"reflect"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -25,6 +27,7 @@ func newBody() *Body {
 }
 
 func (b *Body) appendItem(c nodeContent) *node {
+	b.ensureTrailingNewline()
 	nn := b.children.Append(c)
 	b.items.Add(nn)
 	return nn
@@ -32,11 +35,38 @@ func (b *Body) appendItem(c nodeContent) *node {
 
 func (b *Body) appendItemNode(nn *node) *node {
 	nn.assertUnattached()
+	b.ensureTrailingNewline()
 	b.children.AppendNode(nn)
 	b.items.Add(nn)
 	return nn
 }
 
+// ensureTrailingNewline appends a newline to the body if its existing content
+// doesn't already end with one, so that a new item appended afterwards will
+// begin on a new line.
+//
+// This can arise when the body was parsed from source whose final item ends
+// at EOF without a newline. Without this, the new item would be written on
+// the same line, potentially making it part of a trailing comment.
+func (b *Body) ensureTrailingNewline() {
+	last := b.children.lastToken()
+	if last == nil || tokenEndsWithNewline(last) {
+		return
+	}
+	b.children.AppendUnstructuredTokens(newlineTokens())
+}
+
+// tokenEndsWithNewline returns true if the given token ends with a newline,
+// either because it is a newline token or because it is a single-line
+// comment.
+func tokenEndsWithNewline(tok *Token) bool {
+	if tok.Type == hclsyntax.TokenNewline {
+		return true
+	}
+	// Single-line comments include their terminating newline
+	return tok.Type == hclsyntax.TokenComment && bytes.HasSuffix(tok.Bytes, []byte{'\n'})
+}
+
 // Clear removes all of the items from the body, making it empty.
 func (b *Body) Clear() {
 	b.children.Clear()
@@ -108,20 +138,52 @@ func (b *Body) getAttributeNode(name string) *node {
 // block.
 func (b *Body) FirstMatchingBlock(typeName string, labels []string) *Block {
 	for _, block := range b.Blocks() {
-		if typeName == block.Type() {
-			labelNames := block.Labels()
-			if len(labels) == 0 && len(labelNames) == 0 {
-				return block
-			}
-			if reflect.DeepEqual(labels, labelNames) {
-				return block
-			}
+		if blockMatches(block, typeName, labels) {
+			return block
 		}
 	}
 
 	return nil
 }
 
+// MatchingBlocks returns a new slice of all of the blocks in the body that
+// have the given type name and labels, in the order they appear in the body.
+// As with FirstMatchingBlock, the labels are compared in their decoded form
+// and must match exactly, so blocks with additional labels don't match.
+func (b *Body) MatchingBlocks(typeName string, labels []string) []*Block {
+	var ret []*Block
+	for _, block := range b.Blocks() {
+		if blockMatches(block, typeName, labels) {
+			ret = append(ret, block)
+		}
+	}
+	return ret
+}
+
+// BlocksOfType returns a new slice of all of the blocks in the body that
+// have the given type name, regardless of their labels, in the order they
+// appear in the body.
+func (b *Body) BlocksOfType(typeName string) []*Block {
+	var ret []*Block
+	for _, block := range b.Blocks() {
+		if block.Type() == typeName {
+			ret = append(ret, block)
+		}
+	}
+	return ret
+}
+
+func blockMatches(block *Block, typeName string, labels []string) bool {
+	if typeName != block.Type() {
+		return false
+	}
+	labelNames := block.Labels()
+	if len(labels) == 0 && len(labelNames) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(labels, labelNames)
+}
+
 // RemoveBlock removes the given block from the body, if it's in that body.
 // If it isn't present, this is a no-op.
 //
@@ -137,6 +199,90 @@ func (b *Body) RemoveBlock(block *Block) bool {
 	return false
 }
 
+// RemoveBlockCleaningWhitespace is like RemoveBlock, but also removes blank
+// lines around the removed block so that the remaining body doesn't have
+// two consecutive groups of blank lines where the block used to be, nor
+// blank lines at the start or end of the body that were separating the
+// block from its neighbors.
+//
+// Blank lines that appeared before the first item in the body, or after the
+// last item, are left unchanged unless the removed block was the last item.
+//
+// Returns true if it removed something, or false otherwise.
+func (b *Body) RemoveBlockCleaningWhitespace(block *Block) bool {
+	var n *node
+	for candidate := range b.items {
+		if candidate.content == block {
+			n = candidate
+			break
+		}
+	}
+	if n == nil {
+		return false
+	}
+
+	b.removeNodeCleaningWhitespace(n)
+	return true
+}
+
+// removeNodeCleaningWhitespace detaches the given item node from the body
+// along with the blank lines around it, as described for
+// RemoveBlockCleaningWhitespace.
+func (b *Body) removeNodeCleaningWhitespace(n *node) {
+	var before, after []*node
+	prev, next := n.before, n.after
+	for prev != nil && isBlankLineNode(prev) {
+		before = append(before, prev)
+		prev = prev.before
+	}
+	for next != nil && isBlankLineNode(next) {
+		after = append(after, next)
+		next = next.after
+	}
+
+	var remove []*node
+	if prev != nil && next == nil {
+		// The node was the last item, so the blank lines that separated
+		// it from the previous item are no longer needed.
+		remove = append(remove, before...)
+	}
+	if prev == nil || next == nil || len(before) > 0 || endsWithBlankLine(prev) {
+		remove = append(remove, after...)
+	}
+
+	n.Detach()
+	b.items.Remove(n)
+	for _, blank := range remove {
+		blank.Detach()
+	}
+}
+
+// endsWithBlankLine returns true if the given node consists of unstructured
+// tokens, such as comments, that end with a blank line.
+func endsWithBlankLine(n *node) bool {
+	toks, ok := n.content.(Tokens)
+	if !ok || len(toks) < 2 || toks[len(toks)-1].Type != hclsyntax.TokenNewline {
+		return false
+	}
+	prev := toks[len(toks)-2]
+	return prev.Type == hclsyntax.TokenNewline || (prev.Type == hclsyntax.TokenComment && bytes.HasSuffix(prev.Bytes, []byte{'\n'}))
+}
+
+// isBlankLineNode returns true if the given node consists only of newline
+// tokens, and so represents one or more blank lines between body items.
+func isBlankLineNode(n *node) bool {
+	toks, ok := n.content.(Tokens)
+	if !ok || len(toks) == 0 {
+		return false
+	}
+	for _, tok := range toks {
+		if tok.Type != hclsyntax.TokenNewline {
+			return false
+		}
+	}
+	return true
+}
+
 // SetAttributeRaw either replaces the expression of an existing attribute
 // of the given name or adds a new attribute definition to the end of the block,
 // using the given tokens verbatim as the expression.
@@ -150,7 +296,7 @@ func (b *Body) SetAttributeRaw(name string, tokens Tokens) *Attribute {
 	if attr != nil {
 		attr.expr = attr.expr.ReplaceWith(expr)
 	} else {
-		attr := newAttribute()
+		attr = newAttribute()
 		attr.init(name, expr)
 		b.appendItem(attr)
 	}
@@ -171,13 +317,35 @@ func (b *Body) SetAttributeValue(name string, val cty.Value) *Attribute {
 	if attr != nil {
 		attr.expr = attr.expr.ReplaceWith(expr)
 	} else {
-		attr := newAttribute()
+		attr = newAttribute()
 		attr.init(name, expr)
 		b.appendItem(attr)
 	}
 	return attr
 }
 
+// SetAttributeValueFunc is like SetAttributeValue except that it uses
+// TokensForValueFunc with the given fallback to produce the expression, and
+// so returns an error rather than panicking if the value is unknown or of a
+// capsule type and the fallback doesn't handle it.
+//
+// If an error is returned then the body is not modified.
+func (b *Body) SetAttributeValueFunc(name string, val cty.Value, fallback ValueTokensFunc) (*Attribute, error) {
+	expr, err := NewExpressionLiteralFunc(val, fallback)
+	if err != nil {
+		return nil, err
+	}
+	attr := b.GetAttribute(name)
+	if attr != nil {
+		attr.expr = attr.expr.ReplaceWith(expr)
+	} else {
+		attr = newAttribute()
+		attr.init(name, expr)
+		b.appendItem(attr)
+	}
+	return attr, nil
+}
+
 // SetAttributeTraversal either replaces the expression of an existing attribute
 // of the given name or adds a new attribute definition to the end of the body.
 //
@@ -192,7 +360,7 @@ func (b *Body) SetAttributeTraversal(name string, traversal hcl.Traversal) *Attr
 	if attr != nil {
 		attr.expr = attr.expr.ReplaceWith(expr)
 	} else {
-		attr := newAttribute()
+		attr = newAttribute()
 		attr.init(name, expr)
 		b.appendItem(attr)
 	}
@@ -200,6 +368,10 @@ func (b *Body) SetAttributeTraversal(name string, traversal hcl.Traversal) *Attr
 }
 
 // RemoveAttribute removes the attribute with the given name from the body.
+// The comments on the lines directly above the attribute and any comment at
+// the end of its line belong to the attribute, and so are removed with it.
+// Any blank lines around it are left in place; use
+// RemoveAttributeCleaningWhitespace to remove those too.
 //
 // The return value is the attribute that was removed, or nil if there was
 // no such attribute (in which case the call was a no-op).
@@ -213,6 +385,98 @@ func (b *Body) RemoveAttribute(name string) *Attribute {
 	return node.content.(*Attribute)
 }
 
+// RemoveAttributeCleaningWhitespace is like RemoveAttribute, but also
+// removes blank lines around the removed attribute in the same way as
+// RemoveBlockCleaningWhitespace. Removing an attribute that was alone in a
+// group of attributes separated by blank lines therefore leaves a single
+// blank line between the neighboring groups, rather than two.
+//
+// The return value is the attribute that was removed, or nil if there was
+// no such attribute (in which case the call was a no-op).
+func (b *Body) RemoveAttributeCleaningWhitespace(name string) *Attribute {
+	node := b.getAttributeNode(name)
+	if node == nil {
+		return nil
+	}
+	b.removeNodeCleaningWhitespace(node)
+	return node.content.(*Attribute)
+}
+
+// SortAttributes reorders the attributes in the receiving body so that they
+// appear in lexicographical order by name, which can be used to produce
+// stable output when the attributes were added in an arbitrary order, such
+// as from iterating over a map.
+//
+// Each attribute is moved along with its leading comments and any comment
+// on the same line. Only runs of consecutive attributes are sorted, and each
+// such run is sorted separately, so any blocks, blank lines, or detached
+// comments remain at their current positions and continue to separate the
+// attributes into the same groups as before. Nested block bodies are not
+// affected.
+func (b *Body) SortAttributes() {
+	var run []*node
+	for n := b.children.first; ; n = n.after {
+		if n != nil {
+			if _, isAttr := n.content.(*Attribute); isAttr && b.items.Has(n) {
+				run = append(run, n)
+				continue
+			}
+		}
+		sortAttributeRun(run)
+		run = run[:0]
+		if n == nil {
+			break
+		}
+	}
+}
+
+// sortAttributeRun sorts the given consecutive attribute nodes by name by
+// rearranging their contents, so that the nodes themselves stay in place.
+func sortAttributeRun(run []*node) {
+	if len(run) < 2 {
+		return
+	}
+
+	// The final attribute in a body might not end with a newline, in which
+	// case it would run into the following attribute if moved earlier.
+	last := run[len(run)-1].content.(*Attribute)
+	if tok := last.children.lastToken(); tok != nil && !tokenEndsWithNewline(tok) {
+		last.children.AppendUnstructuredTokens(newlineTokens())
+	}
+
+	attrs := make([]*Attribute, len(run))
+	names := make(map[*Attribute]string, len(run))
+	for i, n := range run {
+		attr := n.content.(*Attribute)
+		attrs[i] = attr
+		names[attr] = string(attr.name.content.(*identifier).token.Bytes)
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		return names[attrs[i]] < names[attrs[j]]
+	})
+	for i, n := range run {
+		n.content = attrs[i]
+	}
+}
+
+// RenameVariablePrefix calls Expression.RenameVariablePrefix with the given
+// arguments for the expression of every attribute in the receiving body and
+// in the bodies of all of its nested blocks, recursively.
+//
+// This can be used to rename references to a symbol throughout a file while
+// preserving its formatting and comments. As with RenameVariablePrefix
+// itself, only whole names at the start of an absolute traversal are
+// matched, and any remaining steps of a matching traversal are left
+// unchanged.
+func (b *Body) RenameVariablePrefix(search, replacement []string) {
+	for _, attr := range b.Attributes() {
+		attr.Expr().RenameVariablePrefix(search, replacement)
+	}
+	for _, block := range b.Blocks() {
+		block.Body().RenameVariablePrefix(search, replacement)
+	}
+}
+
 // AppendBlock appends an existing block (which must not be already attached
 // to a body) to the end of the receiving body.
 func (b *Body) AppendBlock(block *Block) *Block {
@@ -229,14 +493,59 @@ func (b *Body) AppendNewBlock(typeName string, labels []string) *Block {
 	return block
 }
 
+// InsertBlockBefore inserts an existing block (which must not be already
+// attached to a body) into the receiving body immediately before the given
+// position block, separated from it by a blank line.
+//
+// If pos is not a block in the receiving body then this is a no-op.
+//
+// Returns true if it inserted the block, or false otherwise.
+func (b *Body) InsertBlockBefore(pos *Block, block *Block) bool {
+	posNode := b.items.FindNodeWithContent(pos)
+	if posNode == nil {
+		return false
+	}
+
+	nn := newNode(block)
+	b.children.InsertNode(posNode, nn)
+	b.items.Add(nn)
+	b.children.Insert(posNode, newlineTokens())
+	return true
+}
+
+// InsertBlockAfter inserts an existing block (which must not be already
+// attached to a body) into the receiving body immediately after the given
+// position block, separated from it by a blank line.
+//
+// If pos is not a block in the receiving body then this is a no-op.
+//
+// Returns true if it inserted the block, or false otherwise.
+func (b *Body) InsertBlockAfter(pos *Block, block *Block) bool {
+	posNode := b.items.FindNodeWithContent(pos)
+	if posNode == nil {
+		return false
+	}
+
+	next := posNode.after
+	b.children.Insert(next, newlineTokens())
+	nn := newNode(block)
+	b.children.InsertNode(next, nn)
+	b.items.Add(nn)
+	return true
+}
+
 // AppendNewline appends a newline token to th end of the receiving body,
 // which generally serves as a separator between different sets of body
 // contents.
 func (b *Body) AppendNewline() {
-	b.AppendUnstructuredTokens(Tokens{
+	b.AppendUnstructuredTokens(newlineTokens())
+}
+
+func newlineTokens() Tokens {
+	return Tokens{
 		{
 			Type:  hclsyntax.TokenNewline,
 			Bytes: []byte{'\n'},
 		},
-	})
+	}
 }
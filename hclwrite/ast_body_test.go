@@ -345,6 +345,60 @@ parent {
 	}
 }
 
+func TestBodyMatchingBlocks(t *testing.T) {
+	src := `service "a" {
+  n = 1
+}
+other "a" {
+  n = 2
+}
+service "b" {
+  n = 3
+}
+service "a" {
+  n = 4
+}
+service "a" "b" {
+  n = 5
+}
+service "\u0061" {
+  n = 6
+}
+`
+	f, diags := ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		for _, diag := range diags {
+			t.Logf("- %s", diag.Error())
+		}
+		t.Fatalf("unexpected diagnostics")
+	}
+
+	ns := func(blocks []*Block) []string {
+		var ret []string
+		for _, block := range blocks {
+			ret = append(ret, strings.TrimSpace(string(block.Body().GetAttribute("n").Expr().BuildTokens(nil).Bytes())))
+		}
+		return ret
+	}
+
+	body := f.Body()
+	if got, want := ns(body.MatchingBlocks("service", []string{"a"})), []string{"1", "4", "6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong matching blocks\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := ns(body.MatchingBlocks("service", []string{"a", "b"})), []string{"5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong matching blocks with two labels\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := body.MatchingBlocks("service", nil); got != nil {
+		t.Errorf("unexpected matching blocks without labels: %#v", ns(got))
+	}
+	if got, want := ns(body.BlocksOfType("service")), []string{"1", "3", "4", "5", "6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong blocks of type\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := body.BlocksOfType("missing"); got != nil {
+		t.Errorf("unexpected blocks of missing type: %#v", ns(got))
+	}
+}
+
 func TestBodySetAttributeValue(t *testing.T) {
 	tests := []struct {
 		src  string
@@ -543,6 +597,33 @@ func TestBodySetAttributeValue(t *testing.T) {
 	}
 }
 
+func TestBodySetAttributeValueFunc(t *testing.T) {
+	f := NewEmptyFile()
+	body := f.Body()
+	body.SetAttributeValue("a", cty.True)
+
+	_, err := body.SetAttributeValueFunc("a", cty.UnknownVal(cty.Bool), nil)
+	if _, ok := err.(*UnrepresentableValueError); !ok {
+		t.Fatalf("wrong error %#v; want *UnrepresentableValueError", err)
+	}
+	if got, want := string(f.Bytes()), "a = true\n"; got != want {
+		t.Errorf("body was modified after error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	attr, err := body.SetAttributeValueFunc("b", cty.UnknownVal(cty.Bool), func(cty.Value) (Tokens, error) {
+		return TokensForIdentifier("placeholder"), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if attr == nil || attr != body.GetAttribute("b") {
+		t.Errorf("did not return the new attribute")
+	}
+	if got, want := string(f.Bytes()), "a = true\nb = placeholder\n"; got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func TestBodySetAttributeTraversal(t *testing.T) {
 	tests := []struct {
 		src  string
@@ -933,6 +1014,42 @@ func TestBodySetAttributeRaw(t *testing.T) {
 	}
 }
 
+func TestBodySetAttributeResult(t *testing.T) {
+	tests := map[string]func(b *Body, name string) *Attribute{
+		"SetAttributeValue": func(b *Body, name string) *Attribute {
+			return b.SetAttributeValue(name, cty.True)
+		},
+		"SetAttributeTraversal": func(b *Body, name string) *Attribute {
+			return b.SetAttributeTraversal(name, hcl.Traversal{hcl.TraverseRoot{Name: "foo"}})
+		},
+		"SetAttributeRaw": func(b *Body, name string) *Attribute {
+			return b.SetAttributeRaw(name, TokensForValue(cty.True))
+		},
+	}
+
+	for method, set := range tests {
+		for _, name := range []string{"existing", "new"} {
+			t.Run(fmt.Sprintf("%s %s", method, name), func(t *testing.T) {
+				f, diags := ParseConfig([]byte("existing = 1\n"), "", hcl.Pos{Line: 1, Column: 1})
+				if len(diags) != 0 {
+					for _, diag := range diags {
+						t.Logf("- %s", diag.Error())
+					}
+					t.Fatalf("unexpected diagnostics")
+				}
+
+				got := set(f.Body(), name)
+				if got == nil {
+					t.Fatalf("%s returned nil", method)
+				}
+				if want := f.Body().GetAttribute(name); got != want {
+					t.Errorf("%s returned an attribute other than the one it set", method)
+				}
+			})
+		}
+	}
+}
+
 func TestBodySetAttributeValueInBlock(t *testing.T) {
 	src := `service "label1" {
   attr1 = "val1"
@@ -1590,3 +1707,577 @@ bar {}
 	}
 
 }
+
+func TestBodyInsertBlock(t *testing.T) {
+	src := strings.TrimSpace(`
+a = 1
+
+# Foo
+foo {
+  b = 1
+}
+
+bar {
+  b = 2
+}
+`) + "\n"
+
+	tests := map[string]struct {
+		insert func(body *Body) bool
+		want   string
+	}{
+		"before first": {
+			func(body *Body) bool {
+				return body.InsertBlockBefore(body.FirstMatchingBlock("foo", nil), NewBlock("baz", []string{"a"}))
+			},
+			`a = 1
+
+baz "a" {
+}
+
+# Foo
+foo {
+  b = 1
+}
+
+bar {
+  b = 2
+}
+`,
+		},
+		"before last": {
+			func(body *Body) bool {
+				return body.InsertBlockBefore(body.FirstMatchingBlock("bar", nil), NewBlock("baz", nil))
+			},
+			`a = 1
+
+# Foo
+foo {
+  b = 1
+}
+
+baz {
+}
+
+bar {
+  b = 2
+}
+`,
+		},
+		"after first": {
+			func(body *Body) bool {
+				return body.InsertBlockAfter(body.FirstMatchingBlock("foo", nil), NewBlock("baz", nil))
+			},
+			`a = 1
+
+# Foo
+foo {
+  b = 1
+}
+
+baz {
+}
+
+bar {
+  b = 2
+}
+`,
+		},
+		"after last": {
+			func(body *Body) bool {
+				return body.InsertBlockAfter(body.FirstMatchingBlock("bar", nil), NewBlock("baz", nil))
+			},
+			`a = 1
+
+# Foo
+foo {
+  b = 1
+}
+
+bar {
+  b = 2
+}
+
+baz {
+}
+`,
+		},
+		"position not in body": {
+			func(body *Body) bool {
+				return body.InsertBlockBefore(NewBlock("other", nil), NewBlock("baz", nil))
+			},
+			src,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+
+			wantOK := test.want != src
+			gotOK := test.insert(f.Body())
+			if gotOK != wantOK {
+				t.Errorf("wrong return value %#v; want %#v", gotOK, wantOK)
+			}
+
+			got := string(f.Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyRemoveBlockCleaningWhitespace(t *testing.T) {
+	tests := map[string]struct {
+		src    string
+		remove func(body *Body) (*Body, *Block)
+		want   string
+	}{
+		"middle": {
+			"a = 1\n\nfoo {\n}\n\nbar {\n}\n",
+			func(body *Body) (*Body, *Block) {
+				return body, body.FirstMatchingBlock("foo", nil)
+			},
+			"a = 1\n\nbar {\n}\n",
+		},
+		"middle without blank line before": {
+			"a = 1\nfoo {\n}\n\nbar {\n}\n",
+			func(body *Body) (*Body, *Block) {
+				return body, body.FirstMatchingBlock("foo", nil)
+			},
+			"a = 1\n\nbar {\n}\n",
+		},
+		"first": {
+			"foo {\n}\n\nbar {\n}\n",
+			func(body *Body) (*Body, *Block) {
+				return body, body.FirstMatchingBlock("foo", nil)
+			},
+			"bar {\n}\n",
+		},
+		"last": {
+			"a = 1\n\nfoo {\n}\n\n\nbar {\n}\n",
+			func(body *Body) (*Body, *Block) {
+				return body, body.FirstMatchingBlock("bar", nil)
+			},
+			"a = 1\n\nfoo {\n}\n",
+		},
+		"only": {
+			"foo {\n}\n\n",
+			func(body *Body) (*Body, *Block) {
+				return body, body.FirstMatchingBlock("foo", nil)
+			},
+			"",
+		},
+		"only in nested body": {
+			"outer {\n  foo {\n  }\n\n}\n",
+			func(body *Body) (*Body, *Block) {
+				outer := body.FirstMatchingBlock("outer", nil).Body()
+				return outer, outer.FirstMatchingBlock("foo", nil)
+			},
+			"outer {\n}\n",
+		},
+		"first in nested body": {
+			"outer {\n  foo {\n  }\n\n  a = 1\n}\n",
+			func(body *Body) (*Body, *Block) {
+				outer := body.FirstMatchingBlock("outer", nil).Body()
+				return outer, outer.FirstMatchingBlock("foo", nil)
+			},
+			"outer {\n  a = 1\n}\n",
+		},
+		"comment is kept": {
+			"a = 1\n\n# about foo\n\nfoo {\n}\n\nbar {\n}\n",
+			func(body *Body) (*Body, *Block) {
+				return body, body.FirstMatchingBlock("foo", nil)
+			},
+			"a = 1\n\n# about foo\n\nbar {\n}\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+
+			body, block := test.remove(f.Body())
+			if !body.RemoveBlockCleaningWhitespace(block) {
+				t.Fatalf("block was not removed")
+			}
+			if body.RemoveBlockCleaningWhitespace(block) {
+				t.Errorf("block was removed a second time")
+			}
+
+			got := string(f.BuildTokens(nil).Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%q\nwant:\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyRemoveAttributeCleaningWhitespace(t *testing.T) {
+	tests := map[string]struct {
+		src    string
+		remove string
+		want   string
+	}{
+		"alone in middle group": {
+			"a = 1\nb = 2\n\n# about c\nc = 3\n\nd = 4\n",
+			"c",
+			"a = 1\nb = 2\n\nd = 4\n",
+		},
+		"within group": {
+			"a = 1\nb = 2\n\nc = 3\n",
+			"b",
+			"a = 1\n\nc = 3\n",
+		},
+		"alone in last group": {
+			"a = 1\n\nb = 2\n",
+			"b",
+			"a = 1\n",
+		},
+		"alone in first group": {
+			"a = 1\n\nb = 2\n",
+			"a",
+			"b = 2\n",
+		},
+		"nonexistent": {
+			"a = 1\n\nb = 2\n",
+			"c",
+			"a = 1\n\nb = 2\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+
+			body := f.Body()
+			wantRemoved := body.GetAttribute(test.remove)
+			if got := body.RemoveAttributeCleaningWhitespace(test.remove); got != wantRemoved {
+				t.Errorf("wrong attribute returned %#v; want %#v", got, wantRemoved)
+			}
+
+			got := string(f.Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%q\nwant:\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyRemoveAttributeComments(t *testing.T) {
+	tests := map[string]struct {
+		src         string
+		remove      string
+		want        string
+		wantRemoved bool
+	}{
+		"leading comment": {
+			"a = 1\n# about b\n// more about b\nb = 2\nc = 3\n",
+			"b",
+			"a = 1\nc = 3\n",
+			true,
+		},
+		"line comment": {
+			"a = 1\nb = 2 # about b\nc = 3\n",
+			"b",
+			"a = 1\nc = 3\n",
+			true,
+		},
+		"detached comment": {
+			"a = 1\n# about the file\n\nb = 2\n",
+			"b",
+			"a = 1\n# about the file\n\n",
+			true,
+		},
+		"nonexistent": {
+			"# about a\na = 1\n",
+			"b",
+			"# about a\na = 1\n",
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+
+			removed := f.Body().RemoveAttribute(test.remove)
+			if got := removed != nil; got != test.wantRemoved {
+				t.Errorf("wrong removed attribute %#v; want removed %t", removed, test.wantRemoved)
+			}
+
+			got := string(f.Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%q\nwant:\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyEditsPreserveBlankLines(t *testing.T) {
+	src := `# Network settings
+address = "10.0.0.1"
+port    = 80
+
+
+# Limits
+max_conns = 10
+timeout   = 30
+
+service {
+  name = "web"
+
+  replicas = 2
+}
+`
+	f, diags := ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	body := f.Body()
+	body.SetAttributeValue("port", cty.NumberIntVal(8080))
+	body.SetAttributeValue("timeout", cty.NumberIntVal(60))
+	body.FirstMatchingBlock("service", nil).Body().SetAttributeValue("replicas", cty.NumberIntVal(3))
+
+	want := `# Network settings
+address = "10.0.0.1"
+port    = 8080
+
+
+# Limits
+max_conns = 10
+timeout   = 60
+
+service {
+  name = "web"
+
+  replicas = 3
+}
+`
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBodyLineCommentsPreserved(t *testing.T) {
+	tests := map[string]struct {
+		src    string
+		modify func(body *Body)
+		want   string
+	}{
+		"change another attribute's value": {
+			`count = 3 # number of instances
+name  = "a"
+`,
+			func(body *Body) {
+				body.SetAttributeValue("name", cty.StringVal("b"))
+			},
+			`count = 3 # number of instances
+name  = "b"
+`,
+		},
+		"change another attribute in a nested block": {
+			`count = 3 # number of instances
+
+thing {
+  name = "a" // the name
+}
+`,
+			func(body *Body) {
+				body.FirstMatchingBlock("thing", nil).Body().SetAttributeValue("name", cty.StringVal("bbbbbbb"))
+			},
+			`count = 3 # number of instances
+
+thing {
+  name = "bbbbbbb" // the name
+}
+`,
+		},
+		"remove another attribute": {
+			`name  = "a"
+count = 3 # number of instances
+`,
+			func(body *Body) {
+				body.RemoveAttribute("name")
+			},
+			`count = 3 # number of instances
+`,
+		},
+		"append after single-line comment at EOF": {
+			`count = 3 # number of instances`,
+			func(body *Body) {
+				body.SetAttributeValue("name", cty.StringVal("a"))
+			},
+			`count = 3 # number of instances
+name  = "a"
+`,
+		},
+		"append after multi-line comment at EOF": {
+			`count = 3 /* number of instances */`,
+			func(body *Body) {
+				body.AppendNewBlock("thing", nil)
+			},
+			`count = 3 /* number of instances */
+thing {
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+
+			test.modify(f.Body())
+
+			got := string(f.Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyRenameVariablePrefix(t *testing.T) {
+	src := `# Comment
+a = aws_instance.old.id # trailing
+b = [aws_instance.old[0], aws_instance.older.id, aws_instance_old.id]
+
+resource "x" "y" {
+  c = "${aws_instance.old.arn}-suffix"
+  nested {
+    d = max(aws_instance.old.count,   1)
+  }
+}
+`
+	want := `# Comment
+a = aws_instance.new.id # trailing
+b = [aws_instance.new[0], aws_instance.older.id, aws_instance_old.id]
+
+resource "x" "y" {
+  c = "${aws_instance.new.arn}-suffix"
+  nested {
+    d = max(aws_instance.new.count,   1)
+  }
+}
+`
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	f.Body().RenameVariablePrefix(
+		[]string{"aws_instance", "old"},
+		[]string{"aws_instance", "new"},
+	)
+
+	if got := string(f.BuildTokens(nil).Bytes()); got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBodySortAttributes(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"empty": {
+			``,
+			``,
+		},
+		"simple": {
+			"c = 3\na = 1\nb = 2\n",
+			"a = 1\nb = 2\nc = 3\n",
+		},
+		"comments move with their attributes": {
+			`# About c
+c = 3 # trailing c
+// About a
+a = 1
+b = 2 # trailing b
+`,
+			`// About a
+a = 1
+b = 2 # trailing b
+# About c
+c = 3 # trailing c
+`,
+		},
+		"groups separated by blank lines": {
+			"d = 4\nc = 3\n\nb = 2\na = 1\n",
+			"c = 3\nd = 4\n\na = 1\nb = 2\n",
+		},
+		"detached comments stay in place": {
+			"b = 2\n\n# Detached\n\na = 1\n",
+			"b = 2\n\n# Detached\n\na = 1\n",
+		},
+		"blocks stay in place": {
+			"z = 1\ny = 2\nblock {\n  d = 1\n  c = 2\n}\nx = 3\nw = 4\n",
+			"y = 2\nz = 1\nblock {\n  d = 1\n  c = 2\n}\nw = 4\nx = 3\n",
+		},
+		"no trailing newline": {
+			"b = 2\na = 1",
+			"a = 1\nb = 2\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			f.Body().SortAttributes()
+			if got := string(f.BuildTokens(nil).Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("generated", func(t *testing.T) {
+		f := NewEmptyFile()
+		body := f.Body()
+		for _, name := range []string{"mango", "apple", "kiwi"} {
+			body.SetAttributeValue(name, cty.StringVal(name))
+		}
+		body.SortAttributes()
+		body.SetAttributeValue("kiwi", cty.True)
+		got := string(f.Bytes())
+		want := "apple = \"apple\"\nkiwi  = true\nmango = \"mango\"\n"
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
@@ -4,8 +4,12 @@
 package hclwrite
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
+	"unicode"
 
+	"github.com/apparentlymart/go-textseg/v15/textseg"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
@@ -67,6 +71,19 @@ func NewExpressionLiteral(val cty.Value) *Expression {
 	return expr
 }
 
+// NewExpressionLiteralFunc is like NewExpressionLiteral except that it uses
+// TokensForValueFunc with the given fallback, and so returns an error rather
+// than panicking if the value cannot be represented in source code.
+func NewExpressionLiteralFunc(val cty.Value, fallback ValueTokensFunc) (*Expression, error) {
+	toks, err := TokensForValueFunc(val, fallback)
+	if err != nil {
+		return nil, err
+	}
+	expr := newExpression()
+	expr.children.AppendUnstructuredTokens(toks)
+	return expr, nil
+}
+
 // NewExpressionAbsTraversal constructs an expression that represents the
 // given traversal, which must be absolute or this function will panic.
 func NewExpressionAbsTraversal(traversal hcl.Traversal) *Expression {
@@ -127,6 +144,33 @@ func NewExpressionAbsTraversal(traversal hcl.Traversal) *Expression {
 	return expr
 }
 
+// BuildTokens appends the tokens that make up the receiving expression to
+// the given slice, which may be nil, and returns the result.
+//
+// The appended tokens are the ones held in the syntax tree, and so modifying
+// them modifies the expression. Use Tokens instead to obtain tokens for
+// analysis that can't affect the expression.
+func (e *Expression) BuildTokens(to Tokens) Tokens {
+	return e.inTree.BuildTokens(to)
+}
+
+// Tokens returns a copy of the sequence of tokens that make up the receiving
+// expression, exactly as they would be written into the source file, which
+// can be used for lightweight analysis of the expression without parsing it
+// again.
+//
+// The first token retains the spacing that separates it from whatever
+// precedes the expression, such as the equals sign of an attribute.
+func (e *Expression) Tokens() Tokens {
+	toks := e.BuildTokens(nil)
+	for i, tok := range toks {
+		newTok := *tok
+		newTok.Bytes = append([]byte(nil), tok.Bytes...)
+		toks[i] = &newTok
+	}
+	return toks
+}
+
 // Variables returns the absolute traversals that exist within the receiving
 // expression.
 func (e *Expression) Variables() []*Traversal {
@@ -225,3 +269,307 @@ func newTraverseIndex() *TraverseIndex {
 		inTree: newInTree(),
 	}
 }
+
+// ConvertToHeredoc rewrites the receiving expression, which must be a quoted
+// template string such as "Hello, ${name}!\n", as an equivalent heredoc
+// template that uses the given terminator as its closing marker:
+//
+//	<<EOT
+//	Hello, ${name}!
+//	EOT
+//
+// Only the tokens of the literal parts of the template are changed, so any
+// interpolation and directive sequences are preserved exactly, along with
+// the variables they refer to. The heredoc is not indented, so that the
+// lines of its content are exactly those of the original string.
+//
+// The value of a heredoc always ends with a newline, so the template must
+// end with literal text that ends with a newline. The text must also not
+// contain carriage returns or any other non-printable characters except
+// tabs, since a heredoc cannot represent them unambiguously. An error is
+// returned, and the expression is left unchanged, if it does not meet these
+// requirements, if the terminator is not a valid identifier, or if any line
+// of the text consists only of the terminator and so would be taken as the
+// closing marker. Strip markers, such as in "${~ name ~}", trim whitespace
+// from individual literal tokens, which are split differently in quoted and
+// heredoc templates, so an error is also returned if the template uses them
+// in a way that would trim differently after conversion.
+//
+// A heredoc's closing marker must be alone on its line, so the caller must
+// ensure that nothing else follows the expression on the same line, such as
+// a comment.
+func (e *Expression) ConvertToHeredoc(terminator string) error {
+	if !hclsyntax.ValidIdentifier(terminator) {
+		return fmt.Errorf("invalid heredoc terminator %q: must be a valid identifier", terminator)
+	}
+	toks := e.BuildTokens(nil)
+	lits, err := templateLiteralTokens(toks, hclsyntax.TokenOQuote, hclsyntax.TokenCQuote)
+	if err != nil {
+		return err
+	}
+	if len(lits) == 0 || lits[len(lits)-1] != len(toks)-2 {
+		return fmt.Errorf("template must end with a newline to be written as a heredoc")
+	}
+
+	vals := make([]string, len(lits))
+	var all strings.Builder
+	line, pure := "", true
+	for i, idx := range lits {
+		val, diags := hclsyntax.ParseStringLiteralToken(hclsyntax.Token{
+			Type:  toks[idx].Type,
+			Bytes: toks[idx].Bytes,
+		})
+		if diags.HasErrors() {
+			return fmt.Errorf("invalid template literal %q: %s", toks[idx].Bytes, diags.Error())
+		}
+		vals[i] = val
+		all.WriteString(val)
+
+		// A line made only of literal text would be taken as the closing
+		// marker if it contains only the terminator and spaces.
+		if i > 0 && lits[i-1] != idx-1 {
+			pure = false
+		}
+		for {
+			nl := strings.IndexByte(val, '\n')
+			if nl < 0 {
+				line += val
+				break
+			}
+			line += val[:nl]
+			if pure && strings.TrimSpace(line) == terminator {
+				return fmt.Errorf("template contains a line %q that would be ambiguous with the heredoc terminator", terminator)
+			}
+			line, pure = "", true
+			val = val[nl+1:]
+		}
+	}
+	if !strings.HasSuffix(all.String(), "\n") {
+		return fmt.Errorf("template must end with a newline to be written as a heredoc")
+	}
+	if !heredocCanRepresent(all.String()) {
+		return fmt.Errorf("template contains non-printable characters that cannot be written in a heredoc")
+	}
+
+	orig := saveTokens(toks)
+	toks[0].Type = hclsyntax.TokenOHeredoc
+	toks[0].Bytes = []byte("<<" + terminator + "\n")
+	for i, idx := range lits {
+		toks[idx].Type = hclsyntax.TokenStringLit
+		toks[idx].Bytes = escapeTemplateLit(escapeHeredocLit, vals, lits, i)
+	}
+	last := toks[len(toks)-1]
+	last.Type = hclsyntax.TokenCHeredoc
+	last.Bytes = []byte(terminator)
+	last.SpacesBefore = 0
+	return checkTemplateConversion(toks, orig)
+}
+
+// ConvertToQuoted rewrites the receiving expression, which must be a heredoc
+// template, as an equivalent quoted template string, such as
+// "Hello, ${name}!\n".
+//
+// As with ConvertToHeredoc, only the tokens of the literal parts of the
+// template are changed. If the heredoc is indented, with the <<- introducer,
+// then the leading whitespace that it would remove from each line is
+// removed from the literal text, so that the value of the template is
+// unchanged.
+//
+// An error is returned, and the expression is left unchanged, if the
+// expression is not a heredoc template or if, as described for
+// ConvertToHeredoc, its strip markers would trim differently after
+// conversion.
+func (e *Expression) ConvertToQuoted() error {
+	toks := e.BuildTokens(nil)
+	lits, err := templateLiteralTokens(toks, hclsyntax.TokenOHeredoc, hclsyntax.TokenCHeredoc)
+	if err != nil {
+		return err
+	}
+
+	vals := make([]string, len(lits))
+	for i, idx := range lits {
+		val, diags := hclsyntax.ParseStringLiteralToken(hclsyntax.Token{
+			Type:  toks[idx].Type,
+			Bytes: toks[idx].Bytes,
+		})
+		if diags.HasErrors() {
+			return fmt.Errorf("invalid template literal %q: %s", toks[idx].Bytes, diags.Error())
+		}
+		vals[i] = val
+	}
+	if bytes.HasPrefix(toks[0].Bytes, []byte("<<-")) {
+		flushHeredocLiterals(lits, vals)
+	}
+
+	orig := saveTokens(toks)
+	toks[0].Type = hclsyntax.TokenOQuote
+	toks[0].Bytes = []byte{'"'}
+	for i, idx := range lits {
+		toks[idx].Type = hclsyntax.TokenQuotedLit
+		toks[idx].Bytes = escapeTemplateLit(escapeQuotedStringLit, vals, lits, i)
+	}
+	last := toks[len(toks)-1]
+	last.Type = hclsyntax.TokenCQuote
+	last.Bytes = []byte{'"'}
+	last.SpacesBefore = 0
+	return checkTemplateConversion(toks, orig)
+}
+
+// saveTokens returns a copy of the given tokens, for restoring them with
+// checkTemplateConversion if a conversion fails.
+func saveTokens(toks Tokens) []Token {
+	ret := make([]Token, len(toks))
+	for i, tok := range toks {
+		ret[i] = *tok
+	}
+	return ret
+}
+
+// checkTemplateConversion verifies that the given converted template tokens
+// have the same literal text as the original tokens when parsed, and if not
+// restores the original tokens and returns an error.
+//
+// The conversions change only the syntax of the literal text, so this
+// catches only differences caused by strip markers.
+func checkTemplateConversion(toks Tokens, orig []Token) error {
+	origToks := make(Tokens, len(orig))
+	for i := range orig {
+		origToks[i] = &orig[i]
+	}
+	want, wantErr := templateLiteralValues(origToks)
+	got, gotErr := templateLiteralValues(toks)
+	if wantErr == nil && gotErr == nil && len(got) == len(want) {
+		same := true
+		for i := range got {
+			if !got[i].RawEquals(want[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return nil
+		}
+	}
+
+	for i, tok := range toks {
+		*tok = orig[i]
+	}
+	return fmt.Errorf("template cannot be converted without changing its value, due to its use of whitespace strip markers")
+}
+
+// templateLiteralValues parses the given tokens as an expression and returns
+// the values of all of the literal expressions within it, in order.
+func templateLiteralValues(toks Tokens) ([]cty.Value, error) {
+	src := append(toks.Bytes(), '\n')
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var vals []cty.Value
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if lit, ok := node.(*hclsyntax.LiteralValueExpr); ok {
+			vals = append(vals, lit.Val)
+		}
+		return nil
+	})
+	return vals, nil
+}
+
+// templateLiteralTokens checks that the given tokens are a single template
+// delimited by the given opening and closing token types, and returns the
+// indices of the tokens of its literal parts, in order. Tokens within
+// interpolation and directive sequences are not included.
+func templateLiteralTokens(toks Tokens, open, close hclsyntax.TokenType) ([]int, error) {
+	if len(toks) < 2 || toks[0].Type != open {
+		return nil, fmt.Errorf("expression is not a %s template", templateKindName(open))
+	}
+	var lits []int
+	depth := 0
+	for i := 1; i < len(toks); i++ {
+		switch tok := toks[i]; {
+		case tok.Type == hclsyntax.TokenTemplateInterp || tok.Type == hclsyntax.TokenTemplateControl:
+			depth++
+		case tok.Type == hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		case depth == 0 && tok.Type == close:
+			if i != len(toks)-1 {
+				return nil, fmt.Errorf("expression is not a %s template", templateKindName(open))
+			}
+			return lits, nil
+		case depth == 0:
+			lits = append(lits, i)
+		}
+	}
+	return nil, fmt.Errorf("expression is not a %s template", templateKindName(open))
+}
+
+func templateKindName(open hclsyntax.TokenType) string {
+	if open == hclsyntax.TokenOHeredoc {
+		return "heredoc"
+	}
+	return "quoted"
+}
+
+// escapeTemplateLit returns the value of the literal at index i in vals,
+// escaped using the given function. The literal tokens of a template are not
+// always split at escape sequences, so a template sequence introducer at the
+// end of one literal is escaped if the literal that immediately follows it
+// begins with a brace.
+func escapeTemplateLit(escape func(string) []byte, vals []string, lits []int, i int) []byte {
+	if i+1 < len(lits) && lits[i+1] == lits[i]+1 && strings.HasPrefix(vals[i+1], "{") {
+		buf := escape(vals[i] + "{")
+		return buf[:len(buf)-1]
+	}
+	return escape(vals[i])
+}
+
+// flushHeredocLiterals removes from the given literal values the leading
+// whitespace that hclsyntax removes when parsing an indented heredoc: the
+// smallest number of whitespace characters that begin any non-blank line.
+// A line that begins with a template sequence has no leading whitespace.
+func flushHeredocLiterals(lits []int, vals []string) {
+	const maxInt = int((^uint(0)) >> 1)
+
+	if len(lits) == 0 || lits[0] != 1 {
+		// A template sequence begins the first line
+		return
+	}
+
+	minSpaces := maxInt
+	newline := true
+	var adjust []int
+	for i, idx := range lits {
+		if i > 0 && lits[i-1] != idx-1 && newline {
+			// A template sequence begins the line
+			minSpaces = 0
+			newline = false
+		}
+		val := vals[i]
+		if newline {
+			newline = false
+			trimmed := strings.TrimLeftFunc(val, unicode.IsSpace)
+			// A line containing only spaces is blank, and so doesn't count.
+			if len(trimmed) != 0 || !strings.HasSuffix(val, "\n") {
+				spaceBytes := len(val) - len(trimmed)
+				spaces, _ := textseg.TokenCount([]byte(val[:spaceBytes]), textseg.ScanGraphemeClusters)
+				if spaces < minSpaces {
+					minSpaces = spaces
+				}
+				adjust = append(adjust, i)
+			}
+		}
+		if strings.HasSuffix(val, "\n") {
+			newline = true
+		}
+	}
+	for _, i := range adjust {
+		valBytes := []byte(vals[i])
+		spaceByteCount := 0
+		for n := 0; n < minSpaces && len(valBytes) > 0; n++ {
+			adv, _, _ := textseg.ScanGraphemeClusters(valBytes, true)
+			spaceByteCount += adv
+			valBytes = valBytes[adv:]
+		}
+		vals[i] = vals[i][spaceByteCount:]
+	}
+}
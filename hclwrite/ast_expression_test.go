@@ -0,0 +1,236 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExpressionConvertTemplate(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		heredoc   bool // if true, convert to a heredoc with terminator EOT
+		want      string
+		wantError string
+	}{
+		"to heredoc": {
+			`a = "Hello, ${name}!\n"` + "\n",
+			true,
+			"a = <<EOT\nHello, ${name}!\nEOT\n",
+			``,
+		},
+		"to heredoc with escapes": {
+			`a = "\"$${x}\"\t\\n\n%%{y}\u00e9\n"` + "\n",
+			true,
+			"a = <<EOT\n\"$${x}\"\t\\n\n%%{y}é\nEOT\n",
+			``,
+		},
+		"to heredoc with directives": {
+			`a = "%{for x in xs~}\n${x}\n%{endfor~}\n"` + "\n",
+			true,
+			"a = <<EOT\n%{for x in xs~}\n${x}\n%{endfor~}\nEOT\n",
+			``,
+		},
+		"to heredoc with nested template": {
+			`a = "${x ? "EOT\n" : ""}\n"` + "\n",
+			true,
+			"a = <<EOT\n${x ? \"EOT\\n\" : \"\"}\nEOT\n",
+			``,
+		},
+		"to heredoc with terminator in line": {
+			`a = "EOT${x}\nEOTS\n"` + "\n",
+			true,
+			"a = <<EOT\nEOT${x}\nEOTS\nEOT\n",
+			``,
+		},
+		"to heredoc in block": {
+			"b {\n  a = \"x\\n\"\n}\n",
+			true,
+			"b {\n  a = <<EOT\nx\nEOT\n}\n",
+			``,
+		},
+		"to heredoc with ambiguous terminator": {
+			`a = "a\n  EOT\nb\n"` + "\n",
+			true,
+			"",
+			`template contains a line "EOT" that would be ambiguous with the heredoc terminator`,
+		},
+		"to heredoc without trailing newline": {
+			`a = "a\nb"` + "\n",
+			true,
+			"",
+			`template must end with a newline to be written as a heredoc`,
+		},
+		"to heredoc ending with interpolation": {
+			`a = "a\n${b}"` + "\n",
+			true,
+			"",
+			`template must end with a newline to be written as a heredoc`,
+		},
+		"to heredoc with carriage return": {
+			`a = "a\r\n"` + "\n",
+			true,
+			"",
+			`template contains non-printable characters that cannot be written in a heredoc`,
+		},
+		"to heredoc from heredoc": {
+			"a = <<EOT\nx\nEOT\n",
+			true,
+			"",
+			`expression is not a quoted template`,
+		},
+		"to heredoc from operation": {
+			`a = "a\n" == "b\n"` + "\n",
+			true,
+			"",
+			`expression is not a quoted template`,
+		},
+		"to quoted": {
+			"a = <<EOT\nHello, ${name}!\n\"$${x}\" \\n\nEOT\n",
+			false,
+			`a = "Hello, ${name}!\n\"$${x}\" \\n\n"` + "\n",
+			``,
+		},
+		"to quoted from indented": {
+			"a = <<-EOT\n    if ${x}; then\n\n      echo $${y}\n    fi\n  EOT\n",
+			false,
+			`a = "if ${x}; then\n\n  echo $${y}\nfi\n"` + "\n",
+			``,
+		},
+		"to quoted from indented with interpolated line": {
+			"a = <<-EOT\n  a\n${b}\n  EOT\n",
+			false,
+			`a = "  a\n${b}\n"` + "\n",
+			``,
+		},
+		"to quoted with strip markers": {
+			"a = <<-EOT\n  %{if x~}\n    a\n  %{~endif}\n  EOT\n",
+			false,
+			"",
+			`template cannot be converted without changing its value, due to its use of whitespace strip markers`,
+		},
+		"to quoted from quoted": {
+			`a = "x"` + "\n",
+			false,
+			"",
+			`expression is not a heredoc template`,
+		},
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+			"x":    cty.True,
+			"xs":   cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			"b":    cty.StringVal("   b"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			body := f.Body()
+			if blocks := body.Blocks(); len(blocks) > 0 {
+				body = blocks[0].Body()
+			}
+			expr := body.GetAttribute("a").Expr()
+			wantVars := len(expr.Variables())
+
+			var err error
+			if test.heredoc {
+				err = expr.ConvertToHeredoc("EOT")
+			} else {
+				err = expr.ConvertToQuoted()
+			}
+			if test.wantError != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error %q", test.wantError)
+				}
+				if got := err.Error(); got != test.wantError {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.wantError)
+				}
+				if got := string(f.Bytes()); got != test.src {
+					t.Fatalf("expression was modified\ngot:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := string(f.Bytes())
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+			if got := len(expr.Variables()); got != wantVars {
+				t.Errorf("wrong number of variables %d; want %d", got, wantVars)
+			}
+
+			// The converted expression must have the same value as the original.
+			if test.src[0] == 'b' {
+				return // the conversion in a block is covered by the other cases
+			}
+			wantVal := evalTestAttr(t, test.src, ctx)
+			gotVal := evalTestAttr(t, got, ctx)
+			if !gotVal.RawEquals(wantVal) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", gotVal, wantVal)
+			}
+		})
+	}
+}
+
+func evalTestAttr(t *testing.T, src string, ctx *hcl.EvalContext) cty.Value {
+	t.Helper()
+	f, diags := hclsyntax.ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics parsing\n%s\n%s", src, diags.Error())
+	}
+	attr := f.Body.(*hclsyntax.Body).Attributes["a"]
+	val, diags := attr.Expr.Value(ctx)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics evaluating\n%s\n%s", src, diags.Error())
+	}
+	return val
+}
+
+func TestExpressionTokens(t *testing.T) {
+	src := "a = upper(\"x\") # comment\n"
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	expr := f.Body().GetAttribute("a").Expr()
+
+	got := expr.Tokens()
+	want := Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("upper"), SpacesBefore: 1},
+		{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("x")},
+		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenCParen, Bytes: []byte(")")},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong tokens\n%s", diff)
+	}
+	if diff := cmp.Diff(got, expr.BuildTokens(nil)); diff != "" {
+		t.Errorf("Tokens and BuildTokens disagree\n%s", diff)
+	}
+
+	// The result is a copy, so modifying it must not modify the file.
+	got[0].Bytes[0] = 'U'
+	got[3].Bytes = []byte("y")
+	if got, want := string(f.Bytes()), src; got != want {
+		t.Errorf("file was modified\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AttributeEdit describes a change to a single attribute, for use with
+// ApplyAttributeEdits.
+type AttributeEdit struct {
+	// Path identifies the attribute to change. Each block containing the
+	// attribute is given by a step for its type followed by one step for
+	// each of its labels, and the final step is the attribute name. For
+	// example, the path resource.aws_instance.example.tags.Name refers to
+	// the Name attribute in the body of a "tags" block nested inside a
+	// block of type "resource" with the labels "aws_instance" and
+	// "example".
+	//
+	// Steps may be given either as attributes or as index steps with string
+	// keys, so that a label that is not a valid identifier can be written
+	// as resource["aws_instance"]["my example"]. Paths are usually obtained
+	// by calling hclsyntax.ParseTraversalAbs.
+	Path hcl.Traversal
+
+	// Value is the new value for the attribute, which is either replaced
+	// in-place or, if it is not already present, appended to the end of
+	// the body containing it. If Value is cty.NilVal then the attribute is
+	// removed instead.
+	Value cty.Value
+}
+
+// ApplyAttributeEdits parses the given source code, makes the given changes
+// to the values of its attributes, and returns the updated source code.
+//
+// Only the expressions of the changed attributes are replaced, so comments
+// and formatting elsewhere in the file are preserved. This allows source
+// code that was analyzed with package hclsyntax to be updated based on the
+// result of that analysis, since the paths of attributes found during
+// analysis can be passed here along with the same source code.
+//
+// The edits are applied in the given order. If the source code has errors,
+// or if any edit refers to a block that does not exist or has a value that
+// cannot be represented in HCL, then the result is nil and the returned
+// diagnostics describe the problems.
+func ApplyAttributeEdits(src []byte, filename string, edits []AttributeEdit) ([]byte, hcl.Diagnostics) {
+	f, diags := ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, edit := range edits {
+		diags = append(diags, applyAttributeEdit(f.Body(), edit)...)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return f.Bytes(), diags
+}
+
+func applyAttributeEdit(body *Body, edit AttributeEdit) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	names := make([]string, len(edit.Path))
+	for i, step := range edit.Path {
+		switch ts := step.(type) {
+		case hcl.TraverseRoot:
+			names[i] = ts.Name
+			continue
+		case hcl.TraverseAttr:
+			names[i] = ts.Name
+			continue
+		case hcl.TraverseIndex:
+			if ts.Key.Type() == cty.String && ts.Key.IsKnown() && !ts.Key.IsNull() {
+				names[i] = ts.Key.AsString()
+				continue
+			}
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attribute path",
+			Detail:   "Each step of an attribute path must be a name or a string index.",
+			Subject:  step.SourceRange().Ptr(),
+		})
+		return diags
+	}
+	if len(names) == 0 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attribute path",
+			Detail:   "An attribute path must include at least the attribute name.",
+		})
+		return diags
+	}
+
+	body = findEditBody(body, names[:len(names)-1])
+	if body == nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Block not found",
+			Detail:   fmt.Sprintf("There is no block matching %s to contain the attribute %q.", strings.Join(names[:len(names)-1], "."), names[len(names)-1]),
+			Subject:  edit.Path.SourceRange().Ptr(),
+		})
+		return diags
+	}
+
+	name := names[len(names)-1]
+	if edit.Value == cty.NilVal {
+		body.RemoveAttribute(name)
+		return diags
+	}
+	if _, err := body.SetAttributeValueFunc(name, edit.Value, nil); err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable attribute value",
+			Detail:   fmt.Sprintf("Cannot set %s: %s.", strings.Join(names, "."), err),
+			Subject:  edit.Path.SourceRange().Ptr(),
+		})
+	}
+	return diags
+}
+
+// findEditBody returns the body of the first nested block matching the given
+// sequence of block types and labels, or nil if there is no such block.
+func findEditBody(body *Body, names []string) *Body {
+	if len(names) == 0 {
+		return body
+	}
+	for _, block := range body.Blocks() {
+		if block.Type() != names[0] {
+			continue
+		}
+		labels := block.Labels()
+		if len(labels) > len(names)-1 {
+			continue
+		}
+		match := true
+		for i, label := range labels {
+			if names[i+1] != label {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if ret := findEditBody(block.Body(), names[len(labels)+1:]); ret != nil {
+			return ret
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestApplyAttributeEdits(t *testing.T) {
+	src := `# Leading file comment
+a = 1 # trailing a
+
+service "web" "front end" {
+  # The port to listen on
+  port = 80
+  tags {
+    env = "dev"
+  }
+}
+
+service "db" {
+  port = 5432
+}
+`
+
+	path := func(s string) hcl.Traversal {
+		t.Helper()
+		traversal, diags := hclsyntax.ParseTraversalAbs([]byte(s), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("invalid path %q: %s", s, diags.Error())
+		}
+		return traversal
+	}
+
+	tests := map[string]struct {
+		edits     []AttributeEdit
+		want      string
+		wantDiags []string
+	}{
+		"no edits": {
+			nil,
+			src,
+			nil,
+		},
+		"replace top-level": {
+			[]AttributeEdit{
+				{Path: path("a"), Value: cty.NumberIntVal(2)},
+			},
+			`# Leading file comment
+a = 2 # trailing a
+
+service "web" "front end" {
+  # The port to listen on
+  port = 80
+  tags {
+    env = "dev"
+  }
+}
+
+service "db" {
+  port = 5432
+}
+`,
+			nil,
+		},
+		"replace and add nested": {
+			[]AttributeEdit{
+				{Path: path(`service.web["front end"].port`), Value: cty.NumberIntVal(8080)},
+				{Path: path(`service.web["front end"].tags.owner`), Value: cty.StringVal("ops")},
+				{Path: path("service.db.port"), Value: cty.NumberIntVal(5433)},
+			},
+			`# Leading file comment
+a = 1 # trailing a
+
+service "web" "front end" {
+  # The port to listen on
+  port = 8080
+  tags {
+    env   = "dev"
+    owner = "ops"
+  }
+}
+
+service "db" {
+  port = 5433
+}
+`,
+			nil,
+		},
+		"remove": {
+			[]AttributeEdit{
+				{Path: path("service.db.port"), Value: cty.NilVal},
+				{Path: path("nonexist"), Value: cty.NilVal},
+			},
+			`# Leading file comment
+a = 1 # trailing a
+
+service "web" "front end" {
+  # The port to listen on
+  port = 80
+  tags {
+    env = "dev"
+  }
+}
+
+service "db" {
+}
+`,
+			nil,
+		},
+		"no matching block": {
+			[]AttributeEdit{
+				{Path: path("service.cache.port"), Value: cty.NumberIntVal(1)},
+			},
+			"",
+			[]string{`:1,1-19: Block not found; There is no block matching service.cache to contain the attribute "port".`},
+		},
+		"unknown value": {
+			[]AttributeEdit{
+				{Path: path("a"), Value: cty.UnknownVal(cty.Number)},
+			},
+			"",
+			[]string{`:1,1-2: Unsuitable attribute value; Cannot set a: cannot produce tokens for unknown value.`},
+		},
+		"invalid step": {
+			[]AttributeEdit{
+				{Path: path("service[0].port"), Value: cty.NumberIntVal(1)},
+			},
+			"",
+			[]string{`:1,8-11: Invalid attribute path; Each step of an attribute path must be a name or a string index.`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ApplyAttributeEdits([]byte(src), "", test.edits)
+
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			if diff := cmp.Diff(test.wantDiags, gotDiags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+			if diff := cmp.Diff(test.want, string(got)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
@@ -98,3 +98,28 @@ func ExampleExpression_RenameVariablePrefix() {
 	// foo = z.x + z.y * b.c
 	// bar = max(z.z, b.c)
 }
+
+func ExampleTokensForTemplate() {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	// SetAttributeValue always produces a literal string, so any template
+	// sequences in the value are escaped.
+	body.SetAttributeValue("literal", cty.StringVal("prefix-${var.x}-suffix"))
+
+	// TokensForTemplate instead produces real interpolation sequences for
+	// any parts that aren't literal text.
+	body.SetAttributeRaw("interpolated", hclwrite.TokensForTemplate(
+		hclwrite.TokensForTemplateLiteral("prefix-"),
+		hclwrite.TokensForTraversal(hcl.Traversal{
+			hcl.TraverseRoot{Name: "var"},
+			hcl.TraverseAttr{Name: "x"},
+		}),
+		hclwrite.TokensForTemplateLiteral("-suffix"),
+	))
+
+	fmt.Printf("%s", f.Bytes())
+	// Output:
+	// literal      = "prefix-$${var.x}-suffix"
+	// interpolated = "prefix-${var.x}-suffix"
+}
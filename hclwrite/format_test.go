@@ -10,7 +10,9 @@ import (
 	"reflect"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestFormat(t *testing.T) {
@@ -42,6 +44,10 @@ func TestFormat(t *testing.T) {
 			`a=["hello"][0]`,
 			`a = ["hello"][0]`,
 		},
+		{
+			`a=b??-1`,
+			`a = b ?? -1`,
+		},
 		{
 			`( a+2 )`,
 			`(a + 2)`,
@@ -614,6 +620,63 @@ module "x" {
   a     = "b"
   abcde = "456"
 }`,
+		},
+		{
+			// A blank line ends a group of aligned attributes.
+			`
+a = 1
+bbb = 2
+
+cccccc = 3
+dd = 4
+`,
+			`
+a   = 1
+bbb = 2
+
+cccccc = 3
+dd     = 4
+`,
+		},
+		{
+			// So does a comment line or a nested block, and an attribute whose
+			// value spans multiple lines is never aligned with its neighbors.
+			`
+a = 1
+bbb = 2
+# comment
+cccccc = 3
+dd = 4
+block {
+  x = 1
+  yyyy = 2
+}
+e = 5
+ffff = {
+  g = 6
+  hhh = 7
+}
+i = 8
+jjjj = 9
+`,
+			`
+a   = 1
+bbb = 2
+# comment
+cccccc = 3
+dd     = 4
+block {
+  x    = 1
+  yyyy = 2
+}
+e = 5
+ffff = {
+  g   = 6
+  hhh = 7
+}
+i    = 8
+jjjj = 9
+`,
 		},
 		{
 			`attr = provider::framework::example()`,
@@ -650,6 +713,75 @@ module "x" {
 
 }
 
+func TestFormatGeneratedAttributes(t *testing.T) {
+	f := NewEmptyFile()
+	body := f.Body()
+	body.SetAttributeValue("a", cty.NumberIntVal(1))
+	body.SetAttributeValue("bbbbbb", cty.StringVal("x"))
+	body.AppendNewline()
+	body.SetAttributeValue("cc", cty.True)
+	body.SetAttributeValue("dddd", cty.ListVal([]cty.Value{cty.True}))
+	block := body.AppendNewBlock("nested", nil)
+	block.Body().SetAttributeValue("q", cty.True)
+	block.Body().SetAttributeTraversal("qqqqq", hcl.Traversal{
+		hcl.TraverseRoot{Name: "var"},
+		hcl.TraverseAttr{Name: "foo"},
+	})
+	body.SetAttributeValue("e", cty.True)
+	body.SetAttributeValue("ffffff", cty.ObjectVal(map[string]cty.Value{
+		"a":   cty.True,
+		"bbb": cty.False,
+	}))
+	body.SetAttributeValue("g", cty.True)
+	body.SetAttributeValue("hh", cty.NullVal(cty.String))
+
+	want := `a      = 1
+bbbbbb = "x"
+
+cc   = true
+dddd = [true]
+nested {
+  q     = true
+  qqqqq = var.foo
+}
+e = true
+ffffff = {
+  a   = true
+  bbb = false
+}
+g  = true
+hh = null
+`
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	// Formatting the result again must not change it.
+	if got := string(Format(f.Bytes())); got != want {
+		t.Errorf("wrong result after Format\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatUTF8BOM(t *testing.T) {
+	src := "\xef\xbb\xbfa=1\nbbb=2\n"
+	want := "\xef\xbb\xbfa   = 1\nbbb = 2\n"
+	if got := string(Format([]byte(src))); got != want {
+		t.Errorf("wrong Format result\ngot:  %q\nwant: %q", got, want)
+	}
+	if got := string(FormatWithConfig([]byte(src), FormatConfig{UseTabs: true})); got != want {
+		t.Errorf("wrong FormatWithConfig result\ngot:  %q\nwant: %q", got, want)
+	}
+
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	f.Body().SetAttributeValue("c", cty.True)
+	want = "\xef\xbb\xbfa   = 1\nbbb = 2\nc   = true\n"
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("wrong File.Bytes result\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
 func TestLinesForFormat(t *testing.T) {
 	tests := []struct {
 		tokens Tokens
@@ -798,3 +930,91 @@ func TestLinesForFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			`   foo(a,b)+1`,
+			`foo(a, b) + 1`,
+		},
+		{
+			`[1,2,  3]`,
+			`[1, 2, 3]`,
+		},
+		{
+			"{\na=1\nbcd=2\n}",
+			"{\n  a   = 1\n  bcd = 2\n}",
+		},
+		{
+			``,
+			``,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			tokens := lexConfig([]byte(test.input))
+			tokens = tokens[:len(tokens)-1] // remove the EOF token to produce a fragment
+			before := string(tokens.Bytes())
+
+			got := string(FormatTokens(tokens).Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ninput:\n%s\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+			}
+			if after := string(tokens.Bytes()); after != before {
+				t.Errorf("input tokens were modified\nbefore:\n%s\nafter:\n%s", before, after)
+			}
+		})
+	}
+}
+
+func TestFormatWithConfig(t *testing.T) {
+	src := "a=1\nblock {\nfoo=\"bar\" # comment\nbaz = {\nx = 1\n}\n}\n"
+	tests := map[string]struct {
+		config FormatConfig
+		want   string
+	}{
+		"default": {
+			FormatConfig{},
+			"a = 1\nblock {\n  foo = \"bar\" # comment\n  baz = {\n    x = 1\n  }\n}\n",
+		},
+		"four spaces": {
+			FormatConfig{IndentWidth: 4},
+			"a = 1\nblock {\n    foo = \"bar\" # comment\n    baz = {\n        x = 1\n    }\n}\n",
+		},
+		"tabs": {
+			FormatConfig{UseTabs: true, IndentWidth: 4},
+			"a = 1\nblock {\n\tfoo = \"bar\" # comment\n\tbaz = {\n\t\tx = 1\n\t}\n}\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(FormatWithConfig([]byte(src), test.config))
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("matches Format", func(t *testing.T) {
+		src := []byte("a=1\nbcd=2\nblock \"x\" {\nfoo=1 # c\nlonger=2 # d\n}\n")
+		got := string(FormatWithConfig(src, FormatConfig{}))
+		want := string(Format(src))
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("alignment with tabs", func(t *testing.T) {
+		src := []byte("block {\nfoo=1 # c\nlonger=2 # d\n}\n")
+		got := string(FormatWithConfig(src, FormatConfig{UseTabs: true}))
+		want := "block {\n\tfoo    = 1 # c\n\tlonger = 2 # d\n}\n"
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
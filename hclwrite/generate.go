@@ -5,6 +5,7 @@ package hclwrite
 
 import (
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -22,13 +23,55 @@ import (
 // It is not possible to express an unknown value in source code, so this
 // function will panic if the given value is unknown or contains any unknown
 // values. A caller can call the value's IsWhollyKnown method to verify that
-// no unknown values are present before calling TokensForValue.
+// no unknown values are present before calling TokensForValue, or use
+// TokensForValueFunc to handle such values without panicking.
 func TokensForValue(val cty.Value) Tokens {
-	toks := appendTokensForValue(val, nil)
-	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	toks, err := TokensForValueFunc(val, nil)
+	if err != nil {
+		panic(err.Error())
+	}
 	return toks
 }
 
+// ValueTokensFunc is the signature of a function that produces tokens for a
+// value that has no literal representation in HCL source, for use with
+// TokensForValueFunc.
+type ValueTokensFunc func(val cty.Value) (Tokens, error)
+
+// TokensForValueFunc is like TokensForValue except that it returns an error
+// rather than panicking when the given value is unknown, is of a capsule
+// type, or contains such a value.
+//
+// If fallback is non-nil then it is called for each such value, and the
+// tokens it returns are used in place of that value. This allows a caller
+// to emit a placeholder for unknown values, or to define a rendering for its
+// own capsule types. The fallback may return an error, such as an
+// *UnrepresentableValueError, to reject a value. If fallback is nil then an
+// *UnrepresentableValueError is returned for the first such value found.
+func TokensForValueFunc(val cty.Value, fallback ValueTokensFunc) (Tokens, error) {
+	toks, err := appendTokensForValue(val, nil, fallback)
+	if err != nil {
+		return nil, err
+	}
+	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	return toks, nil
+}
+
+// UnrepresentableValueError is the error returned by TokensForValueFunc and
+// its callers when a value cannot be represented in HCL source code.
+type UnrepresentableValueError struct {
+	// Value is the value that cannot be represented, which may be nested
+	// inside the value that was originally given.
+	Value cty.Value
+}
+
+func (e *UnrepresentableValueError) Error() string {
+	if !e.Value.IsKnown() {
+		return "cannot produce tokens for unknown value"
+	}
+	return fmt.Sprintf("cannot produce tokens for %#v", e.Value)
+}
+
 // TokensForTraversal returns a sequence of tokens that represents the given
 // traversal.
 //
@@ -142,6 +185,153 @@ func TokensForObject(attrs []ObjectAttrTokens) Tokens {
 	return toks
 }
 
+// ObjectExprBuilder incrementally constructs the tokens for an object
+// constructor expression. Create one using NewObjectExpr.
+//
+// The zero value of ObjectExprBuilder is an empty object ready to use.
+type ObjectExprBuilder struct {
+	attrs []ObjectAttrTokens
+}
+
+// NewObjectExpr returns a builder for an object constructor expression with
+// no attributes.
+//
+// For example, the following produces tokens for an object with a nested
+// object and a tuple as its attribute values, which could then be passed to
+// Body.SetAttributeRaw:
+//
+//	tokens := hclwrite.NewObjectExpr().
+//		AddItem(hclwrite.TokensForIdentifier("name"), hclwrite.TokensForValue(cty.StringVal("web"))).
+//		AddItem(hclwrite.TokensForIdentifier("limits"), hclwrite.NewObjectExpr().
+//			AddItem(hclwrite.TokensForIdentifier("cpu"), hclwrite.TokensForValue(cty.NumberIntVal(2))).
+//			Tokens()).
+//		AddItem(hclwrite.TokensForIdentifier("ports"), hclwrite.NewTupleExpr().
+//			Append(hclwrite.TokensForValue(cty.NumberIntVal(80))).
+//			Tokens()).
+//		Tokens()
+func NewObjectExpr() *ObjectExprBuilder {
+	return &ObjectExprBuilder{}
+}
+
+// AddItem appends an attribute with the given name and value tokens to the
+// object, returning the receiver to allow chaining calls.
+//
+// The tokens are included verbatim, in the same way as for TokensForObject.
+func (b *ObjectExprBuilder) AddItem(key, value Tokens) *ObjectExprBuilder {
+	b.attrs = append(b.attrs, ObjectAttrTokens{
+		Name:  key,
+		Value: value,
+	})
+	return b
+}
+
+// Tokens returns the tokens for the object constructor expression, with the
+// attributes added so far in the order they were added.
+//
+// Each call returns a new sequence of tokens, but the tokens for the names
+// and values are shared with the builder, and so the result should not be
+// modified while the builder is still in use.
+func (b *ObjectExprBuilder) Tokens() Tokens {
+	return TokensForObject(b.attrs)
+}
+
+// TupleExprBuilder incrementally constructs the tokens for a tuple
+// constructor expression. Create one using NewTupleExpr.
+//
+// The zero value of TupleExprBuilder is an empty tuple ready to use.
+type TupleExprBuilder struct {
+	elems []Tokens
+}
+
+// NewTupleExpr returns a builder for a tuple constructor expression with no
+// elements. See NewObjectExpr for an example of building nested
+// expressions.
+func NewTupleExpr() *TupleExprBuilder {
+	return &TupleExprBuilder{}
+}
+
+// Append appends elements with the given tokens to the tuple, returning
+// the receiver to allow chaining calls.
+//
+// The tokens are included verbatim, in the same way as for TokensForTuple.
+func (b *TupleExprBuilder) Append(elems ...Tokens) *TupleExprBuilder {
+	b.elems = append(b.elems, elems...)
+	return b
+}
+
+// Tokens returns the tokens for the tuple constructor expression, with the
+// elements appended so far in the order they were appended.
+//
+// As with ObjectExprBuilder.Tokens, the element tokens are shared with the
+// builder.
+func (b *TupleExprBuilder) Tokens() Tokens {
+	return TokensForTuple(b.elems)
+}
+
+// TraversalBuilder incrementally constructs a traversal, such as a reference
+// to another attribute, for use with Body.SetAttributeTraversal or for
+// generating tokens. Create one using NewTraversal.
+type TraversalBuilder struct {
+	traversal hcl.Traversal
+}
+
+// NewTraversal returns a builder for a traversal that starts with a
+// reference to the variable of the given name.
+//
+// For example, the following sets an attribute to the reference
+// var.subnets[0]["id"]:
+//
+//	body.SetAttributeTraversal("subnet_id", hclwrite.NewTraversal("var").
+//		Attr("subnets").
+//		Index(cty.NumberIntVal(0)).
+//		Index(cty.StringVal("id")).
+//		Traversal())
+func NewTraversal(root string) *TraversalBuilder {
+	return &TraversalBuilder{
+		traversal: hcl.Traversal{
+			hcl.TraverseRoot{Name: root},
+		},
+	}
+}
+
+// Attr appends an attribute access step to the traversal, returning the
+// receiver to allow chaining calls.
+//
+// If the given name is not a valid identifier then it can't be written
+// using attribute syntax, and so an index step with the name as a string
+// key is appended instead, which has the same meaning.
+func (b *TraversalBuilder) Attr(name string) *TraversalBuilder {
+	if !hclsyntax.ValidIdentifier(name) {
+		return b.Index(cty.StringVal(name))
+	}
+	b.traversal = append(b.traversal, hcl.TraverseAttr{Name: name})
+	return b
+}
+
+// Index appends an index step with the given key to the traversal,
+// returning the receiver to allow chaining calls.
+//
+// The key is typically a number or a string, and must be known and not
+// null so that it can be written as a literal. Generating tokens for a
+// traversal with an unsuitable key causes a panic.
+func (b *TraversalBuilder) Index(key cty.Value) *TraversalBuilder {
+	b.traversal = append(b.traversal, hcl.TraverseIndex{Key: key})
+	return b
+}
+
+// Traversal returns the traversal built so far. The result is a new slice
+// on each call, so it can be modified without affecting the builder.
+func (b *TraversalBuilder) Traversal() hcl.Traversal {
+	return append(hcl.Traversal(nil), b.traversal...)
+}
+
+// Tokens returns the tokens for the traversal built so far, as would be
+// returned by TokensForTraversal, for use where the traversal is only part
+// of an expression.
+func (b *TraversalBuilder) Tokens() Tokens {
+	return TokensForTraversal(b.traversal)
+}
+
 // TokensForFunctionCall returns a sequence of tokens that represents call
 // to the function with the given name, using the argument tokens to
 // populate the argument expressions.
@@ -182,11 +372,225 @@ func TokensForFunctionCall(funcName string, args ...Tokens) Tokens {
 	return toks
 }
 
-func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
-	switch {
+// TokensForGetAttr returns a sequence of tokens that represents accessing
+// the attribute with the given name on the result of the given expression,
+// as in expr.name.
+//
+// TokensForGetAttr includes the given expression tokens verbatim, without
+// any validation to ensure that they represent a valid expression. The
+// caller must wrap the expression in parentheses if it would otherwise be
+// ambiguous, such as when it is a binary operation.
+func TokensForGetAttr(expr Tokens, name string) Tokens {
+	var toks Tokens
+	toks = append(toks, expr...)
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenDot,
+		Bytes: []byte{'.'},
+	})
+	toks = append(toks, TokensForIdentifier(name)...)
+
+	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	return toks
+}
 
-	case !val.IsKnown():
-		panic("cannot produce tokens for unknown value")
+// TokensForIndex returns a sequence of tokens that represents indexing the
+// result of the given expression with the given key expression, as in
+// expr[key].
+//
+// TokensForIndex includes the given tokens verbatim, without any validation
+// to ensure that they represent valid expressions. As with TokensForGetAttr,
+// the caller must wrap the expression in parentheses if necessary.
+func TokensForIndex(expr Tokens, key Tokens) Tokens {
+	var toks Tokens
+	toks = append(toks, expr...)
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenOBrack,
+		Bytes: []byte{'['},
+	})
+	toks = append(toks, key...)
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenCBrack,
+		Bytes: []byte{']'},
+	})
+
+	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	return toks
+}
+
+// TokensForTemplateLiteral returns a sequence of tokens representing the
+// given string as literal text within a template, for use as one of the
+// parts given to TokensForTemplate. Any characters that would otherwise be
+// interpreted as template syntax or escape sequences are escaped.
+func TokensForTemplateLiteral(s string) Tokens {
+	src := escapeQuotedStringLit(s)
+	if len(src) == 0 {
+		return nil
+	}
+	return Tokens{
+		{
+			Type:  hclsyntax.TokenQuotedLit,
+			Bytes: src,
+		},
+	}
+}
+
+// TokensForHeredoc returns a sequence of tokens that represents the given
+// string as an indented heredoc template, such as:
+//
+//	<<-EOT
+//	  echo "hello"
+//	EOT
+//
+// This is more readable than a quoted string for multi-line text such as
+// embedded scripts. The closing marker is chosen so that it does not
+// appear as a line of the text, and any template sequences in the text are
+// escaped, so that the result always evaluates to exactly the given string.
+//
+// Because the value of a heredoc always ends with a newline, the given
+// string is returned as a quoted string instead, as for TokensForValue, if
+// it does not end with a newline. The same is true if it contains carriage
+// returns or other non-printable characters except tabs, which a heredoc
+// cannot represent unambiguously. If none of the lines of the text begin
+// at the left margin then the heredoc is not indented, since indenting
+// would cause their common leading whitespace to be removed.
+func TokensForHeredoc(s string) Tokens {
+	if !heredocCanRepresent(s) {
+		return TokensForValue(cty.StringVal(s))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+
+	// The heredoc is indented only if at least one non-blank line begins at
+	// the left margin, so that only our own indentation is removed when
+	// parsing. Blank lines are not indented and don't affect the result.
+	indent := false
+	for _, line := range lines {
+		if trimmed := strings.TrimLeftFunc(line, unicode.IsSpace); trimmed != "" && trimmed == line {
+			indent = true
+			break
+		}
+	}
+
+	marker := "EOT"
+	for i := 1; heredocLinesContain(lines, marker); i++ {
+		marker = fmt.Sprintf("EOT%d", i)
+	}
+
+	introducer := "<<" + marker + "\n"
+	if indent {
+		introducer = "<<-" + marker + "\n"
+	}
+	toks := Tokens{
+		{
+			Type:  hclsyntax.TokenOHeredoc,
+			Bytes: []byte(introducer),
+		},
+	}
+	for _, line := range lines {
+		var buf []byte
+		if indent && strings.TrimLeftFunc(line, unicode.IsSpace) != "" {
+			buf = append(buf, "  "...)
+		}
+		buf = append(buf, escapeHeredocLit(line)...)
+		buf = append(buf, '\n')
+		toks = append(toks, &Token{
+			Type:  hclsyntax.TokenStringLit,
+			Bytes: buf,
+		})
+	}
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenCHeredoc,
+		Bytes: []byte(marker),
+	})
+	return toks
+}
+
+func heredocCanRepresent(s string) bool {
+	if !strings.HasSuffix(s, "\n") {
+		return false
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// heredocLinesContain returns true if any of the given lines would be
+// interpreted as a heredoc closing marker with the given name.
+func heredocLinesContain(lines []string, marker string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeHeredocLit escapes the template sequences in the given heredoc
+// line. Unlike in quoted strings, backslashes have no special meaning.
+func escapeHeredocLit(s string) []byte {
+	buf := make([]byte, 0, len(s))
+	for i, r := range s {
+		buf = appendRune(buf, r)
+		if (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{") {
+			// Double up our template introducer symbol to escape it.
+			buf = appendRune(buf, r)
+		}
+	}
+	return buf
+}
+
+// TokensForTemplate returns a sequence of tokens that represents a quoted
+// template string, such as "Hello, ${name}!", made from the given parts in
+// order.
+//
+// A part consisting only of literal template text, as returned by
+// TokensForTemplateLiteral, is included as-is. Any other part is included
+// verbatim as an interpolated expression, without any validation to ensure
+// that it represents a valid expression.
+func TokensForTemplate(parts ...Tokens) Tokens {
+	var toks Tokens
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenOQuote,
+		Bytes: []byte{'"'},
+	})
+	for _, part := range parts {
+		if isTemplateLiteral(part) {
+			toks = append(toks, part...)
+			continue
+		}
+		toks = append(toks, &Token{
+			Type:  hclsyntax.TokenTemplateInterp,
+			Bytes: []byte("${"),
+		})
+		toks = append(toks, part...)
+		toks = append(toks, &Token{
+			Type:  hclsyntax.TokenTemplateSeqEnd,
+			Bytes: []byte{'}'},
+		})
+	}
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenCQuote,
+		Bytes: []byte{'"'},
+	})
+
+	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	return toks
+}
+
+func isTemplateLiteral(toks Tokens) bool {
+	for _, tok := range toks {
+		if tok.Type != hclsyntax.TokenQuotedLit {
+			return false
+		}
+	}
+	return true
+}
+
+func appendTokensForValue(val cty.Value, toks Tokens, fallback ValueTokensFunc) (Tokens, error) {
+	switch {
 
 	case val.IsNull():
 		toks = append(toks, &Token{
@@ -194,6 +598,16 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 			Bytes: []byte(`null`),
 		})
 
+	case !val.IsKnown() || val.Type().IsCapsuleType():
+		if fallback == nil {
+			return nil, &UnrepresentableValueError{Value: val}
+		}
+		moreToks, err := fallback(val)
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, moreToks...)
+
 	case val.Type() == cty.Bool:
 		var src []byte
 		if val.True() {
@@ -248,7 +662,11 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 				})
 			}
 			_, eVal := it.Element()
-			toks = appendTokensForValue(eVal, toks)
+			var err error
+			toks, err = appendTokensForValue(eVal, toks, fallback)
+			if err != nil {
+				return nil, err
+			}
 			i++
 		}
 
@@ -271,6 +689,7 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 
 		i := 0
 		for it := val.ElementIterator(); it.Next(); {
+			var err error
 			eKey, eVal := it.Element()
 			if hclsyntax.ValidIdentifier(eKey.AsString()) {
 				toks = append(toks, &Token{
@@ -278,13 +697,19 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 					Bytes: []byte(eKey.AsString()),
 				})
 			} else {
-				toks = appendTokensForValue(eKey, toks)
+				toks, err = appendTokensForValue(eKey, toks, fallback)
+				if err != nil {
+					return nil, err
+				}
 			}
 			toks = append(toks, &Token{
 				Type:  hclsyntax.TokenEqual,
 				Bytes: []byte{'='},
 			})
-			toks = appendTokensForValue(eVal, toks)
+			toks, err = appendTokensForValue(eVal, toks, fallback)
+			if err != nil {
+				return nil, err
+			}
 			toks = append(toks, &Token{
 				Type:  hclsyntax.TokenNewline,
 				Bytes: []byte{'\n'},
@@ -298,10 +723,10 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 		})
 
 	default:
-		panic(fmt.Sprintf("cannot produce tokens for %#v", val))
+		return nil, &UnrepresentableValueError{Value: val}
 	}
 
-	return toks
+	return toks, nil
 }
 
 func appendTokensForTraversal(traversal hcl.Traversal, toks Tokens) Tokens {
@@ -335,7 +760,11 @@ func appendTokensForTraversalStep(step hcl.Traverser, toks Tokens) Tokens {
 			Type:  hclsyntax.TokenOBrack,
 			Bytes: []byte{'['},
 		})
-		toks = appendTokensForValue(ts.Key, toks)
+		var err error
+		toks, err = appendTokensForValue(ts.Key, toks, nil)
+		if err != nil {
+			panic(err.Error())
+		}
 		toks = append(toks, &Token{
 			Type:  hclsyntax.TokenCBrack,
 			Bytes: []byte{']'},
@@ -6,6 +6,7 @@ package hclwrite
 import (
 	"bytes"
 	"math/big"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -499,6 +500,100 @@ func TestTokensForValue(t *testing.T) {
 	}
 }
 
+func TestTokensForValueFunc(t *testing.T) {
+	type widget struct{ name string }
+	widgetType := cty.Capsule("widget", reflect.TypeOf(widget{}))
+	widgetVal := cty.CapsuleVal(widgetType, &widget{name: "gear"})
+
+	placeholder := func(val cty.Value) (Tokens, error) {
+		if val.IsKnown() && val.Type().Equals(widgetType) {
+			return TokensForValue(cty.StringVal(val.EncapsulatedValue().(*widget).name)), nil
+		}
+		if !val.IsKnown() {
+			return TokensForIdentifier("unknown"), nil
+		}
+		return nil, &UnrepresentableValueError{Value: val}
+	}
+
+	tests := map[string]struct {
+		val      cty.Value
+		fallback ValueTokensFunc
+		want     string
+		wantErr  string
+	}{
+		"known without fallback": {
+			cty.ListVal([]cty.Value{cty.True}),
+			nil,
+			`[true]`,
+			``,
+		},
+		"unknown without fallback": {
+			cty.UnknownVal(cty.String),
+			nil,
+			``,
+			`cannot produce tokens for unknown value`,
+		},
+		"nested unknown without fallback": {
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.UnknownVal(cty.Bool),
+			}),
+			nil,
+			``,
+			`cannot produce tokens for unknown value`,
+		},
+		"capsule without fallback": {
+			widgetVal,
+			nil,
+			``,
+			`cannot produce tokens for cty.CapsuleVal(cty.Capsule("widget", reflect.TypeOf(hclwrite.widget{name:""})), &hclwrite.widget{name:"gear"})`,
+		},
+		"null capsule without fallback": {
+			cty.NullVal(widgetType),
+			nil,
+			`null`,
+			``,
+		},
+		"unknown with fallback": {
+			cty.TupleVal([]cty.Value{cty.UnknownVal(cty.String), cty.True}),
+			placeholder,
+			`[unknown, true]`,
+			``,
+		},
+		"capsule with fallback": {
+			cty.ObjectVal(map[string]cty.Value{
+				"w": widgetVal,
+			}),
+			placeholder,
+			"{\n  w = \"gear\"\n}",
+			``,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := TokensForValueFunc(test.val, test.fallback)
+			if test.wantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success; want error %q", test.wantErr)
+				}
+				if _, ok := err.(*UnrepresentableValueError); !ok {
+					t.Errorf("wrong error type %T", err)
+				}
+				if got := err.Error(); got != test.wantErr {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := string(got.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
 func TestTokensForTraversal(t *testing.T) {
 	tests := []struct {
 		Val  hcl.Traversal
@@ -883,3 +978,216 @@ func TestTokenGenerateConsistency(t *testing.T) {
 		}
 	})
 }
+
+func TestTokensForExpressionBuilders(t *testing.T) {
+	varConfig := TokensForTraversal(hcl.Traversal{
+		hcl.TraverseRoot{Name: "var"},
+		hcl.TraverseAttr{Name: "config"},
+	})
+
+	tests := map[string]struct {
+		Got  Tokens
+		Want string
+	}{
+		"get attr": {
+			TokensForGetAttr(TokensForFunctionCall("lookup", varConfig), "name"),
+			`lookup(var.config).name`,
+		},
+		"index by string": {
+			TokensForIndex(varConfig, TokensForValue(cty.StringVal("key"))),
+			`var.config["key"]`,
+		},
+		"index by expression": {
+			TokensForIndex(TokensForIdentifier("local"), TokensForTraversal(hcl.Traversal{
+				hcl.TraverseRoot{Name: "count"},
+				hcl.TraverseAttr{Name: "index"},
+			})),
+			`local[count.index]`,
+		},
+		"template": {
+			TokensForTemplate(
+				TokensForTemplateLiteral("Hello, "),
+				TokensForGetAttr(varConfig, "name"),
+				TokensForTemplateLiteral("!"),
+			),
+			`"Hello, ${var.config.name}!"`,
+		},
+		"template with escapes": {
+			TokensForTemplate(
+				TokensForTemplateLiteral("${not} \"interp\"\n"),
+				TokensForFunctionCall("upper", TokensForValue(cty.StringVal("x"))),
+			),
+			`"$${not} \"interp\"\n${upper("x")}"`,
+		},
+		"empty template": {
+			TokensForTemplate(TokensForTemplateLiteral("")),
+			`""`,
+		},
+		"function call of traversal": {
+			TokensForFunctionCall("jsonencode", varConfig),
+			`jsonencode(var.config)`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(test.Got.Bytes())
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+
+			_, diags := hclsyntax.ParseExpression(test.Got.Bytes(), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Errorf("result is not a valid expression: %s", diags.Error())
+			}
+		})
+	}
+}
+
+func TestObjectAndTupleExprBuilders(t *testing.T) {
+	f := NewEmptyFile()
+	f.Body().SetAttributeRaw("service", NewObjectExpr().
+		AddItem(TokensForIdentifier("name"), TokensForValue(cty.StringVal("web"))).
+		AddItem(TokensForIdentifier("limits"), NewObjectExpr().
+			AddItem(TokensForIdentifier("cpu"), TokensForValue(cty.NumberIntVal(2))).
+			AddItem(TokensForIdentifier("memory"), TokensForValue(cty.StringVal("1G"))).
+			Tokens()).
+		AddItem(TokensForIdentifier("ports"), NewTupleExpr().
+			Append(TokensForValue(cty.NumberIntVal(80))).
+			Append(TokensForValue(cty.NumberIntVal(443)), TokensForTraversal(hcl.Traversal{
+				hcl.TraverseRoot{Name: "var"},
+				hcl.TraverseAttr{Name: "port"},
+			})).
+			Tokens()).
+		AddItem(TokensForIdentifier("empty"), NewTupleExpr().Tokens()).
+		Tokens())
+
+	got := string(f.Bytes())
+	want := `service = {
+  name = "web"
+  limits = {
+    cpu    = 2
+    memory = "1G"
+  }
+  ports = [80, 443, var.port]
+  empty = []
+}
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The zero values are also usable, and produce empty constructors.
+	var obj ObjectExprBuilder
+	if got, want := string(obj.Tokens().Bytes()), "{}"; got != want {
+		t.Errorf("wrong result for empty object %q; want %q", got, want)
+	}
+	var tup TupleExprBuilder
+	if got, want := string(tup.Tokens().Bytes()), "[]"; got != want {
+		t.Errorf("wrong result for empty tuple %q; want %q", got, want)
+	}
+}
+
+func TestTraversalBuilder(t *testing.T) {
+	f := NewEmptyFile()
+	body := f.Body()
+	body.SetAttributeTraversal("subnet", NewTraversal("var").
+		Attr("subnets").
+		Index(cty.NumberIntVal(0)).
+		Index(cty.StringVal("id")).
+		Traversal())
+	body.SetAttributeTraversal("odd", NewTraversal("local").
+		Attr("not valid").
+		Traversal())
+	body.SetAttributeRaw("upper", TokensForFunctionCall("upper", NewTraversal("var").Attr("name").Tokens()))
+
+	got := string(f.Bytes())
+	want := `subnet = var.subnets[0]["id"]
+odd    = local["not valid"]
+upper  = upper(var.name)
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The returned traversal is a copy, so later steps don't affect it.
+	b := NewTraversal("a")
+	before := b.Traversal()
+	b.Attr("b")
+	if got, want := len(before), 1; got != want {
+		t.Errorf("wrong length of earlier traversal %d; want %d", got, want)
+	}
+	if got, want := len(b.Traversal()), 2; got != want {
+		t.Errorf("wrong length of traversal %d; want %d", got, want)
+	}
+}
+
+func TestTokensForHeredoc(t *testing.T) {
+	tests := map[string]struct {
+		val  string
+		want string
+	}{
+		"script": {
+			"#!/bin/sh\nset -e\n\nif true; then\n  echo \"${HOME}\" 100%{x}\nfi\n",
+			"<<-EOT\n  #!/bin/sh\n  set -e\n\n  if true; then\n    echo \"$${HOME}\" 100%%{x}\n  fi\nEOT",
+		},
+		"single line": {
+			"hello\n",
+			"<<-EOT\n  hello\nEOT",
+		},
+		"only a newline": {
+			"\n",
+			"<<EOT\n\nEOT",
+		},
+		"marker collision": {
+			"a\nEOT\n  EOT1\nb\n",
+			"<<-EOT2\n  a\n  EOT\n    EOT1\n  b\nEOT2",
+		},
+		"all lines indented": {
+			"  a\n\tb\n",
+			"<<EOT\n  a\n\tb\nEOT",
+		},
+		"backslashes": {
+			"C:\\path\\n\n",
+			"<<-EOT\n  C:\\path\\n\nEOT",
+		},
+		"no trailing newline": {
+			"a\nb",
+			`"a\nb"`,
+		},
+		"carriage return": {
+			"a\r\nb\r\n",
+			`"a\r\nb\r\n"`,
+		},
+		"empty": {
+			"",
+			`""`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			toks := TokensForHeredoc(test.val)
+			if got := string(toks.Bytes()); got != test.want {
+				t.Errorf("wrong tokens\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+
+			// The result must evaluate to exactly the given string, even
+			// when nested and formatted.
+			f := NewEmptyFile()
+			f.Body().AppendNewBlock("a", nil).Body().SetAttributeRaw("v", toks)
+			parsed, diags := hclsyntax.ParseConfig(f.Bytes(), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("result does not parse: %s\n%s", diags.Error(), f.Bytes())
+			}
+			blocks := parsed.Body.(*hclsyntax.Body).Blocks
+			val, diags := blocks[0].Body.Attributes["v"].Expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatalf("result does not evaluate: %s", diags.Error())
+			}
+			if got := val.AsString(); got != test.val {
+				t.Errorf("wrong value\ngot:  %q\nwant: %q", got, test.val)
+			}
+		})
+	}
+}
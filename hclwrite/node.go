@@ -80,6 +80,12 @@ func (n *node) ReplaceWith(c nodeContent) *node {
 	if after != nil {
 		after.before = nn
 	}
+	if list.first == n {
+		list.first = nn
+	}
+	if list.last == n {
+		list.last = nn
+	}
 	return nn
 }
 
@@ -107,6 +113,31 @@ func (ns *nodes) BuildTokens(to Tokens) Tokens {
 	return to
 }
 
+// lastToken returns the last token that BuildTokens would produce for the
+// receiver, or nil if it would produce none, without building the others.
+func (ns *nodes) lastToken() *Token {
+	for n := ns.last; n != nil; n = n.before {
+		var last *Token
+		switch c := n.content.(type) {
+		case Tokens:
+			if len(c) > 0 {
+				last = c[len(c)-1]
+			}
+		case interface{ childNodes() *nodes }:
+			last = c.childNodes().lastToken()
+		default:
+			// Leaf nodes have only a few tokens, so we can just build them.
+			if toks := c.BuildTokens(nil); len(toks) > 0 {
+				last = toks[len(toks)-1]
+			}
+		}
+		if last != nil {
+			return last
+		}
+	}
+	return nil
+}
+
 func (ns *nodes) Clear() {
 	ns.first = nil
 	ns.last = nil
@@ -146,19 +177,23 @@ func (ns *nodes) Insert(pos *node, c nodeContent) *node {
 
 // InsertNode inserts a node at a given position.
 // The first argument is a node reference before which to insert.
-// To insert it to an empty list, set position to nil.
+// To insert it at the end of the list, set position to nil.
 func (ns *nodes) InsertNode(pos *node, n *node) {
 	if pos == nil {
-		// inserts n to empty list.
-		ns.first = n
-		ns.last = n
-	} else {
-		// inserts n before pos.
+		// inserts n at the end of the list.
+		ns.AppendNode(n)
+		return
+	}
+
+	// inserts n before pos.
+	if pos.before != nil {
 		pos.before.after = n
-		n.before = pos.before
-		pos.before = n
-		n.after = pos
+	} else {
+		ns.first = n
 	}
+	n.before = pos.before
+	pos.before = n
+	n.after = pos
 
 	n.list = ns
 }
@@ -283,6 +318,10 @@ func (it *inTree) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
+func (it *inTree) childNodes() *nodes {
+	return it.children
+}
+
 func (it *inTree) BuildTokens(to Tokens) Tokens {
 	for n := it.children.first; n != nil; n = n.after {
 		to = n.BuildTokens(to)
@@ -4,6 +4,7 @@
 package hclwrite
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 
@@ -59,6 +60,7 @@ func parse(src []byte, filename string, start hcl.Pos) (*File, hcl.Diagnostics)
 
 		srcBytes: src,
 		body:     root,
+		bom:      bytes.HasPrefix(src, []byte(hclsyntax.UTF8BOM)),
 	}
 
 	nodes := ret.inTree.children
@@ -315,6 +317,7 @@ func parseBlock(nativeBlock *hclsyntax.Block, from, leadComments, lineComments,
 	}
 
 	before, labelsNode, from := parseBlockLabels(nativeBlock, from)
+	children.AppendUnstructuredTokens(before.Tokens())
 	block.labels = labelsNode
 	children.AppendNode(labelsNode)
 
@@ -7,6 +7,7 @@ import (
 	"bytes"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 // NewFile creates a new file object that is empty and ready to have constructs
@@ -30,6 +31,71 @@ func ParseConfig(src []byte, filename string, start hcl.Pos) (*File, hcl.Diagnos
 	return parse(src, filename, start)
 }
 
+// ParseBlock interprets the given source bytes as a single block, returning
+// a *hclwrite.Block that is not attached to any body. The result can then be
+// added to a body using Body.AppendBlock or Body.InsertBlockBefore, which is
+// more convenient than building up a block with NewBlock when the desired
+// content is already available as source code.
+//
+// The source must contain exactly one block and nothing else, aside from any
+// comments before it. The filename and start position are used only for the
+// source ranges of the returned diagnostics, which describe any syntax
+// errors in the fragment. If there are any errors then the returned block is
+// nil.
+func ParseBlock(src []byte, filename string, start hcl.Pos) (*Block, hcl.Diagnostics) {
+	nativeFile, diags := hclsyntax.ParseConfig(src, filename, start)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	nativeBody := nativeFile.Body.(*hclsyntax.Body)
+
+	for _, attr := range nativeBody.Attributes {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unexpected argument",
+			Detail:   "A block fragment must contain only a single block, not arguments.",
+			Subject:  attr.NameRange.Ptr(),
+		})
+	}
+	switch len(nativeBody.Blocks) {
+	case 0:
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing block",
+			Detail:   "A block fragment must contain a single block.",
+			Subject:  nativeBody.SrcRange.Ptr(),
+		})
+	case 1:
+		// okay
+	default:
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Extraneous block",
+			Detail:   "A block fragment must contain only a single block.",
+			Subject:  nativeBody.Blocks[1].DefRange().Ptr(),
+		})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	f, diags := parse(src, filename, start)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body := f.Body()
+	block := body.Blocks()[0]
+	body.RemoveBlock(block)
+
+	// The fragment might end at the closing brace without a newline, but
+	// the block must end with one so that it can be inserted before other
+	// items in a body.
+	if tok := block.children.lastToken(); tok == nil || !tokenEndsWithNewline(tok) {
+		block.children.AppendUnstructuredTokens(newlineTokens())
+	}
+	return block, diags
+}
+
 // Format takes source code and performs simple whitespace changes to transform
 // it to a canonical layout style.
 //
@@ -38,10 +104,102 @@ func ParseConfig(src []byte, filename string, start hcl.Pos) (*File, hcl.Diagnos
 // changes will be made. It also ignores syntax errors and can thus be applied
 // to partial source code, although the result in that case may not be
 // desirable.
+//
+// A UTF-8 byte order mark at the start of the source code is preserved.
+//
+// The equals signs of consecutive single-line attributes are aligned into a
+// column, as are any comments that follow them. A group of aligned lines
+// ends at any line that isn't a single-line attribute, such as a blank line,
+// a comment on its own line, a block header, or the first line of an
+// attribute whose value is a bracketed expression spanning multiple lines.
 func Format(src []byte) []byte {
 	tokens := lexConfig(src)
 	format(tokens)
 	buf := &bytes.Buffer{}
+	if bytes.HasPrefix(src, []byte(hclsyntax.UTF8BOM)) {
+		buf.WriteString(hclsyntax.UTF8BOM)
+	}
 	tokens.WriteTo(buf)
 	return buf.Bytes()
 }
+
+// FormatConfig customizes the layout produced by FormatWithConfig.
+//
+// The zero value of FormatConfig selects the same layout as Format.
+type FormatConfig struct {
+	// IndentWidth is the number of spaces used for each level of
+	// indentation. If it is zero, the canonical two spaces are used.
+	IndentWidth int
+
+	// UseTabs, if set, causes each level of indentation to be a single tab
+	// character instead of spaces, in which case IndentWidth is ignored.
+	// The alignment of the equals signs of attributes and of trailing
+	// comments is still done with spaces, counting each tab as a single
+	// column, and so is exact only among lines at the same level.
+	UseTabs bool
+}
+
+// FormatWithConfig is like Format, but uses the given configuration to
+// customize the indentation of the result. All of the other layout rules are
+// the same as for Format.
+func FormatWithConfig(src []byte, config FormatConfig) []byte {
+	indentWidth := config.IndentWidth
+	if indentWidth <= 0 {
+		indentWidth = defaultIndentWidth
+	}
+	if config.UseTabs {
+		indentWidth = 1
+	}
+
+	tokens := lexConfig(src)
+	formatWithIndent(tokens, indentWidth)
+	buf := &bytes.Buffer{}
+	if bytes.HasPrefix(src, []byte(hclsyntax.UTF8BOM)) {
+		buf.WriteString(hclsyntax.UTF8BOM)
+	}
+	if config.UseTabs {
+		writeTokensTabIndented(buf, tokens)
+	} else {
+		tokens.WriteTo(buf)
+	}
+	return buf.Bytes()
+}
+
+// writeTokensTabIndented is like Tokens.WriteTo, except that the spaces
+// before the first token on each line are written as tabs instead.
+func writeTokensTabIndented(buf *bytes.Buffer, tokens Tokens) {
+	lineStart := true
+	for _, token := range tokens {
+		if lineStart {
+			buf.Write(bytes.Repeat([]byte{'\t'}, token.SpacesBefore))
+		} else {
+			buf.Write(bytes.Repeat([]byte{' '}, token.SpacesBefore))
+		}
+		buf.Write(token.Bytes)
+		lineStart = tokenIsNewline(token)
+	}
+}
+
+// FormatTokens returns a copy of the given tokens with the whitespace between
+// them adjusted to the same canonical layout style used by Format.
+//
+// Unlike Format, the tokens need not represent a whole file. They may be a
+// fragment such as a single expression, in which case they are formatted as
+// if they began at the start of a line and the first token is given no
+// leading spaces. A fragment need not end with a newline. The given tokens
+// are not modified.
+func FormatTokens(tokens Tokens) Tokens {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	ret := make(Tokens, len(tokens))
+	for i, tok := range tokens {
+		newTok := *tok
+		ret[i] = &newTok
+	}
+
+	format(ret)
+	ret[0].SpacesBefore = 0
+	return ret
+}
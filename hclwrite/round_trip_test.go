@@ -183,3 +183,124 @@ func TestRoundTripSafeConcurrent(t *testing.T) {
 		}()
 	}
 }
+
+func TestFileNormalizeStrings(t *testing.T) {
+	src := "a = \"caf\\u00e9\"\n" +
+		"b = \"tab\t and \\u0009 and \\\"quoted\\\" and \\\\\"\n" +
+		"c = \"$${literal} and %%{literal} and $$ and ${interp(\"\\u0041\")} and $5\"\n" +
+		"d = \"${x}\\u000a${y}\"\n" +
+		"g = \"\\u0024${\"z\"}\"\n" +
+		"h = \"\\u0025%{ if true }y%{ endif }\"\n" +
+		"block \"lab\\u0065l\" {\n" +
+		"  e = <<EOT\n" +
+		"keep \\u0041 as is\n" +
+		"EOT\n" +
+		"  f = \"\\U0001F600 and \u200b\"\n" +
+		"}\n"
+	want := "a = \"café\"\n" +
+		"b = \"tab\\t and \\t and \\\"quoted\\\" and \\\\\"\n" +
+		"c = \"$${literal} and %%{literal} and $$ and ${interp(\"A\")} and $5\"\n" +
+		"d = \"${x}\\n${y}\"\n" +
+		"g = \"\\u0024${\"z\"}\"\n" +
+		"h = \"\\u0025%{if true}y%{endif}\"\n" +
+		"block \"label\" {\n" +
+		"  e = <<EOT\n" +
+		"keep \\u0041 as is\n" +
+		"EOT\n" +
+		"  f = \"\U0001F600 and \\u200b\"\n" +
+		"}\n"
+
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	f.NormalizeStrings()
+	got := string(f.Bytes())
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The normalized strings must have the same values as the originals.
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"x": cty.StringVal("x"),
+			"y": cty.StringVal("y"),
+		},
+		Functions: map[string]function.Function{
+			"interp": stdlib.UpperFunc,
+		},
+	}
+	values := func(src string) map[string]cty.Value {
+		t.Helper()
+		f, diags := hclsyntax.ParseConfig([]byte(src), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+		body := f.Body.(*hclsyntax.Body)
+		ret := map[string]cty.Value{}
+		for name, attr := range body.Attributes {
+			ret[name], _ = attr.Expr.Value(ctx)
+		}
+		for _, block := range body.Blocks {
+			ret["label"] = cty.StringVal(block.Labels[0])
+			for name, attr := range block.Body.Attributes {
+				ret[name], _ = attr.Expr.Value(ctx)
+			}
+		}
+		return ret
+	}
+	srcVals, gotVals := values(src), values(got)
+	for name, srcVal := range srcVals {
+		if gotVal := gotVals[name]; !srcVal.RawEquals(gotVal) {
+			t.Errorf("value of %s changed\nbefore: %#v\nafter:  %#v", name, srcVal, gotVal)
+		}
+	}
+	if len(srcVals) != 9 {
+		t.Errorf("wrong number of values %d; want 9", len(srcVals))
+	}
+}
+
+func TestFileBytesWithLineEnding(t *testing.T) {
+	src := "# comment\r\n" +
+		"a = \"line\\nbreak\" /* multi\n   line */\n" +
+		"block {\n" +
+		"  b = <<EOT\n" +
+		"heredoc\r\n" +
+		"EOT\n" +
+		"}\n"
+
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	f.Body().SetAttributeValue("c", cty.StringVal("new"))
+
+	tests := map[LineEnding]string{
+		LineEndingLF: "# comment\n" +
+			"a = \"line\\nbreak\" /* multi\n   line */\n" +
+			"block {\n" +
+			"  b = <<EOT\n" +
+			"heredoc\n" +
+			"EOT\n" +
+			"}\n" +
+			"c = \"new\"\n",
+		LineEndingCRLF: "# comment\r\n" +
+			"a = \"line\\nbreak\" /* multi\r\n   line */\r\n" +
+			"block {\r\n" +
+			"  b = <<EOT\r\n" +
+			"heredoc\r\n" +
+			"EOT\r\n" +
+			"}\r\n" +
+			"c = \"new\"\r\n",
+	}
+	for style, want := range tests {
+		if got := string(f.BytesWithLineEnding(style)); got != want {
+			t.Errorf("wrong result for style %d\ngot:  %q\nwant: %q", style, got, want)
+		}
+	}
+
+	// The tokens themselves must be unchanged.
+	if got := string(f.Bytes()); got != src+"c = \"new\"\n" {
+		t.Errorf("file was modified: %q", got)
+	}
+}
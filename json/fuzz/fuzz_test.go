@@ -6,18 +6,42 @@ package fuzzjson
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/json"
 )
 
 func FuzzParse(f *testing.F) {
 	f.Fuzz(func(t *testing.T, data []byte) {
-		_, diags := json.Parse(data, "<fuzz-conf>")
+		file, diags := json.Parse(data, "<fuzz-conf>")
 
 		if diags.HasErrors() {
 			t.Logf("Error when parsing JSON %v", data)
 			for _, diag := range diags {
 				t.Logf("- %s", diag.Error())
 			}
+			return
+		}
+
+		// The body must also be safe to inspect and evaluate, since the
+		// JSON syntax defers much of its interpretation until then.
+		attrs, _ := file.Body.JustAttributes()
+		for _, attr := range attrs {
+			attr.Expr.Value(nil)
+			attr.Expr.Variables()
+			hcl.ExprList(attr.Expr)
+			hcl.ExprMap(attr.Expr)
+			hcl.AbsTraversalForExpr(attr.Expr)
+		}
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{{Name: "a"}},
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: "b"},
+				{Type: "c", LabelNames: []string{"name"}},
+				{Type: "d", LabelNames: []string{"type", "name"}},
+			},
+		})
+		for _, block := range content.Blocks {
+			block.Body.JustAttributes()
 		}
 	})
 }
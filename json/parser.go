@@ -4,16 +4,26 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
-func parseFileContent(buf []byte, filename string, start hcl.Pos) (node, hcl.Diagnostics) {
-	tokens := scan(buf, pos{Filename: filename, Pos: start})
-	p := newPeeker(tokens)
+func parseFileContent(buf []byte, filename string, start hcl.Pos, opts ParseOptions) (node, hcl.Diagnostics) {
+	// Some editors write a UTF-8 byte order mark at the start of a file. We
+	// skip it, but still count its bytes so that the byte offsets in source
+	// ranges remain relative to the original buffer.
+	if bytes.HasPrefix(buf, []byte(hclsyntax.UTF8BOM)) {
+		buf = buf[len(hclsyntax.UTF8BOM):]
+		start.Byte += len(hclsyntax.UTF8BOM)
+	}
+
+	tokens, scanDiags := scan(buf, pos{Filename: filename, Pos: start}, opts.AllowComments)
+	p := newPeeker(tokens, opts)
 	node, diags := parseValue(p)
 	if len(diags) == 0 && p.Peek().Type != tokenEOF {
 		diags = diags.Append(&hcl.Diagnostic{
@@ -23,12 +33,12 @@ func parseFileContent(buf []byte, filename string, start hcl.Pos) (node, hcl.Dia
 			Subject:  p.Peek().Range.Ptr(),
 		})
 	}
-	return node, diags
+	return node, append(scanDiags, diags...)
 }
 
-func parseExpression(buf []byte, filename string, start hcl.Pos) (node, hcl.Diagnostics) {
-	tokens := scan(buf, pos{Filename: filename, Pos: start})
-	p := newPeeker(tokens)
+func parseExpression(buf []byte, filename string, start hcl.Pos, opts ParseOptions) (node, hcl.Diagnostics) {
+	tokens, scanDiags := scan(buf, pos{Filename: filename, Pos: start}, opts.AllowComments)
+	p := newPeeker(tokens, opts)
 	node, diags := parseValue(p)
 	if len(diags) == 0 && p.Peek().Type != tokenEOF {
 		diags = diags.Append(&hcl.Diagnostic{
@@ -38,7 +48,7 @@ func parseExpression(buf []byte, filename string, start hcl.Pos) (node, hcl.Diag
 			Subject:  p.Peek().Range.Ptr(),
 		})
 	}
-	return node, diags
+	return node, append(scanDiags, diags...)
 }
 
 func parseValue(p *peeker) (node, hcl.Diagnostics) {
@@ -52,9 +62,15 @@ func parseValue(p *peeker) (node, hcl.Diagnostics) {
 	}
 
 	switch tok.Type {
-	case tokenBraceO:
-		return wrapInvalid(parseObject(p))
-	case tokenBrackO:
+	case tokenBraceO, tokenBrackO:
+		if p.depth >= p.maxNestingDepth() {
+			return parseTooDeep(p)
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+		if tok.Type == tokenBraceO {
+			return wrapInvalid(parseObject(p))
+		}
 		return wrapInvalid(parseArray(p))
 	case tokenNumber:
 		return wrapInvalid(parseNumber(p))
@@ -101,6 +117,40 @@ func parseValue(p *peeker) (node, hcl.Diagnostics) {
 	}
 }
 
+// parseTooDeep skips over the object or array that begins at the next token
+// without parsing its content, returning an error diagnostic about it being
+// nested too deeply. Skipping the whole construct here, rather than letting
+// parseObject or parseArray recover, avoids recursing any further.
+func parseTooDeep(p *peeker) (node, hcl.Diagnostics) {
+	start := p.Read()
+	end := start
+	open := 1
+	for open > 0 {
+		tok := p.Peek()
+		switch tok.Type {
+		case tokenBraceO, tokenBrackO:
+			open++
+		case tokenBraceC, tokenBrackC:
+			open--
+		case tokenEOF:
+			// Ran out of source before we were able to recover, so we'll
+			// bail here and let the caller deal with it.
+			open = 0
+			continue
+		}
+		end = p.Read()
+	}
+
+	return invalidVal{hcl.RangeBetween(start.Range, end.Range)}, hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Nesting too deep",
+			Detail:   fmt.Sprintf("Objects and arrays may be nested no more than %d levels deep.", p.maxNestingDepth()),
+			Subject:  &start.Range,
+		},
+	}
+}
+
 func tokenCanStartValue(tok token) bool {
 	switch tok.Type {
 	case tokenBraceO, tokenBrackO, tokenNumber, tokenString, tokenKeyword:
@@ -211,7 +261,7 @@ Token:
 		switch p.Peek().Type {
 		case tokenComma:
 			comma := p.Read()
-			if p.Peek().Type == tokenBraceC {
+			if p.Peek().Type == tokenBraceC && !p.trailingCommas {
 				// Special error message for this common mistake
 				return nil, diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
@@ -307,7 +357,7 @@ Token:
 		switch p.Peek().Type {
 		case tokenComma:
 			comma := p.Read()
-			if p.Peek().Type == tokenBrackC {
+			if p.Peek().Type == tokenBrackC && !p.trailingCommas {
 				// Special error message for this common mistake
 				return nil, diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
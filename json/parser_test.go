@@ -597,11 +597,54 @@ func TestParse(t *testing.T) {
 			}},
 			1,
 		},
+		{
+			`1e`,
+			invalidVal{hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 3, Byte: 2},
+			}},
+			1,
+		},
+		{
+			`-`,
+			invalidVal{hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 2, Byte: 1},
+			}},
+			1,
+		},
+		{
+			`"\ud800"`,
+			&stringVal{
+				Value: "\ufffd",
+				SrcRange: hcl.Range{
+					Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:   hcl.Pos{Line: 1, Column: 9, Byte: 8},
+				},
+			},
+			0,
+		},
+		{
+			`"\u12"`,
+			invalidVal{hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 7, Byte: 6},
+			}},
+			1,
+		},
+		{
+			`"\`,
+			invalidVal{hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:   hcl.Pos{Line: 1, Column: 3, Byte: 2},
+			}},
+			1,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.Input, func(t *testing.T) {
-			got, diag := parseFileContent([]byte(test.Input), "", hcl.Pos{Byte: 0, Line: 1, Column: 1})
+			got, diag := parseFileContent([]byte(test.Input), "", hcl.Pos{Byte: 0, Line: 1, Column: 1}, ParseOptions{})
 
 			if len(diag) != test.DiagCount {
 				t.Errorf("got %d diagnostics; want %d", len(diag), test.DiagCount)
@@ -643,7 +686,7 @@ func TestParseWithPos(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.Input, func(t *testing.T) {
-			got, diag := parseFileContent([]byte(test.Input), "", test.StartPos)
+			got, diag := parseFileContent([]byte(test.Input), "", test.StartPos, ParseOptions{})
 
 			if len(diag) != test.DiagCount {
 				t.Errorf("got %d diagnostics; want %d", len(diag), test.DiagCount)
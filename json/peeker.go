@@ -6,13 +6,31 @@ package json
 type peeker struct {
 	tokens []token
 	pos    int
+
+	// trailingCommas is true if the parser should accept a trailing comma
+	// after the final element of an object or array.
+	trailingCommas bool
+
+	// maxDepth is the maximum nesting depth of objects and arrays, and
+	// depth is the current nesting depth counted against it.
+	maxDepth int
+	depth    int
 }
 
-func newPeeker(tokens []token) *peeker {
+func newPeeker(tokens []token, opts ParseOptions) *peeker {
 	return &peeker{
-		tokens: tokens,
-		pos:    0,
+		tokens:         tokens,
+		pos:            0,
+		trailingCommas: opts.AllowTrailingCommas,
+		maxDepth:       opts.MaxNestingDepth,
+	}
+}
+
+func (p *peeker) maxNestingDepth() int {
+	if p.maxDepth > 0 {
+		return p.maxDepth
 	}
+	return DefaultMaxNestingDepth
 }
 
 func (p *peeker) Peek() token {
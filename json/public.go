@@ -30,7 +30,42 @@ func Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
 // In most cases json.Parse should be sufficient, but it can be useful for parsing
 // a part of JSON with correct positions.
 func ParseWithStartPos(src []byte, filename string, start hcl.Pos) (*hcl.File, hcl.Diagnostics) {
-	rootNode, diags := parseFileContent(src, filename, start)
+	return parseWithStartPos(src, filename, start, ParseOptions{})
+}
+
+// DefaultMaxNestingDepth is the maximum nesting depth of objects and arrays
+// accepted by the parser when no other limit is specified.
+const DefaultMaxNestingDepth = 4096
+
+// ParseOptions represents optional extensions to the JSON syntax that can be
+// enabled when parsing with ParseWithOptions. The zero value of ParseOptions
+// selects strict JSON, as accepted by Parse.
+type ParseOptions struct {
+	// AllowComments enables JavaScript-style comments, using either the
+	// "//" line comment or the "/* ... */" block comment syntax. Comments
+	// are treated as whitespace.
+	AllowComments bool
+
+	// AllowTrailingCommas permits a comma after the final property of an
+	// object or the final element of an array.
+	AllowTrailingCommas bool
+
+	// MaxNestingDepth is the maximum nesting depth of objects and arrays
+	// that the parser will accept. Input nested more deeply than this
+	// produces an error diagnostic rather than exhausting the stack. Zero
+	// selects DefaultMaxNestingDepth.
+	MaxNestingDepth int
+}
+
+// ParseWithOptions attempts to parse like json.Parse, but additionally
+// accepts the syntax extensions selected in the given options. This can be
+// used to load files in the "JSON with comments" dialect used by some tools.
+func ParseWithOptions(src []byte, filename string, opts ParseOptions) (*hcl.File, hcl.Diagnostics) {
+	return parseWithStartPos(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1}, opts)
+}
+
+func parseWithStartPos(src []byte, filename string, start hcl.Pos, opts ParseOptions) (*hcl.File, hcl.Diagnostics) {
+	rootNode, diags := parseFileContent(src, filename, start, opts)
 
 	switch rootNode.(type) {
 	case *objectVal, *arrayVal:
@@ -76,6 +111,14 @@ func ParseWithStartPos(src []byte, filename string, start hcl.Pos) (*hcl.File, h
 
 // ParseExpression parses the given buffer as a standalone JSON expression,
 // returning it as an instance of Expression.
+//
+// The result is interpreted as an attribute value in a JSON file would be:
+// when evaluated with a non-nil EvalContext a JSON string is a template,
+// while numbers, booleans and null are literals and arrays and objects
+// produce tuple and object values whose elements are interpreted in the same
+// way. This allows applications to accept standalone expressions in either
+// HCL syntax, by using this function or hclsyntax.ParseExpression depending
+// on the source.
 func ParseExpression(src []byte, filename string) (hcl.Expression, hcl.Diagnostics) {
 	return ParseExpressionWithStartPos(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
 }
@@ -84,7 +127,14 @@ func ParseExpression(src []byte, filename string) (hcl.Expression, hcl.Diagnosti
 // json.ParseExpression you can pass a start position of the given JSON
 // expression as a hcl.Pos.
 func ParseExpressionWithStartPos(src []byte, filename string, start hcl.Pos) (hcl.Expression, hcl.Diagnostics) {
-	node, diags := parseExpression(src, filename, start)
+	node, diags := parseExpression(src, filename, start, ParseOptions{})
+	return &expression{src: node}, diags
+}
+
+// ParseExpressionWithOptions parses like json.ParseExpression, but
+// additionally accepts the syntax extensions selected in the given options.
+func ParseExpressionWithOptions(src []byte, filename string, opts ParseOptions) (hcl.Expression, hcl.Diagnostics) {
+	node, diags := parseExpression(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1}, opts)
 	return &expression{src: node}, diags
 }
 
@@ -117,6 +117,30 @@ func TestParse_malformed(t *testing.T) {
 	}
 }
 
+func TestParse_utf8BOM(t *testing.T) {
+	src := "\xef\xbb\xbf{\"port\": 80}"
+	file, diags := Parse([]byte(src), "test.json")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	attr := attrs["port"]
+	if attr == nil {
+		t.Fatalf("missing attribute \"port\"")
+	}
+	// Byte offsets are relative to the original source, including the BOM.
+	if got, want := string(attr.NameRange.SliceBytes([]byte(src))), `"port"`; got != want {
+		t.Errorf("wrong source for name range %q; want %q", got, want)
+	}
+	if got, want := attr.NameRange.Start, (hcl.Pos{Line: 1, Column: 2, Byte: 4}); got != want {
+		t.Errorf("wrong name range start\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
 func TestParseWithStartPos(t *testing.T) {
 	src := `{
   "foo": {
@@ -187,6 +211,143 @@ func TestParseWithStartPos(t *testing.T) {
 	}
 }
 
+func TestParseWithOptions(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		opts      ParseOptions
+		diagCount int
+		want      map[string]hcl.Range
+	}{
+		"comments": {
+			`{
+  // Line comment
+  "foo": "bar", /* block
+  comment */ "baz": true // trailing
+}`,
+			ParseOptions{AllowComments: true},
+			0,
+			map[string]hcl.Range{
+				"foo": {
+					Start: hcl.Pos{Line: 3, Column: 10, Byte: 29},
+					End:   hcl.Pos{Line: 3, Column: 15, Byte: 34},
+				},
+				"baz": {
+					Start: hcl.Pos{Line: 4, Column: 21, Byte: 65},
+					End:   hcl.Pos{Line: 4, Column: 25, Byte: 69},
+				},
+			},
+		},
+		"comments not allowed": {
+			`{
+  // Line comment
+  "foo": "bar"
+}`,
+			ParseOptions{},
+			3,
+			nil,
+		},
+		"unterminated block comment": {
+			`{"foo": "bar"} /* oops`,
+			ParseOptions{AllowComments: true},
+			1,
+			nil,
+		},
+		"trailing commas not allowed": {
+			`{"foo": ["bar",],}`,
+			ParseOptions{AllowComments: true},
+			3,
+			nil,
+		},
+		"trailing commas": {
+			`{"foo": ["bar",],}`,
+			ParseOptions{AllowTrailingCommas: true},
+			0,
+			map[string]hcl.Range{
+				"foo": {
+					Start: hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					End:   hcl.Pos{Line: 1, Column: 17, Byte: 16},
+				},
+			},
+		},
+		"nesting within limit": {
+			`{"foo": [[1]]}`,
+			ParseOptions{MaxNestingDepth: 3},
+			0,
+			map[string]hcl.Range{
+				"foo": {
+					Start: hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					End:   hcl.Pos{Line: 1, Column: 14, Byte: 13},
+				},
+			},
+		},
+		"nesting too deep": {
+			`{"foo": [[{"a": [1]}]], "bar": true}`,
+			ParseOptions{MaxNestingDepth: 3},
+			1,
+			nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := ParseWithOptions([]byte(test.src), "", test.opts)
+			if got, want := len(diags), test.diagCount; got != want {
+				t.Errorf("got %d diagnostics; want %d", got, want)
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+			}
+			if diags.HasErrors() {
+				return
+			}
+
+			attrs, diags := file.Body.JustAttributes()
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics on decode: %s", diags.Error())
+			}
+			if got, want := len(attrs), len(test.want); got != want {
+				t.Fatalf("got %d attributes; want %d", got, want)
+			}
+			for name, want := range test.want {
+				attr, ok := attrs[name]
+				if !ok {
+					t.Errorf("missing attribute %q", name)
+					continue
+				}
+				if got := attr.Expr.Range(); got.Start != want.Start || got.End != want.End {
+					t.Errorf("wrong range for %q\ngot:  %s\nwant: %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUnterminatedBlockComment(t *testing.T) {
+	src := "{\"foo\": \"bar\"}\n/* oops"
+	_, diags := ParseWithOptions([]byte(src), "test.json", ParseOptions{AllowComments: true})
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("got %d diagnostics; want %d\n%s", got, want, diags.Error())
+	}
+	want := "test.json:2,1-3: Unterminated block comment; There is no closing marker for this block comment, which starts here."
+	if got := diags[0].Error(); got != want {
+		t.Errorf("wrong diagnostic\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseNestingTooDeep(t *testing.T) {
+	src := `{"foo": ` + strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000) + `}`
+	_, diags := Parse([]byte(src), "")
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("got %d diagnostics; want %d\n%s", got, want, diags.Error())
+	}
+	if got, want := diags[0].Summary, "Nesting too deep"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diags[0].Subject.Start.Byte, 8+DefaultMaxNestingDepth-1; got != want {
+		t.Errorf("wrong subject start byte %d; want %d", got, want)
+	}
+}
+
 func TestParseExpression(t *testing.T) {
 	tests := []struct {
 		Input string
@@ -274,6 +435,41 @@ func TestParseExpression_malformed(t *testing.T) {
 	}
 }
 
+func TestParseExpressionWithOptions(t *testing.T) {
+	src := `[
+  // The greeting
+  "hello ${name}",
+  2,
+]`
+
+	if _, diags := ParseExpression([]byte(src), ""); !diags.HasErrors() {
+		t.Errorf("unexpected success without options")
+	}
+
+	expr, diags := ParseExpressionWithOptions([]byte(src), "", ParseOptions{
+		AllowComments:       true,
+		AllowTrailingCommas: true,
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	got, diags := expr.Value(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+		},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	want := cty.TupleVal([]cty.Value{
+		cty.StringVal("hello world"),
+		cty.NumberIntVal(2),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
 func TestParseExpressionWithStartPos(t *testing.T) {
 	src := `{
   "foo": "bar"
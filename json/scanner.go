@@ -41,8 +41,13 @@ type token struct {
 // token types keyword, string and number, preferring to capture erroneous
 // extra bytes that we presume the user intended to be part of the token
 // so that we can generate more helpful diagnostics in the parser.
-func scan(buf []byte, start pos) []token {
+//
+// If comments is true then JavaScript-style line and block comments are
+// treated as whitespace, and the returned diagnostics report any block
+// comment that is not terminated. Otherwise the diagnostics are always empty.
+func scan(buf []byte, start pos, comments bool) ([]token, hcl.Diagnostics) {
 	var tokens []token
+	var diags hcl.Diagnostics
 	p := start
 	for {
 		if len(buf) == 0 {
@@ -51,10 +56,20 @@ func scan(buf []byte, start pos) []token {
 				Bytes: nil,
 				Range: posRange(p, p),
 			})
-			return tokens
+			return tokens, diags
 		}
 
 		buf, p = skipWhitespace(buf, p)
+		for comments {
+			var skipped bool
+			var commentDiags hcl.Diagnostics
+			buf, p, skipped, commentDiags = skipComment(buf, p)
+			diags = append(diags, commentDiags...)
+			if !skipped {
+				break
+			}
+			buf, p = skipWhitespace(buf, p)
+		}
 
 		if len(buf) == 0 {
 			tokens = append(tokens, token{
@@ -62,7 +77,7 @@ func scan(buf []byte, start pos) []token {
 				Bytes: nil,
 				Range: posRange(p, p),
 			})
-			return tokens
+			return tokens, diags
 		}
 
 		start = p
@@ -119,7 +134,7 @@ func scan(buf []byte, start pos) []token {
 				Bytes: nil,
 				Range: posRange(p, p),
 			})
-			return tokens
+			return tokens, diags
 		}
 	}
 }
@@ -275,6 +290,63 @@ Byte:
 	return buf[i:], p
 }
 
+// skipComment skips over a single JavaScript-style comment at the start of
+// the given buffer, if present, returning the remaining buffer and the
+// position after the comment. The final result is false if the buffer does
+// not begin with a comment.
+//
+// A line comment ends before the next newline, leaving the newline to be
+// skipped as whitespace. A block comment that is not terminated extends to
+// the end of the buffer, and is reported in the returned diagnostics.
+func skipComment(buf []byte, start pos) ([]byte, pos, bool, hcl.Diagnostics) {
+	if len(buf) < 2 || buf[0] != '/' || (buf[1] != '/' && buf[1] != '*') {
+		return buf, start, false, nil
+	}
+	block := buf[1] == '*'
+
+	p := start
+	p.Pos.Byte += 2
+	p.Pos.Column += 2
+	i := 2
+	for i < len(buf) {
+		switch {
+		case block && buf[i] == '*' && i+1 < len(buf) && buf[i+1] == '/':
+			p.Pos.Byte += 2
+			p.Pos.Column += 2
+			return buf[i+2:], p, true, nil
+		case buf[i] == '\n':
+			if !block {
+				return buf[i:], p, true, nil
+			}
+			p.Pos.Byte++
+			p.Pos.Column = 1
+			p.Pos.Line++
+			i++
+		case buf[i] == '\r':
+			// As in skipWhitespace, a carriage return takes up no space.
+			p.Pos.Byte++
+			i++
+		default:
+			advance, _, _ := textseg.ScanGraphemeClusters(buf[i:], true)
+			p.Pos.Byte += advance
+			p.Pos.Column++
+			i += advance
+		}
+	}
+	if block {
+		startRange := start.Range(2, 2)
+		return buf[i:], p, true, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unterminated block comment",
+				Detail:   "There is no closing marker for this block comment, which starts here.",
+				Subject:  &startRange,
+			},
+		}
+	}
+	return buf[i:], p, true, nil
+}
+
 type pos struct {
 	Filename string
 	Pos      hcl.Pos
@@ -862,7 +862,7 @@ func TestScan(t *testing.T) {
 					Column: 1,
 				},
 			}
-			got := scan(buf, start)
+			got, _ := scan(buf, start, false)
 
 			if !reflect.DeepEqual(got, test.Want) {
 				errMsg := &bytes.Buffer{}
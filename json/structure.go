@@ -5,6 +5,7 @@ package json
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -106,6 +107,16 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 	for _, blockS := range schema.Blocks {
 		blockSchemas[blockS.Type] = blockS
 	}
+	attrSchemasFold := map[string]hcl.AttributeSchema{}
+	blockSchemasFold := map[string]hcl.BlockHeaderSchema{}
+	if schema.CaseInsensitive {
+		for _, attrS := range schema.Attributes {
+			attrSchemasFold[strings.ToLower(attrS.Name)] = attrS
+		}
+		for _, blockS := range schema.Blocks {
+			blockSchemasFold[strings.ToLower(blockS.Type)] = blockS
+		}
+	}
 
 	for _, jsonAttr := range jsonAttrs {
 		attrName := jsonAttr.Name
@@ -113,12 +124,34 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 			continue
 		}
 
+		_, isAttr := attrSchemas[attrName]
+		_, isBlock := blockSchemas[attrName]
+		if !isAttr && !isBlock && schema.CaseInsensitive {
+			if attrS, defined := attrSchemasFold[strings.ToLower(attrName)]; defined {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Non-canonical argument name",
+					Detail:   fmt.Sprintf("The argument name %q should be written as %q. Names that differ only in letter case are deprecated.", attrName, attrS.Name),
+					Subject:  &jsonAttr.NameRange,
+				})
+				attrSchemas[attrName] = attrS
+			} else if blockS, defined := blockSchemasFold[strings.ToLower(attrName)]; defined {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Non-canonical block type",
+					Detail:   fmt.Sprintf("The block type %q should be written as %q. Names that differ only in letter case are deprecated.", attrName, blockS.Type),
+					Subject:  &jsonAttr.NameRange,
+				})
+				blockSchemas[attrName] = blockS
+			}
+		}
+
 		if attrS, defined := attrSchemas[attrName]; defined {
-			if existing, exists := content.Attributes[attrName]; exists {
+			if existing, exists := content.Attributes[attrS.Name]; exists {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Duplicate argument",
-					Detail:   fmt.Sprintf("The argument %q was already set at %s.", attrName, existing.Range),
+					Detail:   fmt.Sprintf("The argument %q was already set at %s.", attrS.Name, existing.Range),
 					Subject:  &jsonAttr.NameRange,
 					Context:  jsonAttr.Range().Ptr(),
 				})
@@ -219,6 +252,13 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 	return attrs, diags
 }
 
+// JustAttributesPartial is the same as JustAttributes, because JSON cannot
+// distinguish blocks from attributes without a schema and so a JSON body
+// never contains blocks that would need to be ignored.
+func (b *body) JustAttributesPartial() (hcl.Attributes, hcl.Diagnostics) {
+	return b.JustAttributes()
+}
+
 func (b *body) MissingItemRange() hcl.Range {
 	switch tv := b.val.(type) {
 	case *objectVal:
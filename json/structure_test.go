@@ -1120,6 +1120,43 @@ func TestBodyContent(t *testing.T) {
 			},
 			2,
 		},
+		{
+			`{"Region": "us-east-1", "Resource": {"foo": {}}}`,
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{
+					{
+						Name:     "region",
+						Required: true,
+					},
+				},
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:       "resource",
+						LabelNames: []string{"name"},
+					},
+				},
+			},
+			3, // missing required argument, plus two extraneous properties
+		},
+		{
+			`{"Region": "us-east-1", "Resource": {"foo": {}}}`,
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{
+					{
+						Name:     "region",
+						Required: true,
+					},
+				},
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:       "resource",
+						LabelNames: []string{"name"},
+					},
+				},
+				CaseInsensitive: true,
+			},
+			2, // non-canonical name warnings only
+		},
 	}
 
 	for i, test := range tests {
@@ -1128,7 +1165,18 @@ func TestBodyContent(t *testing.T) {
 			if len(diags) != 0 {
 				t.Fatalf("Parse produced diagnostics: %s", diags)
 			}
-			_, diags = file.Body.Content(test.schema)
+			content, diags := file.Body.Content(test.schema)
+			if test.schema.CaseInsensitive {
+				if diags.HasErrors() {
+					t.Errorf("unexpected errors: %s", diags.Error())
+				}
+				if _, ok := content.Attributes["region"]; !ok {
+					t.Errorf("missing canonical attribute \"region\"")
+				}
+				if len(content.Blocks) != 1 || content.Blocks[0].Type != "resource" {
+					t.Errorf("wrong blocks %s", spew.Sdump(content.Blocks))
+				}
+			}
 			if len(diags) != test.diagCount {
 				t.Errorf("Wrong number of diagnostics %d; want %d", len(diags), test.diagCount)
 				for _, diag := range diags {
@@ -1237,6 +1285,22 @@ func TestJustAttributes(t *testing.T) {
 	}
 }
 
+func TestJustAttributesPartial(t *testing.T) {
+	file, diags := Parse([]byte(`{"foo": true, "bar": {"baz": 1}}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	want, wantDiags := file.Body.JustAttributes()
+	got, gotDiags := hcl.JustAttributesPartial(file.Body)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+	if !reflect.DeepEqual(gotDiags, wantDiags) {
+		t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", spew.Sdump(gotDiags), spew.Sdump(wantDiags))
+	}
+}
+
 func TestExpressionVariables(t *testing.T) {
 	tests := []struct {
 		Src  string
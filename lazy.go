@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// LazyResolver is the signature of a function that produces the values
+// within a lazily-resolved variable created by LazyVal.
+//
+// The given path is relative to the variable, and is empty when the value of
+// the whole variable is needed. The result is the value at that path, which
+// may itself be another lazy value if its content is also to be resolved on
+// demand, except that the result for an empty path must not be a lazy value.
+// If there is no value at the given path then the resolver must return an
+// error, whose message is used as the detail of an error diagnostic and so
+// should be written as a full sentence, such as "This object does not have
+// an attribute named "foo"."
+//
+// A resolver is called each time an expression that refers to the variable
+// is evaluated, and the results are not cached. Because expressions may be
+// evaluated concurrently using the same EvalContext, a resolver must be safe
+// to call concurrently from multiple goroutines unless the application
+// ensures that the contexts containing it are used by only one goroutine at
+// a time. A resolver whose values are expensive to produce should cache them
+// itself, taking care to synchronize access to the cache.
+type LazyResolver func(path cty.Path) (cty.Value, error)
+
+type lazyValue struct {
+	resolve LazyResolver
+}
+
+var lazyType = cty.Capsule("lazy value", reflect.TypeOf(lazyValue{}))
+
+// LazyVal returns a value that can be placed in the Variables map of an
+// EvalContext, or returned from its VariableResolver, so that the content of
+// the variable is produced on demand by the given resolver.
+//
+// When an absolute traversal such as config.a["b"].c is evaluated with
+// Traversal.TraverseAbs, the longest leading sequence of attribute and
+// index steps with known, non-null keys after the root is passed to the
+// resolver as a single path, and any remaining steps are applied to the
+// value it returns in the usual way. In particular, a reference to the
+// variable alone resolves the whole variable with an empty path.
+//
+// A lazy value is never returned from TraverseAbs itself, and so for
+// expressions that refer to variables only through traversals, such as
+// those of the hclsyntax package, the laziness is invisible to the rest of
+// the evaluation. Expressions that look up an attribute or element
+// dynamically, such as config[var.key], still resolve the part of the
+// variable before the dynamic step in full.
+func LazyVal(resolve LazyResolver) cty.Value {
+	return cty.CapsuleVal(lazyType, &lazyValue{resolve: resolve})
+}
+
+// IsLazyVal returns true if the given value was returned by LazyVal.
+func IsLazyVal(val cty.Value) bool {
+	return val.Type() == lazyType && val.IsKnown() && !val.IsNull()
+}
+
+// resolveLazy resolves the given value, if it is a lazy value, using as many
+// of the leading steps of the given relative traversal as the resolver can
+// handle, and returns the result along with the remaining steps. The given
+// range is that of the part of the traversal that produced the value.
+func resolveLazy(val cty.Value, rng Range, steps Traversal) (cty.Value, Traversal, Diagnostics) {
+	for IsLazyVal(val) {
+		lazy := val.EncapsulatedValue().(*lazyValue)
+
+		var path cty.Path
+		for _, step := range steps {
+			if step, ok := step.(TraverseAttr); ok {
+				path = path.GetAttr(step.Name)
+				continue
+			}
+			if step, ok := step.(TraverseIndex); ok && step.Key.IsKnown() && !step.Key.IsNull() {
+				path = path.Index(step.Key)
+				continue
+			}
+			break
+		}
+		if len(path) > 0 {
+			rng = RangeBetween(rng, steps[len(path)-1].SourceRange())
+			steps = steps[len(path):]
+		}
+
+		var err error
+		val, err = lazy.resolve(path)
+		if err == nil && len(path) == 0 && IsLazyVal(val) {
+			// A resolver must produce a concrete value for the whole of
+			// its variable, or we would never stop resolving.
+			val = cty.DynamicVal
+		}
+		if err != nil {
+			return cty.DynamicVal, nil, Diagnostics{
+				{
+					Severity: DiagError,
+					Summary:  "Invalid reference",
+					Detail:   err.Error(),
+					Subject:  rng.Ptr(),
+				},
+			}
+		}
+	}
+	return val, steps, nil
+}
@@ -24,7 +24,8 @@ func MergeFiles(files []*File) Body {
 }
 
 // MergeBodies is like MergeFiles except it deals directly with bodies, rather
-// than with entire files.
+// than with entire files. Use OverrideBodies instead to allow later bodies to
+// override attributes defined in earlier ones.
 func MergeBodies(bodies []Body) Body {
 	if len(bodies) == 0 {
 		// Swap out for our singleton empty body, to reduce the number of
@@ -67,6 +68,63 @@ func MergeBodies(bodies []Body) Body {
 	return mergedBodies(new)
 }
 
+// OverrideBodies is like MergeBodies except that an attribute may be
+// defined in more than one of the given bodies, in which case the definition
+// from the latest body takes precedence and the earlier definitions are
+// ignored. This is useful for layering an override configuration over a
+// base configuration.
+//
+// Blocks are not overridden: all of the blocks from all of the bodies are
+// returned, in order, just as for MergeBodies. Diagnostics produced by the
+// underlying bodies are returned unchanged.
+func OverrideBodies(bodies []Body) Body {
+	if len(bodies) == 0 {
+		return emptyBody
+	}
+	return overrideBodies(bodies)
+}
+
+// SingletonBlockConflicts checks that the given body, typically the result
+// of MergeBodies or OverrideBodies over several files, contains at most one
+// block of each of the block types in the given schema, returning an error
+// diagnostic for each additional definition of such a block type.
+//
+// Each diagnostic has the header of the additional block as its subject and
+// describes where the first block of that type was defined, which is often
+// in a different file. Attributes in the schema are ignored, and any blocks
+// of other types are allowed to appear any number of times. Diagnostics
+// returned by the body while retrieving its blocks, such as for a block with
+// the wrong number of labels, are returned along with the conflicts.
+//
+// Blocks are not overridden by OverrideBodies, so applications using it to
+// layer configuration can use this function to reject multiple definitions
+// of block types where only one definition makes sense.
+func SingletonBlockConflicts(body Body, schema *BodySchema) Diagnostics {
+	content, _, diags := body.PartialContent(&BodySchema{
+		Blocks: schema.Blocks,
+	})
+
+	first := make(map[string]*Block, len(schema.Blocks))
+	for _, block := range content.Blocks {
+		prev, exists := first[block.Type]
+		if !exists {
+			first[block.Type] = block
+			continue
+		}
+		diags = diags.Append(&Diagnostic{
+			Severity: DiagError,
+			Summary:  fmt.Sprintf("Duplicate %s block", block.Type),
+			Detail: fmt.Sprintf(
+				"Only one block of type %q is allowed. Previous definition was at %s.",
+				block.Type, prev.DefRange.String(),
+			),
+			Subject: block.DefRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
 var emptyBody = mergedBodies([]Body{})
 
 // EmptyBody returns a body with no content. This body can be used as a
@@ -88,20 +146,31 @@ type mergedBodies []Body
 func (mb mergedBodies) Content(schema *BodySchema) (*BodyContent, Diagnostics) {
 	// the returned body will always be empty in this case, because mergedContent
 	// will only ever call Content on the child bodies.
-	content, _, diags := mb.mergedContent(schema, false)
+	content, _, diags := mb.mergedContent(schema, false, false)
 	return content, diags
 }
 
 func (mb mergedBodies) PartialContent(schema *BodySchema) (*BodyContent, Body, Diagnostics) {
-	return mb.mergedContent(schema, true)
+	return mb.mergedContent(schema, true, false)
 }
 
 func (mb mergedBodies) JustAttributes() (Attributes, Diagnostics) {
+	return mb.justAttributes(Body.JustAttributes, false)
+}
+
+func (mb mergedBodies) JustAttributesPartial() (Attributes, Diagnostics) {
+	return mb.justAttributes(JustAttributesPartial, false)
+}
+
+// justAttributes merges the attributes returned by calling the given function
+// on each of the bodies. If override is set then later attributes replace
+// earlier ones of the same name, rather than being reported as duplicates.
+func (mb mergedBodies) justAttributes(get func(Body) (Attributes, Diagnostics), override bool) (Attributes, Diagnostics) {
 	attrs := make(map[string]*Attribute)
 	var diags Diagnostics
 
 	for _, body := range mb {
-		thisAttrs, thisDiags := body.JustAttributes()
+		thisAttrs, thisDiags := get(body)
 
 		if len(thisDiags) != 0 {
 			diags = append(diags, thisDiags...)
@@ -109,7 +178,7 @@ func (mb mergedBodies) JustAttributes() (Attributes, Diagnostics) {
 
 		if thisAttrs != nil {
 			for name, attr := range thisAttrs {
-				if existing := attrs[name]; existing != nil {
+				if existing := attrs[name]; existing != nil && !override {
 					diags = diags.Append(&Diagnostic{
 						Severity: DiagError,
 						Summary:  "Duplicate argument",
@@ -142,7 +211,10 @@ func (mb mergedBodies) MissingItemRange() Range {
 	return mb[0].MissingItemRange()
 }
 
-func (mb mergedBodies) mergedContent(schema *BodySchema, partial bool) (*BodyContent, Body, Diagnostics) {
+// mergedContent merges the content returned by each of the bodies for the
+// given schema. If override is set then later attributes replace earlier
+// ones of the same name, rather than being reported as duplicates.
+func (mb mergedBodies) mergedContent(schema *BodySchema, partial, override bool) (*BodyContent, Body, Diagnostics) {
 	// We need to produce a new schema with none of the attributes marked as
 	// required, since _any one_ of our bodies can contribute an attribute value.
 	// We'll separately check that all required attributes are present at
@@ -182,7 +254,7 @@ func (mb mergedBodies) mergedContent(schema *BodySchema, partial bool) (*BodyCon
 
 		if thisContent.Attributes != nil {
 			for name, attr := range thisContent.Attributes {
-				if existing := content.Attributes[name]; existing != nil {
+				if existing := content.Attributes[name]; existing != nil && !override {
 					diags = diags.Append(&Diagnostic{
 						Severity: DiagError,
 						Summary:  "Duplicate argument",
@@ -224,6 +296,36 @@ func (mb mergedBodies) mergedContent(schema *BodySchema, partial bool) (*BodyCon
 		}
 	}
 
-	leftoverBody := MergeBodies(mergedLeftovers)
+	var leftoverBody Body
+	if override {
+		leftoverBody = OverrideBodies(mergedLeftovers)
+	} else {
+		leftoverBody = MergeBodies(mergedLeftovers)
+	}
 	return content, leftoverBody, diags
 }
+
+// overrideBodies is the implementation of OverrideBodies, which shares the
+// implementation of mergedBodies with attribute overriding enabled.
+type overrideBodies []Body
+
+func (ob overrideBodies) Content(schema *BodySchema) (*BodyContent, Diagnostics) {
+	content, _, diags := mergedBodies(ob).mergedContent(schema, false, true)
+	return content, diags
+}
+
+func (ob overrideBodies) PartialContent(schema *BodySchema) (*BodyContent, Body, Diagnostics) {
+	return mergedBodies(ob).mergedContent(schema, true, true)
+}
+
+func (ob overrideBodies) JustAttributes() (Attributes, Diagnostics) {
+	return mergedBodies(ob).justAttributes(Body.JustAttributes, true)
+}
+
+func (ob overrideBodies) JustAttributesPartial() (Attributes, Diagnostics) {
+	return mergedBodies(ob).justAttributes(JustAttributesPartial, true)
+}
+
+func (ob overrideBodies) MissingItemRange() Range {
+	return mergedBodies(ob).MissingItemRange()
+}
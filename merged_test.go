@@ -561,6 +561,172 @@ func TestMergeBodiesPartialContent(t *testing.T) {
 	}
 }
 
+func TestMergedBodiesJustAttributesPartial(t *testing.T) {
+	merged := MergeBodies([]Body{
+		&testMergedBodiesPartialVictim{
+			&testMergedBodiesVictim{
+				Name:          "first",
+				HasAttributes: []string{"name"},
+				DiagCount:     1,
+			},
+		},
+		&testMergedBodiesVictim{
+			Name:          "second",
+			HasAttributes: []string{"age"},
+		},
+	})
+
+	got, diags := JustAttributesPartial(merged)
+	if len(diags) != 1 || diags[0].Severity != DiagWarning {
+		t.Errorf("wrong diagnostics; want a single warning")
+		for _, diag := range diags {
+			t.Logf("- %s", diag.Error())
+		}
+	}
+	if _, ok := got["name"]; !ok {
+		t.Errorf("missing attribute %q", "name")
+	}
+	if _, ok := got["age"]; !ok {
+		t.Errorf("missing attribute %q", "age")
+	}
+
+	if _, diags := merged.JustAttributes(); !diags.HasErrors() {
+		t.Errorf("JustAttributes succeeded; want the strict errors")
+	}
+}
+
+func TestOverrideBodies(t *testing.T) {
+	body := OverrideBodies([]Body{
+		&testMergedBodiesVictim{
+			Name:          "base",
+			HasAttributes: []string{"name", "size"},
+			HasBlocks:     map[string]int{"item": 1},
+		},
+		&testMergedBodiesVictim{
+			Name:          "override",
+			HasAttributes: []string{"name", "extra"},
+			HasBlocks:     map[string]int{"item": 2},
+			DiagCount:     1,
+		},
+	})
+
+	schema := &BodySchema{
+		Attributes: []AttributeSchema{
+			{Name: "name", Required: true},
+			{Name: "size"},
+		},
+		Blocks: []BlockHeaderSchema{
+			{Type: "item"},
+		},
+	}
+
+	t.Run("PartialContent", func(t *testing.T) {
+		content, remain, diags := body.PartialContent(schema)
+		if len(diags) != 1 || diags[0].Summary != "Fake diagnostic 0" {
+			t.Errorf("wrong diagnostics: %s", diags.Error())
+		}
+		if got, want := content.Attributes["name"].NameRange.Filename, "override"; got != want {
+			t.Errorf("name is from %q; want %q", got, want)
+		}
+		if got, want := content.Attributes["size"].NameRange.Filename, "base"; got != want {
+			t.Errorf("size is from %q; want %q", got, want)
+		}
+		var blockSources []string
+		for _, block := range content.Blocks {
+			blockSources = append(blockSources, block.DefRange.Filename)
+		}
+		if got, want := blockSources, []string{"base", "override", "override"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong blocks %#v; want %#v", got, want)
+		}
+
+		attrs, _ := remain.JustAttributes()
+		if len(attrs) != 1 || attrs["extra"] == nil {
+			t.Errorf("wrong remaining attributes %#v", attrs)
+		}
+	})
+
+	t.Run("JustAttributes", func(t *testing.T) {
+		attrs, diags := body.JustAttributes()
+		if len(diags) != 1 {
+			t.Errorf("wrong diagnostics: %s", diags.Error())
+		}
+		want := map[string]string{
+			"name":  "override",
+			"size":  "base",
+			"extra": "override",
+		}
+		if len(attrs) != len(want) {
+			t.Errorf("wrong attributes %#v", attrs)
+		}
+		for name, wantSource := range want {
+			if attr := attrs[name]; attr == nil || attr.NameRange.Filename != wantSource {
+				t.Errorf("attribute %q is missing or not from %q", name, wantSource)
+			}
+		}
+	})
+}
+
+// testMergedBodiesPartialVictim is a testMergedBodiesVictim that also
+// implements JustAttributesPartialBody, downgrading its fake diagnostics to
+// warnings.
+func TestSingletonBlockConflicts(t *testing.T) {
+	body := MergeBodies([]Body{
+		&testMergedBodiesVictim{
+			Name:      "first",
+			HasBlocks: map[string]int{"backend": 1, "item": 1},
+		},
+		&testMergedBodiesVictim{
+			Name:      "second",
+			HasBlocks: map[string]int{"item": 2, "other": 1},
+		},
+		&testMergedBodiesVictim{
+			Name:      "third",
+			HasBlocks: map[string]int{"backend": 1},
+			DiagCount: 1,
+		},
+	})
+
+	schema := &BodySchema{
+		Attributes: []AttributeSchema{
+			{Name: "name", Required: true},
+		},
+		Blocks: []BlockHeaderSchema{
+			{Type: "backend"},
+		},
+	}
+
+	diags := SingletonBlockConflicts(body, schema)
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Error())
+	}
+	want := []string{
+		"<nil>: Fake diagnostic 0; For testing only.",
+		`third:0,0-0: Duplicate backend block; Only one block of type "backend" is allowed. Previous definition was at first:0,0-0.`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+
+	schema.Blocks = append(schema.Blocks, BlockHeaderSchema{Type: "item"})
+	diags = SingletonBlockConflicts(body, schema)
+	if got, want := len(diags), 4; got != want {
+		t.Errorf("wrong number of diagnostics %d; want %d: %s", got, want, diags.Error())
+	}
+}
+
+type testMergedBodiesPartialVictim struct {
+	*testMergedBodiesVictim
+}
+
+func (v *testMergedBodiesPartialVictim) JustAttributesPartial() (Attributes, Diagnostics) {
+	attrs, diags := v.JustAttributes()
+	for _, diag := range diags {
+		diag.Severity = DiagWarning
+	}
+	return attrs, diags
+}
+
 type testMergedBodiesVictim struct {
 	Name          string
 	HasAttributes []string
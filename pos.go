@@ -103,6 +103,9 @@ func RangeOver(a, b Range) Range {
 // ContainsPos returns true if and only if the given position is contained within
 // the receiving range.
 //
+// Ranges are half-open: the start position is included in the range but the
+// end position is not, so an empty range contains no positions at all.
+//
 // In the unlikely case that the line/column information disagree with the byte
 // offset information in the given position or receiving range, the byte
 // offsets are given priority.
@@ -111,7 +114,7 @@ func (r Range) ContainsPos(pos Pos) bool {
 }
 
 // ContainsOffset returns true if and only if the given byte offset is within
-// the receiving Range.
+// the receiving Range, using the same half-open semantics as ContainsPos.
 func (r Range) ContainsOffset(offset int) bool {
 	return offset >= r.Start.Byte && offset < r.End.Byte
 }
@@ -197,6 +200,11 @@ func (r Range) SliceBytes(b []byte) []byte {
 
 // Overlaps returns true if the receiver and the other given range share any
 // characters in common.
+//
+// Ranges are half-open, so two ranges where one ends at the same byte offset
+// where the other starts are adjacent and do not overlap. As with
+// ContainsPos, only the byte offsets are considered, and so this is correct
+// for ranges spanning multiple lines.
 func (r Range) Overlaps(other Range) bool {
 	switch {
 	case r.Filename != other.Filename:
@@ -205,12 +213,8 @@ func (r Range) Overlaps(other Range) bool {
 	case r.Empty() || other.Empty():
 		// Empty ranges can never overlap
 		return false
-	case r.ContainsOffset(other.Start.Byte) || r.ContainsOffset(other.End.Byte):
-		return true
-	case other.ContainsOffset(r.Start.Byte) || other.ContainsOffset(r.End.Byte):
-		return true
 	default:
-		return false
+		return r.Start.Byte < other.End.Byte && other.Start.Byte < r.End.Byte
 	}
 }
 
@@ -160,6 +160,104 @@ func TestRangeOver(t *testing.T) {
 	}
 }
 
+func TestRangeContainsPos(t *testing.T) {
+	// A range spanning from the middle of line 1 to the middle of line 2
+	rng := Range{
+		Start: Pos{Byte: 2, Line: 1, Column: 3},
+		End:   Pos{Byte: 7, Line: 2, Column: 3},
+	}
+	tests := []struct {
+		Pos  Pos
+		Want bool
+	}{
+		{Pos{Byte: 1, Line: 1, Column: 2}, false},
+		{Pos{Byte: 2, Line: 1, Column: 3}, true}, // start is inclusive
+		{Pos{Byte: 4, Line: 1, Column: 5}, true},
+		{Pos{Byte: 5, Line: 2, Column: 1}, true},
+		{Pos{Byte: 6, Line: 2, Column: 2}, true},
+		{Pos{Byte: 7, Line: 2, Column: 3}, false}, // end is exclusive
+		{Pos{Byte: 8, Line: 2, Column: 4}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d", test.Pos.Byte), func(t *testing.T) {
+			if got := rng.ContainsPos(test.Pos); got != test.Want {
+				t.Errorf("wrong result %t; want %t", got, test.Want)
+			}
+		})
+	}
+
+	empty := Range{
+		Start: Pos{Byte: 2, Line: 1, Column: 3},
+		End:   Pos{Byte: 2, Line: 1, Column: 3},
+	}
+	if empty.ContainsPos(empty.Start) {
+		t.Errorf("empty range contains its own start position")
+	}
+}
+
+func TestRangeOverlaps(t *testing.T) {
+	rng := func(start, end int) Range {
+		return Range{
+			Filename: "test.hcl",
+			Start:    Pos{Byte: start, Line: 1, Column: start + 1},
+			End:      Pos{Byte: end, Line: 1, Column: end + 1},
+		}
+	}
+	tests := []struct {
+		A    Range
+		B    Range
+		Want bool
+	}{
+		{rng(0, 2), rng(4, 6), false},
+		{rng(0, 2), rng(2, 4), false}, // adjacent
+		{rng(2, 4), rng(0, 2), false}, // adjacent
+		{rng(0, 3), rng(2, 4), true},
+		{rng(2, 4), rng(0, 3), true},
+		{rng(0, 6), rng(2, 4), true},
+		{rng(2, 4), rng(0, 6), true},
+		{rng(2, 4), rng(2, 4), true},
+		{rng(2, 2), rng(0, 4), false}, // empty
+		{rng(0, 4), rng(2, 2), false}, // empty
+		{
+			rng(0, 4),
+			Range{
+				Filename: "other.hcl",
+				Start:    Pos{Byte: 0, Line: 1, Column: 1},
+				End:      Pos{Byte: 4, Line: 1, Column: 5},
+			},
+			false,
+		},
+		{
+			// Multi-line ranges are compared by byte offset only
+			Range{
+				Filename: "test.hcl",
+				Start:    Pos{Byte: 0, Line: 1, Column: 1},
+				End:      Pos{Byte: 10, Line: 3, Column: 2},
+			},
+			Range{
+				Filename: "test.hcl",
+				Start:    Pos{Byte: 9, Line: 3, Column: 1},
+				End:      Pos{Byte: 12, Line: 3, Column: 4},
+			},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s<=>%s", test.A, test.B), func(t *testing.T) {
+			if got := test.A.Overlaps(test.B); got != test.Want {
+				t.Errorf(
+					"wrong result %t; want %t\nA: %-10s %s\nB: %-10s %s",
+					got, test.Want,
+					visRangeOffsets(test.A), test.A,
+					visRangeOffsets(test.B), test.B,
+				)
+			}
+		})
+	}
+}
+
 func TestPosOverlap(t *testing.T) {
 	tests := []struct {
 		A    Range
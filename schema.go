@@ -3,11 +3,23 @@
 
 package hcl
 
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
 // BlockHeaderSchema represents the shape of a block header, and is
 // used for matching blocks within bodies.
 type BlockHeaderSchema struct {
 	Type       string
 	LabelNames []string
+
+	// ExtraLabels, if set, allows blocks of this type to have any number of
+	// additional labels after those named in LabelNames.
+	//
+	// The JSON syntax cannot distinguish labels from the property names of
+	// a block body, so it always expects exactly the labels named in
+	// LabelNames.
+	ExtraLabels bool
 }
 
 // AttributeSchema represents the requirements for an attribute, and is used
@@ -15,10 +27,89 @@ type BlockHeaderSchema struct {
 type AttributeSchema struct {
 	Name     string
 	Required bool
+
+	// Type, if not cty.NilType, is the type that DecodeBodyTyped converts
+	// the attribute's value to. It has no effect on matching attributes.
+	Type cty.Type
 }
 
 // BodySchema represents the desired shallow structure of a body.
 type BodySchema struct {
 	Attributes []AttributeSchema
 	Blocks     []BlockHeaderSchema
+
+	// CaseInsensitive, if set, allows attribute names and block types in
+	// the body to match the schema even if they differ from it in letter
+	// case. Content returned for such items uses the name given in the
+	// schema, and a warning diagnostic is produced for each one to
+	// encourage updating the configuration to use the canonical name.
+	//
+	// An item whose name matches the schema exactly is always preferred
+	// over one that matches only when ignoring case.
+	CaseInsensitive bool
+}
+
+// AttributeNames returns the names of all of the attributes in the schema,
+// in the order they are declared.
+func (s *BodySchema) AttributeNames() []string {
+	return s.Summary().Attributes
+}
+
+// RequiredAttributeNames returns the names of the attributes in the schema
+// that are marked as required, in the order they are declared.
+func (s *BodySchema) RequiredAttributeNames() []string {
+	return s.Summary().RequiredAttributes
+}
+
+// BlockTypes returns the distinct block type names in the schema, in the
+// order they are first declared.
+func (s *BodySchema) BlockTypes() []string {
+	return s.Summary().BlockTypes
+}
+
+// BodySchemaSummary describes the names that a BodySchema refers to, as
+// returned by BodySchema.Summary.
+type BodySchemaSummary struct {
+	// Attributes is the names of all of the attributes in the schema, and
+	// RequiredAttributes is the subset of those that are required.
+	Attributes         []string
+	RequiredAttributes []string
+
+	// BlockTypes is the distinct block type names in the schema.
+	BlockTypes []string
+}
+
+// Summary returns the attribute names and block types that the schema
+// refers to, which can be used to compare a schema with its documentation
+// without decoding a body. Each list is in the order the items are declared
+// in the schema, and each name appears at most once. The receiver may be
+// nil, in which case the result has empty lists.
+func (s *BodySchema) Summary() BodySchemaSummary {
+	var ret BodySchemaSummary
+	if s == nil {
+		return ret
+	}
+
+	seen := make(map[string]struct{})
+	for _, attrS := range s.Attributes {
+		if _, exists := seen[attrS.Name]; exists {
+			continue
+		}
+		seen[attrS.Name] = struct{}{}
+		ret.Attributes = append(ret.Attributes, attrS.Name)
+		if attrS.Required {
+			ret.RequiredAttributes = append(ret.RequiredAttributes, attrS.Name)
+		}
+	}
+
+	seen = make(map[string]struct{})
+	for _, blockS := range s.Blocks {
+		if _, exists := seen[blockS.Type]; exists {
+			continue
+		}
+		seen[blockS.Type] = struct{}{}
+		ret.BlockTypes = append(ret.BlockTypes, blockS.Type)
+	}
+
+	return ret
 }
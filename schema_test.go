@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBodySchemaSummary(t *testing.T) {
+	schema := &BodySchema{
+		Attributes: []AttributeSchema{
+			{Name: "name", Required: true},
+			{Name: "description"},
+			{Name: "count", Required: true},
+		},
+		Blocks: []BlockHeaderSchema{
+			{Type: "network"},
+			{Type: "disk", LabelNames: []string{"name"}},
+			{Type: "network", LabelNames: []string{"name"}},
+		},
+	}
+
+	want := BodySchemaSummary{
+		Attributes:         []string{"name", "description", "count"},
+		RequiredAttributes: []string{"name", "count"},
+		BlockTypes:         []string{"network", "disk"},
+	}
+	if diff := cmp.Diff(want, schema.Summary()); diff != "" {
+		t.Errorf("wrong summary\n%s", diff)
+	}
+	if diff := cmp.Diff(want.Attributes, schema.AttributeNames()); diff != "" {
+		t.Errorf("wrong attribute names\n%s", diff)
+	}
+	if diff := cmp.Diff(want.RequiredAttributes, schema.RequiredAttributeNames()); diff != "" {
+		t.Errorf("wrong required attribute names\n%s", diff)
+	}
+	if diff := cmp.Diff(want.BlockTypes, schema.BlockTypes()); diff != "" {
+		t.Errorf("wrong block types\n%s", diff)
+	}
+
+	var nilSchema *BodySchema
+	if diff := cmp.Diff(BodySchemaSummary{}, nilSchema.Summary()); diff != "" {
+		t.Errorf("wrong summary for nil schema\n%s", diff)
+	}
+}
@@ -41,3 +41,14 @@ func (e staticExpr) Range() Range {
 func (e staticExpr) StartRange() Range {
 	return e.rng
 }
+
+// NewLiteralExpr returns an Expression whose Value method ignores the given
+// EvalContext and always returns the given value, whose Variables method
+// returns nil, and whose Range and StartRange methods both return the given
+// range.
+//
+// It is equivalent to StaticExpr, and is provided under this name for
+// callers that are synthesizing bodies to decode, such as in tests.
+func NewLiteralExpr(val cty.Value, rng Range) Expression {
+	return StaticExpr(val, rng)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestNewLiteralExpr(t *testing.T) {
+	rng := Range{
+		Filename: "test.hcl",
+		Start:    Pos{Line: 1, Column: 1, Byte: 0},
+		End:      Pos{Line: 1, Column: 4, Byte: 3},
+	}
+	expr := NewLiteralExpr(cty.StringVal("foo"), rng)
+
+	ctx := &EvalContext{
+		Variables: map[string]cty.Value{
+			"foo": cty.StringVal("bar"),
+		},
+	}
+	for _, ctx := range []*EvalContext{nil, ctx} {
+		got, diags := expr.Value(ctx)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %s", diags.Error())
+		}
+		if want := cty.StringVal("foo"); !got.RawEquals(want) {
+			t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, want)
+		}
+	}
+	if got := expr.Variables(); got != nil {
+		t.Errorf("unexpected variables: %#v", got)
+	}
+	if got := expr.Range(); got != rng {
+		t.Errorf("wrong range\ngot:  %#v\nwant: %#v", got, rng)
+	}
+	if got := expr.StartRange(); got != rng {
+		t.Errorf("wrong start range\ngot:  %#v\nwant: %#v", got, rng)
+	}
+}
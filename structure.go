@@ -76,6 +76,35 @@ type Body interface {
 	MissingItemRange() Range
 }
 
+// JustAttributesPartialBody is an optional interface implemented by bodies
+// that can distinguish attributes from blocks, allowing JustAttributesPartial
+// to ignore any blocks that are present.
+type JustAttributesPartialBody interface {
+	Body
+
+	// JustAttributesPartial is like JustAttributes except that any blocks
+	// present in the body are ignored, producing at most a warning
+	// diagnostic rather than an error.
+	JustAttributesPartial() (Attributes, Diagnostics)
+}
+
+// JustAttributesPartial is like calling JustAttributes on the given body,
+// except that any blocks in the body are ignored rather than causing error
+// diagnostics, for callers that are interested only in the attributes.
+//
+// If blocks are present then the result includes a warning diagnostic about
+// them, which the caller may either show or discard. Other problems, such as
+// duplicate attribute definitions, are still reported as errors.
+//
+// This relies on the body implementing JustAttributesPartialBody. For any
+// other body, this is equivalent to calling its JustAttributes method.
+func JustAttributesPartial(body Body) (Attributes, Diagnostics) {
+	if pb, ok := body.(JustAttributesPartialBody); ok {
+		return pb.JustAttributesPartial()
+	}
+	return body.JustAttributes()
+}
+
 // BodyContent is the result of applying a BodySchema to a Body.
 type BodyContent struct {
 	Attributes Attributes
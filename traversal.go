@@ -5,6 +5,8 @@ package hcl
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/zclconf/go-cty/cty"
 )
@@ -38,6 +40,79 @@ func TraversalJoin(abs Traversal, rel Traversal) Traversal {
 	return ret
 }
 
+// RebaseTraversal replaces the given absolute oldRoot prefix of the given
+// traversal with the absolute traversal newRoot, returning the result as a
+// new traversal. For example, rebasing var.foo[0] from var to
+// local.imported.var produces local.imported.var.foo[0].
+//
+// The steps that follow the prefix are retained as-is, including their
+// source ranges. Since the steps of newRoot did not appear in the source,
+// they are all given the source range of the prefix they replace, so that
+// diagnostics about them still refer to the original reference.
+//
+// Steps are compared by name or key, ignoring source ranges. If t does not
+// begin with oldRoot then t is returned unchanged along with false. Panics
+// if either oldRoot or newRoot is relative.
+func RebaseTraversal(t, oldRoot, newRoot Traversal) (Traversal, bool) {
+	if oldRoot.IsRelative() {
+		panic("oldRoot argument to RebaseTraversal must be absolute")
+	}
+	if newRoot.IsRelative() {
+		panic("newRoot argument to RebaseTraversal must be absolute")
+	}
+	if len(t) < len(oldRoot) {
+		return t, false
+	}
+	for i, step := range oldRoot {
+		if !traverserEqual(t[i], step) {
+			return t, false
+		}
+	}
+
+	rng := t[:len(oldRoot)].SourceRange()
+	ret := make(Traversal, 0, len(newRoot)+len(t)-len(oldRoot))
+	for _, step := range newRoot {
+		switch ts := step.(type) {
+		case TraverseRoot:
+			ts.SrcRange = rng
+			step = ts
+		case TraverseAttr:
+			ts.SrcRange = rng
+			step = ts
+		case TraverseIndex:
+			ts.SrcRange = rng
+			step = ts
+		case TraverseSplat:
+			ts.SrcRange = rng
+			step = ts
+		}
+		ret = append(ret, step)
+	}
+	ret = append(ret, t[len(oldRoot):]...)
+	return ret, true
+}
+
+// traverserEqual returns true if the two given traversal steps are of the
+// same type and have the same name or key, disregarding their source ranges.
+func traverserEqual(a, b Traverser) bool {
+	switch ta := a.(type) {
+	case TraverseRoot:
+		tb, ok := b.(TraverseRoot)
+		return ok && ta.Name == tb.Name
+	case TraverseAttr:
+		tb, ok := b.(TraverseAttr)
+		return ok && ta.Name == tb.Name
+	case TraverseIndex:
+		tb, ok := b.(TraverseIndex)
+		return ok && ta.Key.RawEquals(tb.Key)
+	case TraverseSplat:
+		_, ok := b.(TraverseSplat)
+		return ok
+	default:
+		return false
+	}
+}
+
 // TraverseRel applies the receiving traversal to the given value, returning
 // the resulting value. This is supported only for relative traversals,
 // and will panic if applied to an absolute traversal.
@@ -62,6 +137,9 @@ func (t Traversal) TraverseRel(val cty.Value) (cty.Value, Diagnostics) {
 // TraverseAbs applies the receiving traversal to the given eval context,
 // returning the resulting value. This is supported only for absolute
 // traversals, and will panic if applied to a relative traversal.
+//
+// If the root variable's value was created by LazyVal then it is resolved
+// on demand, as described there.
 func (t Traversal) TraverseAbs(ctx *EvalContext) (cty.Value, Diagnostics) {
 	if t.IsRelative() {
 		panic("can't use TraverseAbs on a relative traversal")
@@ -74,14 +152,21 @@ func (t Traversal) TraverseAbs(ctx *EvalContext) (cty.Value, Diagnostics) {
 	thisCtx := ctx
 	hasNonNil := false
 	for thisCtx != nil {
-		if thisCtx.Variables == nil {
+		if thisCtx.Variables == nil && thisCtx.VariableResolver == nil {
 			thisCtx = thisCtx.parent
 			continue
 		}
 		hasNonNil = true
 		val, exists := thisCtx.Variables[name]
+		if !exists && thisCtx.VariableResolver != nil {
+			val, exists = thisCtx.VariableResolver(name)
+		}
 		if exists {
-			return split.Rel.TraverseRel(val)
+			val, rel, diags := resolveLazy(val, root.SrcRange, split.Rel)
+			if diags.HasErrors() {
+				return val, diags
+			}
+			return rel.TraverseRel(val)
 		}
 		thisCtx = thisCtx.parent
 	}
@@ -170,6 +255,96 @@ func (t Traversal) SourceRange() Range {
 	return RangeBetween(t[0].SourceRange(), t[len(t)-1].SourceRange())
 }
 
+// RenderSource returns a string representation of the traversal in the
+// native syntax, such as aws_instance.web[0].id, as it might have appeared
+// in the source code it was parsed from. This is intended for use in
+// error messages and other user-facing output.
+//
+// Indexing with a string, number or bool key is rendered as a literal,
+// quoting and escaping string keys as necessary. Any other key, including an
+// unknown value, is rendered as "[...]" since it cannot be written as a
+// literal.
+func (t Traversal) RenderSource() string {
+	var buf strings.Builder
+	for _, step := range t {
+		switch ts := step.(type) {
+		case TraverseRoot:
+			buf.WriteString(ts.Name)
+		case TraverseAttr:
+			buf.WriteByte('.')
+			buf.WriteString(ts.Name)
+		case TraverseIndex:
+			buf.WriteByte('[')
+			buf.WriteString(renderIndexKey(ts.Key))
+			buf.WriteByte(']')
+		case TraverseSplat:
+			buf.WriteString("[*]")
+			buf.WriteString(ts.Each.RenderSource())
+		}
+	}
+	return buf.String()
+}
+
+func renderIndexKey(key cty.Value) string {
+	switch {
+	case !key.IsKnown():
+		return "..."
+	case key.IsNull():
+		return "null"
+	case key.Type() == cty.String:
+		return quoteStringLit(key.AsString())
+	case key.Type() == cty.Number:
+		return key.AsBigFloat().Text('f', -1)
+	case key.Type() == cty.Bool:
+		if key.True() {
+			return "true"
+		}
+		return "false"
+	default:
+		return "..."
+	}
+}
+
+// quoteStringLit returns the given string as a quoted string literal in the
+// native syntax, escaping the characters and template sequences that would
+// otherwise be interpreted.
+func quoteStringLit(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '$', '%':
+			buf.WriteRune(r)
+			if remain := s[i+1:]; len(remain) > 0 && remain[0] == '{' {
+				// Double up our template introducer symbol to escape it.
+				buf.WriteRune(r)
+			}
+		default:
+			if !unicode.IsPrint(r) {
+				if r < 65536 {
+					fmt.Fprintf(&buf, "\\u%04x", r)
+				} else {
+					fmt.Fprintf(&buf, "\\U%08x", r)
+				}
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
 // TraversalSplit represents a pair of traversals, the first of which is
 // an absolute traversal and the second of which is relative to the first.
 //
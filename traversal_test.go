@@ -0,0 +1,447 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTraversalRenderSource(t *testing.T) {
+	tests := []struct {
+		Traversal Traversal
+		Want      string
+	}{
+		{
+			nil,
+			``,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "foo"},
+			},
+			`foo`,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "aws_instance"},
+				TraverseAttr{Name: "web"},
+				TraverseIndex{Key: cty.NumberIntVal(0)},
+				TraverseAttr{Name: "id"},
+			},
+			`aws_instance.web[0].id`,
+		},
+		{
+			Traversal{
+				TraverseAttr{Name: "baz"},
+				TraverseIndex{Key: cty.NumberFloatVal(1.5)},
+			},
+			`.baz[1.5]`,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "foo"},
+				TraverseIndex{Key: cty.StringVal("hello \"world\"")},
+			},
+			`foo["hello \"world\""]`,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "foo"},
+				TraverseIndex{Key: cty.StringVal("${bar}\n%{baz}")},
+			},
+			`foo["$${bar}\n%%{baz}"]`,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "foo"},
+				TraverseIndex{Key: cty.True},
+				TraverseIndex{Key: cty.UnknownVal(cty.String)},
+			},
+			`foo[true][...]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Want, func(t *testing.T) {
+			got := test.Traversal.RenderSource()
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestRebaseTraversal(t *testing.T) {
+	rng := func(start, end int) Range {
+		return Range{
+			Start: Pos{Line: 1, Column: start + 1, Byte: start},
+			End:   Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+	// var.foo[0]
+	orig := Traversal{
+		TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+		TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+		TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(7, 10)},
+	}
+
+	tests := map[string]struct {
+		oldRoot Traversal
+		newRoot Traversal
+		want    Traversal
+		wantOK  bool
+	}{
+		"root only": {
+			Traversal{TraverseRoot{Name: "var"}},
+			Traversal{
+				TraverseRoot{Name: "local"},
+				TraverseAttr{Name: "imported"},
+				TraverseAttr{Name: "var"},
+			},
+			Traversal{
+				TraverseRoot{Name: "local", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "imported", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(7, 10)},
+			},
+			true,
+		},
+		"multi-step prefix": {
+			Traversal{TraverseRoot{Name: "var"}, TraverseAttr{Name: "foo"}},
+			Traversal{TraverseRoot{Name: "bar"}},
+			Traversal{
+				TraverseRoot{Name: "bar", SrcRange: rng(0, 7)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(7, 10)},
+			},
+			true,
+		},
+		"whole traversal": {
+			Traversal{
+				TraverseRoot{Name: "var"},
+				TraverseAttr{Name: "foo"},
+				TraverseIndex{Key: cty.NumberIntVal(0)},
+			},
+			Traversal{TraverseRoot{Name: "baz"}},
+			Traversal{
+				TraverseRoot{Name: "baz", SrcRange: rng(0, 10)},
+			},
+			true,
+		},
+		"different root": {
+			Traversal{TraverseRoot{Name: "local"}},
+			Traversal{TraverseRoot{Name: "baz"}},
+			orig,
+			false,
+		},
+		"different index key": {
+			Traversal{
+				TraverseRoot{Name: "var"},
+				TraverseAttr{Name: "foo"},
+				TraverseIndex{Key: cty.NumberIntVal(1)},
+			},
+			Traversal{TraverseRoot{Name: "baz"}},
+			orig,
+			false,
+		},
+		"prefix longer than traversal": {
+			Traversal{
+				TraverseRoot{Name: "var"},
+				TraverseAttr{Name: "foo"},
+				TraverseIndex{Key: cty.NumberIntVal(0)},
+				TraverseAttr{Name: "bar"},
+			},
+			Traversal{TraverseRoot{Name: "baz"}},
+			orig,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := RebaseTraversal(orig, test.oldRoot, test.newRoot)
+			if ok != test.wantOK {
+				t.Errorf("wrong ok %t; want %t", ok, test.wantOK)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTraversalTypeCheck(t *testing.T) {
+	rng := func(start, end int) Range {
+		return Range{
+			Start: Pos{Line: 1, Column: start + 1, Byte: start},
+			End:   Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+	ty := cty.Object(map[string]cty.Type{
+		"foo": cty.Object(map[string]cty.Type{
+			"bar":  cty.List(cty.String),
+			"pair": cty.Tuple([]cty.Type{cty.String, cty.Number}),
+		}),
+		"tags":  cty.Map(cty.String),
+		"items": cty.List(cty.Object(map[string]cty.Type{"id": cty.String})),
+		"any":   cty.DynamicPseudoType,
+	})
+
+	tests := map[string]struct {
+		traversal Traversal
+		want      []string
+	}{
+		"valid": {
+			// var.foo.bar[0]
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "bar", SrcRange: rng(7, 11)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(11, 14)},
+			},
+			nil,
+		},
+		"missing attribute": {
+			// var.foo.baz[0]
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "baz", SrcRange: rng(7, 11)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(11, 14)},
+			},
+			[]string{`:1,8-12: Unsupported attribute; This object does not have an attribute named "baz".`},
+		},
+		"attribute of primitive": {
+			// var.foo.bar[0].x
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "bar", SrcRange: rng(7, 11)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(11, 14)},
+				TraverseAttr{Name: "x", SrcRange: rng(14, 16)},
+			},
+			[]string{`:1,15-17: Unsupported attribute; Can't access attributes on a primitive-typed value (string).`},
+		},
+		"string index into list": {
+			// var.foo.bar["a"]
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "bar", SrcRange: rng(7, 11)},
+				TraverseIndex{Key: cty.StringVal("a"), SrcRange: rng(11, 16)},
+			},
+			[]string{`:1,12-17: Invalid index; The given key does not identify an element in this collection value: a number is required.`},
+		},
+		"tuple element": {
+			// var.foo.pair[1]
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "pair", SrcRange: rng(7, 12)},
+				TraverseIndex{Key: cty.NumberIntVal(1), SrcRange: rng(12, 15)},
+			},
+			nil,
+		},
+		"tuple index out of range": {
+			// var.foo.pair[2]
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "foo", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "pair", SrcRange: rng(7, 12)},
+				TraverseIndex{Key: cty.NumberIntVal(2), SrcRange: rng(12, 15)},
+			},
+			[]string{`:1,13-16: Invalid index; The given key does not identify an element in this collection value: the given index is greater than or equal to the length of the collection.`},
+		},
+		"map element": {
+			// var.tags.env.x
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "tags", SrcRange: rng(3, 8)},
+				TraverseAttr{Name: "env", SrcRange: rng(8, 12)},
+				TraverseAttr{Name: "x", SrcRange: rng(12, 14)},
+			},
+			[]string{`:1,13-15: Unsupported attribute; Can't access attributes on a primitive-typed value (string).`},
+		},
+		"list of objects": {
+			// var.items.id
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "items", SrcRange: rng(3, 9)},
+				TraverseAttr{Name: "id", SrcRange: rng(9, 12)},
+			},
+			[]string{`:1,10-13: Unsupported attribute; Can't access attributes on a list of objects. Did you mean to access attribute "id" for a specific element of the list, or across all elements of the list?`},
+		},
+		"splat": {
+			// var.items[*].name
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "items", SrcRange: rng(3, 9)},
+				TraverseSplat{
+					Each: Traversal{
+						TraverseAttr{Name: "name", SrcRange: rng(12, 17)},
+					},
+					SrcRange: rng(9, 12),
+				},
+			},
+			[]string{`:1,13-18: Unsupported attribute; This object does not have an attribute named "name".`},
+		},
+		"dynamic": {
+			// var.any.whatever[0]
+			Traversal{
+				TraverseRoot{Name: "var", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "any", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "whatever", SrcRange: rng(7, 16)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(16, 19)},
+			},
+			nil,
+		},
+		"relative": {
+			// .foo.nope
+			Traversal{
+				TraverseAttr{Name: "foo", SrcRange: rng(0, 4)},
+				TraverseAttr{Name: "nope", SrcRange: rng(4, 9)},
+			},
+			[]string{`:1,5-10: Unsupported attribute; This object does not have an attribute named "nope".`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := test.traversal.TypeCheck(ty)
+			var got []string
+			for _, diag := range diags {
+				got = append(got, diag.Error())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTraverseAbsLazy(t *testing.T) {
+	var resolved []string
+	var resolve LazyResolver
+	resolve = func(path cty.Path) (cty.Value, error) {
+		resolved = append(resolved, fmt.Sprintf("%#v", path))
+		if len(path) == 0 {
+			return cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("whole"),
+			}), nil
+		}
+		if step, ok := path[0].(cty.GetAttrStep); ok && step.Name == "nested" {
+			if len(path) == 1 {
+				return LazyVal(resolve), nil
+			}
+			return cty.StringVal("nested"), nil
+		}
+		if step, ok := path[len(path)-1].(cty.GetAttrStep); ok && step.Name == "missing" {
+			return cty.NilVal, fmt.Errorf("There is no setting named %q.", step.Name)
+		}
+		return cty.TupleVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"x": cty.NumberIntVal(int64(len(path))),
+			}),
+		}), nil
+	}
+
+	ctx := &EvalContext{
+		Variables: map[string]cty.Value{
+			"config": LazyVal(resolve),
+		},
+	}
+	rng := func(start, end int) Range {
+		return Range{
+			Start: Pos{Line: 1, Column: start + 1, Byte: start},
+			End:   Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+
+	tests := map[string]struct {
+		Traversal    Traversal
+		Want         cty.Value
+		WantResolved []string
+		WantDiag     string
+	}{
+		"whole variable": {
+			Traversal{
+				TraverseRoot{Name: "config", SrcRange: rng(0, 6)},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("whole"),
+			}),
+			[]string{`cty.Path(nil)`},
+			"",
+		},
+		"static path": {
+			Traversal{
+				TraverseRoot{Name: "config", SrcRange: rng(0, 6)},
+				TraverseAttr{Name: "a", SrcRange: rng(6, 8)},
+				TraverseIndex{Key: cty.StringVal("b"), SrcRange: rng(8, 13)},
+			},
+			cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"x": cty.NumberIntVal(2),
+				}),
+			}),
+			[]string{`cty.Path{cty.GetAttrStep{Name:"a"}, cty.IndexStep{Key:cty.StringVal("b")}}`},
+			"",
+		},
+		"remaining steps": {
+			Traversal{
+				TraverseRoot{Name: "config", SrcRange: rng(0, 6)},
+				TraverseAttr{Name: "a", SrcRange: rng(6, 8)},
+				TraverseIndex{Key: cty.UnknownVal(cty.Number), SrcRange: rng(8, 11)},
+			},
+			cty.DynamicVal,
+			[]string{`cty.Path{cty.GetAttrStep{Name:"a"}}`},
+			"",
+		},
+		"nested lazy value": {
+			Traversal{
+				TraverseRoot{Name: "config", SrcRange: rng(0, 6)},
+				TraverseAttr{Name: "nested", SrcRange: rng(6, 13)},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("whole"),
+			}),
+			[]string{`cty.Path{cty.GetAttrStep{Name:"nested"}}`, `cty.Path(nil)`},
+			"",
+		},
+		"resolver error": {
+			Traversal{
+				TraverseRoot{Name: "config", SrcRange: rng(0, 6)},
+				TraverseAttr{Name: "a", SrcRange: rng(6, 8)},
+				TraverseAttr{Name: "missing", SrcRange: rng(8, 16)},
+			},
+			cty.DynamicVal,
+			[]string{`cty.Path{cty.GetAttrStep{Name:"a"}, cty.GetAttrStep{Name:"missing"}}`},
+			`:1,1-17: Invalid reference; There is no setting named "missing".`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resolved = nil
+			got, diags := test.Traversal.TraverseAbs(ctx)
+			var gotDiag string
+			if len(diags) != 0 {
+				gotDiag = diags.Error()
+			}
+			if gotDiag != test.WantDiag {
+				t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", gotDiag, test.WantDiag)
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+			if !reflect.DeepEqual(resolved, test.WantResolved) {
+				t.Errorf("wrong paths resolved\ngot:  %#v\nwant: %#v", resolved, test.WantResolved)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// TypeCheck statically checks the receiving traversal against the given
+// type, without needing a value of that type, and returns error diagnostics
+// describing the first step of the traversal that would fail for any value
+// of that type.
+//
+// For an absolute traversal, the given type is the type of the root
+// variable and so the root step is not checked. For a relative traversal,
+// the given type is the type of the value the traversal would be applied
+// to.
+//
+// Each step is checked in the same way as the corresponding operation in
+// GetAttr or Index, and the diagnostics are equivalent to those that would
+// be returned by TraverseAbs or TraverseRel for a value of the given type,
+// with their subjects at the source ranges of the failing steps. Checks
+// that depend on the value rather than the type, such as whether a map has
+// an element with a particular key or whether an index is within the
+// length of a list, are not made, and no further checks are made for any
+// step that follows a step that produces a value of type
+// cty.DynamicPseudoType. For a splat step, the steps of the splat are
+// checked against the type of each element of a sequence.
+func (t Traversal) TypeCheck(rootType cty.Type) Diagnostics {
+	steps := t
+	if !t.IsRelative() {
+		steps = t[1:]
+	}
+	_, diags := typeCheckTraversal(rootType, steps)
+	return diags
+}
+
+func typeCheckTraversal(ty cty.Type, steps Traversal) (cty.Type, Diagnostics) {
+	for _, step := range steps {
+		if ty == cty.DynamicPseudoType {
+			return ty, nil
+		}
+
+		var diags Diagnostics
+		switch step := step.(type) {
+		case TraverseAttr:
+			ty, diags = typeCheckGetAttr(ty, step.Name, &step.SrcRange)
+		case TraverseIndex:
+			ty, diags = typeCheckIndex(ty, step.Key, &step.SrcRange)
+		case TraverseSplat:
+			ty, diags = typeCheckSplat(ty, step.Each)
+		default:
+			// TraverseRoot can appear only at the start of a traversal, and
+			// so we should not get here.
+			return cty.DynamicPseudoType, nil
+		}
+		if diags.HasErrors() {
+			return cty.DynamicPseudoType, diags
+		}
+	}
+	return ty, nil
+}
+
+// typeCheckGetAttr is the type-only equivalent of GetAttr.
+func typeCheckGetAttr(ty cty.Type, attrName string, srcRange *Range) (cty.Type, Diagnostics) {
+	const unsupportedAttr = "Unsupported attribute"
+
+	switch {
+	case ty.IsObjectType():
+		if !ty.HasAttribute(attrName) {
+			return cty.DynamicPseudoType, Diagnostics{
+				{
+					Severity: DiagError,
+					Summary:  unsupportedAttr,
+					Detail:   fmt.Sprintf("This object does not have an attribute named %q.", attrName),
+					Subject:  srcRange,
+				},
+			}
+		}
+		return ty.AttributeType(attrName), nil
+	case ty.IsMapType():
+		return ty.ElementType(), nil
+	case ty.IsListType() && ty.ElementType().IsObjectType():
+		if ty.ElementType().HasAttribute(attrName) {
+			return cty.DynamicPseudoType, Diagnostics{
+				{
+					Severity: DiagError,
+					Summary:  unsupportedAttr,
+					Detail:   fmt.Sprintf("Can't access attributes on a list of objects. Did you mean to access attribute %q for a specific element of the list, or across all elements of the list?", attrName),
+					Subject:  srcRange,
+				},
+			}
+		}
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  unsupportedAttr,
+				Detail:   "Can't access attributes on a list of objects. Did you mean to access an attribute for a specific element of the list, or across all elements of the list?",
+				Subject:  srcRange,
+			},
+		}
+	case ty.IsSetType() && ty.ElementType().IsObjectType():
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  unsupportedAttr,
+				Detail:   "Can't access attributes on a set of objects. Did you mean to access an attribute across all elements of the set?",
+				Subject:  srcRange,
+			},
+		}
+	case ty.IsPrimitiveType():
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  unsupportedAttr,
+				Detail:   fmt.Sprintf("Can't access attributes on a primitive-typed value (%s).", ty.FriendlyName()),
+				Subject:  srcRange,
+			},
+		}
+	default:
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  unsupportedAttr,
+				Detail:   "This value does not have any attributes.",
+				Subject:  srcRange,
+			},
+		}
+	}
+}
+
+// typeCheckIndex is the type-only equivalent of Index.
+func typeCheckIndex(ty cty.Type, key cty.Value, srcRange *Range) (cty.Type, Diagnostics) {
+	const invalidIndex = "Invalid index"
+
+	if key.IsNull() {
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  invalidIndex,
+				Detail:   "Can't use a null value as an indexing key.",
+				Subject:  srcRange,
+			},
+		}
+	}
+	if key.Type() == cty.DynamicPseudoType {
+		return cty.DynamicPseudoType, nil
+	}
+	key, _ = key.Unmark()
+
+	switch {
+	case ty.IsListType() || ty.IsTupleType() || ty.IsMapType() || ty.IsObjectType():
+		wantType := cty.String
+		if ty.IsListType() || ty.IsTupleType() {
+			wantType = cty.Number
+		}
+		wasNumber := key.Type() == cty.Number
+		key, keyErr := convert.Convert(key, wantType)
+		if keyErr != nil {
+			return cty.DynamicPseudoType, Diagnostics{
+				{
+					Severity: DiagError,
+					Summary:  invalidIndex,
+					Detail: fmt.Sprintf(
+						"The given key does not identify an element in this collection value: %s.",
+						keyErr.Error(),
+					),
+					Subject: srcRange,
+				},
+			}
+		}
+
+		if wantType == cty.Number && key.IsKnown() {
+			bf := key.AsBigFloat()
+			if _, acc := bf.Int(nil); acc != big.Exact {
+				return cty.DynamicPseudoType, Diagnostics{
+					{
+						Severity: DiagError,
+						Summary:  invalidIndex,
+						Detail:   "The given key does not identify an element in this collection value: indexing a sequence requires a whole number, but the given index has a fractional part.",
+						Subject:  srcRange,
+					},
+				}
+			}
+			if bf.Sign() < 0 {
+				return cty.DynamicPseudoType, Diagnostics{
+					{
+						Severity: DiagError,
+						Summary:  invalidIndex,
+						Detail:   "The given key does not identify an element in this collection value: a negative number is not a valid index for a sequence.",
+						Subject:  srcRange,
+					},
+				}
+			}
+		}
+
+		switch {
+		case ty.IsListType() || ty.IsMapType():
+			return ty.ElementType(), nil
+		case !key.IsKnown():
+			return cty.DynamicPseudoType, nil
+		case ty.IsTupleType():
+			elemTypes := ty.TupleElementTypes()
+			idx, acc := key.AsBigFloat().Int64()
+			if acc != big.Exact || idx >= int64(len(elemTypes)) {
+				detail := "The given key does not identify an element in this collection value: the given index is greater than or equal to the length of the collection."
+				if len(elemTypes) == 0 {
+					detail = "The given key does not identify an element in this collection value: the collection has no elements."
+				}
+				return cty.DynamicPseudoType, Diagnostics{
+					{
+						Severity: DiagError,
+						Summary:  invalidIndex,
+						Detail:   detail,
+						Subject:  srcRange,
+					},
+				}
+			}
+			return elemTypes[idx], nil
+		default:
+			attrName := key.AsString()
+			if !ty.HasAttribute(attrName) {
+				var suggestion string
+				if wasNumber {
+					suggestion = " An object only supports looking up attributes by name, not by numeric index."
+				}
+				return cty.DynamicPseudoType, Diagnostics{
+					{
+						Severity: DiagError,
+						Summary:  invalidIndex,
+						Detail:   fmt.Sprintf("The given key does not identify an element in this collection value.%s", suggestion),
+						Subject:  srcRange,
+					},
+				}
+			}
+			return ty.AttributeType(attrName), nil
+		}
+
+	case ty.IsSetType():
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  invalidIndex,
+				Detail:   "Elements of a set are identified only by their value and don't have any separate index or key to select with, so it's only possible to perform operations across all elements of the set.",
+				Subject:  srcRange,
+			},
+		}
+
+	default:
+		return cty.DynamicPseudoType, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  invalidIndex,
+				Detail:   "This value does not have any indices.",
+				Subject:  srcRange,
+			},
+		}
+	}
+}
+
+// typeCheckSplat checks the given steps of a splat against the type of each
+// element of the given type, returning the type of the splat's result.
+func typeCheckSplat(ty cty.Type, each Traversal) (cty.Type, Diagnostics) {
+	switch {
+	case ty.IsListType() || ty.IsSetType():
+		elemTy, diags := typeCheckTraversal(ty.ElementType(), each)
+		if diags.HasErrors() || elemTy == cty.DynamicPseudoType {
+			return cty.DynamicPseudoType, diags
+		}
+		return cty.List(elemTy), diags
+	case ty.IsTupleType():
+		elemTypes := ty.TupleElementTypes()
+		retTypes := make([]cty.Type, len(elemTypes))
+		for i, elemTy := range elemTypes {
+			var diags Diagnostics
+			retTypes[i], diags = typeCheckTraversal(elemTy, each)
+			if diags.HasErrors() {
+				return cty.DynamicPseudoType, diags
+			}
+		}
+		return cty.Tuple(retTypes), nil
+	default:
+		// Any other value is treated as a single-element sequence.
+		elemTy, diags := typeCheckTraversal(ty, each)
+		if diags.HasErrors() || elemTy == cty.DynamicPseudoType {
+			return cty.DynamicPseudoType, diags
+		}
+		return cty.Tuple([]cty.Type{elemTy}), diags
+	}
+}